@@ -0,0 +1,348 @@
+package artifacts
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// IndexFilename is the name of the index written alongside the artifacts in a directory
+	IndexFilename = "index.json"
+	// ProvidersDir is where terraform providers are mirrored to in a directory of artifacts
+	ProvidersDir = "terraform-providers"
+)
+
+// Artifact is a release, stemcell or CLI that a deployment downloads, with the checksums it is
+// published with, either of which may be empty
+type Artifact struct {
+	URL    string
+	SHA1   string
+	SHA256 string
+}
+
+// Index records the artifacts in a directory, keyed by the URL each was downloaded from
+type Index map[string]Entry
+
+// Entry is a downloaded artifact
+type Entry struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// Mirror maps the URLs of artifacts to where they have been downloaded, so that they are used
+// from there rather than fetched again
+type Mirror map[string]string
+
+// FromVersionFile lists the releases and stemcell in a version file, along with the downloads of
+// each CLI for the given platforms, keyed GOOS/GOARCH
+func FromVersionFile(versionFile []byte, platforms ...string) ([]Artifact, error) {
+	var entries map[string]struct {
+		URL       string `json:"url"`
+		SHA1      string `json:"sha1"`
+		Linux     string `json:"linux"`
+		Platforms map[string]struct {
+			URL    string `json:"url"`
+			SHA256 string `json:"sha256"`
+		} `json:"platforms"`
+	}
+	if err := json.Unmarshal(versionFile, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse version file: [%v]", err)
+	}
+
+	var found []Artifact
+	for _, entry := range entries {
+		if isDownload(entry.URL) {
+			found = append(found, Artifact{URL: entry.URL, SHA1: entry.SHA1})
+		}
+		for _, platform := range platforms {
+			if b, ok := entry.Platforms[platform]; ok {
+				found = append(found, Artifact{URL: b.URL, SHA256: b.SHA256})
+			} else if platform == "linux/amd64" && isDownload(entry.Linux) {
+				found = append(found, Artifact{URL: entry.Linux})
+			}
+		}
+	}
+	return found, nil
+}
+
+// FromOps lists the releases added by BOSH ops files, whether as a release with a url and sha1 or as
+// separate operations replacing /releases/name=NAME/url and /releases/name=NAME/sha1
+func FromOps(opsFiles ...[]byte) []Artifact {
+	var found []Artifact
+	for _, contents := range opsFiles {
+		var ops []struct {
+			Path  string      `yaml:"path"`
+			Value interface{} `yaml:"value"`
+		}
+		if err := yaml.Unmarshal(contents, &ops); err != nil {
+			continue
+		}
+		sha1s := map[string]string{}
+		for _, op := range ops {
+			if v, ok := op.Value.(string); ok && strings.HasSuffix(op.Path, "/sha1") && !isVariable(v) {
+				sha1s[strings.TrimSuffix(op.Path, "/sha1")] = v
+			}
+		}
+		for _, op := range ops {
+			if v, ok := op.Value.(string); ok && strings.HasSuffix(op.Path, "/url") && isDownload(v) {
+				found = append(found, Artifact{URL: v, SHA1: sha1s[strings.TrimSuffix(op.Path, "/url")]})
+				continue
+			}
+			found = append(found, releases(op.Value)...)
+		}
+	}
+	return found
+}
+
+// releases finds every map with a url to download in value, taking its sha1 alongside
+func releases(value interface{}) []Artifact {
+	var found []Artifact
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if url, ok := v["url"].(string); ok && isDownload(url) {
+			sha1, _ := v["sha1"].(string)
+			if isVariable(sha1) {
+				sha1 = ""
+			}
+			found = append(found, Artifact{URL: url, SHA1: sha1})
+		}
+		for _, child := range v {
+			found = append(found, releases(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			found = append(found, releases(child)...)
+		}
+	}
+	return found
+}
+
+// isDownload is true of a URL to download, and false of one that is only known once a variable is interpolated
+func isDownload(url string) bool {
+	return (strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) && !isVariable(url)
+}
+
+// isVariable is true of a value that is only known once it is interpolated, such as a checksum looked up on deploy
+func isVariable(value string) bool {
+	return strings.Contains(value, "((")
+}
+
+// Download downloads every artifact into dir, checking each against the checksums it was published
+// with, and returns an index of them
+func Download(list []Artifact, dir string, stdout io.Writer) (Index, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	index := Index{}
+	for _, a := range list {
+		if _, ok := index[a.URL]; ok {
+			continue
+		}
+		fmt.Fprintf(stdout, "Downloading %s\n", a.URL)
+		entry, err := download(a, dir)
+		if err != nil {
+			return nil, fmt.Errorf("error downloading %s: [%v]", a.URL, err)
+		}
+		index[a.URL] = entry
+	}
+	return index, nil
+}
+
+// AddFiles records the files under sub in dir that were put there by something other than Download,
+// such as terraform providers, so that Load checks them too. They are keyed by their path in dir
+func (index Index) AddFiles(dir, sub string) error {
+	return filepath.Walk(filepath.Join(dir, sub), func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		digest, err := fileSHA256(p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		index[rel] = Entry{File: rel, SHA256: digest}
+		return nil
+	})
+}
+
+// Write writes the index to dir, returning what was written
+func (index Index) Write(dir string) ([]byte, error) {
+	contents, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return contents, ioutil.WriteFile(filepath.Join(dir, IndexFilename), contents, 0644)
+}
+
+func download(a Artifact, dir string) (Entry, error) {
+	resp, err := http.Get(a.URL)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Entry{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	name := fileName(a.URL)
+	f, err := ioutil.TempFile(dir, ".download-")
+	if err != nil {
+		return Entry{}, err
+	}
+	defer os.Remove(f.Name())
+	sha1Digest, sha256Digest := sha1.New(), sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, sha1Digest, sha256Digest), resp.Body); err != nil {
+		f.Close()
+		return Entry{}, err
+	}
+	if err = f.Close(); err != nil {
+		return Entry{}, err
+	}
+	// BOSH releases give a sha1 field, which newer releases fill with a sha256 prefixed by its algorithm
+	releaseDigest, releaseSum := sha1Digest, strings.TrimPrefix(a.SHA1, "sha1:")
+	if strings.HasPrefix(a.SHA1, "sha256:") {
+		releaseDigest, releaseSum = sha256Digest, strings.TrimPrefix(a.SHA1, "sha256:")
+	}
+	if err = check(releaseDigest, releaseSum); err != nil {
+		return Entry{}, err
+	}
+	if err = check(sha256Digest, a.SHA256); err != nil {
+		return Entry{}, err
+	}
+	if err = os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return Entry{}, err
+	}
+	return Entry{File: name, SHA256: hex.EncodeToString(sha256Digest.Sum(nil))}, nil
+}
+
+func check(digest hash.Hash, want string) error {
+	if want == "" {
+		return nil
+	}
+	if got := hex.EncodeToString(digest.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum is %s, expected %s", got, want)
+	}
+	return nil
+}
+
+// fileName is unique to url, while keeping the name it was published with for anyone looking in the directory
+func fileName(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	base := path.Base(strings.SplitN(url, "?", 2)[0])
+	return hex.EncodeToString(sum[:8]) + "-" + base
+}
+
+// Load reads the index in dir and checks every artifact in it, so that one that is missing or has
+// changed since it was downloaded is never used
+func Load(dir string) (Index, Mirror, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, IndexFilename))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading the index of artifacts in %s: [%v]", dir, err)
+	}
+	var index Index
+	if err = json.Unmarshal(contents, &index); err != nil {
+		return nil, nil, fmt.Errorf("error reading the index of artifacts in %s: [%v]", dir, err)
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mirror := Mirror{}
+	var problems []string
+	for _, url := range index.urls() {
+		entry := index[url]
+		p := filepath.Join(dir, filepath.FromSlash(entry.File))
+		got, err := fileSHA256(p)
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, fmt.Sprintf("%s is missing", entry.File))
+		case err != nil:
+			return nil, nil, err
+		case !strings.EqualFold(got, entry.SHA256):
+			problems = append(problems, fmt.Sprintf("%s has changed since it was downloaded", entry.File))
+		default:
+			mirror[url] = p
+		}
+	}
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("artifacts in %s can't be used: %s", dir, strings.Join(problems, "; "))
+	}
+	return index, mirror, nil
+}
+
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	digest := sha256.New()
+	if _, err = io.Copy(digest, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Missing lists the URLs of artifacts in list that aren't in the index
+func (index Index) Missing(list []Artifact) []string {
+	var missing []string
+	for _, a := range list {
+		if _, ok := index[a.URL]; !ok {
+			missing = append(missing, a.URL)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (index Index) urls() []string {
+	var urls []string
+	for url := range index {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// Path returns where the artifact at url has been downloaded to, if it has been
+func (m Mirror) Path(url string) (string, bool) {
+	p, ok := m[url]
+	return p, ok
+}
+
+// Rewrite replaces the URL of every downloaded artifact in contents with a file:// URL of its download.
+// Longer URLs are replaced first, so that a URL which is the prefix of another doesn't break it
+func (m Mirror) Rewrite(contents []byte) []byte {
+	if len(m) == 0 {
+		return contents
+	}
+	var urls []string
+	for url := range m {
+		urls = append(urls, url)
+	}
+	sort.Slice(urls, func(i, j int) bool { return len(urls[i]) > len(urls[j]) })
+	s := string(contents)
+	for _, url := range urls {
+		s = strings.ReplaceAll(s, url, "file://"+filepath.ToSlash(m[url]))
+	}
+	return []byte(s)
+}
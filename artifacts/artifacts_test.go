@@ -0,0 +1,145 @@
+package artifacts
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromVersionFile(t *testing.T) {
+	versionFile := []byte(`{
+		"bosh": {"url": "https://bosh.io/d/github.com/cloudfoundry/bosh?v=1", "version": "1", "sha1": "abc"},
+		"terraform": {"mac": "https://example.com/terraform-darwin.zip", "linux": "https://example.com/terraform-linux.zip"},
+		"bosh-cli": {"platforms": {"linux/amd64": {"url": "https://example.com/bosh-cli-linux", "sha256": "def"}, "darwin/arm64": {"url": "https://example.com/bosh-cli-darwin", "sha256": "ghi"}}}
+	}`)
+	list, err := FromVersionFile(versionFile, "linux/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Artifact{
+		"https://bosh.io/d/github.com/cloudfoundry/bosh?v=1": {URL: "https://bosh.io/d/github.com/cloudfoundry/bosh?v=1", SHA1: "abc"},
+		"https://example.com/terraform-linux.zip":            {URL: "https://example.com/terraform-linux.zip"},
+		"https://example.com/bosh-cli-linux":                 {URL: "https://example.com/bosh-cli-linux", SHA256: "def"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("expected %d artifacts, got %v", len(want), list)
+	}
+	for _, a := range list {
+		if want[a.URL] != a {
+			t.Errorf("unexpected artifact %v", a)
+		}
+	}
+}
+
+func TestFromOps(t *testing.T) {
+	versions := []byte(`[
+		{"type": "replace", "path": "/releases/name=concourse/url", "value": "https://bosh.io/d/github.com/concourse/concourse-bosh-release?v=7.0"},
+		{"type": "replace", "path": "/releases/name=concourse/sha1", "value": "abc"},
+		{"type": "replace", "path": "/stemcells/alias=jammy/version", "value": "1.0"}
+	]`)
+	ops := []byte(`
+- type: replace
+  path: /releases/name=os-conf?
+  value:
+    name: os-conf
+    url: https://bosh.io/d/github.com/cloudfoundry/os-conf-release?v=22
+    sha1: def
+- type: replace
+  path: /releases/name=concourse
+  value:
+    url: ((concourse_release_url))
+- type: replace
+  path: /releases/name=bpm?
+  value:
+    name: bpm
+    url: https://bosh.io/d/github.com/cloudfoundry/bpm-release?v=1
+    sha1: ((bpm_release_sha1))
+`)
+	list := FromOps(versions, ops)
+	if len(list) != 3 {
+		t.Fatalf("expected 3 artifacts, got %v", list)
+	}
+	if list[0] != (Artifact{URL: "https://bosh.io/d/github.com/concourse/concourse-bosh-release?v=7.0", SHA1: "abc"}) {
+		t.Errorf("unexpected artifact %v", list[0])
+	}
+	if list[1] != (Artifact{URL: "https://bosh.io/d/github.com/cloudfoundry/os-conf-release?v=22", SHA1: "def"}) {
+		t.Errorf("unexpected artifact %v", list[1])
+	}
+	if list[2] != (Artifact{URL: "https://bosh.io/d/github.com/cloudfoundry/bpm-release?v=1"}) {
+		t.Errorf("expected a checksum only known on deploy to be left out, got %v", list[2])
+	}
+}
+
+func TestDownloadAndLoad(t *testing.T) {
+	release := []byte("release")
+	sha1Sum := sha1.Sum(release)
+	sha256Sum := sha256.Sum256(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(release)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	list := []Artifact{
+		{URL: server.URL + "/release.tgz?v=1", SHA1: hex.EncodeToString(sha1Sum[:])},
+		{URL: server.URL + "/cli", SHA256: hex.EncodeToString(sha256Sum[:])},
+		{URL: server.URL + "/release.tgz?v=10", SHA1: "sha256:" + hex.EncodeToString(sha256Sum[:])},
+	}
+	index, err := Download(list, dir, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "providers", "registry"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "providers", "registry", "aws.zip"), []byte("provider"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = index.AddFiles(dir, "providers"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = index.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, mirror, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing := loaded.Missing(append(list, Artifact{URL: "https://example.com/other"})); len(missing) != 1 || missing[0] != "https://example.com/other" {
+		t.Errorf("unexpected missing artifacts %v", missing)
+	}
+	rewritten := string(mirror.Rewrite([]byte("url: " + server.URL + "/release.tgz?v=10\nurl: " + server.URL + "/release.tgz?v=1\n")))
+	short, _ := mirror.Path(server.URL + "/release.tgz?v=1")
+	long, _ := mirror.Path(server.URL + "/release.tgz?v=10")
+	if rewritten != "url: file://"+filepath.ToSlash(long)+"\nurl: file://"+filepath.ToSlash(short)+"\n" {
+		t.Errorf("unexpected rewrite %q", rewritten)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "providers", "registry", "aws.zip"), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = Load(dir); err == nil || !strings.Contains(err.Error(), "providers/registry/aws.zip has changed since it was downloaded") {
+		t.Errorf("expected a changed file to be rejected, got %v", err)
+	}
+}
+
+func TestDownloadRejectsChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("release"))
+	}))
+	defer server.Close()
+
+	_, err := Download([]Artifact{{URL: server.URL + "/release.tgz", SHA1: "0000"}}, t.TempDir(), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "expected 0000") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package autoscale
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// ContainersPerWorker is how many active containers a worker is sized for before another is wanted
+const ContainersPerWorker = 100
+
+// PendingBuildsPerWorker is how many builds waiting to be scheduled call for one more worker
+const PendingBuildsPerWorker = 5
+
+// Policy bounds the number of workers the autoscaler can choose
+type Policy struct {
+	Min int
+	Max int
+}
+
+// Load is the demand on the shared workers, measured from the ATC. PoolPendingBuilds counts the builds
+// waiting for each worker pool, which don't call for more shared workers
+type Load struct {
+	PendingBuilds     int
+	PoolPendingBuilds map[string]int
+	ActiveContainers  int
+	Workers           int
+}
+
+// Measure counts the pending builds, and the containers on the running workers that control-tower
+// scales. Workers with tags, a team or another platform belong to worker pools, Windows workers or
+// external workers, so they aren't counted. A pending build is counted for the shared workers when
+// it has a step without tags, and for each pool whose tags cover those of one of its tagged steps, going
+// by the tags of its steps in stepTags. A build whose steps aren't known is counted for the shared workers
+func Measure(builds []fly.Build, stepTags map[int][][]string, workers []fly.Worker, pools []config.WorkerPool) Load {
+	load := Load{PoolPendingBuilds: map[string]int{}}
+	for _, build := range builds {
+		if build.Status != "pending" {
+			continue
+		}
+		steps, ok := stepTags[build.ID]
+		if !ok {
+			load.PendingBuilds++
+			continue
+		}
+		shared, waitingFor := false, map[string]bool{}
+		for _, tags := range steps {
+			if len(tags) == 0 {
+				shared = true
+				continue
+			}
+			for _, pool := range pools {
+				if hasTags(pool.Tags, tags) {
+					waitingFor[pool.Name] = true
+				}
+			}
+		}
+		if shared {
+			load.PendingBuilds++
+		}
+		for name := range waitingFor {
+			load.PoolPendingBuilds[name]++
+		}
+	}
+	for _, worker := range workers {
+		if worker.State != "running" || worker.Team != "" || len(worker.Tags) > 0 || worker.Platform != "linux" {
+			continue
+		}
+		load.Workers++
+		load.ActiveContainers += worker.ActiveContainers
+	}
+	return load
+}
+
+// Desired returns how many workers to run for the load, given the current count. Pending builds scale
+// out straight away, while scaling in removes a single worker at a time, so that a brief lull
+// doesn't drain workers that are needed again minutes later
+func Desired(policy Policy, current int, load Load) int {
+	desired := divideRoundingUp(load.ActiveContainers, ContainersPerWorker)
+	if load.PendingBuilds > 0 {
+		if scaledOut := current + divideRoundingUp(load.PendingBuilds, PendingBuildsPerWorker); scaledOut > desired {
+			desired = scaledOut
+		}
+	} else if desired < current {
+		desired = current - 1
+	}
+
+	if desired < policy.Min {
+		return policy.Min
+	}
+	if desired > policy.Max {
+		return policy.Max
+	}
+	return desired
+}
+
+// String describes the load in the terms Desired works from, followed by the builds waiting for each pool
+func (l Load) String() string {
+	s := fmt.Sprintf("%d pending builds and %d active containers on %d workers", l.PendingBuilds, l.ActiveContainers, l.Workers)
+	var pools []string
+	for name := range l.PoolPendingBuilds {
+		pools = append(pools, name)
+	}
+	sort.Strings(pools)
+	for _, name := range pools {
+		s += fmt.Sprintf(", %d pending builds on worker pool %s", l.PoolPendingBuilds[name], name)
+	}
+	return s
+}
+
+// hasTags is true when workers with the given tags can run a step with the wanted tags
+func hasTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func divideRoundingUp(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,70 @@
+package autoscale
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+func TestMeasure(t *testing.T) {
+	builds := []fly.Build{
+		{ID: 4, Status: "pending"},
+		{ID: 3, Status: "pending"},
+		{ID: 2, Status: "started"},
+		{ID: 1, Status: "pending"},
+	}
+	stepTags := map[int][][]string{
+		4: {{"gpu"}, {"gpu"}},
+		3: {{}, {"gpu"}},
+	}
+	pools := []config.WorkerPool{
+		{Name: "gpu", Tags: []string{"gpu", "large"}},
+		{Name: "arm", Tags: []string{"arm64"}},
+	}
+	workers := []fly.Worker{
+		{Name: "a", State: "running", Platform: "linux", ActiveContainers: 40},
+		{Name: "b", State: "running", Platform: "linux", ActiveContainers: 70},
+		{Name: "c", State: "stalled", Platform: "linux", ActiveContainers: 12},
+		{Name: "d", State: "running", Platform: "linux", Tags: []string{"gpu"}, ActiveContainers: 9},
+		{Name: "e", State: "running", Platform: "windows", ActiveContainers: 3},
+		{Name: "f", State: "running", Platform: "linux", Team: "ops", ActiveContainers: 5},
+	}
+
+	want := Load{PendingBuilds: 2, PoolPendingBuilds: map[string]int{"gpu": 2}, ActiveContainers: 110, Workers: 2}
+	got := Measure(builds, stepTags, workers, pools)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Measure() = %+v, want %+v", got, want)
+	}
+	if s := got.String(); s != "2 pending builds and 110 active containers on 2 workers, 2 pending builds on worker pool gpu" {
+		t.Errorf("Load.String() = %s", s)
+	}
+}
+
+func TestDesired(t *testing.T) {
+	policy := Policy{Min: 2, Max: 10}
+
+	tests := []struct {
+		name    string
+		current int
+		load    Load
+		want    int
+	}{
+		{name: "idle scales in one worker at a time", current: 6, load: Load{Workers: 6}, want: 5},
+		{name: "idle stays at the minimum", current: 2, load: Load{Workers: 2}, want: 2},
+		{name: "containers hold the current size", current: 3, load: Load{ActiveContainers: 250, Workers: 3}, want: 3},
+		{name: "containers scale out", current: 2, load: Load{ActiveContainers: 420, Workers: 2}, want: 5},
+		{name: "pending builds scale out", current: 3, load: Load{PendingBuilds: 7, ActiveContainers: 120, Workers: 3}, want: 5},
+		{name: "pending builds don't scale in", current: 6, load: Load{PendingBuilds: 1, Workers: 6}, want: 7},
+		{name: "scale out stops at the maximum", current: 8, load: Load{PendingBuilds: 30, Workers: 8}, want: 10},
+		{name: "current below the minimum", current: 1, load: Load{Workers: 1}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Desired(policy, tt.current, tt.load); got != tt.want {
+				t.Errorf("Desired() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
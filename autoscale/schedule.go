@@ -0,0 +1,41 @@
+package autoscale
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+// scheduleLookback is how far back Scheduled looks for the last time a schedule matched, long enough
+// for yearly schedules
+const scheduleLookback = 366 * 24 * time.Hour
+
+// Scheduled returns the number of workers set by the schedule that matched most recently before now,
+// in the given timezone, and false if none has matched. When several match the same minute, the one
+// listed last wins
+func Scheduled(schedules []config.WorkerSchedule, timezone string, now time.Time) (int, bool, error) {
+	if len(schedules) == 0 {
+		return 0, false, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return 0, false, fmt.Errorf("worker schedule timezone %s is invalid: [%v]", timezone, err)
+	}
+	crons := make([]config.Cron, len(schedules))
+	for i, schedule := range schedules {
+		if crons[i], err = config.ParseCron(schedule.Cron); err != nil {
+			return 0, false, err
+		}
+	}
+
+	start := now.In(location).Truncate(time.Minute)
+	for t := start; start.Sub(t) <= scheduleLookback; t = t.Add(-time.Minute) {
+		for i := len(crons) - 1; i >= 0; i-- {
+			if crons[i].Matches(t) {
+				return schedules[i].Workers, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,54 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func TestScheduled(t *testing.T) {
+	schedules := []config.WorkerSchedule{
+		{Cron: "0 8 * * 1-5", Workers: 10},
+		{Cron: "0 19 * * 1-5", Workers: 2},
+		{Cron: "0 8 1 1 *", Workers: 1},
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		now      time.Time
+		want     int
+		wantOK   bool
+	}{
+		{name: "during working hours", timezone: "UTC", now: time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC), want: 10, wantOK: true},
+		{name: "overnight", timezone: "UTC", now: time.Date(2024, time.March, 6, 3, 0, 0, 0, time.UTC), want: 2, wantOK: true},
+		{name: "over the weekend", timezone: "UTC", now: time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), want: 2, wantOK: true},
+		{name: "on the minute it matches", timezone: "UTC", now: time.Date(2024, time.March, 5, 8, 0, 59, 0, time.UTC), want: 10, wantOK: true},
+		{name: "later schedules win the same minute", timezone: "UTC", now: time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC), want: 1, wantOK: true},
+		{name: "in the schedule's timezone", timezone: "Europe/London", now: time.Date(2024, time.July, 2, 7, 30, 0, 0, london).UTC(), want: 2, wantOK: true},
+		{name: "unset timezone is UTC", timezone: "", now: time.Date(2024, time.March, 5, 8, 0, 0, 0, time.UTC), want: 10, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := Scheduled(schedules, tt.timezone, tt.now)
+			if err != nil {
+				t.Fatalf("Scheduled() error = %v", err)
+			}
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Scheduled() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok, _ := Scheduled(nil, "UTC", time.Now()); ok {
+		t.Error("Scheduled() with no schedules should match nothing")
+	}
+	if _, _, err := Scheduled(schedules, "Mars/Olympus", time.Now()); err == nil {
+		t.Error("Scheduled() with an unknown timezone should fail")
+	}
+}
@@ -0,0 +1,111 @@
+// Package bastion reaches the director of a deployment deployed with --bastion, whose director can only be
+// reached through the deployment's bastion
+package bastion
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// User is the user that the bastion's key logs in as
+const User = "ubuntu"
+
+const dialTimeout = 10 * time.Second
+
+// Bastion is the jump host in front of a deployment's director
+type Bastion struct {
+	Host       string
+	PrivateKey string
+}
+
+// FromOutputs returns the deployment's bastion, or nil when it doesn't have one
+func FromOutputs(conf config.ConfigView, outputs terraform.Outputs) (*Bastion, error) {
+	if !conf.GetBastion() {
+		return nil, nil
+	}
+	host, err := outputs.Get("BastionPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BastionPublicIP from terraform outputs: [%v]", err)
+	}
+	if host == "" {
+		return nil, errors.New("the deployment has a bastion, but terraform has no address for it")
+	}
+	return &Bastion{Host: host, PrivateKey: conf.GetBastionPrivateKey()}, nil
+}
+
+// Addr is the address that the bastion accepts SSH connections on
+func (b *Bastion) Addr() string {
+	return net.JoinHostPort(b.Host, "22")
+}
+
+// AllProxy is the BOSH_ALL_PROXY that makes the bosh CLI connect to the director through the bastion, logging
+// into it with the private key saved at keyPath
+func (b *Bastion) AllProxy(keyPath string) string {
+	query := url.Values{"private-key": []string{keyPath}}
+	return fmt.Sprintf("ssh+socks5://%s@%s?%s", User, b.Addr(), query.Encode())
+}
+
+// Dial connects to the bastion itself
+func (b *Bastion) Dial() (*ssh.Client, error) {
+	key, err := ssh.ParsePrivateKey([]byte(b.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key for the bastion: [%v]", err)
+	}
+	return ssh.Dial("tcp", b.Addr(), &ssh.ClientConfig{
+		User:            User,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		Timeout:         dialTimeout,
+	})
+}
+
+// WaitUntilReachable retries connecting to the bastion until it accepts SSH connections, as a bastion that has
+// just been created takes a while to boot
+func (b *Bastion) WaitUntilReachable(attempts int, delay time.Duration) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		var client *ssh.Client
+		if client, err = b.Dial(); err == nil {
+			return client.Close()
+		}
+		time.Sleep(delay)
+	}
+	return fmt.Errorf("failed to connect to the bastion at %s: [%v]", b.Addr(), err)
+}
+
+// DialSSH connects to addr over SSH, through b when it isn't nil. The connection to the bastion is closed
+// once the connection to addr is
+func DialSSH(b *Bastion, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if b == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+
+	jump, err := b.Dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the bastion at %s: [%v]", b.Addr(), err)
+	}
+	conn, err := jump.Dial("tcp", addr)
+	if err != nil {
+		jump.Close()
+		return nil, fmt.Errorf("failed to connect to %s through the bastion: [%v]", addr, err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		jump.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(c, chans, reqs)
+	go func() {
+		client.Wait()
+		jump.Close()
+	}()
+	return client, nil
+}
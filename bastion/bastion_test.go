@@ -0,0 +1,38 @@
+package bastion
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+func TestFromOutputs(t *testing.T) {
+	outputs := &terraform.AWSOutputs{BastionPublicIP: terraform.MetadataStringValue{Value: "203.0.113.10"}}
+
+	b, err := FromOutputs(config.Config{}, outputs)
+	if err != nil || b != nil {
+		t.Errorf("FromOutputs() = %v, %v, want no bastion for a deployment without one", b, err)
+	}
+
+	b, err = FromOutputs(config.Config{Bastion: true, BastionPrivateKey: "key"}, outputs)
+	if err != nil {
+		t.Fatalf("FromOutputs() error = %v", err)
+	}
+	if b.Host != "203.0.113.10" || b.PrivateKey != "key" {
+		t.Errorf("FromOutputs() = %+v, want the bastion's address and key", b)
+	}
+
+	_, err = FromOutputs(config.Config{Bastion: true}, &terraform.AWSOutputs{})
+	if err == nil {
+		t.Error("FromOutputs() error = nil, want a missing address to be reported")
+	}
+}
+
+func TestAllProxy(t *testing.T) {
+	b := &Bastion{Host: "203.0.113.10"}
+	want := "ssh+socks5://ubuntu@203.0.113.10:22?private-key=%2Ftmp%2Fbastion_key"
+	if got := b.AllProxy("/tmp/bastion_key"); got != want {
+		t.Errorf("AllProxy() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package bosh
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/artifacts"
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
+	"github.com/EngineerBetter/control-tower/bosh/internal/workingdir"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/iaas"
+)
+
+// Artifacts lists every release, stemcell and CLI that deploying conf downloads, with the CLIs for
+// the given platforms, so that they can be downloaded ahead of a deploy without internet access
+func Artifacts(conf config.ConfigView, provider iaas.Provider, versionFile []byte, platforms ...string) ([]artifacts.Artifact, error) {
+	list, err := artifacts.FromVersionFile(versionFile, platforms...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := &recordingWorkingDir{files: map[string][]byte{}}
+	if err = saveFilesToWorkingDir(files, provider, nil, "", "", ""); err != nil {
+		return nil, err
+	}
+	var ops [][]byte
+	for _, contents := range files.files {
+		ops = append(ops, contents)
+	}
+	if len(conf.GetWorkerPools()) > 0 {
+		poolOps, err := workerPoolsOps(conf.GetWorkerPools(), workerAZs(conf.GetWorkerZones()), conf.GetARM64StemcellURL(), conf.GetARM64ReleaseURL(), conf.GetARM64ReleaseSHA1())
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, poolOps)
+	}
+	if conf.GetWindowsWorkerCount() > 0 {
+		windowsOps, err := windowsWorkersOps(conf.GetWindowsWorkerCount(), conf.GetWindowsStemcellVersion(), conf.GetWindowsWorkerReleaseURL(), conf.GetWindowsWorkerReleaseSHA1())
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, windowsOps)
+	}
+	for _, a := range artifacts.FromOps(ops...) {
+		if a.URL == osConfReleaseURL {
+			a.SHA1 = conf.GetOSConfReleaseSHA1()
+		}
+		list = append(list, a)
+	}
+	if conf.GetDBIAMAuth() {
+		list = append(list, rdsIAMProxySources...)
+	}
+	if conf.GetConcourseVersion() != "" {
+		list = append(list, artifacts.Artifact{URL: concourseReleaseURL(conf.GetConcourseVersion()), SHA1: conf.GetConcourseReleaseSHA1()})
+	}
+
+	stemcellURL, windowsStemcell := "", ""
+	switch provider.IAAS() {
+	case iaas.AWS:
+		stemcellURL, err = boshcli.AWSEnvironment{}.ConcourseStemcellURL()
+		windowsStemcell = awsWindowsStemcellURL
+	case iaas.GCP:
+		stemcellURL, err = boshcli.GCPEnvironment{}.ConcourseStemcellURL()
+		windowsStemcell = gcpWindowsStemcellURL
+	}
+	if err != nil {
+		return nil, err
+	}
+	list = append(list, artifacts.Artifact{URL: stemcellURL})
+	if hasARM64WorkerPool(conf.GetWorkerPools()) {
+		list = append(list, artifacts.Artifact{URL: conf.GetARM64StemcellURL()})
+	}
+	if conf.GetWindowsWorkerCount() > 0 {
+		list = append(list, artifacts.Artifact{URL: windowsStemcellURL(windowsStemcell, conf.GetWindowsStemcellVersion())})
+	}
+	return list, nil
+}
+
+// concourseReleaseURL is where bosh.io serves the given version of the Concourse release
+func concourseReleaseURL(version string) string {
+	return fmt.Sprintf("https://bosh.io/d/github.com/concourse/concourse-bosh-release?v=%s", version)
+}
+
+// mirroredWorkingDir points the releases in every file saved to the working directory at their downloads
+type mirroredWorkingDir struct {
+	workingdir.IClient
+	mirror artifacts.Mirror
+}
+
+func (w mirroredWorkingDir) SaveFileToWorkingDir(path string, contents []byte) (string, error) {
+	return w.IClient.SaveFileToWorkingDir(path, w.mirror.Rewrite(contents))
+}
+
+// recordingWorkingDir keeps the files saved to it in memory
+type recordingWorkingDir struct {
+	files map[string][]byte
+}
+
+func (w *recordingWorkingDir) SaveFileToWorkingDir(path string, contents []byte) (string, error) {
+	w.files[path] = contents
+	return path, nil
+}
+
+func (w *recordingWorkingDir) PathInWorkingDir(filename string) string {
+	return filename
+}
+
+func (w *recordingWorkingDir) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,32 @@
+#!/bin/bash
+set -eu
+
+# The stemcell doesn't ship GPU drivers. Install the NVIDIA server driver for the running kernel
+# before the worker starts
+export DEBIAN_FRONTEND=noninteractive
+if ! nvidia-smi > /dev/null 2>&1; then
+  apt-get update
+  apt-get install -y --no-install-recommends "linux-headers-$(uname -r)" \
+    nvidia-headless-535-server nvidia-utils-535-server
+  modprobe nvidia
+  nvidia-smi
+fi
+
+# The NVIDIA container runtime wraps runc with a hook that gives the GPU to containers started with
+# NVIDIA_VISIBLE_DEVICES, as CUDA images are
+if [ ! -x /usr/bin/nvidia-container-runtime ]; then
+  curl -fsSL https://nvidia.github.io/libnvidia-container/gpgkey \
+    | gpg --batch --yes --dearmor -o /usr/share/keyrings/nvidia-container-toolkit-keyring.gpg
+  curl -fsSL https://nvidia.github.io/libnvidia-container/stable/deb/nvidia-container-toolkit.list \
+    | sed 's#deb https://#deb [signed-by=/usr/share/keyrings/nvidia-container-toolkit-keyring.gpg] https://#g' \
+    > /etc/apt/sources.list.d/nvidia-container-toolkit.list
+  apt-get update
+  apt-get install -y --no-install-recommends nvidia-container-toolkit
+fi
+
+# Guardian reads this through CONCOURSE_GARDEN_CONFIG and starts containers with the NVIDIA runtime
+mkdir -p /var/vcap/data/gpu
+cat > /var/vcap/data/gpu/garden.ini <<'INI'
+[server]
+runtime-plugin = /usr/bin/nvidia-container-runtime
+INI
@@ -3,11 +3,8 @@ package bosh
 import (
 	"fmt"
 	"io"
-	"net"
-
-	"github.com/lib/pq"
-	"golang.org/x/crypto/ssh"
 
+	"github.com/EngineerBetter/control-tower/bastion"
 	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
 	"github.com/EngineerBetter/control-tower/bosh/internal/workingdir"
 	"github.com/EngineerBetter/control-tower/config"
@@ -34,38 +31,23 @@ func NewAWSClient(config config.ConfigView, outputs terraform.Outputs, workingdi
 	if err != nil {
 		return nil, fmt.Errorf("failed to get DirectorPublicIP from terraform outputs: [%v]", err)
 	}
-	addr := net.JoinHostPort(directorPublicIP, "22")
-	key, err := ssh.ParsePrivateKey([]byte(config.GetPrivateKey()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key for bosh: [%v]", err)
-	}
-	conf := &ssh.ClientConfig{
-		User:            "vcap",
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
-	}
-	var boshDBAddress, boshDBPort string
-
-	boshDBAddress, err = outputs.Get("BoshDBAddress")
+	boshDBAddress, err := outputs.Get("BoshDBAddress")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get BoshDBAddress from terraform outputs: [%v]", err)
 	}
-	boshDBPort, err = outputs.Get("BoshDBPort")
+	boshDBPort, err := outputs.Get("BoshDBPort")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get BoshDBPort from terraform outputs: [%v]", err)
 	}
 
-	db, err := newProxyOpener(addr, conf, &pq.Driver{},
-		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
-			config.GetRDSUsername(),
-			config.GetRDSPassword(),
-			boshDBAddress,
-			boshDBPort,
-			config.GetRDSDefaultDatabaseName(),
-		),
-	)
+	jump, err := bastion.FromOutputs(config, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newDirectorDBOpener(config, jump, directorPublicIP, boshDBAddress, boshDBPort)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create db proxyOpener: [%v]", err)
+		return nil, err
 	}
 
 	return &AWSClient{
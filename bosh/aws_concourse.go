@@ -8,11 +8,13 @@ import (
 	"strings"
 
 	"github.com/apparentlymart/go-cidr/cidr"
+
+	"github.com/EngineerBetter/control-tower/siem"
 )
 
 func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, error) {
 
-	err := saveFilesToWorkingDir(client.workingdir, client.provider, creds, client.config.GetConcourseCert(), client.config.GetConcourseKey())
+	err := saveFilesToWorkingDir(client.workingdir, client.provider, creds, client.config.GetConcourseCert(), client.config.GetConcourseKey(), client.config.GetConcourseVars())
 	if err != nil {
 		return creds, fmt.Errorf("failed saving files to working directory in deployConcourse: [%v]", err)
 	}
@@ -50,9 +52,9 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		"postgres_port":              boshDBPort,
 		"postgres_role":              client.config.GetRDSUsername(),
 		"postgres_password":          client.config.GetRDSPassword(),
-		"web_vm_type":                "concourse-web-" + client.config.GetConcourseWebSize(),
+		"web_vm_type":                webVMType(client.config),
 		"persistent_disk":            client.config.GetPersistentDiskSize(),
-		"worker_vm_type":             "concourse-" + client.config.GetConcourseWorkerSize(),
+		"worker_vm_type":             workerVMType(client.config),
 		"worker_count":               client.config.GetConcourseWorkerCount(),
 		"atc_eip":                    atcPublicIP,
 		"atc_encryption_key":         client.config.GetEncryptionKey(),
@@ -73,11 +75,22 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		"--vars-file",
 		client.workingdir.PathInWorkingDir(concourseGrafanaFilename),
 		"--vars-file",
-		client.workingdir.PathInWorkingDir(psqlCAFilename),
-		"--vars-file",
 		client.workingdir.PathInWorkingDir(concourseCertFilename),
 	}
 
+	if client.config.GetExternalDBURL() != "" {
+		vmap["postgres_ca_cert"] = client.config.GetExternalDBCACert()
+		externalDBNameVars(vmap, client.config)
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseExternalDBNamesFilename))
+	} else if client.config.GetDBIAMAuth() {
+		rdsIAMProxyVars(vmap, client.config, boshDBAddress, boshDBPort)
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseRDSIAMProxyFilename))
+	} else if client.config.GetDBCACert() != "" {
+		vmap["postgres_ca_cert"] = client.config.GetDBCACert()
+	} else {
+		flagFiles = append(flagFiles, "--vars-file", client.workingdir.PathInWorkingDir(psqlCAFilename))
+	}
+
 	if client.config.GetConcoursePassword() != "" {
 		vmap["atc_password"] = client.config.GetConcoursePassword()
 	}
@@ -113,14 +126,331 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseMicrosoftAuthFilename))
 	}
 
+	if client.config.IsOIDCAuthSet() {
+		vmap["oidc_issuer"] = client.config.GetOIDCIssuer()
+		vmap["oidc_client_id"] = client.config.GetOIDCClientID()
+		vmap["oidc_client_secret"] = client.config.GetOIDCClientSecret()
+		vmap["oidc_groups_claim"] = client.config.GetOIDCGroupsClaim()
+		vmap["oidc_username_claim"] = client.config.GetOIDCUsernameClaim()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseOIDCAuthFilename))
+	}
+
+	if client.config.IsOAuthAuthSet() {
+		vmap["oauth_auth_url"] = client.config.GetOAuthAuthURL()
+		vmap["oauth_token_url"] = client.config.GetOAuthTokenURL()
+		vmap["oauth_userinfo_url"] = client.config.GetOAuthUserinfoURL()
+		vmap["oauth_client_id"] = client.config.GetOAuthClientID()
+		vmap["oauth_client_secret"] = client.config.GetOAuthClientSecret()
+		vmap["oauth_scopes"] = client.config.GetOAuthScopes()
+		vmap["oauth_groups_key"] = client.config.GetOAuthGroupsKey()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseOAuthAuthFilename))
+	}
+
+	if client.config.IsSAMLAuthSet() {
+		samlVars, err := samlAuthVars(client.config.GetSAMLMetadataURL(), client.config.GetSAMLCACert(), client.config.GetSAMLUsernameAttr(), client.config.GetSAMLEmailAttr(), client.config.GetSAMLGroupsAttr())
+		if err != nil {
+			return creds, err
+		}
+		for k, v := range samlVars {
+			vmap[k] = v
+		}
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSAMLAuthFilename))
+	}
+
+	if client.config.IsVaultSet() {
+		vmap["vault_url"] = client.config.GetVaultURL()
+		vmap["vault_path_prefix"] = client.config.GetVaultPathPrefix()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseVaultFilename))
+
+		if client.config.GetVaultCACert() != "" {
+			vmap["vault_ca_cert"] = client.config.GetVaultCACert()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseVaultCACertFilename))
+		}
+		if client.config.GetVaultClientToken() != "" {
+			vmap["vault_client_token"] = client.config.GetVaultClientToken()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseVaultClientTokenFilename))
+		} else {
+			vmap["vault_auth_backend"] = client.config.GetVaultAuthBackend()
+			vmap["vault_auth_params"] = keyValues(client.config.GetVaultAuthParams())
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseVaultAuthBackendFilename))
+		}
+	}
+
+	if client.config.IsSecretsManagerSet() {
+		vmap["secretsmanager_region"] = client.config.GetRegion()
+		vmap["secretsmanager_pipeline_path"] = client.config.GetSecretsManagerPipelinePath()
+		vmap["secretsmanager_team_path"] = client.config.GetSecretsManagerTeamPath()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSecretsManagerFilename))
+	}
+
+	if client.config.IsSSMSet() {
+		vmap["ssm_region"] = client.config.GetRegion()
+		vmap["ssm_pipeline_path"] = client.config.GetSSMPipelinePath()
+		vmap["ssm_team_path"] = client.config.GetSSMTeamPath()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSSMFilename))
+	}
+
+	if client.config.IsLDAPAuthSet() {
+		vmap["ldap_host"] = client.config.GetLDAPHost()
+		vmap["ldap_bind_dn"] = client.config.GetLDAPBindDN()
+		vmap["ldap_bind_password"] = client.config.GetLDAPBindPassword()
+		vmap["ldap_user_search_base_dn"] = client.config.GetLDAPUserSearchBaseDN()
+		vmap["ldap_user_search_filter"] = client.config.GetLDAPUserSearchFilter()
+		vmap["ldap_user_search_username"] = client.config.GetLDAPUserSearchUsername()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseLDAPAuthFilename))
+
+		if client.config.GetLDAPGroupSearchBaseDN() != "" {
+			vmap["ldap_group_search_base_dn"] = client.config.GetLDAPGroupSearchBaseDN()
+			vmap["ldap_group_search_filter"] = client.config.GetLDAPGroupSearchFilter()
+			vmap["ldap_group_search_name_attr"] = client.config.GetLDAPGroupSearchNameAttr()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseLDAPGroupSearchFilename))
+		}
+		if client.config.GetLDAPCACert() != "" {
+			vmap["ldap_ca_cert"] = client.config.GetLDAPCACert()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseLDAPCACertFilename))
+		}
+	}
+
+	if client.config.IsCFAuthSet() {
+		vmap["cf_api_url"] = client.config.GetCFAuthAPIURL()
+		vmap["cf_client_id"] = client.config.GetCFAuthClientID()
+		vmap["cf_client_secret"] = client.config.GetCFAuthClientSecret()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseCFAuthFilename))
+
+		if client.config.GetCFAuthCACert() != "" {
+			vmap["cf_ca_cert"] = client.config.GetCFAuthCACert()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseCFAuthCACertFilename))
+		}
+		if client.config.IsMainCFAuthSet() {
+			vmap["main_cf_orgs"] = client.config.GetMainCFOrgs()
+			vmap["main_cf_spaces"] = client.config.GetMainCFSpaces()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseMainCFAuthFilename))
+		}
+	}
+
 	if client.config.IsSpot() {
 		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseEphemeralWorkersFilename))
 	}
 
-	if client.config.MetricsIsDisabled() {
+	if client.config.GetWorkerDrainTimeout() != "" {
+		vmap["worker_drain_timeout"] = client.config.GetWorkerDrainTimeout()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerDrainTimeoutFilename))
+	}
+
+	if client.config.GetWorkerRebalanceInterval() != "" {
+		vmap["worker_rebalance_interval"] = client.config.GetWorkerRebalanceInterval()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerRebalanceFilename))
+	}
+
+	if client.config.MetricsIsDisabled() || client.config.GetInfluxDbURL() != "" {
 		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseNoMetricsFilename))
 	}
 
+	if client.config.GetInfluxDbURL() != "" {
+		vmap["external_influxdb_url"] = client.config.GetInfluxDbURL()
+		vmap["external_influxdb_database"] = client.config.GetInfluxDbDatabase()
+		vmap["external_influxdb_username"] = client.config.GetInfluxDbUsername()
+		vmap["external_influxdb_password"] = client.config.GetInfluxDbPassword()
+		vmap["external_influxdb_batch_size"] = client.config.GetInfluxDbBatchSize()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseExternalInfluxDbFilename))
+	}
+
+	if len(client.config.GetContainerPlacementStrategies()) > 0 {
+		vmap["container_placement_strategies"] = client.config.GetContainerPlacementStrategies()
+		vmap["max_active_tasks_per_worker"] = client.config.GetMaxActiveTasksPerWorker()
+		vmap["max_active_containers_per_worker"] = client.config.GetMaxActiveContainersPerWorker()
+		vmap["max_active_volumes_per_worker"] = client.config.GetMaxActiveVolumesPerWorker()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseContainerPlacementFilename))
+	}
+
+	if client.config.GetDefaultTaskCPULimit() > 0 {
+		vmap["default_task_cpu_limit"] = client.config.GetDefaultTaskCPULimit()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseTaskCPULimitFilename))
+	}
+
+	if client.config.GetDefaultTaskMemoryLimit() != "" {
+		vmap["default_task_memory_limit"] = client.config.GetDefaultTaskMemoryLimit()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseTaskMemoryLimitFilename))
+	}
+
+	if client.config.GetInterceptIdleTimeout() != "" {
+		vmap["intercept_idle_timeout"] = client.config.GetInterceptIdleTimeout()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseInterceptTimeoutFilename))
+	}
+
+	if client.config.GetFailedGracePeriod() != "" {
+		vmap["failed_grace_period"] = client.config.GetFailedGracePeriod()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseFailedGracePeriodFilename))
+	}
+
+	if client.config.GetEnableP2PVolumeStreaming() {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseP2PVolumeStreamingFilename))
+	}
+
+	if client.config.GetStreamingCompression() != "" {
+		vmap["streaming_compression"] = client.config.GetStreamingCompression()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseStreamingCompressionFilename))
+	}
+
+	if client.config.GetUpgradeBatchSize() > 0 {
+		vmap["upgrade_batch_size"] = client.config.GetUpgradeBatchSize()
+		vmap["worker_update_watch_time"] = workerUpdateWatchTime(client.config.GetSoakMinutes())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerUpgradeBatchesFilename))
+	}
+
+	if client.config.GetWorkerRetireMaxInFlight() > 0 {
+		vmap["worker_retire_max_in_flight"] = client.config.GetWorkerRetireMaxInFlight()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerRetireInFlightFilename))
+	}
+
+	if len(client.config.GetConcourseWebEnv()) > 0 {
+		vmap["concourse_web_env"] = concourseEnv(client.config.GetConcourseWebEnv())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWebEnvFilename))
+	}
+
+	if len(client.config.GetConcourseWorkerEnv()) > 0 {
+		vmap["concourse_worker_env"] = concourseEnv(client.config.GetConcourseWorkerEnv())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerEnvFilename))
+	}
+
+	if client.config.GetExternalURL() != "" {
+		vmap["external_url"] = client.config.GetExternalURL()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseExternalURLFilename))
+	}
+
+	if client.config.GetConcourseVersion() != "" {
+		vmap["concourse_version"] = client.config.GetConcourseVersion()
+		vmap["concourse_release_sha1"] = client.config.GetConcourseReleaseSHA1()
+		vmap["concourse_release_url"] = concourseReleaseURL(client.config.GetConcourseVersion())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseVersionFilename))
+	}
+
+	if client.config.GetSyslogAddress() != "" {
+		vmap["syslog_address"] = client.config.GetSyslogAddress()
+		vmap["syslog_transport"] = client.config.GetSyslogTransport()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSyslogFilename))
+		if client.config.GetSyslogCACert() != "" {
+			vmap["syslog_ca_cert"] = client.config.GetSyslogCACert()
+			flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSyslogCACertFilename))
+		}
+	}
+
+	if client.config.GetOPAURL() != "" {
+		vmap["opa_url"] = opaDecisionURL(client.config.GetOPAURL(), client.config.GetOPAPolicyPath())
+		vmap["policy_check_actions"] = client.config.GetPolicyCheckActions()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concoursePolicyCheckFilename))
+	}
+
+	if len(client.config.GetAuditLogs()) > 0 {
+		auditLogVars(vmap, client.config.GetAuditLogs())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseAuditLogsFilename))
+	}
+
+	if siemEndpoint, err1 := siem.ParseEndpoint(client.config.GetSIEMEndpoint()); err1 == nil && siemEndpoint.IsSyslog() {
+		vmap["siem_address"] = siemEndpoint.Host
+		vmap["siem_port"] = siemEndpoint.Port
+		vmap["siem_transport"] = siemEndpoint.Transport
+		vmap["siem_tls"] = siemEndpoint.TLS
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseSIEMFilename))
+	}
+
+	if client.config.GetNewRelicAccountID() != "" {
+		vmap["newrelic_account_id"] = client.config.GetNewRelicAccountID()
+		vmap["newrelic_insert_key"] = client.config.GetNewRelicInsertKey()
+		vmap["newrelic_insights_api_url"] = client.config.GetNewRelicInsightsURL()
+		vmap["newrelic_service_prefix"] = client.config.GetNewRelicServicePrefix()
+		vmap["newrelic_batch_size"] = client.config.GetNewRelicBatchSize()
+		vmap["newrelic_batch_duration"] = client.config.GetNewRelicBatchDuration()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseNewRelicFilename))
+	}
+
+	if client.config.GetEnablePrometheusMetrics() {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concoursePrometheusMetricsFilename))
+	}
+
+	if client.config.GetConcourseWebCount() > 1 {
+		webStaticIPs, err := webStaticIPs(client.config.GetPrivateCIDR(), client.config.GetConcourseWebCount())
+		if err != nil {
+			return creds, err
+		}
+		vmap["web_count"] = client.config.GetConcourseWebCount()
+		vmap["web_static_ips"] = webStaticIPs
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWebInstancesFilename))
+	} else if client.config.GetPrivate() {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concoursePrivateWebFilename))
+	}
+
+	if len(client.config.GetMetricsScrapeAllowIPs()) > 0 {
+		vmap["metrics_scrape_allow_ips"] = client.config.GetMetricsScrapeAllowIPs()
+		vmap["metrics_scrape_password"] = client.config.GetMetricsScrapePassword()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseMetricsScrapeFilename))
+	}
+
+	if len(client.config.GetExternalWorkerAllowIPs()) > 0 {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseExternalWorkersFilename))
+	}
+
+	if client.config.IsManagedPrometheusSet() {
+		vmap["managed_prometheus_url"] = client.config.GetManagedPrometheusURL()
+		vmap["managed_prometheus_region"] = client.config.GetManagedPrometheusRegion()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseManagedPrometheusAWSFilename))
+	}
+
+	if client.config.GetWorkerCgroupVersion() != "" || len(client.config.GetWorkerSysctls()) > 0 {
+		vmap["worker_cgroup_version"] = client.config.GetWorkerCgroupVersion()
+		vmap["worker_sysctls"] = client.config.GetWorkerSysctls()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerKernelFilename))
+	}
+
+	if containerd := workerContainerdProperties(client.config); len(containerd) > 0 {
+		vmap["worker_containerd"] = containerd
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerContainerdFilename))
+	}
+
+	if len(client.config.GetWorkerZones()) > 0 {
+		vmap["worker_azs"] = workerAZs(client.config.GetWorkerZones())
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerZonesFilename))
+	}
+
+	if client.config.GetDedicatedWeb() {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseDedicatedWebFilename))
+	}
+
+	if client.config.GetDedicatedWorkers() {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseDedicatedWorkersFilename))
+	}
+
+	if len(client.config.GetWorkerPools()) > 0 {
+		workerPools, err := workerPoolsOps(client.config.GetWorkerPools(), workerAZs(client.config.GetWorkerZones()), client.config.GetARM64StemcellURL(), client.config.GetARM64ReleaseURL(), client.config.GetARM64ReleaseSHA1(), selectedWorkerOps(flagFiles)...)
+		if err != nil {
+			return creds, err
+		}
+		if _, err = client.workingdir.SaveFileToWorkingDir(concourseWorkerPoolsFilename, workerPools); err != nil {
+			return creds, err
+		}
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWorkerPoolsFilename))
+	}
+
+	if client.config.GetWindowsWorkerCount() > 0 {
+		windowsWorkers, err := windowsWorkersOps(client.config.GetWindowsWorkerCount(), client.config.GetWindowsStemcellVersion(), client.config.GetWindowsWorkerReleaseURL(), client.config.GetWindowsWorkerReleaseSHA1())
+		if err != nil {
+			return creds, err
+		}
+		if _, err = client.workingdir.SaveFileToWorkingDir(concourseWindowsWorkersFilename, windowsWorkers); err != nil {
+			return creds, err
+		}
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseWindowsWorkersFilename))
+	}
+
+	if client.config.GetConcourseVars() != "" {
+		flagFiles = append(flagFiles, "--vars-file", client.workingdir.PathInWorkingDir(concourseUserVarsFilename))
+	}
+
+	if UsesOSConf(client.config, client.provider.IAAS()) {
+		vmap["os_conf_release_sha1"] = client.config.GetOSConfReleaseSHA1()
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(osConfReleaseFilename))
+	}
+
 	t, err1 := client.buildTagsYaml(vmap["project"], "concourse")
 	if err1 != nil {
 		return creds, err
@@ -135,6 +465,13 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		return creds, fmt.Errorf("failed to retrieve director IP: [%v]", err)
 	}
 
+	if client.config.GetDBIAMAuth() {
+		err = uploadRDSIAMProxyRelease(client.boshCLI, client.workingdir, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), client.stdout)
+		if err != nil {
+			return creds, err
+		}
+	}
+
 	err = client.boshCLI.RunAuthenticatedCommand(
 		"deploy",
 		directorPublicIP,
@@ -8,9 +8,12 @@ import (
 	"io"
 	"net"
 	"net/url"
-	"strings"
 	"sync"
 
+	"github.com/EngineerBetter/control-tower/bastion"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/proxy"
 )
@@ -48,7 +51,7 @@ func (p *proxyOpener) Close() error {
 	return p.l.Close()
 }
 
-func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Driver, uri string) (Opener, error) {
+func newProxyOpener(dialJumpbox func() (*ssh.Client, error), d driver.Driver, uri string) (Opener, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
@@ -59,7 +62,7 @@ func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Drive
 	}
 	var startOnce sync.Once
 	f := func() {
-		p, err := ssh.Dial("tcp", jumpboxAddr, config)
+		p, err := dialJumpbox()
 		if err != nil {
 			return //TODO: handle
 		}
@@ -83,6 +86,38 @@ func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Drive
 	}, nil
 }
 
+// newDirectorDBOpener returns an Opener for the Concourse database, connecting via the director
+// as a jumpbox since the database is not publicly accessible. The director is reached through jump when
+// the deployment has a bastion. The director's private key is only needed once the database is opened, so
+// clients made from the runtime config, which leaves it out, can still talk to the director
+func newDirectorDBOpener(config config.ConfigView, jump *bastion.Bastion, directorPublicIP, dbAddress, dbPort string) (Opener, error) {
+	dialDirector := func() (*ssh.Client, error) {
+		key, err := ssh.ParsePrivateKey([]byte(config.GetPrivateKey()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key for bosh: [%v]", err)
+		}
+		conf := &ssh.ClientConfig{
+			User:            "vcap",
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		}
+		return bastion.DialSSH(jump, net.JoinHostPort(directorPublicIP, "22"), conf)
+	}
+	db, err := newProxyOpener(dialDirector, &pq.Driver{},
+		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
+			config.GetRDSUsername(),
+			config.GetRDSPassword(),
+			dbAddress,
+			dbPort,
+			config.GetRDSDefaultDatabaseName(),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db proxyOpener: [%v]", err)
+	}
+	return db, nil
+}
+
 type connectorFunc func(context.Context) (driver.Conn, error)
 
 func (f connectorFunc) Connect(ctx context.Context) (driver.Conn, error) {
@@ -114,18 +149,27 @@ func proxyConn(conn net.Conn, dialer proxy.Dialer, addr string) {
 }
 
 func (client *AWSClient) createDefaultDatabases() error {
-	db, err := client.db.Open(client.config.GetRDSDefaultDatabaseName())
+	conn, err := client.db.Open(client.config.GetRDSDefaultDatabaseName())
 	if err != nil {
 		return err
 	}
-	defer db.Close()
-	dbNames := []string{"concourse_atc", "uaa", "credhub"}
-	for _, dbName := range dbNames {
-		_, err := db.Exec("CREATE DATABASE " + dbName)
-		if err != nil && !strings.Contains(err.Error(),
-			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
+	defer conn.Close()
+	names := componentDatabaseNames(client.config)
+	if client.config.GetExternalDBURL() != "" {
+		if err = db.CheckExternal(conn, names); err != nil {
 			return err
 		}
 	}
+	if err = db.CreateComponentDatabases(conn, names); err != nil {
+		return err
+	}
+	if client.config.GetDBIAMAuth() {
+		return db.GrantIAMRole(conn, names)
+	}
 	return nil
 }
+
+// PreviewMigrations reports the ATC schema migrations the next deploy will run
+func (client *AWSClient) PreviewMigrations() (MigrationPreview, error) {
+	return previewMigrations(client.db, concourseDBName(client.config), client.config.GetConcourseVersion(), awsConcourseVersions)
+}
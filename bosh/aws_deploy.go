@@ -1,7 +1,9 @@
 package bosh
 
 import (
+	"bytes"
 	"net"
+	"strings"
 
 	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
 	"github.com/EngineerBetter/control-tower/db"
@@ -10,7 +12,11 @@ import (
 
 // Deploy implements deploy for AWS client
 func (client *AWSClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
-	state, creds, err = client.CreateEnv(state, creds, "")
+	if err = waitForBastion(client.config, client.outputs, client.stdout); err != nil {
+		return state, creds, err
+	}
+
+	state, creds, err = upgradeDirector(client.CreateEnv, state, creds, client.versionFile, client.stdout)
 	if err != nil {
 		return state, creds, err
 	}
@@ -33,6 +39,92 @@ func (client *AWSClient) Deploy(state, creds []byte, detach bool) (newState, new
 	return state, creds, err
 }
 
+// CancelDeploy cancels the running deploy of Concourse, leaving instances that were already updated in place
+func (client *AWSClient) CancelDeploy() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("cancel-tasks", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, "--type", "update_deployment", "--state", "processing")
+}
+
+// CloudCheck runs bosh cloud-check against Concourse, recreating VMs that are missing or have unresponsive
+// agents. Other problems it finds, such as missing disks, are skipped, as their resolutions may lose data
+func (client *AWSClient) CloudCheck() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("cloud-check", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, "--resolution", "recreate_vm")
+}
+
+// SSH runs command on a single Concourse instance, such as worker/<id>, through the director, and returns
+// what it printed
+func (client *AWSClient) SSH(name, command string) ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	err = client.boshCLI.RunAuthenticatedCommand("ssh", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, &output, name, "--results", "--command", command)
+	return output.Bytes(), err
+}
+
+// RecreateInstance runs bosh recreate against a single Concourse instance, such as worker/<id>, running its
+// drain script first
+func (client *AWSClient) RecreateInstance(name string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("recreate", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name)
+}
+
+// StopInstance runs bosh stop against a single Concourse instance, such as worker/<id>, running its drain
+// script and stopping its jobs while keeping its VM, so that it can still be investigated
+func (client *AWSClient) StopInstance(name string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("stop", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name)
+}
+
+// Logs runs bosh logs against a single Concourse instance, such as worker/<id>, downloading a tarball of
+// its job logs into dir
+func (client *AWSClient) Logs(name, dir string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("logs", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name, "--dir", dir)
+}
+
+// ScaleWorkers deploys the Concourse manifest as deployed with the given number of workers, leaving the director
+// and every other instance group as they are
+func (client *AWSClient) ScaleWorkers(instances int) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	manifest, err := client.Manifest()
+	if err != nil {
+		return err
+	}
+	return scaleWorkers(client.boshCLI, client.workingdir, client.config, directorPublicIP, client.stdout, manifest, instances)
+}
+
+// Manifest returns the Concourse manifest as deployed, with the values of its variables filled in
+func (client *AWSClient) Manifest() ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	var manifest bytes.Buffer
+	err = client.boshCLI.RunAuthenticatedCommand("manifest", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, &manifest)
+	return manifest.Bytes(), err
+}
+
 // Locks implements locks for AWS client
 func (client *AWSClient) Locks() ([]byte, error) {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
@@ -86,7 +178,8 @@ func (client *AWSClient) CreateEnv(state, creds []byte, customOps string) (newSt
 	if err1 != nil {
 		return state, creds, err1
 	}
-	boshDBAddress, err1 := client.outputs.Get("BoshDBAddress")
+	// The director's database stays on the instance it was on when restore-db moved Concourse's onto another
+	directorDBAddress, err1 := client.outputs.Get("DirectorDBAddress")
 	if err1 != nil {
 		return state, creds, err1
 	}
@@ -94,6 +187,12 @@ func (client *AWSClient) CreateEnv(state, creds []byte, customOps string) (newSt
 	if err1 != nil {
 		return state, creds, err1
 	}
+	dbCACert := db.RDSRootCert
+	if client.config.GetExternalDBURL() != "" {
+		dbCACert = client.config.GetExternalDBCACert()
+	} else if client.config.GetDBCACert() != "" {
+		dbCACert = client.config.GetDBCACert()
+	}
 	blobstoreUserAccessKeyID, err1 := client.outputs.Get("BlobstoreUserAccessKeyID")
 	if err1 != nil {
 		return state, creds, err1
@@ -142,11 +241,12 @@ func (client *AWSClient) CreateEnv(state, creds []byte, customOps string) (newSt
 		PublicSubnetID:       publicSubnetID,
 		PrivateSubnetID:      privateSubnetID,
 		ExternalIP:           directorPublicIP,
+		Private:              client.config.GetPrivate(),
 		ATCSecurityGroup:     atcSecurityGroupID,
 		VMSecurityGroup:      vmSecurityGroupID,
 		BlobstoreBucket:      blobstoreBucket,
-		DBCACert:             db.RDSRootCert,
-		DBHost:               boshDBAddress,
+		DBCACert:             dbCACert,
+		DBHost:               directorDBAddress,
 		DBName:               client.config.GetRDSDefaultDatabaseName(),
 		DBPassword:           client.config.GetRDSPassword(),
 		DBPort:               boshDbPort,
@@ -155,6 +255,7 @@ func (client *AWSClient) CreateEnv(state, creds []byte, customOps string) (newSt
 		S3AWSSecretAccessKey: blobstoreSecretAccessKey,
 		Spot:                 client.config.IsSpot(),
 		WorkerType:           client.config.GetWorkerType(),
+		WorkerIMDSHopLimit:   client.config.GetWorkerIMDSHopLimit(),
 		CustomOperations:     customOps,
 		VersionFile:          client.versionFile,
 	}, client.config.GetDirectorPassword(), client.config.GetDirectorCert(), client.config.GetDirectorKey(), client.config.GetDirectorCACert(), tags)
@@ -192,6 +293,22 @@ func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
 	if err != nil {
 		return err
 	}
+	webInstanceProfile, err := client.outputs.Get("WebInstanceProfile")
+	if err != nil {
+		return err
+	}
+	webTargetGroups, err := client.outputs.Get("WebTargetGroups")
+	if err != nil {
+		return err
+	}
+	workerSubnetIDs, err := client.outputs.Get("WorkerSubnetIDs")
+	if err != nil {
+		return err
+	}
+	workerZones, err := cloudConfigWorkerZones(client.config.GetWorkerZones(), workerSubnetIDs)
+	if err != nil {
+		return err
+	}
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
 		return err
@@ -230,6 +347,15 @@ func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
 	if err != nil {
 		return err
 	}
+	privateCIDRStatic, err := privateCIDRStatic(privateCIDR, client.config.GetConcourseWebCount())
+	if err != nil {
+		return err
+	}
+
+	var webTargetGroupNames []string
+	if webTargetGroups != "" {
+		webTargetGroupNames = strings.Split(webTargetGroups, ",")
+	}
 
 	return bosh.UpdateCloudConfig(boshcli.AWSEnvironment{
 		AZ:                  client.config.GetAvailabilityZone(),
@@ -240,6 +366,16 @@ func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		Spot:                client.config.IsSpot(),
 		ExternalIP:          directorPublicIP,
 		WorkerType:          client.config.GetWorkerType(),
+		WebInstanceProfile:  webInstanceProfile,
+		WebTargetGroups:     webTargetGroupNames,
+		WorkerIMDSHopLimit:  client.config.GetWorkerIMDSHopLimit(),
+		WorkerSpotBid:       client.config.GetWorkerSpotBid(),
+		WorkerPoolTypes:     workerPoolInstanceTypes(client.config.GetWorkerPools()),
+		WindowsWorkerType:   windowsWorkerType(client.config),
+		WebInstanceType:     client.config.GetWebInstanceType(),
+		WorkerInstanceType:  client.config.GetWorkerInstanceType(),
+		WorkerDiskSizeGB:    client.config.GetWorkerDiskSizeGB(),
+		WorkerDiskType:      client.config.GetWorkerDiskType(),
 		PublicCIDR:          publicCIDR,
 		PublicCIDRGateway:   publicCIDRGateway,
 		PublicCIDRStatic:    publicCIDRStatic,
@@ -247,14 +383,34 @@ func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		PrivateCIDR:         privateCIDR,
 		PrivateCIDRGateway:  privateCIDRGateway,
 		PrivateCIDRReserved: privateCIDRReserved,
+		PrivateCIDRStatic:   privateCIDRStatic,
+		WorkerZones:         workerZones,
+		Dedicated:           client.config.GetDedicatedWeb() || client.config.GetDedicatedWorkers(),
 	}, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert())
 }
+
+// awsWindowsStemcellURL is the Windows Server 2019 stemcell on bosh.io, for Windows workers, which is uploaded at --windows-stemcell-version
+const awsWindowsStemcellURL = "https://bosh.io/d/stemcells/bosh-aws-xen-hvm-windows2019-go_agent"
+
 func (client *AWSClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
 		return err
 	}
-	return bosh.UploadConcourseStemcell(boshcli.AWSEnvironment{
+	err = bosh.UploadConcourseStemcell(boshcli.AWSEnvironment{
 		ExternalIP: directorPublicIP,
 	}, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert())
+	if err != nil {
+		return err
+	}
+	if hasARM64WorkerPool(client.config.GetWorkerPools()) {
+		err = bosh.RunAuthenticatedCommand("upload-stemcell", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, client.config.GetARM64StemcellURL())
+		if err != nil {
+			return err
+		}
+	}
+	if client.config.GetWindowsWorkerCount() > 0 {
+		return bosh.RunAuthenticatedCommand("upload-stemcell", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, windowsStemcellURL(awsWindowsStemcellURL, client.config.GetWindowsStemcellVersion()))
+	}
+	return nil
 }
@@ -8,6 +8,16 @@ import (
 )
 
 type FakeIClient struct {
+	CancelDeployStub        func() error
+	cancelDeployMutex       sync.RWMutex
+	cancelDeployArgsForCall []struct {
+	}
+	cancelDeployReturns struct {
+		result1 error
+	}
+	cancelDeployReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CleanupStub        func() error
 	cleanupMutex       sync.RWMutex
 	cleanupArgsForCall []struct {
@@ -18,6 +28,16 @@ type FakeIClient struct {
 	cleanupReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CloudCheckStub        func() error
+	cloudCheckMutex       sync.RWMutex
+	cloudCheckArgsForCall []struct {
+	}
+	cloudCheckReturns struct {
+		result1 error
+	}
+	cloudCheckReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CreateEnvStub        func([]byte, []byte, string) ([]byte, []byte, error)
 	createEnvMutex       sync.RWMutex
 	createEnvArgsForCall []struct {
@@ -76,6 +96,42 @@ type FakeIClient struct {
 		result1 []byte
 		result2 error
 	}
+	LogsStub        func(string, string) error
+	logsMutex       sync.RWMutex
+	logsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	logsReturns struct {
+		result1 error
+	}
+	logsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ManifestStub        func() ([]byte, error)
+	manifestMutex       sync.RWMutex
+	manifestArgsForCall []struct {
+	}
+	manifestReturns struct {
+		result1 []byte
+		result2 error
+	}
+	manifestReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	PreviewMigrationsStub        func() (bosh.MigrationPreview, error)
+	previewMigrationsMutex       sync.RWMutex
+	previewMigrationsArgsForCall []struct {
+	}
+	previewMigrationsReturns struct {
+		result1 bosh.MigrationPreview
+		result2 error
+	}
+	previewMigrationsReturnsOnCall map[int]struct {
+		result1 bosh.MigrationPreview
+		result2 error
+	}
 	RecreateStub        func() error
 	recreateMutex       sync.RWMutex
 	recreateArgsForCall []struct {
@@ -86,10 +142,110 @@ type FakeIClient struct {
 	recreateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RecreateInstanceStub        func(string) error
+	recreateInstanceMutex       sync.RWMutex
+	recreateInstanceArgsForCall []struct {
+		arg1 string
+	}
+	recreateInstanceReturns struct {
+		result1 error
+	}
+	recreateInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SSHStub        func(string, string) ([]byte, error)
+	sSHMutex       sync.RWMutex
+	sSHArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	sSHReturns struct {
+		result1 []byte
+		result2 error
+	}
+	sSHReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	ScaleWorkersStub        func(int) error
+	scaleWorkersMutex       sync.RWMutex
+	scaleWorkersArgsForCall []struct {
+		arg1 int
+	}
+	scaleWorkersReturns struct {
+		result1 error
+	}
+	scaleWorkersReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StopInstanceStub        func(string) error
+	stopInstanceMutex       sync.RWMutex
+	stopInstanceArgsForCall []struct {
+		arg1 string
+	}
+	stopInstanceReturns struct {
+		result1 error
+	}
+	stopInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeIClient) CancelDeploy() error {
+	fake.cancelDeployMutex.Lock()
+	ret, specificReturn := fake.cancelDeployReturnsOnCall[len(fake.cancelDeployArgsForCall)]
+	fake.cancelDeployArgsForCall = append(fake.cancelDeployArgsForCall, struct {
+	}{})
+	stub := fake.CancelDeployStub
+	fakeReturns := fake.cancelDeployReturns
+	fake.recordInvocation("CancelDeploy", []interface{}{})
+	fake.cancelDeployMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) CancelDeployCallCount() int {
+	fake.cancelDeployMutex.RLock()
+	defer fake.cancelDeployMutex.RUnlock()
+	return len(fake.cancelDeployArgsForCall)
+}
+
+func (fake *FakeIClient) CancelDeployCalls(stub func() error) {
+	fake.cancelDeployMutex.Lock()
+	defer fake.cancelDeployMutex.Unlock()
+	fake.CancelDeployStub = stub
+}
+
+func (fake *FakeIClient) CancelDeployReturns(result1 error) {
+	fake.cancelDeployMutex.Lock()
+	defer fake.cancelDeployMutex.Unlock()
+	fake.CancelDeployStub = nil
+	fake.cancelDeployReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) CancelDeployReturnsOnCall(i int, result1 error) {
+	fake.cancelDeployMutex.Lock()
+	defer fake.cancelDeployMutex.Unlock()
+	fake.CancelDeployStub = nil
+	if fake.cancelDeployReturnsOnCall == nil {
+		fake.cancelDeployReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelDeployReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Cleanup() error {
 	fake.cleanupMutex.Lock()
 	ret, specificReturn := fake.cleanupReturnsOnCall[len(fake.cleanupArgsForCall)]
@@ -143,6 +299,59 @@ func (fake *FakeIClient) CleanupReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) CloudCheck() error {
+	fake.cloudCheckMutex.Lock()
+	ret, specificReturn := fake.cloudCheckReturnsOnCall[len(fake.cloudCheckArgsForCall)]
+	fake.cloudCheckArgsForCall = append(fake.cloudCheckArgsForCall, struct {
+	}{})
+	stub := fake.CloudCheckStub
+	fakeReturns := fake.cloudCheckReturns
+	fake.recordInvocation("CloudCheck", []interface{}{})
+	fake.cloudCheckMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) CloudCheckCallCount() int {
+	fake.cloudCheckMutex.RLock()
+	defer fake.cloudCheckMutex.RUnlock()
+	return len(fake.cloudCheckArgsForCall)
+}
+
+func (fake *FakeIClient) CloudCheckCalls(stub func() error) {
+	fake.cloudCheckMutex.Lock()
+	defer fake.cloudCheckMutex.Unlock()
+	fake.CloudCheckStub = stub
+}
+
+func (fake *FakeIClient) CloudCheckReturns(result1 error) {
+	fake.cloudCheckMutex.Lock()
+	defer fake.cloudCheckMutex.Unlock()
+	fake.CloudCheckStub = nil
+	fake.cloudCheckReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) CloudCheckReturnsOnCall(i int, result1 error) {
+	fake.cloudCheckMutex.Lock()
+	defer fake.cloudCheckMutex.Unlock()
+	fake.CloudCheckStub = nil
+	if fake.cloudCheckReturnsOnCall == nil {
+		fake.cloudCheckReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cloudCheckReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) CreateEnv(arg1 []byte, arg2 []byte, arg3 string) ([]byte, []byte, error) {
 	var arg1Copy []byte
 	if arg1 != nil {
@@ -413,6 +622,180 @@ func (fake *FakeIClient) LocksReturnsOnCall(i int, result1 []byte, result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeIClient) Logs(arg1 string, arg2 string) error {
+	fake.logsMutex.Lock()
+	ret, specificReturn := fake.logsReturnsOnCall[len(fake.logsArgsForCall)]
+	fake.logsArgsForCall = append(fake.logsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LogsStub
+	fakeReturns := fake.logsReturns
+	fake.recordInvocation("Logs", []interface{}{arg1, arg2})
+	fake.logsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) LogsCallCount() int {
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
+	return len(fake.logsArgsForCall)
+}
+
+func (fake *FakeIClient) LogsCalls(stub func(string, string) error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = stub
+}
+
+func (fake *FakeIClient) LogsArgsForCall(i int) (string, string) {
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
+	argsForCall := fake.logsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIClient) LogsReturns(result1 error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = nil
+	fake.logsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) LogsReturnsOnCall(i int, result1 error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = nil
+	if fake.logsReturnsOnCall == nil {
+		fake.logsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.logsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) Manifest() ([]byte, error) {
+	fake.manifestMutex.Lock()
+	ret, specificReturn := fake.manifestReturnsOnCall[len(fake.manifestArgsForCall)]
+	fake.manifestArgsForCall = append(fake.manifestArgsForCall, struct {
+	}{})
+	stub := fake.ManifestStub
+	fakeReturns := fake.manifestReturns
+	fake.recordInvocation("Manifest", []interface{}{})
+	fake.manifestMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) ManifestCallCount() int {
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	return len(fake.manifestArgsForCall)
+}
+
+func (fake *FakeIClient) ManifestCalls(stub func() ([]byte, error)) {
+	fake.manifestMutex.Lock()
+	defer fake.manifestMutex.Unlock()
+	fake.ManifestStub = stub
+}
+
+func (fake *FakeIClient) ManifestReturns(result1 []byte, result2 error) {
+	fake.manifestMutex.Lock()
+	defer fake.manifestMutex.Unlock()
+	fake.ManifestStub = nil
+	fake.manifestReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) ManifestReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.manifestMutex.Lock()
+	defer fake.manifestMutex.Unlock()
+	fake.ManifestStub = nil
+	if fake.manifestReturnsOnCall == nil {
+		fake.manifestReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.manifestReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) PreviewMigrations() (bosh.MigrationPreview, error) {
+	fake.previewMigrationsMutex.Lock()
+	ret, specificReturn := fake.previewMigrationsReturnsOnCall[len(fake.previewMigrationsArgsForCall)]
+	fake.previewMigrationsArgsForCall = append(fake.previewMigrationsArgsForCall, struct {
+	}{})
+	stub := fake.PreviewMigrationsStub
+	fakeReturns := fake.previewMigrationsReturns
+	fake.recordInvocation("PreviewMigrations", []interface{}{})
+	fake.previewMigrationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) PreviewMigrationsCallCount() int {
+	fake.previewMigrationsMutex.RLock()
+	defer fake.previewMigrationsMutex.RUnlock()
+	return len(fake.previewMigrationsArgsForCall)
+}
+
+func (fake *FakeIClient) PreviewMigrationsCalls(stub func() (bosh.MigrationPreview, error)) {
+	fake.previewMigrationsMutex.Lock()
+	defer fake.previewMigrationsMutex.Unlock()
+	fake.PreviewMigrationsStub = stub
+}
+
+func (fake *FakeIClient) PreviewMigrationsReturns(result1 bosh.MigrationPreview, result2 error) {
+	fake.previewMigrationsMutex.Lock()
+	defer fake.previewMigrationsMutex.Unlock()
+	fake.PreviewMigrationsStub = nil
+	fake.previewMigrationsReturns = struct {
+		result1 bosh.MigrationPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) PreviewMigrationsReturnsOnCall(i int, result1 bosh.MigrationPreview, result2 error) {
+	fake.previewMigrationsMutex.Lock()
+	defer fake.previewMigrationsMutex.Unlock()
+	fake.PreviewMigrationsStub = nil
+	if fake.previewMigrationsReturnsOnCall == nil {
+		fake.previewMigrationsReturnsOnCall = make(map[int]struct {
+			result1 bosh.MigrationPreview
+			result2 error
+		})
+	}
+	fake.previewMigrationsReturnsOnCall[i] = struct {
+		result1 bosh.MigrationPreview
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Recreate() error {
 	fake.recreateMutex.Lock()
 	ret, specificReturn := fake.recreateReturnsOnCall[len(fake.recreateArgsForCall)]
@@ -466,11 +849,263 @@ func (fake *FakeIClient) RecreateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) RecreateInstance(arg1 string) error {
+	fake.recreateInstanceMutex.Lock()
+	ret, specificReturn := fake.recreateInstanceReturnsOnCall[len(fake.recreateInstanceArgsForCall)]
+	fake.recreateInstanceArgsForCall = append(fake.recreateInstanceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RecreateInstanceStub
+	fakeReturns := fake.recreateInstanceReturns
+	fake.recordInvocation("RecreateInstance", []interface{}{arg1})
+	fake.recreateInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) RecreateInstanceCallCount() int {
+	fake.recreateInstanceMutex.RLock()
+	defer fake.recreateInstanceMutex.RUnlock()
+	return len(fake.recreateInstanceArgsForCall)
+}
+
+func (fake *FakeIClient) RecreateInstanceCalls(stub func(string) error) {
+	fake.recreateInstanceMutex.Lock()
+	defer fake.recreateInstanceMutex.Unlock()
+	fake.RecreateInstanceStub = stub
+}
+
+func (fake *FakeIClient) RecreateInstanceArgsForCall(i int) string {
+	fake.recreateInstanceMutex.RLock()
+	defer fake.recreateInstanceMutex.RUnlock()
+	argsForCall := fake.recreateInstanceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) RecreateInstanceReturns(result1 error) {
+	fake.recreateInstanceMutex.Lock()
+	defer fake.recreateInstanceMutex.Unlock()
+	fake.RecreateInstanceStub = nil
+	fake.recreateInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) RecreateInstanceReturnsOnCall(i int, result1 error) {
+	fake.recreateInstanceMutex.Lock()
+	defer fake.recreateInstanceMutex.Unlock()
+	fake.RecreateInstanceStub = nil
+	if fake.recreateInstanceReturnsOnCall == nil {
+		fake.recreateInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recreateInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) SSH(arg1 string, arg2 string) ([]byte, error) {
+	fake.sSHMutex.Lock()
+	ret, specificReturn := fake.sSHReturnsOnCall[len(fake.sSHArgsForCall)]
+	fake.sSHArgsForCall = append(fake.sSHArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.SSHStub
+	fakeReturns := fake.sSHReturns
+	fake.recordInvocation("SSH", []interface{}{arg1, arg2})
+	fake.sSHMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) SSHCallCount() int {
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
+	return len(fake.sSHArgsForCall)
+}
+
+func (fake *FakeIClient) SSHCalls(stub func(string, string) ([]byte, error)) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = stub
+}
+
+func (fake *FakeIClient) SSHArgsForCall(i int) (string, string) {
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
+	argsForCall := fake.sSHArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIClient) SSHReturns(result1 []byte, result2 error) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = nil
+	fake.sSHReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) SSHReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = nil
+	if fake.sSHReturnsOnCall == nil {
+		fake.sSHReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.sSHReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) ScaleWorkers(arg1 int) error {
+	fake.scaleWorkersMutex.Lock()
+	ret, specificReturn := fake.scaleWorkersReturnsOnCall[len(fake.scaleWorkersArgsForCall)]
+	fake.scaleWorkersArgsForCall = append(fake.scaleWorkersArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.ScaleWorkersStub
+	fakeReturns := fake.scaleWorkersReturns
+	fake.recordInvocation("ScaleWorkers", []interface{}{arg1})
+	fake.scaleWorkersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) ScaleWorkersCallCount() int {
+	fake.scaleWorkersMutex.RLock()
+	defer fake.scaleWorkersMutex.RUnlock()
+	return len(fake.scaleWorkersArgsForCall)
+}
+
+func (fake *FakeIClient) ScaleWorkersCalls(stub func(int) error) {
+	fake.scaleWorkersMutex.Lock()
+	defer fake.scaleWorkersMutex.Unlock()
+	fake.ScaleWorkersStub = stub
+}
+
+func (fake *FakeIClient) ScaleWorkersArgsForCall(i int) int {
+	fake.scaleWorkersMutex.RLock()
+	defer fake.scaleWorkersMutex.RUnlock()
+	argsForCall := fake.scaleWorkersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) ScaleWorkersReturns(result1 error) {
+	fake.scaleWorkersMutex.Lock()
+	defer fake.scaleWorkersMutex.Unlock()
+	fake.ScaleWorkersStub = nil
+	fake.scaleWorkersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) ScaleWorkersReturnsOnCall(i int, result1 error) {
+	fake.scaleWorkersMutex.Lock()
+	defer fake.scaleWorkersMutex.Unlock()
+	fake.ScaleWorkersStub = nil
+	if fake.scaleWorkersReturnsOnCall == nil {
+		fake.scaleWorkersReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.scaleWorkersReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) StopInstance(arg1 string) error {
+	fake.stopInstanceMutex.Lock()
+	ret, specificReturn := fake.stopInstanceReturnsOnCall[len(fake.stopInstanceArgsForCall)]
+	fake.stopInstanceArgsForCall = append(fake.stopInstanceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StopInstanceStub
+	fakeReturns := fake.stopInstanceReturns
+	fake.recordInvocation("StopInstance", []interface{}{arg1})
+	fake.stopInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) StopInstanceCallCount() int {
+	fake.stopInstanceMutex.RLock()
+	defer fake.stopInstanceMutex.RUnlock()
+	return len(fake.stopInstanceArgsForCall)
+}
+
+func (fake *FakeIClient) StopInstanceCalls(stub func(string) error) {
+	fake.stopInstanceMutex.Lock()
+	defer fake.stopInstanceMutex.Unlock()
+	fake.StopInstanceStub = stub
+}
+
+func (fake *FakeIClient) StopInstanceArgsForCall(i int) string {
+	fake.stopInstanceMutex.RLock()
+	defer fake.stopInstanceMutex.RUnlock()
+	argsForCall := fake.stopInstanceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) StopInstanceReturns(result1 error) {
+	fake.stopInstanceMutex.Lock()
+	defer fake.stopInstanceMutex.Unlock()
+	fake.StopInstanceStub = nil
+	fake.stopInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) StopInstanceReturnsOnCall(i int, result1 error) {
+	fake.stopInstanceMutex.Lock()
+	defer fake.stopInstanceMutex.Unlock()
+	fake.StopInstanceStub = nil
+	if fake.stopInstanceReturnsOnCall == nil {
+		fake.stopInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.stopInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.cancelDeployMutex.RLock()
+	defer fake.cancelDeployMutex.RUnlock()
 	fake.cleanupMutex.RLock()
 	defer fake.cleanupMutex.RUnlock()
+	fake.cloudCheckMutex.RLock()
+	defer fake.cloudCheckMutex.RUnlock()
 	fake.createEnvMutex.RLock()
 	defer fake.createEnvMutex.RUnlock()
 	fake.deployMutex.RLock()
@@ -479,8 +1114,22 @@ func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	defer fake.instancesMutex.RUnlock()
 	fake.locksMutex.RLock()
 	defer fake.locksMutex.RUnlock()
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
+	fake.manifestMutex.RLock()
+	defer fake.manifestMutex.RUnlock()
+	fake.previewMigrationsMutex.RLock()
+	defer fake.previewMigrationsMutex.RUnlock()
 	fake.recreateMutex.RLock()
 	defer fake.recreateMutex.RUnlock()
+	fake.recreateInstanceMutex.RLock()
+	defer fake.recreateInstanceMutex.RUnlock()
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
+	fake.scaleWorkersMutex.RLock()
+	defer fake.scaleWorkersMutex.RUnlock()
+	fake.stopInstanceMutex.RLock()
+	defer fake.stopInstanceMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"time"
 
+	"github.com/EngineerBetter/control-tower/bastion"
 	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
 	"github.com/EngineerBetter/control-tower/bosh/internal/workingdir"
 	"github.com/EngineerBetter/control-tower/config"
@@ -25,6 +27,14 @@ const StateFilename = "director-state.json"
 // CredsFilename is default name for bosh-init creds file
 const CredsFilename = "director-creds.yml"
 
+// bastionKeyFilename is where the bastion's private key is saved for the bosh CLI to log into the bastion with
+const bastionKeyFilename = "bastion_key"
+
+const (
+	bastionAttempts   = 30
+	bastionRetryDelay = 10 * time.Second
+)
+
 // IClient is a client for performing bosh-init commands
 //
 //counterfeiter:generate . IClient
@@ -34,25 +44,64 @@ type IClient interface {
 	Instances() ([]Instance, error)
 	CreateEnv([]byte, []byte, string) ([]byte, []byte, error)
 	Recreate() error
+	RecreateInstance(name string) error
+	StopInstance(name string) error
+	Logs(name, dir string) error
+	ScaleWorkers(instances int) error
+	SSH(name, command string) ([]byte, error)
+	CancelDeploy() error
+	CloudCheck() error
+	Manifest() ([]byte, error)
 	Locks() ([]byte, error)
+	PreviewMigrations() (MigrationPreview, error)
 }
 
-// Instance represents a vm deployed by BOSH
+// Instance represents a vm deployed by BOSH, along with its vitals
 type Instance struct {
-	Name  string
-	IP    string
-	State string
+	Name           string
+	IP             string
+	State          string
+	Uptime         string
+	CPU            string
+	Memory         string
+	EphemeralDisk  string
+	PersistentDisk string
+	// ExpectedState is the state BOSH keeps the instance in, which is stopped or detached when it
+	// has been stopped deliberately
+	ExpectedState string
 }
 
 // ClientFactory creates a new IClient
 type ClientFactory func(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (IClient, error)
 
+// CommandOptions controls the timeouts and retries applied to bosh commands run against the director
+type CommandOptions = boshcli.Options
+
+// CommandPolicy is the timeout and retry behaviour of a single bosh command
+type CommandPolicy = boshcli.Policy
+
 // New returns an IAAS specific implementation of BOSH client
 func New(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (IClient, error) {
-	workingdir, err := workingdir.New()
+	return newClient(config, outputs, stdout, stderr, provider, versionFile, CommandOptions{})
+}
+
+// NewWithCommandOptions returns a ClientFactory whose clients apply options to bosh commands
+func NewWithCommandOptions(options CommandOptions) ClientFactory {
+	return func(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (IClient, error) {
+		return newClient(config, outputs, stdout, stderr, provider, versionFile, options)
+	}
+}
+
+func newClient(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte, options CommandOptions) (IClient, error) {
+	dir, err := workingdir.New()
 	if err != nil {
 		return nil, err
 	}
+	var workingdir workingdir.IClient = dir
+	if len(options.Mirror) > 0 {
+		versionFile = options.Mirror.Rewrite(versionFile)
+		workingdir = mirroredWorkingDir{IClient: dir, mirror: options.Mirror}
+	}
 
 	var binaries map[string]util.BinaryPaths
 
@@ -66,7 +115,19 @@ func New(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.
 		return nil, fmt.Errorf("failed to determine BOSH CLI path: [%v]", err)
 	}
 
-	boshCLI := boshcli.New(boshCLIPath, exec.Command)
+	jump, err := bastion.FromOutputs(config, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if jump != nil {
+		keyPath, err := workingdir.SaveFileToWorkingDir(bastionKeyFilename, []byte(jump.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to save the bastion's private key: [%v]", err)
+		}
+		options.AllProxy = jump.AllProxy(keyPath)
+	}
+
+	boshCLI := boshcli.NewWithOptions(boshCLIPath, exec.Command, options)
 
 	switch provider.IAAS() {
 	case iaas.AWS:
@@ -77,6 +138,17 @@ func New(config config.ConfigView, outputs terraform.Outputs, stdout, stderr io.
 	return nil, fmt.Errorf("IAAS not supported: %s", provider.IAAS())
 }
 
+// waitForBastion waits for the deployment's bastion, when it has one, to accept SSH connections, as the director
+// is only reachable through it and a bastion that terraform has just created may still be booting
+func waitForBastion(config config.ConfigView, outputs terraform.Outputs, stdout io.Writer) error {
+	jump, err := bastion.FromOutputs(config, outputs)
+	if err != nil || jump == nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Waiting for the bastion at %s to accept SSH connections\n", jump.Host)
+	return jump.WaitUntilReachable(bastionAttempts, bastionRetryDelay)
+}
+
 func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error) {
 	output := new(bytes.Buffer)
 
@@ -88,6 +160,8 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 		false,
 		output,
 		"--json",
+		"--vitals",
+		"--details",
 	); err != nil {
 		return nil, fmt.Errorf("Error [%s] running `bosh instances`. stdout: [%s]", err, output.String())
 	}
@@ -95,9 +169,15 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 	jsonOutput := struct {
 		Tables []struct {
 			Rows []struct {
-				Instance     string `json:"instance"`
-				IPs          string `json:"ips"`
-				ProcessState string `json:"process_state"`
+				Instance            string `json:"instance"`
+				IPs                 string `json:"ips"`
+				ProcessState        string `json:"process_state"`
+				State               string `json:"state"`
+				Uptime              string `json:"uptime"`
+				CPUTotal            string `json:"cpu_total"`
+				MemoryUsage         string `json:"memory_usage"`
+				EphemeralDiskUsage  string `json:"ephemeral_disk_usage"`
+				PersistentDiskUsage string `json:"persistent_disk_usage"`
 			} `json:"Rows"`
 		} `json:"Tables"`
 	}{}
@@ -111,9 +191,15 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 	for _, table := range jsonOutput.Tables {
 		for _, row := range table.Rows {
 			instances = append(instances, Instance{
-				Name:  row.Instance,
-				IP:    row.IPs,
-				State: row.ProcessState,
+				Name:           row.Instance,
+				IP:             row.IPs,
+				State:          row.ProcessState,
+				ExpectedState:  row.State,
+				Uptime:         row.Uptime,
+				CPU:            row.CPUTotal,
+				Memory:         row.MemoryUsage,
+				EphemeralDisk:  row.EphemeralDiskUsage,
+				PersistentDisk: row.PersistentDiskUsage,
 			})
 		}
 	}
@@ -130,7 +216,7 @@ type external_tls struct {
 	private_key string `yaml:"private_key"`
 }
 
-func saveFilesToWorkingDir(workingdir workingdir.IClient, provider iaas.Provider, creds []byte, external_tls_certificate string, external_tls_private_key string) error {
+func saveFilesToWorkingDir(workingdir workingdir.IClient, provider iaas.Provider, creds []byte, external_tls_certificate string, external_tls_private_key string, userVars string) error {
 	concourseVersionsContents, _ := provider.Choose(iaas.Choice{
 		AWS: awsConcourseVersions,
 		GCP: gcpConcourseVersions,
@@ -162,12 +248,66 @@ func saveFilesToWorkingDir(workingdir workingdir.IClient, provider iaas.Provider
 		concourseGitHubEnterpriseAuthFilename: concourseGithubEnterpriseAuth,
 		concourseMainGitHubAuthFilename:       concourseMainGitHubAuth,
 		concourseMicrosoftAuthFilename:        concourseMicrosoftAuth,
+		concourseOIDCAuthFilename:             concourseOIDCAuth,
+		concourseOAuthAuthFilename:            concourseOAuthAuth,
+		concourseSAMLAuthFilename:             concourseSAMLAuth,
+		concourseLDAPAuthFilename:             concourseLDAPAuth,
+		concourseLDAPGroupSearchFilename:      concourseLDAPGroupSearch,
+		concourseLDAPCACertFilename:           concourseLDAPCACert,
+		concourseCFAuthFilename:               concourseCFAuth,
+		concourseCFAuthCACertFilename:         concourseCFAuthCACert,
+		concourseMainCFAuthFilename:           concourseMainCFAuth,
+		concourseVaultFilename:                concourseVault,
+		concourseVaultCACertFilename:          concourseVaultCACert,
+		concourseVaultAuthBackendFilename:     concourseVaultAuthBackend,
+		concourseVaultClientTokenFilename:     concourseVaultClientToken,
+		concourseSecretsManagerFilename:       concourseSecretsManager,
+		concourseSSMFilename:                  concourseSSM,
+		concourseSIEMFilename:                 concourseSIEM,
+		concourseVersionFilename:              concourseVersion,
+		concourseContainerPlacementFilename:   concourseContainerPlacement,
+		concourseTaskCPULimitFilename:         concourseTaskCPULimit,
+		concourseTaskMemoryLimitFilename:      concourseTaskMemoryLimit,
+		concourseInterceptTimeoutFilename:     concourseInterceptTimeout,
+		concourseFailedGracePeriodFilename:    concourseFailedGracePeriod,
+		concourseP2PVolumeStreamingFilename:   concourseP2PVolumeStreaming,
+		concourseStreamingCompressionFilename: concourseStreamingCompression,
+		concourseWorkerUpgradeBatchesFilename: concourseWorkerUpgradeBatches,
+		concoursePrometheusMetricsFilename:    concoursePrometheusMetrics,
+		concourseMetricsScrapeFilename:        concourseMetricsScrape,
+		concourseExternalWorkersFilename:      concourseExternalWorkers,
+		concourseNewRelicFilename:             concourseNewRelic,
+		concourseExternalInfluxDbFilename:     concourseExternalInfluxDb,
+		concourseExternalDBNamesFilename:      concourseExternalDBNames,
+		concourseRDSIAMProxyFilename:          concourseRDSIAMProxy,
+		concourseWebEnvFilename:               concourseWebEnv,
+		concourseWebInstancesFilename:         concourseWebInstances,
+		concourseWorkerEnvFilename:            concourseWorkerEnv,
+		concourseExternalURLFilename:          concourseExternalURL,
+		concourseSyslogFilename:               concourseSyslog,
+		concourseSyslogCACertFilename:         concourseSyslogCACert,
+		concoursePolicyCheckFilename:          concoursePolicyCheck,
+		concourseAuditLogsFilename:            concourseAuditLogs,
+		concourseManagedPrometheusAWSFilename: concourseManagedPrometheusAWS,
+		concourseManagedPrometheusGCPFilename: concourseManagedPrometheusGCP,
 		concourseEphemeralWorkersFilename:     concourseEphemeralWorkers,
+		concoursePreemptionRetireFilename:     concoursePreemptionRetire,
+		osConfReleaseFilename:                 osConfRelease,
 		concourseNoMetricsFilename:            concourseNoMetrics,
 		credsFilename:                         creds,
 		extraTagsFilename:                     extraTags,
 		psqlCAFilename:                        []byte(db.RDSRootCert),
 		concourseCertFilename:                 external_tls_config_yaml,
+		concourseUserVarsFilename:             []byte(userVars),
+		concourseWorkerKernelFilename:         concourseWorkerKernel,
+		concourseWorkerDrainTimeoutFilename:   concourseWorkerDrainTimeout,
+		concourseWorkerRebalanceFilename:      concourseWorkerRebalance,
+		concourseWorkerRetireInFlightFilename: concourseWorkerRetireInFlight,
+		concourseWorkerContainerdFilename:     concourseWorkerContainerd,
+		concourseWorkerZonesFilename:          concourseWorkerZones,
+		concourseDedicatedWebFilename:         concourseDedicatedWeb,
+		concourseDedicatedWorkersFilename:     concourseDedicatedWorkers,
+		concoursePrivateWebFilename:           concoursePrivateWeb,
 	}
 
 	for filename, contents := range filesToSave {
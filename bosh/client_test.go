@@ -165,6 +165,38 @@ var _ = Describe("Client", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(instances).To(Equal([]bosh.Instance{expectedInstance}))
 				})
+
+				It("requests vitals", func() {
+					_, err := buildClient().Instances()
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, _, _, _, flags := boshCLI.RunAuthenticatedCommandArgsForCall(0)
+					Expect(flags).To(ContainElement("--vitals"))
+					Expect(flags).To(ContainElement("--details"))
+				})
+			})
+
+			When("instances report vitals", func() {
+				BeforeEach(func() {
+					boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+						io.WriteString(stdout, `{"Tables":[{"Rows": [{"instance": "worker/abc","ips": "10.0.1.2", "process_state": "running", "state": "started", "uptime": "2d 3h 4m 5s", "cpu_total": "12.5%", "memory_usage": "40% (3.1 GB)", "ephemeral_disk_usage": "22% (11i%)", "persistent_disk_usage": ""}]}]}`)
+						return nil
+					}
+				})
+
+				It("returns them with their vitals", func() {
+					instances, err := buildClient().Instances()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(instances).To(Equal([]bosh.Instance{{
+						Name:          "worker/abc",
+						IP:            "10.0.1.2",
+						State:         "running",
+						ExpectedState: "started",
+						Uptime:        "2d 3h 4m 5s",
+						CPU:           "12.5%",
+						Memory:        "40% (3.1 GB)",
+						EphemeralDisk: "22% (11i%)",
+					}}))
+				})
 			})
 		})
 	})
@@ -0,0 +1,355 @@
+package bosh
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// managedConcourseVars are set by control-tower on every deploy and so cannot be overridden by a user vars file
+var managedConcourseVars = []string{
+	"atc_eip",
+	"atc_encryption_key",
+	"atc_password",
+	"audit_builds",
+	"audit_containers",
+	"audit_jobs",
+	"audit_pipelines",
+	"audit_resources",
+	"audit_system",
+	"audit_teams",
+	"audit_volumes",
+	"audit_workers",
+	"bitbucket_client_id",
+	"bitbucket_client_secret",
+	"cf_api_url",
+	"cf_ca_cert",
+	"cf_client_id",
+	"cf_client_secret",
+	"concourse_db_name",
+	"concourse_release_sha1",
+	"concourse_release_url",
+	"concourse_version",
+	"concourse_web_env",
+	"concourse_worker_env",
+	"container_placement_strategies",
+	"credhub_db_name",
+	"default_task_cpu_limit",
+	"default_task_memory_limit",
+	"deployment_name",
+	"domain",
+	"enable_global_resources",
+	"enable_pipeline_instances",
+	"external_influxdb_batch_size",
+	"external_influxdb_database",
+	"external_influxdb_password",
+	"external_influxdb_url",
+	"external_influxdb_username",
+	"external_tls",
+	"external_url",
+	"failed_grace_period",
+	"github_auth_ca_cert",
+	"github_auth_host",
+	"github_client_id",
+	"github_client_secret",
+	"influx_db_retention_period",
+	"intercept_idle_timeout",
+	"ldap_bind_dn",
+	"ldap_bind_password",
+	"ldap_ca_cert",
+	"ldap_group_search_base_dn",
+	"ldap_group_search_filter",
+	"ldap_group_search_name_attr",
+	"ldap_host",
+	"ldap_user_search_base_dn",
+	"ldap_user_search_filter",
+	"ldap_user_search_username",
+	"main_github_orgs",
+	"main_github_teams",
+	"main_cf_orgs",
+	"main_cf_spaces",
+	"main_github_users",
+	"managed_prometheus_location",
+	"managed_prometheus_project",
+	"managed_prometheus_region",
+	"managed_prometheus_url",
+	"max_active_containers_per_worker",
+	"max_active_tasks_per_worker",
+	"max_active_volumes_per_worker",
+	"metrics_scrape_allow_ips",
+	"metrics_scrape_password",
+	"microsoft_client_id",
+	"microsoft_client_secret",
+	"microsoft_tenant",
+	"newrelic_account_id",
+	"newrelic_batch_duration",
+	"newrelic_batch_size",
+	"newrelic_insert_key",
+	"newrelic_insights_api_url",
+	"newrelic_service_prefix",
+	"oauth_auth_url",
+	"oauth_client_id",
+	"oauth_client_secret",
+	"oauth_groups_key",
+	"oauth_scopes",
+	"oauth_token_url",
+	"oauth_userinfo_url",
+	"oidc_client_id",
+	"oidc_client_secret",
+	"oidc_groups_claim",
+	"oidc_issuer",
+	"oidc_username_claim",
+	"opa_url",
+	"os_conf_release_sha1",
+	"persistent_disk",
+	"policy_check_actions",
+	"postgres_ca_cert",
+	"postgres_host",
+	"postgres_password",
+	"postgres_port",
+	"postgres_role",
+	"project",
+	"rds_iam_proxy_cert",
+	"rds_iam_proxy_databases",
+	"rds_iam_proxy_host",
+	"rds_iam_proxy_key",
+	"rds_iam_proxy_region",
+	"rds_iam_proxy_release_version",
+	"rds_iam_proxy_server_ca_cert",
+	"rds_iam_proxy_server_port",
+	"saml_ca_cert",
+	"saml_email_attr",
+	"saml_groups_attr",
+	"saml_sso_issuer",
+	"saml_sso_url",
+	"saml_username_attr",
+	"secretsmanager_pipeline_path",
+	"secretsmanager_region",
+	"secretsmanager_team_path",
+	"siem_address",
+	"siem_port",
+	"siem_tls",
+	"siem_transport",
+	"ssm_pipeline_path",
+	"ssm_region",
+	"ssm_team_path",
+	"streaming_compression",
+	"syslog_address",
+	"syslog_ca_cert",
+	"syslog_transport",
+	"tags",
+	"uaa_db_name",
+	"upgrade_batch_size",
+	"vault_auth_backend",
+	"vault_auth_params",
+	"vault_ca_cert",
+	"vault_client_token",
+	"vault_path_prefix",
+	"vault_url",
+	"web_count",
+	"web_network_name",
+	"web_static_ip",
+	"web_static_ips",
+	"web_vm_type",
+	"worker_azs",
+	"worker_cgroup_version",
+	"worker_containerd",
+	"worker_count",
+	"worker_drain_timeout",
+	"worker_network_name",
+	"worker_rebalance_interval",
+	"worker_retire_max_in_flight",
+	"worker_sysctls",
+	"worker_update_watch_time",
+	"worker_vm_type",
+}
+
+var manifestVarPattern = regexp.MustCompile(`\(\(([a-zA-Z0-9_\-.]+)\)\)`)
+
+// concourseEnv turns CONCOURSE_NAME=value settings into the env property of a Concourse job. Passing
+// them as a var rather than writing them into an ops file keeps values from being read as YAML
+func concourseEnv(settings []string) map[string]string {
+	env := map[string]string{}
+	for _, setting := range settings {
+		kv := strings.SplitN(setting, "=", 2)
+		if len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		}
+	}
+	return env
+}
+
+// auditLogVars sets a variable for every audit log category, true for those that are turned on
+func auditLogVars(vmap map[string]interface{}, enabled []string) {
+	for _, category := range []string{"builds", "containers", "jobs", "pipelines", "resources", "system", "teams", "volumes", "workers"} {
+		vmap["audit_"+category] = false
+	}
+	for _, category := range enabled {
+		vmap["audit_"+category] = true
+	}
+}
+
+// opaDecisionURL is the OPA data API endpoint that Concourse asks for the decision of the policy at path
+func opaDecisionURL(opaURL, path string) string {
+	return opaURL + "/v1/data/" + path
+}
+
+// ValidateConcourseVars checks that every variable in a user supplied vars file is referenced by
+// the Concourse manifest or its ops files and is not one that control-tower sets itself
+func ValidateConcourseVars(varsFile []byte) error {
+	return validateConcourseVars(varsFile, concourseManifestSources()...)
+}
+
+func concourseManifestSources() [][]byte {
+	return [][]byte{
+		concourseManifestContents,
+		concourseBitBucketAuth,
+		concourseGitHubAuth,
+		concourseGithubEnterpriseAuth,
+		concourseMainGitHubAuth,
+		concourseMicrosoftAuth,
+		concourseOIDCAuth,
+		concourseOAuthAuth,
+		concourseSAMLAuth,
+		concourseLDAPAuth,
+		concourseLDAPGroupSearch,
+		concourseLDAPCACert,
+		concourseCFAuth,
+		concourseCFAuthCACert,
+		concourseMainCFAuth,
+		concourseVault,
+		concourseVaultCACert,
+		concourseVaultAuthBackend,
+		concourseVaultClientToken,
+		concourseSecretsManager,
+		concourseSSM,
+		concourseSIEM,
+		concourseVersion,
+		concourseContainerPlacement,
+		concourseTaskCPULimit,
+		concourseTaskMemoryLimit,
+		concourseInterceptTimeout,
+		concourseFailedGracePeriod,
+		concourseP2PVolumeStreaming,
+		concourseStreamingCompression,
+		concourseWorkerUpgradeBatches,
+		concoursePrometheusMetrics,
+		concourseMetricsScrape,
+		concourseExternalWorkers,
+		concourseNewRelic,
+		concourseExternalInfluxDb,
+		concourseExternalDBNames,
+		concourseRDSIAMProxy,
+		concourseWebEnv,
+		concourseWebInstances,
+		concourseWorkerEnv,
+		concourseExternalURL,
+		concourseSyslog,
+		concourseSyslogCACert,
+		concoursePolicyCheck,
+		concourseAuditLogs,
+		concourseManagedPrometheusAWS,
+		concourseManagedPrometheusGCP,
+		concourseEphemeralWorkers,
+		concoursePreemptionRetire,
+		osConfRelease,
+		concourseNoMetrics,
+		extraTags,
+		concourseWorkerKernel,
+		concourseWorkerDrainTimeout,
+		concourseWorkerRebalance,
+		concourseWorkerRetireInFlight,
+		concourseWorkerContainerd,
+		concourseWorkerZones,
+		concourseDedicatedWeb,
+		concourseDedicatedWorkers,
+		concoursePrivateWeb,
+	}
+}
+
+func validateConcourseVars(varsFile []byte, sources ...[]byte) error {
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal(varsFile, &vars); err != nil {
+		return fmt.Errorf("--vars-file is not a YAML map of variable names to values: [%v]", err)
+	}
+
+	known := map[string]bool{}
+	for _, source := range sources {
+		for _, match := range manifestVarPattern.FindAllSubmatch(source, -1) {
+			known[string(match[1])] = true
+		}
+	}
+	managed := map[string]bool{}
+	for _, name := range managedConcourseVars {
+		managed[name] = true
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if managed[name] {
+			return fmt.Errorf("variable %q in --vars-file is set by control-tower and cannot be overridden", name)
+		}
+		if known[name] {
+			continue
+		}
+		if suggestion := closestVar(name, known); suggestion != "" {
+			return fmt.Errorf("unknown variable %q in --vars-file, did you mean %q?", name, suggestion)
+		}
+		return fmt.Errorf("unknown variable %q in --vars-file", name)
+	}
+	return nil
+}
+
+// closestVar returns the known variable within a small edit distance of name, if any
+func closestVar(name string, known map[string]bool) string {
+	const maxDistance = 3
+	best, bestDistance := "", maxDistance+1
+	for candidate := range known {
+		d := editDistance(name, candidate)
+		if d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
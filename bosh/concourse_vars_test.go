@@ -0,0 +1,72 @@
+package bosh
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_validateConcourseVars(t *testing.T) {
+	manifest := []byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: web
+    properties:
+      default_build_logs_to_retain: ((default_build_logs_to_retain))
+      postgresql:
+        host: ((postgres_host))
+`)
+	opsFile := []byte(`
+- type: replace
+  path: /instance_groups/name=web/jobs/name=web/properties/max_build_logs_to_retain?
+  value: ((max_build_logs_to_retain))
+`)
+	tests := []struct {
+		name        string
+		vars        string
+		expectedErr string
+	}{
+		{
+			name: "known variables",
+			vars: "default_build_logs_to_retain: 50\nmax_build_logs_to_retain: 100\n",
+		},
+		{
+			name: "empty file",
+			vars: "",
+		},
+		{
+			name:        "typo of a known variable",
+			vars:        "default_build_log_to_retain: 50\n",
+			expectedErr: `unknown variable "default_build_log_to_retain" in --vars-file, did you mean "default_build_logs_to_retain"?`,
+		},
+		{
+			name:        "unknown variable",
+			vars:        "something_else: true\n",
+			expectedErr: `unknown variable "something_else" in --vars-file`,
+		},
+		{
+			name:        "variable managed by control-tower",
+			vars:        "postgres_host: db.example.com\n",
+			expectedErr: `variable "postgres_host" in --vars-file is set by control-tower and cannot be overridden`,
+		},
+		{
+			name:        "not a map",
+			vars:        "- a\n- b\n",
+			expectedErr: "--vars-file is not a YAML map",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConcourseVars([]byte(tt.vars), manifest, opsFile)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("validateConcourseVars() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("validateConcourseVars() error = %v, want %q", err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -19,12 +19,68 @@ const (
 	concourseGitHubEnterpriseAuthFilename = "github-enterprise-auth.yml"
 	concourseMainGitHubAuthFilename       = "main-github-auth.yml"
 	concourseMicrosoftAuthFilename        = "microsoft-auth.yml"
+	concourseOIDCAuthFilename             = "oidc-auth.yml"
+	concourseOAuthAuthFilename            = "oauth-auth.yml"
+	concourseSAMLAuthFilename             = "saml-auth.yml"
+	concourseLDAPAuthFilename             = "ldap-auth.yml"
+	concourseLDAPGroupSearchFilename      = "ldap-group-search.yml"
+	concourseLDAPCACertFilename           = "ldap-ca-cert.yml"
+	concourseCFAuthFilename               = "cf-auth.yml"
+	concourseCFAuthCACertFilename         = "cf-auth-ca-cert.yml"
+	concourseMainCFAuthFilename           = "main-cf-auth.yml"
+	concourseVaultFilename                = "vault.yml"
+	concourseSecretsManagerFilename       = "secretsmanager.yml"
+	concourseSSMFilename                  = "ssm.yml"
+	concourseSIEMFilename                 = "siem.yml"
+	concourseVersionFilename              = "concourse-version.yml"
+	concourseContainerPlacementFilename   = "container-placement.yml"
+	concourseTaskCPULimitFilename         = "default-task-cpu-limit.yml"
+	concourseTaskMemoryLimitFilename      = "default-task-memory-limit.yml"
+	concourseInterceptTimeoutFilename     = "intercept-idle-timeout.yml"
+	concourseFailedGracePeriodFilename    = "failed-grace-period.yml"
+	concourseP2PVolumeStreamingFilename   = "p2p-volume-streaming.yml"
+	concourseStreamingCompressionFilename = "streaming-compression.yml"
+	concourseWorkerUpgradeBatchesFilename = "worker-upgrade-batches.yml"
+	concoursePrometheusMetricsFilename    = "prometheus-metrics.yml"
+	concourseMetricsScrapeFilename        = "metrics-scrape-endpoint.yml"
+	concourseExternalWorkersFilename      = "external-workers.yml"
+	concourseNewRelicFilename             = "newrelic.yml"
+	concourseExternalInfluxDbFilename     = "external-influxdb.yml"
+	concourseExternalDBNamesFilename      = "external-db-names.yml"
+	concourseRDSIAMProxyFilename          = "rds-iam-proxy.yml"
+	concourseWebEnvFilename               = "concourse-web-env.yml"
+	concourseWebInstancesFilename         = "web-instances.yml"
+	concourseWorkerEnvFilename            = "concourse-worker-env.yml"
+	concourseExternalURLFilename          = "external-url.yml"
+	concourseSyslogFilename               = "syslog.yml"
+	concourseSyslogCACertFilename         = "syslog-ca-cert.yml"
+	concoursePolicyCheckFilename          = "policy-check.yml"
+	concourseAuditLogsFilename            = "audit-logs.yml"
+	concourseManagedPrometheusAWSFilename = "managed-prometheus-aws.yml"
+	concourseManagedPrometheusGCPFilename = "managed-prometheus-gcp.yml"
+	concourseVaultCACertFilename          = "vault-ca-cert.yml"
+	concourseVaultAuthBackendFilename     = "vault-auth-backend.yml"
+	concourseVaultClientTokenFilename     = "vault-client-token.yml"
 	concourseEphemeralWorkersFilename     = "ephemeral_workers.yml"
+	concoursePreemptionRetireFilename     = "preemption_retire.yml"
+	osConfReleaseFilename                 = "os-conf-release.yml"
 	concourseNoMetricsFilename            = "no_metrics.yml"
 	extraTagsFilename                     = "extra_tags.yml"
 	uaaCertFilename                       = "uaa-cert.yml"
 	psqlCAFilename                        = "psql-ca.yml"
 	concourseCertFilename                 = "concourse-cert.yaml"
+	concourseUserVarsFilename             = "concourse-user-vars.yml"
+	concourseWorkerKernelFilename         = "worker_kernel.yml"
+	concourseWorkerPoolsFilename          = "worker_pools.yml"
+	concourseWindowsWorkersFilename       = "windows_workers.yml"
+	concourseWorkerDrainTimeoutFilename   = "worker-drain-timeout.yml"
+	concourseWorkerRebalanceFilename      = "worker-rebalance-interval.yml"
+	concourseWorkerRetireInFlightFilename = "worker-retire-max-in-flight.yml"
+	concourseWorkerContainerdFilename     = "worker-containerd.yml"
+	concourseWorkerZonesFilename          = "worker-zones.yml"
+	concourseDedicatedWebFilename         = "dedicated-web.yml"
+	concourseDedicatedWorkersFilename     = "dedicated-workers.yml"
+	concoursePrivateWebFilename           = "private-web.yml"
 )
 
 var (
@@ -46,15 +102,177 @@ var (
 	//go:embed assets/ops/microsoft-auth.yml
 	concourseMicrosoftAuth []byte
 
+	//go:embed assets/ops/oidc-auth.yml
+	concourseOIDCAuth []byte
+
+	//go:embed assets/ops/oauth-auth.yml
+	concourseOAuthAuth []byte
+
+	//go:embed assets/ops/saml-auth.yml
+	concourseSAMLAuth []byte
+
+	//go:embed assets/ops/ldap-auth.yml
+	concourseLDAPAuth []byte
+
+	//go:embed assets/ops/ldap-group-search.yml
+	concourseLDAPGroupSearch []byte
+
+	//go:embed assets/ops/ldap-ca-cert.yml
+	concourseLDAPCACert []byte
+
+	//go:embed assets/ops/cf-auth.yml
+	concourseCFAuth []byte
+
+	//go:embed assets/ops/cf-auth-ca-cert.yml
+	concourseCFAuthCACert []byte
+
+	//go:embed assets/ops/main-cf-auth.yml
+	concourseMainCFAuth []byte
+
+	//go:embed assets/ops/vault.yml
+	concourseVault []byte
+
+	//go:embed assets/ops/secretsmanager.yml
+	concourseSecretsManager []byte
+
+	//go:embed assets/ops/ssm.yml
+	concourseSSM []byte
+
+	//go:embed assets/ops/siem.yml
+	concourseSIEM []byte
+
+	//go:embed assets/ops/concourse-version.yml
+	concourseVersion []byte
+
+	//go:embed assets/ops/container-placement.yml
+	concourseContainerPlacement []byte
+
+	//go:embed assets/ops/default-task-cpu-limit.yml
+	concourseTaskCPULimit []byte
+
+	//go:embed assets/ops/default-task-memory-limit.yml
+	concourseTaskMemoryLimit []byte
+
+	//go:embed assets/ops/intercept-idle-timeout.yml
+	concourseInterceptTimeout []byte
+
+	//go:embed assets/ops/failed-grace-period.yml
+	concourseFailedGracePeriod []byte
+
+	//go:embed assets/ops/p2p-volume-streaming.yml
+	concourseP2PVolumeStreaming []byte
+
+	//go:embed assets/ops/streaming-compression.yml
+	concourseStreamingCompression []byte
+
+	//go:embed assets/ops/worker-upgrade-batches.yml
+	concourseWorkerUpgradeBatches []byte
+
+	//go:embed assets/ops/prometheus-metrics.yml
+	concoursePrometheusMetrics []byte
+
+	//go:embed assets/ops/metrics-scrape-endpoint.yml
+	concourseMetricsScrape []byte
+
+	//go:embed assets/ops/external-workers.yml
+	concourseExternalWorkers []byte
+
+	//go:embed assets/ops/newrelic.yml
+	concourseNewRelic []byte
+
+	//go:embed assets/ops/external-influxdb.yml
+	concourseExternalInfluxDb []byte
+
+	//go:embed assets/ops/external-db-names.yml
+	concourseExternalDBNames []byte
+
+	//go:embed assets/ops/rds-iam-proxy.yml
+	concourseRDSIAMProxy []byte
+
+	//go:embed assets/ops/concourse-web-env.yml
+	concourseWebEnv []byte
+
+	//go:embed assets/ops/web-instances.yml
+	concourseWebInstances []byte
+
+	//go:embed assets/ops/concourse-worker-env.yml
+	concourseWorkerEnv []byte
+
+	//go:embed assets/ops/external-url.yml
+	concourseExternalURL []byte
+
+	//go:embed assets/ops/syslog.yml
+	concourseSyslog []byte
+
+	//go:embed assets/ops/syslog-ca-cert.yml
+	concourseSyslogCACert []byte
+
+	//go:embed assets/ops/policy-check.yml
+	concoursePolicyCheck []byte
+
+	//go:embed assets/ops/audit-logs.yml
+	concourseAuditLogs []byte
+
+	//go:embed assets/ops/managed-prometheus-aws.yml
+	concourseManagedPrometheusAWS []byte
+
+	//go:embed assets/ops/managed-prometheus-gcp.yml
+	concourseManagedPrometheusGCP []byte
+
+	//go:embed assets/ops/vault-ca-cert.yml
+	concourseVaultCACert []byte
+
+	//go:embed assets/ops/vault-auth-backend.yml
+	concourseVaultAuthBackend []byte
+
+	//go:embed assets/ops/vault-client-token.yml
+	concourseVaultClientToken []byte
+
 	//go:embed assets/ops/ephemeral_workers.yml
 	concourseEphemeralWorkers []byte
 
+	//go:embed assets/ops/preemption_retire.yml
+	concoursePreemptionRetire []byte
+
+	//go:embed assets/ops/os-conf-release.yml
+	osConfRelease []byte
+
 	//go:embed assets/ops/no_metrics.yml
 	concourseNoMetrics []byte
 
 	//go:embed assets/ops/extra_tags.yml
 	extraTags []byte
 
+	//go:embed assets/ops/worker_kernel.yml
+	concourseWorkerKernel []byte
+
+	//go:embed assets/gpu_driver.sh
+	gpuDriverScript []byte
+
+	//go:embed assets/ops/worker-drain-timeout.yml
+	concourseWorkerDrainTimeout []byte
+
+	//go:embed assets/ops/worker-rebalance-interval.yml
+	concourseWorkerRebalance []byte
+
+	//go:embed assets/ops/worker-retire-max-in-flight.yml
+	concourseWorkerRetireInFlight []byte
+
+	//go:embed assets/ops/worker-containerd.yml
+	concourseWorkerContainerd []byte
+
+	//go:embed assets/ops/worker-zones.yml
+	concourseWorkerZones []byte
+
+	//go:embed assets/ops/dedicated-web.yml
+	concourseDedicatedWeb []byte
+
+	//go:embed assets/ops/dedicated-workers.yml
+	concourseDedicatedWorkers []byte
+
+	//go:embed assets/ops/private-web.yml
+	concoursePrivateWeb []byte
+
 	concourseManifestContents = opsassets.ConcourseManifestContents
 	awsConcourseVersions      = opsassets.AwsConcourseVersions
 	awsConcourseSHAs          = opsassets.AwsConcourseSHAs
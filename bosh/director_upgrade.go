@@ -0,0 +1,149 @@
+package bosh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// directorState represents the parts of the bosh create-env state file needed to plan an upgrade
+type directorState struct {
+	CurrentVMCID  string `json:"current_vm_cid"`
+	CurrentDiskID string `json:"current_disk_id"`
+	Stemcells     []struct {
+		ID      string `json:"id"`
+		Version string `json:"version"`
+	} `json:"stemcells"`
+	CurrentStemcellID string `json:"current_stemcell_id"`
+	Releases          []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"releases"`
+}
+
+// DirectorUpgrade describes the changes a create-env will make to an existing director. create-env has no
+// in-place update: any change to the director's manifest, including a release bump, deletes the VM and
+// creates a new one with the same persistent disk, so RequiresNewVM is set whenever anything changes
+type DirectorUpgrade struct {
+	FreshInstall    bool
+	ReleaseChanges  []string
+	StemcellChange  string
+	RequiresNewVM   bool
+	NothingToChange bool
+}
+
+// planDirectorUpgrade compares the deployed director recorded in the state file with the
+// releases and stemcell pinned in the version file
+func planDirectorUpgrade(state, versionFile []byte) (DirectorUpgrade, error) {
+	if len(state) == 0 {
+		return DirectorUpgrade{FreshInstall: true}, nil
+	}
+
+	var current directorState
+	if err := json.Unmarshal(state, &current); err != nil {
+		return DirectorUpgrade{}, fmt.Errorf("failed to parse director state: [%v]", err)
+	}
+	if current.CurrentVMCID == "" {
+		return DirectorUpgrade{FreshInstall: true}, nil
+	}
+
+	var desired map[string]struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(versionFile, &desired); err != nil {
+		return DirectorUpgrade{}, fmt.Errorf("failed to parse version file: [%v]", err)
+	}
+
+	upgrade := DirectorUpgrade{}
+
+	deployedReleases := map[string]string{}
+	for _, release := range current.Releases {
+		name := release.Name
+		if strings.HasSuffix(name, "-cpi") {
+			name = "cpi"
+		}
+		deployedReleases[name] = release.Version
+	}
+	for _, name := range []string{"bosh", "bpm", "cpi"} {
+		want, ok := desired[name]
+		if !ok {
+			continue
+		}
+		if have := deployedReleases[name]; have != want.Version {
+			upgrade.ReleaseChanges = append(upgrade.ReleaseChanges, fmt.Sprintf("%s %s -> %s", name, have, want.Version))
+		}
+	}
+	sort.Strings(upgrade.ReleaseChanges)
+
+	if want, ok := desired["stemcell"]; ok {
+		var have string
+		for _, stemcell := range current.Stemcells {
+			if stemcell.ID == current.CurrentStemcellID {
+				have = stemcell.Version
+			}
+		}
+		if have != want.Version {
+			upgrade.StemcellChange = fmt.Sprintf("stemcell %s -> %s", have, want.Version)
+		}
+	}
+
+	upgrade.NothingToChange = len(upgrade.ReleaseChanges) == 0 && upgrade.StemcellChange == ""
+	upgrade.RequiresNewVM = !upgrade.NothingToChange
+	return upgrade, nil
+}
+
+// String describes the upgrade for the user
+func (u DirectorUpgrade) String() string {
+	switch {
+	case u.FreshInstall:
+		return "Creating new BOSH director"
+	case u.NothingToChange:
+		return "BOSH director releases and stemcell are up to date, its VM is only recreated if its configuration has changed"
+	default:
+		changes := u.ReleaseChanges
+		if u.StemcellChange != "" {
+			changes = append(changes, u.StemcellChange)
+		}
+		return fmt.Sprintf("Upgrading BOSH director (%s), the VM will be recreated and its persistent disk reattached, so the director is unavailable until it is back", strings.Join(changes, ", "))
+	}
+}
+
+// upgradeDirector runs create-env against the existing director state, reporting whether
+// the director VM was recreated and so whether downtime occurred
+func upgradeDirector(createEnv func([]byte, []byte, string) ([]byte, []byte, error), state, creds, versionFile []byte, stdout io.Writer) ([]byte, []byte, error) {
+	upgrade, err := planDirectorUpgrade(state, versionFile)
+	if err != nil {
+		return state, creds, err
+	}
+	fmt.Fprintln(stdout, upgrade)
+
+	var before directorState
+	if !upgrade.FreshInstall {
+		if err = json.Unmarshal(state, &before); err != nil {
+			return state, creds, fmt.Errorf("failed to parse director state: [%v]", err)
+		}
+	}
+
+	newState, newCreds, err := createEnv(state, creds, "")
+	if err != nil || upgrade.FreshInstall {
+		return newState, newCreds, err
+	}
+
+	var after directorState
+	if err = json.Unmarshal(newState, &after); err != nil {
+		return newState, newCreds, fmt.Errorf("failed to parse director state after create-env: [%v]", err)
+	}
+
+	switch {
+	case after.CurrentDiskID != before.CurrentDiskID:
+		fmt.Fprintln(stdout, "WARNING: BOSH director persistent disk was replaced")
+	case after.CurrentVMCID != before.CurrentVMCID:
+		fmt.Fprintln(stdout, "BOSH director VM was recreated, the director was unavailable during the upgrade")
+	default:
+		fmt.Fprintln(stdout, "BOSH director VM was kept, as nothing about it had changed, so there was no downtime")
+	}
+
+	return newState, newCreds, nil
+}
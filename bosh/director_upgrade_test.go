@@ -0,0 +1,158 @@
+package bosh
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const upgradeTestState = `{
+	"current_vm_cid": "i-123",
+	"current_disk_id": "disk-1",
+	"current_stemcell_id": "stemcell-1",
+	"stemcells": [{"id": "stemcell-1", "version": "170.9"}],
+	"releases": [
+		{"name": "bosh", "version": "268.5.0"},
+		{"name": "bpm", "version": "0.12.3"},
+		{"name": "bosh-aws-cpi", "version": "91"}
+	]
+}`
+
+func Test_planDirectorUpgrade(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		versionFile string
+		want        DirectorUpgrade
+	}{
+		{
+			name:        "no existing director",
+			state:       "",
+			versionFile: `{}`,
+			want:        DirectorUpgrade{FreshInstall: true},
+		},
+		{
+			name:        "nothing to change",
+			state:       upgradeTestState,
+			versionFile: `{"bosh":{"version":"268.5.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"91"},"stemcell":{"version":"170.9"}}`,
+			want:        DirectorUpgrade{NothingToChange: true},
+		},
+		{
+			name:        "release bump only",
+			state:       upgradeTestState,
+			versionFile: `{"bosh":{"version":"270.0.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"92"},"stemcell":{"version":"170.9"}}`,
+			want:        DirectorUpgrade{ReleaseChanges: []string{"bosh 268.5.0 -> 270.0.0", "cpi 91 -> 92"}, RequiresNewVM: true},
+		},
+		{
+			name:        "stemcell bump",
+			state:       upgradeTestState,
+			versionFile: `{"bosh":{"version":"268.5.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"91"},"stemcell":{"version":"171.0"}}`,
+			want:        DirectorUpgrade{StemcellChange: "stemcell 170.9 -> 171.0", RequiresNewVM: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planDirectorUpgrade([]byte(tt.state), []byte(tt.versionFile))
+			if err != nil {
+				t.Fatalf("planDirectorUpgrade() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planDirectorUpgrade() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// createEnvLike behaves as bosh create-env does with a director's state: a manifest whose releases or
+// stemcell differ from those deployed deletes the VM and creates another with the same disk, while an
+// unchanged manifest skips the deploy and keeps the VM
+func createEnvLike(versionFile []byte) func([]byte, []byte, string) ([]byte, []byte, error) {
+	return func(state, creds []byte, customOps string) ([]byte, []byte, error) {
+		var desired map[string]struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(versionFile, &desired); err != nil {
+			return nil, nil, err
+		}
+		var deployed map[string]interface{}
+		if err := json.Unmarshal(state, &deployed); err != nil {
+			return nil, nil, err
+		}
+		changed := false
+		for _, release := range deployed["releases"].([]interface{}) {
+			release := release.(map[string]interface{})
+			name := release["name"].(string)
+			if strings.HasSuffix(name, "-cpi") {
+				name = "cpi"
+			}
+			if want, ok := desired[name]; ok && want.Version != release["version"] {
+				release["version"] = want.Version
+				changed = true
+			}
+		}
+		stemcell := deployed["stemcells"].([]interface{})[0].(map[string]interface{})
+		if want, ok := desired["stemcell"]; ok && want.Version != stemcell["version"] {
+			stemcell["version"] = want.Version
+			changed = true
+		}
+		if changed {
+			deployed["current_vm_cid"] = deployed["current_vm_cid"].(string) + "-recreated"
+		}
+		newState, err := json.Marshal(deployed)
+		return newState, creds, err
+	}
+}
+
+func Test_upgradeDirector(t *testing.T) {
+	tests := []struct {
+		name        string
+		versionFile string
+		wantPlan    string
+		want        string
+	}{
+		{
+			name:        "nothing to change",
+			versionFile: `{"bosh":{"version":"268.5.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"91"},"stemcell":{"version":"170.9"}}`,
+			wantPlan:    "only recreated if its configuration has changed",
+			want:        "VM was kept",
+		},
+		{
+			name:        "release bump only",
+			versionFile: `{"bosh":{"version":"270.0.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"91"},"stemcell":{"version":"170.9"}}`,
+			wantPlan:    "the VM will be recreated",
+			want:        "VM was recreated",
+		},
+		{
+			name:        "stemcell bump",
+			versionFile: `{"bosh":{"version":"268.5.0"},"bpm":{"version":"0.12.3"},"cpi":{"version":"91"},"stemcell":{"version":"171.0"}}`,
+			wantPlan:    "the VM will be recreated",
+			want:        "VM was recreated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := new(bytes.Buffer)
+			newState, _, err := upgradeDirector(createEnvLike([]byte(tt.versionFile)), []byte(upgradeTestState), nil, []byte(tt.versionFile), stdout)
+			if err != nil {
+				t.Fatalf("upgradeDirector() error = %v", err)
+			}
+			for _, want := range []string{tt.wantPlan, tt.want} {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("upgradeDirector() output = %q, want it to contain %q", stdout.String(), want)
+				}
+			}
+
+			// The plan made beforehand has to agree with what create-env did
+			plan, err := planDirectorUpgrade([]byte(upgradeTestState), []byte(tt.versionFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			recreated := !strings.Contains(string(newState), `"current_vm_cid":"i-123"`)
+			if plan.RequiresNewVM != recreated {
+				t.Errorf("planDirectorUpgrade() RequiresNewVM = %t, but create-env recreated the VM = %t", plan.RequiresNewVM, recreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package bosh
+
+import (
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+)
+
+// componentDatabaseNames are the databases Concourse, UAA and CredHub keep their data in, in the order of
+// db.ComponentDatabases, which are prefixed with the name of the deployment on an external server
+func componentDatabaseNames(conf config.ConfigView) []string {
+	return db.ComponentDatabaseNames(conf.GetDeployment(), conf.GetExternalDBURL() != "")
+}
+
+// concourseDBName is the database the ATC keeps its data in
+func concourseDBName(conf config.ConfigView) string {
+	return componentDatabaseNames(conf)[0]
+}
+
+// externalDBNameVars sets the vars of external-db-names.yml, which points each component at its
+// database on an external server
+func externalDBNameVars(vmap map[string]interface{}, conf config.ConfigView) {
+	names := componentDatabaseNames(conf)
+	vmap["concourse_db_name"] = names[0]
+	vmap["uaa_db_name"] = names[1]
+	vmap["credhub_db_name"] = names[2]
+}
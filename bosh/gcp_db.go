@@ -1,5 +1,69 @@
 package bosh
 
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/bastion"
+	"github.com/EngineerBetter/control-tower/db"
+)
+
 func (client *GCPClient) createDefaultDatabases() error {
-	return client.provider.CreateDatabases(client.config.GetRDSDefaultDatabaseName(), client.config.GetRDSUsername(), client.config.GetRDSPassword())
+	if client.config.GetExternalDBURL() == "" {
+		return client.provider.CreateDatabases(client.config.GetRDSDefaultDatabaseName(), client.config.GetRDSUsername(), client.config.GetRDSPassword())
+	}
+
+	opener, err := client.dbOpener()
+	if err != nil {
+		return err
+	}
+	defer opener.Close()
+	conn, err := opener.Open(client.config.GetRDSDefaultDatabaseName())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	names := componentDatabaseNames(client.config)
+	if err = db.CheckExternal(conn, names); err != nil {
+		return err
+	}
+	return db.CreateComponentDatabases(conn, names)
+}
+
+// dbPort is the port of CloudSQL, or of the external database when the deployment has one
+func (client *GCPClient) dbPort() string {
+	if client.config.GetExternalDBURL() == "" {
+		return "5432"
+	}
+	// The URL was validated when it was applied to the config
+	external, _ := db.ParseExternalURL(client.config.GetExternalDBURL())
+	return external.Port
+}
+
+// dbOpener connects to the database via the director, as neither CloudSQL nor an external database need
+// be reachable from where control-tower runs
+func (client *GCPClient) dbOpener() (Opener, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DirectorPublicIP from terraform outputs: [%v]", err)
+	}
+	boshDBAddress, err := client.outputs.Get("BoshDBAddress")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BoshDBAddress from terraform outputs: [%v]", err)
+	}
+	jump, err := bastion.FromOutputs(client.config, client.outputs)
+	if err != nil {
+		return nil, err
+	}
+	return newDirectorDBOpener(client.config, jump, directorPublicIP, boshDBAddress, client.dbPort())
+}
+
+// PreviewMigrations reports the ATC schema migrations the next deploy will run
+func (client *GCPClient) PreviewMigrations() (MigrationPreview, error) {
+	opener, err := client.dbOpener()
+	if err != nil {
+		return MigrationPreview{}, err
+	}
+	defer opener.Close()
+
+	return previewMigrations(opener, concourseDBName(client.config), client.config.GetConcourseVersion(), gcpConcourseVersions)
 }
@@ -1,6 +1,7 @@
 package bosh
 
 import (
+	"bytes"
 	"net"
 
 	"github.com/apparentlymart/go-cidr/cidr"
@@ -15,7 +16,11 @@ func (client *GCPClient) Deploy(state, creds []byte, detach bool) (newState, new
 		return state, creds, err
 	}
 
-	state, creds, err = client.CreateEnv(state, creds, "")
+	if err = waitForBastion(client.config, client.outputs, client.stdout); err != nil {
+		return state, creds, err
+	}
+
+	state, creds, err = upgradeDirector(client.CreateEnv, state, creds, client.versionFile, client.stdout)
 	if err != nil {
 		return state, creds, err
 	}
@@ -91,7 +96,7 @@ func (client *GCPClient) CreateEnv(state, creds []byte, customOps string) (newSt
 		InternalGW:         internalGateway.String(),
 		InternalIP:         directorInternalIP.String(),
 		DirectorName:       "bosh",
-		Zone:               client.provider.Zone("", ""),
+		Zone:               client.zone(),
 		Network:            network,
 		PublicSubnetwork:   publicSubnetwork,
 		PrivateSubnetwork:  privateSubnetwork,
@@ -99,6 +104,7 @@ func (client *GCPClient) CreateEnv(state, creds []byte, customOps string) (newSt
 		ProjectID:          project,
 		GcpCredentialsJSON: credentialsPath,
 		ExternalIP:         directorPublicIP,
+		Private:            client.config.GetPrivate(),
 		Spot:               client.config.IsSpot(),
 		PublicKey:          client.config.GetPublicKey(),
 		CustomOperations:   customOps,
@@ -121,6 +127,92 @@ func (client *GCPClient) Recreate() error {
 	}, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert())
 }
 
+// CancelDeploy cancels the running deploy of Concourse, leaving instances that were already updated in place
+func (client *GCPClient) CancelDeploy() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("cancel-tasks", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, "--type", "update_deployment", "--state", "processing")
+}
+
+// CloudCheck runs bosh cloud-check against Concourse, recreating VMs that are missing or have unresponsive
+// agents. Other problems it finds, such as missing disks, are skipped, as their resolutions may lose data
+func (client *GCPClient) CloudCheck() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("cloud-check", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, "--resolution", "recreate_vm")
+}
+
+// SSH runs command on a single Concourse instance, such as worker/<id>, through the director, and returns
+// what it printed
+func (client *GCPClient) SSH(name, command string) ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	err = client.boshCLI.RunAuthenticatedCommand("ssh", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, &output, name, "--results", "--command", command)
+	return output.Bytes(), err
+}
+
+// RecreateInstance runs bosh recreate against a single Concourse instance, such as worker/<id>, running its
+// drain script first
+func (client *GCPClient) RecreateInstance(name string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("recreate", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name)
+}
+
+// StopInstance runs bosh stop against a single Concourse instance, such as worker/<id>, running its drain
+// script and stopping its jobs while keeping its VM, so that it can still be investigated
+func (client *GCPClient) StopInstance(name string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("stop", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name)
+}
+
+// Logs runs bosh logs against a single Concourse instance, such as worker/<id>, downloading a tarball of
+// its job logs into dir
+func (client *GCPClient) Logs(name, dir string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.RunAuthenticatedCommand("logs", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, name, "--dir", dir)
+}
+
+// ScaleWorkers deploys the Concourse manifest as deployed with the given number of workers, leaving the director
+// and every other instance group as they are
+func (client *GCPClient) ScaleWorkers(instances int) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	manifest, err := client.Manifest()
+	if err != nil {
+		return err
+	}
+	return scaleWorkers(client.boshCLI, client.workingdir, client.config, directorPublicIP, client.stdout, manifest, instances)
+}
+
+// Manifest returns the Concourse manifest as deployed, with the values of its variables filled in
+func (client *GCPClient) Manifest() ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	var manifest bytes.Buffer
+	err = client.boshCLI.RunAuthenticatedCommand("manifest", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, &manifest)
+	return manifest.Bytes(), err
+}
+
 // Locks implements locks for GCP client
 func (client *GCPClient) Locks() ([]byte, error) {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
@@ -151,7 +243,19 @@ func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
 	if err != nil {
 		return err
 	}
-	zone := client.provider.Zone("", "")
+	webTargetPool, err := client.outputs.Get("WebTargetPool")
+	if err != nil {
+		return err
+	}
+	workerZones, err := cloudConfigWorkerZones(client.config.GetWorkerZones(), "")
+	if err != nil {
+		return err
+	}
+	soleTenantNodeGroup, err := client.outputs.Get("SoleTenantNodeGroup")
+	if err != nil {
+		return err
+	}
+	zone := client.zone()
 
 	publicCIDR := client.config.GetPublicCIDR()
 	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
@@ -193,6 +297,11 @@ func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		return err
 	}
 
+	privateCIDRStatic, err := privateCIDRStatic(privateCIDR, client.config.GetConcourseWebCount())
+	if err != nil {
+		return err
+	}
+
 	return bosh.UpdateCloudConfig(boshcli.GCPEnvironment{
 		PublicCIDR:          client.config.GetPublicCIDR(),
 		PublicCIDRGateway:   publicCIDRGateway,
@@ -200,20 +309,55 @@ func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		PublicCIDRReserved:  publicCIDRReserved,
 		PrivateCIDRGateway:  privateCIDRGateway,
 		PrivateCIDRReserved: privateCIDRReserved,
+		PrivateCIDRStatic:   privateCIDRStatic,
 		PrivateCIDR:         client.config.GetPrivateCIDR(),
 		Spot:                client.config.IsSpot(),
 		PublicSubnetwork:    publicSubnetwork,
 		PrivateSubnetwork:   privateSubnetwork,
 		Zone:                zone,
 		Network:             network,
+		WebTargetPool:       webTargetPool,
+		WorkerPoolTypes:     workerPoolInstanceTypes(client.config.GetWorkerPools()),
+		WorkerPoolGPUs:      workerPoolGPUs(client.config.GetWorkerPools()),
+		WindowsWorkerType:   windowsWorkerType(client.config),
+		WebInstanceType:     client.config.GetWebInstanceType(),
+		WorkerInstanceType:  client.config.GetWorkerInstanceType(),
+		WorkerDiskSizeGB:    client.config.GetWorkerDiskSizeGB(),
+		WorkerDiskType:      client.config.GetWorkerDiskType(),
+
+		RestrictWorkerEgress: client.config.GetRestrictWorkerEgress(),
+		WorkerZones:          workerZones,
+		SoleTenantNodeGroup:  soleTenantNodeGroup,
 	}, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert())
 }
+
+// zone returns the zone chosen on first deploy, falling back to the provider default for older deployments
+func (client *GCPClient) zone() string {
+	return client.provider.Zone(client.config.GetAvailabilityZone(), "")
+}
+
+// gcpWindowsStemcellURL is the Windows Server 2019 stemcell on bosh.io, for Windows workers, which is uploaded at --windows-stemcell-version
+const gcpWindowsStemcellURL = "https://bosh.io/d/stemcells/bosh-google-kvm-windows2019-go_agent"
+
 func (client *GCPClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
 		return err
 	}
-	return bosh.UploadConcourseStemcell(boshcli.GCPEnvironment{
+	err = bosh.UploadConcourseStemcell(boshcli.GCPEnvironment{
 		ExternalIP: directorPublicIP,
 	}, directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert())
+	if err != nil {
+		return err
+	}
+	if hasARM64WorkerPool(client.config.GetWorkerPools()) {
+		err = bosh.RunAuthenticatedCommand("upload-stemcell", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, client.config.GetARM64StemcellURL())
+		if err != nil {
+			return err
+		}
+	}
+	if client.config.GetWindowsWorkerCount() > 0 {
+		return bosh.RunAuthenticatedCommand("upload-stemcell", directorPublicIP, client.config.GetDirectorPassword(), client.config.GetDirectorCACert(), false, client.stdout, windowsStemcellURL(gcpWindowsStemcellURL, client.config.GetWindowsStemcellVersion()))
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package bosh
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"strings"
@@ -22,6 +23,25 @@ func vars(vars map[string]interface{}) []string {
 			x = append(x, "--var", fmt.Sprintf("%s=%d", k, v))
 		case bool:
 			x = append(x, "--var", fmt.Sprintf("%s=%t", k, v))
+		case []string:
+			if v.([]string) == nil {
+				v = []string{}
+			}
+			list, _ := json.Marshal(v)
+			x = append(x, "--var", fmt.Sprintf("%s=%s", k, list))
+		case map[string]string:
+			if v.(map[string]string) == nil {
+				v = map[string]string{}
+			}
+			hash, _ := json.Marshal(v)
+			x = append(x, "--var", fmt.Sprintf("%s=%s", k, hash))
+		case map[string]interface{}:
+			// Properties of mixed types, such as the worker's containerd settings, which BOSH reads as YAML
+			hash, err := json.Marshal(v)
+			if err != nil {
+				panic(fmt.Sprintf("unsupported value for %s: %v", k, err))
+			}
+			x = append(x, "--var", fmt.Sprintf("%s=%s", k, hash))
 		default:
 			panic("unsupported type")
 		}
@@ -29,6 +49,17 @@ func vars(vars map[string]interface{}) []string {
 	return x
 }
 
+// keyValues converts key=value pairs to a map, the pairs are validated when given as flags
+func keyValues(pairs []string) map[string]string {
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+			m[kv[0]] = kv[1]
+		}
+	}
+	return m
+}
+
 func splitTags(ts []string) (map[string]string, error) {
 	m := make(map[string]string)
 	for _, t := range ts {
@@ -41,6 +72,46 @@ func splitTags(ts []string) (map[string]string, error) {
 	return m, nil
 }
 
+// workerUpdateWatchTime makes BOSH wait soakMinutes after updating each batch of workers before
+// checking they are healthy, then allows them up to ten more minutes to become so
+func workerUpdateWatchTime(soakMinutes int) string {
+	soak := soakMinutes * 60000
+	if soak < 30000 {
+		return fmt.Sprintf("30000-%d", soak+600000)
+	}
+	return fmt.Sprintf("%d-%d", soak, soak+600000)
+}
+
+// webStaticIPs are the addresses in the private subnet of web instances behind a load balancer
+func webStaticIPs(privateCIDR string, webCount int) ([]string, error) {
+	_, parsedCIDR, err := net.ParseCIDR(privateCIDR)
+	if err != nil {
+		return nil, err
+	}
+	var ips []string
+	for i := 0; i < webCount; i++ {
+		ip, err := cidr.Host(parsedCIDR, 8+i)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip.String())
+	}
+	return ips, nil
+}
+
+// privateCIDRStatic is the static range of the private network in the cloud config, which is only
+// needed when web instances are moved there behind a load balancer
+func privateCIDRStatic(privateCIDR string, webCount int) (string, error) {
+	if webCount <= 1 {
+		return "", nil
+	}
+	ips, err := webStaticIPs(privateCIDR, webCount)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("[%s]", strings.Join(ips, ", ")), nil
+}
+
 func formatIPRange(forCIDR, sep string, positions []int) (string, error) {
 	var ips []string
 	_, parsedCIDR, err := net.ParseCIDR(forCIDR)
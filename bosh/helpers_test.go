@@ -0,0 +1,18 @@
+package bosh
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_varsWorkerContainerd(t *testing.T) {
+	conf := config.Config{WorkerDNSServers: []string{"10.0.0.2"}, WorkerDNSProxy: true, WorkerNetworkPool: "10.80.0.0/16", WorkerMaxContainers: 250}
+	vmap := map[string]interface{}{"worker_containerd": workerContainerdProperties(conf)}
+
+	want := []string{"--var", `worker_containerd={"dns_proxy_enable":true,"dns_servers":["10.0.0.2"],"max_containers":250,"network_pool":"10.80.0.0/16"}`}
+	if got := vars(vmap); !reflect.DeepEqual(got, want) {
+		t.Errorf("vars() = %v, want %v", got, want)
+	}
+}
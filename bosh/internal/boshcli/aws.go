@@ -1,6 +1,10 @@
 package boshcli
 
 import (
+	"math"
+	"strconv"
+
+	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
 	"github.com/EngineerBetter/control-tower/util"
 	"github.com/EngineerBetter/control-tower/util/yaml"
@@ -28,6 +32,7 @@ type AWSEnvironment struct {
 	PrivateCIDR           string
 	PrivateCIDRGateway    string
 	PrivateCIDRReserved   string
+	PrivateCIDRStatic     string
 	PrivateKey            string
 	PrivateSubnetID       string
 	PublicCIDR            string
@@ -42,9 +47,41 @@ type AWSEnvironment struct {
 	Spot                  bool
 	VersionFile           []byte
 	VMSecurityGroup       string
+	WebInstanceProfile    string
+	WebInstanceType       string
+	WebTargetGroups       []string
+	WindowsWorkerType     string
+	WorkerDiskSizeGB      int
+	WorkerDiskType        string
+	WorkerInstanceType    string
+	WorkerIMDSHopLimit    int
+	WorkerPoolTypes       map[string]string
+	WorkerSpotBid         int
 	WorkerType            string
+	// WorkerZones are added to the cloud config as further AZs, each with a subnet of the private network
+	WorkerZones []WorkerZone
+	// Dedicated adds the dedicated vm_extension, which gives VMs EC2 dedicated tenancy
+	Dedicated bool
+	// Private leaves out the director's public address, so that it is only reached on its InternalIP
+	Private bool
 }
 
+// defaultWorkerIMDSHopLimit allows containers on workers, which are one network hop from the host, to reach IMDS
+const defaultWorkerIMDSHopLimit = 2
+
+// defaultWorkerSpotBid is the spot price, as a percentage of the on-demand price, above which workers fall back to on-demand
+const defaultWorkerSpotBid = 120
+
+// defaultWorkerDiskSizeGB and defaultAWSWorkerDiskType are the worker ephemeral disk without --worker-disk-size-gb and --worker-disk-type
+const (
+	defaultWorkerDiskSizeGB  = 200
+	defaultAWSWorkerDiskType = "gp2"
+)
+
+// provisionedWorkerDiskIOPS is the IOPS of io1 and io2 worker disks, which must be provisioned, matching
+// the baseline that gp3 disks get for free
+const provisionedWorkerDiskIOPS = 3000
+
 func (e AWSEnvironment) ExtractBOSHandBPM() (util.Resource, util.Resource, error) {
 	resources := util.ParseVersionResources(e.VersionFile)
 
@@ -62,7 +99,11 @@ func (e AWSEnvironment) ConfigureDirectorManifestCPI() (string, error) {
 	cpiResource := util.GetResource("cpi", resources)
 	stemcellResource := util.GetResource("stemcell", resources)
 
-	var allOperations = resource.AWSCPIOps + resource.AWSExternalIPOps + resource.AWSBlobstoreOps + resource.AWSDirectorCustomOps
+	var allOperations = resource.AWSCPIOps + resource.AWSBlobstoreOps + resource.AWSDirectorCustomOps
+	// The director of a private deployment is reached on its internal IP, which ExternalIP is then set to
+	if !e.Private {
+		allOperations += resource.AWSExternalIPOps
+	}
 
 	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
 		"cpi_url":                  cpiResource.URL,
@@ -101,7 +142,17 @@ type awsCloudConfigParams struct {
 	PublicSubnetID      string
 	Spot                bool
 	VMsSecurityGroupID  string
-	WorkerType          string
+	WebInstanceProfile  string
+	WebInstanceType     string
+	WebTargetGroups     []string
+	WindowsWorkerType   string
+	WorkerDiskIOPS      int
+	WorkerDiskSizeGB    int
+	WorkerDiskType      string
+	WorkerInstanceType  string
+	WorkerIMDSHopLimit  int
+	WorkerPoolTypes     map[string]string
+	WorkerSpotBid       int
 	PublicCIDR          string
 	PublicCIDRStatic    string
 	PublicCIDRReserved  string
@@ -109,6 +160,26 @@ type awsCloudConfigParams struct {
 	PrivateCIDR         string
 	PrivateCIDRGateway  string
 	PrivateCIDRReserved string
+	PrivateCIDRStatic   string
+	WorkerZones         []WorkerZone
+	Dedicated           bool
+	// WebVMTypes and WorkerVMTypes are the vm_types of each size, with the same instance types as
+	// list-instance-types reports. Compilation VMs are the large workers
+	WebVMTypes        []iaas.VMType
+	WorkerVMTypes     []iaas.VMType
+	CompilationVMType iaas.VMType
+}
+
+// SpotBid is the spot_bid_price for a worker instance type, rounded up to a hundredth of a cent
+func (p awsCloudConfigParams) SpotBid(onDemandPrice float64) string {
+	bid := math.Ceil(onDemandPrice*float64(p.WorkerSpotBid)*100-1e-6) / 10000
+	return strconv.FormatFloat(bid, 'f', -1, 64)
+}
+
+// PoolSpotBid is the spot_bid_price for a worker pool's instance type, which replaces the bid of the
+// vm_type the pool's VM extension is applied to
+func (p awsCloudConfigParams) PoolSpotBid(instanceType string) string {
+	return p.SpotBid(iaas.AWSOnDemandPrices[instanceType])
 }
 
 // ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
@@ -120,7 +191,16 @@ func (e AWSEnvironment) ConfigureDirectorCloudConfig() (string, error) {
 		PublicSubnetID:      e.PublicSubnetID,
 		PrivateSubnetID:     e.PrivateSubnetID,
 		Spot:                e.Spot,
-		WorkerType:          e.WorkerType,
+		WebInstanceProfile:  e.WebInstanceProfile,
+		WebInstanceType:     e.WebInstanceType,
+		WebTargetGroups:     e.WebTargetGroups,
+		WindowsWorkerType:   e.WindowsWorkerType,
+		WorkerDiskSizeGB:    e.WorkerDiskSizeGB,
+		WorkerDiskType:      e.WorkerDiskType,
+		WorkerInstanceType:  e.WorkerInstanceType,
+		WorkerIMDSHopLimit:  e.WorkerIMDSHopLimit,
+		WorkerPoolTypes:     e.WorkerPoolTypes,
+		WorkerSpotBid:       e.WorkerSpotBid,
 		PublicCIDR:          e.PublicCIDR,
 		PublicCIDRGateway:   e.PublicCIDRGateway,
 		PublicCIDRReserved:  e.PublicCIDRReserved,
@@ -128,6 +208,32 @@ func (e AWSEnvironment) ConfigureDirectorCloudConfig() (string, error) {
 		PrivateCIDR:         e.PrivateCIDR,
 		PrivateCIDRGateway:  e.PrivateCIDRGateway,
 		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		PrivateCIDRStatic:   e.PrivateCIDRStatic,
+		WorkerZones:         e.WorkerZones,
+		Dedicated:           e.Dedicated,
+		WebVMTypes:          iaas.AWSWebVMTypes(),
+		WorkerVMTypes:       iaas.AWSWorkerVMTypes(e.WorkerType),
+	}
+	for _, vmType := range templateParams.WorkerVMTypes {
+		if vmType.Size == "large" {
+			templateParams.CompilationVMType = vmType
+		}
+	}
+
+	if templateParams.WorkerIMDSHopLimit == 0 {
+		templateParams.WorkerIMDSHopLimit = defaultWorkerIMDSHopLimit
+	}
+	if templateParams.WorkerSpotBid == 0 {
+		templateParams.WorkerSpotBid = defaultWorkerSpotBid
+	}
+	if templateParams.WorkerDiskSizeGB == 0 {
+		templateParams.WorkerDiskSizeGB = defaultWorkerDiskSizeGB
+	}
+	if templateParams.WorkerDiskType == "" {
+		templateParams.WorkerDiskType = defaultAWSWorkerDiskType
+	}
+	if templateParams.WorkerDiskType == "io1" || templateParams.WorkerDiskType == "io2" {
+		templateParams.WorkerDiskIOPS = provisionedWorkerDiskIOPS
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.AWSDirectorCloudConfig, templateParams)
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"text/template"
 	"text/template/parse"
@@ -98,6 +99,140 @@ func TestAWSEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
 				return a == b, "m5 worker templating failed"
 			},
 		},
+		{
+			name:    "Success- worker IMDS hop limit rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WorkerIMDSHopLimit = 3
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Count(a, "http_put_response_hop_limit: 3") == 7 && strings.Count(a, "http_tokens: required") == 13, "worker IMDS hop limit templating failed"
+			},
+		},
+		{
+			name:    "Success- worker spot bid percentage rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.Spot = true
+				n.WorkerSpotBid = 150
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "spot_bid_price: 0.0708\n") && strings.Contains(a, "spot_bid_price: 5.568\n"), "worker spot bid templating failed"
+			},
+		},
+		{
+			name:    "Success- worker pool types rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WorkerPoolTypes = map[string]string{"worker-pool-docker-heavy": "m5.2xlarge"}
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: worker-pool-docker-heavy\n  cloud_properties:\n    instance_type: m5.2xlarge\n"), "worker pool types templating failed"
+			},
+		},
+		{
+			name:    "Success- spot worker pools bid on their own instance type",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.Spot = true
+				n.WorkerPoolTypes = map[string]string{"worker-pool-docker-heavy": "m5.2xlarge"}
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: worker-pool-docker-heavy\n  cloud_properties:\n    instance_type: m5.2xlarge\n    spot_bid_price: 0.5328\n"), "worker pool spot bid templating failed"
+			},
+		},
+		{
+			name:    "Success- windows worker type rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WindowsWorkerType = "m5.2xlarge"
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: concourse-windows\n  cloud_properties:\n    instance_type: m5.2xlarge\n"), "windows worker type templating failed"
+			},
+		},
+		{
+			name:    "Success- worker instance type rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WorkerInstanceType = "m7i.xlarge"
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: concourse-worker-custom\n  cloud_properties:\n    instance_type: m7i.xlarge\n"), "worker instance type templating failed"
+			},
+		},
+		{
+			name:    "Success- worker disk rendered with provisioned IOPS",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WorkerDiskSizeGB = 500
+				n.WorkerDiskType = "io2"
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "    ephemeral_disk:\n      size: 500_000\n      type: io2\n      iops: 3000\n      encrypted: true\n"), "worker disk templating failed"
+			},
+		},
+		{
+			name:    "Success- worker zones rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WorkerZones = []WorkerZone{{Name: "z2", Zone: "eu-west-1b", SubnetID: "subnet-b", CIDR: "10.0.2.0/24", Gateway: "10.0.2.1", Reserved: "[10.0.2.1-10.0.2.5]"}}
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: z2\n  cloud_properties:\n    availability_zone: eu-west-1b\n") &&
+					strings.Contains(a, "  - range: 10.0.2.0/24\n    gateway: 10.0.2.1\n    az: z2\n    reserved: [10.0.2.1-10.0.2.5]\n    cloud_properties:\n      subnet: subnet-b\n"), "worker zones templating failed"
+			},
+		},
+		{
+			name:    "Success- dedicated vm_extension rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.Dedicated = true
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "- name: dedicated\n  cloud_properties:\n    tenancy: dedicated\n"), "dedicated vm_extension templating failed"
+			},
+		},
+		{
+			name:    "Success- web instance profile rendered",
+			fields:  fullTemplateParams,
+			wantErr: false,
+			init: func(e AWSEnvironment) AWSEnvironment {
+				n := e
+				n.WebInstanceProfile = "web_instance_profile"
+				return n
+			},
+			validate: func(a, _ string) (bool, string) {
+				return strings.Contains(a, "    - atc_security_group\n    iam_instance_profile: web_instance_profile\n"), "web instance profile templating failed"
+			},
+		},
 		{
 			name:    "Success- m4 worker type is m4",
 			fields:  fullTemplateParams,
@@ -149,6 +284,27 @@ func listNodeFields(node parse.Node, res map[string]int) map[string]int {
 			res = listNodeFields(n, res)
 		}
 	}
+	// Inside range and with, dot is something else, so only fields reached through $ are the template's
+	switch n := node.(type) {
+	case *parse.RangeNode:
+		res = listScopedFields(n.Pipe, n.List, n.ElseList, res)
+	case *parse.WithNode:
+		res = listScopedFields(n.Pipe, n.List, n.ElseList, res)
+	}
+	return res
+}
+
+func listScopedFields(pipe *parse.PipeNode, list, elseList *parse.ListNode, res map[string]int) map[string]int {
+	res[regexp.MustCompile(`(^|\s)\.(\w+)`).FindStringSubmatch(pipe.String())[2]] = 1
+	var re = regexp.MustCompile(`\$\.(\w+)`)
+	for _, l := range []*parse.ListNode{list, elseList} {
+		if l == nil {
+			continue
+		}
+		for _, match := range re.FindAllStringSubmatch(l.String(), -1) {
+			res[match[1]] = 1
+		}
+	}
 	return res
 }
 
@@ -174,6 +330,9 @@ func Test_AWSCloudConfigStructureTest(t *testing.T) {
 		}
 		emptyAwsCloudConfigParams := awsCloudConfigParams{}
 		for k, v := range matchStructFields(emptyAwsCloudConfigParams, listTemplFields(templ)) {
+			if _, isMethod := reflect.TypeOf(emptyAwsCloudConfigParams).MethodByName(k); isMethod {
+				continue
+			}
 			if v < 2 {
 				t.Errorf("Field with key name %s is not mapped properly", k)
 			}
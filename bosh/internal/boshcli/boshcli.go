@@ -12,8 +12,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/EngineerBetter/control-tower/artifacts"
 	"github.com/EngineerBetter/control-tower/util"
 	"github.com/EngineerBetter/control-tower/util/yaml"
 )
@@ -33,20 +36,71 @@ type CreateEnvFiles struct {
 	VarsFileContents  []byte
 }
 
+// WorkerZone is a further zone that workers are spread across, as the BOSH AZ Name. On AWS the zone has
+// a private subnet of its own, SubnetID, which CIDR, Gateway and Reserved describe
+type WorkerZone struct {
+	Name     string
+	Zone     string
+	SubnetID string
+	CIDR     string
+	Gateway  string
+	Reserved string
+}
+
 // CLI struct holds the abstraction of execCmd
 type CLI struct {
 	execCmd  func(string, ...string) *exec.Cmd
 	boshPath string
+	options  Options
+}
+
+// Policy controls how long a bosh command may run and how it is retried after failing.
+// The delay between attempts starts at Backoff and doubles after each retry, up to maxBackoff
+type Policy struct {
+	Timeout time.Duration
+	Retries int
+	Backoff time.Duration
+}
+
+// Options holds the Policy applied to authenticated bosh commands, with overrides keyed by command name.
+// AllProxy, when set, is the BOSH_ALL_PROXY that every bosh command reaches the director through.
+// Mirror holds releases, stemcells and CLIs downloaded ahead of time, which are used instead of their URLs
+type Options struct {
+	Default  Policy
+	Commands map[string]Policy
+	AllProxy string
+	Mirror   artifacts.Mirror
 }
 
+const maxBackoff = 5 * time.Minute
+
+// reattachAttempts bounds how many times control-tower reconnects to a BOSH task that is still
+// running after the bosh CLI lost its connection to the director, eg through a proxy that drops idle connections
+const reattachAttempts = 10
+
+var taskIDPattern = regexp.MustCompile(`^Task (\d+)\b`)
+
 // New provides a new CLI
 func New(boshPath string, execCmdFunc func(string, ...string) *exec.Cmd) ICLI {
+	return NewWithOptions(boshPath, execCmdFunc, Options{})
+}
+
+// NewWithOptions provides a new CLI that applies timeouts and retries to authenticated commands
+func NewWithOptions(boshPath string, execCmdFunc func(string, ...string) *exec.Cmd, options Options) ICLI {
 	return &CLI{
 		execCmd:  execCmdFunc,
 		boshPath: boshPath,
+		options:  options,
 	}
 }
 
+func (o Options) policy(action string) Policy {
+	if p, ok := o.Commands[action]; ok {
+		return p
+	}
+	return o.Default
+}
+
 type IAASEnvironment interface {
 	ConfigureDirectorManifestCPI() (string, error)
 	ConfigureDirectorCloudConfig() (string, error)
@@ -100,10 +154,7 @@ func (c *CLI) UpdateCloudConfig(config IAASEnvironment, ip, password, ca string)
 	}
 	defer os.Remove(caPath)
 	ip = fmt.Sprintf("https://%s", ip)
-	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "update-cloud-config", cloudConfigPath)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	return c.runWithPolicy("update-cloud-config", os.Stdout, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "update-cloud-config", cloudConfigPath)
 }
 
 // Locks runs bosh locks
@@ -114,9 +165,7 @@ func (c *CLI) Locks(config IAASEnvironment, ip, password, ca string) ([]byte, er
 		return nil, err
 	}
 	defer os.Remove(caPath)
-	cmd := c.execCmd(c.boshPath, "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "locks", "--json")
-	cmd.Stdout = &out
-	err = cmd.Run()
+	err = c.runWithPolicy("locks", &out, "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "locks", "--json")
 	if err != nil {
 		return nil, err
 	}
@@ -141,10 +190,7 @@ func (c *CLI) UploadConcourseStemcell(config IAASEnvironment, ip, password, ca s
 	}
 	defer os.Remove(caPath)
 	ip = fmt.Sprintf("https://%s", ip)
-	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "upload-stemcell", stemcell)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	return c.runWithPolicy("upload-stemcell", os.Stdout, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "upload-stemcell", stemcell)
 }
 
 // Recreate runs BOSH recreate
@@ -155,10 +201,7 @@ func (c *CLI) Recreate(config IAASEnvironment, ip, password, ca string) error {
 	}
 	defer os.Remove(caPath)
 	ip = fmt.Sprintf("https://%s", ip)
-	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", "concourse", "recreate")
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	return c.runWithPolicy("recreate", os.Stdout, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", "concourse", "recreate")
 }
 
 func (c *CLI) CreateEnv(createEnvFiles *CreateEnvFiles, config IAASEnvironment, password, cert, key, ca string, tags map[string]string) (*CreateEnvFiles, error) {
@@ -204,7 +247,7 @@ func (c *CLI) CreateEnv(createEnvFiles *CreateEnvFiles, config IAASEnvironment,
 	}
 	defer os.Remove(manifestPath)
 
-	cmd := c.execCmd(c.boshPath, "create-env", "--state="+statePath, "--vars-store="+varsPath, manifestPath)
+	cmd := c.command("create-env", "--state="+statePath, "--vars-store="+varsPath, manifestPath)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 
@@ -243,19 +286,178 @@ func (c *CLI) RunAuthenticatedCommand(action, ip, password, ca string, detach bo
 	if detach && action == "deploy" {
 		return c.detachedBoshCommand(stdout, flags...)
 	}
-	return c.boshCommand(stdout, flags...)
+	return c.runWithPolicy(action, stdout, flags...)
+}
+
+// truncater is implemented by buffers, allowing the output of a failed attempt to be discarded before retrying
+type truncater interface {
+	Len() int
+	Truncate(n int)
+}
+
+// runWithPolicy runs a bosh command, retrying it with exponential backoff according to the policy for `action`.
+// If the command fails after starting a BOSH task that is still running, it reattaches to the task instead
+func (c *CLI) runWithPolicy(action string, stdout io.Writer, flags ...string) error {
+	policy := c.options.policy(action)
+	if len(c.options.Mirror) > 0 {
+		mirrored := make([]string, len(flags))
+		for i, flag := range flags {
+			// a stemcell is uploaded from its path, while a --var naming a release gets a file:// URL
+			mirrored[i] = string(c.options.Mirror.Rewrite([]byte(flag)))
+			if p, ok := c.options.Mirror.Path(flag); ok {
+				mirrored[i] = p
+			}
+		}
+		flags = mirrored
+	}
+	backoff := policy.Backoff
+
+	for attempt := 0; ; attempt++ {
+		mark := -1
+		if buf, ok := stdout.(truncater); ok {
+			mark = buf.Len()
+		}
+
+		watcher := &taskWatcher{w: stdout}
+		err := c.boshCommand(policy.Timeout, watcher, flags...)
+		var timedOut timeoutError
+		if err != nil && watcher.id != "" && !errors.As(err, &timedOut) {
+			return c.followTask(watcher.id, err, policy.Backoff, stdout, globalFlags(action, flags)...)
+		}
+		if err == nil || attempt >= policy.Retries {
+			return err
+		}
+
+		if mark >= 0 {
+			stdout.(truncater).Truncate(mark)
+		}
+		fmt.Fprintf(os.Stderr, "bosh %s failed: [%v], retrying in %s (attempt %d of %d)\n", action, err, backoff, attempt+2, policy.Retries+1)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
 }
 
-func (c *CLI) boshCommand(stdout io.Writer, flags ...string) error {
+func (c *CLI) boshCommand(timeout time.Duration, stdout io.Writer, flags ...string) error {
 	log.Println(c.boshPath, flags)
-	cmd := c.execCmd(c.boshPath, flags...)
+	cmd := c.command(flags...)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = stdout
-	return cmd.Run()
+	if timeout == 0 {
+		return cmd.Run()
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		<-done
+		return timeoutError{timeout}
+	}
+}
+
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.timeout)
+}
+
+// taskWatcher passes output through, noting the ID of the BOSH task that the command started
+type taskWatcher struct {
+	w    io.Writer
+	line []byte
+	id   string
+}
+
+func (t *taskWatcher) Write(p []byte) (int, error) {
+	if t.id == "" {
+		t.line = append(t.line, p...)
+		for {
+			i := bytes.IndexByte(t.line, '\n')
+			if i < 0 {
+				break
+			}
+			if m := taskIDPattern.FindSubmatch(bytes.TrimSpace(t.line[:i])); m != nil {
+				t.id = string(m[1])
+				t.line = nil
+				break
+			}
+			t.line = t.line[i+1:]
+		}
+	}
+	return t.w.Write(p)
+}
+
+// globalFlags returns the flags given before `action`, which authenticate against the director
+func globalFlags(action string, flags []string) []string {
+	for i, flag := range flags {
+		if flag == action {
+			return flags[:i:i]
+		}
+	}
+	return flags[:0:0]
+}
+
+// followTask waits for a BOSH task that was left running when the command watching it failed with cmdErr,
+// reattaching to its output while the director reports it as running
+func (c *CLI) followTask(id string, cmdErr error, delay time.Duration, stdout io.Writer, authFlags ...string) error {
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	err := cmdErr
+	for attempt := 1; attempt <= reattachAttempts; attempt++ {
+		state, stateErr := c.taskState(id, authFlags...)
+		if stateErr != nil {
+			err = stateErr
+			time.Sleep(delay)
+			continue
+		}
+		switch state {
+		case "done":
+			return nil
+		case "queued", "processing", "cancelling":
+			fmt.Fprintf(os.Stderr, "lost connection to BOSH task %s: [%v], reattaching (attempt %d of %d)\n", id, err, attempt, reattachAttempts)
+			if err = c.boshCommand(0, stdout, append(authFlags, "task", id)...); err == nil {
+				return nil
+			}
+			time.Sleep(delay)
+		default:
+			return cmdErr
+		}
+	}
+	return err
+}
+
+// taskState asks the director for the state of a task, eg processing, done or error
+func (c *CLI) taskState(id string, authFlags ...string) (string, error) {
+	var out bytes.Buffer
+	if err := c.boshCommand(0, &out, append(authFlags, "curl", "/tasks/"+id)...); err != nil {
+		return "", err
+	}
+	var task struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &task); err != nil {
+		return "", fmt.Errorf("error parsing state of BOSH task %s: [%v]", id, err)
+	}
+	return task.State, nil
 }
 
 func (c *CLI) detachedBoshCommand(stdout io.Writer, flags ...string) error {
-	cmd := c.execCmd(c.boshPath, flags...)
+	cmd := c.command(flags...)
 	cmd.Stderr = os.Stderr
 
 	cmdReader, err := cmd.StdoutPipe()
@@ -283,6 +485,19 @@ func (c *CLI) detachedBoshCommand(stdout io.Writer, flags ...string) error {
 	return fmt.Errorf("Didn't detect successful task start in BOSH comand: bosh-cli %s", strings.Join(flags, " "))
 }
 
+// command builds a bosh command, which reaches the director through the AllProxy when there is one
+func (c *CLI) command(flags ...string) *exec.Cmd {
+	cmd := c.execCmd(c.boshPath, flags...)
+	if c.options.AllProxy != "" {
+		env := cmd.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		cmd.Env = append(env, "BOSH_ALL_PROXY="+c.options.AllProxy)
+	}
+	return cmd
+}
+
 // If data is empty, return a path to where one could put a temp file
 func writeNonEmptyTempFile(data []byte, filename string) (string, error) {
 	if len(data) == 0 && filename != "" {
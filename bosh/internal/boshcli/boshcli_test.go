@@ -1,10 +1,13 @@
 package boshcli_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
 	"github.com/EngineerBetter/control-tower/internal/fakeexec"
@@ -16,7 +19,7 @@ func TestExecCommandHelper(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
-	fmt.Print(os.Getenv("STDOUT"))
+	fmt.Print(os.Getenv("STDOUT"), os.Getenv("BOSH_ALL_PROXY"))
 	i, _ := strconv.Atoi(os.Getenv("EXIT_STATUS"))
 	os.Exit(i)
 }
@@ -90,3 +93,116 @@ func TestCLI_UploadConcourseStemcell(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestCLI_RunAuthenticatedCommandRetries(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c := boshcli.NewWithOptions("bosh", e.Cmd(), boshcli.Options{
+		Default: boshcli.Policy{Retries: 2, Backoff: time.Millisecond},
+	})
+	isInstances := func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "instances", args[11])
+	}
+	failure := e.ExpectFunc(isInstances)
+	failure.Outputs("partial")
+	failure.Exits(1)
+	e.ExpectFunc(isInstances).Outputs("complete")
+
+	out := new(bytes.Buffer)
+	err := c.RunAuthenticatedCommand("instances", "ip", "password", "ca", false, out, "--json")
+	require.NoError(t, err)
+	require.Equal(t, "complete", out.String())
+}
+
+func TestCLI_RunAuthenticatedCommandUsesAllProxy(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c := boshcli.NewWithOptions("bosh", e.Cmd(), boshcli.Options{
+		AllProxy: "ssh+socks5://ubuntu@203.0.113.10:22?private-key=key",
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "instances", args[11])
+	}).Outputs("proxied through ")
+
+	out := new(bytes.Buffer)
+	err := c.RunAuthenticatedCommand("instances", "ip", "password", "ca", false, out, "--json")
+	require.NoError(t, err)
+	require.Equal(t, "proxied through ssh+socks5://ubuntu@203.0.113.10:22?private-key=key", out.String())
+}
+
+func TestCLI_RunAuthenticatedCommandGivesUp(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c := boshcli.NewWithOptions("bosh", e.Cmd(), boshcli.Options{
+		Default: boshcli.Policy{Retries: 5, Backoff: time.Millisecond},
+		Commands: map[string]boshcli.Policy{
+			"deploy": {Retries: 1, Backoff: time.Millisecond},
+		},
+	})
+	for i := 0; i < 2; i++ {
+		e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+			require.Equal(t, "deploy", args[11])
+		}).Exits(1)
+	}
+
+	err := c.RunAuthenticatedCommand("deploy", "ip", "password", "ca", false, new(bytes.Buffer))
+	require.Error(t, err)
+}
+
+func TestCLI_RunAuthenticatedCommandTimesOut(t *testing.T) {
+	c := boshcli.NewWithOptions("bosh", func(string, ...string) *exec.Cmd {
+		return exec.Command("sleep", "10")
+	}, boshcli.Options{
+		Commands: map[string]boshcli.Policy{
+			"instances": {Timeout: 50 * time.Millisecond},
+		},
+	})
+
+	start := time.Now()
+	err := c.RunAuthenticatedCommand("instances", "ip", "password", "ca", false, new(bytes.Buffer))
+	require.EqualError(t, err, "timed out after 50ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCLI_RunAuthenticatedCommandReattachesToRunningTask(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c := boshcli.NewWithOptions("bosh", e.Cmd(), boshcli.Options{
+		Default: boshcli.Policy{Retries: 2, Backoff: time.Millisecond},
+	})
+	dropped := e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "deploy", args[11])
+	})
+	dropped.Outputs("Using deployment 'concourse'\nTask 42\n\nTask 42 | 10:00:00 | Preparing deployment\n")
+	dropped.Exits(1)
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, []string{"--deployment", "concourse", "curl", "/tasks/42"}, args[9:])
+	}).Outputs(`{"id":42,"state":"processing"}`)
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, []string{"task", "42"}, args[11:])
+	}).Outputs("Task 42 done\n")
+
+	out := new(bytes.Buffer)
+	err := c.RunAuthenticatedCommand("deploy", "ip", "password", "ca", false, out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Task 42 done")
+}
+
+func TestCLI_RunAuthenticatedCommandDoesNotRerunFailedTask(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c := boshcli.NewWithOptions("bosh", e.Cmd(), boshcli.Options{
+		Default: boshcli.Policy{Retries: 2, Backoff: time.Millisecond},
+	})
+	failed := e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "deploy", args[11])
+	})
+	failed.Outputs("Task 42\n")
+	failed.Exits(1)
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "curl", args[11])
+	}).Outputs(`{"id":42,"state":"error"}`)
+
+	err := c.RunAuthenticatedCommand("deploy", "ip", "password", "ca", false, new(bytes.Buffer))
+	require.EqualError(t, err, "exit status 1")
+}
@@ -3,6 +3,7 @@ package boshcli
 import (
 	"io/ioutil"
 
+	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
 	"github.com/EngineerBetter/control-tower/util"
 	"github.com/EngineerBetter/control-tower/util/yaml"
@@ -21,6 +22,7 @@ type GCPEnvironment struct {
 	PrivateCIDR         string
 	PrivateCIDRGateway  string
 	PrivateCIDRReserved string
+	PrivateCIDRStatic   string
 	PrivateSubnetwork   string
 	ProjectID           string
 	PublicCIDR          string
@@ -32,7 +34,23 @@ type GCPEnvironment struct {
 	Spot                bool
 	Tags                string
 	VersionFile         []byte
+	WebInstanceType     string
+	WebTargetPool       string
+	WindowsWorkerType   string
+	WorkerDiskSizeGB    int
+	WorkerDiskType      string
+	WorkerInstanceType  string
+	WorkerPoolGPUs      map[string]string
+	WorkerPoolTypes     map[string]string
 	Zone                string
+	// RestrictWorkerEgress tags workers with restricted-egress, which the firewall denies egress from
+	RestrictWorkerEgress bool
+	// WorkerZones are added to the cloud config as further AZs of the private network, which spans the region
+	WorkerZones []WorkerZone
+	// SoleTenantNodeGroup adds the dedicated vm_extension, which places VMs on the node group
+	SoleTenantNodeGroup string
+	// Private leaves out the director's public address, so that it is only reached on its InternalIP
+	Private bool
 }
 
 func (e GCPEnvironment) ExtractBOSHandBPM() (util.Resource, util.Resource, error) {
@@ -57,7 +75,11 @@ func (e GCPEnvironment) ConfigureDirectorManifestCPI() (string, error) {
 		return "", err
 	}
 
-	var allOperations = resource.GCPCPIOps + resource.GCPExternalIPOps + resource.GCPDirectorCustomOps + resource.GCPJumpboxUserOps
+	var allOperations = resource.GCPCPIOps + resource.GCPDirectorCustomOps + resource.GCPJumpboxUserOps
+	// The director of a private deployment is reached on its internal IP, which ExternalIP is then set to
+	if !e.Private {
+		allOperations += resource.GCPExternalIPOps
+	}
 
 	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
 		"cpi_url":              cpiResource.URL,
@@ -93,6 +115,24 @@ type gcpCloudConfigParams struct {
 	PrivateCIDR         string
 	PrivateCIDRGateway  string
 	PrivateCIDRReserved string
+	PrivateCIDRStatic   string
+	WebInstanceType     string
+	WebTargetPool       string
+	WindowsWorkerType   string
+	WorkerDiskSizeGB    int
+	WorkerDiskType      string
+	WorkerInstanceType  string
+	WorkerPoolTypes     map[string]string
+	WorkerPoolGPUs      map[string]string
+	// RestrictWorkerEgress tags every worker vm_type with restricted-egress
+	RestrictWorkerEgress bool
+	WorkerZones          []WorkerZone
+	SoleTenantNodeGroup  string
+	// WebVMTypes and WorkerVMTypes are the vm_types of each size, with the same machine types as
+	// list-instance-types reports. Compilation VMs are the large workers
+	WebVMTypes        []iaas.VMType
+	WorkerVMTypes     []iaas.VMType
+	CompilationVMType iaas.VMType
 }
 
 // ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
@@ -110,6 +150,30 @@ func (e GCPEnvironment) ConfigureDirectorCloudConfig() (string, error) {
 		PrivateCIDR:         e.PrivateCIDR,
 		PrivateCIDRGateway:  e.PrivateCIDRGateway,
 		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		PrivateCIDRStatic:   e.PrivateCIDRStatic,
+		WebTargetPool:       e.WebTargetPool,
+		WorkerPoolTypes:     e.WorkerPoolTypes,
+		WorkerPoolGPUs:      e.WorkerPoolGPUs,
+		WebInstanceType:     e.WebInstanceType,
+		WindowsWorkerType:   e.WindowsWorkerType,
+		WorkerDiskSizeGB:    e.WorkerDiskSizeGB,
+		WorkerDiskType:      e.WorkerDiskType,
+		WorkerInstanceType:  e.WorkerInstanceType,
+
+		RestrictWorkerEgress: e.RestrictWorkerEgress,
+		WorkerZones:          e.WorkerZones,
+		SoleTenantNodeGroup:  e.SoleTenantNodeGroup,
+		WebVMTypes:           iaas.GCPWebVMTypes(),
+		WorkerVMTypes:        iaas.GCPWorkerVMTypes(),
+	}
+	for _, vmType := range templateParams.WorkerVMTypes {
+		if vmType.Size == "large" {
+			templateParams.CompilationVMType = vmType
+		}
+	}
+
+	if templateParams.WorkerDiskSizeGB == 0 {
+		templateParams.WorkerDiskSizeGB = defaultWorkerDiskSizeGB
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.GCPDirectorCloudConfig, templateParams)
@@ -57,6 +57,7 @@ var _ = Describe("GCPEnvironment", func() {
 			BeforeEach(func() {
 				expected = getFixture("../fixtures/gcp_cloud_config_spot.yml")
 				environment.Spot = true
+				environment.WorkerDiskType = "pd-ssd"
 			})
 
 			It("renders the expected YAML", func() {
@@ -65,6 +66,31 @@ var _ = Describe("GCPEnvironment", func() {
 				Expect(actual).To(Equal(expected))
 			})
 		})
+
+		Context("when workers are spread across further zones", func() {
+			BeforeEach(func() {
+				environment.WorkerZones = []WorkerZone{{Name: "z2", Zone: "other_zone"}}
+			})
+
+			It("adds each zone as an AZ of the private network", func() {
+				actual, err := environment.ConfigureDirectorCloudConfig()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(ContainSubstring("- name: z2\n  cloud_properties:\n    zone: other_zone\n"))
+				Expect(actual).To(ContainSubstring("    gateway: private_cidr_gateway\n    azs: [z1, z2]\n"))
+			})
+		})
+
+		Context("when VMs are placed on a sole-tenant node group", func() {
+			BeforeEach(func() {
+				environment.SoleTenantNodeGroup = "node_group"
+			})
+
+			It("adds the dedicated vm_extension", func() {
+				actual, err := environment.ConfigureDirectorCloudConfig()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(ContainSubstring("- name: dedicated\n  cloud_properties:\n    node_group: node_group\n"))
+			})
+		})
 	})
 })
 
@@ -0,0 +1,209 @@
+package bosh
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// migrationsURLFormat lists the ATC migrations shipped with a Concourse release tag
+	migrationsURLFormat = "https://api.github.com/repos/concourse/concourse/contents/atc/db/migration/migrations?ref=v%s"
+	// rough rate at which Postgres rewrites a table during a migration, used only for estimates
+	migrationBytesPerSecond = 50 * 1024 * 1024
+	migrationBaseDuration   = time.Second
+)
+
+// Migration is an ATC schema migration that has not yet been applied
+type Migration struct {
+	Version int64
+	Name    string
+	// Tables are the existing tables the migration appears to modify, judged by its name
+	Tables []string
+}
+
+// MigrationPreview describes the ATC schema migrations a Concourse upgrade will run
+type MigrationPreview struct {
+	ConcourseVersion  string
+	Pending           []Migration
+	EstimatedDuration time.Duration
+}
+
+// String describes the migrations for the user
+func (p MigrationPreview) String() string {
+	if len(p.Pending) == 0 {
+		return fmt.Sprintf("No database migrations are pending for Concourse %s\n", p.ConcourseVersion)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrading to Concourse %s will run %d database migrations, estimated to take %s:\n", p.ConcourseVersion, len(p.Pending), p.EstimatedDuration)
+	for _, m := range p.Pending {
+		fmt.Fprintf(&b, "  %d %s", m.Version, m.Name)
+		if len(m.Tables) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(m.Tables, ", "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Concourse web will be unavailable while migrations run\n")
+	return b.String()
+}
+
+// concourseReleaseVersion returns the Concourse release version pinned in a versions ops file
+func concourseReleaseVersion(versionsFile []byte) (string, error) {
+	var ops []struct {
+		Path  string
+		Value json.RawMessage
+	}
+	if err := json.Unmarshal(versionsFile, &ops); err != nil {
+		return "", err
+	}
+	for _, op := range ops {
+		if op.Path != "/releases/name=concourse" {
+			continue
+		}
+		var release struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(op.Value, &release); err != nil {
+			return "", err
+		}
+		if release.Version != "" {
+			return release.Version, nil
+		}
+	}
+	return "", fmt.Errorf("did not find concourse release version in versions file")
+}
+
+// releaseMigrations lists the names of the migration files shipped with a Concourse version
+func releaseMigrations(concourseVersion string) ([]string, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	url := fmt.Sprintf(migrationsURLFormat, concourseVersion)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	var files []struct {
+		Name string `json:"name"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	return names, nil
+}
+
+// currentMigrationVersion returns the last migration applied to the ATC database, or 0 if
+// Concourse has never run against it
+func currentMigrationVersion(db *sql.DB) (int64, error) {
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'migrations_history')`).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+	var version int64
+	err := db.QueryRow(`SELECT version FROM migrations_history WHERE direction = 'up' AND status = 'passed' ORDER BY version DESC LIMIT 1`).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+func tableSizes(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.Query(`SELECT relname, pg_total_relation_size(relid) FROM pg_catalog.pg_statio_user_tables`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sizes := map[string]int64{}
+	for rows.Next() {
+		var name string
+		var size int64
+		if err = rows.Scan(&name, &size); err != nil {
+			return nil, err
+		}
+		sizes[name] = size
+	}
+	return sizes, rows.Err()
+}
+
+// planMigrations works out which of the migration files are newer than current, estimating their
+// duration from the size of the tables each one names
+func planMigrations(concourseVersion string, current int64, files []string, sizes map[string]int64) MigrationPreview {
+	preview := MigrationPreview{ConcourseVersion: concourseVersion}
+	seen := map[int64]bool{}
+	for _, file := range files {
+		if !strings.Contains(file, ".up.") {
+			continue
+		}
+		parts := strings.SplitN(file, "_", 2)
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) != 2 || version <= current || seen[version] {
+			continue
+		}
+		seen[version] = true
+
+		name := parts[1][:strings.Index(parts[1], ".up.")]
+		migration := Migration{Version: version, Name: name}
+		preview.EstimatedDuration += migrationBaseDuration
+		for table, size := range sizes {
+			if strings.Contains(name, table) {
+				migration.Tables = append(migration.Tables, table)
+				preview.EstimatedDuration += time.Duration(size/migrationBytesPerSecond) * time.Second
+			}
+		}
+		sort.Strings(migration.Tables)
+		preview.Pending = append(preview.Pending, migration)
+	}
+	sort.Slice(preview.Pending, func(i, j int) bool { return preview.Pending[i].Version < preview.Pending[j].Version })
+	return preview
+}
+
+// previewMigrations compares the migrations applied to the ATC database with those in the
+// Concourse release about to be deployed
+func previewMigrations(opener Opener, dbName, concourseVersion string, versionsFile []byte) (MigrationPreview, error) {
+	var err error
+	if concourseVersion == "" {
+		concourseVersion, err = concourseReleaseVersion(versionsFile)
+		if err != nil {
+			return MigrationPreview{}, err
+		}
+	}
+
+	db, err := opener.Open(dbName)
+	if err != nil {
+		return MigrationPreview{}, err
+	}
+	defer db.Close()
+
+	current, err := currentMigrationVersion(db)
+	if err != nil {
+		return MigrationPreview{}, fmt.Errorf("failed to read applied migrations: [%v]", err)
+	}
+	if current == 0 {
+		return MigrationPreview{ConcourseVersion: concourseVersion}, nil
+	}
+	sizes, err := tableSizes(db)
+	if err != nil {
+		return MigrationPreview{}, fmt.Errorf("failed to read table sizes: [%v]", err)
+	}
+	files, err := releaseMigrations(concourseVersion)
+	if err != nil {
+		return MigrationPreview{}, fmt.Errorf("failed to list migrations for Concourse %s: [%v]", concourseVersion, err)
+	}
+
+	return planMigrations(concourseVersion, current, files, sizes), nil
+}
@@ -0,0 +1,55 @@
+package bosh
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_planMigrations(t *testing.T) {
+	files := []string{
+		"1600000000_create_builds.up.sql",
+		"1600000000_create_builds.down.sql",
+		"1700000000_add_index_to_build_events.up.sql",
+		"1700000000_add_index_to_build_events.down.sql",
+		"1710000000_rename_resource_config_versions.up.go",
+		"migrations.go",
+	}
+	sizes := map[string]int64{
+		"build_events":             10 * migrationBytesPerSecond,
+		"resource_config_versions": 2 * migrationBytesPerSecond,
+		"teams":                    migrationBytesPerSecond,
+	}
+
+	got := planMigrations("7.11.2", 1600000000, files, sizes)
+	want := MigrationPreview{
+		ConcourseVersion: "7.11.2",
+		Pending: []Migration{
+			{Version: 1700000000, Name: "add_index_to_build_events", Tables: []string{"build_events"}},
+			{Version: 1710000000, Name: "rename_resource_config_versions", Tables: []string{"resource_config_versions"}},
+		},
+		EstimatedDuration: 14 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planMigrations() = %+v, want %+v", got, want)
+	}
+
+	if got := planMigrations("7.11.2", 1710000000, files, sizes); len(got.Pending) != 0 {
+		t.Errorf("expected no pending migrations once all are applied, got %+v", got.Pending)
+	}
+}
+
+func Test_concourseReleaseVersion(t *testing.T) {
+	versions := []byte(`[
+		{"type": "replace", "path": "/stemcells/alias=jammy/version", "value": "1.0"},
+		{"type": "replace", "path": "/releases/name=concourse", "value": {"name": "concourse", "version": "7.11.2"}}
+	]`)
+	got, err := concourseReleaseVersion(versions)
+	if err != nil || got != "7.11.2" {
+		t.Errorf("concourseReleaseVersion() = %q, %v", got, err)
+	}
+
+	if _, err = concourseReleaseVersion([]byte(`[{"type": "replace", "path": "/stemcells/alias=jammy/version", "value": "1.0"}]`)); err == nil {
+		t.Error("expected an error when the versions file does not pin concourse")
+	}
+}
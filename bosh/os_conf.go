@@ -0,0 +1,26 @@
+package bosh
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/iaas"
+)
+
+// OSConfReleaseVersion is the version of the os-conf release added by os-conf-release.yml. Its checksum
+// is looked up from bosh.io the first time a deployment needs the release, and kept in the config
+const OSConfReleaseVersion = "23.0.0"
+
+// osConfReleaseURL is where bosh.io serves the os-conf release that os-conf-release.yml adds
+var osConfReleaseURL = fmt.Sprintf("https://bosh.io/d/github.com/cloudfoundry/os-conf-release?v=%s", OSConfReleaseVersion)
+
+// UsesOSConf is true when the deployment runs os-conf jobs on its workers, and so needs the checksum
+// of the os-conf release. They watch for preemption on GCP, configure the kernel, and install GPU drivers
+func UsesOSConf(conf config.ConfigView, name iaas.Name) bool {
+	for _, pool := range conf.GetWorkerPools() {
+		if pool.GPU != "" {
+			return true
+		}
+	}
+	return (name == iaas.GCP && conf.IsSpot()) || conf.GetWorkerCgroupVersion() != "" || len(conf.GetWorkerSysctls()) > 0
+}
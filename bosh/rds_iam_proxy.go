@@ -0,0 +1,196 @@
+package bosh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EngineerBetter/control-tower/artifacts"
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
+	"github.com/EngineerBetter/control-tower/bosh/internal/workingdir"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+)
+
+const (
+	rdsIAMProxyReleaseName = "rds-iam-proxy"
+	// rdsIAMProxyReleaseVersion is bumped whenever the jobs or packages of the release change, as the
+	// director keeps the first release it is given of each version
+	rdsIAMProxyReleaseVersion = "1"
+	// rdsIAMProxyPort is where the proxy listens on the web VMs, on 127.0.0.1 only
+	rdsIAMProxyPort = 6432
+)
+
+// rdsIAMProxyRelease is the BOSH release of the proxy that logs into RDS with IAM authentication tokens on
+// behalf of Concourse, UAA and CredHub. It is created from these jobs and packages when it is first needed
+//
+//go:embed assets/rds-iam-proxy
+var rdsIAMProxyRelease embed.FS
+
+// rdsIAMProxySources are the source tarballs of the release's packages, which are compiled by the director.
+// Each is checked against its SHA256, whether it is downloaded or taken from a mirror
+var rdsIAMProxySources = []artifacts.Artifact{
+	{
+		URL:    "https://www.pgbouncer.org/downloads/files/1.23.1/pgbouncer-1.23.1.tar.gz",
+		SHA256: "1963b497231d9a560a62d266e4a2eae6881ab401853d93e5d292c3740eec5084",
+	},
+	{
+		URL:    "https://github.com/libevent/libevent/releases/download/release-2.1.12-stable/libevent-2.1.12-stable.tar.gz",
+		SHA256: "92e6de1be9ec176428fd2367677e61ceffc2ee1cb119035037a27d346b0403bb",
+	},
+}
+
+// rdsIAMProxyVars sets the vars of rds-iam-proxy.yml, which points Concourse, UAA and CredHub at the proxy
+// in place of the database, logging into it with a password that is only good for the proxy
+func rdsIAMProxyVars(vmap map[string]interface{}, conf config.ConfigView, dbAddress, dbPort string) {
+	serverCA := conf.GetDBCACert()
+	if serverCA == "" {
+		serverCA = db.RDSRootCA()
+	}
+	vmap["postgres_host"] = "127.0.0.1"
+	vmap["postgres_port"] = rdsIAMProxyPort
+	vmap["postgres_role"] = db.IAMRole
+	vmap["postgres_password"] = conf.GetDBProxyPassword()
+	vmap["postgres_ca_cert"] = conf.GetDBProxyCACert()
+	vmap["rds_iam_proxy_release_version"] = rdsIAMProxyReleaseVersion
+	vmap["rds_iam_proxy_region"] = conf.GetRegion()
+	vmap["rds_iam_proxy_host"] = dbAddress
+	vmap["rds_iam_proxy_server_port"] = dbPort
+	vmap["rds_iam_proxy_databases"] = componentDatabaseNames(conf)
+	vmap["rds_iam_proxy_server_ca_cert"] = serverCA
+	vmap["rds_iam_proxy_cert"] = conf.GetDBProxyCert()
+	vmap["rds_iam_proxy_key"] = conf.GetDBProxyKey()
+}
+
+// uploadRDSIAMProxyRelease creates the rds-iam-proxy release and uploads it to the director, unless the
+// director already has this version of it. The package sources are downloaded, or taken from the artifacts
+// that a deploy without internet access is given
+func uploadRDSIAMProxyRelease(boshCLI boshcli.ICLI, dir workingdir.IClient, ip, password, ca string, stdout io.Writer) error {
+	uploaded, err := releaseUploaded(boshCLI, ip, password, ca, rdsIAMProxyReleaseName, rdsIAMProxyReleaseVersion)
+	if err != nil {
+		return err
+	}
+	if uploaded {
+		return nil
+	}
+
+	releaseDir := dir.PathInWorkingDir(rdsIAMProxyReleaseName)
+	if err = writeRDSIAMProxyRelease(releaseDir); err != nil {
+		return fmt.Errorf("failed to write the %s release: [%v]", rdsIAMProxyReleaseName, err)
+	}
+	var mirror artifacts.Mirror
+	if mirrored, ok := dir.(mirroredWorkingDir); ok {
+		mirror = mirrored.mirror
+	}
+	if err = fetchSources(rdsIAMProxySources, mirror, filepath.Join(releaseDir, "src"), stdout); err != nil {
+		return err
+	}
+
+	tarball := filepath.Join(releaseDir, "release.tgz")
+	err = boshCLI.RunAuthenticatedCommand("create-release", ip, password, ca, false, stdout,
+		"--dir", releaseDir, "--name", rdsIAMProxyReleaseName, "--version", rdsIAMProxyReleaseVersion, "--force", "--tarball", tarball)
+	if err != nil {
+		return fmt.Errorf("failed to create the %s release: [%v]", rdsIAMProxyReleaseName, err)
+	}
+	return boshCLI.RunAuthenticatedCommand("upload-release", ip, password, ca, false, stdout, tarball)
+}
+
+// writeRDSIAMProxyRelease writes the embedded jobs and packages of the release to dir
+func writeRDSIAMProxyRelease(dir string) error {
+	const root = "assets/rds-iam-proxy"
+	return fs.WalkDir(rdsIAMProxyRelease, root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(p, root)))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		contents, err := rdsIAMProxyRelease.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, contents, 0644)
+	})
+}
+
+// fetchSources puts each source into dir under the name it was published with, copying it from the mirror
+// when it has been downloaded there
+func fetchSources(sources []artifacts.Artifact, mirror artifacts.Mirror, dir string, stdout io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	downloadDir := filepath.Join(dir, ".downloads")
+	for _, source := range sources {
+		from, ok := mirror.Path(source.URL)
+		if !ok {
+			index, err := artifacts.Download([]artifacts.Artifact{source}, downloadDir, stdout)
+			if err != nil {
+				return err
+			}
+			from = filepath.Join(downloadDir, index[source.URL].File)
+		}
+		contents, err := ioutil.ReadFile(from)
+		if err != nil {
+			return err
+		}
+		if err = checkSourceSHA256(source, contents); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, path.Base(source.URL)), contents, 0644); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(downloadDir)
+}
+
+// checkSourceSHA256 fails unless contents match the SHA256 of source, so that a tarball that has been
+// replaced, upstream or in a mirror, is never compiled into the release
+func checkSourceSHA256(source artifacts.Artifact, contents []byte) error {
+	if source.SHA256 == "" {
+		return fmt.Errorf("%s has no SHA256 to check it against", source.URL)
+	}
+	sum := sha256.Sum256(contents)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, source.SHA256) {
+		return fmt.Errorf("%s has checksum %s, expected %s", source.URL, got, source.SHA256)
+	}
+	return nil
+}
+
+// releaseUploaded is true when the director has the given version of a release
+func releaseUploaded(boshCLI boshcli.ICLI, ip, password, ca, name, version string) (bool, error) {
+	var output bytes.Buffer
+	if err := boshCLI.RunAuthenticatedCommand("releases", ip, password, ca, false, &output, "--json"); err != nil {
+		return false, fmt.Errorf("failed to list the releases on the director: [%v]", err)
+	}
+	var releases struct {
+		Tables []struct {
+			Rows []struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"Rows"`
+		} `json:"Tables"`
+	}
+	if err := json.Unmarshal(output.Bytes(), &releases); err != nil {
+		return false, fmt.Errorf("failed to parse the releases on the director: [%v]", err)
+	}
+	for _, table := range releases.Tables {
+		for _, row := range table.Rows {
+			// versions in use by a deployment are marked with a *
+			if row.Name == name && strings.TrimSuffix(row.Version, "*") == version {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,247 @@
+package bosh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/artifacts"
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli/boshclifakes"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+)
+
+func Test_releaseUploaded(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{
+			name:   "deployed",
+			output: `{"Tables":[{"Rows":[{"name":"concourse","version":"7.11.2*"},{"name":"rds-iam-proxy","version":"1*"}]}]}`,
+			want:   true,
+		},
+		{
+			name:   "uploaded",
+			output: `{"Tables":[{"Rows":[{"name":"rds-iam-proxy","version":"1"}]}]}`,
+			want:   true,
+		},
+		{
+			name:   "another version",
+			output: `{"Tables":[{"Rows":[{"name":"rds-iam-proxy","version":"11"}]}]}`,
+		},
+		{
+			name:   "missing",
+			output: `{"Tables":[{"Rows":[{"name":"concourse","version":"7.11.2*"}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boshCLI := new(boshclifakes.FakeICLI)
+			boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+				_, err := io.WriteString(stdout, tt.output)
+				return err
+			}
+			got, err := releaseUploaded(boshCLI, "1.2.3.4", "password", "ca", rdsIAMProxyReleaseName, "1")
+			if err != nil {
+				t.Fatalf("releaseUploaded() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("releaseUploaded() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uploadRDSIAMProxyRelease(t *testing.T) {
+	mirrorDir, err := ioutil.TempDir("", "rds-iam-proxy-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mirrorDir)
+	// The mirror holds stand-ins for the tarballs, so they are checked against their own checksums
+	realSources := rdsIAMProxySources
+	defer func() { rdsIAMProxySources = realSources }()
+	rdsIAMProxySources = nil
+	mirror := artifacts.Mirror{}
+	for _, source := range realSources {
+		p := filepath.Join(mirrorDir, path.Base(source.URL))
+		if err = ioutil.WriteFile(p, []byte(source.URL), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mirror[source.URL] = p
+		rdsIAMProxySources = append(rdsIAMProxySources, artifacts.Artifact{URL: source.URL, SHA256: sha256Hex(source.URL)})
+	}
+	workDir, err := ioutil.TempDir("", "rds-iam-proxy-release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workDir)
+	dir := mirroredWorkingDir{IClient: fixedWorkingDir(workDir), mirror: mirror}
+
+	boshCLI := new(boshclifakes.FakeICLI)
+	boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+		if action == "releases" {
+			_, err := io.WriteString(stdout, `{"Tables":[{"Rows":[]}]}`)
+			return err
+		}
+		return nil
+	}
+	if err = uploadRDSIAMProxyRelease(boshCLI, dir, "1.2.3.4", "password", "ca", ioutil.Discard); err != nil {
+		t.Fatalf("uploadRDSIAMProxyRelease() error = %v", err)
+	}
+
+	releaseDir := filepath.Join(workDir, rdsIAMProxyReleaseName)
+	for _, pkg := range []string{"libevent", "pgbouncer"} {
+		spec, err := ioutil.ReadFile(filepath.Join(releaseDir, "packages", pkg, "spec"))
+		if err != nil {
+			t.Fatalf("package %s wasn't written: %v", pkg, err)
+		}
+		for _, source := range rdsIAMProxySources {
+			name := path.Base(source.URL)
+			if strings.HasPrefix(name, pkg+"-") && !strings.Contains(string(spec), "- "+name) {
+				t.Errorf("package %s doesn't build from %s", pkg, name)
+			}
+		}
+	}
+	for _, source := range rdsIAMProxySources {
+		contents, err := ioutil.ReadFile(filepath.Join(releaseDir, "src", path.Base(source.URL)))
+		if err != nil || string(contents) != source.URL {
+			t.Errorf("source %s wasn't taken from the mirror: %q, %v", source.URL, contents, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(releaseDir, "jobs", "rds-iam-proxy", "templates", "run.erb")); err != nil {
+		t.Errorf("job wasn't written: %v", err)
+	}
+
+	var actions []string
+	for i := 0; i < boshCLI.RunAuthenticatedCommandCallCount(); i++ {
+		action, _, _, _, _, _, _ := boshCLI.RunAuthenticatedCommandArgsForCall(i)
+		actions = append(actions, action)
+	}
+	if strings.Join(actions, ",") != "releases,create-release,upload-release" {
+		t.Errorf("uploadRDSIAMProxyRelease() ran %v", actions)
+	}
+}
+
+func Test_fetchSources(t *testing.T) {
+	for _, source := range rdsIAMProxySources {
+		if source.SHA256 == "" {
+			t.Errorf("%s has no SHA256", source.URL)
+		}
+	}
+
+	mirrorDir, err := ioutil.TempDir("", "rds-iam-proxy-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mirrorDir)
+	const url = "https://example.com/source-1.0.tar.gz"
+	p := filepath.Join(mirrorDir, "source-1.0.tar.gz")
+	if err = ioutil.WriteFile(p, []byte("replaced"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirror := artifacts.Mirror{url: p}
+
+	tests := []struct {
+		name    string
+		source  artifacts.Artifact
+		wantErr string
+	}{
+		{name: "matching", source: artifacts.Artifact{URL: url, SHA256: sha256Hex("replaced")}},
+		{
+			name:    "mismatched",
+			source:  artifacts.Artifact{URL: url, SHA256: sha256Hex("original")},
+			wantErr: fmt.Sprintf("%s has checksum %s, expected %s", url, sha256Hex("replaced"), sha256Hex("original")),
+		},
+		{name: "unchecked", source: artifacts.Artifact{URL: url}, wantErr: url + " has no SHA256 to check it against"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(mirrorDir, tt.name)
+			err := fetchSources([]artifacts.Artifact{tt.source}, mirror, dir, ioutil.Discard)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("fetchSources() error = %v", err)
+				}
+				if _, err = os.Stat(filepath.Join(dir, "source-1.0.tar.gz")); err != nil {
+					t.Errorf("fetchSources() didn't copy the source: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("fetchSources() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_rdsIAMProxyVars(t *testing.T) {
+	vmap := map[string]interface{}{}
+	conf := config.Config{
+		Deployment:      "control-tower-ci",
+		Region:          "eu-west-1",
+		DBIAMAuth:       true,
+		DBProxyPassword: "proxy-password",
+		DBProxyCACert:   "proxy-ca",
+		DBProxyCert:     "proxy-cert",
+		DBProxyKey:      "proxy-key",
+	}
+	rdsIAMProxyVars(vmap, conf, "db.example.com", "5432")
+
+	for name, want := range map[string]interface{}{
+		"postgres_host":                "127.0.0.1",
+		"postgres_port":                rdsIAMProxyPort,
+		"postgres_role":                db.IAMRole,
+		"postgres_password":            "proxy-password",
+		"postgres_ca_cert":             "proxy-ca",
+		"rds_iam_proxy_region":         "eu-west-1",
+		"rds_iam_proxy_host":           "db.example.com",
+		"rds_iam_proxy_server_port":    "5432",
+		"rds_iam_proxy_server_ca_cert": db.RDSRootCA(),
+		"rds_iam_proxy_cert":           "proxy-cert",
+		"rds_iam_proxy_key":            "proxy-key",
+	} {
+		if vmap[name] != want {
+			t.Errorf("%s = %v, want %v", name, vmap[name], want)
+		}
+	}
+	if databases := vmap["rds_iam_proxy_databases"].([]string); strings.Join(databases, ",") != "concourse_atc,uaa,credhub" {
+		t.Errorf("rds_iam_proxy_databases = %v", databases)
+	}
+
+	conf.DBCACert = "rotated-ca"
+	rdsIAMProxyVars(vmap, conf, "db.example.com", "5432")
+	if vmap["rds_iam_proxy_server_ca_cert"] != "rotated-ca" {
+		t.Errorf("rds_iam_proxy_server_ca_cert = %v, want the CA the database was rotated onto", vmap["rds_iam_proxy_server_ca_cert"])
+	}
+}
+
+// fixedWorkingDir is a working directory at a path chosen by the test
+type fixedWorkingDir string
+
+func (d fixedWorkingDir) SaveFileToWorkingDir(name string, contents []byte) (string, error) {
+	p := filepath.Join(string(d), name)
+	return p, ioutil.WriteFile(p, contents, 0644)
+}
+
+func (d fixedWorkingDir) PathInWorkingDir(name string) string {
+	return filepath.Join(string(d), name)
+}
+
+func (d fixedWorkingDir) Cleanup() error {
+	return nil
+}
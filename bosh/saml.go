@@ -0,0 +1,108 @@
+package bosh
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const samlRedirectBinding = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+
+// samlIDP is the part of a SAML identity provider's metadata that Concourse needs
+type samlIDP struct {
+	EntityID string
+	SSOURL   string
+	CACert   string
+}
+
+type samlEntityDescriptor struct {
+	EntityID         string `xml:"entityID,attr"`
+	IDPSSODescriptor struct {
+		KeyDescriptors []struct {
+			Use         string `xml:"use,attr"`
+			Certificate string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SingleSignOnServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// fetchSAMLMetadata downloads and parses the identity provider metadata at url. It is fetched on
+// every deploy so that a rotated signing certificate is picked up without reconfiguring
+func fetchSAMLMetadata(url string) (samlIDP, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return samlIDP{}, fmt.Errorf("failed to fetch SAML metadata: [%v]", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return samlIDP{}, fmt.Errorf("failed to fetch SAML metadata: unexpected status %s from %s", resp.Status, url)
+	}
+	metadata, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return samlIDP{}, fmt.Errorf("failed to fetch SAML metadata: [%v]", err)
+	}
+	return parseSAMLMetadata(metadata)
+}
+
+func parseSAMLMetadata(metadata []byte) (samlIDP, error) {
+	var descriptor samlEntityDescriptor
+	if err := xml.Unmarshal(metadata, &descriptor); err != nil {
+		return samlIDP{}, fmt.Errorf("failed to parse SAML metadata: [%v]", err)
+	}
+
+	idp := samlIDP{EntityID: descriptor.EntityID}
+	for _, service := range descriptor.IDPSSODescriptor.SingleSignOnServices {
+		if service.Binding == samlRedirectBinding {
+			idp.SSOURL = service.Location
+		}
+	}
+	if idp.SSOURL == "" {
+		return samlIDP{}, fmt.Errorf("SAML metadata has no HTTP-Redirect SingleSignOnService")
+	}
+
+	for _, key := range descriptor.IDPSSODescriptor.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key.Certificate), ""))
+		if err != nil || len(der) == 0 {
+			continue
+		}
+		idp.CACert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		break
+	}
+
+	return idp, nil
+}
+
+// samlAuthVars returns the manifest vars for SAML auth, preferring a CA certificate given
+// explicitly over the signing certificate published in the metadata
+func samlAuthVars(metadataURL, caCert, usernameAttr, emailAttr, groupsAttr string) (map[string]interface{}, error) {
+	idp, err := fetchSAMLMetadata(metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	if caCert == "" {
+		caCert = idp.CACert
+	}
+	if caCert == "" {
+		return nil, fmt.Errorf("SAML metadata at %s has no signing certificate, provide one with --saml-ca-cert", metadataURL)
+	}
+	return map[string]interface{}{
+		"saml_sso_url":       idp.SSOURL,
+		"saml_sso_issuer":    idp.EntityID,
+		"saml_ca_cert":       caCert,
+		"saml_username_attr": usernameAttr,
+		"saml_email_attr":    emailAttr,
+		"saml_groups_attr":   groupsAttr,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package bosh
+
+import (
+	"encoding/pem"
+	"testing"
+)
+
+const samlTestMetadata = `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="https://idp.example.com/saml">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="encryption">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>ZW5jcnlwdGlvbg==</ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>
+        c2lnbmluZw==
+      </ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso/redirect"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+
+func Test_parseSAMLMetadata(t *testing.T) {
+	idp, err := parseSAMLMetadata([]byte(samlTestMetadata))
+	if err != nil {
+		t.Fatalf("parseSAMLMetadata() error = %v", err)
+	}
+	if idp.EntityID != "https://idp.example.com/saml" {
+		t.Errorf("EntityID = %q", idp.EntityID)
+	}
+	if idp.SSOURL != "https://idp.example.com/sso/redirect" {
+		t.Errorf("SSOURL = %q", idp.SSOURL)
+	}
+	block, _ := pem.Decode([]byte(idp.CACert))
+	if block == nil || string(block.Bytes) != "signing" {
+		t.Errorf("CACert = %q, want the PEM encoded signing certificate", idp.CACert)
+	}
+
+	if _, err = parseSAMLMetadata([]byte(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="x"/>`)); err == nil {
+		t.Error("expected an error for metadata without a redirect SingleSignOnService")
+	}
+}
@@ -0,0 +1,47 @@
+package bosh
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
+	"github.com/EngineerBetter/control-tower/bosh/internal/workingdir"
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+const (
+	// scaledManifestFilename is the Concourse manifest as deployed, saved to be deployed again with another number of workers
+	scaledManifestFilename = "scaled-manifest.yml"
+	// workerInstancesFilename is the ops file setting the number of workers in the scaled manifest
+	workerInstancesFilename = "worker-instances.yml"
+)
+
+// workerInstancesOps renders an ops file setting the number of instances in the worker instance group
+func workerInstancesOps(instances int) ([]byte, error) {
+	return yaml.Marshal([]map[string]interface{}{
+		{
+			"type":  "replace",
+			"path":  "/instance_groups/name=worker/instances",
+			"value": instances,
+		},
+	})
+}
+
+// scaleWorkers deploys manifest, the Concourse manifest as the director last deployed it, with the given number
+// of workers. Nothing but the worker instance group changes, as the director, cloud config and stemcells are left
+// alone, and the manifest isn't rendered again from the config
+func scaleWorkers(boshCLI boshcli.ICLI, dir workingdir.IClient, conf config.ConfigView, directorPublicIP string, stdout io.Writer, manifest []byte, instances int) error {
+	if _, err := dir.SaveFileToWorkingDir(scaledManifestFilename, manifest); err != nil {
+		return err
+	}
+	ops, err := workerInstancesOps(instances)
+	if err != nil {
+		return err
+	}
+	if _, err = dir.SaveFileToWorkingDir(workerInstancesFilename, ops); err != nil {
+		return err
+	}
+	return boshCLI.RunAuthenticatedCommand("deploy", directorPublicIP, conf.GetDirectorPassword(), conf.GetDirectorCACert(), false, stdout,
+		dir.PathInWorkingDir(scaledManifestFilename), "--ops-file", dir.PathInWorkingDir(workerInstancesFilename))
+}
@@ -0,0 +1,42 @@
+package bosh
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli/boshclifakes"
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_scaleWorkers(t *testing.T) {
+	boshCLI := new(boshclifakes.FakeICLI)
+	dir := &recordingWorkingDir{files: map[string][]byte{}}
+	manifest := []byte("name: concourse\n")
+
+	if err := scaleWorkers(boshCLI, dir, config.Config{}, "1.2.3.4", new(bytes.Buffer), manifest, 4); err != nil {
+		t.Fatalf("scaleWorkers() error = %v", err)
+	}
+
+	if !bytes.Equal(dir.files[scaledManifestFilename], manifest) {
+		t.Errorf("scaleWorkers() saved manifest %q, want the deployed manifest", dir.files[scaledManifestFilename])
+	}
+	var ops []map[string]interface{}
+	if err := yaml.Unmarshal(dir.files[workerInstancesFilename], &ops); err != nil {
+		t.Fatalf("scaleWorkers() saved invalid ops: %v", err)
+	}
+	if len(ops) != 1 || ops[0]["path"] != "/instance_groups/name=worker/instances" || ops[0]["value"] != 4 {
+		t.Errorf("scaleWorkers() saved ops %v, want only the worker instances set to 4", ops)
+	}
+
+	if boshCLI.RunAuthenticatedCommandCallCount() != 1 {
+		t.Fatalf("scaleWorkers() ran %d bosh commands, want 1", boshCLI.RunAuthenticatedCommandCallCount())
+	}
+	action, ip, _, _, _, _, flags := boshCLI.RunAuthenticatedCommandArgsForCall(0)
+	want := []string{scaledManifestFilename, "--ops-file", workerInstancesFilename}
+	if action != "deploy" || ip != "1.2.3.4" || !reflect.DeepEqual(flags, want) {
+		t.Errorf("scaleWorkers() ran bosh %s against %s with %v, want deploy with %v", action, ip, flags, want)
+	}
+}
@@ -0,0 +1,25 @@
+package bosh
+
+import "github.com/EngineerBetter/control-tower/config"
+
+// Instance types named in place of sizes get their own vm_types in the cloud config
+const (
+	customWebVMType    = "concourse-web-custom"
+	customWorkerVMType = "concourse-worker-custom"
+)
+
+// webVMType is the cloud config vm_type of the web nodes, from their instance type or else their size
+func webVMType(conf config.ConfigView) string {
+	if conf.GetWebInstanceType() != "" {
+		return customWebVMType
+	}
+	return "concourse-web-" + conf.GetConcourseWebSize()
+}
+
+// workerVMType is the cloud config vm_type of the default workers, from their instance type or else their size
+func workerVMType(conf config.ConfigView) string {
+	if conf.GetWorkerInstanceType() != "" {
+		return customWorkerVMType
+	}
+	return "concourse-" + conf.GetConcourseWorkerSize()
+}
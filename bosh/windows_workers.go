@@ -0,0 +1,77 @@
+package bosh
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+// windowsWorkerJob is the job in the Windows worker release that runs the Concourse worker on Windows.
+// The worker job in the Concourse release only runs on Linux
+const windowsWorkerJob = "worker-windows"
+
+// windowsStemcellURL is the given version of the Windows Server 2019 stemcell at baseURL on bosh.io
+func windowsStemcellURL(baseURL, version string) string {
+	return fmt.Sprintf("%s?v=%s", baseURL, version)
+}
+
+// windowsWorkersOps renders an ops file adding the Windows worker release, checked against releaseSHA1,
+// and the worker-windows instance group running its worker job on the given version of the Windows stemcell
+func windowsWorkersOps(count int, stemcellVersion, releaseURL, releaseSHA1 string) ([]byte, error) {
+	releaseName, releaseVersion, err := config.ParseReleaseURL(releaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal([]map[string]interface{}{
+		{
+			"type": "replace",
+			"path": "/stemcells/alias=windows?",
+			"value": map[string]string{
+				"alias":   "windows",
+				"os":      "windows2019",
+				"version": stemcellVersion,
+			},
+		},
+		{
+			"type": "replace",
+			"path": fmt.Sprintf("/releases/name=%s?", releaseName),
+			"value": map[string]string{
+				"name":    releaseName,
+				"version": releaseVersion,
+				"url":     releaseURL,
+				"sha1":    releaseSHA1,
+			},
+		},
+		{
+			"type": "replace",
+			"path": "/instance_groups/name=worker-windows?",
+			"value": map[string]interface{}{
+				"name":      "worker-windows",
+				"instances": count,
+				"azs":       []string{"z1"},
+				"networks":  []map[string]interface{}{{"name": "((worker_network_name))"}},
+				"stemcell":  "windows",
+				"vm_type":   "concourse-windows",
+				"jobs": []map[string]interface{}{
+					{
+						"name":    windowsWorkerJob,
+						"release": releaseName,
+						"properties": map[string]interface{}{
+							"worker_gateway": map[string]interface{}{"worker_key": "((worker_key))"},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// windowsWorkerType is empty when there are no Windows workers, leaving their vm_type out of the cloud config
+func windowsWorkerType(conf config.ConfigView) string {
+	if conf.GetWindowsWorkerCount() == 0 {
+		return ""
+	}
+	return conf.GetWindowsWorkerType()
+}
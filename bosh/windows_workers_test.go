@@ -0,0 +1,50 @@
+package bosh
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func Test_windowsWorkersOps(t *testing.T) {
+	contents, err := windowsWorkersOps(2, "2019.71", "https://example.com/concourse-windows-7.11.2.tgz", "0123456789abcdef0123456789abcdef01234567")
+	if err != nil {
+		t.Fatalf("windowsWorkersOps() error = %v", err)
+	}
+
+	var ops []struct {
+		Path  string                 `yaml:"path"`
+		Value map[string]interface{} `yaml:"value"`
+	}
+	if err = yaml.Unmarshal(contents, &ops); err != nil {
+		t.Fatalf("windowsWorkersOps() rendered invalid YAML: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("windowsWorkersOps() rendered %d ops, want 3", len(ops))
+	}
+
+	if ops[0].Value["os"] != "windows2019" || ops[0].Value["version"] != "2019.71" {
+		t.Errorf("windowsWorkersOps() rendered stemcell op %+v, want version 2019.71", ops[0])
+	}
+	if ops[1].Path != "/releases/name=concourse-windows?" || ops[1].Value["version"] != "7.11.2" || ops[1].Value["sha1"] != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("windowsWorkersOps() rendered release op %+v", ops[1])
+	}
+	if ops[2].Value["instances"] != 2 {
+		t.Errorf("windowsWorkersOps() instances = %v, want 2", ops[2].Value["instances"])
+	}
+	job := ops[2].Value["jobs"].([]interface{})[0].(map[interface{}]interface{})
+	if job["name"] != windowsWorkerJob || job["release"] != "concourse-windows" {
+		t.Errorf("windowsWorkersOps() runs job %v from release %v, want %s from concourse-windows", job["name"], job["release"], windowsWorkerJob)
+	}
+
+	if _, err = windowsWorkersOps(2, "2019.71", "https://example.com/not-a-release", ""); err == nil {
+		t.Error("windowsWorkersOps() with a URL that isn't a release should error")
+	}
+}
+
+func Test_windowsStemcellURL(t *testing.T) {
+	want := "https://bosh.io/d/stemcells/bosh-aws-xen-hvm-windows2019-go_agent?v=2019.71"
+	if got := windowsStemcellURL(awsWindowsStemcellURL, "2019.71"); got != want {
+		t.Errorf("windowsStemcellURL() = %s, want %s", got, want)
+	}
+}
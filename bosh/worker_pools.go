@@ -0,0 +1,330 @@
+package bosh
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/iaas"
+)
+
+// arm64StemcellAlias names the ARM64 stemcell in the manifest, alongside the default jammy stemcell
+const arm64StemcellAlias = "jammy-arm64"
+
+// workerPoolsOps renders an ops file adding an instance group for each worker pool, spread across the
+// same azs as the default workers. The pool's workers register with its tags, so that only steps with
+// matching tags are scheduled on them, and with its team if it has one, so that only that team's builds
+// run on them. The team's untagged steps still run on global workers too. They use its baggageclaim driver if it has one. ARM64 pools run the user supplied ARM64
+// stemcell and Concourse release, which are added to the manifest once, the release checked against
+// arm64ReleaseSHA1. The operations in workerOps
+// that change the default workers are repeated for each pool, so that pools share their settings
+func workerPoolsOps(pools []config.WorkerPool, azs []string, arm64StemcellURL, arm64ReleaseURL, arm64ReleaseSHA1 string, workerOps ...[]byte) ([]byte, error) {
+	var ops []interface{}
+	arm64Added := false
+	for _, pool := range pools {
+		stemcell, release := "jammy", "concourse"
+		if pool.Arch == config.ARM64 {
+			stemcellName, stemcellVersion, err := config.ParseStemcellURL(arm64StemcellURL)
+			if err != nil {
+				return nil, err
+			}
+			releaseName, releaseVersion, err := config.ParseReleaseURL(arm64ReleaseURL)
+			if err != nil {
+				return nil, err
+			}
+			stemcell, release = arm64StemcellAlias, releaseName
+
+			if !arm64Added {
+				ops = append(ops, map[string]interface{}{
+					"type": "replace",
+					"path": fmt.Sprintf("/stemcells/alias=%s?", arm64StemcellAlias),
+					"value": map[string]string{
+						"alias":   arm64StemcellAlias,
+						"name":    stemcellName,
+						"version": stemcellVersion,
+					},
+				}, map[string]interface{}{
+					"type": "replace",
+					"path": fmt.Sprintf("/releases/name=%s?", releaseName),
+					"value": map[string]string{
+						"name":    releaseName,
+						"version": releaseVersion,
+						"url":     arm64ReleaseURL,
+						"sha1":    arm64ReleaseSHA1,
+					},
+				})
+				arm64Added = true
+			}
+		}
+
+		name := "worker-" + pool.Name
+
+		workerProperties := map[string]interface{}{
+			"tags":           pool.Tags,
+			"worker_gateway": map[string]interface{}{"worker_key": "((worker_key))"},
+		}
+		if pool.BaggageclaimDriver != "" {
+			workerProperties["baggageclaim"] = map[string]interface{}{"driver": pool.BaggageclaimDriver}
+		}
+		if pool.Team != "" {
+			workerProperties["team"] = pool.Team
+		}
+		if pool.GPU != "" {
+			// The NVIDIA container runtime is a Guardian runtime plugin
+			workerProperties["runtime"] = "guardian"
+		}
+
+		instanceGroup := map[string]interface{}{
+			"name":      name,
+			"instances": pool.Count,
+			"azs":       azs,
+			"networks":  []map[string]interface{}{{"name": "((worker_network_name))"}},
+			"stemcell":  stemcell,
+			"vm_type":   "concourse-" + pool.Size,
+			"jobs": []map[string]interface{}{
+				{
+					"name":       "worker",
+					"release":    release,
+					"properties": workerProperties,
+				},
+			},
+		}
+		if workerPoolInstanceType(pool) != "" || pool.GPU != "" {
+			instanceGroup["vm_extensions"] = []string{workerPoolExtension(pool)}
+		}
+
+		ops = append(ops, map[string]interface{}{
+			"type":  "replace",
+			"path":  fmt.Sprintf("/instance_groups/name=%s?", name),
+			"value": instanceGroup,
+		})
+	}
+
+	poolOps, err := workerPoolCopies(pools, workerOps)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		if pool.GPU != "" {
+			poolOps = gpuDriverOps(pool, poolOps)
+		}
+	}
+	return yaml.Marshal(append(ops, poolOps...))
+}
+
+// gpuGardenConfig is the Guardian config that gpu_driver.sh writes, running containers with the NVIDIA runtime
+const gpuGardenConfig = "/var/vcap/data/gpu/garden.ini"
+
+// gpuDriverOps install the NVIDIA driver and container runtime on a GPU pool's workers before the worker
+// starts, and have Guardian use the runtime. An instance group has only one os-conf pre-start-script, so
+// when poolOps already give the pool one from the default workers, the driver is installed ahead of it
+func gpuDriverOps(pool config.WorkerPool, poolOps []interface{}) []interface{} {
+	name := "worker-" + pool.Name
+	preStartPath := fmt.Sprintf("/instance_groups/name=%s/jobs/name=pre-start-script?", name)
+	script := string(gpuDriverScript)
+
+	found := false
+	for _, o := range poolOps {
+		op := o.(map[string]interface{})
+		if op["path"] != preStartPath {
+			continue
+		}
+		if existing := preStartScript(op["value"]); existing != "" {
+			script += "\n" + existing
+		}
+		// The value is shared with the copies for other pools, so it is replaced rather than changed
+		op["value"] = preStartScriptJob(script)
+		found = true
+	}
+	if !found {
+		poolOps = append(poolOps, map[string]interface{}{
+			"type":  "replace",
+			"path":  preStartPath,
+			"value": preStartScriptJob(script),
+		})
+	}
+	return append(poolOps, map[string]interface{}{
+		"type":  "replace",
+		"path":  fmt.Sprintf("/instance_groups/name=%s/jobs/name=worker/properties/env?/CONCOURSE_GARDEN_CONFIG", name),
+		"value": gpuGardenConfig,
+	})
+}
+
+func preStartScriptJob(script string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       "pre-start-script",
+		"release":    "os-conf",
+		"properties": map[string]interface{}{"script": script},
+	}
+}
+
+// preStartScript is the script of an os-conf pre-start-script job parsed from an ops file
+func preStartScript(job interface{}) string {
+	value, _ := job.(map[interface{}]interface{})
+	properties, _ := value["properties"].(map[interface{}]interface{})
+	script, _ := properties["script"].(string)
+	return script
+}
+
+// defaultWorkerPath prefixes the paths of operations on the default worker instance group
+const defaultWorkerPath = "/instance_groups/name=worker/"
+
+// workerPoolCopies repeats for each pool the operations in opsFiles that change the default worker
+// instance group, so that the pools' workers get the same settings. Removals are left out, as what
+// they remove from the default workers is never added to the pools
+func workerPoolCopies(pools []config.WorkerPool, opsFiles [][]byte) ([]interface{}, error) {
+	var copies []interface{}
+	for _, contents := range opsFiles {
+		var ops []map[string]interface{}
+		if err := yaml.Unmarshal(contents, &ops); err != nil {
+			return nil, err
+		}
+		for _, pool := range pools {
+			for _, op := range ops {
+				path, _ := op["path"].(string)
+				if op["type"] == "remove" || !strings.HasPrefix(path, defaultWorkerPath) {
+					continue
+				}
+				poolOp := map[string]interface{}{}
+				for k, v := range op {
+					poolOp[k] = v
+				}
+				poolOp["path"] = "/instance_groups/name=worker-" + pool.Name + "/" + strings.TrimPrefix(path, defaultWorkerPath)
+				copies = append(copies, poolOp)
+			}
+		}
+	}
+	return copies, nil
+}
+
+// defaultWorkerOpsFiles are the ops files that change the default worker instance group, which worker
+// pools get too. no_metrics.yml only removes jobs from the default workers, which pools never have
+var defaultWorkerOpsFiles = map[string][]byte{
+	concourseWorkerEnvFilename:            concourseWorkerEnv,
+	concourseDedicatedWorkersFilename:     concourseDedicatedWorkers,
+	concourseEphemeralWorkersFilename:     concourseEphemeralWorkers,
+	concourseP2PVolumeStreamingFilename:   concourseP2PVolumeStreaming,
+	concoursePreemptionRetireFilename:     concoursePreemptionRetire,
+	concourseWorkerContainerdFilename:     concourseWorkerContainerd,
+	concourseWorkerDrainTimeoutFilename:   concourseWorkerDrainTimeout,
+	concourseWorkerRebalanceFilename:      concourseWorkerRebalance,
+	concourseWorkerRetireInFlightFilename: concourseWorkerRetireInFlight,
+	concourseWorkerUpgradeBatchesFilename: concourseWorkerUpgradeBatches,
+	concourseWorkerZonesFilename:          concourseWorkerZones,
+	concourseWorkerKernelFilename:         concourseWorkerKernel,
+}
+
+// selectedWorkerOps are the contents of the ops files in flagFiles that change the default workers, in
+// the order they are applied
+func selectedWorkerOps(flagFiles []string) [][]byte {
+	var selected [][]byte
+	for i := 0; i+1 < len(flagFiles); i++ {
+		if flagFiles[i] != "--ops-file" {
+			continue
+		}
+		if contents, ok := defaultWorkerOpsFiles[filepath.Base(flagFiles[i+1])]; ok {
+			selected = append(selected, contents)
+		}
+	}
+	return selected
+}
+
+// ValidateWorkerPoolSpotBids rejects spot worker pools on AWS whose instance type has no known on-demand
+// price, as their spot bid is a percentage of it
+func ValidateWorkerPoolSpotBids(pools []config.WorkerPool) error {
+	for _, pool := range pools {
+		instanceType := workerPoolInstanceType(pool)
+		if instanceType == "" {
+			continue
+		}
+		if _, ok := iaas.AWSOnDemandPrices[instanceType]; !ok {
+			return fmt.Errorf("worker pool %s runs on %s, whose on-demand price isn't known to bid a percentage of for spot instances, deploy with --spot=false", pool.Name, instanceType)
+		}
+	}
+	return nil
+}
+
+// workerContainerdProperties are the containerd properties of the worker job, leaving out the options
+// that weren't set so that Concourse's defaults apply
+func workerContainerdProperties(conf config.ConfigView) map[string]interface{} {
+	properties := map[string]interface{}{}
+	if len(conf.GetWorkerDNSServers()) > 0 {
+		properties["dns_servers"] = conf.GetWorkerDNSServers()
+	}
+	if conf.GetWorkerDNSProxy() {
+		properties["dns_proxy_enable"] = true
+	}
+	if conf.GetWorkerNetworkPool() != "" {
+		properties["network_pool"] = conf.GetWorkerNetworkPool()
+	}
+	if conf.GetWorkerMaxContainers() > 0 {
+		properties["max_containers"] = conf.GetWorkerMaxContainers()
+	}
+	return properties
+}
+
+// workerPoolInstanceTypes maps the cloud config VM extension of each pool with its own worker type to
+// the instance type it overrides the pool's vm_type with
+func workerPoolInstanceTypes(pools []config.WorkerPool) map[string]string {
+	instanceTypes := map[string]string{}
+	for _, pool := range pools {
+		if instanceType := workerPoolInstanceType(pool); instanceType != "" {
+			instanceTypes[workerPoolExtension(pool)] = instanceType
+		}
+	}
+	return instanceTypes
+}
+
+// workerPoolInstanceType is empty when the pool uses the same instance type as the default workers,
+// amd64 medium workers being t3.medium whatever their type. Worker types that --worker-type offers
+// take their instance types from the same table as the default workers, and other types are named
+// TYPE.SIZE. ARM64 pools without a type are on GCP, where they run on Tau T2A machines
+func workerPoolInstanceType(pool config.WorkerPool) string {
+	if pool.Arch == config.ARM64 && pool.Type == "" {
+		return gcpARM64MachineTypes[pool.Size]
+	}
+	if pool.Type == "" || (pool.Arch != config.ARM64 && pool.Size == "medium") {
+		return ""
+	}
+	if instanceType, ok := iaas.AWSWorkerInstanceTypes[pool.Type][pool.Size]; ok {
+		return instanceType
+	}
+	return pool.Type + "." + pool.Size
+}
+
+var gcpARM64MachineTypes = map[string]string{
+	"medium":   "t2a-standard-1",
+	"large":    "t2a-standard-2",
+	"xlarge":   "t2a-standard-4",
+	"2xlarge":  "t2a-standard-8",
+	"4xlarge":  "t2a-standard-16",
+	"12xlarge": "t2a-standard-48",
+}
+
+// workerPoolGPUs maps the cloud config VM extension of each GCP pool with a GPU to the accelerator
+// attached to its workers. On AWS the GPU comes with the pool's instance type instead
+func workerPoolGPUs(pools []config.WorkerPool) map[string]string {
+	accelerators := map[string]string{}
+	for _, pool := range pools {
+		if pool.GPU != "" && pool.Type == "" {
+			accelerators[workerPoolExtension(pool)] = config.GCPGPUAccelerators[pool.GPU]
+		}
+	}
+	return accelerators
+}
+
+func hasARM64WorkerPool(pools []config.WorkerPool) bool {
+	for _, pool := range pools {
+		if pool.Arch == config.ARM64 {
+			return true
+		}
+	}
+	return false
+}
+
+func workerPoolExtension(pool config.WorkerPool) string {
+	return "worker-pool-" + pool.Name
+}
@@ -0,0 +1,295 @@
+package bosh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_workerPoolsOps(t *testing.T) {
+	pools := []config.WorkerPool{
+		{Name: "docker-heavy", Size: "2xlarge", Count: 2, Type: "m5", Tags: []string{"docker"}, BaggageclaimDriver: "overlay"},
+		{Name: "deploy", Size: "medium", Count: 1, Type: "m5", Tags: []string{"deploy"}, Team: "production"},
+	}
+	contents, err := workerPoolsOps(pools, []string{"z1", "z2"}, "", "", "")
+	if err != nil {
+		t.Fatalf("workerPoolsOps() error = %v", err)
+	}
+
+	var ops []struct {
+		Path  string `yaml:"path"`
+		Value struct {
+			Name         string   `yaml:"name"`
+			Instances    int      `yaml:"instances"`
+			AZs          []string `yaml:"azs"`
+			VMType       string   `yaml:"vm_type"`
+			VMExtensions []string `yaml:"vm_extensions"`
+			Jobs         []struct {
+				Name       string `yaml:"name"`
+				Properties struct {
+					Tags         []string          `yaml:"tags"`
+					Baggageclaim map[string]string `yaml:"baggageclaim"`
+					Team         string            `yaml:"team"`
+				} `yaml:"properties"`
+			} `yaml:"jobs"`
+		} `yaml:"value"`
+	}
+	if err = yaml.Unmarshal(contents, &ops); err != nil {
+		t.Fatalf("workerPoolsOps() rendered invalid YAML: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("workerPoolsOps() rendered %d ops, want 2", len(ops))
+	}
+
+	heavy := ops[0]
+	if heavy.Path != "/instance_groups/name=worker-docker-heavy?" || heavy.Value.Instances != 2 || heavy.Value.VMType != "concourse-2xlarge" {
+		t.Errorf("workerPoolsOps() rendered %+v for the docker-heavy pool", heavy)
+	}
+	if !reflect.DeepEqual(heavy.Value.AZs, []string{"z1", "z2"}) {
+		t.Errorf("workerPoolsOps() azs = %v, want the default workers' [z1 z2]", heavy.Value.AZs)
+	}
+	if !reflect.DeepEqual(heavy.Value.VMExtensions, []string{"worker-pool-docker-heavy"}) {
+		t.Errorf("workerPoolsOps() vm_extensions = %v, want [worker-pool-docker-heavy]", heavy.Value.VMExtensions)
+	}
+	worker := heavy.Value.Jobs[0]
+	if worker.Name != "worker" || !reflect.DeepEqual(worker.Properties.Tags, []string{"docker"}) {
+		t.Errorf("workerPoolsOps() rendered worker job %+v", worker)
+	}
+	if worker.Properties.Baggageclaim["driver"] != "overlay" {
+		t.Errorf("workerPoolsOps() baggageclaim = %v, want driver overlay", worker.Properties.Baggageclaim)
+	}
+	if ops[1].Value.Jobs[0].Properties.Baggageclaim != nil {
+		t.Errorf("workerPoolsOps() gave a pool without a driver baggageclaim properties %v", ops[1].Value.Jobs[0].Properties.Baggageclaim)
+	}
+
+	if worker.Properties.Team != "" || ops[1].Value.Jobs[0].Properties.Team != "production" {
+		t.Errorf("workerPoolsOps() teams = %q and %q, want none and production", worker.Properties.Team, ops[1].Value.Jobs[0].Properties.Team)
+	}
+
+	if ops[1].Value.VMExtensions != nil {
+		t.Errorf("workerPoolsOps() gave a medium pool vm_extensions %v, want none", ops[1].Value.VMExtensions)
+	}
+
+	want := map[string]string{"worker-pool-docker-heavy": "m5.2xlarge"}
+	if got := workerPoolInstanceTypes(pools); !reflect.DeepEqual(got, want) {
+		t.Errorf("workerPoolInstanceTypes() = %v, want %v", got, want)
+	}
+}
+
+func Test_workerPoolsOpsARM64(t *testing.T) {
+	pools := []config.WorkerPool{
+		{Name: "arm", Size: "medium", Count: 1, Type: "m6g", Arch: "arm64", Tags: []string{"arm64"}},
+		{Name: "arm-gcp", Size: "xlarge", Count: 1, Arch: "arm64", Tags: []string{"arm64"}},
+	}
+	contents, err := workerPoolsOps(pools, []string{"z1"},
+		"https://example.com/light-bosh-stemcell-1.406-aws-xen-hvm-ubuntu-jammy-go_agent-arm64.tgz",
+		"https://example.com/concourse-arm64-7.11.2.tgz", "0123456789abcdef0123456789abcdef01234567")
+	if err != nil {
+		t.Fatalf("workerPoolsOps() error = %v", err)
+	}
+
+	var ops []struct {
+		Path  string                 `yaml:"path"`
+		Value map[string]interface{} `yaml:"value"`
+	}
+	if err = yaml.Unmarshal(contents, &ops); err != nil {
+		t.Fatalf("workerPoolsOps() rendered invalid YAML: %v", err)
+	}
+	if len(ops) != 4 {
+		t.Fatalf("workerPoolsOps() rendered %d ops, want 4", len(ops))
+	}
+
+	if ops[0].Path != "/stemcells/alias=jammy-arm64?" || ops[0].Value["name"] != "bosh-aws-xen-hvm-ubuntu-jammy-go_agent-arm64" || ops[0].Value["version"] != "1.406" {
+		t.Errorf("workerPoolsOps() rendered stemcell op %+v", ops[0])
+	}
+	if ops[1].Path != "/releases/name=concourse-arm64?" || ops[1].Value["version"] != "7.11.2" || ops[1].Value["sha1"] != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("workerPoolsOps() rendered release op %+v", ops[1])
+	}
+	if ops[2].Value["stemcell"] != "jammy-arm64" {
+		t.Errorf("workerPoolsOps() arm64 pool stemcell = %v, want jammy-arm64", ops[2].Value["stemcell"])
+	}
+
+	want := map[string]string{"worker-pool-arm": "m6g.medium", "worker-pool-arm-gcp": "t2a-standard-4"}
+	if got := workerPoolInstanceTypes(pools); !reflect.DeepEqual(got, want) {
+		t.Errorf("workerPoolInstanceTypes() = %v, want %v", got, want)
+	}
+	if !hasARM64WorkerPool(pools) {
+		t.Error("hasARM64WorkerPool() = false, want true")
+	}
+}
+
+func Test_workerPoolGPUs(t *testing.T) {
+	pools := []config.WorkerPool{
+		{Name: "ml-aws", Size: "xlarge", Count: 1, Type: "g4dn", GPU: "t4", Tags: []string{"gpu"}},
+		{Name: "ml-gcp", Size: "xlarge", Count: 2, GPU: "v100", Tags: []string{"gpu"}},
+		{Name: "docker", Size: "large", Count: 1, Tags: []string{"docker"}},
+	}
+	contents, err := workerPoolsOps(pools, []string{"z1"}, "", "", "")
+	if err != nil {
+		t.Fatalf("workerPoolsOps() error = %v", err)
+	}
+	var ops []map[string]interface{}
+	if err = yaml.Unmarshal(contents, &ops); err != nil {
+		t.Fatalf("workerPoolsOps() rendered invalid YAML: %v", err)
+	}
+	extensions := ops[1]["value"].(map[interface{}]interface{})["vm_extensions"]
+	if !reflect.DeepEqual(extensions, []interface{}{"worker-pool-ml-gcp"}) {
+		t.Errorf("workerPoolsOps() gave a GCP GPU pool vm_extensions %v, want [worker-pool-ml-gcp]", extensions)
+	}
+
+	wantTypes := map[string]string{"worker-pool-ml-aws": "g4dn.xlarge"}
+	if got := workerPoolInstanceTypes(pools); !reflect.DeepEqual(got, wantTypes) {
+		t.Errorf("workerPoolInstanceTypes() = %v, want %v", got, wantTypes)
+	}
+	wantGPUs := map[string]string{"worker-pool-ml-gcp": "nvidia-tesla-v100"}
+	if got := workerPoolGPUs(pools); !reflect.DeepEqual(got, wantGPUs) {
+		t.Errorf("workerPoolGPUs() = %v, want %v", got, wantGPUs)
+	}
+}
+
+func Test_workerPoolsOpsGPUDriver(t *testing.T) {
+	pools := []config.WorkerPool{
+		{Name: "ml", Size: "xlarge", Count: 1, GPU: "t4"},
+		{Name: "docker", Size: "large", Count: 1},
+	}
+	render := func(workerOps ...[]byte) map[string]interface{} {
+		contents, err := workerPoolsOps(pools, []string{"z1"}, "", "", "", workerOps...)
+		if err != nil {
+			t.Fatalf("workerPoolsOps() error = %v", err)
+		}
+		var ops []struct {
+			Path  string      `yaml:"path"`
+			Value interface{} `yaml:"value"`
+		}
+		if err = yaml.Unmarshal(contents, &ops); err != nil {
+			t.Fatalf("workerPoolsOps() rendered invalid YAML: %v", err)
+		}
+		byPath := map[string]interface{}{}
+		for _, op := range ops {
+			byPath[op.Path] = op.Value
+		}
+		return byPath
+	}
+
+	ops := render()
+	script := preStartScript(ops["/instance_groups/name=worker-ml/jobs/name=pre-start-script?"])
+	if script != string(gpuDriverScript) {
+		t.Errorf("workerPoolsOps() gave the GPU pool pre-start script %q, want gpu_driver.sh", script)
+	}
+	if ops["/instance_groups/name=worker-ml/jobs/name=worker/properties/env?/CONCOURSE_GARDEN_CONFIG"] != gpuGardenConfig {
+		t.Errorf("workerPoolsOps() didn't point the GPU pool's Guardian at %s: %v", gpuGardenConfig, ops)
+	}
+
+	ops = render(concourseWorkerKernel)
+	script = preStartScript(ops["/instance_groups/name=worker-ml/jobs/name=pre-start-script?"])
+	if !strings.HasPrefix(script, string(gpuDriverScript)) || !strings.Contains(script, "unified_cgroup_hierarchy") {
+		t.Errorf("workerPoolsOps() gave the GPU pool pre-start script %q, want the driver installed ahead of the kernel script", script)
+	}
+	script = preStartScript(ops["/instance_groups/name=worker-docker/jobs/name=pre-start-script?"])
+	if strings.Contains(script, "nvidia") || !strings.Contains(script, "unified_cgroup_hierarchy") {
+		t.Errorf("workerPoolsOps() gave a pool without a GPU the pre-start script %q, want only the kernel script", script)
+	}
+}
+
+func Test_workerContainerdProperties(t *testing.T) {
+	conf := config.Config{
+		WorkerDNSServers:    []string{"10.0.0.2"},
+		WorkerNetworkPool:   "172.16.0.0/16",
+		WorkerMaxContainers: 150,
+	}
+	want := map[string]interface{}{"dns_servers": []string{"10.0.0.2"}, "network_pool": "172.16.0.0/16", "max_containers": 150}
+	if got := workerContainerdProperties(conf); !reflect.DeepEqual(got, want) {
+		t.Errorf("workerContainerdProperties() = %v, want %v", got, want)
+	}
+
+	if got := workerContainerdProperties(config.Config{}); len(got) != 0 {
+		t.Errorf("workerContainerdProperties() = %v, want none", got)
+	}
+}
+
+func Test_workerPoolsOpsRepeatsWorkerOps(t *testing.T) {
+	pools := []config.WorkerPool{{Name: "gpu", Size: "xlarge", Count: 1}}
+	workerOps := []byte(`
+- type: replace
+  path: /releases/name=os-conf?
+  value: {name: os-conf}
+- type: replace
+  path: /instance_groups/name=worker/jobs/name=post-deploy-script?
+  value: {name: post-deploy-script}
+- type: remove
+  path: /instance_groups/name=worker/jobs/name=worker/properties/influxdb
+`)
+	contents, err := workerPoolsOps(pools, []string{"z1"}, "", "", "", workerOps)
+	if err != nil {
+		t.Fatalf("workerPoolsOps() error = %v", err)
+	}
+
+	var ops []struct {
+		Type string `yaml:"type"`
+		Path string `yaml:"path"`
+	}
+	if err = yaml.Unmarshal(contents, &ops); err != nil {
+		t.Fatalf("workerPoolsOps() rendered invalid YAML: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("workerPoolsOps() rendered %v, want the pool and its post-deploy-script", ops)
+	}
+	if ops[1].Type != "replace" || ops[1].Path != "/instance_groups/name=worker-gpu/jobs/name=post-deploy-script?" {
+		t.Errorf("workerPoolsOps() rendered %+v, want the post-deploy-script on the pool", ops[1])
+	}
+}
+
+func Test_osConfRelease(t *testing.T) {
+	var ops []struct {
+		Value struct {
+			Version string `yaml:"version"`
+			URL     string `yaml:"url"`
+		} `yaml:"value"`
+	}
+	if err := yaml.Unmarshal(osConfRelease, &ops); err != nil {
+		t.Fatalf("os-conf-release.yml is invalid YAML: %v", err)
+	}
+	if ops[0].Value.Version != OSConfReleaseVersion || ops[0].Value.URL != osConfReleaseURL {
+		t.Errorf("os-conf-release.yml adds %+v, want version %s from %s", ops[0].Value, OSConfReleaseVersion, osConfReleaseURL)
+	}
+}
+
+func Test_selectedWorkerOps(t *testing.T) {
+	flagFiles := []string{
+		"concourse.yml",
+		"--ops-file", "/tmp/work/no_metrics.yml",
+		"--ops-file", "/tmp/work/worker_kernel.yml",
+		"--vars-file", "/tmp/work/worker-zones.yml",
+		"--ops-file", "/tmp/work/worker-upgrade-batches.yml",
+	}
+	want := [][]byte{concourseWorkerKernel, concourseWorkerUpgradeBatches}
+	if got := selectedWorkerOps(flagFiles); !reflect.DeepEqual(got, want) {
+		t.Errorf("selectedWorkerOps() = %d files, want worker_kernel.yml and worker-upgrade-batches.yml", len(got))
+	}
+}
+
+func Test_defaultWorkerOpsFiles(t *testing.T) {
+	known := map[string]bool{}
+	for _, contents := range defaultWorkerOpsFiles {
+		known[string(contents)] = true
+	}
+	for _, contents := range concourseManifestSources()[1:] {
+		var ops []struct {
+			Type string `yaml:"type"`
+			Path string `yaml:"path"`
+		}
+		if err := yaml.Unmarshal(contents, &ops); err != nil {
+			continue
+		}
+		for _, op := range ops {
+			if op.Type != "remove" && strings.HasPrefix(op.Path, defaultWorkerPath) && !known[string(contents)] {
+				t.Errorf("an ops file changing %s isn't in defaultWorkerOpsFiles, so worker pools don't get it:\n%s", op.Path, contents)
+				break
+			}
+		}
+	}
+}
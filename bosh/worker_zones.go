@@ -0,0 +1,60 @@
+package bosh
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+// workerAZs are the BOSH AZs that the worker instance group is spread across: z1, the deployment's own
+// zone, followed by one for each of the further worker zones
+func workerAZs(zones []config.WorkerZone) []string {
+	azs := []string{"z1"}
+	for i := range zones {
+		azs = append(azs, fmt.Sprintf("z%d", i+2))
+	}
+	return azs
+}
+
+// cloudConfigWorkerZones names the further worker zones as BOSH AZs. subnetIDs are the comma separated
+// IDs of the zones' subnets in the same order, on AWS only
+func cloudConfigWorkerZones(zones []config.WorkerZone, subnetIDs string) ([]boshcli.WorkerZone, error) {
+	var ids []string
+	if subnetIDs != "" {
+		ids = strings.Split(subnetIDs, ",")
+	}
+	azs := workerAZs(zones)
+
+	var workerZones []boshcli.WorkerZone
+	for i, zone := range zones {
+		workerZone := boshcli.WorkerZone{Name: azs[i+1], Zone: zone.Zone}
+		if zone.CIDR != "" {
+			if i >= len(ids) {
+				return nil, fmt.Errorf("no subnet was created for worker zone %s", zone.Zone)
+			}
+			_, parsedCIDR, err := net.ParseCIDR(zone.CIDR)
+			if err != nil {
+				return nil, err
+			}
+			gateway, err := cidr.Host(parsedCIDR, 1)
+			if err != nil {
+				return nil, err
+			}
+			reserved, err := formatIPRange(zone.CIDR, "-", []int{1, 5})
+			if err != nil {
+				return nil, err
+			}
+			workerZone.SubnetID = ids[i]
+			workerZone.CIDR = zone.CIDR
+			workerZone.Gateway = gateway.String()
+			workerZone.Reserved = reserved
+		}
+		workerZones = append(workerZones, workerZone)
+	}
+	return workerZones, nil
+}
@@ -0,0 +1,42 @@
+package bosh
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/bosh/internal/boshcli"
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_cloudConfigWorkerZones(t *testing.T) {
+	zones := []config.WorkerZone{
+		{Zone: "eu-west-1b", CIDR: "10.0.2.0/24"},
+		{Zone: "eu-west-1c", CIDR: "10.0.3.0/24"},
+	}
+	got, err := cloudConfigWorkerZones(zones, "subnet-b,subnet-c")
+	if err != nil {
+		t.Fatalf("cloudConfigWorkerZones() error = %v", err)
+	}
+	want := []boshcli.WorkerZone{
+		{Name: "z2", Zone: "eu-west-1b", SubnetID: "subnet-b", CIDR: "10.0.2.0/24", Gateway: "10.0.2.1", Reserved: "[10.0.2.1-10.0.2.5]"},
+		{Name: "z3", Zone: "eu-west-1c", SubnetID: "subnet-c", CIDR: "10.0.3.0/24", Gateway: "10.0.3.1", Reserved: "[10.0.3.1-10.0.3.5]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cloudConfigWorkerZones() = %#v, want %#v", got, want)
+	}
+	if azs := workerAZs(zones); !reflect.DeepEqual(azs, []string{"z1", "z2", "z3"}) {
+		t.Errorf("workerAZs() = %v, want [z1 z2 z3]", azs)
+	}
+
+	got, err = cloudConfigWorkerZones([]config.WorkerZone{{Zone: "europe-west1-c"}}, "")
+	if err != nil {
+		t.Fatalf("cloudConfigWorkerZones() error = %v", err)
+	}
+	if want := []boshcli.WorkerZone{{Name: "z2", Zone: "europe-west1-c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cloudConfigWorkerZones() = %#v, want %#v", got, want)
+	}
+
+	if _, err = cloudConfigWorkerZones(zones, "subnet-b"); err == nil {
+		t.Errorf("cloudConfigWorkerZones() expected an error for a zone without a subnet")
+	}
+}
@@ -17,7 +17,7 @@ var _ = Describe("Certs", func() {
 	var provider = &iaasfakes.FakeProvider{}
 
 	It("Generates a cert for an IP address", func() {
-		certs, err := Generate(constructor, "control-tower-mole", &provider, "99.99.99.99")
+		certs, err := Generate(constructor, "control-tower-mole", &provider, nil, "99.99.99.99")
 		Expect(err).ToNot(HaveOccurred())
 		Expect(string(certs.CACert)).To(ContainSubstring("BEGIN CERTIFICATE"))
 		Expect(string(certs.Key)).To(ContainSubstring("BEGIN RSA PRIVATE KEY"))
@@ -25,7 +25,7 @@ var _ = Describe("Certs", func() {
 	})
 
 	It("Generates a cert for a domain", func() {
-		certs, err := Generate(constructor, "control-tower-mole", &provider, "control-tower-test-"+util.GeneratePasswordWithLength(10)+".engineerbetter.com")
+		certs, err := Generate(constructor, "control-tower-mole", &provider, nil, "control-tower-test-"+util.GeneratePasswordWithLength(10)+".engineerbetter.com")
 		Expect(err).ToNot(HaveOccurred())
 		Expect(string(certs.CACert)).To(ContainSubstring("BEGIN CERTIFICATE"))
 		Expect(string(certs.Key)).To(ContainSubstring("BEGIN RSA PRIVATE KEY"))
@@ -33,7 +33,7 @@ var _ = Describe("Certs", func() {
 	})
 
 	It("Can't generate a cert for google.com", func() {
-		_, err := Generate(constructor, "control-tower-mole", &provider, "google.com")
+		_, err := Generate(constructor, "control-tower-mole", &provider, nil, "google.com")
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -112,8 +112,9 @@ func customNewDNSProviderServiceAccount(saFile string, config *gcloud.Config) (*
 	return gcloud.NewDNSProviderConfig(config)
 }
 
-// Generate generates certs for use in a bosh director manifest
-func Generate(constructor func(u *User) (*lego.Client, error), caName string, provider iaas.Provider, ipOrDomains ...string) (*Certs, error) {
+// Generate generates certs for use in a bosh director manifest. Let's Encrypt DNS-01 challenges are
+// answered with dnsProvider when given, otherwise with the IAAS's own DNS
+func Generate(constructor func(u *User) (*lego.Client, error), caName string, provider iaas.Provider, dnsProvider challenge.Provider, ipOrDomains ...string) (*Certs, error) {
 
 	if hasIP(ipOrDomains) {
 		return generateSelfSigned(caName, ipOrDomains...)
@@ -128,8 +129,13 @@ func Generate(constructor func(u *User) (*lego.Client, error), caName string, pr
 	c.Challenge.Remove(challenge.HTTP01)
 	c.Challenge.Remove(challenge.TLSALPN01)
 
-	switch provider.IAAS() {
-	case iaas.AWS:
+	switch {
+	case dnsProvider != nil:
+		err = c.Challenge.SetDNS01Provider(dnsProvider)
+		if err != nil {
+			return nil, err
+		}
+	case provider.IAAS() == iaas.AWS:
 		dnsConfig := route53.NewDefaultConfig()
 		dnsConfig.PropagationTimeout = 10 * time.Minute
 		dnsConfig.PollingInterval = 30 * time.Second
@@ -142,7 +148,7 @@ func Generate(constructor func(u *User) (*lego.Client, error), caName string, pr
 		if err1 != nil {
 			return nil, err1
 		}
-	case iaas.GCP:
+	case provider.IAAS() == iaas.GCP:
 		dnsConfig := gcloud.NewDefaultConfig()
 		dnsConfig.PropagationTimeout = 10 * time.Minute
 		dnsConfig.PollingInterval = 30 * time.Second
@@ -14,6 +14,8 @@ cp ../control-tower-ops/manifest.yml opsassets/assets/
 cp -R ../control-tower-ops/ops opsassets/assets/ 
 cp ../control-tower-ops/createenv-dependencies-and-cli-versions-aws.json opsassets/assets/
 cp ../control-tower-ops/createenv-dependencies-and-cli-versions-gcp.json opsassets/assets/
+ci/tasks/lib/add-cli-platforms.sh opsassets/assets/createenv-dependencies-and-cli-versions-aws.json
+ci/tasks/lib/add-cli-platforms.sh opsassets/assets/createenv-dependencies-and-cli-versions-gcp.json
 GO111MODULE=on go build -mod=vendor -ldflags "
   -X github.com/EngineerBetter/control-tower/fly.ControlTowerVersion=$version
   -X main.ControlTowerVersion=$version
@@ -0,0 +1,68 @@
+#!/bin/bash
+# Adds a download of bosh-cli and terraform for each platform control-tower is released for to a
+# createenv versions file, with the sha256 control-tower checks each download against
+set -euo pipefail
+
+versions_file=$1
+
+platforms=(darwin/amd64 darwin/arm64 linux/amd64 linux/arm64 windows/amd64)
+# HashiCorp signs the checksums of every terraform release with this key
+hashicorp_key_fingerprint=C874011F0AB405110D02105534365D9472D7468F
+
+tmp=$(mktemp -d)
+trap 'rm -rf "${tmp}"' EXIT
+
+bosh_cli_version=$(jq -r '."bosh-cli".version' "${versions_file}")
+terraform_version=$(jq -r '.terraform.version' "${versions_file}")
+
+# terraform checksums are only trusted once HashiCorp's signature over them has been verified
+terraform_base=https://releases.hashicorp.com/terraform/${terraform_version}
+curl -fsSL "${terraform_base}/terraform_${terraform_version}_SHA256SUMS" -o "${tmp}/SHA256SUMS"
+curl -fsSL "${terraform_base}/terraform_${terraform_version}_SHA256SUMS.sig" -o "${tmp}/SHA256SUMS.sig"
+export GNUPGHOME=${tmp}/gnupg
+mkdir -m 700 "${GNUPGHOME}"
+curl -fsSL https://www.hashicorp.com/.well-known/pgp-key.txt | gpg --batch --quiet --import
+if ! gpg --batch --status-fd 1 --verify "${tmp}/SHA256SUMS.sig" "${tmp}/SHA256SUMS" 2>/dev/null | grep -q "VALIDSIG ${hashicorp_key_fingerprint}"; then
+  echo "terraform ${terraform_version} checksums are not signed by HashiCorp" >&2
+  exit 1
+fi
+
+# bosh-cli releases are not signed, so each download is checked against the digest GitHub recorded
+# when the asset was uploaded
+curl -fsSL "https://api.github.com/repos/cloudfoundry/bosh-cli/releases/tags/v${bosh_cli_version}" -o "${tmp}/bosh-cli-release.json"
+
+for platform in "${platforms[@]}"; do
+  os=${platform%/*}
+  arch=${platform#*/}
+
+  terraform_file=terraform_${terraform_version}_${os}_${arch}.zip
+  terraform_sha256=$(awk -v file="${terraform_file}" '$2 == file { print $1 }' "${tmp}/SHA256SUMS")
+  if [ -z "${terraform_sha256}" ]; then
+    echo "terraform ${terraform_version} has no build for ${platform}" >&2
+    exit 1
+  fi
+
+  bosh_cli_file=bosh-cli-${bosh_cli_version}-${os}-${arch}
+  if [ "${os}" = "windows" ]; then
+    bosh_cli_file=${bosh_cli_file}.exe
+  fi
+  bosh_cli_url=https://github.com/cloudfoundry/bosh-cli/releases/download/v${bosh_cli_version}/${bosh_cli_file}
+  curl -fsSL "${bosh_cli_url}" -o "${tmp}/${bosh_cli_file}"
+  bosh_cli_sha256=$(sha256sum "${tmp}/${bosh_cli_file}" | cut -d' ' -f1)
+  bosh_cli_digest=$(jq -r --arg name "${bosh_cli_file}" '.assets[] | select(.name == $name) | .digest' "${tmp}/bosh-cli-release.json")
+  if [ "${bosh_cli_digest}" != "sha256:${bosh_cli_sha256}" ]; then
+    echo "${bosh_cli_file} has sha256 ${bosh_cli_sha256}, but GitHub recorded ${bosh_cli_digest}" >&2
+    exit 1
+  fi
+
+  jq \
+    --arg platform "${platform}" \
+    --arg bosh_cli_url "${bosh_cli_url}" \
+    --arg bosh_cli_sha256 "${bosh_cli_sha256}" \
+    --arg terraform_url "${terraform_base}/${terraform_file}" \
+    --arg terraform_sha256 "${terraform_sha256}" \
+    '."bosh-cli".platforms[$platform] = {url: $bosh_cli_url, sha256: $bosh_cli_sha256}
+    | .terraform.platforms[$platform] = {url: $terraform_url, sha256: $terraform_sha256}' \
+    "${versions_file}" > "${tmp}/versions.json"
+  mv "${tmp}/versions.json" "${versions_file}"
+done
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/commands/check"
+	"github.com/EngineerBetter/control-tower/concourse"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+)
+
+var initialCheckArgs check.Args
+
+var checkFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialCheckArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialCheckArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialCheckArgs.Namespace,
+	},
+	cli.BoolFlag{
+		Name:        "db",
+		Usage:       "Check that the database is reachable, presents a certificate signed by the stored CA, accepts the credentials and has the extensions the components need",
+		Destination: &initialCheckArgs.DB,
+	},
+}
+
+func checkAction(c *cli.Context, checkArgs check.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower check --db <name>`"))
+	}
+
+	version := c.App.Version
+
+	client, err := buildCheckClient(name, version, checkArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.Check(checkArgs)
+}
+
+func validateCheckArgs(c *cli.Context, checkArgs check.Args) (check.Args, error) {
+	err := checkArgs.MarkSetFlags(c)
+	if err != nil {
+		return checkArgs, fmt.Errorf("failed to mark set Check flags: [%v]", err)
+	}
+
+	if err = checkArgs.Validate(); err != nil {
+		return checkArgs, fmt.Errorf("failed to validate Check flags: [%v]", err)
+	}
+
+	return checkArgs, nil
+}
+
+func buildCheckClient(name, version string, checkArgs check.Args, provider iaas.Provider) (*concourse.Client, error) {
+	versionFile, _ := provider.Choose(iaas.Choice{
+		AWS: resource.AWSVersionFile,
+		GCP: resource.GCPVersionFile,
+	}).([]byte)
+
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(versionFile))
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.NewWithCommandOptions(boshCommandOptions),
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, checkArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+		versionFile,
+		credhub.NewClient,
+	)
+
+	return client, nil
+}
+
+var checkCmd = cli.Command{
+	Name:      "check",
+	Usage:     "Checks the health of a deployment, reporting each problem found",
+	ArgsUsage: "<name>",
+	Flags:     checkFlags,
+	Action: func(c *cli.Context) error {
+		checkArgs, err := validateCheckArgs(c, initialCheckArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on check: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(checkArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on check: [%v]", err))
+		}
+		provider, err := iaas.New(iaasName, checkArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on check: [%v]", err)
+		}
+		return checkAction(c, checkArgs, provider)
+	},
+}
@@ -0,0 +1,73 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the check command
+type Args struct {
+	Region         string
+	RegionIsSet    bool
+	Namespace      string
+	NamespaceIsSet bool
+	IAAS           string
+	IAASIsSet      bool
+	// DB checks that the database can be connected to
+	DB      bool
+	DBIsSet bool
+}
+
+//MarkSetFlags is marking which check Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "region":
+				a.RegionIsSet = true
+			case "namespace":
+				a.NamespaceIsSet = true
+			case "iaas":
+				a.IAASIsSet = true
+			case "db":
+				a.DBIsSet = true
+			default:
+				return fmt.Errorf("flag %q is not supported by check flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Args) Validate() error {
+	if !a.IAASIsSet {
+		return fmt.Errorf("--iaas flag not set")
+	}
+	if !a.DB {
+		return errors.New("nothing to check, set --db to check the database")
+	}
+	return nil
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
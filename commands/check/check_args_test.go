@@ -0,0 +1,66 @@
+package check_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/EngineerBetter/control-tower/commands/check"
+)
+
+func TestCheckArgs_Validate(t *testing.T) {
+	defaultFields := Args{
+		Region:    "eu-west-1",
+		IAAS:      "AWS",
+		IAASIsSet: true,
+		DB:        true,
+		DBIsSet:   true,
+	}
+	tests := []struct {
+		name         string
+		modification func() Args
+		wantErr      bool
+		expectedErr  string
+	}{
+		{
+			name: "Default args",
+			modification: func() Args {
+				return defaultFields
+			},
+			wantErr: false,
+		},
+		{
+			name: "IAAS not set",
+			modification: func() Args {
+				args := defaultFields
+				args.IAASIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--iaas flag not set",
+		},
+		{
+			name: "Nothing to check",
+			modification: func() Args {
+				args := defaultFields
+				args.DB = false
+				args.DBIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "nothing to check, set --db to check the database",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.modification()
+			err := args.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && tt.wantErr && !strings.Contains(err.Error(), tt.expectedErr)) {
+				if err != nil {
+					t.Errorf("CheckArgs.Validate() %v test failed.\nFailed with error = %v,\nExpected error = %v,\nShould fail %v\nWith args: %#v", tt.name, err.Error(), tt.expectedErr, tt.wantErr, args)
+				} else {
+					t.Errorf("CheckArgs.Validate() %v test failed.\nShould fail %v\nWith args: %#v", tt.name, tt.wantErr, args)
+				}
+			}
+		})
+	}
+}
@@ -1,18 +1,35 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
 // Commands is a list of all supported CLI commands
 var Commands = []cli.Command{
+	checkCmd,
 	deployCmd,
 	destroyCmd,
+	driftCmd,
+	exportDeploymentCmd,
+	generateTerraformCmd,
+	iaasCmd,
+	importDeploymentCmd,
 	infoCmd,
 	maintainCmd,
+	restoreDBCmd,
 }
 
-var nonInteractive bool
+var (
+	nonInteractive   bool
+	boshTimeouts     cli.StringSlice
+	boshRetries      int
+	boshRetryBackoff time.Duration
+)
 
 // GlobalFlags are the global CLIflags
 var GlobalFlags = []cli.Flag{
@@ -22,9 +39,62 @@ var GlobalFlags = []cli.Flag{
 		Usage:       "Non interactive",
 		Destination: &nonInteractive,
 	},
+	cli.StringSliceFlag{
+		Name:   "bosh-timeout",
+		EnvVar: "BOSH_TIMEOUT",
+		Usage:  "(optional) Maximum duration of bosh commands, either for all commands (eg 30m) or for one command (eg deploy=2h). Can be repeated",
+		Value:  &boshTimeouts,
+	},
+	cli.IntFlag{
+		Name:        "bosh-retries",
+		EnvVar:      "BOSH_RETRIES",
+		Usage:       "(optional) Number of times to retry a failed bosh command",
+		Destination: &boshRetries,
+	},
+	cli.DurationFlag{
+		Name:        "bosh-retry-backoff",
+		EnvVar:      "BOSH_RETRY_BACKOFF",
+		Usage:       "(optional) Delay before the first retry of a failed bosh command, doubling on each subsequent retry",
+		Value:       10 * time.Second,
+		Destination: &boshRetryBackoff,
+	},
 }
 
 // NonInteractiveModeEnabled returns true if --non-interactive true has been passed in
 func NonInteractiveModeEnabled() bool {
 	return nonInteractive
 }
+
+func parseBoshCommandOptions() (bosh.CommandOptions, error) {
+	return buildBoshCommandOptions(boshTimeouts, boshRetries, boshRetryBackoff)
+}
+
+func buildBoshCommandOptions(timeouts []string, retries int, backoff time.Duration) (bosh.CommandOptions, error) {
+	if retries < 0 {
+		return bosh.CommandOptions{}, fmt.Errorf("--bosh-retries must not be negative, got %d", retries)
+	}
+	if backoff < 0 {
+		return bosh.CommandOptions{}, fmt.Errorf("--bosh-retry-backoff must not be negative, got %s", backoff)
+	}
+
+	options := bosh.CommandOptions{
+		Default:  bosh.CommandPolicy{Retries: retries, Backoff: backoff},
+		Commands: map[string]bosh.CommandPolicy{},
+	}
+	for _, timeout := range timeouts {
+		command, value := "", timeout
+		if i := strings.Index(timeout, "="); i >= 0 {
+			command, value = timeout[:i], timeout[i+1:]
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return bosh.CommandOptions{}, fmt.Errorf("invalid --bosh-timeout %q, expected a positive duration such as 30m or deploy=2h", timeout)
+		}
+		if command == "" {
+			options.Default.Timeout = d
+			continue
+		}
+		options.Commands[command] = bosh.CommandPolicy{Timeout: d, Retries: retries, Backoff: backoff}
+	}
+	return options, nil
+}
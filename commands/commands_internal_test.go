@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/iaas/iaasfakes"
+)
+
+func Test_buildBoshCommandOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts []string
+		retries  int
+		backoff  time.Duration
+		want     bosh.CommandOptions
+		wantErr  bool
+	}{
+		{
+			name:    "no timeouts keeps bosh commands unbounded",
+			retries: 0,
+			backoff: 10 * time.Second,
+			want: bosh.CommandOptions{
+				Default:  bosh.CommandPolicy{Backoff: 10 * time.Second},
+				Commands: map[string]bosh.CommandPolicy{},
+			},
+		},
+		{
+			name:     "default and per command timeouts",
+			timeouts: []string{"30m", "deploy=2h"},
+			retries:  3,
+			backoff:  time.Second,
+			want: bosh.CommandOptions{
+				Default: bosh.CommandPolicy{Timeout: 30 * time.Minute, Retries: 3, Backoff: time.Second},
+				Commands: map[string]bosh.CommandPolicy{
+					"deploy": {Timeout: 2 * time.Hour, Retries: 3, Backoff: time.Second},
+				},
+			},
+		},
+		{
+			name:     "invalid duration",
+			timeouts: []string{"deploy=forever"},
+			wantErr:  true,
+		},
+		{
+			name:     "zero duration",
+			timeouts: []string{"0s"},
+			wantErr:  true,
+		},
+		{
+			name:    "negative retries",
+			retries: -1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildBoshCommandOptions(tt.timeouts, tt.retries, tt.backoff)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildBoshCommandOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildBoshCommandOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeInstanceTypes(t *testing.T) {
+	tests := []struct {
+		name          string
+		instanceTypes []iaas.InstanceType
+		want          string
+	}{
+		{
+			name: "with worker types",
+			instanceTypes: []iaas.InstanceType{
+				{Role: "web", Size: "small", Name: "t3.small", Zones: []string{"eu-west-1a", "eu-west-1b"}},
+				{Role: "worker", Size: "12xlarge", WorkerType: "m5a", Name: "m5a.12xlarge"},
+			},
+			want: `ROLE    SIZE      WORKER TYPE  INSTANCE TYPE  ZONES
+web     small     -            t3.small       eu-west-1a,eu-west-1b
+worker  12xlarge  m5a          m5a.12xlarge   none
+`,
+		},
+		{
+			name: "without worker types",
+			instanceTypes: []iaas.InstanceType{
+				{Role: "worker", Size: "xlarge", Name: "n1-standard-4", Zones: []string{"europe-west1-b"}},
+			},
+			want: `ROLE    SIZE    INSTANCE TYPE  ZONES
+worker  xlarge  n1-standard-4  europe-west1-b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeInstanceTypes(&buf, tt.instanceTypes); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeInstanceTypes() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_listQuotas(t *testing.T) {
+	provider := &iaasfakes.FakeProvider{}
+	provider.QuotasReturns([]iaas.Quota{{Name: "CPUS", Limit: 24, Usage: 10}, {Name: "IN_USE_ADDRESSES", Limit: 8, Usage: 2}}, nil)
+
+	var buf bytes.Buffer
+	if err := listQuotas(provider, &buf, false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `QUOTA             USAGE  LIMIT
+CPUS              10     24
+IN_USE_ADDRESSES  2      8
+`
+	if buf.String() != want {
+		t.Errorf("listQuotas() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func Test_listZones(t *testing.T) {
+	provider := &iaasfakes.FakeProvider{}
+	provider.ListZonesReturns([]string{"eu-west-1a", "eu-west-1b"}, nil)
+
+	var buf bytes.Buffer
+	if err := listZones(provider, &buf, true); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[\n  \"eu-west-1a\",\n  \"eu-west-1b\"\n]\n"
+	if buf.String() != want {
+		t.Errorf("listZones() = %q, want %q", buf.String(), want)
+	}
+}
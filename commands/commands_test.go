@@ -17,11 +17,12 @@ var _ = Describe("commands", func() {
 				Expect(string(output)).To(ContainSubstring("--tls-cert value"))
 				Expect(string(output)).To(ContainSubstring("--tls-key value"))
 				Expect(string(output)).To(ContainSubstring("--db-size value"))
-				Expect(string(output)).To(MatchRegexp(`--vpc-network-range value\s+\(optional\) VPC network CIDR to deploy into, only required if IAAS is AWS`))
-				Expect(string(output)).To(MatchRegexp(`--public-subnet-range value\s+\(optional\) public network CIDR \(if IAAS is AWS must be within --vpc-network-range\)`))
-				Expect(string(output)).To(MatchRegexp(`--private-subnet-range value\s+\(optional\) private network CIDR \(if IAAS is AWS must be within --vpc-network-range\)`))
-				Expect(string(output)).To(MatchRegexp(`--rds-subnet-range1 value\s+\(optional\) first rds network CIDR \(if IAAS is AWS must be within --vpc-network-range\)`))
-				Expect(string(output)).To(MatchRegexp(`--rds-subnet-range2 value\s+\(optional\) second rds network CIDR \(if IAAS is AWS must be within --vpc-network-range\)`))
+				Expect(string(output)).To(MatchRegexp(`--network-cidr value, --vpc-network-range value\s+\(optional\) VPC network CIDR to deploy into, only required if IAAS is AWS`))
+				Expect(string(output)).To(MatchRegexp(`--public-cidr value, --public-subnet-range value\s+\(optional\) public network CIDR \(if IAAS is AWS must be within --network-cidr\)`))
+				Expect(string(output)).To(MatchRegexp(`--private-cidr value, --private-subnet-range value\s+\(optional\) private network CIDR \(if IAAS is AWS must be within --network-cidr\)`))
+				Expect(string(output)).To(MatchRegexp(`--rds-cidrs value\s+\(optional\) Comma separated pair of CIDRs for the RDS subnets`))
+				Expect(string(output)).To(MatchRegexp(`--rds-subnet-range1 value\s+\(optional\) first rds network CIDR \(if IAAS is AWS must be within --network-cidr\)`))
+				Expect(string(output)).To(MatchRegexp(`--rds-subnet-range2 value\s+\(optional\) second rds network CIDR \(if IAAS is AWS must be within --network-cidr\)`))
 			})
 		})
 
@@ -132,6 +133,82 @@ var _ = Describe("commands", func() {
 		})
 	})
 
+	Describe("export-deployment", func() {
+		When("using --help", func() {
+			It("displays usage details", func() {
+				output, err := controlTowerCommand("export-deployment", "--help").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("control-tower export-deployment - Exports a deployment to an encrypted archive, so that it can be imported elsewhere"))
+			})
+		})
+
+		When("the passphrase is too short", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("export-deployment", "abc", "--iaas", "AWS", "--archive", "abc.tgz", "--passphrase", "hunter2").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Error validating args on export-deployment: [failed to validate ExportDeployment flags: [the archive passphrase must be at least 12 characters long]]"))
+			})
+		})
+	})
+
+	Describe("generate-terraform", func() {
+		When("--network-only is passed", func() {
+			It("prints the network module", func() {
+				output, err := controlTowerCommand("generate-terraform", "--iaas", "AWS", "--network-only").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring(`resource "aws_vpc" "default"`))
+				Expect(string(output)).To(ContainSubstring(`variable "network_cidr"`))
+				Expect(string(output)).NotTo(ContainSubstring(`resource "aws_db_instance"`))
+			})
+		})
+
+		When("--network-only is not passed", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("generate-terraform", "--iaas", "GCP").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("only the network module can be generated, --network-only must be provided"))
+			})
+		})
+	})
+
+	Describe("iaas", func() {
+		When("using --help", func() {
+			It("lists the queries", func() {
+				output, err := controlTowerCommand("iaas", "--help").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("list-zones"))
+				Expect(string(output)).To(ContainSubstring("list-instance-types"))
+				Expect(string(output)).To(ContainSubstring("quotas"))
+			})
+		})
+
+		When("the IAAS is not specified", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("iaas", "list-zones").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Error validating args on iaas list-zones: [failed to validate IAAS query flags: [--iaas flag not set]]"))
+			})
+		})
+	})
+
+	Describe("import-deployment", func() {
+		When("using --help", func() {
+			It("displays usage details", func() {
+				output, err := controlTowerCommand("import-deployment", "--help").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("control-tower import-deployment - Imports a deployment from an archive created by export-deployment, ready to be deployed"))
+			})
+		})
+
+		When("the passphrase is too short", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("import-deployment", "abc", "--iaas", "AWS", "--archive", "abc.tgz", "--passphrase", "hunter2").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Error validating args on import-deployment: [failed to validate ImportDeployment flags: [the archive passphrase must be at least 12 characters long]]"))
+			})
+		})
+	})
+
 	Describe("info", func() {
 		When("using --help", func() {
 			It("displays usage details", func() {
@@ -183,4 +260,56 @@ var _ = Describe("commands", func() {
 			})
 		})
 	})
+
+	Describe("check", func() {
+		When("using --help", func() {
+			It("displays usage details", func() {
+				output, err := controlTowerCommand("check", "--help").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("control-tower check - Checks the health of a deployment"))
+			})
+		})
+
+		When("nothing is chosen to check", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("check", "--iaas", "AWS", "abc").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Error validating args on check: [failed to validate Check flags: [nothing to check, set --db to check the database]]"))
+			})
+		})
+
+		When("no name is passed in", func() {
+			It("displays correct usage", func() {
+				output, err := controlTowerCommand("check", "--iaas", "AWS", "--db").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Usage is `control-tower check --db <name>`"))
+			})
+		})
+	})
+
+	Describe("restore-db", func() {
+		When("using --help", func() {
+			It("displays usage details", func() {
+				output, err := controlTowerCommand("restore-db", "--help").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("control-tower restore-db - Restores the database to a point in time"))
+			})
+		})
+
+		When("the timestamp is not specified", func() {
+			It("shows a meaningful error", func() {
+				output, err := controlTowerCommand("restore-db", "--iaas", "AWS", "abc").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Error validating args on restore-db: [failed to validate RestoreDB flags: [--timestamp flag not set]]"))
+			})
+		})
+
+		When("no name is passed in", func() {
+			It("displays correct usage", func() {
+				output, err := controlTowerCommand("restore-db", "--iaas", "AWS", "--timestamp", "2023-11-14T22:00:00Z").CombinedOutput()
+				Expect(err).To(HaveOccurred(), string(output))
+				Expect(string(output)).To(ContainSubstring("Usage is `control-tower restore-db <name>`"))
+			})
+		})
+	})
 })
@@ -4,36 +4,97 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/artifacts"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/EngineerBetter/control-tower/dns"
+	"github.com/EngineerBetter/control-tower/quota"
+	"github.com/EngineerBetter/control-tower/siem"
 )
 
 // Args are arguments passed to the deploy command
 type Args struct {
-	IAAS                string
-	IAASIsSet           bool
-	Region              string
-	RegionIsSet         bool
-	Domain              string
-	DomainIsSet         bool
-	TLSCert             string
-	TLSCertIsSet        bool
-	TLSKey              string
-	TLSKeyIsSet         bool
-	WorkerCount         int
-	WorkerCountIsSet    bool
-	WorkerSize          string
-	WorkerSizeIsSet     bool
-	WebSize             string
-	WebSizeIsSet        bool
-	PersistentDiskSize  string
-	PersistentDiskIsSet bool
-	SelfUpdate          bool
-	SelfUpdateIsSet     bool
-	DBSize              string
+	IAAS                    string
+	IAASIsSet               bool
+	Region                  string
+	RegionIsSet             bool
+	Domain                  string
+	DomainIsSet             bool
+	TLSCert                 string
+	TLSCertIsSet            bool
+	TLSKey                  string
+	TLSKeyIsSet             bool
+	WorkerCount             int
+	WorkerCountIsSet        bool
+	WorkerSize              string
+	WorkerSizeIsSet         bool
+	WebSize                 string
+	WebSizeIsSet            bool
+	WebCount                int
+	WebCountIsSet           bool
+	PersistentDiskSize      string
+	PersistentDiskIsSet     bool
+	SelfUpdate              bool
+	SelfUpdateIsSet         bool
+	CFAuthAPIURL            string
+	CFAuthAPIURLIsSet       bool
+	CFAuthClientID          string
+	CFAuthClientIDIsSet     bool
+	CFAuthClientSecret      string
+	CFAuthClientSecretIsSet bool
+	CFAuthCACert            string
+	CFAuthCACertIsSet       bool
+	// CFAuthIsSet is true if the user has specified the --cf-auth-api-url, --cf-auth-client-id and --cf-auth-client-secret flags
+	CFAuthIsSet                        bool
+	MainCFOrgs                         string
+	MainCFOrgsIsSet                    bool
+	MainCFSpaces                       string
+	MainCFSpacesIsSet                  bool
+	InternalLB                         bool
+	InternalLBIsSet                    bool
+	InternalLBAllowIPs                 string
+	InternalLBAllowIPsIsSet            bool
+	InternalLBTLSCert                  string
+	InternalLBTLSCertIsSet             bool
+	InternalLBTLSKey                   string
+	InternalLBTLSKeyIsSet              bool
+	InternalLBHealthCheckPath          string
+	InternalLBHealthCheckPathIsSet     bool
+	InternalLBHealthCheckInterval      int
+	InternalLBHealthCheckIntervalIsSet bool
+	InternalLBHealthyThreshold         int
+	InternalLBHealthyThresholdIsSet    bool
+	InternalLBUnhealthyThreshold       int
+	InternalLBUnhealthyThresholdIsSet  bool
+	// SkipMigrationCheck disables the preview of database migrations before upgrading Concourse
+	SkipMigrationCheck bool
+	// NonInteractive is set from the global --non-interactive flag, so that deploy never waits on stdin
+	NonInteractive bool
+	// ScanForLeaks checks what a deploy leaves behind for credentials outside the fields meant to hold them
+	ScanForLeaks   bool
+	TeamsFile      string
+	TeamsFileIsSet bool
+	// TeamsFileContents is loaded from the path given by --teams-file
+	TeamsFileContents string
+	// PruneTeams destroys teams that are not in the teams file
+	PruneTeams        bool
+	ArtifactsDir      string
+	ArtifactsDirIsSet bool
+	// Artifacts and ArtifactsMirror are loaded from the directory given by --artifacts-dir
+	Artifacts       artifacts.Index
+	ArtifactsMirror artifacts.Mirror
+	DBSize          string
 	// DBSizeIsSet is true if the user has manually specified the db-size (ie, it's not the default)
 	DBSizeIsSet                    bool
 	RDSDiskEncryption              bool
@@ -81,28 +142,462 @@ type Args struct {
 	MicrosoftAuthTenant            string
 	MicrosoftAuthTenantIsSet       bool
 	// MicrosoftAuthIsSet is true if the user has specified both the --microsoft-auth-client-secret and --microsoft-auth-client-id flags
-	MicrosoftAuthIsSet bool
-	NoMetrics          bool
-	NoMetricsIsSet     bool
-	Tags               cli.StringSlice
+	MicrosoftAuthIsSet     bool
+	OIDCIssuer             string
+	OIDCIssuerIsSet        bool
+	OIDCClientID           string
+	OIDCClientIDIsSet      bool
+	OIDCClientSecret       string
+	OIDCClientSecretIsSet  bool
+	OIDCGroupsClaim        string
+	OIDCGroupsClaimIsSet   bool
+	OIDCUsernameClaim      string
+	OIDCUsernameClaimIsSet bool
+	// OIDCAuthIsSet is true if the user has specified the --oidc-issuer, --oidc-client-id and --oidc-client-secret flags
+	OIDCAuthIsSet          bool
+	OAuthAuthURL           string
+	OAuthAuthURLIsSet      bool
+	OAuthTokenURL          string
+	OAuthTokenURLIsSet     bool
+	OAuthUserinfoURL       string
+	OAuthUserinfoURLIsSet  bool
+	OAuthClientID          string
+	OAuthClientIDIsSet     bool
+	OAuthClientSecret      string
+	OAuthClientSecretIsSet bool
+	OAuthScopes            string
+	OAuthScopesIsSet       bool
+	OAuthGroupsKey         string
+	OAuthGroupsKeyIsSet    bool
+	// OAuthAuthIsSet is true if the user has specified the --oauth-auth-url, --oauth-token-url, --oauth-userinfo-url, --oauth-client-id and --oauth-client-secret flags
+	OAuthAuthIsSet               bool
+	SAMLMetadataURL              string
+	SAMLMetadataURLIsSet         bool
+	SAMLCACert                   string
+	SAMLCACertIsSet              bool
+	SAMLUsernameAttr             string
+	SAMLUsernameAttrIsSet        bool
+	SAMLEmailAttr                string
+	SAMLEmailAttrIsSet           bool
+	SAMLGroupsAttr               string
+	SAMLGroupsAttrIsSet          bool
+	LDAPHost                     string
+	LDAPHostIsSet                bool
+	LDAPBindDN                   string
+	LDAPBindDNIsSet              bool
+	LDAPBindPassword             string
+	LDAPBindPasswordIsSet        bool
+	LDAPCACert                   string
+	LDAPCACertIsSet              bool
+	LDAPUserSearchBaseDN         string
+	LDAPUserSearchBaseDNIsSet    bool
+	LDAPUserSearchFilter         string
+	LDAPUserSearchFilterIsSet    bool
+	LDAPUserSearchUsername       string
+	LDAPUserSearchUsernameIsSet  bool
+	LDAPGroupSearchBaseDN        string
+	LDAPGroupSearchBaseDNIsSet   bool
+	LDAPGroupSearchFilter        string
+	LDAPGroupSearchFilterIsSet   bool
+	LDAPGroupSearchNameAttr      string
+	LDAPGroupSearchNameAttrIsSet bool
+	// LDAPAuthIsSet is true if the user has specified the --ldap-host, --ldap-bind-dn, --ldap-bind-password and --ldap-user-search-base-dn flags
+	LDAPAuthIsSet  bool
+	NoMetrics      bool
+	NoMetricsIsSet bool
+	Tags           cli.StringSlice
 	// TagsIsSet is true if the user has specified tags using --add-tag
-	TagsIsSet        bool
-	Spot             bool
-	SpotIsSet        bool
-	Zone             string
-	ZoneIsSet        bool
-	WorkerType       string
-	WorkerTypeIsSet  bool
-	NetworkCIDR      string
-	NetworkCIDRIsSet bool
-	PublicCIDR       string
-	PublicCIDRIsSet  bool
-	PrivateCIDR      string
-	PrivateCIDRIsSet bool
-	RDS1CIDR         string
-	RDS1CIDRIsSet    bool
-	RDS2CIDR         string
-	RDS2CIDRIsSet    bool
+	TagsIsSet             bool
+	Spot                  bool
+	SpotIsSet             bool
+	WorkerSpotIsSet       bool
+	WorkerPreemptIsSet    bool
+	Zone                  string
+	ZoneIsSet             bool
+	WorkerType            string
+	WorkerTypeIsSet       bool
+	NetworkCIDR           string
+	NetworkCIDRIsSet      bool
+	PublicCIDR            string
+	PublicCIDRIsSet       bool
+	PrivateCIDR           string
+	PrivateCIDRIsSet      bool
+	RDS1CIDR              string
+	RDS1CIDRIsSet         bool
+	RDS2CIDR              string
+	RDS2CIDRIsSet         bool
+	RDSCIDRs              string
+	RDSCIDRsIsSet         bool
+	VaultURL              string
+	VaultURLIsSet         bool
+	VaultCACert           string
+	VaultCACertIsSet      bool
+	VaultAuthBackend      string
+	VaultAuthBackendIsSet bool
+	VaultAuthParams       cli.StringSlice
+	VaultAuthParamsIsSet  bool
+	VaultClientToken      string
+	VaultClientTokenIsSet bool
+	VaultPathPrefix       string
+	VaultPathPrefixIsSet  bool
+	VarsFile              string
+	VarsFileIsSet         bool
+	// VarsFileContents is loaded from the path given by --vars-file
+	VarsFileContents         string
+	WorkerCgroupVersion      string
+	WorkerCgroupVersionIsSet bool
+	WorkerIMDSHopLimit       int
+	WorkerIMDSHopLimitIsSet  bool
+	WorkerSpotBid            int
+	WorkerSpotBidIsSet       bool
+	WorkerSysctls            cli.StringSlice
+	// WorkerSysctlsIsSet is true if the user has specified kernel parameters using --worker-sysctl
+	WorkerSysctlsIsSet bool
+	// WorkerDNSServers replace the DNS servers that build containers resolve names with
+	WorkerDNSServers      cli.StringSlice
+	WorkerDNSServersIsSet bool
+	// WorkerDNSProxy makes build containers resolve names through the worker, using its /etc/hosts and resolvers
+	WorkerDNSProxy           bool
+	WorkerDNSProxyIsSet      bool
+	WorkerNetworkPool        string
+	WorkerNetworkPoolIsSet   bool
+	WorkerMaxContainers      int
+	WorkerMaxContainersIsSet bool
+	// WorkerPoolsFile is the path to a YAML list of extra worker pools
+	WorkerPoolsFile      string
+	WorkerPoolsFileIsSet bool
+	// WorkerPools are loaded from the path given by --worker-pools-file
+	WorkerPools []config.WorkerPool
+	// ARM64StemcellURL and ARM64ReleaseURL are deployed to worker pools with arch arm64, checking the
+	// release against ARM64ReleaseSHA1
+	ARM64StemcellURL      string
+	ARM64StemcellURLIsSet bool
+	ARM64ReleaseURL       string
+	ARM64ReleaseURLIsSet  bool
+	ARM64ReleaseSHA1      string
+	ARM64ReleaseSHA1IsSet bool
+	// WindowsWorkerCount and WindowsWorkerType size the instance group of Windows workers
+	WindowsWorkerCount      int
+	WindowsWorkerCountIsSet bool
+	WindowsWorkerType       string
+	WindowsWorkerTypeIsSet  bool
+	// WindowsWorkerReleaseURL is the release whose worker-windows job Windows workers run, checked against
+	// WindowsWorkerReleaseSHA1, on the Windows stemcell at WindowsStemcellVersion
+	WindowsWorkerReleaseURL       string
+	WindowsWorkerReleaseURLIsSet  bool
+	WindowsWorkerReleaseSHA1      string
+	WindowsWorkerReleaseSHA1IsSet bool
+	WindowsStemcellVersion        string
+	WindowsStemcellVersionIsSet   bool
+	// WorkerInstanceType and WebInstanceType name an instance type directly, in place of the one
+	// chosen by size, and are checked against the IAAS when the config is built
+	WorkerInstanceType      string
+	WorkerInstanceTypeIsSet bool
+	WebInstanceType         string
+	WebInstanceTypeIsSet    bool
+	// WorkerDiskSizeGB and WorkerDiskType size the disk that worker containers and volumes live on
+	WorkerDiskSizeGB      int
+	WorkerDiskSizeGBIsSet bool
+	WorkerDiskType        string
+	WorkerDiskTypeIsSet   bool
+	// WorkerAutoscaleMin and WorkerAutoscaleMax bound the worker count chosen by `maintain --autoscale`
+	WorkerAutoscaleMin      int
+	WorkerAutoscaleMinIsSet bool
+	WorkerAutoscaleMax      int
+	WorkerAutoscaleMaxIsSet bool
+	// WorkerSchedules are `<cron expression>=<workers>` entries, evaluated in WorkerScheduleTimezone
+	WorkerSchedules             cli.StringSlice
+	WorkerSchedulesIsSet        bool
+	WorkerScheduleTimezone      string
+	WorkerScheduleTimezoneIsSet bool
+	// WorkerDrainTimeout, WorkerRebalanceInterval and WorkerRetireMaxInFlight control how workers are retired
+	WorkerDrainTimeout           string
+	WorkerDrainTimeoutIsSet      bool
+	WorkerRebalanceInterval      string
+	WorkerRebalanceIntervalIsSet bool
+	WorkerRetireMaxInFlight      int
+	WorkerRetireMaxInFlightIsSet bool
+	// CredentialManager is the backend Concourse reads credentials from, one of CredentialManagers
+	CredentialManager               string
+	CredentialManagerIsSet          bool
+	SecretsManagerPipelinePath      string
+	SecretsManagerPipelinePathIsSet bool
+	SecretsManagerTeamPath          string
+	SecretsManagerTeamPathIsSet     bool
+	SSMPipelinePath                 string
+	SSMPipelinePathIsSet            bool
+	SSMTeamPath                     string
+	SSMTeamPathIsSet                bool
+	SSMKMSKeyID                     string
+	SSMKMSKeyIDIsSet                bool
+	// ManagedPrometheus remote-writes metrics to AMP on AWS or Managed Service for Prometheus on GCP
+	ManagedPrometheus                  bool
+	ManagedPrometheusIsSet             bool
+	ManagedPrometheusWorkspaceURL      string
+	ManagedPrometheusWorkspaceURLIsSet bool
+	// EnablePrometheusMetrics exposes Concourse's Prometheus emitter to the private network for scraping
+	EnablePrometheusMetrics      bool
+	EnablePrometheusMetricsIsSet bool
+	// MetricsScrapeAllowIPs turns on a separate metrics listener with TLS and basic auth, reachable from these addresses only
+	MetricsScrapeAllowIPs      string
+	MetricsScrapeAllowIPsIsSet bool
+	// ExternalWorkerAllowIPs opens worker registration to these addresses, for workers outside the deployment
+	ExternalWorkerAllowIPs      string
+	ExternalWorkerAllowIPsIsSet bool
+	// RestrictWorkerEgress limits the connections workers open to the deployment's network and WorkerEgressAllow
+	RestrictWorkerEgress      bool
+	RestrictWorkerEgressIsSet bool
+	WorkerEgressAllow         cli.StringSlice
+	WorkerEgressAllowIsSet    bool
+	// WorkerZones are further zones in the region to spread workers across
+	WorkerZones      cli.StringSlice
+	WorkerZonesIsSet bool
+	// ExistingVPCID on AWS and ExistingNetwork on GCP deploy into a network that is managed elsewhere, in its
+	// ExistingPublicSubnet and ExistingPrivateSubnet. The RDS instance is placed in the two ExistingDBSubnets
+	ExistingVPCID              string
+	ExistingVPCIDIsSet         bool
+	ExistingNetwork            string
+	ExistingNetworkIsSet       bool
+	ExistingPublicSubnet       string
+	ExistingPublicSubnetIsSet  bool
+	ExistingPrivateSubnet      string
+	ExistingPrivateSubnetIsSet bool
+	ExistingDBSubnets          cli.StringSlice
+	ExistingDBSubnetsIsSet     bool
+	// Private gives the director, web VMs and web load balancer no public addresses, so that they are only
+	// reached from within the network, over a VPN, Direct Connect or Interconnect
+	Private      bool
+	PrivateIsSet bool
+	// Bastion adds a hardened host in the public subnet that the director is reached through, by SSH from
+	// control-tower's own address and BastionAllowIPs
+	Bastion              bool
+	BastionIsSet         bool
+	BastionAllowIPs      string
+	BastionAllowIPsIsSet bool
+	// VPCEndpoints adds VPC endpoints for S3, EC2, Secrets Manager and CloudWatch to a private deployment on
+	// AWS, so that calls to those APIs don't go through the NAT gateway
+	VPCEndpoints      bool
+	VPCEndpointsIsSet bool
+	// NATTopology chooses between a single NAT gateway, one in each worker zone as well or a NAT instance on AWS
+	NATTopology      string
+	NATTopologyIsSet bool
+	// IPv6 gives the network and the web load balancer IPv6 addresses on AWS, with AAAA records alongside the A
+	// records, reaching the load balancer over IPv6 from AllowIPv6s
+	IPv6            bool
+	IPv6IsSet       bool
+	AllowIPv6s      string
+	AllowIPv6sIsSet bool
+	// PeerVPCIDs on AWS and PeerNetworks on GCP are peered with the deployment's network, which routes to
+	// them, so that workers can reach services in them
+	PeerVPCIDs        cli.StringSlice
+	PeerVPCIDsIsSet   bool
+	PeerNetworks      cli.StringSlice
+	PeerNetworksIsSet bool
+	// TransitGatewayID attaches the VPC to an existing transit gateway on AWS, which TransitGatewayRoutes are
+	// sent to. The VPC's range is propagated into the transit gateway's default route table, or
+	// TransitGatewayRouteTableID, unless NoTransitGatewayPropagation is set
+	TransitGatewayID                 string
+	TransitGatewayIDIsSet            bool
+	TransitGatewayRoutes             cli.StringSlice
+	TransitGatewayRoutesIsSet        bool
+	TransitGatewayRouteTableID       string
+	TransitGatewayRouteTableIDIsSet  bool
+	NoTransitGatewayPropagation      bool
+	NoTransitGatewayPropagationIsSet bool
+	// NCCHub makes the network a VPC spoke of an existing Network Connectivity Center hub on GCP, which
+	// exports the routes to its subnets other than those in NCCExcludeExportRanges
+	NCCHub                      string
+	NCCHubIsSet                 bool
+	NCCExcludeExportRanges      cli.StringSlice
+	NCCExcludeExportRangesIsSet bool
+	// WebAllowIPs, DirectorAllowIPs, SSHAllowIPs and MetricsAllowIPs replace AllowIPs for the web UI, the
+	// director's APIs, SSH to the director and bastion, and Grafana respectively
+	WebAllowIPs           string
+	WebAllowIPsIsSet      bool
+	DirectorAllowIPs      string
+	DirectorAllowIPsIsSet bool
+	SSHAllowIPs           string
+	SSHAllowIPsIsSet      bool
+	MetricsAllowIPs       string
+	MetricsAllowIPsIsSet  bool
+	// WebEIP on AWS and WebAddressName on GCP are existing addresses that the web endpoint takes, in place of
+	// one created and released along with the deployment
+	WebEIP              string
+	WebEIPIsSet         bool
+	WebAddressName      string
+	WebAddressNameIsSet bool
+	// DedicatedWeb and DedicatedWorkers run those VMs on hardware dedicated to this account, with EC2
+	// dedicated tenancy or a GCP sole-tenant node group of SoleTenantNodeCount SoleTenantNodeType nodes
+	DedicatedWeb             bool
+	DedicatedWebIsSet        bool
+	DedicatedWorkers         bool
+	DedicatedWorkersIsSet    bool
+	SoleTenantNodeType       string
+	SoleTenantNodeTypeIsSet  bool
+	SoleTenantNodeCount      int
+	SoleTenantNodeCountIsSet bool
+	// ExternalDBURL points the deployment at an existing Postgres server instead of creating RDS or CloudSQL
+	ExternalDBURL         string
+	ExternalDBURLIsSet    bool
+	ExternalDBCACert      string
+	ExternalDBCACertIsSet bool
+	// DBEngine chooses between an RDS instance of DBSize and Aurora Serverless v2 scaling between DBMinACU and DBMaxACU
+	DBEngine      string
+	DBEngineIsSet bool
+	DBMinACU      float64
+	DBMinACUIsSet bool
+	DBMaxACU      float64
+	DBMaxACUIsSet bool
+	// DBHA makes the CloudSQL instance regional, with a standby in another zone that it fails over to
+	DBHA      bool
+	DBHAIsSet bool
+	// DBMultiAZ runs the RDS instance with a synchronous standby in another zone that it fails over to
+	DBMultiAZ      bool
+	DBMultiAZIsSet bool
+	// DBReadReplica adds a read replica of the database, for querying without adding load to the ATC's
+	DBReadReplica      bool
+	DBReadReplicaIsSet bool
+	// DBKMSKey is the customer managed key the database is encrypted with: the ID or ARN of a KMS key on
+	// AWS, or the resource name of a Cloud KMS key on GCP
+	DBKMSKey      string
+	DBKMSKeyIsSet bool
+	// DBIAMAuth connects Concourse, UAA and CredHub to RDS with IAM authentication tokens in place of a
+	// password, through a proxy on the web VMs that refreshes the tokens
+	DBIAMAuth      bool
+	DBIAMAuthIsSet bool
+	// DBVersion is the Postgres major version of the database. Changing it to a later version upgrades the
+	// database in place, which DBUpgrade has to confirm
+	DBVersion      string
+	DBVersionIsSet bool
+	DBUpgrade      bool
+	DBUpgradeIsSet bool
+	// DBResize is how an existing database is moved onto a new DBSize, which has to be chosen as resizing
+	// it in place restarts it
+	DBResize      string
+	DBResizeIsSet bool
+	// DBStorage is the GB of storage of the RDS instance, which autoscales up to DBMaxStorage when it is set
+	DBStorage          int
+	DBStorageIsSet     bool
+	DBMaxStorage       int
+	DBMaxStorageIsSet  bool
+	DBStorageType      string
+	DBStorageTypeIsSet bool
+	DBIOPS             int
+	DBIOPSIsSet        bool
+	// DBBackupRetention is the days that automated backups of the database are kept for, which are taken
+	// during DBBackupWindow. DBDeletionProtection stops the database being deleted until it is turned off
+	DBBackupRetention         int
+	DBBackupRetentionIsSet    bool
+	DBBackupWindow            string
+	DBBackupWindowIsSet       bool
+	DBDeletionProtection      bool
+	DBDeletionProtectionIsSet bool
+	// DBParams are Postgres parameters for the database in the format name=value
+	DBParams      cli.StringSlice
+	DBParamsIsSet bool
+	// SIEMEndpoint receives Concourse auth events and control-tower audit records
+	SIEMEndpoint      string
+	SIEMEndpointIsSet bool
+	// ConcourseVersion overrides the Concourse release pinned in this version of control-tower
+	ConcourseVersion      string
+	ConcourseVersionIsSet bool
+	// ContainerPlacementStrategies are applied in order to choose the worker for each container
+	ContainerPlacementStrategies      cli.StringSlice
+	ContainerPlacementStrategiesIsSet bool
+	MaxActiveTasksPerWorker           int
+	MaxActiveTasksPerWorkerIsSet      bool
+	MaxActiveContainersPerWorker      int
+	MaxActiveContainersPerWorkerIsSet bool
+	MaxActiveVolumesPerWorker         int
+	MaxActiveVolumesPerWorkerIsSet    bool
+	// DefaultTaskCPULimit and DefaultTaskMemoryLimit apply to tasks that don't set their own container_limits
+	DefaultTaskCPULimit         int
+	DefaultTaskCPULimitIsSet    bool
+	DefaultTaskMemoryLimit      string
+	DefaultTaskMemoryLimitIsSet bool
+	InterceptIdleTimeout        string
+	InterceptIdleTimeoutIsSet   bool
+	FailedGracePeriod           string
+	FailedGracePeriodIsSet      bool
+	// EnableP2PVolumeStreaming streams volumes directly between workers rather than through the web node
+	EnableP2PVolumeStreaming      bool
+	EnableP2PVolumeStreamingIsSet bool
+	StreamingCompression          string
+	StreamingCompressionIsSet     bool
+	// ConcourseWebEnv and ConcourseWorkerEnv are extra CONCOURSE_* settings in the format KEY=VALUE
+	ConcourseWebEnv         cli.StringSlice
+	ConcourseWebEnvIsSet    bool
+	ConcourseWorkerEnv      cli.StringSlice
+	ConcourseWorkerEnvIsSet bool
+	// ExternalURL is the address of a reverse proxy in front of Concourse, which may include a path prefix
+	ExternalURL      string
+	ExternalURLIsSet bool
+	// SyslogAddress receives Concourse build logs from the web node's syslog drainer
+	SyslogAddress        string
+	SyslogAddressIsSet   bool
+	SyslogTransport      string
+	SyslogTransportIsSet bool
+	SyslogCACert         string
+	SyslogCACertIsSet    bool
+	// NewRelicAccountID and NewRelicInsertKey send Concourse metrics to New Relic, in batches of
+	// NewRelicBatchSize events or every NewRelicBatchDuration, whichever comes first
+	NewRelicAccountID          string
+	NewRelicAccountIDIsSet     bool
+	NewRelicInsertKey          string
+	NewRelicInsertKeyIsSet     bool
+	NewRelicInsightsURL        string
+	NewRelicInsightsURLIsSet   bool
+	NewRelicServicePrefix      string
+	NewRelicServicePrefixIsSet bool
+	NewRelicBatchSize          int
+	NewRelicBatchSizeIsSet     bool
+	NewRelicBatchDuration      string
+	NewRelicBatchDurationIsSet bool
+	// InfluxDbURL sends Concourse metrics to an existing InfluxDB in place of the colocated metrics stack
+	InfluxDbURL            string
+	InfluxDbURLIsSet       bool
+	InfluxDbDatabase       string
+	InfluxDbDatabaseIsSet  bool
+	InfluxDbUsername       string
+	InfluxDbUsernameIsSet  bool
+	InfluxDbPassword       string
+	InfluxDbPasswordIsSet  bool
+	InfluxDbBatchSize      int
+	InfluxDbBatchSizeIsSet bool
+	// LockStaleMinutes is how long a deployment lock can go without a heartbeat before it is taken over
+	LockStaleMinutes      int
+	LockStaleMinutesIsSet bool
+	// UpgradeBatchSize is how many workers are upgraded at once, each batch soaking for SoakMinutes
+	// before the next. The upgrade halts if the rate of errored builds rises by more than
+	// SoakMaxErrorRateIncrease percentage points
+	UpgradeBatchSize              int
+	UpgradeBatchSizeIsSet         bool
+	SoakMinutes                   int
+	SoakMinutesIsSet              bool
+	SoakMaxErrorRateIncrease      int
+	SoakMaxErrorRateIncreaseIsSet bool
+	// OPAURL is the Open Policy Agent server Concourse asks before performing the PolicyCheckActions
+	OPAURL                  string
+	OPAURLIsSet             bool
+	OPAPolicyPath           string
+	OPAPolicyPathIsSet      bool
+	PolicyCheckActions      cli.StringSlice
+	PolicyCheckActionsIsSet bool
+	// EnableAuditLogs turns on every AuditLogCategories, before the category=true|false overrides in AuditLogs
+	EnableAuditLogs      bool
+	EnableAuditLogsIsSet bool
+	AuditLogs            cli.StringSlice
+	AuditLogsIsSet       bool
+	// DNS is the provider managing the record for Domain, when it isn't the IAAS's own DNS
+	DNS          string
+	DNSIsSet     bool
+	DNSZone      string
+	DNSZoneIsSet bool
+	// QuotaPolicy is a policy file or https:// policy service capping what can be deployed. Quota is
+	// loaded from it before the deploy starts
+	QuotaPolicy      string
+	QuotaPolicyIsSet bool
+	Quota            *quota.Policy
 }
 
 // MarkSetFlags is marking the IsSet DeployArgs
@@ -130,6 +625,8 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.WorkerSizeIsSet = true
 			case "web-size":
 				a.WebSizeIsSet = true
+			case "web-count":
+				a.WebCountIsSet = true
 			case "persistent-disk":
 				a.PersistentDiskIsSet = true
 			case "iaas":
@@ -142,6 +639,12 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.RDSDiskEncryptionIsSet = true
 			case "spot", "preemptible":
 				a.SpotIsSet = true
+			case "worker-spot":
+				a.SpotIsSet = true
+				a.WorkerSpotIsSet = true
+			case "worker-preemptible":
+				a.SpotIsSet = true
+				a.WorkerPreemptIsSet = true
 			case "allow-ips":
 				a.AllowIPsIsSet = true
 			case "bitbucket-auth-client-id":
@@ -168,6 +671,60 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.MicrosoftAuthClientSecretIsSet = true
 			case "microsoft-auth-tenant":
 				a.MicrosoftAuthTenantIsSet = true
+			case "oidc-issuer":
+				a.OIDCIssuerIsSet = true
+			case "oidc-client-id":
+				a.OIDCClientIDIsSet = true
+			case "oidc-client-secret":
+				a.OIDCClientSecretIsSet = true
+			case "oidc-groups-claim":
+				a.OIDCGroupsClaimIsSet = true
+			case "oidc-username-claim":
+				a.OIDCUsernameClaimIsSet = true
+			case "oauth-auth-url":
+				a.OAuthAuthURLIsSet = true
+			case "oauth-token-url":
+				a.OAuthTokenURLIsSet = true
+			case "oauth-userinfo-url":
+				a.OAuthUserinfoURLIsSet = true
+			case "oauth-client-id":
+				a.OAuthClientIDIsSet = true
+			case "oauth-client-secret":
+				a.OAuthClientSecretIsSet = true
+			case "oauth-scopes":
+				a.OAuthScopesIsSet = true
+			case "oauth-groups-key":
+				a.OAuthGroupsKeyIsSet = true
+			case "saml-metadata-url":
+				a.SAMLMetadataURLIsSet = true
+			case "saml-ca-cert":
+				a.SAMLCACertIsSet = true
+			case "saml-username-attr":
+				a.SAMLUsernameAttrIsSet = true
+			case "saml-email-attr":
+				a.SAMLEmailAttrIsSet = true
+			case "saml-groups-attr":
+				a.SAMLGroupsAttrIsSet = true
+			case "ldap-host":
+				a.LDAPHostIsSet = true
+			case "ldap-bind-dn":
+				a.LDAPBindDNIsSet = true
+			case "ldap-bind-password":
+				a.LDAPBindPasswordIsSet = true
+			case "ldap-ca-cert":
+				a.LDAPCACertIsSet = true
+			case "ldap-user-search-base-dn":
+				a.LDAPUserSearchBaseDNIsSet = true
+			case "ldap-user-search-filter":
+				a.LDAPUserSearchFilterIsSet = true
+			case "ldap-user-search-username":
+				a.LDAPUserSearchUsernameIsSet = true
+			case "ldap-group-search-base-dn":
+				a.LDAPGroupSearchBaseDNIsSet = true
+			case "ldap-group-search-filter":
+				a.LDAPGroupSearchFilterIsSet = true
+			case "ldap-group-search-name-attr":
+				a.LDAPGroupSearchNameAttrIsSet = true
 			case "add-tag":
 				a.TagsIsSet = true
 			case "namespace":
@@ -176,18 +733,338 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.ZoneIsSet = true
 			case "worker-type":
 				a.WorkerTypeIsSet = true
-			case "vpc-network-range":
+			case "network-cidr":
 				a.NetworkCIDRIsSet = true
-			case "public-subnet-range":
+			case "public-cidr":
 				a.PublicCIDRIsSet = true
-			case "private-subnet-range":
+			case "private-cidr":
 				a.PrivateCIDRIsSet = true
+			case "rds-cidrs":
+				a.RDSCIDRsIsSet = true
 			case "rds-subnet-range1":
 				a.RDS1CIDRIsSet = true
 			case "rds-subnet-range2":
 				a.RDS2CIDRIsSet = true
 			case "no-metrics":
 				a.NoMetricsIsSet = true
+			case "managed-prometheus":
+				a.ManagedPrometheusIsSet = true
+			case "enable-prometheus-metrics":
+				a.EnablePrometheusMetricsIsSet = true
+			case "metrics-scrape-allow-ips":
+				a.MetricsScrapeAllowIPsIsSet = true
+			case "external-worker-allow-ips":
+				a.ExternalWorkerAllowIPsIsSet = true
+			case "restrict-worker-egress":
+				a.RestrictWorkerEgressIsSet = true
+			case "worker-egress-allow":
+				a.WorkerEgressAllowIsSet = true
+			case "worker-zone":
+				a.WorkerZonesIsSet = true
+			case "existing-vpc-id":
+				a.ExistingVPCIDIsSet = true
+			case "existing-network":
+				a.ExistingNetworkIsSet = true
+			case "existing-public-subnet":
+				a.ExistingPublicSubnetIsSet = true
+			case "existing-private-subnet":
+				a.ExistingPrivateSubnetIsSet = true
+			case "existing-db-subnets":
+				a.ExistingDBSubnetsIsSet = true
+			case "private":
+				a.PrivateIsSet = true
+			case "bastion":
+				a.BastionIsSet = true
+			case "bastion-allow-ips":
+				a.BastionAllowIPsIsSet = true
+			case "vpc-endpoints":
+				a.VPCEndpointsIsSet = true
+			case "nat-topology":
+				a.NATTopologyIsSet = true
+			case "ipv6":
+				a.IPv6IsSet = true
+			case "allow-ipv6-ips":
+				a.AllowIPv6sIsSet = true
+			case "peer-vpc-id":
+				a.PeerVPCIDsIsSet = true
+			case "peer-network":
+				a.PeerNetworksIsSet = true
+			case "transit-gateway-id":
+				a.TransitGatewayIDIsSet = true
+			case "transit-gateway-route":
+				a.TransitGatewayRoutesIsSet = true
+			case "transit-gateway-route-table-id":
+				a.TransitGatewayRouteTableIDIsSet = true
+			case "no-transit-gateway-propagation":
+				a.NoTransitGatewayPropagationIsSet = true
+			case "ncc-hub":
+				a.NCCHubIsSet = true
+			case "ncc-exclude-export-range":
+				a.NCCExcludeExportRangesIsSet = true
+			case "web-allow-ips":
+				a.WebAllowIPsIsSet = true
+			case "director-allow-ips":
+				a.DirectorAllowIPsIsSet = true
+			case "ssh-allow-ips":
+				a.SSHAllowIPsIsSet = true
+			case "metrics-allow-ips":
+				a.MetricsAllowIPsIsSet = true
+			case "web-eip":
+				a.WebEIPIsSet = true
+			case "web-address-name":
+				a.WebAddressNameIsSet = true
+			case "dedicated-web":
+				a.DedicatedWebIsSet = true
+			case "dedicated-workers":
+				a.DedicatedWorkersIsSet = true
+			case "sole-tenant-node-type":
+				a.SoleTenantNodeTypeIsSet = true
+			case "sole-tenant-node-count":
+				a.SoleTenantNodeCountIsSet = true
+			case "external-db-url":
+				a.ExternalDBURLIsSet = true
+			case "external-db-ca-cert":
+				a.ExternalDBCACertIsSet = true
+			case "db-engine":
+				a.DBEngineIsSet = true
+			case "db-min-acu":
+				a.DBMinACUIsSet = true
+			case "db-max-acu":
+				a.DBMaxACUIsSet = true
+			case "db-ha":
+				a.DBHAIsSet = true
+			case "db-multi-az":
+				a.DBMultiAZIsSet = true
+			case "db-read-replica":
+				a.DBReadReplicaIsSet = true
+			case "db-kms-key":
+				a.DBKMSKeyIsSet = true
+			case "db-iam-auth":
+				a.DBIAMAuthIsSet = true
+			case "db-version":
+				a.DBVersionIsSet = true
+			case "db-upgrade":
+				a.DBUpgradeIsSet = true
+			case "db-resize":
+				a.DBResizeIsSet = true
+			case "db-storage":
+				a.DBStorageIsSet = true
+			case "db-max-storage":
+				a.DBMaxStorageIsSet = true
+			case "db-storage-type":
+				a.DBStorageTypeIsSet = true
+			case "db-iops":
+				a.DBIOPSIsSet = true
+			case "db-backup-retention":
+				a.DBBackupRetentionIsSet = true
+			case "db-backup-window":
+				a.DBBackupWindowIsSet = true
+			case "db-deletion-protection":
+				a.DBDeletionProtectionIsSet = true
+			case "db-param":
+				a.DBParamsIsSet = true
+			case "siem-endpoint":
+				a.SIEMEndpointIsSet = true
+			case "concourse-version":
+				a.ConcourseVersionIsSet = true
+			case "container-placement-strategy":
+				a.ContainerPlacementStrategiesIsSet = true
+			case "max-active-tasks-per-worker":
+				a.MaxActiveTasksPerWorkerIsSet = true
+			case "max-active-containers-per-worker":
+				a.MaxActiveContainersPerWorkerIsSet = true
+			case "max-active-volumes-per-worker":
+				a.MaxActiveVolumesPerWorkerIsSet = true
+			case "default-task-cpu-limit":
+				a.DefaultTaskCPULimitIsSet = true
+			case "default-task-memory-limit":
+				a.DefaultTaskMemoryLimitIsSet = true
+			case "intercept-idle-timeout":
+				a.InterceptIdleTimeoutIsSet = true
+			case "failed-container-grace-period":
+				a.FailedGracePeriodIsSet = true
+			case "enable-p2p-volume-streaming":
+				a.EnableP2PVolumeStreamingIsSet = true
+			case "streaming-compression":
+				a.StreamingCompressionIsSet = true
+			case "concourse-web-env":
+				a.ConcourseWebEnvIsSet = true
+			case "concourse-worker-env":
+				a.ConcourseWorkerEnvIsSet = true
+			case "external-url":
+				a.ExternalURLIsSet = true
+			case "syslog-address":
+				a.SyslogAddressIsSet = true
+			case "syslog-transport":
+				a.SyslogTransportIsSet = true
+			case "syslog-ca-cert":
+				a.SyslogCACertIsSet = true
+			case "newrelic-account-id":
+				a.NewRelicAccountIDIsSet = true
+			case "newrelic-insert-key":
+				a.NewRelicInsertKeyIsSet = true
+			case "newrelic-insights-api-url":
+				a.NewRelicInsightsURLIsSet = true
+			case "newrelic-service-prefix":
+				a.NewRelicServicePrefixIsSet = true
+			case "newrelic-batch-size":
+				a.NewRelicBatchSizeIsSet = true
+			case "newrelic-batch-duration":
+				a.NewRelicBatchDurationIsSet = true
+			case "influxdb-url":
+				a.InfluxDbURLIsSet = true
+			case "influxdb-database":
+				a.InfluxDbDatabaseIsSet = true
+			case "influxdb-username":
+				a.InfluxDbUsernameIsSet = true
+			case "influxdb-password":
+				a.InfluxDbPasswordIsSet = true
+			case "influxdb-batch-size":
+				a.InfluxDbBatchSizeIsSet = true
+			case "lock-stale-minutes":
+				a.LockStaleMinutesIsSet = true
+			case "upgrade-batch-size":
+				a.UpgradeBatchSizeIsSet = true
+			case "soak-minutes":
+				a.SoakMinutesIsSet = true
+			case "soak-max-error-rate-increase":
+				a.SoakMaxErrorRateIncreaseIsSet = true
+			case "opa-url":
+				a.OPAURLIsSet = true
+			case "opa-policy-path":
+				a.OPAPolicyPathIsSet = true
+			case "policy-check-action":
+				a.PolicyCheckActionsIsSet = true
+			case "enable-audit-logs":
+				a.EnableAuditLogsIsSet = true
+			case "audit-log":
+				a.AuditLogsIsSet = true
+			case "dns":
+				a.DNSIsSet = true
+			case "dns-zone":
+				a.DNSZoneIsSet = true
+			case "quota-policy":
+				a.QuotaPolicyIsSet = true
+			case "managed-prometheus-workspace-url":
+				a.ManagedPrometheusWorkspaceURLIsSet = true
+			case "credential-manager":
+				a.CredentialManagerIsSet = true
+			case "secretsmanager-pipeline-path-template":
+				a.SecretsManagerPipelinePathIsSet = true
+			case "secretsmanager-team-path-template":
+				a.SecretsManagerTeamPathIsSet = true
+			case "ssm-pipeline-path-template":
+				a.SSMPipelinePathIsSet = true
+			case "ssm-team-path-template":
+				a.SSMTeamPathIsSet = true
+			case "ssm-kms-key-id":
+				a.SSMKMSKeyIDIsSet = true
+			case "vault-url":
+				a.VaultURLIsSet = true
+			case "vault-ca-cert":
+				a.VaultCACertIsSet = true
+			case "vault-auth-backend":
+				a.VaultAuthBackendIsSet = true
+			case "vault-auth-param":
+				a.VaultAuthParamsIsSet = true
+			case "vault-client-token":
+				a.VaultClientTokenIsSet = true
+			case "vault-path-prefix":
+				a.VaultPathPrefixIsSet = true
+			case "vars-file":
+				a.VarsFileIsSet = true
+			case "worker-cgroup-version":
+				a.WorkerCgroupVersionIsSet = true
+			case "worker-sysctl":
+				a.WorkerSysctlsIsSet = true
+			case "worker-dns-server":
+				a.WorkerDNSServersIsSet = true
+			case "worker-dns-proxy":
+				a.WorkerDNSProxyIsSet = true
+			case "worker-network-pool":
+				a.WorkerNetworkPoolIsSet = true
+			case "worker-max-containers":
+				a.WorkerMaxContainersIsSet = true
+			case "worker-imds-hop-limit":
+				a.WorkerIMDSHopLimitIsSet = true
+			case "worker-spot-bid-percentage":
+				a.WorkerSpotBidIsSet = true
+			case "cf-auth-api-url":
+				a.CFAuthAPIURLIsSet = true
+			case "cf-auth-client-id":
+				a.CFAuthClientIDIsSet = true
+			case "cf-auth-client-secret":
+				a.CFAuthClientSecretIsSet = true
+			case "cf-auth-ca-cert":
+				a.CFAuthCACertIsSet = true
+			case "main-team-cf-orgs":
+				a.MainCFOrgsIsSet = true
+			case "main-team-cf-spaces":
+				a.MainCFSpacesIsSet = true
+			case "internal-lb":
+				a.InternalLBIsSet = true
+			case "internal-lb-allow-ips":
+				a.InternalLBAllowIPsIsSet = true
+			case "internal-lb-tls-cert":
+				a.InternalLBTLSCertIsSet = true
+			case "internal-lb-tls-key":
+				a.InternalLBTLSKeyIsSet = true
+			case "internal-lb-health-check-path":
+				a.InternalLBHealthCheckPathIsSet = true
+			case "internal-lb-health-check-interval":
+				a.InternalLBHealthCheckIntervalIsSet = true
+			case "internal-lb-healthy-threshold":
+				a.InternalLBHealthyThresholdIsSet = true
+			case "internal-lb-unhealthy-threshold":
+				a.InternalLBUnhealthyThresholdIsSet = true
+			case "skip-migration-check":
+				//do nothing
+			case "teams-file":
+				a.TeamsFileIsSet = true
+			case "artifacts-dir":
+				a.ArtifactsDirIsSet = true
+			case "worker-pools-file":
+				a.WorkerPoolsFileIsSet = true
+			case "arm64-stemcell-url":
+				a.ARM64StemcellURLIsSet = true
+			case "arm64-release-url":
+				a.ARM64ReleaseURLIsSet = true
+			case "arm64-release-sha1":
+				a.ARM64ReleaseSHA1IsSet = true
+			case "windows-worker-count":
+				a.WindowsWorkerCountIsSet = true
+			case "windows-worker-type":
+				a.WindowsWorkerTypeIsSet = true
+			case "windows-worker-release-url":
+				a.WindowsWorkerReleaseURLIsSet = true
+			case "windows-worker-release-sha1":
+				a.WindowsWorkerReleaseSHA1IsSet = true
+			case "windows-stemcell-version":
+				a.WindowsStemcellVersionIsSet = true
+			case "worker-instance-type":
+				a.WorkerInstanceTypeIsSet = true
+			case "web-instance-type":
+				a.WebInstanceTypeIsSet = true
+			case "worker-disk-size-gb":
+				a.WorkerDiskSizeGBIsSet = true
+			case "worker-disk-type":
+				a.WorkerDiskTypeIsSet = true
+			case "worker-autoscale-min":
+				a.WorkerAutoscaleMinIsSet = true
+			case "worker-autoscale-max":
+				a.WorkerAutoscaleMaxIsSet = true
+			case "worker-schedule":
+				a.WorkerSchedulesIsSet = true
+			case "worker-schedule-timezone":
+				a.WorkerScheduleTimezoneIsSet = true
+			case "worker-drain-timeout":
+				a.WorkerDrainTimeoutIsSet = true
+			case "worker-rebalance-interval":
+				a.WorkerRebalanceIntervalIsSet = true
+			case "worker-retire-max-in-flight":
+				a.WorkerRetireMaxInFlightIsSet = true
+			case "prune-teams":
+				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by deployment flags", f)
 			}
@@ -197,6 +1074,10 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 	a.GithubAuthIsSet = c.IsSet("github-auth-client-id") && c.IsSet("github-auth-client-secret")
 	a.GithubEnterpriseAuthIsSet = c.IsSet("github-auth-host") && c.IsSet("github-auth-ca-cert")
 	a.MicrosoftAuthIsSet = c.IsSet("microsoft-auth-client-id") && c.IsSet("microsoft-auth-client-secret")
+	a.OIDCAuthIsSet = c.IsSet("oidc-issuer") && c.IsSet("oidc-client-id") && c.IsSet("oidc-client-secret")
+	a.OAuthAuthIsSet = c.IsSet("oauth-auth-url") && c.IsSet("oauth-token-url") && c.IsSet("oauth-userinfo-url") && c.IsSet("oauth-client-id") && c.IsSet("oauth-client-secret")
+	a.CFAuthIsSet = c.IsSet("cf-auth-api-url") && c.IsSet("cf-auth-client-id") && c.IsSet("cf-auth-client-secret")
+	a.LDAPAuthIsSet = c.IsSet("ldap-host") && c.IsSet("ldap-bind-dn") && c.IsSet("ldap-bind-password") && c.IsSet("ldap-user-search-base-dn")
 	a.MainGithubAuthIsSet = c.IsSet("main-team-github-users") || c.IsSet("main-team-github-teams") || c.IsSet("main-team-github-orgs")
 
 	return nil
@@ -205,21 +1086,87 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 // WorkerSizes are the permitted concourse worker sizes
 var WorkerSizes = []string{"medium", "large", "xlarge", "2xlarge", "4xlarge", "12xlarge", "24xlarge"}
 
+// WorkerDiskTypes are the permitted worker disk types on each IAAS
+var WorkerDiskTypes = map[string][]string{
+	"aws": {"gp2", "gp3", "io1", "io2"},
+	"gcp": {"pd-balanced", "pd-ssd", "pd-standard", "hyperdisk-balanced"},
+}
+
 // WebSizes are the permitted concourse web sizes
 var WebSizes = []string{"small", "medium", "large", "xlarge", "2xlarge"}
 
 // PersistentDiskSizes are the permitted concourse persistent disk sizes
 var PersistentDiskSizes = []string{"small", "default", "medium", "large"}
 
+// AMPRemoteWriteURLPattern matches the remote write endpoint of an AWS Managed Prometheus workspace,
+// capturing its region and workspace ID
+var AMPRemoteWriteURLPattern = regexp.MustCompile(`^https://aps-workspaces\.([a-z0-9-]+)\.amazonaws\.com/workspaces/(ws-[a-zA-Z0-9-]+)/api/v1/remote_write$`)
+
+// ContainerPlacementStrategies contains the valid values for --container-placement-strategy flag
+var ContainerPlacementStrategies = []string{"volume-locality", "random", "fewest-build-containers", "limit-active-tasks", "limit-active-containers", "limit-active-volumes"}
+
+// AuditLogCategories contains the Concourse audit log categories that can be given to --audit-log
+var AuditLogCategories = []string{"builds", "containers", "jobs", "pipelines", "resources", "system", "teams", "volumes", "workers"}
+
+// CredentialManagers contains the valid values for --credential-manager flag
+var CredentialManagers = []string{"credhub", "secretsmanager", "ssm"}
+
+var concourseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// releaseSHA1 matches the checksum of a BOSH release, which newer releases give as a sha256 prefixed by its algorithm
+var releaseSHA1 = regexp.MustCompile(`^([a-f0-9]{40}|sha256:[a-f0-9]{64})$`)
+
+// stemcellVersion matches the version of a stemcell published on bosh.io, like 2019.71 or 1.404
+var stemcellVersion = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// vpcIDPattern matches the ID of a VPC, like vpc-0a1b2c3d4e5f67890
+var vpcIDPattern = regexp.MustCompile(`^vpc-[a-f0-9]+$`)
+
+var kmsKeyIDPattern = regexp.MustCompile(`^(arn:aws[a-z-]*:kms:[a-z0-9-]+:\d{12}:key/)?(mrk-)?[a-f0-9-]{32,36}$`)
+
 // AllowedDBSizes contains the valid values for --db-size flag
 var AllowedDBSizes = []string{"small", "medium", "large", "xlarge", "2xlarge", "4xlarge"}
 
+// AllowedDBEngines contains the valid values for --db-engine flag
+var AllowedDBEngines = []string{config.DB_ENGINE_POSTGRES, config.DB_ENGINE_AURORA_SERVERLESS}
+
+// AllowedDBVersions contains the valid values for --db-version flag
+var AllowedDBVersions = []string{"13", "14", "15", "16"}
+
+// DBResizeBlueGreen and DBResizeInPlace are the ways --db-resize moves an existing database onto a new --db-size
+const (
+	DBResizeBlueGreen = "blue-green"
+	DBResizeInPlace   = "in-place"
+)
+
+// AllowedDBResizes contains the valid values for --db-resize flag
+var AllowedDBResizes = []string{DBResizeBlueGreen, DBResizeInPlace}
+
+// AllowedNATTopologies contains the valid values for --nat-topology flag
+var AllowedNATTopologies = []string{config.NAT_SINGLE, config.NAT_PER_ZONE, config.NAT_INSTANCE}
+
+// AllowedDBStorageTypes contains the valid values for --db-storage-type flag
+var AllowedDBStorageTypes = []string{"gp2", "gp3", "io1", "io2"}
+
+// MinDBStorage and MaxDBStorage are the GB of storage an RDS for PostgreSQL instance can have
+const (
+	MinDBStorage = 20
+	MaxDBStorage = 65536
+)
+
+// MaxACU is the most Aurora capacity units an Aurora Serverless v2 instance can scale to
+const MaxACU = 128
+
 // Validate validates that flag interdependencies
 func (a Args) Validate() error {
 	if !a.IAASIsSet {
 		return fmt.Errorf("--iaas flag not set")
 	}
 
+	if err := a.validateDomainFields(); err != nil {
+		return err
+	}
+
 	if err := a.validateCertFields(); err != nil {
 		return err
 	}
@@ -228,6 +1175,10 @@ func (a Args) Validate() error {
 		return err
 	}
 
+	if err := a.validateInternalLBFields(); err != nil {
+		return err
+	}
+
 	if err := a.validateWebFields(); err != nil {
 		return err
 	}
@@ -240,189 +1191,1678 @@ func (a Args) Validate() error {
 		return err
 	}
 
-	if err := a.validateGithubFields(); err != nil {
+	if err := a.validateExternalDBFields(); err != nil {
 		return err
 	}
 
-	if err := a.validateNetworkRanges(); err != nil {
+	if err := a.validateDBEngineFields(); err != nil {
 		return err
 	}
 
-	if err := a.validateTags(); err != nil {
+	if err := a.validateDBVersionFields(); err != nil {
 		return err
 	}
 
-	if a.MainGithubAuthIsSet {
-		if err := a.validateMainAuth(); err != nil {
-			return err
-		}
+	if err := a.validateDBResizeFields(); err != nil {
+		return err
 	}
 
-	return nil
-}
+	if err := a.validateDBStorageFields(); err != nil {
+		return err
+	}
 
-func (a Args) validateCertFields() error {
-	if a.TLSKey != "" && a.TLSCert == "" {
-		return errors.New("--tls-key requires --tls-cert to also be provided")
+	if err := a.validateDBBackupFields(); err != nil {
+		return err
 	}
-	if a.TLSCert != "" && a.TLSKey == "" {
-		return errors.New("--tls-cert requires --tls-key to also be provided")
+
+	if err := a.validateDBKMSKeyFields(); err != nil {
+		return err
 	}
-	if (a.TLSKey != "" || a.TLSCert != "") && a.Domain == "" {
-		return errors.New("custom certificates require --domain to be provided")
+
+	if err := a.validateDBIAMAuthFields(); err != nil {
+		return err
 	}
 
-	return nil
-}
+	if err := validateDBParams(a.DBParams); err != nil {
+		return err
+	}
 
-func (a Args) validateWorkerFields() error {
+	if err := a.validateGithubFields(); err != nil {
+		return err
+	}
 
-	if a.WorkerCount < 1 {
-		return errors.New("minimum number of workers is 1")
+	if err := a.validateOIDCFields(); err != nil {
+		return err
 	}
 
-	if a.WorkerTypeIsSet && strings.ToLower(a.IAAS) != "aws" {
-		return errors.New("worker-type is only defined on AWS")
+	if err := a.validateOAuthFields(); err != nil {
+		return err
 	}
 
-	re := regexp.MustCompile("^m5$|^m5a$|^m4$")
-	if a.WorkerTypeIsSet && !re.MatchString(a.WorkerType) {
-		return fmt.Errorf("worker-type %s is invalid: must be one of m4, m5, or m5a", a.WorkerType)
+	if err := a.validateSAMLFields(); err != nil {
+		return err
 	}
 
-	for _, size := range WorkerSizes {
-		if size == a.WorkerSize {
-			return nil
-		}
+	if err := a.validateLDAPFields(); err != nil {
+		return err
 	}
-	return fmt.Errorf("unknown worker size: `%s`. Valid sizes are: %v", a.WorkerSize, WorkerSizes)
-}
 
-func (a Args) validateWebFields() error {
-	if a.NoMetricsIsSet && a.InfluxDbRetentionIsSet {
-		return fmt.Errorf("no-metrics is invalid when used with influxdb-retention-period")
+	if err := a.validateCFAuthFields(); err != nil {
+		return err
 	}
 
-	for _, size := range WebSizes {
-		if size == a.WebSize {
-			return nil
+	if err := a.validateVaultFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateCredentialManagerFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateManagedPrometheusFields(); err != nil {
+		return err
+	}
+
+	if a.SIEMEndpoint != "" {
+		if _, err := siem.ParseEndpoint(a.SIEMEndpoint); err != nil {
+			return err
 		}
 	}
-	return fmt.Errorf("unknown web node size: `%s`. Valid sizes are: %v", a.WebSize, WebSizes)
-}
 
-func (a Args) validatePersistentDiskFields() error {
-	for _, size := range PersistentDiskSizes {
-		if size == a.PersistentDiskSize {
-			return nil
+	if a.ConcourseVersion != "" && !concourseVersionPattern.MatchString(a.ConcourseVersion) {
+		return fmt.Errorf("--concourse-version %s is invalid: must be a release version such as 7.11.2", a.ConcourseVersion)
+	}
+
+	if err := a.validateContainerPlacementFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateTaskLimitFields(); err != nil {
+		return err
+	}
+
+	if a.StreamingCompression != "" {
+		known := false
+		for _, compression := range StreamingCompressions {
+			known = known || compression == a.StreamingCompression
+		}
+		if !known {
+			return fmt.Errorf("unknown streaming compression: `%s`. Valid compressions are: %v", a.StreamingCompression, StreamingCompressions)
 		}
 	}
-	return fmt.Errorf("unknown persistent disk size: `%s`. Valid sizes are: %v", a.PersistentDiskSize, PersistentDiskSizes)
-}
 
-func (a Args) validateDBFields() error {
-	for _, size := range AllowedDBSizes {
-		if size == a.DBSize {
-			return nil
+	if err := validateConcourseEnv("concourse-web-env", a.ConcourseWebEnv); err != nil {
+		return err
+	}
+
+	if err := validateConcourseEnv("concourse-worker-env", a.ConcourseWorkerEnv); err != nil {
+		return err
+	}
+
+	if a.ExternalURL != "" {
+		externalURL, err := url.Parse(a.ExternalURL)
+		if err != nil || externalURL.Scheme != "https" || externalURL.Host == "" || externalURL.RawQuery != "" || externalURL.Fragment != "" {
+			return fmt.Errorf("--external-url %s is invalid: must be an https URL without a query or fragment", a.ExternalURL)
 		}
 	}
-	return fmt.Errorf("unknown DB size: `%s`. Valid sizes are: %v", a.DBSize, AllowedDBSizes)
-}
 
-func (a Args) validateGithubFields() error {
-	if a.GithubAuthClientID != "" && a.GithubAuthClientSecret == "" {
-		return errors.New("--github-auth-client-id requires --github-auth-client-secret to also be provided")
+	if err := a.validateSyslogFields(); err != nil {
+		return err
 	}
-	if a.GithubAuthClientID == "" && a.GithubAuthClientSecret != "" {
-		return errors.New("--github-auth-client-secret requires --github-auth-client-id to also be provided")
+
+	if err := a.validateNewRelicFields(); err != nil {
+		return err
 	}
-	if a.GithubAuthHost != "" && (!a.GithubAuthIsSet || a.GithubAuthCaCert == "") {
-		return errors.New("--github-auth-host requires --github-auth-ca-cert, --github-auth-client-id, --github-auth-client-secret to also be provided")
+
+	if err := a.validateInfluxDbFields(); err != nil {
+		return err
 	}
-	if a.GithubAuthCaCert != "" && (!a.GithubAuthIsSet || a.GithubAuthHost == "") {
-		return errors.New("--github-auth-ca-cert requires --github-auth-host, --github-auth-client-id, --github-auth-client-secret to also be provided")
+
+	if a.LockStaleMinutesIsSet && a.LockStaleMinutes < 1 {
+		return errors.New("--lock-stale-minutes must be at least 1")
 	}
-	if a.GithubEnterpriseAuthIsSet && !a.certParseable() {
-		return errors.New("unable to decode value passed to --github-auth-ca-cert. Provide a CA certificate in PEM format")
+
+	if err := a.validateUpgradeBatchFields(); err != nil {
+		return err
 	}
-	if a.GithubEnterpriseAuthIsSet && !govalidator.IsDNSName(a.GithubAuthHost) {
-		return errors.New("--github-auth-host must be a valid DNS address (omitting protocol)")
+
+	if err := a.validatePolicyCheckFields(); err != nil {
+		return err
 	}
-	return nil
-}
 
-func (a Args) certParseable() bool {
-	decodedCert, _ := pem.Decode([]byte(a.GithubAuthCaCert))
-	return decodedCert != nil
-}
+	if err := a.validateAuditLogFields(); err != nil {
+		return err
+	}
 
-func (a Args) validateNetworkRanges() error {
-	if a.PublicCIDR != "" || a.PrivateCIDR != "" {
-		if a.PublicCIDR == "" || a.PrivateCIDR == "" {
-			return errors.New("both --public-subnet-range and --private-subnet-range are required when either is provided")
-		}
+	if err := a.validateDNSFields(); err != nil {
+		return err
 	}
 
-	return nil
-}
+	if a.QuotaPolicyIsSet && a.QuotaPolicy == "" {
+		return errors.New("--quota-policy can't be removed once set, deploy with a policy that leaves the limits out to lift them")
+	}
 
-func (a Args) validateTags() error {
-	pattern := regexp.MustCompile(`\w+=\w+`)
-	for _, tag := range a.Tags {
-		if !pattern.MatchString(tag) {
-			return fmt.Errorf("`%v` is not in the format `key=value`", tag)
-		}
+	if quota.IsURL(a.QuotaPolicy) && !strings.HasPrefix(a.QuotaPolicy, "https://") {
+		return fmt.Errorf("--quota-policy %s is invalid: policy services must be reached over https", a.QuotaPolicy)
 	}
-	return nil
-}
 
-func (a Args) validateMainAuth() error {
-	if err := a.validateMainAuthFlags(); err != nil {
+	if err := a.validateNetworkRanges(); err != nil {
 		return err
 	}
-	return nil
-}
 
-// This is a partial validation check based on the format expected by Concourse:
-// https://concourse-ci.org/github-auth.html#configuring-main-team-authorization
-// as well as some of the validation rules imposed by GitHub:
-// https://github.com/dead-claudia/github-limits
-// We don't check all rules (such as reserved names) as we're just trying to save
-// users from typos rather than from creating invalid entities in GitHub
-func (a Args) validateMainAuthFlags() error {
-	userAndOrgRe := regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`)
-	teamRe := regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	if err := a.validateExistingNetworkFields(); err != nil {
+		return err
+	}
 
-	if a.MainGithubUsersIsSet {
-		for _, user := range strings.Split(a.MainGithubUsers, ",") {
-			if userAndOrgRe.FindString(strings.TrimSpace(user)) == "" {
-				return fmt.Errorf("Invalid user %q provided to --main-team-github-users", user)
-			}
+	if a.Private && strings.ToLower(a.IAAS) == "gcp" && a.WebCount > 1 {
+		return errors.New("--private with --web-count greater than 1 is only available on AWS, as the load balancer in front of web instances on GCP is always external")
+	}
+
+	if err := a.validateBastionFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateVPCEndpointsFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateNATTopologyFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateIPv6Fields(); err != nil {
+		return err
+	}
+
+	if err := a.validatePeeringFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateTransitGatewayFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateNCCFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateWebAddressFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateTags(); err != nil {
+		return err
+	}
+
+	if err := a.validateWorkerKernelFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateWorkerContainerdFields(); err != nil {
+		return err
+	}
+
+	for _, rule := range a.WorkerEgressAllow {
+		if rule == "" {
+			continue
+		}
+		if _, err := config.ParseEgressRule(rule); err != nil {
+			return err
 		}
 	}
 
-	if a.MainGithubOrgsIsSet {
-		for _, org := range strings.Split(a.MainGithubOrgs, ",") {
-			if userAndOrgRe.FindString(strings.TrimSpace(org)) == "" {
-				return fmt.Errorf("Invalid org %q provided to --main-team-github-orgs", org)
-			}
+	zones := map[string]bool{}
+	for _, zone := range a.WorkerZones {
+		if zones[zone] {
+			return fmt.Errorf("--worker-zone %s is given more than once", zone)
 		}
+		zones[zone] = true
 	}
 
-	if a.MainGithubTeamsIsSet {
-		for _, orgTeam := range strings.Split(a.MainGithubTeams, ",") {
-			parts := strings.Split(orgTeam, ":")
-			org := strings.TrimSpace(parts[0])
-			if len(parts) != 2 {
-				return fmt.Errorf("Invalid team %q does not contain org", org)
-			}
-			team := strings.TrimSpace(parts[1])
-			if userAndOrgRe.FindString(org) == "" {
-				return fmt.Errorf("Invalid org %q provided for team %q in --main-team-github-teams", org, team)
-			}
-			if teamRe.FindString(team) == "" {
-				return fmt.Errorf("Invalid team %q provided to --main-team-github-teams", orgTeam)
-			}
+	if a.MainGithubAuthIsSet {
+		if err := a.validateMainAuth(); err != nil {
+			return err
+		}
+	}
+
+	if a.PruneTeams && !a.TeamsFileIsSet {
+		return errors.New("--prune-teams requires --teams-file to also be provided")
+	}
+
+	return nil
+}
+
+func (a Args) validateCertFields() error {
+	if a.TLSKey != "" && a.TLSCert == "" {
+		return errors.New("--tls-key requires --tls-cert to also be provided")
+	}
+	if a.TLSCert != "" && a.TLSKey == "" {
+		return errors.New("--tls-cert requires --tls-key to also be provided")
+	}
+	if (a.TLSKey != "" || a.TLSCert != "") && a.Domain == "" {
+		return errors.New("custom certificates require --domain to be provided")
+	}
+
+	return nil
+}
+
+func (a Args) validateInternalLBFields() error {
+	if a.InternalLB && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("internal-lb is only defined on AWS")
+	}
+	if !a.InternalLB && (a.InternalLBAllowIPsIsSet || a.InternalLBTLSCertIsSet || a.InternalLBTLSKeyIsSet) {
+		return errors.New("--internal-lb-allow-ips, --internal-lb-tls-cert and --internal-lb-tls-key require --internal-lb to also be provided")
+	}
+	if a.InternalLBTLSKey != "" && a.InternalLBTLSCert == "" {
+		return errors.New("--internal-lb-tls-key requires --internal-lb-tls-cert to also be provided")
+	}
+	if a.InternalLBTLSCert != "" && a.InternalLBTLSKey == "" {
+		return errors.New("--internal-lb-tls-cert requires --internal-lb-tls-key to also be provided")
+	}
+	if a.InternalLBTLSCert != "" {
+		if decodedCert, _ := pem.Decode([]byte(a.InternalLBTLSCert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --internal-lb-tls-cert. Provide a certificate in PEM format")
+		}
+	}
+	return a.validateInternalLBHealthCheckFields()
+}
+
+func (a Args) validateInternalLBHealthCheckFields() error {
+	if !a.InternalLB && (a.InternalLBHealthCheckPathIsSet || a.InternalLBHealthCheckIntervalIsSet || a.InternalLBHealthyThresholdIsSet || a.InternalLBUnhealthyThresholdIsSet) {
+		return errors.New("internal load balancer health check flags require --internal-lb to also be provided")
+	}
+	if a.InternalLBHealthCheckPathIsSet && !regexp.MustCompile(`^/[A-Za-z0-9/_.~%?=&-]*$`).MatchString(a.InternalLBHealthCheckPath) {
+		return fmt.Errorf("internal-lb-health-check-path %q is invalid: must be a URL path beginning with /", a.InternalLBHealthCheckPath)
+	}
+	if a.InternalLBHealthCheckIntervalIsSet && (a.InternalLBHealthCheckInterval < 5 || a.InternalLBHealthCheckInterval > 300) {
+		return fmt.Errorf("internal-lb-health-check-interval %d is invalid: must be between 5 and 300 seconds", a.InternalLBHealthCheckInterval)
+	}
+	if a.InternalLBHealthyThresholdIsSet && (a.InternalLBHealthyThreshold < 2 || a.InternalLBHealthyThreshold > 10) {
+		return fmt.Errorf("internal-lb-healthy-threshold %d is invalid: must be between 2 and 10", a.InternalLBHealthyThreshold)
+	}
+	if a.InternalLBUnhealthyThresholdIsSet && (a.InternalLBUnhealthyThreshold < 2 || a.InternalLBUnhealthyThreshold > 10) {
+		return fmt.Errorf("internal-lb-unhealthy-threshold %d is invalid: must be between 2 and 10", a.InternalLBUnhealthyThreshold)
+	}
+	return nil
+}
+
+// validateInstanceTypeName checks that the type looks like an EC2 instance type on AWS, or a machine
+// type on GCP. Whether the region offers it is left to the IAAS
+func validateInstanceTypeName(flag, instanceType, iaas string) error {
+	if strings.ToLower(iaas) == "aws" {
+		if !regexp.MustCompile(`^[a-z][a-z0-9-]*\.[a-z0-9]+$`).MatchString(instanceType) {
+			return fmt.Errorf("%s %q is invalid: must be an EC2 instance type like m5.xlarge", flag, instanceType)
+		}
+		return nil
+	}
+	if !regexp.MustCompile(`^[a-z][a-z0-9]*-[a-z0-9-]+$`).MatchString(instanceType) {
+		return fmt.Errorf("%s %q is invalid: must be a machine type like n1-standard-4", flag, instanceType)
+	}
+	return nil
+}
+
+func (a Args) validateWorkerFields() error {
+
+	if a.WorkerCount < 1 {
+		return errors.New("minimum number of workers is 1")
+	}
+
+	if a.WebCountIsSet && a.WebCount < 1 {
+		return errors.New("minimum number of web instances is 1")
+	}
+
+	if a.WorkerTypeIsSet && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("worker-type is only defined on AWS")
+	}
+
+	if a.WorkerIMDSHopLimitIsSet && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("worker-imds-hop-limit is only defined on AWS")
+	}
+
+	if a.WorkerIMDSHopLimitIsSet && (a.WorkerIMDSHopLimit < 1 || a.WorkerIMDSHopLimit > 64) {
+		return fmt.Errorf("worker-imds-hop-limit %d is invalid: must be between 1 and 64", a.WorkerIMDSHopLimit)
+	}
+
+	if a.ARM64StemcellURLIsSet {
+		if _, _, err := config.ParseStemcellURL(a.ARM64StemcellURL); err != nil {
+			return fmt.Errorf("--arm64-stemcell-url %v", err)
+		}
+	}
+
+	if a.ARM64ReleaseURLIsSet {
+		name, _, err := config.ParseReleaseURL(a.ARM64ReleaseURL)
+		if err != nil {
+			return fmt.Errorf("--arm64-release-url %v", err)
+		}
+		// The ARM64 release is added alongside the Concourse release that the web and other workers run
+		if name == "concourse" {
+			return fmt.Errorf("--arm64-release-url %s is invalid: its release must be named something other than concourse, like concourse-arm64, so that it doesn't replace the release the web and amd64 workers run", a.ARM64ReleaseURL)
+		}
+	}
+
+	if a.ARM64ReleaseSHA1IsSet && !releaseSHA1.MatchString(a.ARM64ReleaseSHA1) {
+		return fmt.Errorf("--arm64-release-sha1 %s is invalid: must be a sha1, or a sha256 prefixed with sha256:", a.ARM64ReleaseSHA1)
+	}
+
+	if a.WindowsWorkerCountIsSet && a.WindowsWorkerCount < 0 {
+		return fmt.Errorf("windows-worker-count %d is invalid: must be 0 or more", a.WindowsWorkerCount)
+	}
+
+	if a.WindowsWorkerTypeIsSet {
+		if err := validateInstanceTypeName("windows-worker-type", a.WindowsWorkerType, a.IAAS); err != nil {
+			return err
+		}
+	}
+
+	if a.WindowsWorkerReleaseURLIsSet {
+		name, _, err := config.ParseReleaseURL(a.WindowsWorkerReleaseURL)
+		if err != nil {
+			return fmt.Errorf("--windows-worker-release-url %v", err)
+		}
+		if name == "concourse" {
+			return fmt.Errorf("--windows-worker-release-url %s is invalid: its release must be named something other than concourse, like concourse-windows, so that it doesn't replace the release the web and Linux workers run", a.WindowsWorkerReleaseURL)
+		}
+	}
+
+	if a.WindowsWorkerReleaseSHA1IsSet && !releaseSHA1.MatchString(a.WindowsWorkerReleaseSHA1) {
+		return fmt.Errorf("--windows-worker-release-sha1 %s is invalid: must be a sha1, or a sha256 prefixed with sha256:", a.WindowsWorkerReleaseSHA1)
+	}
+
+	if a.WindowsStemcellVersionIsSet && !stemcellVersion.MatchString(a.WindowsStemcellVersion) {
+		return fmt.Errorf("--windows-stemcell-version %s is invalid: must be a version of the stemcell on bosh.io, like 2019.71", a.WindowsStemcellVersion)
+	}
+
+	// An empty --worker-instance-type goes back to the type chosen by size
+	if a.WorkerInstanceType != "" {
+		if err := validateInstanceTypeName("worker-instance-type", a.WorkerInstanceType, a.IAAS); err != nil {
+			return err
+		}
+		if a.WorkerSizeIsSet || a.WorkerTypeIsSet {
+			return errors.New("worker-instance-type can't be used with worker-size or worker-type")
+		}
+	}
+
+	if a.WorkerDiskSizeGBIsSet && (a.WorkerDiskSizeGB < 50 || a.WorkerDiskSizeGB > 16000) {
+		return fmt.Errorf("worker-disk-size-gb %d is invalid: must be between 50 and 16000", a.WorkerDiskSizeGB)
+	}
+
+	if a.WorkerDiskTypeIsSet {
+		diskTypes := WorkerDiskTypes[strings.ToLower(a.IAAS)]
+		if !contains(diskTypes, a.WorkerDiskType) {
+			return fmt.Errorf("worker-disk-type %s is invalid: must be one of %s", a.WorkerDiskType, strings.Join(diskTypes, ", "))
+		}
+	}
+
+	if a.WorkerAutoscaleMinIsSet != a.WorkerAutoscaleMaxIsSet {
+		return errors.New("worker-autoscale-min and worker-autoscale-max must be set together")
+	}
+
+	// Setting both to 0 turns autoscaling off again
+	if a.WorkerAutoscaleMaxIsSet && (a.WorkerAutoscaleMin != 0 || a.WorkerAutoscaleMax != 0) {
+		if a.WorkerAutoscaleMin < 1 {
+			return errors.New("worker-autoscale-min must be at least 1")
+		}
+		if a.WorkerAutoscaleMax < a.WorkerAutoscaleMin {
+			return fmt.Errorf("worker-autoscale-max %d is invalid: must be at least worker-autoscale-min %d", a.WorkerAutoscaleMax, a.WorkerAutoscaleMin)
+		}
+	}
+
+	// An empty --worker-schedule removes the schedules
+	for _, schedule := range a.WorkerSchedules {
+		if schedule == "" {
+			continue
+		}
+		if _, err := config.ParseWorkerSchedule(schedule); err != nil {
+			return err
+		}
+	}
+
+	if a.WorkerScheduleTimezoneIsSet {
+		if _, err := time.LoadLocation(a.WorkerScheduleTimezone); err != nil {
+			return fmt.Errorf("worker-schedule-timezone %s is invalid: must be a timezone like Europe/London", a.WorkerScheduleTimezone)
+		}
+	}
+
+	// Empty durations go back to Concourse's defaults
+	if a.WorkerDrainTimeout != "" {
+		if d, err := time.ParseDuration(a.WorkerDrainTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("worker-drain-timeout %s is invalid: must be a positive duration, like 2h", a.WorkerDrainTimeout)
+		}
+	}
+
+	if a.WorkerRebalanceInterval != "" {
+		if d, err := time.ParseDuration(a.WorkerRebalanceInterval); err != nil || d <= 0 {
+			return fmt.Errorf("worker-rebalance-interval %s is invalid: must be a positive duration, like 4h", a.WorkerRebalanceInterval)
+		}
+	}
+
+	if a.WorkerRetireMaxInFlight < 0 {
+		return errors.New("worker-retire-max-in-flight must not be negative")
+	}
+
+	if a.WorkerSpotIsSet && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("worker-spot is only defined on AWS, use worker-preemptible on GCP")
+	}
+
+	if a.WorkerPreemptIsSet && strings.ToLower(a.IAAS) != "gcp" {
+		return errors.New("worker-preemptible is only defined on GCP, use worker-spot on AWS")
+	}
+
+	if a.WorkerSpotBidIsSet && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("worker-spot-bid-percentage is only defined on AWS")
+	}
+
+	if a.WorkerSpotBidIsSet && a.WorkerSpotBid < 1 {
+		return fmt.Errorf("worker-spot-bid-percentage %d is invalid: must be positive", a.WorkerSpotBid)
+	}
+
+	if a.WorkerSpotBidIsSet && a.SpotIsSet && !a.Spot {
+		return errors.New("worker-spot-bid-percentage requires spot workers")
+	}
+
+	if (a.SoleTenantNodeTypeIsSet || a.SoleTenantNodeCountIsSet) && strings.ToLower(a.IAAS) != "gcp" {
+		return errors.New("sole-tenant-node-type and sole-tenant-node-count are only defined on GCP, EC2 dedicated instances need no nodes")
+	}
+
+	if a.SoleTenantNodeCountIsSet && a.SoleTenantNodeCount < 1 {
+		return fmt.Errorf("sole-tenant-node-count %d is invalid: must be positive", a.SoleTenantNodeCount)
+	}
+
+	re := regexp.MustCompile("^m5$|^m5a$|^m4$")
+	if a.WorkerTypeIsSet && !re.MatchString(a.WorkerType) {
+		return fmt.Errorf("worker-type %s is invalid: must be one of m4, m5, or m5a", a.WorkerType)
+	}
+
+	for _, size := range WorkerSizes {
+		if size == a.WorkerSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown worker size: `%s`. Valid sizes are: %v", a.WorkerSize, WorkerSizes)
+}
+
+func (a Args) validateWebFields() error {
+	if a.NoMetricsIsSet && a.InfluxDbRetentionIsSet {
+		return fmt.Errorf("no-metrics is invalid when used with influxdb-retention-period")
+	}
+
+	if a.WebInstanceType != "" {
+		if err := validateInstanceTypeName("web-instance-type", a.WebInstanceType, a.IAAS); err != nil {
+			return err
+		}
+		if a.WebSizeIsSet {
+			return errors.New("web-instance-type can't be used with web-size")
+		}
+	}
+
+	for _, size := range WebSizes {
+		if size == a.WebSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown web node size: `%s`. Valid sizes are: %v", a.WebSize, WebSizes)
+}
+
+func (a Args) validatePersistentDiskFields() error {
+	for _, size := range PersistentDiskSizes {
+		if size == a.PersistentDiskSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown persistent disk size: `%s`. Valid sizes are: %v", a.PersistentDiskSize, PersistentDiskSizes)
+}
+
+func (a Args) validateDBFields() error {
+	for _, size := range AllowedDBSizes {
+		if size == a.DBSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown DB size: `%s`. Valid sizes are: %v", a.DBSize, AllowedDBSizes)
+}
+
+func (a Args) validateExternalDBFields() error {
+	if !a.ExternalDBURLIsSet {
+		if a.ExternalDBCACertIsSet {
+			return errors.New("--external-db-ca-cert requires --external-db-url to also be provided")
+		}
+		return nil
+	}
+	if _, err := db.ParseExternalURL(a.ExternalDBURL); err != nil {
+		return err
+	}
+	if a.ExternalDBCACert == "" {
+		return errors.New("--external-db-url requires --external-db-ca-cert, the CA certificate that the server's TLS certificate is signed by")
+	}
+	if decodedCert, _ := pem.Decode([]byte(a.ExternalDBCACert)); decodedCert == nil {
+		return errors.New("unable to decode value passed to --external-db-ca-cert. Provide a CA certificate in PEM format")
+	}
+	for _, dbFlag := range []struct {
+		name  string
+		isSet bool
+	}{
+		{"db-size", a.DBSizeIsSet},
+		{"db-engine", a.DBEngineIsSet},
+		{"db-version", a.DBVersionIsSet},
+		{"db-resize", a.DBResizeIsSet},
+		{"db-ha", a.DBHAIsSet},
+		{"db-multi-az", a.DBMultiAZIsSet},
+		{"db-read-replica", a.DBReadReplicaIsSet},
+		{"db-kms-key", a.DBKMSKeyIsSet},
+		{"db-iam-auth", a.DBIAMAuthIsSet},
+		{"db-storage", a.DBStorageIsSet},
+		{"db-max-storage", a.DBMaxStorageIsSet},
+		{"db-storage-type", a.DBStorageTypeIsSet},
+		{"db-iops", a.DBIOPSIsSet},
+		{"db-backup-retention", a.DBBackupRetentionIsSet},
+		{"db-backup-window", a.DBBackupWindowIsSet},
+		{"db-deletion-protection", a.DBDeletionProtectionIsSet},
+		{"db-param", a.DBParamsIsSet},
+		{"rds-disk-encryption", a.RDSDiskEncryptionIsSet},
+		{"existing-db-subnets", a.ExistingDBSubnetsIsSet},
+	} {
+		if dbFlag.isSet {
+			return fmt.Errorf("--%s has no effect with --external-db-url, as no database is created", dbFlag.name)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateDBEngineFields() error {
+	if a.DBEngineIsSet {
+		known := false
+		for _, engine := range AllowedDBEngines {
+			if engine == a.DBEngine {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown DB engine: `%s`. Valid engines are: %v", a.DBEngine, AllowedDBEngines)
+		}
+	}
+	if a.DBEngine == config.DB_ENGINE_AURORA_SERVERLESS {
+		if strings.ToLower(a.IAAS) != "aws" {
+			return errors.New("--db-engine aurora-serverless is only available on AWS")
+		}
+		if a.DBSizeIsSet {
+			return errors.New("--db-size sets the class of an RDS instance, Aurora Serverless scales between --db-min-acu and --db-max-acu instead")
+		}
+	}
+	if a.DBMinACUIsSet {
+		if err := validateACU("db-min-acu", a.DBMinACU); err != nil {
+			return err
+		}
+	}
+	if a.DBMaxACUIsSet {
+		if err := validateACU("db-max-acu", a.DBMaxACU); err != nil {
+			return err
+		}
+	}
+	if a.DBMinACUIsSet && a.DBMaxACUIsSet && a.DBMinACU > a.DBMaxACU {
+		return fmt.Errorf("--db-min-acu %g cannot be greater than --db-max-acu %g", a.DBMinACU, a.DBMaxACU)
+	}
+	if a.DBHA && strings.ToLower(a.IAAS) != "gcp" {
+		return errors.New("--db-ha is only available on GCP")
+	}
+	if a.DBMultiAZ && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--db-multi-az is only available on AWS, use --db-ha on GCP")
+	}
+	if a.DBMultiAZ && a.DBEngine == config.DB_ENGINE_AURORA_SERVERLESS {
+		return errors.New("--db-multi-az applies to an RDS instance, while Aurora Serverless already keeps its storage in several zones")
+	}
+	return nil
+}
+
+func (a Args) validateDBVersionFields() error {
+	if a.DBUpgradeIsSet && !a.DBVersionIsSet {
+		return errors.New("--db-upgrade requires --db-version, the major version to upgrade the database to")
+	}
+	if !a.DBVersionIsSet {
+		return nil
+	}
+	for _, version := range AllowedDBVersions {
+		if version == a.DBVersion {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown DB version: `%s`. Valid versions are: %v", a.DBVersion, AllowedDBVersions)
+}
+
+func (a Args) validateDBResizeFields() error {
+	if !a.DBResizeIsSet {
+		return nil
+	}
+	if !a.DBSizeIsSet {
+		return errors.New("--db-resize requires --db-size, the size to resize the database to")
+	}
+	known := false
+	for _, resize := range AllowedDBResizes {
+		if resize == a.DBResize {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown DB resize: `%s`. Valid resizes are: %v", a.DBResize, AllowedDBResizes)
+	}
+	if a.DBResize == DBResizeBlueGreen && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--db-resize blue-green uses an RDS Blue/Green deployment, which CloudSQL doesn't have, so it is only available on AWS")
+	}
+	return nil
+}
+
+func (a Args) dbStorageIsSet() bool {
+	return a.DBStorageIsSet || a.DBMaxStorageIsSet || a.DBStorageTypeIsSet || a.DBIOPSIsSet
+}
+
+// cloudKMSKeyPattern matches the resource name of a Cloud KMS key
+var cloudKMSKeyPattern = regexp.MustCompile(`^projects/[a-z0-9-]+/locations/[a-z0-9-]+/keyRings/[A-Za-z0-9_-]+/cryptoKeys/[A-Za-z0-9_-]+$`)
+
+func (a Args) validateDBKMSKeyFields() error {
+	if !a.DBKMSKeyIsSet {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) == "gcp" {
+		if !cloudKMSKeyPattern.MatchString(a.DBKMSKey) {
+			return fmt.Errorf("--db-kms-key %s is invalid: must be the resource name of a Cloud KMS key, like projects/my-project/locations/europe-west1/keyRings/my-ring/cryptoKeys/my-key", a.DBKMSKey)
+		}
+		return nil
+	}
+	if !kmsKeyIDPattern.MatchString(a.DBKMSKey) {
+		return fmt.Errorf("--db-kms-key %s is invalid: must be a KMS key ID or key ARN", a.DBKMSKey)
+	}
+	if a.RDSDiskEncryptionIsSet {
+		return errors.New("--db-kms-key and --rds-disk-encryption cannot be used together, as --rds-disk-encryption creates a KMS key of its own")
+	}
+	return nil
+}
+
+func (a Args) validateDBIAMAuthFields() error {
+	if a.DBIAMAuthIsSet && a.DBIAMAuth && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--db-iam-auth is only available on AWS")
+	}
+	return nil
+}
+
+func (a Args) validateDBStorageFields() error {
+	if !a.dbStorageIsSet() {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--db-storage, --db-max-storage, --db-storage-type and --db-iops are only available on AWS, as CloudSQL storage grows automatically")
+	}
+	if a.DBEngine == config.DB_ENGINE_AURORA_SERVERLESS {
+		return errors.New("--db-storage, --db-max-storage, --db-storage-type and --db-iops don't apply to Aurora Serverless, as its storage grows automatically")
+	}
+	if a.DBStorageIsSet && (a.DBStorage < MinDBStorage || a.DBStorage > MaxDBStorage) {
+		return fmt.Errorf("--db-storage %d is invalid: must be between %d and %d GB", a.DBStorage, MinDBStorage, MaxDBStorage)
+	}
+	// A --db-max-storage of 0 turns storage autoscaling off again
+	if a.DBMaxStorageIsSet && a.DBMaxStorage != 0 && (a.DBMaxStorage < MinDBStorage || a.DBMaxStorage > MaxDBStorage) {
+		return fmt.Errorf("--db-max-storage %d is invalid: must be between %d and %d GB, or 0 to turn storage autoscaling off", a.DBMaxStorage, MinDBStorage, MaxDBStorage)
+	}
+	if a.DBStorageTypeIsSet {
+		known := false
+		for _, storageType := range AllowedDBStorageTypes {
+			if storageType == a.DBStorageType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown DB storage type: `%s`. Valid types are: %v", a.DBStorageType, AllowedDBStorageTypes)
+		}
+	}
+	if a.DBIOPSIsSet && (a.DBIOPS < 1000 || a.DBIOPS > 256000) {
+		return fmt.Errorf("--db-iops %d is invalid: must be between 1000 and 256000", a.DBIOPS)
+	}
+	return nil
+}
+
+func (a Args) validateDBBackupFields() error {
+	if a.DBBackupRetentionIsSet {
+		// RDS keeps automated backups for up to 35 days, and CloudSQL keeps up to 365 daily backups
+		maxRetention := 35
+		if strings.ToLower(a.IAAS) == "gcp" {
+			maxRetention = 365
+		}
+		if a.DBBackupRetention < 1 || a.DBBackupRetention > maxRetention {
+			return fmt.Errorf("--db-backup-retention %d is invalid: must be between 1 and %d days on %s", a.DBBackupRetention, maxRetention, strings.ToUpper(a.IAAS))
+		}
+	}
+	if a.DBBackupWindowIsSet {
+		return validateBackupWindow(a.DBBackupWindow)
+	}
+	return nil
+}
+
+var dbParamPattern = regexp.MustCompile(`^([a-z][a-z0-9_.]*)=(.+)$`)
+
+// validateDBParams checks each Postgres parameter is in the format name=value, and that its value can be
+// written into the terraform template as it is
+func validateDBParams(params []string) error {
+	seen := map[string]bool{}
+	for _, param := range params {
+		match := dbParamPattern.FindStringSubmatch(param)
+		if match == nil {
+			return fmt.Errorf("--db-param `%v` is not a Postgres parameter in the format `name=value`", param)
+		}
+		if strings.ContainsAny(match[2], "\"\\\n") || strings.Contains(match[2], "${") || strings.Contains(match[2], "%{") {
+			return fmt.Errorf("--db-param %s cannot contain double quotes, backslashes, newlines, ${ or %%{", match[1])
+		}
+		if seen[match[1]] {
+			return fmt.Errorf("--db-param %s is given more than once", match[1])
+		}
+		seen[match[1]] = true
+	}
+	return nil
+}
+
+var backupWindowRegex = regexp.MustCompile(`^([01]\d|2[0-3]):([0-5]\d)-([01]\d|2[0-3]):([0-5]\d)$`)
+
+// validateBackupWindow checks a window of hh:mm-hh:mm in UTC is at least the 30 minutes that RDS requires,
+// allowing for windows that span midnight
+func validateBackupWindow(window string) error {
+	match := backupWindowRegex.FindStringSubmatch(window)
+	if match == nil {
+		return fmt.Errorf("--db-backup-window %s is invalid: must be a window of hh:mm-hh:mm in UTC, such as 03:00-04:00", window)
+	}
+	minutes := make([]int, 4)
+	for i := range minutes {
+		minutes[i], _ = strconv.Atoi(match[i+1])
+	}
+	start := minutes[0]*60 + minutes[1]
+	end := minutes[2]*60 + minutes[3]
+	if end < start {
+		end += 24 * 60
+	}
+	if end-start < 30 {
+		return fmt.Errorf("--db-backup-window %s is invalid: must be at least 30 minutes long", window)
+	}
+	return nil
+}
+
+// validateACU checks a number of Aurora capacity units is one that Aurora Serverless v2 can scale to
+func validateACU(flag string, acu float64) error {
+	if acu < 0.5 || acu > MaxACU || math.Mod(acu, 0.5) != 0 {
+		return fmt.Errorf("--%s %g is invalid: must be between 0.5 and %d in steps of 0.5", flag, acu, MaxACU)
+	}
+	return nil
+}
+
+func (a Args) validateGithubFields() error {
+	if a.GithubAuthClientID != "" && a.GithubAuthClientSecret == "" {
+		return errors.New("--github-auth-client-id requires --github-auth-client-secret to also be provided")
+	}
+	if a.GithubAuthClientID == "" && a.GithubAuthClientSecret != "" {
+		return errors.New("--github-auth-client-secret requires --github-auth-client-id to also be provided")
+	}
+	if a.GithubAuthHost != "" && (!a.GithubAuthIsSet || a.GithubAuthCaCert == "") {
+		return errors.New("--github-auth-host requires --github-auth-ca-cert, --github-auth-client-id, --github-auth-client-secret to also be provided")
+	}
+	if a.GithubAuthCaCert != "" && (!a.GithubAuthIsSet || a.GithubAuthHost == "") {
+		return errors.New("--github-auth-ca-cert requires --github-auth-host, --github-auth-client-id, --github-auth-client-secret to also be provided")
+	}
+	if a.GithubEnterpriseAuthIsSet && !a.certParseable() {
+		return errors.New("unable to decode value passed to --github-auth-ca-cert. Provide a CA certificate in PEM format")
+	}
+	if a.GithubEnterpriseAuthIsSet && !govalidator.IsDNSName(a.GithubAuthHost) {
+		return errors.New("--github-auth-host must be a valid DNS address (omitting protocol)")
+	}
+	return nil
+}
+
+func (a Args) validateOIDCFields() error {
+	if a.OIDCIssuer == "" && a.OIDCClientID == "" && a.OIDCClientSecret == "" {
+		if a.OIDCGroupsClaimIsSet || a.OIDCUsernameClaimIsSet {
+			return errors.New("--oidc-groups-claim and --oidc-username-claim require --oidc-issuer, --oidc-client-id and --oidc-client-secret to also be provided")
+		}
+		return nil
+	}
+	if a.OIDCIssuer == "" || a.OIDCClientID == "" || a.OIDCClientSecret == "" {
+		return errors.New("--oidc-issuer, --oidc-client-id and --oidc-client-secret must all be provided to use OIDC auth")
+	}
+	issuer, err := url.Parse(a.OIDCIssuer)
+	if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+		return fmt.Errorf("--oidc-issuer %s is invalid: must be an https URL", a.OIDCIssuer)
+	}
+	return nil
+}
+
+func (a Args) validateOAuthFields() error {
+	if a.OAuthAuthURL == "" && a.OAuthTokenURL == "" && a.OAuthUserinfoURL == "" && a.OAuthClientID == "" && a.OAuthClientSecret == "" {
+		if a.OAuthScopesIsSet || a.OAuthGroupsKeyIsSet {
+			return errors.New("--oauth-scopes and --oauth-groups-key require --oauth-auth-url, --oauth-token-url, --oauth-userinfo-url, --oauth-client-id and --oauth-client-secret to also be provided")
+		}
+		return nil
+	}
+	if a.OAuthAuthURL == "" || a.OAuthTokenURL == "" || a.OAuthUserinfoURL == "" || a.OAuthClientID == "" || a.OAuthClientSecret == "" {
+		return errors.New("--oauth-auth-url, --oauth-token-url, --oauth-userinfo-url, --oauth-client-id and --oauth-client-secret must all be provided to use OAuth auth")
+	}
+	for flag, value := range map[string]string{
+		"--oauth-auth-url":     a.OAuthAuthURL,
+		"--oauth-token-url":    a.OAuthTokenURL,
+		"--oauth-userinfo-url": a.OAuthUserinfoURL,
+	} {
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("%s %s is invalid: must be an https URL", flag, value)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateSAMLFields() error {
+	if a.SAMLMetadataURL == "" {
+		if a.SAMLCACertIsSet || a.SAMLUsernameAttrIsSet || a.SAMLEmailAttrIsSet || a.SAMLGroupsAttrIsSet {
+			return errors.New("--saml-ca-cert, --saml-username-attr, --saml-email-attr and --saml-groups-attr require --saml-metadata-url to also be provided")
+		}
+		return nil
+	}
+	metadataURL, err := url.Parse(a.SAMLMetadataURL)
+	if err != nil || metadataURL.Scheme != "https" || metadataURL.Host == "" {
+		return fmt.Errorf("--saml-metadata-url %s is invalid: must be an https URL", a.SAMLMetadataURL)
+	}
+	if a.SAMLCACert != "" {
+		if decodedCert, _ := pem.Decode([]byte(a.SAMLCACert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --saml-ca-cert. Provide a CA certificate in PEM format")
+		}
+	}
+	return nil
+}
+
+func (a Args) validateLDAPFields() error {
+	required := a.LDAPHost != "" || a.LDAPBindDN != "" || a.LDAPBindPassword != "" || a.LDAPUserSearchBaseDN != ""
+	optional := a.LDAPCACertIsSet || a.LDAPUserSearchFilterIsSet || a.LDAPUserSearchUsernameIsSet || a.LDAPGroupSearchBaseDNIsSet || a.LDAPGroupSearchFilterIsSet || a.LDAPGroupSearchNameAttrIsSet
+	if !required {
+		if optional {
+			return errors.New("LDAP search and certificate flags require --ldap-host, --ldap-bind-dn, --ldap-bind-password and --ldap-user-search-base-dn to also be provided")
+		}
+		return nil
+	}
+	if a.LDAPHost == "" || a.LDAPBindDN == "" || a.LDAPBindPassword == "" || a.LDAPUserSearchBaseDN == "" {
+		return errors.New("--ldap-host, --ldap-bind-dn, --ldap-bind-password and --ldap-user-search-base-dn must all be provided to use LDAP auth")
+	}
+	if _, _, err := net.SplitHostPort(a.LDAPHost); err != nil {
+		return fmt.Errorf("--ldap-host %s is invalid: must be in the format host:port", a.LDAPHost)
+	}
+	if a.LDAPCACert != "" {
+		if decodedCert, _ := pem.Decode([]byte(a.LDAPCACert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --ldap-ca-cert. Provide a CA certificate in PEM format")
+		}
+	}
+	if (a.LDAPGroupSearchFilterIsSet || a.LDAPGroupSearchNameAttrIsSet) && a.LDAPGroupSearchBaseDN == "" {
+		return errors.New("--ldap-group-search-filter and --ldap-group-search-name-attr require --ldap-group-search-base-dn to also be provided")
+	}
+	return nil
+}
+
+func (a Args) validateCFAuthFields() error {
+	if a.CFAuthAPIURL == "" && a.CFAuthClientID == "" && a.CFAuthClientSecret == "" {
+		if a.CFAuthCACertIsSet || a.MainCFOrgsIsSet || a.MainCFSpacesIsSet {
+			return errors.New("--cf-auth-ca-cert, --main-team-cf-orgs and --main-team-cf-spaces require --cf-auth-api-url, --cf-auth-client-id and --cf-auth-client-secret to also be provided")
+		}
+		return nil
+	}
+	if a.CFAuthAPIURL == "" || a.CFAuthClientID == "" || a.CFAuthClientSecret == "" {
+		return errors.New("--cf-auth-api-url, --cf-auth-client-id and --cf-auth-client-secret must all be provided to use CF auth")
+	}
+	apiURL, err := url.Parse(a.CFAuthAPIURL)
+	if err != nil || apiURL.Scheme != "https" || apiURL.Host == "" {
+		return fmt.Errorf("--cf-auth-api-url %s is invalid: must be an https URL", a.CFAuthAPIURL)
+	}
+	if a.CFAuthCACert != "" {
+		if decodedCert, _ := pem.Decode([]byte(a.CFAuthCACert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --cf-auth-ca-cert. Provide a CA certificate in PEM format")
+		}
+	}
+	if a.MainCFSpacesIsSet {
+		for _, space := range strings.Split(a.MainCFSpaces, ",") {
+			parts := strings.Split(strings.TrimSpace(space), ":")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("Invalid space %q provided to --main-team-cf-spaces, must be in the format ORG:SPACE", space)
+			}
+		}
+	}
+	return nil
+}
+
+func (a Args) validateVaultFields() error {
+	if a.VaultURL == "" {
+		if a.VaultCACertIsSet || a.VaultAuthBackendIsSet || a.VaultAuthParamsIsSet || a.VaultClientTokenIsSet || a.VaultPathPrefixIsSet {
+			return errors.New("--vault-ca-cert, --vault-auth-backend, --vault-auth-param, --vault-client-token and --vault-path-prefix require --vault-url to also be provided")
+		}
+		return nil
+	}
+	vaultURL, err := url.Parse(a.VaultURL)
+	if err != nil || vaultURL.Scheme != "https" || vaultURL.Host == "" {
+		return fmt.Errorf("--vault-url %s is invalid: must be an https URL", a.VaultURL)
+	}
+	if a.VaultCACert != "" {
+		if decodedCert, _ := pem.Decode([]byte(a.VaultCACert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --vault-ca-cert. Provide a CA certificate in PEM format")
+		}
+	}
+	if (a.VaultAuthBackend == "") == (a.VaultClientToken == "") {
+		return errors.New("exactly one of --vault-auth-backend and --vault-client-token must be provided to use Vault")
+	}
+	if a.VaultClientToken != "" && a.VaultAuthParamsIsSet {
+		return errors.New("--vault-auth-param requires --vault-auth-backend, not --vault-client-token")
+	}
+	for _, param := range a.VaultAuthParams {
+		if kv := strings.SplitN(param, "=", 2); len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("`%v` is not in the format `key=value`", param)
+		}
+	}
+	if !strings.HasPrefix(a.VaultPathPrefix, "/") {
+		return fmt.Errorf("--vault-path-prefix %s is invalid: must begin with /", a.VaultPathPrefix)
+	}
+	return nil
+}
+
+func (a Args) validateCredentialManagerFields() error {
+	if a.CredentialManager != "secretsmanager" && (a.SecretsManagerPipelinePathIsSet || a.SecretsManagerTeamPathIsSet) {
+		return errors.New("--secretsmanager-pipeline-path-template and --secretsmanager-team-path-template require --credential-manager secretsmanager")
+	}
+	if a.CredentialManager != "ssm" && (a.SSMPipelinePathIsSet || a.SSMTeamPathIsSet || a.SSMKMSKeyIDIsSet) {
+		return errors.New("--ssm-pipeline-path-template, --ssm-team-path-template and --ssm-kms-key-id require --credential-manager ssm")
+	}
+	if a.CredentialManager == "" || a.CredentialManager == "credhub" {
+		return nil
+	}
+	known := false
+	for _, manager := range CredentialManagers {
+		known = known || manager == a.CredentialManager
+	}
+	if !known {
+		return fmt.Errorf("unknown credential manager: `%s`. Valid credential managers are: %v", a.CredentialManager, CredentialManagers)
+	}
+	if a.VaultURLIsSet {
+		return fmt.Errorf("--vault-url cannot be used with --credential-manager %s", a.CredentialManager)
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return fmt.Errorf("credential-manager %s is only defined on AWS", a.CredentialManager)
+	}
+	if a.CredentialManager == "ssm" {
+		if a.SSMKMSKeyID != "" && !kmsKeyIDPattern.MatchString(a.SSMKMSKeyID) {
+			return fmt.Errorf("--ssm-kms-key-id %s is invalid: must be a KMS key ID or key ARN", a.SSMKMSKeyID)
+		}
+		if err := validateSecretTemplate("--ssm-pipeline-path-template", a.SSMPipelinePath, "{{.Team}}", "{{.Pipeline}}"); err != nil {
+			return err
+		}
+		return validateSecretTemplate("--ssm-team-path-template", a.SSMTeamPath, "{{.Team}}")
+	}
+	if err := validateSecretTemplate("--secretsmanager-pipeline-path-template", a.SecretsManagerPipelinePath, "{{.Team}}", "{{.Pipeline}}"); err != nil {
+		return err
+	}
+	return validateSecretTemplate("--secretsmanager-team-path-template", a.SecretsManagerTeamPath, "{{.Team}}")
+}
+
+func (a Args) validateContainerPlacementFields() error {
+	seen := map[string]bool{}
+	for _, strategy := range a.ContainerPlacementStrategies {
+		known := false
+		for _, s := range ContainerPlacementStrategies {
+			known = known || s == strategy
+		}
+		if !known {
+			return fmt.Errorf("unknown container placement strategy: `%s`. Valid strategies are: %v", strategy, ContainerPlacementStrategies)
+		}
+		if seen[strategy] {
+			return fmt.Errorf("container placement strategy %s is given more than once", strategy)
+		}
+		seen[strategy] = true
+	}
+	if seen["random"] && len(a.ContainerPlacementStrategies) > 1 {
+		return errors.New("container placement strategy random cannot be chained with other strategies")
+	}
+
+	limits := []struct {
+		flag  string
+		value int
+		isSet bool
+	}{
+		{"max-active-tasks-per-worker", a.MaxActiveTasksPerWorker, a.MaxActiveTasksPerWorkerIsSet},
+		{"max-active-containers-per-worker", a.MaxActiveContainersPerWorker, a.MaxActiveContainersPerWorkerIsSet},
+		{"max-active-volumes-per-worker", a.MaxActiveVolumesPerWorker, a.MaxActiveVolumesPerWorkerIsSet},
+	}
+	for _, limit := range limits {
+		if limit.isSet && limit.value < 0 {
+			return fmt.Errorf("%s %d is invalid: must not be negative", limit.flag, limit.value)
+		}
+	}
+	return nil
+}
+
+// taskMemoryLimitPattern matches the memory sizes Concourse accepts for a task's container limit, such as 512MB or 4GB
+var taskMemoryLimitPattern = regexp.MustCompile(`^[0-9]+(KB|MB|GB|TB)$`)
+
+func (a Args) validateTaskLimitFields() error {
+	if a.DefaultTaskCPULimit < 0 {
+		return fmt.Errorf("--default-task-cpu-limit %d is invalid: must not be negative", a.DefaultTaskCPULimit)
+	}
+	if a.DefaultTaskMemoryLimit != "" && !taskMemoryLimitPattern.MatchString(a.DefaultTaskMemoryLimit) {
+		return fmt.Errorf("--default-task-memory-limit %s is invalid: must be a size such as 512MB or 4GB", a.DefaultTaskMemoryLimit)
+	}
+
+	durations := []struct {
+		flag  string
+		value string
+	}{
+		{"intercept-idle-timeout", a.InterceptIdleTimeout},
+		{"failed-container-grace-period", a.FailedGracePeriod},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(duration.value); err != nil || d <= 0 {
+			return fmt.Errorf("--%s %s is invalid: must be a positive duration such as 30m or 2h", duration.flag, duration.value)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateUpgradeBatchFields() error {
+	if a.UpgradeBatchSize < 0 {
+		return errors.New("--upgrade-batch-size must not be negative")
+	}
+	if a.SoakMinutes < 0 {
+		return errors.New("--soak-minutes must not be negative")
+	}
+	if a.SoakMaxErrorRateIncreaseIsSet && (a.SoakMaxErrorRateIncrease < 1 || a.SoakMaxErrorRateIncrease > 100) {
+		return errors.New("--soak-max-error-rate-increase must be between 1 and 100")
+	}
+	if (a.SoakMinutesIsSet || a.SoakMaxErrorRateIncreaseIsSet) && a.UpgradeBatchSizeIsSet && a.UpgradeBatchSize == 0 {
+		return errors.New("--soak-minutes and --soak-max-error-rate-increase only apply with --upgrade-batch-size")
+	}
+	return nil
+}
+
+// StreamingCompressions contains the valid values for --streaming-compression flag
+var StreamingCompressions = []string{"gzip", "zstd", "raw"}
+
+// SyslogTransports contains the valid values for --syslog-transport flag
+var SyslogTransports = []string{"tcp", "udp", "tls"}
+
+func (a Args) validateSyslogFields() error {
+	if a.SyslogAddress != "" {
+		host, port, err := net.SplitHostPort(a.SyslogAddress)
+		if _, portErr := strconv.Atoi(port); err != nil || host == "" || portErr != nil {
+			return fmt.Errorf("--syslog-address %s is invalid: must be in the format host:port", a.SyslogAddress)
+		}
+	}
+	if a.SyslogTransportIsSet {
+		known := false
+		for _, transport := range SyslogTransports {
+			known = known || transport == a.SyslogTransport
+		}
+		if !known {
+			return fmt.Errorf("unknown syslog transport: `%s`. Valid transports are: %v", a.SyslogTransport, SyslogTransports)
+		}
+	}
+	if a.SyslogCACert != "" {
+		if a.SyslogTransportIsSet && a.SyslogTransport != "tls" {
+			return errors.New("--syslog-ca-cert requires --syslog-transport tls")
+		}
+		if decodedCert, _ := pem.Decode([]byte(a.SyslogCACert)); decodedCert == nil {
+			return errors.New("unable to decode value passed to --syslog-ca-cert. Provide a CA certificate in PEM format")
+		}
+	}
+	return nil
+}
+
+var newRelicAccountIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+func (a Args) validateNewRelicFields() error {
+	if a.NewRelicAccountID != "" && !newRelicAccountIDPattern.MatchString(a.NewRelicAccountID) {
+		return fmt.Errorf("--newrelic-account-id %s is invalid: must be the numeric ID of a New Relic account", a.NewRelicAccountID)
+	}
+	if a.NewRelicInsightsURL != "" {
+		u, err := url.Parse(a.NewRelicInsightsURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("--newrelic-insights-api-url %s is invalid: must be an https URL", a.NewRelicInsightsURL)
+		}
+	}
+	if a.NewRelicBatchSizeIsSet && a.NewRelicBatchSize < 1 {
+		return errors.New("--newrelic-batch-size must be at least 1")
+	}
+	if a.NewRelicBatchDuration != "" {
+		if d, err := time.ParseDuration(a.NewRelicBatchDuration); err != nil || d <= 0 {
+			return fmt.Errorf("--newrelic-batch-duration %s is invalid: must be a positive duration, like 60s", a.NewRelicBatchDuration)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateInfluxDbFields() error {
+	if a.InfluxDbURL != "" {
+		u, err := url.Parse(a.InfluxDbURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--influxdb-url %s is invalid: must be an http or https URL", a.InfluxDbURL)
+		}
+	}
+	if a.InfluxDbBatchSizeIsSet && a.InfluxDbBatchSize < 1 {
+		return errors.New("--influxdb-batch-size must be at least 1")
+	}
+	return nil
+}
+
+var (
+	opaPolicyPathPattern     = regexp.MustCompile(`^[A-Za-z0-9_]+(/[A-Za-z0-9_]+)*$`)
+	policyCheckActionPattern = regexp.MustCompile(`^[A-Z][A-Za-z]+$`)
+)
+
+func (a Args) validatePolicyCheckFields() error {
+	if a.OPAURL != "" {
+		opaURL, err := url.Parse(a.OPAURL)
+		if err != nil || (opaURL.Scheme != "http" && opaURL.Scheme != "https") || opaURL.Host == "" || opaURL.RawQuery != "" || opaURL.Fragment != "" {
+			return fmt.Errorf("--opa-url %s is invalid: must be an http or https URL without a query or fragment", a.OPAURL)
+		}
+	}
+	if a.OPAPolicyPath != "" && !opaPolicyPathPattern.MatchString(a.OPAPolicyPath) {
+		return fmt.Errorf("--opa-policy-path %s is invalid: must be a package path such as concourse/decision", a.OPAPolicyPath)
+	}
+	seen := map[string]bool{}
+	for _, action := range a.PolicyCheckActions {
+		if !policyCheckActionPattern.MatchString(action) {
+			return fmt.Errorf("--policy-check-action %s is invalid: must be a Concourse action name such as SaveConfig", action)
+		}
+		if seen[action] {
+			return fmt.Errorf("--policy-check-action %s is given more than once", action)
+		}
+		seen[action] = true
+	}
+	return nil
+}
+
+// Domains splits the comma separated --domain into the primary domain Concourse is served from,
+// followed by any additional domains that also resolve to it
+func (a Args) Domains() []string {
+	domains := []string{}
+	for _, domain := range strings.Split(a.Domain, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// AuditLogOverrides parses the category=true|false values of --audit-log
+func (a Args) AuditLogOverrides() (map[string]bool, error) {
+	overrides := map[string]bool{}
+	for _, override := range a.AuditLogs {
+		parts := strings.SplitN(override, "=", 2)
+		known := false
+		for _, category := range AuditLogCategories {
+			known = known || category == parts[0]
+		}
+		if !known {
+			return nil, fmt.Errorf("--audit-log %s is invalid: category must be one of %v", override, AuditLogCategories)
+		}
+		enabled := true
+		if len(parts) == 2 {
+			var err error
+			if enabled, err = strconv.ParseBool(parts[1]); err != nil {
+				return nil, fmt.Errorf("--audit-log %s is invalid: must be in the format `category=true|false`", override)
+			}
+		}
+		if _, seen := overrides[parts[0]]; seen {
+			return nil, fmt.Errorf("--audit-log %s is given more than once", parts[0])
+		}
+		overrides[parts[0]] = enabled
+	}
+	return overrides, nil
+}
+
+func (a Args) validateAuditLogFields() error {
+	_, err := a.AuditLogOverrides()
+	return err
+}
+
+func (a Args) validateDomainFields() error {
+	domains := a.Domains()
+	if len(domains) < 2 {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, domain := range domains {
+		if govalidator.IsIP(domain) {
+			return fmt.Errorf("--domain %s is invalid: only domain names can be given alongside other domains", domain)
+		}
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		if seen[domain] {
+			return fmt.Errorf("--domain %s is given more than once", domain)
+		}
+		seen[domain] = true
+	}
+	return nil
+}
+
+func (a Args) validateDNSFields() error {
+	if a.DNSIsSet {
+		known := false
+		for _, provider := range dns.Providers {
+			known = known || provider == a.DNS
+		}
+		if !known {
+			return fmt.Errorf("unknown DNS provider: `%s`. Valid providers are: %v", a.DNS, dns.Providers)
+		}
+	}
+	if a.DNSZone != "" && !govalidator.IsDNSName(strings.TrimSuffix(a.DNSZone, ".")) {
+		return fmt.Errorf("--dns-zone %s is invalid: must be a domain name", a.DNSZone)
+	}
+	return nil
+}
+
+var concourseEnvPattern = regexp.MustCompile(`^(CONCOURSE_[A-Z0-9_]+)=(.*)$`)
+
+func validateConcourseEnv(flag string, settings []string) error {
+	seen := map[string]bool{}
+	for _, setting := range settings {
+		match := concourseEnvPattern.FindStringSubmatch(setting)
+		if match == nil {
+			return fmt.Errorf("%s `%v` is not a Concourse setting in the format `CONCOURSE_NAME=value`", flag, setting)
+		}
+		if seen[match[1]] {
+			return fmt.Errorf("%s %s is given more than once", flag, match[1])
+		}
+		seen[match[1]] = true
+	}
+	return nil
+}
+
+func (a Args) validateManagedPrometheusFields() error {
+	if !a.ManagedPrometheus {
+		if a.ManagedPrometheusWorkspaceURLIsSet {
+			return errors.New("--managed-prometheus-workspace-url requires --managed-prometheus to also be provided")
+		}
+		return nil
+	}
+	if a.NoMetrics {
+		return errors.New("--managed-prometheus sends metrics from the colocated metrics stack, so cannot be used with --no-metrics")
+	}
+	switch strings.ToLower(a.IAAS) {
+	case "aws":
+		if !AMPRemoteWriteURLPattern.MatchString(a.ManagedPrometheusWorkspaceURL) {
+			return fmt.Errorf("--managed-prometheus-workspace-url %q is invalid: must be the remote write URL of an AWS Managed Prometheus workspace, like https://aps-workspaces.eu-west-1.amazonaws.com/workspaces/ws-abc123/api/v1/remote_write", a.ManagedPrometheusWorkspaceURL)
+		}
+	case "gcp":
+		if a.ManagedPrometheusWorkspaceURLIsSet {
+			return errors.New("managed-prometheus-workspace-url is only defined on AWS")
+		}
+	}
+	return nil
+}
+
+// validateSecretTemplate checks that a secret path template names the secret and the given
+// fields, and starts with a fixed prefix that the web node's access can be scoped to
+func validateSecretTemplate(flag, template string, fields ...string) error {
+	for _, field := range append(fields, "{{.Secret}}") {
+		if !strings.Contains(template, field) {
+			return fmt.Errorf("%s %s is invalid: must contain %s", flag, template, field)
+		}
+	}
+	if !strings.HasPrefix(template, "/") || strings.HasPrefix(template, "/{{") {
+		return fmt.Errorf("%s %s is invalid: must begin with a fixed path such as /concourse/", flag, template)
+	}
+	return nil
+}
+
+func (a Args) certParseable() bool {
+	decodedCert, _ := pem.Decode([]byte(a.GithubAuthCaCert))
+	return decodedCert != nil
+}
+
+func (a Args) validateNetworkRanges() error {
+	if a.PublicCIDR != "" || a.PrivateCIDR != "" {
+		if a.PublicCIDR == "" || a.PrivateCIDR == "" {
+			return errors.New("both --public-cidr and --private-cidr are required when either is provided")
+		}
+	}
+
+	if a.RDSCIDRsIsSet {
+		if a.RDS1CIDRIsSet || a.RDS2CIDRIsSet {
+			return errors.New("--rds-cidrs replaces --rds-subnet-range1 and --rds-subnet-range2, so they can't be given together")
+		}
+		if len(strings.Split(a.RDSCIDRs, ",")) != 2 {
+			return fmt.Errorf("--rds-cidrs %q must be two CIDRs separated by a comma, one for each RDS subnet", a.RDSCIDRs)
+		}
+	}
+
+	return nil
+}
+
+// WithRDSCIDRs sets RDS1CIDR and RDS2CIDR from RDSCIDRs, which is validated to be a pair, when it is given
+func (a Args) WithRDSCIDRs() Args {
+	if !a.RDSCIDRsIsSet {
+		return a
+	}
+	cidrs := strings.Split(a.RDSCIDRs, ",")
+	a.RDS1CIDR, a.RDS1CIDRIsSet = strings.TrimSpace(cidrs[0]), true
+	a.RDS2CIDR, a.RDS2CIDRIsSet = strings.TrimSpace(cidrs[1]), true
+	return a
+}
+
+// ExistingNetworkFlagsSet is true when the deployment goes into a network that is managed elsewhere
+func (a Args) ExistingNetworkFlagsSet() bool {
+	return a.ExistingVPCIDIsSet || a.ExistingNetworkIsSet
+}
+
+// ExistingNetworkName is the VPC ID on AWS, or the network name on GCP, of the existing network
+func (a Args) ExistingNetworkName() string {
+	if a.ExistingVPCIDIsSet {
+		return a.ExistingVPCID
+	}
+	return a.ExistingNetwork
+}
+
+func (a Args) validateBastionFields() error {
+	if a.BastionAllowIPsIsSet && !a.Bastion {
+		return errors.New("--bastion-allow-ips requires --bastion to also be provided")
+	}
+	if a.Bastion && a.Private && a.ExistingVPCIDIsSet {
+		return errors.New("--bastion isn't available to private deployments with --existing-vpc-id, as the bastion needs a subnet that routes to an internet gateway and the existing public subnet routes through a NAT gateway")
+	}
+	return nil
+}
+
+func (a Args) validateVPCEndpointsFields() error {
+	if !a.VPCEndpoints {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--vpc-endpoints is only available on AWS, as VMs on GCP reach Google's APIs through Private Google Access")
+	}
+	if a.ExistingVPCIDIsSet {
+		return errors.New("--vpc-endpoints isn't available with --existing-vpc-id, as the endpoints of an existing VPC are managed along with it")
+	}
+	return nil
+}
+
+func (a Args) validateNATTopologyFields() error {
+	if !a.NATTopologyIsSet {
+		return nil
+	}
+	known := false
+	for _, topology := range AllowedNATTopologies {
+		if topology == a.NATTopology {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown NAT topology: `%s`. Valid topologies are: %v", a.NATTopology, AllowedNATTopologies)
+	}
+	if a.NATTopology == config.NAT_SINGLE {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--nat-topology is only available on AWS, as Cloud NAT on GCP already spans the region")
+	}
+	if a.ExistingVPCIDIsSet {
+		return errors.New("--nat-topology isn't available with --existing-vpc-id, as the NAT gateway of an existing VPC is managed along with it")
+	}
+	return nil
+}
+
+func (a Args) validateIPv6Fields() error {
+	if a.AllowIPv6sIsSet && !a.IPv6 {
+		return errors.New("--allow-ipv6-ips requires --ipv6 to also be provided")
+	}
+	if !a.IPv6 {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--ipv6 is only available on AWS")
+	}
+	if a.ExistingVPCIDIsSet {
+		return errors.New("--ipv6 isn't available with --existing-vpc-id, as the addressing of an existing VPC is managed along with it")
+	}
+	return nil
+}
+
+func (a Args) validatePeeringFields() error {
+	isAWS := strings.ToLower(a.IAAS) == "aws"
+	if a.PeerVPCIDsIsSet && !isAWS {
+		return errors.New("--peer-vpc-id is only available on AWS, use --peer-network on GCP")
+	}
+	if a.PeerNetworksIsSet && isAWS {
+		return errors.New("--peer-network is only available on GCP, use --peer-vpc-id on AWS")
+	}
+	if a.PeerVPCIDsIsSet && a.ExistingVPCIDIsSet {
+		return errors.New("--peer-vpc-id isn't available with --existing-vpc-id, as the peerings and route tables of an existing VPC are managed along with it")
+	}
+	for _, id := range a.PeerVPCIDs {
+		if id != "" && !vpcIDPattern.MatchString(id) {
+			return fmt.Errorf("--peer-vpc-id %q is not a VPC ID, which is vpc- followed by hexadecimal digits", id)
+		}
+	}
+	for _, network := range a.PeerNetworks {
+		if network != "" && (!strings.Contains(network, "projects/") || !strings.Contains(network, "/global/networks/")) {
+			return fmt.Errorf("--peer-network %q is not a network of the form projects/PROJECT/global/networks/NETWORK", network)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateTransitGatewayFields() error {
+	optionsSet := a.TransitGatewayRoutesIsSet || a.TransitGatewayRouteTableIDIsSet || a.NoTransitGatewayPropagationIsSet
+	if !a.TransitGatewayIDIsSet && !optionsSet {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--transit-gateway-id is only available on AWS, use --ncc-hub on GCP")
+	}
+	if a.ExistingVPCIDIsSet {
+		return errors.New("--transit-gateway-id isn't available with --existing-vpc-id, as the attachments and route tables of an existing VPC are managed along with it")
+	}
+	if a.TransitGatewayIDIsSet && a.TransitGatewayID != "" && !strings.HasPrefix(a.TransitGatewayID, "tgw-") {
+		return fmt.Errorf("--transit-gateway-id %q is not a transit gateway ID, which starts with tgw-", a.TransitGatewayID)
+	}
+	if a.TransitGatewayRouteTableIDIsSet && a.TransitGatewayRouteTableID != "" && !strings.HasPrefix(a.TransitGatewayRouteTableID, "tgw-rtb-") {
+		return fmt.Errorf("--transit-gateway-route-table-id %q is not a transit gateway route table ID, which starts with tgw-rtb-", a.TransitGatewayRouteTableID)
+	}
+	for _, route := range a.TransitGatewayRoutes {
+		if route == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(route); err != nil {
+			return fmt.Errorf("--transit-gateway-route %q is not a valid CIDR", route)
+		}
+	}
+	return nil
+}
+
+// nccHubPattern matches a Network Connectivity Center hub, which is always global
+var nccHubPattern = regexp.MustCompile(`^projects/[a-z0-9-]+/locations/global/hubs/[a-z0-9-]+$`)
+
+func (a Args) validateNCCFields() error {
+	if !a.NCCHubIsSet && !a.NCCExcludeExportRangesIsSet {
+		return nil
+	}
+	if strings.ToLower(a.IAAS) != "gcp" {
+		return errors.New("--ncc-hub is only available on GCP, use --transit-gateway-id on AWS")
+	}
+	if a.NCCHub != "" && !nccHubPattern.MatchString(a.NCCHub) {
+		return fmt.Errorf("--ncc-hub %q is not a hub of the form projects/PROJECT/locations/global/hubs/HUB", a.NCCHub)
+	}
+	for _, cidr := range a.NCCExcludeExportRanges {
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("--ncc-exclude-export-range %q is not a valid CIDR", cidr)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateWebAddressFields() error {
+	isAWS := strings.ToLower(a.IAAS) == "aws"
+	if a.WebEIPIsSet && !isAWS {
+		return errors.New("--web-eip is only available on AWS, use --web-address-name on GCP")
+	}
+	if a.WebAddressNameIsSet && isAWS {
+		return errors.New("--web-address-name is only available on GCP, use --web-eip on AWS")
+	}
+	if a.WebEIP != "" {
+		if ip := net.ParseIP(a.WebEIP); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("--web-eip %q is not an IPv4 address", a.WebEIP)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateExistingNetworkFields() error {
+	subnetsSet := a.ExistingPublicSubnetIsSet || a.ExistingPrivateSubnetIsSet || a.ExistingDBSubnetsIsSet
+	if !a.ExistingNetworkFlagsSet() {
+		if subnetsSet {
+			return errors.New("--existing-public-subnet, --existing-private-subnet and --existing-db-subnets require --existing-vpc-id on AWS or --existing-network on GCP")
+		}
+		return nil
+	}
+
+	isAWS := strings.ToLower(a.IAAS) == "aws"
+	if a.ExistingVPCIDIsSet && !isAWS {
+		return errors.New("--existing-vpc-id is only available on AWS, use --existing-network on GCP")
+	}
+	if a.ExistingNetworkIsSet && isAWS {
+		return errors.New("--existing-network is only available on GCP, use --existing-vpc-id on AWS")
+	}
+	if a.ExistingPublicSubnet == "" || a.ExistingPrivateSubnet == "" {
+		return errors.New("both --existing-public-subnet and --existing-private-subnet are required to deploy into an existing network")
+	}
+	if a.ExistingDBSubnetsIsSet && !isAWS {
+		return errors.New("--existing-db-subnets is only available on AWS, as CloudSQL is reached through private service access rather than a subnet")
+	}
+	if isAWS && !a.ExternalDBURLIsSet && len(a.ExistingDBSubnets) != 2 {
+		return errors.New("--existing-vpc-id requires --existing-db-subnets, two subnets in different zones for the RDS instance")
+	}
+	if a.ExistingDBSubnetsIsSet && len(a.ExistingDBSubnets) != 2 {
+		return errors.New("--existing-db-subnets takes two subnets in different zones")
+	}
+
+	for _, rangeFlag := range []struct {
+		name  string
+		isSet bool
+	}{
+		{"network-cidr", a.NetworkCIDRIsSet},
+		{"public-cidr", a.PublicCIDRIsSet},
+		{"private-cidr", a.PrivateCIDRIsSet},
+		{"rds-subnet-range1", a.RDS1CIDRIsSet},
+		{"rds-subnet-range2", a.RDS2CIDRIsSet},
+		{"rds-cidrs", a.RDSCIDRsIsSet},
+	} {
+		if rangeFlag.isSet {
+			return fmt.Errorf("--%s has no effect when deploying into an existing network, as its ranges are those of the existing subnets", rangeFlag.name)
+		}
+	}
+	if isAWS && a.WorkerZonesIsSet {
+		return errors.New("--worker-zone creates subnets in the deployment's VPC, so it isn't available with --existing-vpc-id")
+	}
+	return nil
+}
+
+func (a Args) validateTags() error {
+	pattern := regexp.MustCompile(`\w+=\w+`)
+	for _, tag := range a.Tags {
+		if !pattern.MatchString(tag) {
+			return fmt.Errorf("`%v` is not in the format `key=value`", tag)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateWorkerKernelFields() error {
+	// An empty value resets the setting
+	if a.WorkerCgroupVersionIsSet && a.WorkerCgroupVersion != "" && a.WorkerCgroupVersion != "1" && a.WorkerCgroupVersion != "2" {
+		return fmt.Errorf("worker-cgroup-version %s is invalid: must be 1 or 2", a.WorkerCgroupVersion)
+	}
+
+	pattern := regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_\-]+)+=\S.*$`)
+	for _, sysctl := range a.WorkerSysctls {
+		if sysctl != "" && !pattern.MatchString(sysctl) {
+			return fmt.Errorf("`%v` is not a kernel parameter in the format `key.name=value`", sysctl)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateWorkerContainerdFields() error {
+	for _, server := range a.WorkerDNSServers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("worker-dns-server %s is invalid: must be an IP address", server)
+		}
+	}
+	if len(a.WorkerDNSServers) > 0 && a.WorkerDNSProxy {
+		return errors.New("--worker-dns-server and --worker-dns-proxy cannot be used together, the DNS proxy resolves names with the worker's own DNS servers")
+	}
+
+	if a.WorkerNetworkPool != "" {
+		_, pool, err := net.ParseCIDR(a.WorkerNetworkPool)
+		if err != nil || pool.IP.To4() == nil {
+			return fmt.Errorf("worker-network-pool %s is invalid: must be an IPv4 CIDR block", a.WorkerNetworkPool)
+		}
+		if ones, _ := pool.Mask.Size(); ones > 24 {
+			return fmt.Errorf("worker-network-pool %s is too small: must be a /24 or larger", a.WorkerNetworkPool)
+		}
+	}
+
+	if a.WorkerMaxContainers < 0 {
+		return fmt.Errorf("worker-max-containers %d is invalid: must not be negative", a.WorkerMaxContainers)
+	}
+	return nil
+}
+
+func (a Args) validateMainAuth() error {
+	if err := a.validateMainAuthFlags(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// This is a partial validation check based on the format expected by Concourse:
+// https://concourse-ci.org/github-auth.html#configuring-main-team-authorization
+// as well as some of the validation rules imposed by GitHub:
+// https://github.com/dead-claudia/github-limits
+// We don't check all rules (such as reserved names) as we're just trying to save
+// users from typos rather than from creating invalid entities in GitHub
+func (a Args) validateMainAuthFlags() error {
+	userAndOrgRe := regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`)
+	teamRe := regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+	if a.MainGithubUsersIsSet {
+		for _, user := range strings.Split(a.MainGithubUsers, ",") {
+			if userAndOrgRe.FindString(strings.TrimSpace(user)) == "" {
+				return fmt.Errorf("Invalid user %q provided to --main-team-github-users", user)
+			}
+		}
+	}
+
+	if a.MainGithubOrgsIsSet {
+		for _, org := range strings.Split(a.MainGithubOrgs, ",") {
+			if userAndOrgRe.FindString(strings.TrimSpace(org)) == "" {
+				return fmt.Errorf("Invalid org %q provided to --main-team-github-orgs", org)
+			}
+		}
+	}
+
+	if a.MainGithubTeamsIsSet {
+		for _, orgTeam := range strings.Split(a.MainGithubTeams, ",") {
+			parts := strings.Split(orgTeam, ":")
+			org := strings.TrimSpace(parts[0])
+			if len(parts) != 2 {
+				return fmt.Errorf("Invalid team %q does not contain org", org)
+			}
+			team := strings.TrimSpace(parts[1])
+			if userAndOrgRe.FindString(org) == "" {
+				return fmt.Errorf("Invalid org %q provided for team %q in --main-team-github-teams", org, team)
+			}
+			if teamRe.FindString(team) == "" {
+				return fmt.Errorf("Invalid team %q provided to --main-team-github-teams", orgTeam)
+			}
 		}
 	}
 	return nil
@@ -439,8 +2879,20 @@ type ContextWrapper struct {
 	c *cli.Context
 }
 
-// IsSet tells you if a user provided a flag
+// IsSet tells you if a user provided a flag, by any of its names
 func (t *ContextWrapper) IsSet(name string) bool {
+	for _, flag := range t.c.Command.Flags {
+		names := strings.Split(flag.GetName(), ",")
+		if strings.TrimSpace(names[0]) != name {
+			continue
+		}
+		for _, alias := range names {
+			if t.c.IsSet(strings.TrimSpace(alias)) {
+				return true
+			}
+		}
+		return false
+	}
 	return t.c.IsSet(name)
 }
 
@@ -0,0 +1,179 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+var workerPoolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// workerPoolTeamPattern matches the team names that control-tower accepts in a teams file
+var workerPoolTeamPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParseWorkerPools reads and validates the YAML list of pools given by --worker-pools-file
+func ParseWorkerPools(contents []byte, iaas string) ([]config.WorkerPool, error) {
+	var pools []config.WorkerPool
+	if err := yaml.UnmarshalStrict(contents, &pools); err != nil {
+		return nil, fmt.Errorf("worker pools are invalid: [%v]", err)
+	}
+
+	names := map[string]bool{}
+	for i := range pools {
+		pool := &pools[i]
+		if !workerPoolNamePattern.MatchString(pool.Name) {
+			return nil, fmt.Errorf("worker pool name `%s` is invalid: must start with a letter and contain only lowercase letters, digits and hyphens", pool.Name)
+		}
+		if names[pool.Name] {
+			return nil, fmt.Errorf("worker pool %s is defined more than once", pool.Name)
+		}
+		names[pool.Name] = true
+
+		if pool.Count < 1 {
+			return nil, fmt.Errorf("worker pool %s is invalid: minimum number of workers is 1", pool.Name)
+		}
+		if !isWorkerSize(pool.Size) {
+			return nil, fmt.Errorf("worker pool %s is invalid: unknown worker size: `%s`. Valid sizes are: %v", pool.Name, pool.Size, WorkerSizes)
+		}
+
+		var err error
+		switch {
+		case pool.GPU != "":
+			err = prepareGPUWorkerPool(pool, iaas)
+		case pool.Arch == "" || pool.Arch == "amd64":
+			err = validateAMD64WorkerPool(*pool, iaas)
+		case pool.Arch == config.ARM64:
+			err = prepareARM64WorkerPool(pool, iaas)
+		default:
+			err = fmt.Errorf("arch %s must be amd64 or arm64", pool.Arch)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("worker pool %s is invalid: %v", pool.Name, err)
+		}
+
+		if pool.BaggageclaimDriver != "" && !contains(config.BaggageclaimDrivers, pool.BaggageclaimDriver) {
+			return nil, fmt.Errorf("worker pool %s is invalid: baggageclaim_driver %s must be one of %v", pool.Name, pool.BaggageclaimDriver, config.BaggageclaimDrivers)
+		}
+		if pool.Team != "" && !workerPoolTeamPattern.MatchString(pool.Team) {
+			return nil, fmt.Errorf("worker pool %s is invalid: team name `%s` must be lowercase letters, numbers, - and _", pool.Name, pool.Team)
+		}
+	}
+	return pools, nil
+}
+
+func validateAMD64WorkerPool(pool config.WorkerPool, iaas string) error {
+	if pool.Type == "" {
+		return nil
+	}
+	if strings.ToLower(iaas) != "aws" {
+		return errors.New("type is only defined on AWS")
+	}
+	if pool.Type != "m4" && pool.Type != "m5" && pool.Type != "m5a" {
+		return fmt.Errorf("type %s must be one of m4, m5, or m5a", pool.Type)
+	}
+	if pool.Type == "m4" && (pool.Size == "12xlarge" || pool.Size == "24xlarge") {
+		return fmt.Errorf("m4 instances don't come in size %s", pool.Size)
+	}
+	return nil
+}
+
+// prepareARM64WorkerPool validates an ARM64 pool, defaulting its type to Graviton m6g on AWS and tagging
+// it arm64 so that pipelines can target it
+func prepareARM64WorkerPool(pool *config.WorkerPool, iaas string) error {
+	if pool.Size == "24xlarge" {
+		return errors.New("ARM64 instances don't come in size 24xlarge")
+	}
+	if strings.ToLower(iaas) == "aws" {
+		if pool.Type == "" {
+			pool.Type = "m6g"
+		}
+		if pool.Type != "m6g" && pool.Type != "m7g" {
+			return fmt.Errorf("type %s must be m6g or m7g for arch arm64", pool.Type)
+		}
+	} else if pool.Type != "" {
+		return errors.New("type is only defined on AWS")
+	}
+
+	addTag(pool, config.ARM64)
+	return nil
+}
+
+// awsGPUSizes are the worker sizes that each GPU instance family comes in
+var awsGPUSizes = map[string][]string{
+	"g4dn": {"xlarge", "2xlarge", "4xlarge", "12xlarge"},
+	"g5":   {"xlarge", "2xlarge", "4xlarge", "12xlarge", "24xlarge"},
+}
+
+// gcpGPUSizes are the worker sizes whose machine types can have each GPU attached, GCP limiting
+// the number of vCPUs per GPU
+var gcpGPUSizes = map[string][]string{
+	"p100": {"medium", "large", "xlarge", "2xlarge", "4xlarge"},
+	"t4":   {"medium", "large", "xlarge", "2xlarge", "4xlarge"},
+	"v100": {"medium", "large", "xlarge", "2xlarge"},
+}
+
+// prepareGPUWorkerPool validates a pool with a GPU, setting its type to the GPU's instance family on AWS
+// and tagging it gpu so that pipelines can target it
+func prepareGPUWorkerPool(pool *config.WorkerPool, iaas string) error {
+	if pool.Arch != "" && pool.Arch != "amd64" {
+		return fmt.Errorf("gpu %s is only available with arch amd64", pool.GPU)
+	}
+	if pool.Type != "" {
+		return errors.New("type can't be set on a pool with a gpu, it is chosen by the gpu")
+	}
+
+	var sizes []string
+	if strings.ToLower(iaas) == "aws" {
+		family, ok := config.AWSGPUFamilies[pool.GPU]
+		if !ok {
+			return fmt.Errorf("gpu %s is not available on AWS, must be one of %v", pool.GPU, gpuNames(config.AWSGPUFamilies))
+		}
+		pool.Type = family
+		sizes = awsGPUSizes[family]
+	} else {
+		if _, ok := config.GCPGPUAccelerators[pool.GPU]; !ok {
+			return fmt.Errorf("gpu %s is not available on GCP, must be one of %v", pool.GPU, gpuNames(config.GCPGPUAccelerators))
+		}
+		sizes = gcpGPUSizes[pool.GPU]
+	}
+	if !contains(sizes, pool.Size) {
+		return fmt.Errorf("gpu %s doesn't come in size %s, must be one of %v", pool.GPU, pool.Size, sizes)
+	}
+
+	addTag(pool, config.GPUTag)
+	return nil
+}
+
+func gpuNames(gpus map[string]string) []string {
+	var names []string
+	for name := range gpus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func addTag(pool *config.WorkerPool, tag string) {
+	if !contains(pool.Tags, tag) {
+		pool.Tags = append(pool.Tags, tag)
+	}
+}
+
+func isWorkerSize(size string) bool {
+	return contains(WorkerSizes, size)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package deploy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func TestParseWorkerPools(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		iaas     string
+		want     []config.WorkerPool
+		wantErr  string
+	}{
+		{
+			name: "pools are parsed",
+			contents: `
+- name: docker-heavy
+  size: 2xlarge
+  count: 2
+  type: m5
+  tags: [docker]
+- name: deploy
+  size: medium
+  count: 1
+  tags: [deploy]
+  team: production
+`,
+			iaas: "AWS",
+			want: []config.WorkerPool{
+				{Name: "docker-heavy", Size: "2xlarge", Count: 2, Type: "m5", Tags: []string{"docker"}},
+				{Name: "deploy", Size: "medium", Count: 1, Tags: []string{"deploy"}, Team: "production"},
+			},
+		},
+		{
+			name:     "unknown fields are rejected",
+			contents: "- name: deploy\n  size: medium\n  count: 1\n  tag: [deploy]\n",
+			iaas:     "AWS",
+			wantErr:  "worker pools are invalid: [yaml: unmarshal errors:\n  line 4: field tag not found in type config.WorkerPool]",
+		},
+		{
+			name:     "names must be unique",
+			contents: "- {name: deploy, size: medium, count: 1}\n- {name: deploy, size: large, count: 1}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool deploy is defined more than once",
+		},
+		{
+			name:     "names must be usable as instance group names",
+			contents: "- {name: Deploy_Pool, size: medium, count: 1}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool name `Deploy_Pool` is invalid: must start with a letter and contain only lowercase letters, digits and hyphens",
+		},
+		{
+			name:     "count must be positive",
+			contents: "- {name: deploy, size: medium, count: 0}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool deploy is invalid: minimum number of workers is 1",
+		},
+		{
+			name:     "size must be known",
+			contents: "- {name: deploy, size: huge, count: 1}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool deploy is invalid: unknown worker size: `huge`. Valid sizes are: [medium large xlarge 2xlarge 4xlarge 12xlarge 24xlarge]",
+		},
+		{
+			name:     "type is only defined on AWS",
+			contents: "- {name: deploy, size: large, count: 1, type: m5}\n",
+			iaas:     "GCP",
+			wantErr:  "worker pool deploy is invalid: type is only defined on AWS",
+		},
+		{
+			name:     "m4 pools can't be 12xlarge",
+			contents: "- {name: deploy, size: 12xlarge, count: 1, type: m4}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool deploy is invalid: m4 instances don't come in size 12xlarge",
+		},
+		{
+			name:     "arm64 pools default to Graviton and are tagged arm64",
+			contents: "- {name: arm, size: large, count: 2, arch: arm64, tags: [docker]}\n",
+			iaas:     "AWS",
+			want: []config.WorkerPool{
+				{Name: "arm", Size: "large", Count: 2, Type: "m6g", Arch: "arm64", Tags: []string{"docker", "arm64"}},
+			},
+		},
+		{
+			name:     "arm64 pools on GCP have no type",
+			contents: "- {name: arm, size: xlarge, count: 1, arch: arm64, tags: [arm64]}\n",
+			iaas:     "GCP",
+			want: []config.WorkerPool{
+				{Name: "arm", Size: "xlarge", Count: 1, Arch: "arm64", Tags: []string{"arm64"}},
+			},
+		},
+		{
+			name:     "arm64 pools need an ARM64 type",
+			contents: "- {name: arm, size: large, count: 1, arch: arm64, type: m5}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool arm is invalid: type m5 must be m6g or m7g for arch arm64",
+		},
+		{
+			name:     "gpu pools get the GPU's instance family on AWS and are tagged gpu",
+			contents: "- {name: ml, size: 2xlarge, count: 1, gpu: t4}\n",
+			iaas:     "AWS",
+			want: []config.WorkerPool{
+				{Name: "ml", Size: "2xlarge", Count: 1, Type: "g4dn", GPU: "t4", Tags: []string{"gpu"}},
+			},
+		},
+		{
+			name:     "gpu pools on GCP keep their size's machine type",
+			contents: "- {name: ml, size: xlarge, count: 2, gpu: v100, tags: [gpu, cuda]}\n",
+			iaas:     "GCP",
+			want: []config.WorkerPool{
+				{Name: "ml", Size: "xlarge", Count: 2, GPU: "v100", Tags: []string{"gpu", "cuda"}},
+			},
+		},
+		{
+			name:     "gpu must be available on the IAAS",
+			contents: "- {name: ml, size: xlarge, count: 1, gpu: v100}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool ml is invalid: gpu v100 is not available on AWS, must be one of [a10g t4]",
+		},
+		{
+			name:     "gpu instance types come in fewer sizes",
+			contents: "- {name: ml, size: medium, count: 1, gpu: t4}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool ml is invalid: gpu t4 doesn't come in size medium, must be one of [xlarge 2xlarge 4xlarge 12xlarge]",
+		},
+		{
+			name:     "gpu pools can't set a type",
+			contents: "- {name: ml, size: xlarge, count: 1, gpu: t4, type: m5}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool ml is invalid: type can't be set on a pool with a gpu, it is chosen by the gpu",
+		},
+		{
+			name:     "baggageclaim driver must be known",
+			contents: "- {name: docker, size: large, count: 1, baggageclaim_driver: zfs}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool docker is invalid: baggageclaim_driver zfs must be one of [overlay btrfs naive]",
+		},
+		{
+			name:     "arch must be known",
+			contents: "- {name: arm, size: large, count: 1, arch: riscv64}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool arm is invalid: arch riscv64 must be amd64 or arm64",
+		},
+		{
+			name:     "team must be a valid team name",
+			contents: "- {name: untrusted, size: large, count: 1, team: Untrusted}\n",
+			iaas:     "AWS",
+			wantErr:  "worker pool untrusted is invalid: team name `Untrusted` must be lowercase letters, numbers, - and _",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWorkerPools([]byte(tt.contents), tt.iaas)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ParseWorkerPools() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWorkerPools() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWorkerPools() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
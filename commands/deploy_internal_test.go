@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/EngineerBetter/control-tower/iaas"
-	"github.com/EngineerBetter/control-tower/testsupport"
 
 	"github.com/EngineerBetter/control-tower/commands/deploy"
 )
@@ -26,6 +25,12 @@ func Test_regionFromZone(t *testing.T) {
 			want:  "eu-west-1",
 			want1: fmt.Sprintf("No region provided, please note that your zone will be paired with a matching region.\nThis region: %s is used for deployment.\n", "eu-west-1"),
 		},
+		{
+			name:  "a valid GCP zone returns a valid region",
+			args:  args{zone: "europe-west2-c"},
+			want:  "europe-west2",
+			want1: fmt.Sprintf("No region provided, please note that your zone will be paired with a matching region.\nThis region: %s is used for deployment.\n", "europe-west2"),
+		},
 		{
 			name:  "an invalid zone returns empty region",
 			args:  args{zone: "wrong-zone"},
@@ -115,6 +120,28 @@ func Test_setZoneAndRegion(t *testing.T) {
 			providerRegion: "eu-west-1",
 			expectedRegion: "us-east-1",
 		},
+		{
+			name: "region should be inferred from a GCP zone",
+			args: deploy.Args{
+				IAAS:      "GCP",
+				Zone:      "us-east1-c",
+				ZoneIsSet: true,
+			},
+			providerRegion: "europe-west1",
+			expectedRegion: "us-east1",
+		},
+		{
+			name: "zone must belong to the region",
+			args: deploy.Args{
+				IAAS:        "GCP",
+				Region:      "europe-west1",
+				RegionIsSet: true,
+				Zone:        "us-east1-c",
+				ZoneIsSet:   true,
+			},
+			wantErr:        true,
+			expectedRegion: "europe-west1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -198,300 +225,3 @@ func Test_validateNameLength(t *testing.T) {
 		})
 	}
 }
-
-func Test_validateCidrRanges(t *testing.T) {
-	testsupport.SetupFakeCredsForGCPProvider(t)
-	gcpProvider, err := iaas.New(iaas.GCP, "europe-west1")
-	if err != nil {
-		t.Fatalf("Error creating GCP provider in test: [%v]", err)
-	}
-	awsProvider, err := iaas.New(iaas.AWS, "eu-west-1")
-	if err != nil {
-		t.Fatalf("Error creating AWS provider in test: [%v]", err)
-	}
-
-	type args struct {
-		provider    iaas.Provider
-		networkCidr string
-		publicCidr  string
-		privateCidr string
-		rds1Cidr    string
-		rds2Cidr    string
-	}
-	tests := []struct {
-		name          string
-		args          args
-		wantErr       bool
-		desiredErrMsg string
-	}{
-		{
-			name: "does not err if no flags provided",
-			args: args{
-				provider: awsProvider,
-			},
-			wantErr: false,
-		}, //
-		{
-			name: "errs if public range is provided and private is not",
-			args: args{
-				provider:    gcpProvider,
-				networkCidr: "10.0.0.0/16",
-				publicCidr:  "10.0.0.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - both public-subnet-range and private-subnet-range must be provided",
-		},
-		{
-			name: "errs if private range is provided and public is not",
-			args: args{
-				provider:    gcpProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - both public-subnet-range and private-subnet-range must be provided",
-		},
-		{
-			name: "errs if provider is AWS and default range is not provided",
-			args: args{
-				provider:    awsProvider,
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - vpc-network-range must be provided when using AWS",
-		},
-		{
-			name: "errs if provider is AWS and rds1 range is not provided",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds2Cidr:    "10.0.4.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - both rds1-subnet-range and rds2-subnet-range must be provided",
-		},
-		{
-			name: "errs if provider is AWS and rds2 range is not provided",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "10.0.2.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - both rds1-subnet-range and rds2-subnet-range must be provided",
-		},
-		{
-			name: "errs if default range isn't a CIDR",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "aNetwork",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - vpc-network-range is not a valid CIDR",
-		},
-		{
-			name: "errs if public range isn't a CIDR",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "aPublicNetwork",
-				rds1Cidr:    "10.0.2.0/16",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - public-subnet-range is not a valid CIDR",
-		},
-		{
-			name: "errs if private range isn't a CIDR",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "aPrivateNetwork",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "10.0.2.0/16",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - private-subnet-range is not a valid CIDR",
-		},
-		{
-			name: "errs if rds1 range isn't a CIDR",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "aPrivateNetwork",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds1-subnet-range is not a valid CIDR",
-		},
-		{
-			name: "errs if rds2 range isn't a CIDR",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "10.0.2.0/16",
-				rds2Cidr:    "aPrivateNetwork",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds2-subnet-range is not a valid CIDR",
-		},
-		{
-			name: "errs if provider is AWS and public range is not in default range",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "172.0.0.0/24",
-				rds1Cidr:    "10.0.2.0/16",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - public-subnet-range must be within vpc-network-range",
-		},
-		{
-			name: "errs if provider is AWS and private range is not in default range",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "172.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "10.0.2.0/16",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - private-subnet-range must be within vpc-network-range",
-		},
-		{
-			name: "errs if provider is AWS and rds1 range is not in default range",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "192.168.2.0/16",
-				rds2Cidr:    "10.0.3.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds1-subnet-range must be within vpc-network-range",
-		},
-		{
-			name: "errs if provider is AWS and rds2 range is not in default range",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.1.0/24",
-				rds1Cidr:    "10.0.3.0/16",
-				rds2Cidr:    "192.168.2.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds2-subnet-range must be within vpc-network-range",
-		},
-		{
-			name: "errs if public range overlaps with private range",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.0.0/24",
-				rds1Cidr:    "10.0.3.0/16",
-				rds2Cidr:    "10.0.4.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - public-subnet-range must not overlap private-network-range",
-		},
-		{
-			name: "errs if network cidr range is not big enough (16 usable IPs)",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/28",
-				privateCidr: "10.0.0.0/32",
-				publicCidr:  "10.0.0.0/24",
-				rds1Cidr:    "10.0.3.0/16",
-				rds2Cidr:    "10.0.4.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - vpc-network-range is not big enough, at least /26 needed.",
-		},
-		{
-			name: "errs if private cidr range is not big enough (8 usable IPs)",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/32",
-				publicCidr:  "10.0.0.0/24",
-				rds1Cidr:    "10.0.3.0/16",
-				rds2Cidr:    "10.0.4.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - private-subnet-range is not big enough, at least /28 needed.",
-		},
-		{
-			name: "errs if public cidr range is not big enough  (8 usable IPs)",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.0.0/32",
-				rds1Cidr:    "10.0.3.0/16",
-				rds2Cidr:    "10.0.4.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - public-subnet-range is not big enough, at least /28 needed.",
-		},
-		{
-			name: "errs if rds1 cidr range is not big enough  (8 usable IPs)",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.0.0/24",
-				rds1Cidr:    "10.0.3.0/32",
-				rds2Cidr:    "10.0.4.0/16",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds1-subnet-range is not big enough, at least /29 needed.",
-		},
-		{
-			name: "errs if rds2 cidr range is not big enough  (8 usable IPs)",
-			args: args{
-				provider:    awsProvider,
-				networkCidr: "10.0.0.0/16",
-				privateCidr: "10.0.0.0/24",
-				publicCidr:  "10.0.0.0/24",
-				rds1Cidr:    "10.0.3.0/24",
-				rds2Cidr:    "10.0.4.0/32",
-			},
-			wantErr:       true,
-			desiredErrMsg: "error validating CIDR ranges - rds2-subnet-range is not big enough, at least /29 needed.",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateCidrRanges(tt.args.provider, tt.args.networkCidr, tt.args.publicCidr, tt.args.privateCidr, tt.args.rds1Cidr, tt.args.rds2Cidr)
-
-			if (err == nil && tt.wantErr) || (err != nil && !tt.wantErr) {
-				t.Errorf("validateCidrRanges() error = %v, wantErr %v", err, tt.wantErr)
-			}
-
-			if err != nil && tt.wantErr {
-				if err.Error() != tt.desiredErrMsg {
-					t.Errorf("validateCidrRanges() error message = [%v], desiredErrMsg [%v]", err.Error(), tt.desiredErrMsg)
-				}
-			}
-		})
-	}
-}
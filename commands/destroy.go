@@ -11,6 +11,7 @@ import (
 	"github.com/EngineerBetter/control-tower/concourse"
 	"github.com/EngineerBetter/control-tower/config"
 	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
 	"github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
@@ -42,12 +43,19 @@ var destroyFlags = []cli.Flag{
 		EnvVar:      "NAMESPACE",
 		Destination: &initialDestroyArgs.Namespace,
 	},
+	cli.IntFlag{
+		Name:        "lock-stale-minutes",
+		Usage:       "(optional) Minutes a deployment lock can go without a heartbeat before it is taken over, once no BOSH tasks are running",
+		EnvVar:      "LOCK_STALE_MINUTES",
+		Value:       15,
+		Destination: &initialDestroyArgs.LockStaleMinutes,
+	},
 }
 
 func destroyAction(c *cli.Context, destroyArgs destroy.Args, provider iaas.Provider) error {
 	name := c.Args().Get(0)
 	if name == "" {
-		return errors.New("Usage is `control-tower destroy <name>`")
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower destroy <name>`"))
 	}
 
 	if !NonInteractiveModeEnabled() {
@@ -68,7 +76,7 @@ func destroyAction(c *cli.Context, destroyArgs destroy.Args, provider iaas.Provi
 	if err != nil {
 		return err
 	}
-	return client.Destroy()
+	return client.Destroy(destroyArgs)
 }
 
 func validateDestroyArgs(c *cli.Context, destroyArgs destroy.Args) (destroy.Args, error) {
@@ -100,11 +108,16 @@ func buildDestroyClient(name, version string, destroyArgs destroy.Args, provider
 		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
 	}
 
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	client := concourse.NewClient(
 		provider,
 		terraformClient,
 		tfInputVarsFactory,
-		bosh.New,
+		bosh.NewWithCommandOptions(boshCommandOptions),
 		fly.New,
 		certs.Generate,
 		config.New(provider, name, destroyArgs.Namespace),
@@ -133,11 +146,11 @@ var destroyCmd = cli.Command{
 	Action: func(c *cli.Context) error {
 		destroyArgs, err := validateDestroyArgs(c, initialDestroyArgs)
 		if err != nil {
-			return fmt.Errorf("Error validating args on destroy: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on destroy: [%v]", err))
 		}
 		iaasName, err := iaas.Validate(destroyArgs.IAAS)
 		if err != nil {
-			return fmt.Errorf("Error mapping to supported IAASes on destroy: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on destroy: [%v]", err))
 		}
 		provider, err := iaas.New(iaasName, destroyArgs.Region)
 		if err != nil {
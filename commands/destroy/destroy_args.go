@@ -14,6 +14,9 @@ type Args struct {
 	Namespace      string
 	NamespaceIsSet bool
 	IAASIsSet      bool
+	// LockStaleMinutes is how long a deployment lock can go without a heartbeat before it is taken over
+	LockStaleMinutes      int
+	LockStaleMinutesIsSet bool
 }
 
 //MarkSetFlags is marking which destroy Args have been set
@@ -27,6 +30,8 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.NamespaceIsSet = true
 			case "iaas":
 				a.IAASIsSet = true
+			case "lock-stale-minutes":
+				a.LockStaleMinutesIsSet = true
 			default:
 				return fmt.Errorf("flag %q is not supported by deployment flags", f)
 			}
@@ -39,6 +44,9 @@ func (a *Args) Validate() error {
 	if !a.IAASIsSet {
 		return fmt.Errorf("--iaas flag not set")
 	}
+	if a.LockStaleMinutesIsSet && a.LockStaleMinutes < 1 {
+		return fmt.Errorf("--lock-stale-minutes must be at least 1")
+	}
 	return nil
 }
 
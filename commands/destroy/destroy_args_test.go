@@ -37,6 +37,17 @@ func TestDestroyArgs_Validate(t *testing.T) {
 			wantErr:     true,
 			expectedErr: "--iaas flag not set",
 		},
+		{
+			name: "Lock stale minutes below 1",
+			modification: func() Args {
+				args := defaultFields
+				args.LockStaleMinutes = 0
+				args.LockStaleMinutesIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--lock-stale-minutes must be at least 1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/commands/drift"
+	"github.com/EngineerBetter/control-tower/concourse"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+)
+
+var initialDriftArgs drift.Args
+
+var driftFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialDriftArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialDriftArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialDriftArgs.Namespace,
+	},
+	cli.BoolFlag{
+		Name:        "revert",
+		Usage:       "(optional) Revert changes made outside of control-tower instead of only reporting them",
+		Destination: &initialDriftArgs.Revert,
+	},
+}
+
+func driftAction(c *cli.Context, driftArgs drift.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower concourse-drift <name>`"))
+	}
+
+	version := c.App.Version
+
+	client, err := buildDriftClient(name, version, driftArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.Drift(driftArgs)
+}
+
+func validateDriftArgs(c *cli.Context, driftArgs drift.Args) (drift.Args, error) {
+	err := driftArgs.MarkSetFlags(c)
+	if err != nil {
+		return driftArgs, fmt.Errorf("failed to mark set Drift flags: [%v]", err)
+	}
+
+	if err = driftArgs.Validate(); err != nil {
+		return driftArgs, fmt.Errorf("failed to validate Drift flags: [%v]", err)
+	}
+
+	return driftArgs, nil
+}
+
+func buildDriftClient(name, version string, driftArgs drift.Args, provider iaas.Provider) (*concourse.Client, error) {
+	versionFile, _ := provider.Choose(iaas.Choice{
+		AWS: resource.AWSVersionFile,
+		GCP: resource.GCPVersionFile,
+	}).([]byte)
+
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(versionFile))
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.NewWithCommandOptions(boshCommandOptions),
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, driftArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+		versionFile,
+		credhub.NewClient,
+	)
+
+	return client, nil
+}
+
+var driftCmd = cli.Command{
+	Name:      "concourse-drift",
+	Usage:     "Reports or reverts changes made to Concourse teams and pipelines outside of control-tower",
+	ArgsUsage: "<name>",
+	Flags:     driftFlags,
+	Action: func(c *cli.Context) error {
+		driftArgs, err := validateDriftArgs(c, initialDriftArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on concourse-drift: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(driftArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on concourse-drift: [%v]", err))
+		}
+		provider, err := iaas.New(iaasName, driftArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on concourse-drift: [%v]", err)
+		}
+		return driftAction(c, driftArgs, provider)
+	},
+}
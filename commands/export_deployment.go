@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/commands/exportdeployment"
+	"github.com/EngineerBetter/control-tower/concourse"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+)
+
+var initialExportDeploymentArgs exportdeployment.Args
+
+var exportDeploymentFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialExportDeploymentArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialExportDeploymentArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialExportDeploymentArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "archive",
+		Usage:       "(required) Path to write the archive to, which must not already exist",
+		Destination: &initialExportDeploymentArgs.Archive,
+	},
+	cli.StringFlag{
+		Name:        "passphrase",
+		Usage:       "(required) Passphrase of at least 12 characters used to encrypt the credentials and state in the archive",
+		EnvVar:      "ARCHIVE_PASSPHRASE",
+		Destination: &initialExportDeploymentArgs.Passphrase,
+	},
+	cli.StringFlag{
+		Name:        "artifacts-dir",
+		Usage:       "(optional) Directory to download every release, stemcell, CLI and terraform provider the deployment needs into, for deploying without internet access",
+		Destination: &initialExportDeploymentArgs.ArtifactsDir,
+	},
+}
+
+func exportDeploymentAction(c *cli.Context, exportDeploymentArgs exportdeployment.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower export-deployment <name>`"))
+	}
+
+	version := c.App.Version
+
+	client, err := buildExportDeploymentClient(name, version, exportDeploymentArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.ExportDeployment(exportDeploymentArgs)
+}
+
+func validateExportDeploymentArgs(c *cli.Context, exportDeploymentArgs exportdeployment.Args) (exportdeployment.Args, error) {
+	err := exportDeploymentArgs.MarkSetFlags(c)
+	if err != nil {
+		return exportDeploymentArgs, fmt.Errorf("failed to mark set ExportDeployment flags: [%v]", err)
+	}
+
+	if err = exportDeploymentArgs.Validate(); err != nil {
+		return exportDeploymentArgs, fmt.Errorf("failed to validate ExportDeployment flags: [%v]", err)
+	}
+
+	return exportDeploymentArgs, nil
+}
+
+func buildExportDeploymentClient(name, version string, exportDeploymentArgs exportdeployment.Args, provider iaas.Provider) (*concourse.Client, error) {
+	versionFile, _ := provider.Choose(iaas.Choice{
+		AWS: resource.AWSVersionFile,
+		GCP: resource.GCPVersionFile,
+	}).([]byte)
+
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(versionFile))
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.NewWithCommandOptions(boshCommandOptions),
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, exportDeploymentArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+		versionFile,
+		credhub.NewClient,
+	)
+
+	return client, nil
+}
+
+var exportDeploymentCmd = cli.Command{
+	Name:      "export-deployment",
+	Usage:     "Exports a deployment to an encrypted archive, so that it can be imported elsewhere",
+	ArgsUsage: "<name>",
+	Flags:     exportDeploymentFlags,
+	Action: func(c *cli.Context) error {
+		exportDeploymentArgs, err := validateExportDeploymentArgs(c, initialExportDeploymentArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on export-deployment: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(exportDeploymentArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on export-deployment: [%v]", err))
+		}
+		provider, err := iaas.New(iaasName, exportDeploymentArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on export-deployment: [%v]", err)
+		}
+		return exportDeploymentAction(c, exportDeploymentArgs, provider)
+	},
+}
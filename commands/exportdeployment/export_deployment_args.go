@@ -0,0 +1,78 @@
+package exportdeployment
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/deployarchive"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the export-deployment command
+type Args struct {
+	Region         string
+	RegionIsSet    bool
+	Namespace      string
+	NamespaceIsSet bool
+	IAAS           string
+	IAASIsSet      bool
+	Archive        string
+	ArchiveIsSet   bool
+	Passphrase     string
+	ArtifactsDir   string
+}
+
+// MarkSetFlags is marking which export-deployment Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "region":
+				a.RegionIsSet = true
+			case "namespace":
+				a.NamespaceIsSet = true
+			case "iaas":
+				a.IAASIsSet = true
+			case "archive":
+				a.ArchiveIsSet = true
+			case "artifacts-dir":
+				//do nothing
+			case "passphrase":
+				//do nothing
+			default:
+				return fmt.Errorf("flag %q is not supported by export-deployment flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Args) Validate() error {
+	if !a.IAASIsSet {
+		return fmt.Errorf("--iaas flag not set")
+	}
+	if !a.ArchiveIsSet || a.Archive == "" {
+		return fmt.Errorf("--archive flag not set")
+	}
+	return deployarchive.ValidatePassphrase(a.Passphrase)
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
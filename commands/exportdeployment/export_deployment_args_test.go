@@ -0,0 +1,77 @@
+package exportdeployment_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/EngineerBetter/control-tower/commands/exportdeployment"
+)
+
+func TestExportDeploymentArgs_Validate(t *testing.T) {
+	defaultFields := Args{
+		Region:       "eu-west-1",
+		IAAS:         "AWS",
+		IAASIsSet:    true,
+		Archive:      "lab.tgz",
+		ArchiveIsSet: true,
+		Passphrase:   "correct horse battery staple",
+	}
+	tests := []struct {
+		name         string
+		modification func() Args
+		wantErr      bool
+		expectedErr  string
+	}{
+		{
+			name: "Default args",
+			modification: func() Args {
+				return defaultFields
+			},
+			wantErr: false,
+		},
+		{
+			name: "IAAS not set",
+			modification: func() Args {
+				args := defaultFields
+				args.IAASIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--iaas flag not set",
+		},
+		{
+			name: "Archive not set",
+			modification: func() Args {
+				args := defaultFields
+				args.Archive = ""
+				args.ArchiveIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--archive flag not set",
+		},
+		{
+			name: "Passphrase too short",
+			modification: func() Args {
+				args := defaultFields
+				args.Passphrase = "hunter2"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "the archive passphrase must be at least 12 characters long",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.modification()
+			err := args.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && tt.wantErr && !strings.Contains(err.Error(), tt.expectedErr)) {
+				if err != nil {
+					t.Errorf("ExportDeploymentArgs.Validate() %v test failed.\nFailed with error = %v,\nExpected error = %v,\nShould fail %v\nWith args: %#v", tt.name, err.Error(), tt.expectedErr, tt.wantErr, args)
+				} else {
+					t.Errorf("ExportDeploymentArgs.Validate() %v test failed.\nShould fail %v\nWith args: %#v", tt.name, tt.wantErr, args)
+				}
+			}
+		})
+	}
+}
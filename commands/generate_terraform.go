@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/commands/generateterraform"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+)
+
+var initialGenerateTerraformArgs generateterraform.Args
+
+var generateTerraformFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialGenerateTerraformArgs.IAAS,
+	},
+	cli.BoolFlag{
+		Name:        "network-only",
+		Usage:       "(required) Generate only the network and firewall module, with variables for its name and ranges",
+		Destination: &initialGenerateTerraformArgs.NetworkOnly,
+	},
+}
+
+func generateTerraformAction(iaasName iaas.Name, stdout io.Writer) error {
+	var module string
+	switch iaasName {
+	case iaas.AWS:
+		module = resource.AWSNetworkTerraformConfig
+	case iaas.GCP:
+		module = resource.GCPNetworkTerraformConfig
+	default:
+		return fmt.Errorf("no network module for IAAS %s", iaasName)
+	}
+	_, err := io.WriteString(stdout, module)
+	return err
+}
+
+func validateGenerateTerraformArgs(c *cli.Context, generateTerraformArgs generateterraform.Args) (generateterraform.Args, error) {
+	err := generateTerraformArgs.MarkSetFlags(c)
+	if err != nil {
+		return generateTerraformArgs, fmt.Errorf("failed to mark set Generate Terraform flags: [%v]", err)
+	}
+
+	if err = generateTerraformArgs.Validate(); err != nil {
+		return generateTerraformArgs, fmt.Errorf("failed to validate Generate Terraform flags: [%v]", err)
+	}
+
+	return generateTerraformArgs, nil
+}
+
+var generateTerraformCmd = cli.Command{
+	Name:  "generate-terraform",
+	Usage: "Prints a terraform module of the network control-tower deploys into, for placing other systems on the same network",
+	Flags: generateTerraformFlags,
+	Action: func(c *cli.Context) error {
+		generateTerraformArgs, err := validateGenerateTerraformArgs(c, initialGenerateTerraformArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on generate-terraform: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(generateTerraformArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on generate-terraform: [%v]", err))
+		}
+		return generateTerraformAction(iaasName, os.Stdout)
+	},
+}
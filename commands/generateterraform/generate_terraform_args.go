@@ -0,0 +1,63 @@
+package generateterraform
+
+import (
+	"errors"
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the generate-terraform command
+type Args struct {
+	IAAS        string
+	IAASIsSet   bool
+	NetworkOnly bool
+}
+
+//MarkSetFlags is marking which generate-terraform Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "iaas":
+				a.IAASIsSet = true
+			case "network-only":
+				//do nothing
+			default:
+				return fmt.Errorf("flag %q is not supported by generate-terraform flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Args) Validate() error {
+	if !a.IAASIsSet {
+		return fmt.Errorf("--iaas flag not set")
+	}
+	if !a.NetworkOnly {
+		return errors.New("only the network module can be generated, --network-only must be provided")
+	}
+	return nil
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
@@ -0,0 +1,63 @@
+package generateterraform_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/EngineerBetter/control-tower/commands/generateterraform"
+)
+
+func TestGenerateTerraformArgs_Validate(t *testing.T) {
+	defaultFields := Args{
+		IAAS:        "AWS",
+		IAASIsSet:   true,
+		NetworkOnly: true,
+	}
+	tests := []struct {
+		name         string
+		modification func() Args
+		wantErr      bool
+		expectedErr  string
+	}{
+		{
+			name: "Default args",
+			modification: func() Args {
+				return defaultFields
+			},
+			wantErr: false,
+		},
+		{
+			name: "IAAS not set",
+			modification: func() Args {
+				args := defaultFields
+				args.IAASIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--iaas flag not set",
+		},
+		{
+			name: "Network only not set",
+			modification: func() Args {
+				args := defaultFields
+				args.NetworkOnly = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "only the network module can be generated, --network-only must be provided",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.modification()
+			err := args.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && tt.wantErr && !strings.Contains(err.Error(), tt.expectedErr)) {
+				if err != nil {
+					t.Errorf("GenerateTerraformArgs.Validate() %v test failed.\nFailed with error = %v,\nExpected error = %v,\nShould fail %v\nWith args: %#v", tt.name, err.Error(), tt.expectedErr, tt.wantErr, args)
+				} else {
+					t.Errorf("GenerateTerraformArgs.Validate() %v test failed.\nShould fail %v\nWith args: %#v", tt.name, tt.wantErr, args)
+				}
+			}
+		})
+	}
+}
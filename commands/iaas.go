@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/commands/iaasquery"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/iaas"
+)
+
+var initialIAASQueryArgs iaasquery.Args
+
+var iaasQueryFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialIAASQueryArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialIAASQueryArgs.IAAS,
+	},
+	cli.BoolFlag{
+		Name:        "json",
+		Usage:       "(optional) Output as json",
+		EnvVar:      "JSON",
+		Destination: &initialIAASQueryArgs.JSON,
+	},
+}
+
+func validateIAASQueryArgs(c *cli.Context, iaasQueryArgs iaasquery.Args) (iaasquery.Args, error) {
+	err := iaasQueryArgs.MarkSetFlags(c)
+	if err != nil {
+		return iaasQueryArgs, fmt.Errorf("failed to mark set IAAS query flags: [%v]", err)
+	}
+
+	if err = iaasQueryArgs.Validate(); err != nil {
+		return iaasQueryArgs, fmt.Errorf("failed to validate IAAS query flags: [%v]", err)
+	}
+
+	return iaasQueryArgs, nil
+}
+
+// iaasQueryCmd builds an iaas subcommand that runs query against the provider chosen by the flags
+func iaasQueryCmd(name, usage string, query func(provider iaas.Provider, w io.Writer, asJSON bool) error) cli.Command {
+	return cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: iaasQueryFlags,
+		Action: func(c *cli.Context) error {
+			iaasQueryArgs, err := validateIAASQueryArgs(c, initialIAASQueryArgs)
+			if err != nil {
+				return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on iaas %s: [%v]", name, err))
+			}
+			iaasName, err := iaas.Validate(iaasQueryArgs.IAAS)
+			if err != nil {
+				return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on iaas %s: [%v]", name, err))
+			}
+			provider, err := iaas.New(iaasName, iaasQueryArgs.Region)
+			if err != nil {
+				return fmt.Errorf("Error creating IAAS provider on iaas %s: [%v]", name, err)
+			}
+			return query(provider, os.Stdout, iaasQueryArgs.JSON)
+		},
+	}
+}
+
+func listZones(provider iaas.Provider, w io.Writer, asJSON bool) error {
+	zones, err := provider.ListZones()
+	if err != nil {
+		return fmt.Errorf("failed to list zones in region %s: [%v]", provider.Region(), err)
+	}
+	if asJSON {
+		return writeJSON(w, zones)
+	}
+	for _, zone := range zones {
+		fmt.Fprintln(w, zone)
+	}
+	return nil
+}
+
+func listInstanceTypes(provider iaas.Provider, w io.Writer, asJSON bool) error {
+	instanceTypes, err := provider.ListInstanceTypes()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeJSON(w, instanceTypes)
+	}
+	return writeInstanceTypes(w, instanceTypes)
+}
+
+func listQuotas(provider iaas.Provider, w io.Writer, asJSON bool) error {
+	quotas, err := provider.Quotas()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeJSON(w, quotas)
+	}
+	return writeQuotas(w, quotas)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(bytes))
+	return err
+}
+
+// writeInstanceTypes writes a table of instance types, only including the worker type column
+// where the provider has worker types
+func writeInstanceTypes(w io.Writer, instanceTypes []iaas.InstanceType) error {
+	withWorkerTypes := false
+	for _, instanceType := range instanceTypes {
+		if instanceType.WorkerType != "" {
+			withWorkerTypes = true
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if withWorkerTypes {
+		fmt.Fprintln(tw, "ROLE\tSIZE\tWORKER TYPE\tINSTANCE TYPE\tZONES")
+	} else {
+		fmt.Fprintln(tw, "ROLE\tSIZE\tINSTANCE TYPE\tZONES")
+	}
+	for _, instanceType := range instanceTypes {
+		zones := strings.Join(instanceType.Zones, ",")
+		if zones == "" {
+			zones = "none"
+		}
+		if withWorkerTypes {
+			workerType := instanceType.WorkerType
+			if workerType == "" {
+				workerType = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", instanceType.Role, instanceType.Size, workerType, instanceType.Name, zones)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", instanceType.Role, instanceType.Size, instanceType.Name, zones)
+		}
+	}
+	return tw.Flush()
+}
+
+func writeQuotas(w io.Writer, quotas []iaas.Quota) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUOTA\tUSAGE\tLIMIT")
+	for _, quota := range quotas {
+		fmt.Fprintf(tw, "%s\t%g\t%g\n", quota.Name, quota.Usage, quota.Limit)
+	}
+	return tw.Flush()
+}
+
+var iaasCmd = cli.Command{
+	Name:  "iaas",
+	Usage: "Queries the IAAS for values that can be passed to deploy flags",
+	Subcommands: []cli.Command{
+		iaasQueryCmd("list-zones", "Lists the zones in the region, for --zone", listZones),
+		iaasQueryCmd("list-instance-types", "Lists the instance types for each --web-size and --worker-size, and the zones offering them", listInstanceTypes),
+		iaasQueryCmd("quotas", "Lists the quotas in the region, and how much of each is in use", listQuotas),
+	},
+}
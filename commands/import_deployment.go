@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/commands/importdeployment"
+	"github.com/EngineerBetter/control-tower/concourse"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+)
+
+var initialImportDeploymentArgs importdeployment.Args
+
+var importDeploymentFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialImportDeploymentArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialImportDeploymentArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialImportDeploymentArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "archive",
+		Usage:       "(required) Path of an archive created by export-deployment",
+		Destination: &initialImportDeploymentArgs.Archive,
+	},
+	cli.StringFlag{
+		Name:        "passphrase",
+		Usage:       "(required) Passphrase of at least 12 characters used to encrypt the credentials and state in the archive",
+		EnvVar:      "ARCHIVE_PASSPHRASE",
+		Destination: &initialImportDeploymentArgs.Passphrase,
+	},
+	cli.BoolFlag{
+		Name:        "include-state",
+		Usage:       "(optional) Also import the terraform and director state, to take over the exported infrastructure rather than recreate it",
+		Destination: &initialImportDeploymentArgs.IncludeState,
+	},
+	cli.StringFlag{
+		Name:        "artifacts-dir",
+		Usage:       "(optional) Directory of artifacts downloaded by export-deployment --artifacts-dir, which are checked against the archive",
+		Destination: &initialImportDeploymentArgs.ArtifactsDir,
+	},
+}
+
+func importDeploymentAction(c *cli.Context, importDeploymentArgs importdeployment.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower import-deployment <name>`"))
+	}
+
+	version := c.App.Version
+
+	client, err := buildImportDeploymentClient(name, version, importDeploymentArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.ImportDeployment(importDeploymentArgs)
+}
+
+func validateImportDeploymentArgs(c *cli.Context, importDeploymentArgs importdeployment.Args) (importdeployment.Args, error) {
+	err := importDeploymentArgs.MarkSetFlags(c)
+	if err != nil {
+		return importDeploymentArgs, fmt.Errorf("failed to mark set ImportDeployment flags: [%v]", err)
+	}
+
+	if err = importDeploymentArgs.Validate(); err != nil {
+		return importDeploymentArgs, fmt.Errorf("failed to validate ImportDeployment flags: [%v]", err)
+	}
+
+	return importDeploymentArgs, nil
+}
+
+func buildImportDeploymentClient(name, version string, importDeploymentArgs importdeployment.Args, provider iaas.Provider) (*concourse.Client, error) {
+	versionFile, _ := provider.Choose(iaas.Choice{
+		AWS: resource.AWSVersionFile,
+		GCP: resource.GCPVersionFile,
+	}).([]byte)
+
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(versionFile))
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.NewWithCommandOptions(boshCommandOptions),
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, importDeploymentArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+		versionFile,
+		credhub.NewClient,
+	)
+
+	return client, nil
+}
+
+var importDeploymentCmd = cli.Command{
+	Name:      "import-deployment",
+	Usage:     "Imports a deployment from an archive created by export-deployment, ready to be deployed",
+	ArgsUsage: "<name>",
+	Flags:     importDeploymentFlags,
+	Action: func(c *cli.Context) error {
+		importDeploymentArgs, err := validateImportDeploymentArgs(c, initialImportDeploymentArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on import-deployment: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(importDeploymentArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on import-deployment: [%v]", err))
+		}
+		provider, err := iaas.New(iaasName, importDeploymentArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on import-deployment: [%v]", err)
+		}
+		return importDeploymentAction(c, importDeploymentArgs, provider)
+	},
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/EngineerBetter/control-tower/concourse"
 	"github.com/EngineerBetter/control-tower/config"
 	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
 	"github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
@@ -46,6 +47,12 @@ var infoFlags = []cli.Flag{
 		Usage:       "(optional) Output only the expiration date of the director nats certificate",
 		Destination: &initialInfoArgs.CertExpiry,
 	},
+	cli.BoolFlag{
+		Name:        "fail-if-outdated",
+		Usage:       "(optional) Exit with an error if the deployed Concourse is behind the latest release or end of life",
+		EnvVar:      "FAIL_IF_OUTDATED",
+		Destination: &initialInfoArgs.FailIfOutdated,
+	},
 	cli.StringFlag{
 		Name:        "iaas",
 		Usage:       "(required) IAAS, can be AWS or GCP",
@@ -63,7 +70,7 @@ var infoFlags = []cli.Flag{
 func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) error {
 	name := c.Args().Get(0)
 	if name == "" {
-		return errors.New("Usage is `control-tower info <name>`")
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower info <name>`"))
 	}
 
 	version := c.App.Version
@@ -76,6 +83,27 @@ func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) erro
 	if err != nil {
 		return err
 	}
+
+	if infoArgs.FailIfOutdated || !(infoArgs.Env || infoArgs.CertExpiry) {
+		if err = i.CheckConcourseVersion(concourse.NewReleaseFeed()); err != nil {
+			if infoArgs.FailIfOutdated {
+				return fmt.Errorf("unable to check whether Concourse is outdated: [%v]", err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: unable to check for Concourse upgrades: [%v]\n", err)
+		}
+	}
+
+	if err = writeInfo(infoArgs, i); err != nil {
+		return err
+	}
+
+	if infoArgs.FailIfOutdated && i.ConcourseVersion != nil && (i.ConcourseVersion.Outdated || i.ConcourseVersion.EOL) {
+		return fmt.Errorf("Concourse %s is outdated, latest is %s", i.ConcourseVersion.Deployed, i.ConcourseVersion.Latest)
+	}
+	return nil
+}
+
+func writeInfo(infoArgs info.Args, i *concourse.Info) error {
 	switch {
 	case infoArgs.JSON:
 		return json.NewEncoder(os.Stdout).Encode(i)
@@ -124,11 +152,16 @@ func buildInfoClient(name, version string, infoArgs info.Args, provider iaas.Pro
 		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
 	}
 
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	client := concourse.NewClient(
 		provider,
 		terraformClient,
 		tfInputVarsFactory,
-		bosh.New,
+		bosh.NewWithCommandOptions(boshCommandOptions),
 		fly.New,
 		certs.Generate,
 		config.New(provider, name, infoArgs.Namespace),
@@ -157,11 +190,11 @@ var infoCmd = cli.Command{
 	Action: func(c *cli.Context) error {
 		infoArgs, err := validateInfoArgs(c, initialInfoArgs)
 		if err != nil {
-			return fmt.Errorf("Error validating args on info: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on info: [%v]", err))
 		}
 		iaasName, err := iaas.Validate(infoArgs.IAAS)
 		if err != nil {
-			return fmt.Errorf("Error mapping to supported IAASes on info: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on info: [%v]", err))
 		}
 		provider, err := iaas.New(iaasName, infoArgs.Region)
 		if err != nil {
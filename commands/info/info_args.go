@@ -17,6 +17,7 @@ type Args struct {
 	IAAS           string
 	IAASIsSet      bool
 	CertExpiry     bool
+	FailIfOutdated bool
 }
 
 //MarkSetFlags is marking which info Args have been set
@@ -30,7 +31,7 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.NamespaceIsSet = true
 			case "iaas":
 				a.IAASIsSet = true
-			case "json", "env", "cert-expiry":
+			case "json", "env", "cert-expiry", "fail-if-outdated":
 				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by info flags", f)
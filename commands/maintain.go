@@ -13,6 +13,7 @@ import (
 	"github.com/EngineerBetter/control-tower/concourse"
 	"github.com/EngineerBetter/control-tower/config"
 	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
 	"github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
@@ -52,12 +53,64 @@ var maintainFlags = []cli.Flag{
 		EnvVar:      "STAGE",
 		Destination: &initialMaintainArgs.Stage,
 	},
+	cli.BoolFlag{
+		Name:        "remediate",
+		Usage:       "(optional) Find builds stuck pending, stalled workers and unhealthy VMs, then prune the stalled workers and recreate the unhealthy VMs",
+		Destination: &initialMaintainArgs.Remediate,
+	},
+	cli.StringFlag{
+		Name:        "stuck-after",
+		Usage:       "(optional) How long a build can be pending before --remediate reports it as stuck (default: 2h)",
+		EnvVar:      "STUCK_AFTER",
+		Destination: &initialMaintainArgs.StuckAfter,
+	},
+	cli.BoolFlag{
+		Name:        "dry-run",
+		Usage:       "(optional) Only report what --remediate finds, or the worker count --autoscale would choose, without changing anything",
+		Destination: &initialMaintainArgs.DryRun,
+	},
+	cli.BoolFlag{
+		Name:        "autoscale",
+		Usage:       "(optional) Scale the workers to the pending builds and active containers, within the bounds set by deploy --worker-autoscale-min and --worker-autoscale-max",
+		Destination: &initialMaintainArgs.Autoscale,
+	},
+	cli.BoolFlag{
+		Name:        "rotate-workers",
+		Usage:       "(optional) Recreate the workers one at a time, landing each so that its running builds finish first, eg to pick up a new stemcell without an outage",
+		Destination: &initialMaintainArgs.RotateWorkers,
+	},
+	cli.StringFlag{
+		Name:        "land-worker",
+		Usage:       "(optional) Land the named worker, so that it takes no new builds and leaves once its running builds have finished",
+		Destination: &initialMaintainArgs.LandWorker,
+	},
+	cli.StringFlag{
+		Name:        "retire-worker",
+		Usage:       "(optional) Land the named worker, then prune it from the ATC once its running builds have finished",
+		Destination: &initialMaintainArgs.RetireWorker,
+	},
+	cli.StringFlag{
+		Name:        "worker-logs-dir",
+		Usage:       "(optional) Directory to download the BOSH logs of the worker given to --land-worker or --retire-worker to",
+		Destination: &initialMaintainArgs.WorkerLogsDir,
+	},
+	cli.BoolFlag{
+		Name:        "rotate-db-ca",
+		Usage:       "(optional) Rotate the RDS or CloudSQL instance onto a new CA, after redeploying the director and Concourse to trust it",
+		Destination: &initialMaintainArgs.RotateDBCA,
+	},
+	cli.StringFlag{
+		Name:        "db-ca-identifier",
+		Usage:       "(optional) RDS CA that --rotate-db-ca moves the instance onto (default: rds-ca-rsa2048-g1)",
+		EnvVar:      "DB_CA_IDENTIFIER",
+		Destination: &initialMaintainArgs.DBCAIdentifier,
+	},
 }
 
 func maintainAction(c *cli.Context, maintainArgs maintain.Args, provider iaas.Provider) error {
 	name := c.Args().Get(0)
 	if name == "" {
-		return errors.New("Usage is `control-tower maintain <name>`")
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower maintain <name>`"))
 	}
 
 	version := c.App.Version
@@ -103,11 +156,16 @@ func buildMaintainClient(name, version string, maintainArgs maintain.Args, provi
 		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
 	}
 
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	client := concourse.NewClient(
 		provider,
 		terraformClient,
 		tfInputVarsFactory,
-		bosh.New,
+		bosh.NewWithCommandOptions(boshCommandOptions),
 		fly.New,
 		certs.Generate,
 		config.New(provider, name, maintainArgs.Namespace),
@@ -136,11 +194,11 @@ var maintainCmd = cli.Command{
 	Action: func(c *cli.Context) error {
 		maintainArgs, err := validateMaintainArgs(c, initialMaintainArgs)
 		if err != nil {
-			return fmt.Errorf("Error validating args on maintain: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on maintain: [%v]", err))
 		}
 		iaasName, err := iaas.Validate(maintainArgs.IAAS)
 		if err != nil {
-			return fmt.Errorf("Error mapping to supported IAASes on maintain: [%v]", err)
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on maintain: [%v]", err))
 		}
 		provider, err := iaas.New(iaasName, maintainArgs.Region)
 		if err != nil {
@@ -1,7 +1,10 @@
 package maintain
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	cli "gopkg.in/urfave/cli.v1"
 )
@@ -18,6 +21,35 @@ type Args struct {
 	IAASIsSet          bool
 	Stage              int
 	StageIsSet         bool
+	// Remediate finds builds stuck pending for longer than StuckAfter, stalled workers and unhealthy
+	// VMs, and fixes what it safely can unless DryRun is set
+	Remediate       bool
+	RemediateIsSet  bool
+	StuckAfter      string
+	StuckAfterIsSet bool
+	DryRun          bool
+	DryRunIsSet     bool
+	// Autoscale resizes the workers to the queue, within the bounds given to deploy, reporting the
+	// new size without deploying it if DryRun is set
+	Autoscale      bool
+	AutoscaleIsSet bool
+	// RotateWorkers recreates the workers one at a time, landing each first so that its builds finish
+	RotateWorkers      bool
+	RotateWorkersIsSet bool
+	// LandWorker and RetireWorker are the name of a worker to land, or to land and then prune from the ATC
+	LandWorker        string
+	LandWorkerIsSet   bool
+	RetireWorker      string
+	RetireWorkerIsSet bool
+	// WorkerLogsDir is where the BOSH logs of the landed or retired worker are downloaded to
+	WorkerLogsDir      string
+	WorkerLogsDirIsSet bool
+	// RotateDBCA moves the RDS or CloudSQL instance onto a new CA, once the director and Concourse trust it
+	RotateDBCA      bool
+	RotateDBCAIsSet bool
+	// DBCAIdentifier is the RDS CA that RotateDBCA moves the instance onto, or rds-ca-rsa2048-g1 when it's empty
+	DBCAIdentifier      string
+	DBCAIdentifierIsSet bool
 }
 
 //MarkSetFlags is marking which info Args have been set
@@ -35,6 +67,26 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.StageIsSet = true
 			case "iaas":
 				a.IAASIsSet = true
+			case "remediate":
+				a.RemediateIsSet = true
+			case "stuck-after":
+				a.StuckAfterIsSet = true
+			case "dry-run":
+				a.DryRunIsSet = true
+			case "autoscale":
+				a.AutoscaleIsSet = true
+			case "rotate-workers":
+				a.RotateWorkersIsSet = true
+			case "land-worker":
+				a.LandWorkerIsSet = true
+			case "retire-worker":
+				a.RetireWorkerIsSet = true
+			case "worker-logs-dir":
+				a.WorkerLogsDirIsSet = true
+			case "rotate-db-ca":
+				a.RotateDBCAIsSet = true
+			case "db-ca-identifier":
+				a.DBCAIdentifierIsSet = true
 			default:
 				return fmt.Errorf("flag %q is not supported by maintain flags", f)
 			}
@@ -47,6 +99,53 @@ func (a *Args) Validate() error {
 	if !a.IAASIsSet {
 		return fmt.Errorf("--iaas flag not set")
 	}
+	if a.Remediate && a.RenewNatsCert {
+		return errors.New("--remediate and --renew-nats-cert cannot be run together")
+	}
+	if a.Autoscale && (a.Remediate || a.RenewNatsCert) {
+		return errors.New("--autoscale cannot be run together with --remediate or --renew-nats-cert")
+	}
+	if a.RotateWorkers && (a.Autoscale || a.Remediate || a.RenewNatsCert) {
+		return errors.New("--rotate-workers cannot be run together with --autoscale, --remediate or --renew-nats-cert")
+	}
+	if a.LandWorkerIsSet && a.RetireWorkerIsSet {
+		return errors.New("--land-worker and --retire-worker cannot be run together")
+	}
+	if (a.LandWorkerIsSet || a.RetireWorkerIsSet) && (a.Autoscale || a.Remediate || a.RenewNatsCert || a.RotateWorkers) {
+		return errors.New("--land-worker and --retire-worker cannot be run together with --autoscale, --remediate, --renew-nats-cert or --rotate-workers")
+	}
+	if a.RotateDBCA && (a.Autoscale || a.Remediate || a.RenewNatsCert || a.RotateWorkers || a.LandWorkerIsSet || a.RetireWorkerIsSet) {
+		return errors.New("--rotate-db-ca cannot be run together with other maintenance actions")
+	}
+	if a.DBCAIdentifierIsSet && !a.RotateDBCA {
+		return errors.New("--db-ca-identifier only applies with --rotate-db-ca")
+	}
+	if a.DBCAIdentifierIsSet && strings.ToLower(a.IAAS) != "aws" {
+		return errors.New("--db-ca-identifier only applies on AWS, as CloudSQL is rotated onto the CA it adds")
+	}
+	if a.DBCAIdentifierIsSet && a.DBCAIdentifier == "" {
+		return errors.New("--db-ca-identifier requires the identifier of an RDS CA, like rds-ca-ecc384-g1")
+	}
+	if a.LandWorkerIsSet && a.LandWorker == "" {
+		return errors.New("--land-worker requires the name of a worker")
+	}
+	if a.RetireWorkerIsSet && a.RetireWorker == "" {
+		return errors.New("--retire-worker requires the name of a worker")
+	}
+	if a.WorkerLogsDirIsSet && !a.LandWorkerIsSet && !a.RetireWorkerIsSet {
+		return errors.New("--worker-logs-dir only applies with --land-worker or --retire-worker")
+	}
+	if !a.Remediate && a.StuckAfterIsSet {
+		return errors.New("--stuck-after only applies with --remediate")
+	}
+	if !a.Remediate && !a.Autoscale && a.DryRunIsSet {
+		return errors.New("--dry-run only applies with --remediate or --autoscale")
+	}
+	if a.StuckAfter != "" {
+		if d, err := time.ParseDuration(a.StuckAfter); err != nil || d <= 0 {
+			return fmt.Errorf("--stuck-after %s is invalid: must be a positive duration, like 2h", a.StuckAfter)
+		}
+	}
 	return nil
 }
 
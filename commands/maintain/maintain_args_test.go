@@ -37,6 +37,190 @@ func TestMaintainArgs_Validate(t *testing.T) {
 			wantErr:     true,
 			expectedErr: "--iaas flag not set",
 		},
+		{
+			name: "Remediate with a dry run",
+			modification: func() Args {
+				args := defaultFields
+				args.Remediate = true
+				args.RemediateIsSet = true
+				args.StuckAfter = "90m"
+				args.StuckAfterIsSet = true
+				args.DryRun = true
+				args.DryRunIsSet = true
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Stuck after without remediate",
+			modification: func() Args {
+				args := defaultFields
+				args.StuckAfter = "90m"
+				args.StuckAfterIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--stuck-after only applies with --remediate",
+		},
+		{
+			name: "Autoscale with a dry run",
+			modification: func() Args {
+				args := defaultFields
+				args.Autoscale = true
+				args.AutoscaleIsSet = true
+				args.DryRun = true
+				args.DryRunIsSet = true
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Dry run on its own",
+			modification: func() Args {
+				args := defaultFields
+				args.DryRun = true
+				args.DryRunIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--dry-run only applies with --remediate or --autoscale",
+		},
+		{
+			name: "Autoscale and remediate",
+			modification: func() Args {
+				args := defaultFields
+				args.Autoscale = true
+				args.AutoscaleIsSet = true
+				args.Remediate = true
+				args.RemediateIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--autoscale cannot be run together with --remediate or --renew-nats-cert",
+		},
+		{
+			name: "Rotate workers and autoscale",
+			modification: func() Args {
+				args := defaultFields
+				args.RotateWorkers = true
+				args.RotateWorkersIsSet = true
+				args.Autoscale = true
+				args.AutoscaleIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--rotate-workers cannot be run together with --autoscale, --remediate or --renew-nats-cert",
+		},
+		{
+			name: "Land worker and retire worker",
+			modification: func() Args {
+				args := defaultFields
+				args.LandWorker = "a1b2c3"
+				args.LandWorkerIsSet = true
+				args.RetireWorker = "d4e5f6"
+				args.RetireWorkerIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--land-worker and --retire-worker cannot be run together",
+		},
+		{
+			name: "Rotate DB CA and rotate workers",
+			modification: func() Args {
+				args := defaultFields
+				args.RotateDBCA = true
+				args.RotateDBCAIsSet = true
+				args.RotateWorkers = true
+				args.RotateWorkersIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--rotate-db-ca cannot be run together with other maintenance actions",
+		},
+		{
+			name: "DB CA identifier",
+			modification: func() Args {
+				args := defaultFields
+				args.RotateDBCA = true
+				args.RotateDBCAIsSet = true
+				args.DBCAIdentifier = "rds-ca-ecc384-g1"
+				args.DBCAIdentifierIsSet = true
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB CA identifier without rotating the DB CA",
+			modification: func() Args {
+				args := defaultFields
+				args.DBCAIdentifier = "rds-ca-ecc384-g1"
+				args.DBCAIdentifierIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--db-ca-identifier only applies with --rotate-db-ca",
+		},
+		{
+			name: "DB CA identifier on GCP",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.RotateDBCA = true
+				args.RotateDBCAIsSet = true
+				args.DBCAIdentifier = "rds-ca-ecc384-g1"
+				args.DBCAIdentifierIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--db-ca-identifier only applies on AWS, as CloudSQL is rotated onto the CA it adds",
+		},
+		{
+			name: "Retire worker without a name",
+			modification: func() Args {
+				args := defaultFields
+				args.RetireWorkerIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--retire-worker requires the name of a worker",
+		},
+		{
+			name: "Worker logs dir without a worker",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerLogsDir = "logs"
+				args.WorkerLogsDirIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-logs-dir only applies with --land-worker or --retire-worker",
+		},
+		{
+			name: "Stuck after must be a duration",
+			modification: func() Args {
+				args := defaultFields
+				args.Remediate = true
+				args.RemediateIsSet = true
+				args.StuckAfter = "2"
+				args.StuckAfterIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--stuck-after 2 is invalid: must be a positive duration, like 2h",
+		},
+		{
+			name: "Remediate and renew NATS cert",
+			modification: func() Args {
+				args := defaultFields
+				args.Remediate = true
+				args.RemediateIsSet = true
+				args.RenewNatsCert = true
+				args.RenewNatsCertIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--remediate and --renew-nats-cert cannot be run together",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/commands/restoredb"
+	"github.com/EngineerBetter/control-tower/concourse"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/credhub"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/resource"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+)
+
+var initialRestoreDBArgs restoredb.Args
+
+var restoreDBFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialRestoreDBArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(required) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Destination: &initialRestoreDBArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialRestoreDBArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "timestamp",
+		Usage:       "(required) RFC3339 time to restore the database to, like 2006-01-02T15:04:05Z",
+		Destination: &initialRestoreDBArgs.Timestamp,
+	},
+}
+
+func restoreDBAction(c *cli.Context, restoreDBArgs restoredb.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("Usage is `control-tower restore-db <name>`"))
+	}
+
+	version := c.App.Version
+
+	client, err := buildRestoreDBClient(name, version, restoreDBArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.RestoreDB(restoreDBArgs)
+}
+
+func validateRestoreDBArgs(c *cli.Context, restoreDBArgs restoredb.Args) (restoredb.Args, error) {
+	err := restoreDBArgs.MarkSetFlags(c)
+	if err != nil {
+		return restoreDBArgs, fmt.Errorf("failed to mark set RestoreDB flags: [%v]", err)
+	}
+
+	if err = restoreDBArgs.Validate(); err != nil {
+		return restoreDBArgs, fmt.Errorf("failed to validate RestoreDB flags: [%v]", err)
+	}
+
+	return restoreDBArgs, nil
+}
+
+func buildRestoreDBClient(name, version string, restoreDBArgs restoredb.Args, provider iaas.Provider) (*concourse.Client, error) {
+	versionFile, _ := provider.Choose(iaas.Choice{
+		AWS: resource.AWSVersionFile,
+		GCP: resource.GCPVersionFile,
+	}).([]byte)
+
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(versionFile))
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	boshCommandOptions, err := parseBoshCommandOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.NewWithCommandOptions(boshCommandOptions),
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, restoreDBArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+		versionFile,
+		credhub.NewClient,
+	)
+
+	return client, nil
+}
+
+var restoreDBCmd = cli.Command{
+	Name:      "restore-db",
+	Usage:     "Restores the database to a point in time as a new instance, which the deployment is moved onto, keeping the old instance",
+	ArgsUsage: "<name>",
+	Flags:     restoreDBFlags,
+	Action: func(c *cli.Context) error {
+		restoreDBArgs, err := validateRestoreDBArgs(c, initialRestoreDBArgs)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error validating args on restore-db: [%v]", err))
+		}
+		iaasName, err := iaas.Validate(restoreDBArgs.IAAS)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, fmt.Errorf("Error mapping to supported IAASes on restore-db: [%v]", err))
+		}
+		provider, err := iaas.New(iaasName, restoreDBArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on restore-db: [%v]", err)
+		}
+		return restoreDBAction(c, restoreDBArgs, provider)
+	},
+}
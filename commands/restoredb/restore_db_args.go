@@ -0,0 +1,82 @@
+package restoredb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the restore-db command
+type Args struct {
+	Region         string
+	RegionIsSet    bool
+	Namespace      string
+	NamespaceIsSet bool
+	IAAS           string
+	IAASIsSet      bool
+	// Timestamp is the RFC3339 time to restore the database to
+	Timestamp      string
+	TimestampIsSet bool
+}
+
+//MarkSetFlags is marking which restore-db Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "region":
+				a.RegionIsSet = true
+			case "namespace":
+				a.NamespaceIsSet = true
+			case "iaas":
+				a.IAASIsSet = true
+			case "timestamp":
+				a.TimestampIsSet = true
+			default:
+				return fmt.Errorf("flag %q is not supported by restore-db flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Args) Validate() error {
+	if !a.IAASIsSet {
+		return fmt.Errorf("--iaas flag not set")
+	}
+	if !a.TimestampIsSet || a.Timestamp == "" {
+		return errors.New("--timestamp flag not set")
+	}
+	if _, err := a.Time(); err != nil {
+		return fmt.Errorf("--timestamp %s is invalid: must be an RFC3339 time, like 2006-01-02T15:04:05Z", a.Timestamp)
+	}
+	return nil
+}
+
+// Time is the parsed Timestamp
+func (a *Args) Time() (time.Time, error) {
+	return time.Parse(time.RFC3339, a.Timestamp)
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
@@ -0,0 +1,85 @@
+package restoredb_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/EngineerBetter/control-tower/commands/restoredb"
+)
+
+func TestRestoreDBArgs_Validate(t *testing.T) {
+	defaultFields := Args{
+		Region:         "eu-west-1",
+		IAAS:           "AWS",
+		IAASIsSet:      true,
+		Timestamp:      "2023-11-14T22:00:00Z",
+		TimestampIsSet: true,
+	}
+	tests := []struct {
+		name         string
+		modification func() Args
+		wantErr      bool
+		expectedErr  string
+	}{
+		{
+			name: "Default args",
+			modification: func() Args {
+				return defaultFields
+			},
+			wantErr: false,
+		},
+		{
+			name: "Timestamp with an offset",
+			modification: func() Args {
+				args := defaultFields
+				args.Timestamp = "2023-11-14T23:00:00+01:00"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "IAAS not set",
+			modification: func() Args {
+				args := defaultFields
+				args.IAASIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--iaas flag not set",
+		},
+		{
+			name: "Timestamp not set",
+			modification: func() Args {
+				args := defaultFields
+				args.Timestamp = ""
+				args.TimestampIsSet = false
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--timestamp flag not set",
+		},
+		{
+			name: "Timestamp without a time zone",
+			modification: func() Args {
+				args := defaultFields
+				args.Timestamp = "2023-11-14 22:00"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--timestamp 2023-11-14 22:00 is invalid: must be an RFC3339 time, like 2006-01-02T15:04:05Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.modification()
+			err := args.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && tt.wantErr && !strings.Contains(err.Error(), tt.expectedErr)) {
+				if err != nil {
+					t.Errorf("RestoreDBArgs.Validate() %v test failed.\nFailed with error = %v,\nExpected error = %v,\nShould fail %v\nWith args: %#v", tt.name, err.Error(), tt.expectedErr, tt.wantErr, args)
+				} else {
+					t.Errorf("RestoreDBArgs.Validate() %v test failed.\nShould fail %v\nWith args: %#v", tt.name, tt.wantErr, args)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package concourse
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/autoscale"
+	"github.com/EngineerBetter/control-tower/commands/maintain"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// autoscale sizes the workers to the builds waiting to be scheduled and the containers running,
+// within the bounds given to deploy, or to the worker schedules. A schedule raises the lower bound
+// when autoscaling is also enabled. Builds waiting only for worker pools don't count, as pools keep
+// their own counts. A new size is deployed to the worker instance group with BOSH and saved to the
+// runtime file, so that the next deploy keeps it. Only the runtime file is used, so that the credential the
+// self-update pipeline autoscales with doesn't need to read the rest of the config
+func (client *Client) autoscale(m maintain.Args) error {
+	runtime, err := client.configClient.LoadRuntime()
+	if err != nil {
+		return err
+	}
+	conf := runtime.Config
+	if conf.WorkerAutoscaleMax == 0 && len(conf.WorkerSchedules) == 0 {
+		return errors.New("autoscaling is not enabled, deploy with --worker-autoscale-min and --worker-autoscale-max or --worker-schedule first")
+	}
+
+	scheduled, isScheduled, err := autoscale.Scheduled(conf.WorkerSchedules, conf.WorkerScheduleTimezone, time.Now())
+	if err != nil {
+		return err
+	}
+	if isScheduled {
+		fmt.Fprintf(client.stdout, "Scheduled for %d workers\n", scheduled)
+	}
+
+	desired := conf.ConcourseWorkerCount
+	if conf.WorkerAutoscaleMax == 0 {
+		if isScheduled {
+			desired = scheduled
+		}
+		return client.scaleWorkers(runtime, desired, m.DryRun)
+	}
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", conf.GetDomain()),
+		Username: conf.GetConcourseUsername(),
+		Password: conf.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return err
+	}
+	defer flyClient.Cleanup()
+
+	builds, err := flyClient.Builds(buildsToInspect)
+	if err != nil {
+		return fmt.Errorf("error listing builds: [%v]", err)
+	}
+	stepTags := map[int][][]string{}
+	for _, build := range builds {
+		if build.Status != "pending" {
+			continue
+		}
+		if stepTags[build.ID], err = flyClient.StepTags(build.ID); err != nil {
+			return fmt.Errorf("error finding the workers build %d is waiting for: [%v]", build.ID, err)
+		}
+	}
+	workers, err := flyClient.Workers()
+	if err != nil {
+		return fmt.Errorf("error listing workers: [%v]", err)
+	}
+
+	load := autoscale.Measure(builds, stepTags, workers, conf.WorkerPools)
+	policy := autoscale.Policy{Min: conf.WorkerAutoscaleMin, Max: conf.WorkerAutoscaleMax}
+	if isScheduled && scheduled > policy.Min {
+		policy.Min = scheduled
+		if policy.Min > policy.Max {
+			policy.Min = policy.Max
+		}
+	}
+	desired = autoscale.Desired(policy, conf.ConcourseWorkerCount, load)
+	fmt.Fprintf(client.stdout, "%s\n", load)
+	return client.scaleWorkers(runtime, desired, m.DryRun)
+}
+
+// scaleWorkers deploys the given number of workers unless it's what is already deployed
+func (client *Client) scaleWorkers(runtime config.Runtime, desired int, dryRun bool) error {
+	conf := runtime.Config
+	fmt.Fprintf(client.stdout, "Want %d workers, have %d\n", desired, conf.ConcourseWorkerCount)
+	if desired == conf.ConcourseWorkerCount || dryRun {
+		return nil
+	}
+
+	tfOutputs, err := client.runtimeOutputs(runtime)
+	if err != nil {
+		return err
+	}
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	lock, err := deploylock.Acquire(client.provider, conf.GetConfigBucket(), "autoscale", deploylock.Options{
+		StaleAfter: deploylock.DefaultStaleAfter,
+		InFlight: func() (bool, error) {
+			return boshLocked(boshClient)
+		},
+		Stderr: client.stderr,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	// Only the worker instance group is deployed, leaving the director and the rest of Concourse as they are
+	fmt.Fprintf(client.stdout, "Scaling workers to %d\n", desired)
+	if err = boshClient.ScaleWorkers(desired); err != nil {
+		return err
+	}
+
+	runtime.Config.ConcourseWorkerCount = desired
+	return client.configClient.UpdateRuntime(runtime)
+}
+
+// runtimeOutputs returns the outputs that the last deploy recorded in the runtime file, or builds them with
+// terraform for deployments that haven't been deployed since it was added
+func (client *Client) runtimeOutputs(runtime config.Runtime) (terraform.Outputs, error) {
+	if runtime.Outputs != nil {
+		return terraform.RecordedOutputs(runtime.Outputs), nil
+	}
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	return client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+}
@@ -0,0 +1,71 @@
+package concourse
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/bosh/boshfakes"
+	"github.com/EngineerBetter/control-tower/commands/maintain"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/config/configfakes"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/iaas/iaasfakes"
+	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/terraform/terraformfakes"
+)
+
+func TestClient_AutoscaleUsesRuntime(t *testing.T) {
+	runtime := config.Runtime{
+		Config: config.Config{
+			ConcourseWorkerCount: 1,
+			ConfigBucket:         "control-tower-test-eu-west-1-config",
+			DirectorPublicIP:     "99.99.99.99",
+			WorkerSchedules:      []config.WorkerSchedule{{Cron: "* * * * *", Workers: 3}},
+		},
+		Outputs: map[string]string{"DirectorPublicIP": "99.99.99.99"},
+	}
+	configClient := new(configfakes.FakeIClient)
+	configClient.LoadRuntimeReturns(runtime, nil)
+	tfCLI := new(terraformfakes.FakeCLIInterface)
+	boshClient := new(boshfakes.FakeIClient)
+	boshClient.LocksReturns([]byte(`{"Tables":[{"Content":"locks","Rows":[]}]}`), nil)
+	provider := new(iaasfakes.FakeProvider)
+	provider.WriteFileIfVersionReturns("1", true, nil)
+	var gotOutputs terraform.Outputs
+	client := &Client{
+		configClient: configClient,
+		boshClientFactory: func(_ config.ConfigView, outputs terraform.Outputs, _, _ io.Writer, _ iaas.Provider, _ []byte) (bosh.IClient, error) {
+			gotOutputs = outputs
+			return boshClient, nil
+		},
+		provider: provider,
+		tfCLI:    tfCLI,
+		stdout:   ioutil.Discard,
+		stderr:   ioutil.Discard,
+	}
+
+	if err := client.Maintain(maintain.Args{Autoscale: true}); err != nil {
+		t.Fatalf("Maintain() error = %v", err)
+	}
+	if configClient.LoadCallCount() != 0 || tfCLI.BuildOutputCallCount() != 0 {
+		t.Errorf("Maintain() read the config %d times and the terraform outputs %d times, want neither", configClient.LoadCallCount(), tfCLI.BuildOutputCallCount())
+	}
+	if !reflect.DeepEqual(gotOutputs, terraform.RecordedOutputs(runtime.Outputs)) {
+		t.Errorf("Maintain() reached the director with %v, want the recorded outputs", gotOutputs)
+	}
+	if boshClient.ScaleWorkersCallCount() != 1 || boshClient.ScaleWorkersArgsForCall(0) != 3 {
+		t.Fatalf("Maintain() didn't scale the workers to 3")
+	}
+	if configClient.UpdateCallCount() != 0 {
+		t.Errorf("Maintain() wrote config.json")
+	}
+	if configClient.UpdateRuntimeCallCount() != 1 {
+		t.Fatalf("Maintain() wrote the runtime file %d times, want once", configClient.UpdateRuntimeCallCount())
+	}
+	if got := configClient.UpdateRuntimeArgsForCall(0).Config.ConcourseWorkerCount; got != 3 {
+		t.Errorf("Maintain() saved %d workers, want 3", got)
+	}
+}
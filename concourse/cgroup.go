@@ -0,0 +1,124 @@
+package concourse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// workerRebootTimeout is how long a worker may take to come back after being rebooted onto a new cgroup version
+const workerRebootTimeout = 15 * time.Minute
+
+// switchWorkerCgroups reboots each worker that isn't running the deployed --worker-cgroup-version, one at a time.
+// The worker's pre-start script has already set the kernel command line, so the reboot is all that is left
+func (client *Client) switchWorkerCgroups(conf config.ConfigView, boshClient bosh.IClient, flyClient fly.IClient) error {
+	landTimeout, err := workerLandTimeout(conf)
+	if err != nil {
+		return err
+	}
+
+	instances, err := boshClient.Instances()
+	if err != nil {
+		return fmt.Errorf("error listing VMs: [%v]", err)
+	}
+	for _, instance := range instances {
+		if !isCgroupWorker(instance, conf.GetWorkerPools()) {
+			continue
+		}
+		if err = switchWorkerCgroup(boshClient, flyClient, instance, conf.GetWorkerCgroupVersion(), landTimeout, client.stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCgroupWorker is true for the VMs that --worker-cgroup-version applies to, which are the default workers
+// and the workers in pools
+func isCgroupWorker(instance bosh.Instance, pools []config.WorkerPool) bool {
+	group := strings.SplitN(instance.Name, "/", 2)[0]
+	if group == "worker" {
+		return true
+	}
+	for _, pool := range pools {
+		if group == "worker-"+pool.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// switchWorkerCgroup lands the worker through the ATC so that no running build is lost, reboots it if it isn't
+// running the desired cgroup version, and waits for it to register again
+func switchWorkerCgroup(boshClient bosh.IClient, flyClient fly.IClient, instance bosh.Instance, desired string, landTimeout time.Duration, stdout io.Writer) error {
+	current, err := workerCgroupVersion(boshClient, instance)
+	if err != nil {
+		return fmt.Errorf("error checking the cgroup version of worker %s: [%v]", instance.Name, err)
+	}
+	if current == desired {
+		return nil
+	}
+
+	name := workerName(instance)
+	worker, registered, err := findWorker(flyClient, name)
+	if err != nil {
+		return err
+	}
+	if registered && worker.State == "running" {
+		fmt.Fprintf(stdout, "Landing worker %s to switch it from cgroup v%s to v%s\n", instance.Name, current, desired)
+		if err = flyClient.LandWorker(name); err != nil {
+			return err
+		}
+	}
+	if registered {
+		err = waitForWorker(flyClient, name, landTimeout, func(worker fly.Worker, registered bool) bool {
+			return !registered || worker.State == "landed" || worker.State == "stalled"
+		})
+		if err != nil {
+			return fmt.Errorf("worker %s did not land within %s, deploy again once its builds have finished: [%v]", instance.Name, landTimeout, err)
+		}
+	}
+
+	// Stop the worker so that it doesn't register again and take builds before the reboot
+	fmt.Fprintf(stdout, "Rebooting worker %s\n", instance.Name)
+	if _, err = boshClient.SSH(instance.Name, `sudo /var/vcap/bosh/bin/monit stop worker && sudo shutdown -r +1 "control-tower: switching cgroup version"`); err != nil {
+		return fmt.Errorf("error rebooting worker %s: [%v]", instance.Name, err)
+	}
+
+	// The director can't reach the worker while it reboots, so failures are retried until the timeout
+	deadline := time.Now().Add(workerRebootTimeout)
+	for {
+		time.Sleep(workerPollInterval)
+		current, err = workerCgroupVersion(boshClient, instance)
+		if err == nil && current == desired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("worker %s did not come back with cgroup v%s within %s", instance.Name, desired, workerRebootTimeout)
+		}
+	}
+
+	err = waitForWorker(flyClient, name, workerRebootTimeout, func(worker fly.Worker, registered bool) bool {
+		return registered && worker.State == "running"
+	})
+	if err != nil {
+		return fmt.Errorf("worker %s did not register again after being rebooted: [%v]", instance.Name, err)
+	}
+	return nil
+}
+
+// workerCgroupVersion is the cgroup version the worker has booted with
+func workerCgroupVersion(boshClient bosh.IClient, instance bosh.Instance) (string, error) {
+	output, err := boshClient.SSH(instance.Name, "stat -fc %T /sys/fs/cgroup")
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(string(output), "cgroup2fs") {
+		return "2", nil
+	}
+	return "1", nil
+}
@@ -0,0 +1,23 @@
+package concourse
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_isCgroupWorker(t *testing.T) {
+	pools := []config.WorkerPool{{Name: "docker"}}
+	tests := map[string]bool{
+		"worker/a1b2c3":         true,
+		"worker-docker/d4e5f6":  true,
+		"worker-windows/a1b2c3": false,
+		"web/a1b2c3":            false,
+	}
+	for name, want := range tests {
+		if got := isCgroupWorker(bosh.Instance{Name: name}, pools); got != want {
+			t.Errorf("isCgroupWorker(%s) = %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package concourse
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/EngineerBetter/control-tower/bastion"
+	"github.com/EngineerBetter/control-tower/commands/check"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// Check reports the health of the deployment, printing each check with whether it passed
+func (client *Client) Check(args check.Args) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	if !args.DB {
+		return nil
+	}
+
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	target, err := dbHealthTarget(conf, client.provider.IAAS(), tfOutputs)
+	if err != nil {
+		return err
+	}
+
+	dialer, closeDialer := client.dbHealthDialer(conf, tfOutputs)
+	defer closeDialer()
+
+	failed := 0
+	for _, result := range db.CheckHealth(dialer, target) {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(client.stdout, "FAIL  %s: %v\n", result.Name, result.Err)
+			continue
+		}
+		fmt.Fprintf(client.stdout, "PASS  %s: %s\n", result.Name, result.Detail)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d database check(s) failed", failed)
+	}
+	return nil
+}
+
+// dbHealthTarget is the database the director connects to, with the CA its certificate must be signed by
+func dbHealthTarget(conf config.ConfigView, iaasName iaas.Name, tfOutputs terraform.Outputs) (db.Target, error) {
+	if conf.GetExternalDBURL() != "" {
+		// The URL was validated when it was applied to the config
+		external, err := db.ParseExternalURL(conf.GetExternalDBURL())
+		if err != nil {
+			return db.Target{}, err
+		}
+		return db.Target{
+			Host:      external.Host,
+			Port:      external.Port,
+			Username:  external.Username,
+			Password:  external.Password,
+			Database:  external.Database,
+			CACert:    conf.GetExternalDBCACert(),
+			Databases: db.ComponentDatabaseNames(conf.GetDeployment(), true),
+		}, nil
+	}
+
+	address, err := tfOutputs.Get("BoshDBAddress")
+	if err != nil {
+		return db.Target{}, err
+	}
+	target := db.Target{
+		Host:      address,
+		Username:  conf.GetRDSUsername(),
+		Password:  conf.GetRDSPassword(),
+		CACert:    conf.GetDBCACert(),
+		Databases: db.ComponentDatabases,
+	}
+
+	if iaasName == iaas.GCP {
+		target.Port = "5432"
+		target.Database = "postgres"
+		if target.CACert == "" {
+			if target.CACert, err = tfOutputs.Get("SQLServerCert"); err != nil {
+				return db.Target{}, err
+			}
+		}
+		return target, nil
+	}
+
+	if target.Port, err = tfOutputs.Get("BoshDBPort"); err != nil {
+		return db.Target{}, err
+	}
+	target.Database = conf.GetRDSDefaultDatabaseName()
+	if target.CACert == "" {
+		target.CACert = db.RDSRootCA()
+	}
+	return target, nil
+}
+
+// dbHealthDialer connects to the database via the director, as the director does. When the director
+// can't be reached, which is likely when the database is down on AWS, the database is connected to
+// directly from where control-tower runs instead
+func (client *Client) dbHealthDialer(conf config.ConfigView, tfOutputs terraform.Outputs) (db.Dialer, func()) {
+	sshClient, err := dialDirector(conf, tfOutputs)
+	if err != nil {
+		fmt.Fprintf(client.stderr, "WARNING: failed to connect to the director, so the database is checked from here rather than from the director: [%v]\n", err)
+		return directDialer{}, func() {}
+	}
+	fmt.Fprintln(client.stdout, "Checking the database from the director")
+	return sshDialer{sshClient}, func() { sshClient.Close() }
+}
+
+// dialDirector connects to the director over SSH, through the bastion when the deployment has one
+func dialDirector(conf config.ConfigView, tfOutputs terraform.Outputs) (*ssh.Client, error) {
+	directorPublicIP, err := tfOutputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	jump, err := bastion.FromOutputs(conf, tfOutputs)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ssh.ParsePrivateKey([]byte(conf.GetPrivateKey()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key for bosh: [%v]", err)
+	}
+	return bastion.DialSSH(jump, net.JoinHostPort(directorPublicIP, "22"), &ssh.ClientConfig{
+		User:            "vcap",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		Timeout:         10 * time.Second,
+	})
+}
+
+// sshDialer connects through the director. Connections through it can't time out on their own, and rely
+// on the director's timeouts instead
+type sshDialer struct {
+	client *ssh.Client
+}
+
+func (d sshDialer) Dial(network, address string) (net.Conn, error) {
+	return d.client.Dial(network, address)
+}
+
+func (d sshDialer) DialTimeout(network, address string, _ time.Duration) (net.Conn, error) {
+	return d.client.Dial(network, address)
+}
+
+type directDialer struct{}
+
+func (directDialer) Dial(network, address string) (net.Conn, error) {
+	return net.Dial(network, address)
+}
+
+func (directDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, address, timeout)
+}
@@ -0,0 +1,73 @@
+package concourse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+func Test_dbHealthTarget(t *testing.T) {
+	conf := config.Config{RDSUsername: "admin", RDSPassword: "s3cret", RDSDefaultDatabaseName: "bosh_abc"}
+	awsOutputs := &terraform.AWSOutputs{
+		BoshDBAddress: terraform.MetadataStringValue{Value: "db.abc.eu-west-1.rds.amazonaws.com"},
+		BoshDBPort:    terraform.MetadataStringValue{Value: "5432"},
+	}
+	gcpOutputs := &terraform.GCPOutputs{
+		BoshDBAddress: terraform.MetadataStringValue{Value: "10.0.0.3"},
+		SQLServerCert: terraform.MetadataStringValue{Value: "CLOUDSQL CA"},
+	}
+
+	t.Run("RDS", func(t *testing.T) {
+		got, err := dbHealthTarget(conf, iaas.AWS, awsOutputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Host != "db.abc.eu-west-1.rds.amazonaws.com" || got.Port != "5432" || got.Database != "bosh_abc" || got.Username != "admin" {
+			t.Errorf("dbHealthTarget() = %+v", got)
+		}
+		if _, err = db.ParseCABundle(got.CACert); err != nil {
+			t.Errorf("dbHealthTarget() CA of RDS is invalid: %v", err)
+		}
+	})
+
+	t.Run("RDS with a fetched CA", func(t *testing.T) {
+		withCA := conf
+		withCA.DBCACert = "FETCHED CA"
+		got, err := dbHealthTarget(withCA, iaas.AWS, awsOutputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.CACert != "FETCHED CA" {
+			t.Errorf("dbHealthTarget() CACert = %q, want the fetched CA", got.CACert)
+		}
+	})
+
+	t.Run("CloudSQL", func(t *testing.T) {
+		got, err := dbHealthTarget(conf, iaas.GCP, gcpOutputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := db.Target{Host: "10.0.0.3", Port: "5432", Username: "admin", Password: "s3cret", Database: "postgres", CACert: "CLOUDSQL CA", Databases: db.ComponentDatabases}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("dbHealthTarget() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("External database", func(t *testing.T) {
+		external := config.Config{Deployment: "control-tower-ci", ExternalDBURL: "postgres://concourse:pw@db.example.com:6432/control_tower", ExternalDBCACert: "EXTERNAL CA"}
+		got, err := dbHealthTarget(external, iaas.AWS, awsOutputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := db.Target{Host: "db.example.com", Port: "6432", Username: "concourse", Password: "pw", Database: "control_tower", CACert: "EXTERNAL CA",
+			Databases: []string{"control_tower_ci_concourse_atc", "control_tower_ci_uaa", "control_tower_ci_credhub"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("dbHealthTarget() = %+v, want %+v", got, want)
+		}
+	})
+}
+
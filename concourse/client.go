@@ -1,19 +1,32 @@
 package concourse
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"time"
 
+	"github.com/EngineerBetter/control-tower/commands/check"
+	"github.com/EngineerBetter/control-tower/commands/destroy"
+	"github.com/EngineerBetter/control-tower/commands/drift"
+	"github.com/EngineerBetter/control-tower/commands/exportdeployment"
+	"github.com/EngineerBetter/control-tower/commands/importdeployment"
 	"github.com/EngineerBetter/control-tower/commands/maintain"
+	"github.com/EngineerBetter/control-tower/commands/restoredb"
 	"github.com/EngineerBetter/control-tower/credhub"
 
 	"github.com/EngineerBetter/control-tower/bosh"
 	"github.com/EngineerBetter/control-tower/certs"
 	"github.com/EngineerBetter/control-tower/commands/deploy"
 	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/dns"
 	"github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/siem"
 	"github.com/EngineerBetter/control-tower/terraform"
 
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/lego"
 )
 
@@ -21,7 +34,7 @@ import (
 type Client struct {
 	acmeClientConstructor func(u *certs.User) (*lego.Client, error)
 	boshClientFactory     bosh.ClientFactory
-	certGenerator         func(constructor func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error)
+	certGenerator         func(constructor func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, dnsProvider challenge.Provider, ip ...string) (*certs.Certs, error)
 	configClient          config.IClient
 	deployArgs            *deploy.Args
 	eightRandomLetters    func() string
@@ -41,10 +54,15 @@ type Client struct {
 
 // IClient represents a control-tower client
 type IClient interface {
+	Check(check.Args) error
 	Deploy() error
-	Destroy() error
+	Destroy(destroy.Args) error
+	Drift(drift.Args) error
+	ExportDeployment(exportdeployment.Args) error
 	FetchInfo() (*Info, error)
+	ImportDeployment(importdeployment.Args) error
 	Maintain(maintain.Args) error
+	RestoreDB(restoredb.Args) error
 }
 
 // New returns a new client
@@ -54,7 +72,7 @@ func NewClient(
 	tfInputVarsFactory TFInputVarsFactory,
 	boshClientFactory bosh.ClientFactory,
 	flyClientFactory func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error),
-	certGenerator func(constructor func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error),
+	certGenerator func(constructor func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, dnsProvider challenge.Provider, ip ...string) (*certs.Certs, error),
 	configClient config.IClient,
 	deployArgs *deploy.Args,
 	stdout, stderr io.Writer,
@@ -99,3 +117,46 @@ func (client *Client) buildBoshClient(config config.ConfigView, tfOutputs terraf
 		client.versionFile,
 	)
 }
+
+// sendAuditEvent records a command run against the deployment with the SIEM, if one is configured.
+// Failing to reach the SIEM is reported but doesn't fail the command.
+func (client *Client) sendAuditEvent(config config.ConfigView, action string, err error) {
+	if config.GetSIEMEndpoint() == "" {
+		return
+	}
+	event := siem.NewEvent(action, config.GetProject(), config.GetIAAS(), config.GetRegion(), client.version, err)
+	if sendErr := siem.Send(config.GetSIEMEndpoint(), event); sendErr != nil {
+		fmt.Fprintf(client.stderr, "WARNING: failed to send audit record to SIEM: [%v]\n", sendErr)
+	}
+}
+
+// dnsProvider returns the provider of the records for the deployment's domains, which is the IAAS's
+// own DNS unless --dns chose another
+func (client *Client) dnsProvider(conf config.ConfigView) (dns.Provider, error) {
+	name := conf.GetDNSProvider()
+	if name == "" {
+		name = dns.Cloud
+	}
+	return dns.New(name, conf.GetDNSZone(), dns.Options{
+		Cloud:          client.provider,
+		Stdin:          os.Stdin,
+		Stdout:         client.stdout,
+		NonInteractive: client.deployArgs != nil && client.deployArgs.NonInteractive,
+	})
+}
+
+// acquireDeploymentLock takes the lock in the config bucket that stops two control-tower processes
+// changing the deployment at once. A lock whose holder died is taken over once it has gone staleAfter
+// without a heartbeat and the director, if there is one yet, holds no locks of its own
+func (client *Client) acquireDeploymentLock(conf config.ConfigView, command string, staleAfter time.Duration) (*deploylock.Lock, error) {
+	return deploylock.Acquire(client.provider, conf.GetConfigBucket(), command, deploylock.Options{
+		StaleAfter: staleAfter,
+		InFlight: func() (bool, error) {
+			if conf.GetDirectorPublicIP() == "" {
+				return false, nil
+			}
+			return client.checkIfLocked()
+		},
+		Stderr: client.stderr,
+	})
+}
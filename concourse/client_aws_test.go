@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/lego"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -16,6 +18,7 @@ import (
 	"github.com/EngineerBetter/control-tower/certs"
 	"github.com/EngineerBetter/control-tower/certs/certsfakes"
 	"github.com/EngineerBetter/control-tower/commands/deploy"
+	"github.com/EngineerBetter/control-tower/commands/destroy"
 	"github.com/EngineerBetter/control-tower/concourse"
 	"github.com/EngineerBetter/control-tower/concourse/concoursefakes"
 	"github.com/EngineerBetter/control-tower/config"
@@ -53,12 +56,14 @@ var _ = Describe("client", func() {
 	var configClient *configfakes.FakeIClient
 	var boshClient *boshfakes.FakeIClient
 	var credhubClient *credhubfakes.FakeIClient
+	var awsClient *iaasfakes.FakeProvider
 
 	var setupFakeAwsProvider = func() *iaasfakes.FakeProvider {
 		provider := &iaasfakes.FakeProvider{}
 		provider.DBTypeReturns("db.t3.small")
 		provider.RegionReturns("eu-west-1")
 		provider.IAASReturns(iaas.AWS)
+		provider.WriteFileIfVersionReturns("1", true, nil)
 		provider.CheckForWhitelistedIPStub = func(ip, securityGroup string) (bool, error) {
 			actions = append(actions, "checking security group for IP")
 			if ip == "1.2.3.4" {
@@ -137,14 +142,14 @@ var _ = Describe("client", func() {
 	}
 
 	BeforeEach(func() {
-		certGenerator := func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error) {
+		certGenerator := func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, dnsProvider challenge.Provider, ip ...string) (*certs.Certs, error) {
 			actions = append(actions, fmt.Sprintf("generating cert ca: %s, cn: %s", caName, ip))
 			return &certs.Certs{
 				CACert: []byte("----EXAMPLE CERT----"),
 			}, nil
 		}
 
-		awsClient := setupFakeAwsProvider()
+		awsClient = setupFakeAwsProvider()
 		tfInputVarsFactory = setupFakeTfInputVarsFactory()
 		configClient = setupFakeConfigClient()
 
@@ -186,6 +191,7 @@ var _ = Describe("client", func() {
 			AvailabilityZone:         "eu-west-1a",
 			ConcoursePassword:        "s3cret",
 			ConcourseUsername:        "admin",
+			ConcourseWebCount:        1,
 			ConcourseWebSize:         "medium",
 			ConcourseWorkerCount:     1,
 			ConcourseWorkerSize:      "large",
@@ -197,6 +203,7 @@ var _ = Describe("client", func() {
 			DirectorRegistryPassword: "original-password",
 			DirectorUsername:         "admin",
 			EncryptionKey:            "123456789a123456789b123456789c",
+			MetricsScrapePassword:    "original-password",
 			IAAS:                     "AWS",
 			PersistentDisk:           "default",
 			PrivateKey:               privateKeyFixture,
@@ -301,40 +308,66 @@ var _ = Describe("client", func() {
 
 	Describe("Destroy", func() {
 		It("Loads the config file", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(actions).To(ContainElement("loading config file"))
 		})
 
 		It("Builds IAAS environment", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(tfInputVarsFactory.NewInputVarsCallCount()).To(Equal(1))
 			Expect(tfInputVarsFactory.NewInputVarsArgsForCall(0)).To(Equal(configInBucket))
 		})
 
 		It("Loads terraform output", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(actions).To(ContainElement("initializing terraform outputs"))
 		})
 
 		It("Deletes the vms in the vpcs", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(actions).To(ContainElement("deleting vms in vpc-112233"))
 		})
 
 		It("Destroys the terraform infrastructure", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(actions).To(ContainElement("destroying terraform"))
 		})
 
 		It("Deletes the config", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Expect(actions).To(ContainElement("deleting config"))
 		})
 
 		It("Prints a destroy success message", func() {
-			Expect(buildClient().Destroy()).To(Succeed())
+			Expect(buildClient().Destroy(destroy.Args{})).To(Succeed())
 			Eventually(stdout).Should(gbytes.Say("DESTROY SUCCESSFUL"))
 		})
+
+		Context("When another process holds the deployment lock", func() {
+			BeforeEach(func() {
+				awsClient.HasFileReturns(true, nil)
+				awsClient.LoadFileVersionReturns([]byte(fmt.Sprintf(`{"id":"other","user":"alice","host":"laptop","pid":42,"command":"deploy","heartbeat":%q}`, time.Now().UTC().Format(time.RFC3339))), "1", nil)
+			})
+
+			It("Returns an error naming the holder without destroying anything", func() {
+				err := buildClient().Destroy(destroy.Args{})
+				Expect(err).To(MatchError(ContainSubstring("failed to acquire lock on deployment, held by alice@laptop (pid 42) running deploy")))
+				Expect(actions).ToNot(ContainElement("destroying terraform"))
+			})
+		})
+
+		Context("When the database has deletion protection", func() {
+			BeforeEach(func() {
+				configInBucket.DBDeletionProtection = true
+			})
+
+			It("Returns an error without deleting anything", func() {
+				err := buildClient().Destroy(destroy.Args{})
+				Expect(err).To(MatchError(ContainSubstring("has deletion protection. Deploy again with --db-deletion-protection=false before destroying")))
+				Expect(actions).ToNot(ContainElement("deleting vms in vpc-112233"))
+				Expect(actions).ToNot(ContainElement("destroying terraform"))
+			})
+		})
 	})
 
 	Describe("FetchInfo", func() {
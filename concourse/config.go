@@ -5,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 
+	"github.com/EngineerBetter/control-tower/bosh"
 	"github.com/EngineerBetter/control-tower/commands/deploy"
 	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/EngineerBetter/control-tower/dns"
 	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/quota"
 	"github.com/asaskevich/govalidator"
 	"github.com/imdario/mergo"
 )
@@ -46,18 +51,188 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 			return config.Config{}, false, err
 		}
 
+		// The ranges of an existing network's subnets are whatever they were made with elsewhere
+		if conf.ExistingNetwork == "" {
+			if err = config.ValidateCidrRanges(client.provider.IAAS(), conf.NetworkCIDR, conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		previousPools := conf.WorkerPools
 		conf, isDomainUpdated, err = applyArgumentsToConfig(conf, client.deployArgs, client.provider)
 		if err != nil {
 			return config.Config{}, false, fmt.Errorf("error merging new options with existing config: [%v]", err)
 		}
+
+		if conf.ExistingNetwork != "" {
+			if conf, err = applyExistingNetwork(conf, client.deployArgs, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if client.deployArgs.ExternalDBURLIsSet {
+			if conf, err = applyExternalDB(conf, client.deployArgs, true); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if client.deployArgs.WorkerZonesIsSet {
+			if conf, err = applyWorkerZones(conf, client.deployArgs.WorkerZones, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if conf, err = applyNATTopology(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateBastion(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateVPCEndpoints(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateIPv6(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validatePeering(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateTransitGateway(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateWebAddress(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if conf, err = applyBastionKey(conf, client.sshGenerator); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateDedicatedVMs(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if client.deployArgs.WebSizeIsSet || client.deployArgs.WorkerSizeIsSet || client.deployArgs.WorkerZonesIsSet {
+			if err = validateZone(conf, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if client.deployArgs.WebInstanceTypeIsSet || client.deployArgs.WorkerInstanceTypeIsSet {
+			if err = validateInstanceTypes(conf, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if err = checkQuota(conf, client.deployArgs); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = checkGPUQuota(previousPools, conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
 	} else {
 		conf, _, err = applyArgumentsToConfig(defaultConf, client.deployArgs, client.provider)
 		if err != nil {
 			return config.Config{}, false, fmt.Errorf("error applying arguments to default config: [%v]", err)
 		}
 
+		// Deployments from before --worker-disk-type keep the pd-standard disks GCP gives by default
+		if conf.WorkerDiskType == "" && client.provider.IAAS() == iaas.GCP {
+			conf.WorkerDiskType = newGCPWorkerDiskType
+		}
+
+		// The flags are checked as given, so that a range given without the others is refused rather than
+		// replaced by the defaults
+		if err = config.ValidateCidrRanges(client.provider.IAAS(), client.deployArgs.NetworkCIDR, client.deployArgs.PublicCIDR, client.deployArgs.PrivateCIDR, client.deployArgs.RDS1CIDR, client.deployArgs.RDS2CIDR); err != nil {
+			return config.Config{}, false, err
+		}
+
 		conf = applyImmutableArgumentsToConfig(conf, client.deployArgs, client.provider)
 
+		if conf.ExistingNetwork != "" {
+			if conf, err = applyExistingNetwork(conf, client.deployArgs, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if conf.Private && conf.ExistingNetwork == "" && client.provider.IAAS() == iaas.AWS {
+			conf.NATCIDR, err = config.AllocateNATCIDR(conf.NetworkCIDR, []string{conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR})
+			if err != nil {
+				return config.Config{}, false, fmt.Errorf("error allocating a subnet for the NAT gateway: [%v]", err)
+			}
+		}
+
+		if client.deployArgs.ExternalDBURLIsSet {
+			if conf, err = applyExternalDB(conf, client.deployArgs, false); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if client.deployArgs.WorkerZonesIsSet {
+			if conf, err = applyWorkerZones(conf, client.deployArgs.WorkerZones, client.provider); err != nil {
+				return config.Config{}, false, err
+			}
+		}
+
+		if conf, err = applyNATTopology(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateBastion(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateVPCEndpoints(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateIPv6(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validatePeering(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateTransitGateway(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateWebAddress(conf); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if conf, err = applyBastionKey(conf, client.sshGenerator); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateDedicatedVMs(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateZone(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = validateInstanceTypes(conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = checkQuota(conf, client.deployArgs); err != nil {
+			return config.Config{}, false, err
+		}
+
+		if err = checkGPUQuota(nil, conf, client.provider); err != nil {
+			return config.Config{}, false, err
+		}
+
 		err = client.configClient.Update(conf)
 		if err != nil {
 			return config.Config{}, false, fmt.Errorf("error persisting new config after setting values [%v]", err)
@@ -79,6 +254,40 @@ func assertImmutableFieldsNotChanging(deployArgs *deploy.Args, conf config.Confi
 		return fmt.Errorf("Existing deployment uses zone %s and cannot change to zone %s", conf.GetAvailabilityZone(), deployArgs.Zone)
 	}
 
+	// Deployments that never chose a DB engine run an RDS for PostgreSQL instance
+	engine := conf.GetDBEngine()
+	if engine == "" {
+		engine = config.DB_ENGINE_POSTGRES
+	}
+	if deployArgs.DBEngineIsSet && deployArgs.DBEngine != engine {
+		return fmt.Errorf("Existing deployment uses DB engine %s and cannot change to DB engine %s", engine, deployArgs.DBEngine)
+	}
+
+	if err := checkDBVersionChange(deployArgs, conf); err != nil {
+		return err
+	}
+
+	if deployArgs.DBKMSKeyIsSet && deployArgs.DBKMSKey != conf.GetDBKMSKey() {
+		return fmt.Errorf("Existing deployment's database is encrypted with %q and cannot change to be encrypted with %q", conf.GetDBKMSKey(), deployArgs.DBKMSKey)
+	}
+
+	if deployArgs.DBIAMAuthIsSet && deployArgs.DBIAMAuth != conf.GetDBIAMAuth() {
+		return errors.New("--db-iam-auth can only be set on the initial deploy, as the existing databases are owned by the role that logs in with a password")
+	}
+
+	if deployArgs.ExistingNetworkFlagsSet() {
+		if deployArgs.ExistingNetworkName() != conf.GetExistingNetwork() ||
+			deployArgs.ExistingPublicSubnet != conf.GetExistingPublicSubnet() ||
+			deployArgs.ExistingPrivateSubnet != conf.GetExistingPrivateSubnet() ||
+			strings.Join(deployArgs.ExistingDBSubnets, ",") != strings.Join(conf.GetExistingDBSubnets(), ",") {
+			return errors.New("the existing network and subnets that the deployment is placed in cannot change after initial deploy")
+		}
+	}
+
+	if deployArgs.PrivateIsSet && deployArgs.Private != conf.GetPrivate() {
+		return errors.New("a deployment cannot be made private, or given public addresses, after initial deploy")
+	}
+
 	if deployArgs.RDSDiskEncryption != conf.GetRDSDiskEncryption() {
 		return fmt.Errorf("The disk encryption cannot be changed after initial deploy!")
 	}
@@ -96,6 +305,7 @@ func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, pass
 
 	conf.AvailabilityZone = ""
 	conf.ConcourseWebSize = "small"
+	conf.ConcourseWebCount = 1
 	conf.ConcourseWorkerCount = 1
 	conf.ConcourseWorkerSize = "xlarge"
 	conf.DirectorHMUserPassword = passwordGenerator(defaultPasswordLength)
@@ -105,6 +315,7 @@ func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, pass
 	conf.DirectorRegistryPassword = passwordGenerator(defaultPasswordLength)
 	conf.DirectorUsername = "admin"
 	conf.EncryptionKey = passwordGenerator(32)
+	conf.MetricsScrapePassword = passwordGenerator(defaultPasswordLength)
 	conf.IAAS = provider.IAAS().String()
 	conf.PersistentDisk = "default"
 	conf.PrivateKey = strings.TrimSpace(string(privateKey))
@@ -158,18 +369,90 @@ func applyArgumentsToConfig(conf config.Config, deployArgs *deploy.Args, provide
 	if deployArgs.WorkerSizeIsSet {
 		conf.ConcourseWorkerSize = deployArgs.WorkerSize
 	}
+	if deployArgs.UpgradeBatchSizeIsSet {
+		conf.UpgradeBatchSize = deployArgs.UpgradeBatchSize
+	}
+	if deployArgs.SoakMinutesIsSet {
+		conf.SoakMinutes = deployArgs.SoakMinutes
+	}
+	if deployArgs.SoakMaxErrorRateIncreaseIsSet || conf.SoakMaxErrorRateIncrease == 0 {
+		conf.SoakMaxErrorRateIncrease = deployArgs.SoakMaxErrorRateIncrease
+	}
 	if deployArgs.WebSizeIsSet {
 		conf.ConcourseWebSize = deployArgs.WebSize
 	}
+	if deployArgs.WebCountIsSet {
+		conf.ConcourseWebCount = deployArgs.WebCount
+	}
 	if deployArgs.PersistentDiskIsSet {
 		conf.PersistentDisk = deployArgs.PersistentDiskSize
 	}
 	if deployArgs.DBSizeIsSet {
-		conf.RDSInstanceClass = provider.DBType(deployArgs.DBSize)
+		class := provider.DBType(deployArgs.DBSize)
+		if err = checkDBResize(deployArgs, conf, provider.IAAS(), class); err != nil {
+			return config.Config{}, false, err
+		}
+		// conf.Version is only set once a deploy has been completed, before which there is no instance to switch over from
+		conf.DBBlueGreenUpdate = deployArgs.DBResize == deploy.DBResizeBlueGreen && conf.Version != "" && class != conf.RDSInstanceClass
+		if conf.DBBlueGreenUpdate {
+			conf.DBResizedFrom = conf.RDSInstanceClass
+		}
+		conf.RDSInstanceClass = class
+	}
+	if conf, err = applyDBEngine(conf, deployArgs); err != nil {
+		return config.Config{}, false, err
+	}
+	if deployArgs.DBVersionIsSet {
+		conf.DBVersion = deployArgs.DBVersion
+	}
+	if conf, err = applyDBStorage(conf, deployArgs); err != nil {
+		return config.Config{}, false, err
+	}
+	if conf, err = applyDBBackups(conf, deployArgs); err != nil {
+		return config.Config{}, false, err
+	}
+	if deployArgs.DBParamsIsSet {
+		if conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-param has no effect on a deployment with an external database")
+		}
+		conf.DBParams = deployArgs.DBParams
+	}
+	if deployArgs.DBHAIsSet {
+		if deployArgs.DBHA && conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-ha has no effect on a deployment with an external database")
+		}
+		conf.DBHA = deployArgs.DBHA
+	}
+	if deployArgs.DBMultiAZIsSet {
+		if deployArgs.DBMultiAZ && conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-multi-az has no effect on a deployment with an external database")
+		}
+		if deployArgs.DBMultiAZ && conf.DBEngine == config.DB_ENGINE_AURORA_SERVERLESS {
+			return config.Config{}, false, errors.New("--db-multi-az applies to an RDS instance, while Aurora Serverless already keeps its storage in several zones")
+		}
+		conf.DBMultiAZ = deployArgs.DBMultiAZ
+	}
+	if deployArgs.DBReadReplicaIsSet {
+		if deployArgs.DBReadReplica && conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-read-replica has no effect on a deployment with an external database")
+		}
+		conf.DBReadReplica = deployArgs.DBReadReplica
+	}
+	if deployArgs.DBKMSKeyIsSet {
+		if conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-kms-key has no effect on a deployment with an external database")
+		}
+		conf.DBKMSKey = deployArgs.DBKMSKey
 	}
 	if deployArgs.RDSDiskEncryptionIsSet {
 		conf.RDSDiskEncryption = deployArgs.RDSDiskEncryption
 	}
+	if deployArgs.DBIAMAuthIsSet {
+		if conf.ExternalDBURL != "" {
+			return config.Config{}, false, errors.New("--db-iam-auth has no effect on a deployment with an external database")
+		}
+		conf.DBIAMAuth = deployArgs.DBIAMAuth
+	}
 	if deployArgs.BitbucketAuthIsSet {
 		conf.BitbucketClientID = deployArgs.BitbucketAuthClientID
 		conf.BitbucketClientSecret = deployArgs.BitbucketAuthClientSecret
@@ -192,120 +475,1533 @@ func applyArgumentsToConfig(conf config.Config, deployArgs *deploy.Args, provide
 		conf.MicrosoftClientSecret = deployArgs.MicrosoftAuthClientSecret
 		conf.MicrosoftTenant = deployArgs.MicrosoftAuthTenant
 	}
-	if deployArgs.NoMetricsIsSet {
-		conf.NoMetrics = deployArgs.NoMetrics
+	if deployArgs.OIDCAuthIsSet {
+		conf.OIDCIssuer = deployArgs.OIDCIssuer
+		conf.OIDCClientID = deployArgs.OIDCClientID
+		conf.OIDCClientSecret = deployArgs.OIDCClientSecret
+		conf.OIDCGroupsClaim = deployArgs.OIDCGroupsClaim
+		conf.OIDCUsernameClaim = deployArgs.OIDCUsernameClaim
 	}
-	if deployArgs.TagsIsSet {
-		conf.Tags = deployArgs.Tags
+	if deployArgs.OAuthAuthIsSet {
+		conf.OAuthAuthURL = deployArgs.OAuthAuthURL
+		conf.OAuthTokenURL = deployArgs.OAuthTokenURL
+		conf.OAuthUserinfoURL = deployArgs.OAuthUserinfoURL
+		conf.OAuthClientID = deployArgs.OAuthClientID
+		conf.OAuthClientSecret = deployArgs.OAuthClientSecret
+		conf.OAuthScopes = splitList(deployArgs.OAuthScopes)
+		conf.OAuthGroupsKey = deployArgs.OAuthGroupsKey
 	}
-	if deployArgs.SpotIsSet {
-		conf.VMProvisioningType = config.ConvertSpotBoolToVMProvisioningType(deployArgs.Spot)
+	if deployArgs.CFAuthIsSet {
+		conf.CFAuthAPIURL = deployArgs.CFAuthAPIURL
+		conf.CFAuthClientID = deployArgs.CFAuthClientID
+		conf.CFAuthClientSecret = deployArgs.CFAuthClientSecret
+		conf.CFAuthCACert = deployArgs.CFAuthCACert
 	}
-	if deployArgs.WorkerTypeIsSet {
-		conf.WorkerType = deployArgs.WorkerType
+	if deployArgs.MainCFOrgsIsSet {
+		conf.MainCFOrgs = splitList(deployArgs.MainCFOrgs)
 	}
-
-	if deployArgs.EnableGlobalResourcesIsSet {
-		conf.EnableGlobalResources = deployArgs.EnableGlobalResources
+	if deployArgs.MainCFSpacesIsSet {
+		conf.MainCFSpaces = splitList(deployArgs.MainCFSpaces)
 	}
-	if deployArgs.EnablePipelineInstancesIsSet {
-		conf.EnablePipelineInstances = deployArgs.EnablePipelineInstances
+	if deployArgs.InternalLBIsSet {
+		conf.InternalLB = deployArgs.InternalLB
 	}
-
-	// Flag has default value, hence it's always set.
-	conf.InfluxDbRetention = deployArgs.InfluxDbRetention
-
-	var isDomainUpdated bool
-	if deployArgs.DomainIsSet {
-		if conf.Domain != deployArgs.Domain {
-			isDomainUpdated = true
+	if deployArgs.InternalLBAllowIPsIsSet {
+		internalAllow, err := parseAllowedIPsCIDRs(deployArgs.InternalLBAllowIPs)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error determining IP addresses to allow internal load balancer access from: [%v]", err)
 		}
-		conf.Domain = deployArgs.Domain
-	} else {
-		if govalidator.IsIPv4(conf.Domain) {
-			conf.Domain = ""
+		if conf.InternalLBAllowIPs, err = internalAllow.String(); err != nil {
+			return config.Config{}, false, err
 		}
 	}
-
-	return conf, isDomainUpdated, nil
-}
-
-// Set config fields that are only valid on first deployment
-func applyImmutableArgumentsToConfig(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) config.Config {
-	if hasCIDRFlagsSet(deployArgs, provider) {
-		conf = populateConfigWithDeployArgsCIDRs(conf, deployArgs, provider)
+	if deployArgs.BastionIsSet {
+		conf.Bastion = deployArgs.Bastion
 	}
-
-	conf.AvailabilityZone = provider.Zone(deployArgs.Zone, conf.ConcourseWorkerSize)
-	return conf
-}
-
-func hasCIDRFlagsSet(deployArgs *deploy.Args, provider iaas.Provider) bool {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		return deployArgs.NetworkCIDRIsSet && deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
-	case iaas.GCP:
-		return deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
-	default:
-		return false
+	if deployArgs.BastionAllowIPsIsSet {
+		bastionAllow, err := parseAllowedIPsCIDRs(deployArgs.BastionAllowIPs)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error determining IP addresses to allow SSH to the bastion from: [%v]", err)
+		}
+		if conf.BastionAllowIPs, err = bastionAllow.String(); err != nil {
+			return config.Config{}, false, err
+		}
 	}
-}
-
-func populateConfigWithDeployArgsCIDRs(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) config.Config {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		conf.NetworkCIDR = deployArgs.NetworkCIDR
-		conf.PublicCIDR = deployArgs.PublicCIDR
-		conf.PrivateCIDR = deployArgs.PrivateCIDR
-		conf.RDS1CIDR = deployArgs.RDS1CIDR
-		conf.RDS2CIDR = deployArgs.RDS2CIDR
-	case iaas.GCP:
-		conf.PublicCIDR = deployArgs.PublicCIDR
-		conf.PrivateCIDR = deployArgs.PrivateCIDR
+	if deployArgs.VPCEndpointsIsSet {
+		conf.VPCEndpoints = deployArgs.VPCEndpoints
 	}
-	return conf
-}
-
-func populateConfigWithDefaultCIDRs(conf config.Config, provider iaas.Provider) config.Config {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		conf.NetworkCIDR = "10.0.0.0/16"
-		conf.PrivateCIDR = "10.0.1.0/24"
-		conf.PublicCIDR = "10.0.0.0/24"
-		conf.RDS1CIDR = "10.0.4.0/24"
-		conf.RDS2CIDR = "10.0.5.0/24"
-	case iaas.GCP:
-		conf.PrivateCIDR = "10.0.1.0/24"
-		conf.PublicCIDR = "10.0.0.0/24"
+	if deployArgs.NATTopologyIsSet {
+		conf.NATTopology = deployArgs.NATTopology
 	}
-	return conf
-}
-
-func getUpdatedAllowedIPs(ingressAddresses cidrBlocks) (string, error) {
-	addr, err := ingressAddresses.String()
-	if err != nil {
-		return "", err
+	if deployArgs.IPv6IsSet {
+		conf.IPv6 = deployArgs.IPv6
+		if conf.IPv6 && conf.AllowIPv6s == "" {
+			conf.AllowIPv6s = `"::/0"`
+		}
 	}
-	return addr, nil
-}
-
-type cidrBlocks []*net.IPNet
-
-func parseAllowedIPsCIDRs(s string) (cidrBlocks, error) {
-	var x cidrBlocks
-	for _, ip := range strings.Split(s, ",") {
-		ip = strings.TrimSpace(ip)
-		_, ipNet, err := net.ParseCIDR(ip)
-		if err != nil {
-			ipNet = &net.IPNet{
-				IP:   net.ParseIP(ip),
-				Mask: net.CIDRMask(32, 32),
-			}
+	if deployArgs.PeerVPCIDsIsSet {
+		conf.PeerVPCIDs = nonEmpty(deployArgs.PeerVPCIDs)
+	}
+	if deployArgs.PeerNetworksIsSet {
+		conf.PeerNetworks = nonEmpty(deployArgs.PeerNetworks)
+	}
+	if deployArgs.TransitGatewayIDIsSet {
+		conf.TransitGatewayID = deployArgs.TransitGatewayID
+		if conf.TransitGatewayID == "" {
+			conf.TransitGatewayRoutes = nil
+			conf.TransitGatewayRouteTableID = ""
+			conf.NoTransitGatewayPropagation = false
 		}
-		if ipNet.IP == nil {
-			return nil, fmt.Errorf("could not parse %q as an IP address or CIDR range", ip)
+	}
+	if deployArgs.TransitGatewayRoutesIsSet {
+		conf.TransitGatewayRoutes = nonEmpty(deployArgs.TransitGatewayRoutes)
+	}
+	if deployArgs.TransitGatewayRouteTableIDIsSet {
+		conf.TransitGatewayRouteTableID = deployArgs.TransitGatewayRouteTableID
+	}
+	if deployArgs.NoTransitGatewayPropagationIsSet {
+		conf.NoTransitGatewayPropagation = deployArgs.NoTransitGatewayPropagation
+	}
+	if deployArgs.NCCHubIsSet {
+		conf.NCCHub = deployArgs.NCCHub
+		if conf.NCCHub == "" {
+			conf.NCCExcludeExportRanges = nil
+		} else {
+			conf.GoogleBetaProvider = true
+		}
+	}
+	if deployArgs.NCCExcludeExportRangesIsSet {
+		conf.NCCExcludeExportRanges = nonEmpty(deployArgs.NCCExcludeExportRanges)
+	}
+	for _, allowList := range []struct {
+		isSet       bool
+		value       string
+		field       *string
+		description string
+	}{
+		{deployArgs.WebAllowIPsIsSet, deployArgs.WebAllowIPs, &conf.WebAllowIPs, "the web UI"},
+		{deployArgs.DirectorAllowIPsIsSet, deployArgs.DirectorAllowIPs, &conf.DirectorAllowIPs, "the director"},
+		{deployArgs.SSHAllowIPsIsSet, deployArgs.SSHAllowIPs, &conf.SSHAllowIPs, "SSH"},
+		{deployArgs.MetricsAllowIPsIsSet, deployArgs.MetricsAllowIPs, &conf.MetricsAllowIPs, "Grafana"},
+	} {
+		if !allowList.isSet {
+			continue
+		}
+		// An empty list falls back to the list it defaults to
+		if allowList.value == "" {
+			*allowList.field = ""
+			continue
+		}
+		allow, err := parseAllowedIPsCIDRs(allowList.value)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error determining IP addresses to allow access to %s from: [%v]", allowList.description, err)
+		}
+		if *allowList.field, err = allow.String(); err != nil {
+			return config.Config{}, false, err
+		}
+	}
+	if deployArgs.WebEIPIsSet {
+		conf.WebEIP = deployArgs.WebEIP
+	}
+	if deployArgs.WebAddressNameIsSet {
+		conf.WebAddressName = deployArgs.WebAddressName
+	}
+	if deployArgs.AllowIPv6sIsSet {
+		ipv6Allow, err := parseAllowedIPv6CIDRs(deployArgs.AllowIPv6s)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error determining IPv6 addresses to allow access from: [%v]", err)
+		}
+		if conf.AllowIPv6s, err = ipv6Allow.String(); err != nil {
+			return config.Config{}, false, err
+		}
+	}
+	if deployArgs.InternalLBTLSCertIsSet {
+		conf.InternalLBTLSCert = deployArgs.InternalLBTLSCert
+		conf.InternalLBTLSKey = deployArgs.InternalLBTLSKey
+	}
+	if deployArgs.InternalLBHealthCheckPathIsSet {
+		conf.InternalLBHealthCheckPath = deployArgs.InternalLBHealthCheckPath
+	}
+	if deployArgs.InternalLBHealthCheckIntervalIsSet {
+		conf.InternalLBHealthCheckInterval = deployArgs.InternalLBHealthCheckInterval
+	}
+	if deployArgs.InternalLBHealthyThresholdIsSet {
+		conf.InternalLBHealthyThreshold = deployArgs.InternalLBHealthyThreshold
+	}
+	if deployArgs.InternalLBUnhealthyThresholdIsSet {
+		conf.InternalLBUnhealthyThreshold = deployArgs.InternalLBUnhealthyThreshold
+	}
+	if deployArgs.LDAPAuthIsSet {
+		conf.LDAPHost = deployArgs.LDAPHost
+		conf.LDAPBindDN = deployArgs.LDAPBindDN
+		conf.LDAPBindPassword = deployArgs.LDAPBindPassword
+		conf.LDAPCACert = deployArgs.LDAPCACert
+		conf.LDAPUserSearchBaseDN = deployArgs.LDAPUserSearchBaseDN
+		conf.LDAPUserSearchFilter = deployArgs.LDAPUserSearchFilter
+		conf.LDAPUserSearchUsername = deployArgs.LDAPUserSearchUsername
+		conf.LDAPGroupSearchBaseDN = deployArgs.LDAPGroupSearchBaseDN
+		conf.LDAPGroupSearchFilter = deployArgs.LDAPGroupSearchFilter
+		conf.LDAPGroupSearchNameAttr = deployArgs.LDAPGroupSearchNameAttr
+	}
+	if deployArgs.SAMLMetadataURLIsSet {
+		conf.SAMLMetadataURL = deployArgs.SAMLMetadataURL
+		conf.SAMLCACert = deployArgs.SAMLCACert
+		conf.SAMLUsernameAttr = deployArgs.SAMLUsernameAttr
+		conf.SAMLEmailAttr = deployArgs.SAMLEmailAttr
+		conf.SAMLGroupsAttr = deployArgs.SAMLGroupsAttr
+	}
+	if deployArgs.NoMetricsIsSet {
+		conf.NoMetrics = deployArgs.NoMetrics
+	}
+	if deployArgs.EnablePrometheusMetricsIsSet {
+		conf.EnablePrometheusMetrics = deployArgs.EnablePrometheusMetrics
+	}
+	if deployArgs.MetricsScrapeAllowIPsIsSet {
+		conf.MetricsScrapeAllowIPs = nil
+		if deployArgs.MetricsScrapeAllowIPs != "" {
+			scrapeAllow, err := parseAllowedIPsCIDRs(deployArgs.MetricsScrapeAllowIPs)
+			if err != nil {
+				return config.Config{}, false, fmt.Errorf("error determining IP addresses to allow metrics scraping from: [%v]", err)
+			}
+			for _, ipNet := range scrapeAllow {
+				conf.MetricsScrapeAllowIPs = append(conf.MetricsScrapeAllowIPs, ipNet.String())
+			}
+		}
+	}
+	if deployArgs.ExternalWorkerAllowIPsIsSet {
+		conf.ExternalWorkerAllowIPs = nil
+		if deployArgs.ExternalWorkerAllowIPs != "" {
+			workerAllow, err := parseAllowedIPsCIDRs(deployArgs.ExternalWorkerAllowIPs)
+			if err != nil {
+				return config.Config{}, false, fmt.Errorf("error determining IP addresses to allow external workers from: [%v]", err)
+			}
+			for _, ipNet := range workerAllow {
+				conf.ExternalWorkerAllowIPs = append(conf.ExternalWorkerAllowIPs, ipNet.String())
+			}
+		}
+	}
+	if deployArgs.ManagedPrometheusIsSet {
+		conf.ManagedPrometheus = deployArgs.ManagedPrometheus
+		conf.ManagedPrometheusURL = deployArgs.ManagedPrometheusWorkspaceURL
+		conf.ManagedPrometheusRegion = conf.Region
+		if m := deploy.AMPRemoteWriteURLPattern.FindStringSubmatch(conf.ManagedPrometheusURL); m != nil {
+			conf.ManagedPrometheusRegion = m[1]
+		}
+	}
+	if deployArgs.SIEMEndpointIsSet {
+		conf.SIEMEndpoint = deployArgs.SIEMEndpoint
+	}
+	if deployArgs.ConcourseVersionIsSet && deployArgs.ConcourseVersion != conf.ConcourseVersion {
+		conf.ConcourseVersion = deployArgs.ConcourseVersion
+		// looked up again for the new release when it is deployed
+		conf.ConcourseReleaseSHA1 = ""
+	}
+	if deployArgs.ContainerPlacementStrategiesIsSet {
+		conf.ContainerPlacementStrategies = deployArgs.ContainerPlacementStrategies
+	}
+	if deployArgs.MaxActiveTasksPerWorkerIsSet {
+		conf.MaxActiveTasksPerWorker = deployArgs.MaxActiveTasksPerWorker
+	}
+	if deployArgs.MaxActiveContainersPerWorkerIsSet {
+		conf.MaxActiveContainersPerWorker = deployArgs.MaxActiveContainersPerWorker
+	}
+	if deployArgs.MaxActiveVolumesPerWorkerIsSet {
+		conf.MaxActiveVolumesPerWorker = deployArgs.MaxActiveVolumesPerWorker
+	}
+	if deployArgs.DefaultTaskCPULimitIsSet {
+		conf.DefaultTaskCPULimit = deployArgs.DefaultTaskCPULimit
+	}
+	if deployArgs.DefaultTaskMemoryLimitIsSet {
+		conf.DefaultTaskMemoryLimit = deployArgs.DefaultTaskMemoryLimit
+	}
+	if deployArgs.InterceptIdleTimeoutIsSet {
+		conf.InterceptIdleTimeout = deployArgs.InterceptIdleTimeout
+	}
+	if deployArgs.FailedGracePeriodIsSet {
+		conf.FailedGracePeriod = deployArgs.FailedGracePeriod
+	}
+	if deployArgs.EnableP2PVolumeStreamingIsSet {
+		conf.EnableP2PVolumeStreaming = deployArgs.EnableP2PVolumeStreaming
+	}
+	if deployArgs.StreamingCompressionIsSet {
+		conf.StreamingCompression = deployArgs.StreamingCompression
+	}
+	if deployArgs.ConcourseWebEnvIsSet {
+		conf.ConcourseWebEnv = deployArgs.ConcourseWebEnv
+	}
+	if deployArgs.ConcourseWorkerEnvIsSet {
+		conf.ConcourseWorkerEnv = deployArgs.ConcourseWorkerEnv
+	}
+	if deployArgs.ExternalURLIsSet {
+		conf.ExternalURL = strings.TrimSuffix(deployArgs.ExternalURL, "/")
+	}
+	if deployArgs.SyslogAddressIsSet {
+		conf.SyslogAddress = deployArgs.SyslogAddress
+		if conf.SyslogAddress == "" {
+			conf.SyslogTransport = ""
+			conf.SyslogCACert = ""
+		}
+	}
+	if deployArgs.SyslogTransportIsSet {
+		conf.SyslogTransport = deployArgs.SyslogTransport
+	}
+	if deployArgs.SyslogCACertIsSet {
+		conf.SyslogCACert = deployArgs.SyslogCACert
+	}
+	if conf.SyslogAddress != "" && conf.SyslogTransport == "" {
+		conf.SyslogTransport = "tcp"
+	}
+	if conf.SyslogAddress == "" && (deployArgs.SyslogTransportIsSet || conf.SyslogCACert != "") {
+		return config.Config{}, false, errors.New("--syslog-transport and --syslog-ca-cert require --syslog-address to also be provided")
+	}
+	if conf.SyslogCACert != "" && conf.SyslogTransport != "tls" {
+		return config.Config{}, false, errors.New("--syslog-ca-cert requires --syslog-transport tls")
+	}
+	if deployArgs.NewRelicAccountIDIsSet {
+		conf.NewRelicAccountID = deployArgs.NewRelicAccountID
+		if conf.NewRelicAccountID == "" {
+			conf.NewRelicInsertKey = ""
+			conf.NewRelicInsightsURL = ""
+			conf.NewRelicServicePrefix = ""
+			conf.NewRelicBatchSize = 0
+			conf.NewRelicBatchDuration = ""
+		}
+	}
+	if deployArgs.NewRelicInsertKeyIsSet {
+		conf.NewRelicInsertKey = deployArgs.NewRelicInsertKey
+	}
+	if deployArgs.NewRelicInsightsURLIsSet {
+		conf.NewRelicInsightsURL = strings.TrimSuffix(deployArgs.NewRelicInsightsURL, "/")
+	}
+	if deployArgs.NewRelicServicePrefixIsSet {
+		conf.NewRelicServicePrefix = deployArgs.NewRelicServicePrefix
+	}
+	if deployArgs.NewRelicBatchSizeIsSet {
+		conf.NewRelicBatchSize = deployArgs.NewRelicBatchSize
+	}
+	if deployArgs.NewRelicBatchDurationIsSet {
+		conf.NewRelicBatchDuration = deployArgs.NewRelicBatchDuration
+	}
+	if conf.NewRelicAccountID != "" {
+		if conf.NewRelicInsertKey == "" {
+			return config.Config{}, false, errors.New("--newrelic-account-id requires --newrelic-insert-key to also be provided")
+		}
+		if conf.NewRelicInsightsURL == "" {
+			conf.NewRelicInsightsURL = "https://insights-collector.newrelic.com"
+		}
+		if conf.NewRelicBatchSize == 0 {
+			conf.NewRelicBatchSize = 2000
+		}
+		if conf.NewRelicBatchDuration == "" {
+			conf.NewRelicBatchDuration = "60s"
+		}
+	} else if deployArgs.NewRelicInsertKeyIsSet || deployArgs.NewRelicInsightsURLIsSet || deployArgs.NewRelicServicePrefixIsSet || deployArgs.NewRelicBatchSizeIsSet || deployArgs.NewRelicBatchDurationIsSet {
+		return config.Config{}, false, errors.New("the --newrelic-* flags require --newrelic-account-id to also be provided")
+	}
+	if deployArgs.InfluxDbURLIsSet {
+		conf.InfluxDbURL = deployArgs.InfluxDbURL
+		if conf.InfluxDbURL == "" {
+			conf.InfluxDbDatabase = ""
+			conf.InfluxDbUsername = ""
+			conf.InfluxDbPassword = ""
+			conf.InfluxDbBatchSize = 0
+		}
+	}
+	if deployArgs.InfluxDbDatabaseIsSet {
+		conf.InfluxDbDatabase = deployArgs.InfluxDbDatabase
+	}
+	if deployArgs.InfluxDbUsernameIsSet {
+		conf.InfluxDbUsername = deployArgs.InfluxDbUsername
+	}
+	if deployArgs.InfluxDbPasswordIsSet {
+		conf.InfluxDbPassword = deployArgs.InfluxDbPassword
+	}
+	if deployArgs.InfluxDbBatchSizeIsSet {
+		conf.InfluxDbBatchSize = deployArgs.InfluxDbBatchSize
+	}
+	if conf.InfluxDbURL != "" {
+		if conf.InfluxDbDatabase == "" {
+			conf.InfluxDbDatabase = "concourse"
+		}
+		if conf.InfluxDbBatchSize == 0 {
+			conf.InfluxDbBatchSize = 5000
+		}
+	} else if deployArgs.InfluxDbDatabaseIsSet || deployArgs.InfluxDbUsernameIsSet || deployArgs.InfluxDbPasswordIsSet || deployArgs.InfluxDbBatchSizeIsSet {
+		return config.Config{}, false, errors.New("the --influxdb-* flags require --influxdb-url to also be provided")
+	}
+	if deployArgs.OPAURLIsSet {
+		conf.OPAURL = strings.TrimSuffix(deployArgs.OPAURL, "/")
+		if conf.OPAURL == "" {
+			conf.OPAPolicyPath = ""
+			conf.PolicyCheckActions = nil
+		}
+	}
+	if deployArgs.OPAPolicyPathIsSet {
+		conf.OPAPolicyPath = deployArgs.OPAPolicyPath
+	}
+	if deployArgs.PolicyCheckActionsIsSet {
+		conf.PolicyCheckActions = deployArgs.PolicyCheckActions
+	}
+	if conf.OPAURL == "" && (conf.OPAPolicyPath != "" || len(conf.PolicyCheckActions) > 0) {
+		return config.Config{}, false, errors.New("--opa-policy-path and --policy-check-action require --opa-url to also be provided")
+	}
+	if conf.OPAURL != "" && conf.OPAPolicyPath == "" {
+		conf.OPAPolicyPath = "concourse/decision"
+	}
+	if conf.OPAURL != "" && len(conf.PolicyCheckActions) == 0 {
+		conf.PolicyCheckActions = []string{"SaveConfig"}
+	}
+	if deployArgs.EnableAuditLogsIsSet || deployArgs.AuditLogsIsSet {
+		auditLogs, err := applyAuditLogArgs(conf.AuditLogs, deployArgs)
+		if err != nil {
+			return config.Config{}, false, err
+		}
+		conf.AuditLogs = auditLogs
+	}
+	if err := validateContainerPlacementLimits(conf); err != nil {
+		return config.Config{}, false, err
+	}
+	if conf.ManagedPrometheus && conf.NoMetrics {
+		return config.Config{}, false, errors.New("--managed-prometheus sends metrics from the colocated metrics stack, which this deployment has disabled with --no-metrics")
+	}
+	if conf.ManagedPrometheus && conf.InfluxDbURL != "" {
+		return config.Config{}, false, errors.New("--managed-prometheus sends metrics from the colocated metrics stack, which --influxdb-url replaces")
+	}
+	if err := validateWebCount(conf); err != nil {
+		return config.Config{}, false, err
+	}
+	if err := validateWorkerPools(conf); err != nil {
+		return config.Config{}, false, err
+	}
+	if len(conf.MetricsScrapeAllowIPs) > 0 && (conf.NoMetrics || conf.InfluxDbURL != "") {
+		return config.Config{}, false, errors.New("--metrics-scrape-allow-ips serves metrics from the colocated metrics stack, which this deployment has disabled with --no-metrics or replaced with --influxdb-url")
+	}
+	if deployArgs.TagsIsSet {
+		conf.Tags = deployArgs.Tags
+	}
+	if deployArgs.SpotIsSet {
+		conf.VMProvisioningType = config.ConvertSpotBoolToVMProvisioningType(deployArgs.Spot)
+	}
+	if deployArgs.DedicatedWebIsSet {
+		conf.DedicatedWeb = deployArgs.DedicatedWeb
+	}
+	if deployArgs.DedicatedWorkersIsSet {
+		conf.DedicatedWorkers = deployArgs.DedicatedWorkers
+	}
+	if deployArgs.SoleTenantNodeTypeIsSet {
+		conf.SoleTenantNodeType = deployArgs.SoleTenantNodeType
+	}
+	if deployArgs.SoleTenantNodeCountIsSet {
+		conf.SoleTenantNodeCount = deployArgs.SoleTenantNodeCount
+	}
+	if (conf.DedicatedWeb || conf.DedicatedWorkers) && provider.IAAS() == iaas.GCP {
+		if conf.SoleTenantNodeType == "" {
+			conf.SoleTenantNodeType = defaultSoleTenantNodeType
+		}
+		if conf.SoleTenantNodeCount == 0 {
+			conf.SoleTenantNodeCount = 1
+		}
+	}
+	if deployArgs.WorkerTypeIsSet {
+		conf.WorkerType = deployArgs.WorkerType
+	}
+	if deployArgs.VarsFileIsSet {
+		conf.ConcourseVars = deployArgs.VarsFileContents
+	}
+	if deployArgs.CredentialManagerIsSet {
+		conf.CredentialManager = deployArgs.CredentialManager
+		conf.SecretsManagerPipelinePath = deployArgs.SecretsManagerPipelinePath
+		conf.SecretsManagerTeamPath = deployArgs.SecretsManagerTeamPath
+		conf.SSMPipelinePath = deployArgs.SSMPipelinePath
+		conf.SSMTeamPath = deployArgs.SSMTeamPath
+		conf.SSMKMSKeyID = deployArgs.SSMKMSKeyID
+	}
+	if deployArgs.VaultURLIsSet {
+		conf.VaultURL = deployArgs.VaultURL
+		conf.VaultCACert = deployArgs.VaultCACert
+		conf.VaultAuthBackend = deployArgs.VaultAuthBackend
+		conf.VaultAuthParams = deployArgs.VaultAuthParams
+		conf.VaultClientToken = deployArgs.VaultClientToken
+		conf.VaultPathPrefix = deployArgs.VaultPathPrefix
+	}
+	if deployArgs.TeamsFileIsSet {
+		conf.TeamsSpec = deployArgs.TeamsFileContents
+	}
+	if deployArgs.WorkerCgroupVersionIsSet {
+		conf.WorkerCgroupVersion = deployArgs.WorkerCgroupVersion
+	}
+	if deployArgs.WorkerSysctlsIsSet {
+		conf.WorkerSysctls = nonEmpty(deployArgs.WorkerSysctls)
+	}
+	if deployArgs.WorkerDNSServersIsSet {
+		conf.WorkerDNSServers = deployArgs.WorkerDNSServers
+	}
+	if deployArgs.WorkerDNSProxyIsSet {
+		conf.WorkerDNSProxy = deployArgs.WorkerDNSProxy
+	}
+	if len(conf.WorkerDNSServers) > 0 && conf.WorkerDNSProxy {
+		return config.Config{}, false, errors.New("--worker-dns-server and --worker-dns-proxy cannot be used together, deploy with --worker-dns-proxy=false to use DNS servers")
+	}
+	if deployArgs.WorkerNetworkPoolIsSet {
+		conf.WorkerNetworkPool = deployArgs.WorkerNetworkPool
+	}
+	if deployArgs.WorkerMaxContainersIsSet {
+		conf.WorkerMaxContainers = deployArgs.WorkerMaxContainers
+	}
+	if deployArgs.RestrictWorkerEgressIsSet {
+		conf.RestrictWorkerEgress = deployArgs.RestrictWorkerEgress
+	}
+	if deployArgs.WorkerEgressAllowIsSet {
+		conf.WorkerEgressAllow = nil
+		for _, allow := range deployArgs.WorkerEgressAllow {
+			if allow == "" {
+				continue
+			}
+			rule, err := config.ParseEgressRule(allow)
+			if err != nil {
+				return config.Config{}, false, err
+			}
+			conf.WorkerEgressAllow = append(conf.WorkerEgressAllow, rule)
+		}
+	}
+	if deployArgs.WorkerIMDSHopLimitIsSet {
+		conf.WorkerIMDSHopLimit = deployArgs.WorkerIMDSHopLimit
+	}
+	if deployArgs.WorkerSpotBidIsSet {
+		conf.WorkerSpotBid = deployArgs.WorkerSpotBid
+	}
+	if deployArgs.WorkerPoolsFileIsSet {
+		conf.WorkerPools = deployArgs.WorkerPools
+	}
+	if deployArgs.ARM64StemcellURLIsSet {
+		conf.ARM64StemcellURL = deployArgs.ARM64StemcellURL
+	}
+	if deployArgs.ARM64ReleaseURLIsSet {
+		conf.ARM64ReleaseURL = deployArgs.ARM64ReleaseURL
+	}
+	if deployArgs.ARM64ReleaseSHA1IsSet {
+		conf.ARM64ReleaseSHA1 = deployArgs.ARM64ReleaseSHA1
+	}
+	if deployArgs.WindowsWorkerCountIsSet {
+		conf.WindowsWorkerCount = deployArgs.WindowsWorkerCount
+	}
+	if deployArgs.WindowsWorkerTypeIsSet {
+		conf.WindowsWorkerType = deployArgs.WindowsWorkerType
+	}
+	if deployArgs.WindowsWorkerReleaseURLIsSet {
+		conf.WindowsWorkerReleaseURL = deployArgs.WindowsWorkerReleaseURL
+	}
+	if deployArgs.WindowsWorkerReleaseSHA1IsSet {
+		conf.WindowsWorkerReleaseSHA1 = deployArgs.WindowsWorkerReleaseSHA1
+	}
+	if deployArgs.WindowsStemcellVersionIsSet {
+		conf.WindowsStemcellVersion = deployArgs.WindowsStemcellVersion
+	}
+	// A quota policy is kept once given, so that later deploys, such as from the self-update pipeline, are
+	// checked against it too. A policy service is asked again on each deploy, while a file is kept as read
+	if deployArgs.QuotaPolicyIsSet {
+		conf.QuotaPolicyURL, conf.QuotaPolicy = "", deployArgs.Quota
+		if quota.IsURL(deployArgs.QuotaPolicy) {
+			conf.QuotaPolicyURL, conf.QuotaPolicy = deployArgs.QuotaPolicy, nil
+		}
+	}
+	// Choosing a size again goes back from an instance type to the sizes
+	if deployArgs.WorkerInstanceTypeIsSet {
+		conf.WorkerInstanceType = deployArgs.WorkerInstanceType
+	} else if deployArgs.WorkerSizeIsSet || deployArgs.WorkerTypeIsSet {
+		conf.WorkerInstanceType = ""
+	}
+	if deployArgs.WebInstanceTypeIsSet {
+		conf.WebInstanceType = deployArgs.WebInstanceType
+	} else if deployArgs.WebSizeIsSet {
+		conf.WebInstanceType = ""
+	}
+	if deployArgs.WorkerDiskSizeGBIsSet {
+		conf.WorkerDiskSizeGB = deployArgs.WorkerDiskSizeGB
+	}
+	if deployArgs.WorkerDiskTypeIsSet {
+		conf.WorkerDiskType = deployArgs.WorkerDiskType
+	}
+	if deployArgs.WorkerAutoscaleMaxIsSet {
+		conf.WorkerAutoscaleMin = deployArgs.WorkerAutoscaleMin
+		conf.WorkerAutoscaleMax = deployArgs.WorkerAutoscaleMax
+	}
+	if deployArgs.WorkerSchedulesIsSet {
+		conf.WorkerSchedules = nil
+		for _, s := range deployArgs.WorkerSchedules {
+			if s == "" {
+				continue
+			}
+			schedule, err := config.ParseWorkerSchedule(s)
+			if err != nil {
+				return config.Config{}, false, err
+			}
+			conf.WorkerSchedules = append(conf.WorkerSchedules, schedule)
+		}
+	}
+	if deployArgs.WorkerScheduleTimezoneIsSet {
+		conf.WorkerScheduleTimezone = deployArgs.WorkerScheduleTimezone
+	}
+	if deployArgs.WorkerDrainTimeoutIsSet {
+		conf.WorkerDrainTimeout = deployArgs.WorkerDrainTimeout
+	}
+	if deployArgs.WorkerRebalanceIntervalIsSet {
+		conf.WorkerRebalanceInterval = deployArgs.WorkerRebalanceInterval
+	}
+	if deployArgs.WorkerRetireMaxInFlightIsSet {
+		conf.WorkerRetireMaxInFlight = deployArgs.WorkerRetireMaxInFlight
+	}
+	if conf.WorkerRetireMaxInFlight > 0 && conf.UpgradeBatchSize > 0 {
+		return config.Config{}, false, errors.New("--worker-retire-max-in-flight can't be combined with --upgrade-batch-size, which already sets how many workers are updated at once")
+	}
+	if conf.WorkerAutoscaleMax > 0 {
+		// Start within the bounds, autoscaling takes it from there
+		if conf.ConcourseWorkerCount < conf.WorkerAutoscaleMin {
+			conf.ConcourseWorkerCount = conf.WorkerAutoscaleMin
+		}
+		if conf.ConcourseWorkerCount > conf.WorkerAutoscaleMax {
+			conf.ConcourseWorkerCount = conf.WorkerAutoscaleMax
+		}
+	}
+	if conf.WindowsWorkerCount > 0 && conf.WindowsWorkerType == "" {
+		// A general purpose instance type with 4 vCPUs, enough for MSVC builds
+		conf.WindowsWorkerType, _ = provider.Choose(iaas.Choice{
+			AWS: "m5.xlarge",
+			GCP: "n1-standard-4",
+		}).(string)
+	}
+	if err := validateHyperdisk(conf); err != nil {
+		return config.Config{}, false, err
+	}
+	if conf.WindowsWorkerCount > 0 && (conf.WindowsWorkerReleaseURL == "" || conf.WindowsWorkerReleaseSHA1 == "" || conf.WindowsStemcellVersion == "") {
+		return config.Config{}, false, errors.New("--windows-worker-count requires --windows-worker-release-url, --windows-worker-release-sha1 and --windows-stemcell-version, as the Concourse release's worker job only runs on Linux")
+	}
+
+	if deployArgs.EnableGlobalResourcesIsSet {
+		conf.EnableGlobalResources = deployArgs.EnableGlobalResources
+	}
+	if deployArgs.EnablePipelineInstancesIsSet {
+		conf.EnablePipelineInstances = deployArgs.EnablePipelineInstances
+	}
+
+	// Flag has default value, hence it's always set.
+	conf.InfluxDbRetention = deployArgs.InfluxDbRetention
+
+	var isDomainUpdated bool
+	if deployArgs.DomainIsSet {
+		domains := deployArgs.Domains()
+		var domain string
+		var additionalDomains []string
+		if len(domains) > 0 {
+			domain = domains[0]
+		}
+		if len(domains) > 1 {
+			additionalDomains = domains[1:]
+		}
+		if conf.Domain != domain || strings.Join(conf.AdditionalDomains, ",") != strings.Join(additionalDomains, ",") {
+			isDomainUpdated = true
+		}
+		conf.Domain = domain
+		conf.AdditionalDomains = additionalDomains
+	} else {
+		if govalidator.IsIPv4(conf.Domain) {
+			conf.Domain = ""
+		}
+	}
+
+	if deployArgs.DNSIsSet {
+		conf.DNSProvider = deployArgs.DNS
+		if conf.DNSProvider == dns.Cloud {
+			conf.DNSProvider = ""
+			conf.DNSZone = ""
+		}
+	}
+	if deployArgs.DNSZoneIsSet {
+		conf.DNSZone = strings.TrimSuffix(deployArgs.DNSZone, ".")
+	}
+	if err := validateDNS(conf); err != nil {
+		return config.Config{}, false, err
+	}
+
+	return conf, isDomainUpdated, nil
+}
+
+// validateDNS checks that a DNS provider other than the IAAS's has a domain to manage, and the zone it lives in
+func validateDNS(conf config.Config) error {
+	switch conf.DNSProvider {
+	case "":
+		if conf.DNSZone != "" {
+			return errors.New("--dns-zone requires --dns ns1 or --dns azure")
+		}
+		return nil
+	case dns.Manual:
+		if conf.DNSZone != "" {
+			return errors.New("--dns-zone requires --dns ns1 or --dns azure")
+		}
+	default:
+		if conf.DNSZone == "" {
+			return fmt.Errorf("--dns %s requires --dns-zone to also be provided", conf.DNSProvider)
+		}
+	}
+	if conf.Domain == "" {
+		return fmt.Errorf("--dns %s requires --domain to also be provided", conf.DNSProvider)
+	}
+	if conf.DNSZone != "" {
+		for _, domain := range append([]string{conf.Domain}, conf.AdditionalDomains...) {
+			if !dns.InZone(domain, conf.DNSZone) {
+				return fmt.Errorf("--domain %s is not in --dns-zone %s", domain, conf.DNSZone)
+			}
+		}
+	}
+	return nil
+}
+
+// Set config fields that are only valid on first deployment
+func applyImmutableArgumentsToConfig(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) config.Config {
+	if hasCIDRFlagsSet(deployArgs, provider) {
+		conf = populateConfigWithDeployArgsCIDRs(conf, deployArgs, provider)
+	}
+
+	conf.AvailabilityZone = provider.Zone(deployArgs.Zone, conf.ConcourseWorkerSize)
+
+	if deployArgs.ExistingNetworkFlagsSet() {
+		conf.ExistingNetwork = deployArgs.ExistingNetworkName()
+		conf.ExistingPublicSubnet = deployArgs.ExistingPublicSubnet
+		conf.ExistingPrivateSubnet = deployArgs.ExistingPrivateSubnet
+		conf.ExistingDBSubnets = deployArgs.ExistingDBSubnets
+	}
+
+	conf.Private = deployArgs.Private
+	return conf
+}
+
+// applyExistingNetwork takes the ranges and zone of the deployment from the subnets of the existing network it
+// is placed in. Its NAT address is looked up on every deploy, in case the network's owners have replaced it
+func applyExistingNetwork(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) (config.Config, error) {
+	network, err := provider.DescribeNetwork(conf.ExistingNetwork, conf.ExistingPublicSubnet, conf.ExistingPrivateSubnet, conf.ExistingDBSubnets)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if network.CIDR != "" {
+		conf.NetworkCIDR = network.CIDR
+	}
+	conf.PublicCIDR = network.PublicCIDR
+	conf.PrivateCIDR = network.PrivateCIDR
+	if network.Zone != "" {
+		if deployArgs.ZoneIsSet && deployArgs.Zone != network.Zone {
+			return config.Config{}, fmt.Errorf("--zone %s is not the zone of the existing subnets, which is %s", deployArgs.Zone, network.Zone)
+		}
+		conf.AvailabilityZone = network.Zone
+	}
+	conf.ExistingNATIP = network.NATIP
+	return conf, nil
+}
+
+// applyExternalDB points the deployment at an existing Postgres server, whose credentials and database replace the
+// generated RDS ones. The server and database can't change after the initial deploy, as the deployment's data is
+// kept there, but the credentials and CA certificate can be rotated
+func applyExternalDB(conf config.Config, deployArgs *deploy.Args, priorConfigExists bool) (config.Config, error) {
+	external, err := db.ParseExternalURL(deployArgs.ExternalDBURL)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err = db.CheckExternalDatabaseNames(conf.Deployment, external.Database); err != nil {
+		return config.Config{}, err
+	}
+	if priorConfigExists {
+		if conf.ExternalDBURL == "" {
+			return config.Config{}, errors.New("--external-db-url cannot be applied after initial deploy, as the deployment's data is kept in the database it was created with")
+		}
+		previous, err := db.ParseExternalURL(conf.ExternalDBURL)
+		if err != nil {
+			return config.Config{}, err
+		}
+		if previous.Host != external.Host || previous.Port != external.Port || previous.Database != external.Database {
+			return config.Config{}, errors.New("--external-db-url cannot point at a different server or database after initial deploy")
+		}
+	}
+
+	conf.ExternalDBURL = deployArgs.ExternalDBURL
+	conf.ExternalDBCACert = deployArgs.ExternalDBCACert
+	conf.RDSUsername = external.Username
+	conf.RDSPassword = external.Password
+	conf.RDSDefaultDatabaseName = external.Database
+	return conf, nil
+}
+
+// validateWebCount checks that a deployment with several web instances has a domain for its load balancer,
+// and doesn't run the colocated metrics stack, which would keep separate metrics on each web instance
+func validateWebCount(conf config.Config) error {
+	if conf.ConcourseWebCount <= 1 {
+		return nil
+	}
+	if conf.Private && conf.IAAS == "GCP" {
+		return errors.New("--web-count greater than 1 is only available to private deployments on AWS, as the load balancer in front of web instances on GCP is always external")
+	}
+	if conf.Domain == "" || net.ParseIP(conf.Domain) != nil {
+		return errors.New("--web-count greater than 1 requires a --domain, as the load balancer in front of the web instances has a different IP")
+	}
+	if !conf.NoMetrics && conf.InfluxDbURL == "" {
+		return errors.New("--web-count greater than 1 requires --no-metrics or --influxdb-url, as each web instance would keep its own metrics in the colocated metrics stack")
+	}
+	return nil
+}
+
+// validateWorkerPools checks that the ARM64 stemcell and release are known when any worker pool needs them,
+// that each pool's stemcell supports its baggageclaim driver, that GPU pools aren't given containerd settings,
+// and that spot pools on AWS can bid for their instance types
+func validateWorkerPools(conf config.Config) error {
+	if conf.IAAS == "AWS" && conf.IsSpot() {
+		if err := bosh.ValidateWorkerPoolSpotBids(conf.WorkerPools); err != nil {
+			return err
+		}
+	}
+	for _, pool := range conf.WorkerPools {
+		if pool.GPU != "" && (len(conf.WorkerDNSServers) > 0 || conf.WorkerDNSProxy || conf.WorkerNetworkPool != "" || conf.WorkerMaxContainers > 0) {
+			return fmt.Errorf("worker pool %s has a gpu, whose workers run Guardian for the NVIDIA container runtime, so it can't take the containerd settings --worker-dns-server, --worker-dns-proxy, --worker-network-pool or --worker-max-containers", pool.Name)
+		}
+		if pool.Arch != config.ARM64 {
+			// Other pools run the default Ubuntu Jammy stemcell
+			continue
+		}
+		if conf.ARM64StemcellURL == "" || conf.ARM64ReleaseURL == "" || conf.ARM64ReleaseSHA1 == "" {
+			return fmt.Errorf("worker pool %s has arch arm64, which requires --arm64-stemcell-url, --arm64-release-url and --arm64-release-sha1", pool.Name)
+		}
+		if pool.BaggageclaimDriver == "" {
+			continue
+		}
+		stemcellName, _, err := config.ParseStemcellURL(conf.ARM64StemcellURL)
+		if err != nil {
+			return err
+		}
+		if !config.StemcellSupportsBaggageclaimDriver(stemcellName, pool.BaggageclaimDriver) {
+			return fmt.Errorf("worker pool %s has baggageclaim_driver %s, which stemcell %s doesn't support, use naive", pool.Name, pool.BaggageclaimDriver, stemcellName)
+		}
+	}
+	return nil
+}
+
+// newGCPWorkerDiskType is the worker disk type of new deployments on GCP without --worker-disk-type
+const newGCPWorkerDiskType = "pd-ssd"
+
+// hyperdiskUnsupportedFamilies are the GCP machine families that can't attach Hyperdisk, including the n1
+// and t2a machine types that worker sizes, pools and Windows workers run on by default
+var hyperdiskUnsupportedFamilies = []string{"e2", "n1", "t2a", "t2d"}
+
+// validateHyperdisk rejects a Hyperdisk worker disk for workers on machine types that can't attach it
+func validateHyperdisk(conf config.Config) error {
+	if !strings.HasPrefix(conf.WorkerDiskType, "hyperdisk") {
+		return nil
+	}
+	if conf.WorkerInstanceType == "" || len(conf.WorkerPools) > 0 {
+		return fmt.Errorf("worker-disk-type %s needs a --worker-instance-type that supports it, like n4-standard-4, and no worker pools", conf.WorkerDiskType)
+	}
+	machineTypes := []string{conf.WorkerInstanceType}
+	if conf.WindowsWorkerCount > 0 {
+		machineTypes = append(machineTypes, conf.WindowsWorkerType)
+	}
+	for _, machineType := range machineTypes {
+		family := strings.SplitN(machineType, "-", 2)[0]
+		for _, unsupported := range hyperdiskUnsupportedFamilies {
+			if family == unsupported {
+				return fmt.Errorf("worker-disk-type %s can't be attached to machine type %s, use a type that supports Hyperdisk, like n4-standard-4", conf.WorkerDiskType, machineType)
+			}
+		}
+	}
+	return nil
+}
+
+// checkQuota rejects a deployment that the --quota-policy given now or by an earlier deploy doesn't allow,
+// before anything is created or changed
+func checkQuota(conf config.Config, deployArgs *deploy.Args) error {
+	policy := conf.QuotaPolicy
+	switch {
+	case deployArgs.Quota != nil:
+		policy = deployArgs.Quota
+	case conf.QuotaPolicyURL != "":
+		fetched, err := quota.Load(conf.QuotaPolicyURL, conf.Project)
+		if err != nil {
+			return err
+		}
+		policy = &fetched
+	}
+	if policy == nil {
+		return nil
+	}
+	workers := conf.ConcourseWorkerCount
+	if conf.WorkerAutoscaleMax > 0 {
+		// Autoscaling can grow the deployment up to its maximum without another deploy
+		workers = conf.WorkerAutoscaleMax
+	}
+	for _, schedule := range conf.WorkerSchedules {
+		if schedule.Workers > workers && conf.WorkerAutoscaleMax == 0 {
+			workers = schedule.Workers
+		}
+	}
+	request := quota.Request{
+		Workers:            workers,
+		WebNodes:           conf.ConcourseWebCount,
+		Region:             conf.Region,
+		WorkerSize:         conf.ConcourseWorkerSize,
+		WebSize:            conf.ConcourseWebSize,
+		WorkerInstanceType: conf.WorkerInstanceType,
+		WebInstanceType:    conf.WebInstanceType,
+	}
+	for _, pool := range conf.WorkerPools {
+		request.Workers += pool.Count
+		request.PoolSizes = append(request.PoolSizes, pool.Size)
+		if pool.GPU != "" {
+			request.GPUWorkers += pool.Count
+		}
+	}
+	request.Workers += conf.WindowsWorkerCount
+	return policy.Check(request)
+}
+
+// checkGPUQuota rejects a deployment whose worker pools need more GPUs than are left of the region's
+// quota on GCP, counting only the GPUs added since the previous deploy. AWS limits GPU instances with
+// vCPU quotas in AWS Service Quotas, which aren't checked
+func checkGPUQuota(previousPools []config.WorkerPool, conf config.Config, provider iaas.Provider) error {
+	if provider.IAAS() != iaas.GCP {
+		return nil
+	}
+	needed := map[string]int{}
+	for _, pool := range conf.WorkerPools {
+		if pool.GPU != "" {
+			needed[gpuQuotaMetric(pool.GPU, conf.Spot)] += pool.Count
+		}
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+	for _, pool := range previousPools {
+		if pool.GPU != "" {
+			needed[gpuQuotaMetric(pool.GPU, conf.Spot)] -= pool.Count
+		}
+	}
+
+	quotas, err := provider.Quotas()
+	if err != nil {
+		return fmt.Errorf("error checking GPU quota: [%v]", err)
+	}
+	for _, q := range quotas {
+		if n := needed[q.Name]; n > 0 && float64(n) > q.Limit-q.Usage {
+			return fmt.Errorf("deployment is over quota: worker pools need %d more %s but %.0f of the region's quota of %.0f are left", n, q.Name, q.Limit-q.Usage, q.Limit)
+		}
+	}
+	return nil
+}
+
+// gpuQuotaMetric is the GCP region quota limiting the GPU, preemptible workers having their own quota
+func gpuQuotaMetric(gpu string, preemptible bool) string {
+	metric := fmt.Sprintf("NVIDIA_%s_GPUS", strings.ToUpper(gpu))
+	if preemptible {
+		return "PREEMPTIBLE_" + metric
+	}
+	return metric
+}
+
+// applyAuditLogArgs returns the audit log categories that are on once --enable-audit-logs has turned every
+// category on or off, and the --audit-log overrides have been applied on top of it
+func applyAuditLogArgs(current []string, deployArgs *deploy.Args) ([]string, error) {
+	overrides, err := deployArgs.AuditLogOverrides()
+	if err != nil {
+		return nil, err
+	}
+	enabled := map[string]bool{}
+	for _, category := range current {
+		enabled[category] = true
+	}
+	var auditLogs []string
+	for _, category := range deploy.AuditLogCategories {
+		if deployArgs.EnableAuditLogsIsSet {
+			enabled[category] = deployArgs.EnableAuditLogs
+		}
+		if override, ok := overrides[category]; ok {
+			enabled[category] = override
+		}
+		if enabled[category] {
+			auditLogs = append(auditLogs, category)
+		}
+	}
+	return auditLogs, nil
+}
+
+// validateContainerPlacementLimits checks that each limit-active-* strategy has a limit, which
+// may have been set by an earlier deploy rather than alongside the strategy
+func validateContainerPlacementLimits(conf config.Config) error {
+	limits := map[string]int{
+		"limit-active-tasks":      conf.MaxActiveTasksPerWorker,
+		"limit-active-containers": conf.MaxActiveContainersPerWorker,
+		"limit-active-volumes":    conf.MaxActiveVolumesPerWorker,
+	}
+	for _, strategy := range conf.ContainerPlacementStrategies {
+		if limit, ok := limits[strategy]; ok && limit == 0 {
+			return fmt.Errorf("container placement strategy %s requires --max-active-%s-per-worker to be greater than 0", strategy, strings.TrimPrefix(strategy, "limit-active-"))
+		}
+	}
+	return nil
+}
+
+// validateZone checks that the deployment's zone can run its web and worker VMs before anything is created,
+// along with the further zones that workers are spread across
+func validateZone(conf config.ConfigView, provider iaas.Provider) error {
+	if err := provider.ValidateZone(conf.GetAvailabilityZone(), conf.GetConcourseWebSize(), conf.GetConcourseWorkerSize()); err != nil {
+		return fmt.Errorf("error validating zone: [%v]", err)
+	}
+	for _, workerZone := range conf.GetWorkerZones() {
+		if err := provider.ValidateZone(workerZone.Zone, conf.GetConcourseWebSize(), conf.GetConcourseWorkerSize()); err != nil {
+			return fmt.Errorf("error validating worker zone: [%v]", err)
+		}
+	}
+	return nil
+}
+
+// defaultDBMinACU and defaultDBMaxACU are the capacity range of an Aurora Serverless database without
+// --db-min-acu and --db-max-acu, which idles at the lowest capacity and can grow to around a large RDS instance
+const (
+	defaultDBMinACU = 0.5
+	defaultDBMaxACU = 4
+)
+
+// applyDBEngine sets the capacity range of an Aurora Serverless database, which replaces the RDS instance class
+func applyDBEngine(conf config.Config, deployArgs *deploy.Args) (config.Config, error) {
+	if deployArgs.DBEngineIsSet {
+		conf.DBEngine = deployArgs.DBEngine
+	}
+	if conf.DBEngine != config.DB_ENGINE_AURORA_SERVERLESS {
+		if deployArgs.DBMinACUIsSet || deployArgs.DBMaxACUIsSet {
+			return config.Config{}, errors.New("--db-min-acu and --db-max-acu require --db-engine aurora-serverless")
+		}
+		return conf, nil
+	}
+	if deployArgs.DBSizeIsSet {
+		return config.Config{}, errors.New("--db-size sets the class of an RDS instance, Aurora Serverless scales between --db-min-acu and --db-max-acu instead")
+	}
+
+	if deployArgs.DBMinACUIsSet {
+		conf.DBMinACU = deployArgs.DBMinACU
+	}
+	if deployArgs.DBMaxACUIsSet {
+		conf.DBMaxACU = deployArgs.DBMaxACU
+	}
+	if conf.DBMinACU == 0 {
+		conf.DBMinACU = defaultDBMinACU
+	}
+	if conf.DBMaxACU == 0 {
+		conf.DBMaxACU = defaultDBMaxACU
+	}
+	if conf.DBMinACU > conf.DBMaxACU {
+		return config.Config{}, fmt.Errorf("--db-min-acu %g cannot be greater than --db-max-acu %g", conf.DBMinACU, conf.DBMaxACU)
+	}
+	return conf, nil
+}
+
+// defaultDBStorage is the GB of storage that RDS instances are created with without --db-storage
+const defaultDBStorage = 10
+
+// applyDBStorage sets the storage of the RDS instance, which can't be shrunk and, for provisioned IOPS,
+// has to be large enough for them
+func applyDBStorage(conf config.Config, deployArgs *deploy.Args) (config.Config, error) {
+	if !deployArgs.DBStorageIsSet && !deployArgs.DBMaxStorageIsSet && !deployArgs.DBStorageTypeIsSet && !deployArgs.DBIOPSIsSet {
+		return conf, nil
+	}
+	if conf.DBEngine == config.DB_ENGINE_AURORA_SERVERLESS {
+		return config.Config{}, errors.New("--db-storage, --db-max-storage, --db-storage-type and --db-iops don't apply to Aurora Serverless, as its storage grows automatically")
+	}
+	if conf.ExternalDBURL != "" {
+		return config.Config{}, errors.New("--db-storage, --db-max-storage, --db-storage-type and --db-iops have no effect on a deployment with an external database")
+	}
+
+	storage := conf.DBStorage
+	if storage == 0 {
+		storage = defaultDBStorage
+	}
+	if deployArgs.DBStorageIsSet {
+		if deployArgs.DBStorage < storage {
+			return config.Config{}, fmt.Errorf("RDS storage cannot be shrunk from %d GB to %d GB", storage, deployArgs.DBStorage)
+		}
+		storage = deployArgs.DBStorage
+		conf.DBStorage = deployArgs.DBStorage
+	}
+	if deployArgs.DBMaxStorageIsSet {
+		conf.DBMaxStorage = deployArgs.DBMaxStorage
+	}
+	if deployArgs.DBStorageTypeIsSet {
+		conf.DBStorageType = deployArgs.DBStorageType
+	}
+	if deployArgs.DBIOPSIsSet {
+		conf.DBIOPS = deployArgs.DBIOPS
+	}
+
+	if conf.DBMaxStorage != 0 && conf.DBMaxStorage <= storage {
+		return config.Config{}, fmt.Errorf("--db-max-storage %d must be greater than the %d GB of RDS storage", conf.DBMaxStorage, storage)
+	}
+	switch conf.DBStorageType {
+	case "io1", "io2":
+		if conf.DBIOPS == 0 {
+			return config.Config{}, fmt.Errorf("--db-storage-type %s requires --db-iops", conf.DBStorageType)
+		}
+		if storage < 100 {
+			return config.Config{}, fmt.Errorf("--db-storage-type %s requires at least 100 GB of --db-storage, not %d GB", conf.DBStorageType, storage)
+		}
+	case "gp3":
+		if conf.DBIOPS != 0 && storage < 400 {
+			return config.Config{}, fmt.Errorf("--db-iops can only be set on gp3 storage of at least 400 GB, not %d GB", storage)
+		}
+	default:
+		if conf.DBIOPS != 0 {
+			return config.Config{}, errors.New("--db-iops requires --db-storage-type gp3, io1 or io2")
+		}
+	}
+	return conf, nil
+}
+
+// applyDBBackups sets the retention and window of automated backups of the database, and its deletion protection
+func applyDBBackups(conf config.Config, deployArgs *deploy.Args) (config.Config, error) {
+	if !deployArgs.DBBackupRetentionIsSet && !deployArgs.DBBackupWindowIsSet && !deployArgs.DBDeletionProtectionIsSet {
+		return conf, nil
+	}
+	if conf.ExternalDBURL != "" {
+		return config.Config{}, errors.New("--db-backup-retention, --db-backup-window and --db-deletion-protection have no effect on a deployment with an external database")
+	}
+	if deployArgs.DBBackupRetentionIsSet {
+		conf.DBBackupRetention = deployArgs.DBBackupRetention
+	}
+	if deployArgs.DBBackupWindowIsSet {
+		conf.DBBackupWindow = deployArgs.DBBackupWindow
+	}
+	if deployArgs.DBDeletionProtectionIsSet {
+		conf.DBDeletionProtection = deployArgs.DBDeletionProtection
+	}
+	return conf, nil
+}
+
+// dbVersion is the Postgres major version of the deployment's database
+func dbVersion(conf config.ConfigView) string {
+	if conf.GetDBVersion() != "" {
+		return conf.GetDBVersion()
+	}
+	if conf.GetIAAS() == "GCP" {
+		return config.DEFAULT_GCP_DB_VERSION
+	}
+	return config.DEFAULT_AWS_DB_VERSION
+}
+
+// checkDBVersionChange refuses to downgrade an existing database, and only upgrades it to a later major
+// version with --db-upgrade, as the upgrade takes the database offline and cannot be undone
+func checkDBVersionChange(deployArgs *deploy.Args, conf config.ConfigView) error {
+	if !deployArgs.DBVersionIsSet {
+		return nil
+	}
+	if conf.GetExternalDBURL() != "" {
+		return errors.New("--db-version has no effect on a deployment with an external database")
+	}
+	current := dbVersion(conf)
+	currentMajor, err := strconv.ParseFloat(current, 64)
+	if err != nil {
+		return fmt.Errorf("existing DB version %s is invalid: [%v]", current, err)
+	}
+	requestedMajor, err := strconv.ParseFloat(deployArgs.DBVersion, 64)
+	if err != nil {
+		return fmt.Errorf("DB version %s is invalid: [%v]", deployArgs.DBVersion, err)
+	}
+	if requestedMajor < currentMajor {
+		return fmt.Errorf("Existing deployment runs Postgres %s and cannot be downgraded to Postgres %s", current, deployArgs.DBVersion)
+	}
+	if requestedMajor > currentMajor && !deployArgs.DBUpgrade {
+		return fmt.Errorf("Existing deployment runs Postgres %s. Upgrading it to Postgres %s takes the database offline for a few minutes up to half an hour and cannot be undone, so deploy again with --db-upgrade to go ahead", current, deployArgs.DBVersion)
+	}
+	return nil
+}
+
+// checkDBResize only resizes an existing database to a new class with --db-resize, which chooses between
+// switching over to a resized copy of an RDS instance and restarting the instance at the new size
+func checkDBResize(deployArgs *deploy.Args, conf config.ConfigView, iaasName iaas.Name, class string) error {
+	// conf.Version is only set once a deploy has been completed, before which there is no database to resize
+	if conf.GetVersion() == "" || conf.GetExternalDBURL() != "" || conf.GetDBEngine() == config.DB_ENGINE_AURORA_SERVERLESS || class == conf.GetRDSInstanceClass() {
+		return nil
+	}
+	if !deployArgs.DBResizeIsSet {
+		if iaasName == iaas.GCP {
+			return fmt.Errorf("Existing deployment's database is %s. Resizing it to %s restarts the CloudSQL instance, which takes it offline for a few minutes, so deploy again with --db-resize in-place to go ahead", conf.GetRDSInstanceClass(), class)
+		}
+		return fmt.Errorf("Existing deployment's database is %s. Resizing it to %s in place restarts the RDS instance, which takes it offline for a few minutes or more, so deploy again with --db-resize blue-green to switch over to a resized copy in under a minute, or with --db-resize in-place to go ahead", conf.GetRDSInstanceClass(), class)
+	}
+	// terraform deletes the old instance once a Blue/Green deployment has switched over
+	if deployArgs.DBResize == deploy.DBResizeBlueGreen && conf.GetDBDeletionProtection() {
+		return errors.New("--db-resize blue-green deletes the old RDS instance once it has switched over, which deletion protection prevents. Deploy with --db-deletion-protection=false first")
+	}
+	return nil
+}
+
+// defaultSoleTenantNodeType is the GCP node type for dedicated VMs without --sole-tenant-node-type, which
+// runs the n1 machine types of every web and worker size
+const defaultSoleTenantNodeType = "n1-node-96-624"
+
+// validateDedicatedVMs checks that dedicated workers can be placed. They must be on-demand, as EC2 doesn't
+// offer spot capacity on dedicated hardware, and a GCP sole-tenant node group is only in the deployment's zone
+func validateDedicatedVMs(conf config.Config, provider iaas.Provider) error {
+	if !conf.DedicatedWorkers {
+		return nil
+	}
+	if conf.IsSpot() {
+		return errors.New("--dedicated-workers cannot run spot or preemptible workers, deploy with --spot=false")
+	}
+	if provider.IAAS() == iaas.GCP && len(conf.WorkerZones) > 0 {
+		return errors.New("--dedicated-workers places workers on a sole-tenant node group in the deployment's zone, so it cannot be used with --worker-zone on GCP")
+	}
+	return nil
+}
+
+// validateBastion checks that a deployment with a bastion is private, as the director of any other deployment
+// is reached directly, and that its bastion has a subnet with an internet gateway to go in
+func validateBastion(conf config.Config, provider iaas.Provider) error {
+	if !conf.Bastion {
+		return nil
+	}
+	if !conf.Private {
+		return errors.New("--bastion requires a deployment made with --private, as the director of any other deployment is reached directly")
+	}
+	if conf.ExistingNetwork != "" && provider.IAAS() == iaas.AWS {
+		return errors.New("--bastion isn't available to private deployments with --existing-vpc-id, as the bastion needs a subnet that routes to an internet gateway and the existing public subnet routes through a NAT gateway")
+	}
+	return nil
+}
+
+// validateVPCEndpoints checks that a deployment with VPC endpoints is private, and has a VPC of its own for
+// the endpoints to go in
+func validateVPCEndpoints(conf config.Config) error {
+	if !conf.VPCEndpoints {
+		return nil
+	}
+	if !conf.Private {
+		return errors.New("--vpc-endpoints requires a deployment made with --private, whose VMs all reach AWS's APIs through the NAT gateway")
+	}
+	if conf.ExistingNetwork != "" {
+		return errors.New("--vpc-endpoints isn't available with --existing-vpc-id, as the endpoints of an existing VPC are managed along with it")
+	}
+	return nil
+}
+
+// validateIPv6 checks that a deployment with IPv6 has a load balancer with public addresses to give IPv6
+// addresses to, and a VPC of its own to give IPv6 ranges to
+func validateIPv6(conf config.Config) error {
+	if !conf.IPv6 {
+		return nil
+	}
+	if conf.ConcourseWebCount <= 1 {
+		return errors.New("--ipv6 requires --web-count greater than 1, as IPv6 clients reach the web VMs through the dual-stack load balancer in front of them")
+	}
+	if conf.Private {
+		return errors.New("--ipv6 isn't available with --private, as the load balancer of a private deployment is only reached at its private IPv4 address")
+	}
+	if conf.ExistingNetwork != "" {
+		return errors.New("--ipv6 isn't available with --existing-vpc-id, as the addressing of an existing VPC is managed along with it")
+	}
+	return nil
+}
+
+// nonEmpty leaves out the empty values of a flag that can be used more than once, which empty its list
+func nonEmpty(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// validatePeering checks that a deployment peered with other VPCs on AWS has a VPC of its own, whose route
+// tables get routes to them
+func validatePeering(conf config.Config, provider iaas.Provider) error {
+	if len(conf.PeerVPCIDs) > 0 && conf.ExistingNetwork != "" && provider.IAAS() == iaas.AWS {
+		return errors.New("--peer-vpc-id isn't available with --existing-vpc-id, as the peerings and route tables of an existing VPC are managed along with it")
+	}
+	return nil
+}
+
+// validateTransitGateway checks that a deployment with routes through a transit gateway is attached to one,
+// has a VPC of its own to attach, and routes nothing within its own network through it. On GCP, ranges are
+// only excluded from a Network Connectivity Center hub that the network is a spoke of
+func validateTransitGateway(conf config.Config) error {
+	if conf.NCCHub == "" && len(conf.NCCExcludeExportRanges) > 0 {
+		return errors.New("--ncc-exclude-export-range requires --ncc-hub")
+	}
+	if conf.TransitGatewayID == "" {
+		if len(conf.TransitGatewayRoutes) > 0 || conf.TransitGatewayRouteTableID != "" || conf.NoTransitGatewayPropagation {
+			return errors.New("--transit-gateway-route, --transit-gateway-route-table-id and --no-transit-gateway-propagation require --transit-gateway-id")
+		}
+		return nil
+	}
+	if conf.ExistingNetwork != "" {
+		return errors.New("--transit-gateway-id isn't available with --existing-vpc-id, as the attachments and route tables of an existing VPC are managed along with it")
+	}
+	for _, route := range conf.TransitGatewayRoutes {
+		err := config.CheckCIDROverlaps([]config.NamedCIDR{
+			{Name: "--network-cidr", CIDR: conf.NetworkCIDR},
+			{Name: "--transit-gateway-route", CIDR: route},
+		})
+		if err != nil {
+			return fmt.Errorf("error validating --transit-gateway-route - %v", err)
+		}
+	}
+	return nil
+}
+
+// validateWebAddress checks that a deployment given an existing address for its web endpoint has a public
+// endpoint to give it to
+func validateWebAddress(conf config.Config) error {
+	if conf.Private && (conf.WebEIP != "" || conf.WebAddressName != "") {
+		return errors.New("--web-eip and --web-address-name aren't available with --private, as the web endpoint of a private deployment has no public address")
+	}
+	return nil
+}
+
+// applyBastionKey generates the key pair that the bastion is reached with, the first time a deployment has one.
+// The key is kept when the bastion is removed, so that adding it again doesn't change its key
+func applyBastionKey(conf config.Config, sshGenerator func() ([]byte, []byte, string, error)) (config.Config, error) {
+	if !conf.Bastion || conf.BastionPrivateKey != "" {
+		return conf, nil
+	}
+	privateKey, publicKey, _, err := sshGenerator()
+	if err != nil {
+		return config.Config{}, fmt.Errorf("error generating the bastion's SSH key: [%v]", err)
+	}
+	conf.BastionPrivateKey = string(privateKey)
+	conf.BastionPublicKey = strings.TrimSpace(string(publicKey))
+	return conf, nil
+}
+
+// applyWorkerZones replaces the zones that workers are spread across. On AWS, zones that were already
+// in use keep their subnets, and each new zone is given the first free block of the network
+func applyWorkerZones(conf config.Config, zones []string, provider iaas.Provider) (config.Config, error) {
+	existing := map[string]config.WorkerZone{}
+	for _, workerZone := range conf.WorkerZones {
+		existing[workerZone.Zone] = workerZone
+	}
+
+	var workerZones []config.WorkerZone
+	for _, zone := range zones {
+		if zone == "" {
+			continue
+		}
+		if zone == conf.AvailabilityZone {
+			return config.Config{}, fmt.Errorf("--worker-zone %s is the deployment's own zone, which workers are always placed in", zone)
+		}
+		workerZones = append(workerZones, config.WorkerZone{Zone: zone, CIDR: existing[zone].CIDR, NATCIDR: existing[zone].NATCIDR})
+	}
+
+	if provider.IAAS() == iaas.AWS {
+		taken := []string{conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR}
+		if conf.NATCIDR != "" {
+			taken = append(taken, conf.NATCIDR)
+		}
+		for _, workerZone := range workerZones {
+			if workerZone.CIDR != "" {
+				taken = append(taken, workerZone.CIDR)
+			}
+			if workerZone.NATCIDR != "" {
+				taken = append(taken, workerZone.NATCIDR)
+			}
+		}
+		for i := range workerZones {
+			if workerZones[i].CIDR != "" {
+				continue
+			}
+			allocated, err := config.AllocateWorkerZoneCIDR(conf.NetworkCIDR, conf.PrivateCIDR, taken)
+			if err != nil {
+				return config.Config{}, fmt.Errorf("error allocating a subnet for worker zone %s: [%v]", workerZones[i].Zone, err)
+			}
+			workerZones[i].CIDR = allocated
+			taken = append(taken, allocated)
+		}
+	}
+
+	conf.WorkerZones = workerZones
+	return conf, nil
+}
+
+// applyNATTopology gives each worker zone that doesn't have one yet the first free /28 of the network for its
+// own NAT gateway, when the deployment has a NAT gateway per zone. The subnets are kept when the topology
+// changes back, so that they don't move if it changes again
+func applyNATTopology(conf config.Config, provider iaas.Provider) (config.Config, error) {
+	if conf.NATTopology == "" || conf.NATTopology == config.NAT_SINGLE {
+		return conf, nil
+	}
+	if conf.ExistingNetwork != "" {
+		return config.Config{}, errors.New("--nat-topology isn't available with --existing-vpc-id, as the NAT gateway of an existing VPC is managed along with it")
+	}
+	if conf.NATTopology != config.NAT_PER_ZONE || provider.IAAS() != iaas.AWS {
+		return conf, nil
+	}
+	if len(conf.WorkerZones) == 0 {
+		return config.Config{}, errors.New("--nat-topology per-zone gives each --worker-zone a NAT gateway of its own, so it needs at least one --worker-zone")
+	}
+
+	taken := []string{conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR}
+	if conf.NATCIDR != "" {
+		taken = append(taken, conf.NATCIDR)
+	}
+	for _, workerZone := range conf.WorkerZones {
+		taken = append(taken, workerZone.CIDR)
+		if workerZone.NATCIDR != "" {
+			taken = append(taken, workerZone.NATCIDR)
+		}
+	}
+
+	workerZones := make([]config.WorkerZone, len(conf.WorkerZones))
+	copy(workerZones, conf.WorkerZones)
+	for i := range workerZones {
+		if workerZones[i].NATCIDR != "" {
+			continue
+		}
+		allocated, err := config.AllocateNATCIDR(conf.NetworkCIDR, taken)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("error allocating a subnet for the NAT gateway of worker zone %s: [%v]", workerZones[i].Zone, err)
+		}
+		workerZones[i].NATCIDR = allocated
+		taken = append(taken, allocated)
+	}
+	conf.WorkerZones = workerZones
+	return conf, nil
+}
+
+// instanceTypeMinimums are the least vCPUs and memory that an instance type named for each role can
+// have, those of the smallest sizes: t3.small web nodes and n1-standard-1 workers
+var instanceTypeMinimums = map[string]struct{ vCPUs, memoryMiB int }{
+	"web":    {1, 2048},
+	"worker": {1, 3840},
+}
+
+// validateInstanceTypes checks instance types named in place of sizes against the IAAS, so that
+// types newer than the sizes map to can be used, as long as the zone offers them and they are
+// big enough to run Concourse
+func validateInstanceTypes(conf config.ConfigView, provider iaas.Provider) error {
+	for _, role := range []struct{ name, instanceType string }{
+		{"web", conf.GetWebInstanceType()},
+		{"worker", conf.GetWorkerInstanceType()},
+	} {
+		if role.instanceType == "" {
+			continue
+		}
+		spec, err := provider.DescribeInstanceType(conf.GetAvailabilityZone(), role.instanceType)
+		if err != nil {
+			return fmt.Errorf("error validating %s instance type: [%v]", role.name, err)
+		}
+
+		amd64 := false
+		for _, arch := range spec.Architectures {
+			amd64 = amd64 || arch == "amd64"
+		}
+		if !amd64 {
+			return fmt.Errorf("%s instance type %s is invalid: must be amd64, ARM64 workers go in a worker pool with arch arm64", role.name, role.instanceType)
+		}
+
+		minimum := instanceTypeMinimums[role.name]
+		if spec.VCPUs < minimum.vCPUs || spec.MemoryMiB < minimum.memoryMiB {
+			return fmt.Errorf("%s instance type %s is too small: it has %d vCPUs and %d MiB of memory, at least %d vCPUs and %d MiB are needed", role.name, role.instanceType, spec.VCPUs, spec.MemoryMiB, minimum.vCPUs, minimum.memoryMiB)
+		}
+	}
+	return nil
+}
+
+func hasCIDRFlagsSet(deployArgs *deploy.Args, provider iaas.Provider) bool {
+	switch provider.IAAS() {
+	case iaas.AWS:
+		return deployArgs.NetworkCIDRIsSet && deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
+	case iaas.GCP:
+		return deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
+	default:
+		return false
+	}
+}
+
+func populateConfigWithDeployArgsCIDRs(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) config.Config {
+	switch provider.IAAS() {
+	case iaas.AWS:
+		conf.NetworkCIDR = deployArgs.NetworkCIDR
+		conf.PublicCIDR = deployArgs.PublicCIDR
+		conf.PrivateCIDR = deployArgs.PrivateCIDR
+		conf.RDS1CIDR = deployArgs.RDS1CIDR
+		conf.RDS2CIDR = deployArgs.RDS2CIDR
+	case iaas.GCP:
+		conf.PublicCIDR = deployArgs.PublicCIDR
+		conf.PrivateCIDR = deployArgs.PrivateCIDR
+	}
+	return conf
+}
+
+func populateConfigWithDefaultCIDRs(conf config.Config, provider iaas.Provider) config.Config {
+	switch provider.IAAS() {
+	case iaas.AWS:
+		conf.NetworkCIDR = "10.0.0.0/16"
+		conf.PrivateCIDR = "10.0.1.0/24"
+		conf.PublicCIDR = "10.0.0.0/24"
+		conf.RDS1CIDR = "10.0.4.0/24"
+		conf.RDS2CIDR = "10.0.5.0/24"
+	case iaas.GCP:
+		conf.PrivateCIDR = "10.0.1.0/24"
+		conf.PublicCIDR = "10.0.0.0/24"
+	}
+	return conf
+}
+
+func getUpdatedAllowedIPs(ingressAddresses cidrBlocks) (string, error) {
+	addr, err := ingressAddresses.String()
+	if err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+// splitList splits a comma separated flag value, ignoring surrounding whitespace and empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+type cidrBlocks []*net.IPNet
+
+func parseAllowedIPsCIDRs(s string) (cidrBlocks, error) {
+	var x cidrBlocks
+	for _, ip := range strings.Split(s, ",") {
+		ip = strings.TrimSpace(ip)
+		_, ipNet, err := net.ParseCIDR(ip)
+		if err != nil {
+			ipNet = &net.IPNet{
+				IP:   net.ParseIP(ip),
+				Mask: net.CIDRMask(32, 32),
+			}
+			if ipNet.IP != nil && ipNet.IP.To4() == nil {
+				ipNet.Mask = net.CIDRMask(128, 128)
+			}
+		}
+		if ipNet.IP == nil {
+			return nil, fmt.Errorf("could not parse %q as an IP address or CIDR range", ip)
+		}
+		x = append(x, ipNet)
+	}
+	return x, nil
+}
+
+// parseAllowedIPv6CIDRs is parseAllowedIPsCIDRs for the IPv6 ranges of --allow-ipv6-ips, which can't take
+// IPv4 addresses
+func parseAllowedIPv6CIDRs(s string) (cidrBlocks, error) {
+	x, err := parseAllowedIPsCIDRs(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, ipNet := range x {
+		if ipNet.IP.To4() != nil {
+			return nil, fmt.Errorf("%q is not an IPv6 address or CIDR range", ipNet)
 		}
-		x = append(x, ipNet)
 	}
 	return x, nil
 }
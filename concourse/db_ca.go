@@ -0,0 +1,117 @@
+package concourse
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// defaultRDSCACertIdentifier is the RDS CA that maintain --rotate-db-ca rotates the database onto, unless
+// another is given with --db-ca-identifier
+const defaultRDSCACertIdentifier = "rds-ca-rsa2048-g1"
+
+// refreshDBCACert fetches the CAs of the database from the IAAS, so that the director and Concourse trust
+// any that it is rotated onto. The CAs last fetched are kept when the IAAS can't be reached, which are
+// the RDS CAs built into control-tower, or the CloudSQL CA that terraform outputs, until a fetch succeeds
+func (client *Client) refreshDBCACert(conf config.ConfigView, tfOutputs terraform.Outputs) string {
+	if conf.GetExternalDBURL() != "" {
+		return conf.GetDBCACert()
+	}
+	bundle, err := client.fetchDBCACert(conf, tfOutputs)
+	if err != nil {
+		fmt.Fprintf(client.stderr, "WARNING: failed to fetch the database CA certificates, so the ones last fetched are trusted: [%v]\n", err)
+		return conf.GetDBCACert()
+	}
+	return bundle
+}
+
+func (client *Client) fetchDBCACert(conf config.ConfigView, tfOutputs terraform.Outputs) (string, error) {
+	name, err := dbInstanceName(conf, client.provider.IAAS(), tfOutputs)
+	if err != nil {
+		return "", err
+	}
+	return client.provider.DatabaseCACerts(name)
+}
+
+// rotateDBCA moves the database onto a new CA, which is rdsCACertIdentifier on AWS. The director and Concourse
+// are redeployed to trust it first, so that they can still connect once the database presents a certificate signed by it
+func (client *Client) rotateDBCA(rdsCACertIdentifier string) (err error) {
+	if rdsCACertIdentifier == "" {
+		rdsCACertIdentifier = defaultRDSCACertIdentifier
+	}
+
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	defer func() { client.sendAuditEvent(conf, "rotate-db-ca", err) }()
+
+	if conf.GetExternalDBURL() != "" {
+		return exitcode.WithCode(exitcode.Validation, errors.New("--rotate-db-ca rotates the CA of the RDS or CloudSQL instance, which a deployment with --external-db-url doesn't have"))
+	}
+
+	lock, err := client.acquireDeploymentLock(conf, "rotate-db-ca", deploylock.DefaultStaleAfter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	name, err := dbInstanceName(conf, client.provider.IAAS(), tfOutputs)
+	if err != nil {
+		return err
+	}
+
+	if client.provider.IAAS() == iaas.GCP {
+		fmt.Fprintf(client.stdout, "Adding a new CA to CloudSQL instance %s\n", name)
+		if err = client.provider.AddDatabaseCA(name); err != nil {
+			return err
+		}
+	}
+	conf.DBCACert, err = client.provider.DatabaseCACerts(name)
+	if err != nil {
+		return err
+	}
+
+	// once the director and Concourse are redeployed, running the rotation again finishes it
+	defer func() { err = exitcode.WithCode(exitcode.Partial, err) }()
+	if err = client.configClient.Update(conf); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(client.stdout, "Redeploying the director and Concourse to trust the new CA")
+	if err = client.redeployBosh(conf, tfOutputs); err != nil {
+		return err
+	}
+
+	if client.provider.IAAS() == iaas.GCP {
+		fmt.Fprintf(client.stdout, "Rotating CloudSQL instance %s onto the new CA\n", name)
+		if err = client.provider.RotateDatabaseCA(name); err != nil {
+			return err
+		}
+	} else {
+		conf.DBCACertIdentifier = rdsCACertIdentifier
+		if err = client.configClient.Update(conf); err != nil {
+			return err
+		}
+		fmt.Fprintf(client.stdout, "Rotating %s onto %s, which restarts it\n", name, rdsCACertIdentifier)
+		if err = client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(client.stdout, "%s now presents a certificate signed by the new CA\n", name)
+	return nil
+}
@@ -3,9 +3,11 @@ package concourse
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"text/template"
 	"time"
 
@@ -16,8 +18,14 @@ import (
 	"github.com/EngineerBetter/control-tower/bosh"
 	"github.com/EngineerBetter/control-tower/certs"
 	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/dns"
+	"github.com/EngineerBetter/control-tower/exitcode"
 	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/terraform"
+	"github.com/EngineerBetter/control-tower/util"
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/lego"
 	"gopkg.in/yaml.v2"
 )
@@ -48,8 +56,8 @@ func stripVersion(tags []string) []string {
 }
 
 // Deploy deploys a concourse instance
-func (client *Client) Deploy() error {
-	err := client.configClient.EnsureBucketExists()
+func (client *Client) Deploy() (err error) {
+	err = client.configClient.EnsureBucketExists()
 	if err != nil {
 		return fmt.Errorf("error ensuring config bucket exists before deploy: [%v]", err)
 	}
@@ -58,6 +66,48 @@ func (client *Client) Deploy() error {
 	if err != nil {
 		return fmt.Errorf("error getting initial config before deploy: [%v]", err)
 	}
+	defer func() { client.sendAuditEvent(conf, "deploy", err) }()
+
+	// Checked on every deploy, as a deployment pinned to a Concourse version may be upgraded to a
+	// control-tower with a different stemcell line, including by the self-update pipeline
+	if conf.ConcourseVersion != "" {
+		conf, err = client.resolveConcourseVersion(conf)
+		if err != nil {
+			return exitcode.WithCode(exitcode.Validation, err)
+		}
+	}
+	conf, err = client.resolveOSConfRelease(conf)
+	if err != nil {
+		return exitcode.WithCode(exitcode.Validation, err)
+	}
+
+	if client.deployArgs.ArtifactsDirIsSet {
+		if err = client.checkArtifactsDir(conf); err != nil {
+			return exitcode.WithCode(exitcode.Validation, err)
+		}
+	}
+
+	staleAfter := deploylock.DefaultStaleAfter
+	if client.deployArgs.LockStaleMinutesIsSet {
+		staleAfter = time.Duration(client.deployArgs.LockStaleMinutes) * time.Minute
+	}
+	lock, err := client.acquireDeploymentLock(conf, "deploy", staleAfter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	// once infrastructure has been created, deploying again picks up where a failed deploy left off
+	infrastructureChanged := false
+	defer func() {
+		if infrastructureChanged {
+			err = exitcode.WithCode(exitcode.Partial, err)
+		}
+	}()
 
 	r, err := client.checkPreTerraformConfigRequirements(conf, client.deployArgs.SelfUpdate)
 	if err != nil {
@@ -67,19 +117,51 @@ func (client *Client) Deploy() error {
 	conf.SourceAccessIP = r.SourceAccessIP
 	conf.HostedZoneID = r.HostedZoneID
 	conf.HostedZoneRecordPrefix = r.HostedZoneRecordPrefix
+	conf.AdditionalRecordPrefixes = r.AdditionalRecordPrefixes
 	conf.Domain = r.Domain
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
+	if conf.DBBlueGreenUpdate && !conf.DBLogicalReplication {
+		if err = client.confirmDBRestart(); err != nil {
+			return err
+		}
+	}
+
+	infrastructureChanged = true
+	// conf.Version is only set once a deploy has been completed, before which there is no database to upgrade
+	if client.deployArgs.DBUpgrade && client.provider.IAAS() == iaas.GCP && conf.Version != "" {
+		if err = client.upgradeCloudSQL(conf, tfInputVars); err != nil {
+			return err
+		}
+	}
+
+	if err = lock.SetStage(deploylock.StageTerraform); err != nil {
+		return err
+	}
+	if conf.DBBlueGreenUpdate {
+		if !conf.DBLogicalReplication {
+			if err = client.enableDBLogicalReplication(conf); err != nil {
+				return err
+			}
+			conf.DBLogicalReplication = true
+			tfInputVars = client.tfInputVarsFactory.NewInputVars(conf)
+		}
+		fmt.Fprintf(client.stdout, "Resizing the RDS instance to %s with a Blue/Green deployment. A resized copy is created and kept in sync, then switched over to in under a minute, after which the old instance is deleted\n", conf.RDSInstanceClass)
+	}
 	err = client.tfCLI.Apply(tfInputVars)
 	if err != nil {
 		return err
 	}
+	if err = lock.SetStage(""); err != nil {
+		return err
+	}
 
 	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
 	if err != nil {
 		return err
 	}
+	conf.DBCACert = client.refreshDBCACert(conf, tfOutputs)
 
 	err = client.configClient.Update(conf)
 	if err != nil {
@@ -103,13 +185,24 @@ func (client *Client) Deploy() error {
 	conf.ConcourseCert = cr.Certs.ConcourseCert
 	conf.ConcourseKey = cr.Certs.ConcourseKey
 	conf.ConcourseCACert = cr.Certs.ConcourseCACert
+	conf.DBProxyPassword = cr.DBProxy.Password
+	conf.DBProxyCACert = cr.DBProxy.CACert
+	conf.DBProxyCert = cr.DBProxy.Cert
+	conf.DBProxyKey = cr.DBProxy.Key
 
+	// bosh create-env runs on this machine, where the director's locks can't show that it is still going
+	if err = lock.SetStage(deploylock.StageBosh); err != nil {
+		return err
+	}
 	var bp BoshParams
 	if client.deployArgs.SelfUpdate {
 		bp, err = client.updateBoshAndPipeline(conf, tfOutputs)
 	} else {
 		bp, err = client.deployBoshAndPipeline(conf, tfOutputs)
 	}
+	if err == nil {
+		err = lock.SetStage("")
+	}
 
 	conf.CredhubPassword = bp.CredhubPassword
 	conf.CredhubAdminClientSecret = bp.CredhubAdminClientSecret
@@ -124,17 +217,97 @@ func (client *Client) Deploy() error {
 	conf.DirectorCACert = bp.DirectorCACert
 
 	err1 := client.configClient.Update(conf)
+	if err1 == nil {
+		// maintain --autoscale reaches the director with these, as it can't read the terraform state
+		err1 = client.configClient.UpdateRuntime(config.Runtime{
+			Config:  config.RuntimeConfig(conf),
+			Outputs: terraform.RecordOutputs(tfOutputs, terraform.RuntimeOutputKeys),
+		})
+	}
 	if err == nil {
 		err = err1
 	}
+	if err == nil && client.deployArgs.ScanForLeaks {
+		// A leak doesn't leave the deployment part way through changing
+		infrastructureChanged = false
+		err = client.scanForLeaks(conf, tfOutputs)
+	}
 	return err
 }
 
+// upgradeCloudSQL upgrades the CloudSQL instance to the configured version before terraform is applied, as
+// changing its version in terraform would replace the instance. On AWS terraform upgrades RDS in place itself
+func (client *Client) upgradeCloudSQL(conf config.ConfigView, tfInputVars terraform.InputVars) error {
+	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return err
+	}
+	dbName, err := tfOutputs.Get("DBName")
+	if err != nil {
+		return err
+	}
+	version := cloudSQLDatabaseVersion(conf)
+	fmt.Fprintf(client.stdout, "Upgrading CloudSQL instance %s to %s if it runs an earlier version, after taking a backup. The database is offline until the upgrade finishes\n", dbName, version)
+	return client.provider.UpgradeDatabase(dbName, version)
+}
+
+// confirmDBRestart asks before the restart that enabling logical replication needs, as it takes the database
+// offline, unless nobody is there to answer
+func (client *Client) confirmDBRestart() error {
+	if client.deployArgs.NonInteractive {
+		return nil
+	}
+	proceed, err := util.Confirm(os.Stdin, client.stdout, "A Blue/Green resize needs logical replication, which only takes effect once the RDS instance restarts. This restarts the database, taking it offline for a few minutes. Restart it now?")
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return errors.New("resize stopped before restarting the database, deploy again when it can be restarted")
+	}
+	return nil
+}
+
+// enableDBLogicalReplication sets rds.logical_replication in the parameter group of the RDS instance, creating
+// the group when the instance has none, and reboots the instance for it to take effect. A Blue/Green deployment
+// keeps the resized copy in sync with logical replication, so it can't be created until then. The instance is
+// left at the size it is at, and the config saved, so that a deploy which fails afterwards doesn't reboot it again
+func (client *Client) enableDBLogicalReplication(conf config.Config) error {
+	conf.RDSInstanceClass = conf.DBResizedFrom
+	conf.DBBlueGreenUpdate = false
+	conf.DBLogicalReplication = true
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+	fmt.Fprintln(client.stdout, "Enabling logical replication on the RDS instance, which a Blue/Green deployment needs")
+	if err := client.tfCLI.Apply(tfInputVars); err != nil {
+		return err
+	}
+	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return err
+	}
+	name, err := dbInstanceName(conf, client.provider.IAAS(), tfOutputs)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(client.stdout, "Rebooting RDS instance %s for logical replication to take effect. The database is offline for a few minutes until it is available again\n", name)
+	if err = client.provider.RebootDatabase(name); err != nil {
+		return err
+	}
+	return client.configClient.Update(conf)
+}
+
 func (client *Client) deployBoshAndPipeline(c config.ConfigView, tfOutputs terraform.Outputs) (BoshParams, error) {
 	// When we are deploying for the first time rather than updating
 	// ensure that the pipeline is set _after_ the concourse is deployed
 
+	stopWatching, err := client.watchUpgrade(c, tfOutputs)
+	if err != nil {
+		return BoshParams{}, err
+	}
+
 	bp, err := client.deployBosh(c, tfOutputs, false)
+	if err1 := stopWatching(); err1 != nil {
+		return bp, err1
+	}
 	if err != nil {
 		return bp, err
 	}
@@ -148,6 +321,10 @@ func (client *Client) deployBoshAndPipeline(c config.ConfigView, tfOutputs terra
 		return bp, err
 	}
 
+	if err = credhubClient.SetRuntimeCreds(client.provider, tfOutputs); err != nil {
+		return bp, err
+	}
+
 	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
 		Target:   c.GetDeployment(),
 		API:      fmt.Sprintf("https://%s", c.GetDomain()),
@@ -167,6 +344,27 @@ func (client *Client) deployBoshAndPipeline(c config.ConfigView, tfOutputs terra
 		return bp, err
 	}
 
+	if c.GetTeamsSpec() != "" {
+		spec, err := fly.ParseTeamsSpec([]byte(c.GetTeamsSpec()))
+		if err != nil {
+			return bp, err
+		}
+		if err = flyClient.SetTeams(spec, client.deployArgs.PruneTeams); err != nil {
+			return bp, err
+		}
+	}
+
+	if c.GetWorkerCgroupVersion() != "" {
+		boshClient, err := client.buildBoshClient(c, tfOutputs)
+		if err != nil {
+			return bp, err
+		}
+		defer boshClient.Cleanup()
+		if err = client.switchWorkerCgroups(c, boshClient, flyClient); err != nil {
+			return bp, err
+		}
+	}
+
 	params := deployMessageParams{
 		ConcoursePassword:         bp.ConcoursePassword,
 		ConcourseUsername:         bp.ConcourseUsername,
@@ -245,16 +443,19 @@ type TerraformRequirements struct {
 	SourceAccessIP         string
 	HostedZoneID           string
 	HostedZoneRecordPrefix string
-	Domain                 string
+	// AdditionalRecordPrefixes are the records in the same hosted zone for any additional domains
+	AdditionalRecordPrefixes []string
+	Domain                   string
 }
 
 func (client *Client) checkPreTerraformConfigRequirements(conf config.ConfigView, selfUpdate bool) (TerraformRequirements, error) {
 	r := TerraformRequirements{
-		Region:                 conf.GetRegion(),
-		SourceAccessIP:         conf.GetSourceAccessIP(),
-		HostedZoneID:           conf.GetHostedZoneID(),
-		HostedZoneRecordPrefix: conf.GetHostedZoneRecordPrefix(),
-		Domain:                 conf.GetDomain(),
+		Region:                   conf.GetRegion(),
+		SourceAccessIP:           conf.GetSourceAccessIP(),
+		HostedZoneID:             conf.GetHostedZoneID(),
+		HostedZoneRecordPrefix:   conf.GetHostedZoneRecordPrefix(),
+		AdditionalRecordPrefixes: conf.GetAdditionalRecordPrefixes(),
+		Domain:                   conf.GetDomain(),
 	}
 
 	region := client.provider.Region()
@@ -281,6 +482,7 @@ func (client *Client) checkPreTerraformConfigRequirements(conf config.ConfigView
 	}
 	r.HostedZoneID = zone.HostedZoneID
 	r.HostedZoneRecordPrefix = zone.HostedZoneRecordPrefix
+	r.AdditionalRecordPrefixes = zone.AdditionalRecordPrefixes
 	r.Domain = zone.Domain
 
 	return r, nil
@@ -300,12 +502,21 @@ type Certs struct {
 	ConcourseCACert string
 }
 
+// DBProxyCredentials are what Concourse, UAA and CredHub log into the proxy that authenticates to RDS with IAM with
+type DBProxyCredentials struct {
+	Password string
+	CACert   string
+	Cert     string
+	Key      string
+}
+
 // Requirements represents the pre deployment requirements of a Concourse
 type Requirements struct {
 	Domain           string
 	DirectorPublicIP string
 	DirectorCerts    DirectorCerts
 	Certs            Certs
+	DBProxy          DBProxyCredentials
 }
 
 func (client *Client) checkPreDeployConfigRequirements(c func(u *certs.User) (*lego.Client, error), isDomainUpdated bool, cfg config.ConfigView, tfOutputs terraform.Outputs) (Requirements, error) {
@@ -322,6 +533,27 @@ func (client *Client) checkPreDeployConfigRequirements(c func(u *certs.User) (*l
 		cr.Domain = domain
 	}
 
+	var acmeDNS challenge.Provider
+	if cfg.GetDomain() != "" {
+		dnsProvider, err := client.dnsProvider(cfg)
+		if err != nil {
+			return cr, err
+		}
+		// terraform creates the records in zones hosted by the IAAS, alongside the rest of the infrastructure
+		if cfg.GetDNSProvider() != "" {
+			atcPublicIP, err := tfOutputs.Get("ATCPublicIP")
+			if err != nil {
+				return cr, err
+			}
+			for _, domain := range append([]string{cfg.GetDomain()}, cfg.GetAdditionalDomains()...) {
+				if err = dnsProvider.SetRecord(domain, "A", atcPublicIP); err != nil {
+					return cr, fmt.Errorf("error setting DNS record for %s: [%v]", domain, err)
+				}
+			}
+		}
+		acmeDNS = dns.ACMEProvider(dnsProvider)
+	}
+
 	dc := DirectorCerts{
 		DirectorCACert: cfg.GetDirectorCACert(),
 		DirectorCert:   cfg.GetDirectorCert(),
@@ -341,13 +573,27 @@ func (client *Client) checkPreDeployConfigRequirements(c func(u *certs.User) (*l
 		ConcourseCACert: cfg.GetConcourseCACert(),
 	}
 
-	cc, err = client.ensureConcourseCerts(c, isDomainUpdated, cc, cfg.GetDeployment(), cr.Domain)
+	domains := []string{cr.Domain}
+	if cfg.GetDomain() != "" {
+		domains = append(domains, cfg.GetAdditionalDomains()...)
+	}
+	cc, err = client.ensureConcourseCerts(c, isDomainUpdated, cc, cfg.GetDeployment(), domains, acmeDNS)
 	if err != nil {
 		return cr, err
 	}
 
 	cr.Certs = cc
 
+	cr.DBProxy, err = client.ensureDBProxyCredentials(c, DBProxyCredentials{
+		Password: cfg.GetDBProxyPassword(),
+		CACert:   cfg.GetDBProxyCACert(),
+		Cert:     cfg.GetDBProxyCert(),
+		Key:      cfg.GetDBProxyKey(),
+	}, cfg)
+	if err != nil {
+		return cr, err
+	}
+
 	cr.DirectorPublicIP, err = tfOutputs.Get("DirectorPublicIP")
 	if err != nil {
 		return cr, err
@@ -384,7 +630,7 @@ func (client *Client) ensureDirectorCerts(c func(u *certs.User) (*lego.Client, e
 		return certs, err
 	}
 
-	directorCerts, err := client.certGenerator(c, deployment, client.provider, ip, directorInternalIP.String())
+	directorCerts, err := client.certGenerator(c, deployment, client.provider, nil, ip, directorInternalIP.String())
 	if err != nil {
 		return certs, err
 	}
@@ -396,6 +642,26 @@ func (client *Client) ensureDirectorCerts(c func(u *certs.User) (*lego.Client, e
 	return certs, nil
 }
 
+// ensureDBProxyCredentials generates the password and certificate of the proxy on the web VMs the first time a
+// deployment that authenticates to RDS with IAM is deployed. The proxy only listens on the loopback address, so
+// its certificate is self-signed for 127.0.0.1
+func (client *Client) ensureDBProxyCredentials(c func(u *certs.User) (*lego.Client, error), creds DBProxyCredentials, cfg config.ConfigView) (DBProxyCredentials, error) {
+	if !cfg.GetDBIAMAuth() || creds.CACert != "" {
+		return creds, nil
+	}
+
+	proxyCerts, err := client.certGenerator(c, cfg.GetDeployment()+"-db-proxy", client.provider, nil, "127.0.0.1")
+	if err != nil {
+		return creds, err
+	}
+
+	creds.Password = client.passwordGenerator(20)
+	creds.CACert = string(proxyCerts.CACert)
+	creds.Cert = string(proxyCerts.Cert)
+	creds.Key = string(proxyCerts.Key)
+	return creds, nil
+}
+
 func timeTillExpiry(cert string) time.Duration {
 	block, _ := pem.Decode([]byte(cert))
 	if block == nil {
@@ -408,7 +674,7 @@ func timeTillExpiry(cert string) time.Duration {
 	return time.Until(c.NotAfter)
 }
 
-func (client *Client) ensureConcourseCerts(c func(u *certs.User) (*lego.Client, error), domainUpdated bool, cc Certs, deployment, domain string) (Certs, error) {
+func (client *Client) ensureConcourseCerts(c func(u *certs.User) (*lego.Client, error), domainUpdated bool, cc Certs, deployment string, domains []string, acmeDNS challenge.Provider) (Certs, error) {
 	certs := cc
 
 	if client.deployArgs.TLSCert != "" {
@@ -423,8 +689,9 @@ func (client *Client) ensureConcourseCerts(c func(u *certs.User) (*lego.Client,
 		return certs, nil
 	}
 
-	// If no domain has been provided by the user, the value of cfg.Domain is set to the ATC's public IP in checkPreDeployConfigRequirements
-	Certs, err := client.certGenerator(c, deployment, client.provider, domain)
+	// If no domain has been provided by the user, the value of cfg.Domain is set to the ATC's public IP in checkPreDeployConfigRequirements.
+	// Any additional domains are included as subject alternative names on the same certificate
+	Certs, err := client.certGenerator(c, deployment, client.provider, acmeDNS, domains...)
 	if err != nil {
 		return certs, err
 	}
@@ -466,6 +733,27 @@ func (client *Client) deployBosh(config config.ConfigView, tfOutputs terraform.O
 		return bp, err
 	}
 
+	// Only an existing deployment has a database to migrate
+	if len(boshStateBytes) > 0 && !client.deployArgs.SkipMigrationCheck {
+		preview, err1 := boshClient.PreviewMigrations()
+		if err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: unable to preview database migrations: [%v]\n", err1)
+		} else {
+			fmt.Fprint(client.stdout, preview)
+		}
+
+		// Pause so that the operator can plan the downtime, unless nobody is there to answer
+		if err1 == nil && len(preview.Pending) > 0 && !detach && !client.deployArgs.NonInteractive {
+			proceed, err1 := util.Confirm(os.Stdin, client.stdout, "Continue with the upgrade now?")
+			if err1 != nil {
+				return bp, err1
+			}
+			if !proceed {
+				return bp, fmt.Errorf("upgrade stopped before running %d database migrations, deploy again when ready", len(preview.Pending))
+			}
+		}
+	}
+
 	boshStateBytes, boshCredsBytes, err = boshClient.Deploy(boshStateBytes, boshCredsBytes, detach)
 	err1 := client.configClient.StoreAsset(bosh.StateFilename, boshStateBytes)
 	if err == nil {
@@ -528,45 +816,72 @@ func (client *Client) setUserIP(c config.ConfigView) (string, error) {
 
 // HostedZone represents a DNS hosted zone
 type HostedZone struct {
-	HostedZoneID           string
-	HostedZoneRecordPrefix string
-	Domain                 string
+	HostedZoneID             string
+	HostedZoneRecordPrefix   string
+	AdditionalRecordPrefixes []string
+	Domain                   string
 }
 
 func (client *Client) setHostedZone(c config.ConfigView, domain string) (HostedZone, error) {
 	zone := HostedZone{
-		HostedZoneID:           c.GetHostedZoneID(),
-		HostedZoneRecordPrefix: c.GetHostedZoneRecordPrefix(),
-		Domain:                 c.GetDomain(),
+		HostedZoneID:             c.GetHostedZoneID(),
+		HostedZoneRecordPrefix:   c.GetHostedZoneRecordPrefix(),
+		AdditionalRecordPrefixes: c.GetAdditionalRecordPrefixes(),
+		Domain:                   c.GetDomain(),
 	}
 	if domain == "" {
 		return zone, nil
 	}
 
+	// records in zones the IAAS doesn't host are set once terraform has allocated the IP
+	if c.GetDNSProvider() != "" {
+		return HostedZone{Domain: domain}, nil
+	}
+
 	hostedZoneName, hostedZoneID, err := client.provider.FindLongestMatchingHostedZone(domain)
 	if err != nil {
 		return zone, err
 	}
 	zone.HostedZoneID = hostedZoneID
+	zone.HostedZoneRecordPrefix = recordPrefix(c.GetIAAS(), domain, hostedZoneName)
 
-	if domain == hostedZoneName {
-		zone.HostedZoneRecordPrefix = ""
-	} else {
-		zone.HostedZoneRecordPrefix = strings.TrimSuffix(domain, fmt.Sprintf(".%s", hostedZoneName))
-		if c.GetIAAS() == "GCP" {
-			zone.HostedZoneRecordPrefix = fmt.Sprintf("%s.", zone.HostedZoneRecordPrefix)
+	zone.AdditionalRecordPrefixes = nil
+	for _, additionalDomain := range c.GetAdditionalDomains() {
+		additionalZoneName, additionalZoneID, err1 := client.provider.FindLongestMatchingHostedZone(additionalDomain)
+		if err1 != nil {
+			return zone, err1
+		}
+		if additionalZoneID != hostedZoneID {
+			return zone, fmt.Errorf("--domain %s is in DNS zone %s but must be in the same zone as %s, which is %s", additionalDomain, additionalZoneName, domain, hostedZoneName)
 		}
+		zone.AdditionalRecordPrefixes = append(zone.AdditionalRecordPrefixes, recordPrefix(c.GetIAAS(), additionalDomain, hostedZoneName))
 	}
 	zone.Domain = domain
 
 	_, err = client.stderr.Write([]byte(fmt.Sprintf(
-		"\nWARNING: adding record %s to DNS zone %s with name %s\n\n", domain, hostedZoneName, hostedZoneID)))
+		"\nWARNING: adding record %s to DNS zone %s with name %s\n\n", strings.Join(append([]string{domain}, c.GetAdditionalDomains()...), ", "), hostedZoneName, hostedZoneID)))
 	if err != nil {
 		return zone, err
 	}
 	return zone, err
 }
 
+// recordPrefix is the name of the record for domain within hostedZoneName. Terraform's Route53
+// record can't be given an empty name, so on AWS the record for the zone apex is named in full
+func recordPrefix(iaasName, domain, hostedZoneName string) string {
+	if domain == hostedZoneName {
+		if iaasName == "AWS" {
+			return domain
+		}
+		return ""
+	}
+	prefix := strings.TrimSuffix(domain, fmt.Sprintf(".%s", hostedZoneName))
+	if iaasName == "GCP" {
+		prefix = fmt.Sprintf("%s.", prefix)
+	}
+	return prefix
+}
+
 const deployMsg = `DEPLOY SUCCESSFUL. Log in with:
 fly --target {{.Project}} login{{if not .ConcourseUserProvidedCert}} --insecure{{end}} --concourse-url https://{{.Domain}} --username {{.ConcourseUsername}} --password {{.ConcoursePassword}}
 
@@ -622,3 +937,33 @@ func loadDirectorCreds(configClient config.IClient) ([]byte, error) {
 
 	return configClient.LoadAsset(bosh.CredsFilename)
 }
+
+// redeployBosh deploys the director and Concourse again with the state and credentials stored by the last
+// deploy, storing them again afterwards
+func (client *Client) redeployBosh(conf config.ConfigView, tfOutputs terraform.Outputs) error {
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	boshStateBytes, err := loadDirectorState(client.configClient)
+	if err != nil {
+		return err
+	}
+	boshCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+
+	boshStateBytes, boshCredsBytes, err = boshClient.Deploy(boshStateBytes, boshCredsBytes, false)
+	err1 := client.configClient.StoreAsset(bosh.StateFilename, boshStateBytes)
+	if err == nil {
+		err = err1
+	}
+	err1 = client.configClient.StoreAsset(bosh.CredsFilename, boshCredsBytes)
+	if err == nil {
+		err = err1
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package concourse
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/lego"
+
+	"github.com/EngineerBetter/control-tower/certs"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/iaas"
+)
+
+func Test_recordPrefix(t *testing.T) {
+	tests := []struct {
+		name           string
+		iaasName       string
+		domain         string
+		hostedZoneName string
+		want           string
+	}{
+		{
+			name:           "subdomain on AWS",
+			iaasName:       "AWS",
+			domain:         "ci.example.com",
+			hostedZoneName: "example.com",
+			want:           "ci",
+		},
+		{
+			name:           "apex on AWS is named in full",
+			iaasName:       "AWS",
+			domain:         "example.com",
+			hostedZoneName: "example.com",
+			want:           "example.com",
+		},
+		{
+			name:           "subdomain on GCP",
+			iaasName:       "GCP",
+			domain:         "ci.example.com",
+			hostedZoneName: "example.com",
+			want:           "ci.",
+		},
+		{
+			name:           "apex on GCP",
+			iaasName:       "GCP",
+			domain:         "example.com",
+			hostedZoneName: "example.com",
+			want:           "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordPrefix(tt.iaasName, tt.domain, tt.hostedZoneName); got != tt.want {
+				t.Errorf("recordPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ensureDBProxyCredentials(t *testing.T) {
+	var generated []string
+	client := &Client{
+		certGenerator: func(constructor func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, dnsProvider challenge.Provider, ip ...string) (*certs.Certs, error) {
+			generated = append(generated, ip...)
+			return &certs.Certs{CACert: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")}, nil
+		},
+		passwordGenerator: func(int) string { return "generated" },
+	}
+
+	got, err := client.ensureDBProxyCredentials(nil, DBProxyCredentials{}, config.Config{Deployment: "control-tower-test"})
+	if err != nil || got != (DBProxyCredentials{}) {
+		t.Errorf("ensureDBProxyCredentials() = %+v, %v, want nothing generated without IAM authentication", got, err)
+	}
+
+	conf := config.Config{Deployment: "control-tower-test", DBIAMAuth: true}
+	got, err = client.ensureDBProxyCredentials(nil, DBProxyCredentials{}, conf)
+	if err != nil {
+		t.Fatalf("ensureDBProxyCredentials() error = %v", err)
+	}
+	if want := (DBProxyCredentials{Password: "generated", CACert: "ca", Cert: "cert", Key: "key"}); got != want {
+		t.Errorf("ensureDBProxyCredentials() = %+v, want %+v", got, want)
+	}
+	if len(generated) != 1 || generated[0] != "127.0.0.1" {
+		t.Errorf("ensureDBProxyCredentials() generated a certificate for %v, want 127.0.0.1", generated)
+	}
+
+	existing := DBProxyCredentials{Password: "kept", CACert: "kept-ca", Cert: "kept-cert", Key: "kept-key"}
+	if got, err = client.ensureDBProxyCredentials(nil, existing, conf); err != nil || got != existing {
+		t.Errorf("ensureDBProxyCredentials() = %+v, %v, want the existing credentials kept", got, err)
+	}
+}
@@ -0,0 +1,277 @@
+package concourse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/artifacts"
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/commands/exportdeployment"
+	"github.com/EngineerBetter/control-tower/commands/importdeployment"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deployarchive"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/opsassets"
+)
+
+const (
+	archiveConfigFile   = "config.json"
+	archiveManifestFile = "concourse-manifest.yml"
+	archiveTFStateFile  = "terraform.tfstate"
+	archivePinsDir      = "pins/"
+	archiveArtifactsDir = "artifacts/"
+	// gcsDefaultStateFile is where the gcs terraform backend keeps the state of the default workspace
+	gcsDefaultStateFile = "default.tfstate"
+)
+
+// ExportDeployment writes the config, credentials, state and deployed manifest of a deployment to
+// an archive, along with the artifact versions it was deployed with, so that it can be recreated
+// somewhere else by ImportDeployment
+func (client *Client) ExportDeployment(e exportdeployment.Args) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	configBytes, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	archive := deployarchive.Archive{
+		Metadata: deployarchive.Metadata{
+			ControlTowerVersion: client.version,
+			IAAS:                client.provider.IAAS().String(),
+			Region:              conf.GetRegion(),
+			Namespace:           conf.GetNamespace(),
+			Deployment:          conf.GetDeployment(),
+			ExportedAt:          time.Now().UTC(),
+		},
+		Secret: map[string][]byte{archiveConfigFile: configBytes},
+		Public: client.pinnedArtifacts(),
+	}
+
+	assets := map[string]string{
+		bosh.StateFilename: bosh.StateFilename,
+		bosh.CredsFilename: bosh.CredsFilename,
+		archiveTFStateFile: client.tfStateAsset(conf),
+	}
+	for name, asset := range assets {
+		exists, err1 := client.configClient.HasAsset(asset)
+		if err1 != nil {
+			return err1
+		}
+		if !exists {
+			continue
+		}
+		archive.Secret[name], err1 = client.configClient.LoadAsset(asset)
+		if err1 != nil {
+			return fmt.Errorf("error loading %s to export: [%v]", asset, err1)
+		}
+	}
+
+	if e.ArtifactsDir != "" {
+		index, err1 := client.downloadArtifacts(conf, e.ArtifactsDir)
+		if err1 != nil {
+			return fmt.Errorf("error downloading artifacts to export: [%v]", err1)
+		}
+		archive.Public[archiveArtifactsDir+artifacts.IndexFilename] = index
+	}
+
+	manifest, err := client.deployedManifest(conf)
+	if err != nil {
+		fmt.Fprintf(client.stderr, "WARNING: exporting without the deployed manifest, as it could not be fetched: [%v]\n", err)
+	} else {
+		archive.Secret[archiveManifestFile] = manifest
+	}
+
+	if err = deployarchive.WriteFile(e.Archive, archive, e.Passphrase); err != nil {
+		return fmt.Errorf("error writing deployment archive: [%v]", err)
+	}
+	_, err = fmt.Fprintf(client.stdout, "Exported %s to %s\n", conf.GetDeployment(), e.Archive)
+	return err
+}
+
+// ImportDeployment stores the config from an archive written by ExportDeployment, so that the next
+// deploy recreates the exported deployment. The exported state is only imported when asked for, as
+// it describes infrastructure that only exists where the deployment was exported from
+func (client *Client) ImportDeployment(i importdeployment.Args) error {
+	archive, err := deployarchive.ReadFile(i.Archive, i.Passphrase)
+	if err != nil {
+		return fmt.Errorf("error reading deployment archive: [%v]", err)
+	}
+
+	if archive.Metadata.IAAS != client.provider.IAAS().String() {
+		return fmt.Errorf("archive was exported from %s and cannot be imported into %s", archive.Metadata.IAAS, client.provider.IAAS())
+	}
+	if mismatched := client.mismatchedPins(archive); len(mismatched) > 0 {
+		return fmt.Errorf("archive pins different versions of %s than this control-tower, import it with control-tower %s instead", strings.Join(mismatched, ", "), archive.Metadata.ControlTowerVersion)
+	}
+	if err = checkArtifacts(archive, i.ArtifactsDir); err != nil {
+		return err
+	}
+	if i.IncludeState && archive.Metadata.Region != client.provider.Region() {
+		return fmt.Errorf("the state in the archive describes infrastructure in %s, so it cannot be imported into %s", archive.Metadata.Region, client.provider.Region())
+	}
+
+	err = client.configClient.EnsureBucketExists()
+	if err != nil {
+		return fmt.Errorf("error ensuring config bucket exists before import: [%v]", err)
+	}
+	exists, err := client.configClient.ConfigExists()
+	if err != nil {
+		return fmt.Errorf("error determining if config already exists [%v]", err)
+	}
+	if exists {
+		return fmt.Errorf("a deployment already exists here, destroy it or import with a different name or namespace")
+	}
+
+	var conf config.Config
+	if err = json.Unmarshal(archive.Secret[archiveConfigFile], &conf); err != nil {
+		return fmt.Errorf("error reading config from deployment archive: [%v]", err)
+	}
+
+	// The deployment takes on the identity of where it is imported to, keeping everything else
+	target := client.configClient.NewConfig()
+	conf.ConfigBucket = target.ConfigBucket
+	conf.Deployment = target.Deployment
+	conf.Namespace = target.Namespace
+	conf.Project = target.Project
+	if conf.Region != target.Region {
+		conf.Region = target.Region
+		conf.AvailabilityZone = client.provider.Zone("", conf.ConcourseWorkerSize)
+	}
+
+	if i.IncludeState {
+		assets := map[string]string{
+			bosh.StateFilename: bosh.StateFilename,
+			bosh.CredsFilename: bosh.CredsFilename,
+			archiveTFStateFile: client.tfStateAsset(conf),
+		}
+		for name, asset := range assets {
+			contents, ok := archive.Secret[name]
+			if !ok {
+				continue
+			}
+			if err = client.configClient.StoreAsset(asset, contents); err != nil {
+				return fmt.Errorf("error storing imported %s: [%v]", asset, err)
+			}
+		}
+	}
+
+	if err = client.configClient.Update(conf); err != nil {
+		return fmt.Errorf("error storing imported config: [%v]", err)
+	}
+	_, err = fmt.Fprintf(client.stdout, "Imported %s exported at %s, deploy to recreate it\n", archive.Metadata.Deployment, archive.Metadata.ExportedAt.Format(time.RFC3339))
+	return err
+}
+
+// downloadArtifacts downloads everything deploying conf needs from the internet into dir, returning
+// the index written alongside them. CLIs are downloaded for linux/amd64, where the director runs, and
+// for the platform control-tower is running on
+func (client *Client) downloadArtifacts(conf config.ConfigView, dir string) ([]byte, error) {
+	list, err := bosh.Artifacts(conf, client.provider, client.versionFile, "linux/amd64", runtime.GOOS+"/"+runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+	index, err := artifacts.Download(list, dir, client.stdout)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.tfCLI.MirrorProviders(client.tfInputVarsFactory.NewInputVars(conf), filepath.Join(dir, artifacts.ProvidersDir)); err != nil {
+		return nil, fmt.Errorf("error downloading terraform providers: [%v]", err)
+	}
+	if err = index.AddFiles(dir, artifacts.ProvidersDir); err != nil {
+		return nil, err
+	}
+	return index.Write(dir)
+}
+
+// checkArtifacts checks that dir holds the artifacts exported with the archive, unchanged
+func checkArtifacts(archive deployarchive.Archive, dir string) error {
+	if dir == "" {
+		return nil
+	}
+	exported, ok := archive.Public[archiveArtifactsDir+artifacts.IndexFilename]
+	if !ok {
+		return fmt.Errorf("--artifacts-dir was given but the archive was exported without artifacts, export it again with --artifacts-dir")
+	}
+	index, err := ioutil.ReadFile(filepath.Join(dir, artifacts.IndexFilename))
+	if err != nil {
+		return fmt.Errorf("error reading artifacts: [%v]", err)
+	}
+	if !bytes.Equal(index, exported) {
+		return fmt.Errorf("the artifacts in %s are not the ones exported with the archive", dir)
+	}
+	_, _, err = artifacts.Load(dir)
+	return err
+}
+
+// checkArtifactsDir refuses to deploy from a directory of artifacts that lacks any the deployment needs,
+// rather than finding out part way through the deploy that something has to be downloaded
+func (client *Client) checkArtifactsDir(conf config.ConfigView) error {
+	list, err := bosh.Artifacts(conf, client.provider, client.versionFile, "linux/amd64", runtime.GOOS+"/"+runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	if missing := client.deployArgs.Artifacts.Missing(list); len(missing) > 0 {
+		return fmt.Errorf("%s is missing artifacts this deployment needs, export it again with --artifacts-dir to download them: %s", client.deployArgs.ArtifactsDir, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// pinnedArtifacts are the versions of everything control-tower deploys, which are compiled in and
+// so can only be reproduced by the same build of control-tower
+func (client *Client) pinnedArtifacts() map[string][]byte {
+	versions, _ := client.provider.Choose(iaas.Choice{
+		AWS: opsassets.AwsConcourseVersions,
+		GCP: opsassets.GcpConcourseVersions,
+	}).([]byte)
+	shas, _ := client.provider.Choose(iaas.Choice{
+		AWS: opsassets.AwsConcourseSHAs,
+		GCP: opsassets.GcpConcourseSHAs,
+	}).([]byte)
+	return map[string][]byte{
+		archivePinsDir + "concourse-versions.json":                      versions,
+		archivePinsDir + "concourse-shas.json":                          shas,
+		archivePinsDir + "createenv-dependencies-and-cli-versions.json": client.versionFile,
+	}
+}
+
+func (client *Client) mismatchedPins(archive deployarchive.Archive) []string {
+	var mismatched []string
+	for name, contents := range client.pinnedArtifacts() {
+		if !bytes.Equal(archive.Public[name], contents) {
+			mismatched = append(mismatched, strings.TrimPrefix(name, archivePinsDir))
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched
+}
+
+func (client *Client) tfStateAsset(conf config.ConfigView) string {
+	if client.provider.IAAS() == iaas.GCP {
+		return gcsDefaultStateFile
+	}
+	return conf.GetTFStatePath()
+}
+
+func (client *Client) deployedManifest(conf config.ConfigView) ([]byte, error) {
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return nil, err
+	}
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return nil, err
+	}
+	defer boshClient.Cleanup()
+	return boshClient.Manifest()
+}
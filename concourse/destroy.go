@@ -3,21 +3,62 @@ package concourse
 import (
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/EngineerBetter/control-tower/commands/destroy"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/exitcode"
 	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/terraform"
 )
 
 // Destroy destroys a concourse instance
-func (client *Client) Destroy() error {
+func (client *Client) Destroy(d destroy.Args) (err error) {
 
 	conf, err := client.configClient.Load()
 	if err != nil {
 		return err
 	}
+	defer func() { client.sendAuditEvent(conf, "destroy", err) }()
+
+	// terraform would fail to delete the database only once the VMs have already gone
+	if conf.DBDeletionProtection {
+		return fmt.Errorf("The database of %s has deletion protection. Deploy again with --db-deletion-protection=false before destroying", conf.Deployment)
+	}
+
+	staleAfter := deploylock.DefaultStaleAfter
+	if d.LockStaleMinutesIsSet {
+		staleAfter = time.Duration(d.LockStaleMinutes) * time.Minute
+	}
+	lock, err := client.acquireDeploymentLock(conf, "destroy", staleAfter)
+	if err != nil {
+		return err
+	}
+	// the lock goes with the config bucket once the destroy succeeds
+	bucketDeleted := false
+	defer func() {
+		if bucketDeleted {
+			lock.Stop()
+			return
+		}
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	// once VMs have been deleted, destroying again picks up where a failed destroy left off
+	deleting := false
+	defer func() {
+		if deleting {
+			err = exitcode.WithCode(exitcode.Partial, err)
+		}
+	}()
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
 	var volumesToDelete []string
+	deleting = true
 
 	switch client.provider.IAAS() {
 
@@ -26,11 +67,7 @@ func (client *Client) Destroy() error {
 		if err1 != nil {
 			return err1
 		}
-		vpcID, err2 := tfOutputs.Get("VPCID")
-		if err2 != nil {
-			return err2
-		}
-		volumesToDelete, err1 = client.provider.DeleteVMsInVPC(vpcID)
+		volumesToDelete, err1 = client.deleteAWSVMs(conf, tfOutputs)
 		if err1 != nil {
 			return err1
 		}
@@ -40,17 +77,27 @@ func (client *Client) Destroy() error {
 		if err1 != nil {
 			return err1
 		}
-		zone := client.provider.Zone("", "")
+		zone := client.provider.Zone(conf.GetAvailabilityZone(), "")
 		err1 = client.provider.DeleteVMsInDeployment(zone, project, conf.GetDeployment())
 		if err1 != nil {
 			return err1
 		}
 	}
 
+	if err = lock.SetStage(deploylock.StageTerraform); err != nil {
+		return err
+	}
 	err = client.tfCLI.Destroy(tfInputVars)
 	if err != nil {
 		return err
 	}
+	if err = lock.SetStage(""); err != nil {
+		return err
+	}
+
+	if conf.GetDNSProvider() != "" && conf.GetDomain() != "" {
+		client.deleteDNSRecords(conf)
+	}
 
 	if client.provider.IAAS() == iaas.AWS {
 		if len(volumesToDelete) > 0 {
@@ -64,11 +111,50 @@ func (client *Client) Destroy() error {
 	if err = client.configClient.DeleteAll(conf); err != nil {
 		return err
 	}
+	bucketDeleted = true
 
 	return writeDestroySuccessMessage(client.stdout)
 }
+
+// deleteDNSRecords removes the records that terraform doesn't manage. The infrastructure has gone by
+// this point, so failing to remove them is reported without failing the destroy
+func (client *Client) deleteDNSRecords(conf config.ConfigView) {
+	dnsProvider, providerErr := client.dnsProvider(conf)
+	for _, domain := range append([]string{conf.GetDomain()}, conf.GetAdditionalDomains()...) {
+		err := providerErr
+		if err == nil {
+			err = dnsProvider.DeleteRecord(domain, "A")
+		}
+		if err != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to delete DNS record for %s: [%v]\n", domain, err)
+		}
+	}
+}
+
 func writeDestroySuccessMessage(stdout io.Writer) error {
 	_, err := stdout.Write([]byte("\nDESTROY SUCCESSFUL\n\n"))
 
 	return err
 }
+
+// deleteAWSVMs deletes the VMs in the deployment's VPC. An existing VPC is shared with VMs that aren't the
+// deployment's, so only those in the deployment's security groups are deleted from it
+func (client *Client) deleteAWSVMs(conf config.ConfigView, tfOutputs terraform.Outputs) ([]string, error) {
+	if conf.GetExistingNetwork() == "" {
+		vpcID, err := tfOutputs.Get("VPCID")
+		if err != nil {
+			return nil, err
+		}
+		return client.provider.DeleteVMsInVPC(vpcID)
+	}
+
+	var groupIDs []string
+	for _, output := range []string{"DirectorSecurityGroupID", "VMsSecurityGroupID", "ATCSecurityGroupID"} {
+		groupID, err := tfOutputs.Get(output)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+	return client.provider.DeleteVMsInSecurityGroups(groupIDs)
+}
@@ -0,0 +1,62 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/control-tower/commands/drift"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// Drift reports, and optionally reverts, changes made to the Concourse teams and pipelines outside of control-tower
+func (client *Client) Drift(d drift.Args) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", conf.GetDomain()),
+		Username: conf.GetConcourseUsername(),
+		Password: conf.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return err
+	}
+	defer flyClient.Cleanup()
+
+	differences, err := flyClient.Drift(conf)
+	if err != nil {
+		return fmt.Errorf("error detecting drift: [%v]", err)
+	}
+
+	if len(differences) == 0 {
+		_, err = fmt.Fprintln(client.stdout, "No drift detected")
+		return err
+	}
+
+	fmt.Fprintln(client.stdout, "Detected changes made outside of control-tower:")
+	for _, difference := range differences {
+		fmt.Fprintf(client.stdout, "  - %s\n", difference)
+	}
+
+	if !d.Revert {
+		return fmt.Errorf("detected %d change(s) made outside of control-tower, re-run with --revert to restore the bootstrap configuration", len(differences))
+	}
+
+	// Teams that aren't in the teams file are only destroyed when control-tower manages the teams from one,
+	// as they are only reported as drift then
+	fmt.Fprintln(client.stdout, "Reverting teams and pipelines to the control-tower configuration")
+	spec, err := fly.BootstrapTeamsSpec(conf)
+	if err != nil {
+		return err
+	}
+	if err = flyClient.SetTeams(spec, conf.GetTeamsSpec() != ""); err != nil {
+		return err
+	}
+	return flyClient.SetDefaultPipeline(conf, true)
+}
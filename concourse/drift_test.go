@@ -0,0 +1,76 @@
+package concourse
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/commands/drift"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/config/configfakes"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/fly/flyfakes"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/iaas/iaasfakes"
+)
+
+func TestClient_DriftRevert(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      config.Config
+		wantSpec  fly.TeamsSpec
+		wantPrune bool
+	}{
+		{
+			name: "main team only",
+			conf: config.Config{ConcourseUsername: "admin", MainGithubUsers: "someone"},
+			wantSpec: fly.TeamsSpec{
+				"main": {"local-user": {"admin"}, "github-user": {"someone"}},
+			},
+		},
+		{
+			name: "teams file",
+			conf: config.Config{ConcourseUsername: "admin", TeamsSpec: "dev:\n  github-team: [org:dev]\n"},
+			wantSpec: fly.TeamsSpec{
+				"main": {"local-user": {"admin"}},
+				"dev":  {"github-team": {"org:dev"}},
+			},
+			wantPrune: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configClient := new(configfakes.FakeIClient)
+			configClient.LoadReturns(tt.conf, nil)
+			flyClient := new(flyfakes.FakeIClient)
+			flyClient.DriftReturns([]string{"team main: auth has been modified"}, nil)
+			client := &Client{
+				configClient: configClient,
+				flyClientFactory: func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+					return flyClient, nil
+				},
+				provider: new(iaasfakes.FakeProvider),
+				stdout:   ioutil.Discard,
+				stderr:   ioutil.Discard,
+			}
+
+			if err := client.Drift(drift.Args{Revert: true}); err != nil {
+				t.Fatalf("Drift() error = %v", err)
+			}
+			if flyClient.SetTeamsCallCount() != 1 {
+				t.Fatalf("Drift() set teams %d times, want once", flyClient.SetTeamsCallCount())
+			}
+			spec, prune := flyClient.SetTeamsArgsForCall(0)
+			if !reflect.DeepEqual(spec, tt.wantSpec) {
+				t.Errorf("Drift() set teams %v, want %v", spec, tt.wantSpec)
+			}
+			if prune != tt.wantPrune {
+				t.Errorf("Drift() pruned teams = %t, want %t", prune, tt.wantPrune)
+			}
+			if flyClient.SetDefaultPipelineCallCount() != 1 {
+				t.Errorf("Drift() set the pipeline %d times, want once", flyClient.SetDefaultPipelineCallCount())
+			}
+		})
+	}
+}
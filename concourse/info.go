@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"regexp"
@@ -25,12 +26,38 @@ type Info struct {
 	Instances   []bosh.Instance `json:"instances"`
 	CertExpiry  string          `json:"cert_expiry"`
 	GatewayUser string
+	// ConcourseVersion is only populated once CheckConcourseVersion has been called
+	ConcourseVersion *ConcourseVersionStatus `json:"concourse_version,omitempty"`
+	// ExternalWorker is only populated once a deploy with --external-worker-allow-ips has generated its key
+	ExternalWorker *ExternalWorkerInfo `json:"external_worker,omitempty"`
+	// Database is only populated for a CloudSQL instance deployed with --db-ha
+	Database *iaas.DatabaseAvailability `json:"database,omitempty"`
 }
 
+// ExternalWorkerInfo is what a worker outside the deployment needs to register with the TSA
+type ExternalWorkerInfo struct {
+	TSAHost          string `json:"tsa_host"`
+	TSAPublicKey     string `json:"tsa_public_key"`
+	TSAFingerprint   string `json:"tsa_public_key_fingerprint"`
+	WorkerPublicKey  string `json:"worker_public_key"`
+	WorkerPrivateKey string `json:"worker_private_key"`
+}
+
+// tsaPort is where the web node registers workers, as in the Concourse manifest
+const tsaPort = "2222"
+
+// prometheusMetricsPort is where the web node serves metrics for Prometheus, as set in prometheus-metrics.yml
+const prometheusMetricsPort = "9391"
+
 // TerraformInfo represents the terraform output fields needed for the info templates
 type TerraformInfo struct {
-	DirectorPublicIP string
-	NatGatewayIP     string
+	DirectorPublicIP  string
+	NatGatewayIP      string
+	InternalLBDNSName string
+	// DBReplicaAddress is only populated for a deployment with --db-read-replica
+	DBReplicaAddress string
+	// BastionPublicIP is only populated for a deployment with --bastion
+	BastionPublicIP string
 }
 
 // FetchInfo fetches and builds the info
@@ -70,6 +97,11 @@ func (client *Client) FetchInfo() (*Info, error) {
 		}
 	}
 
+	var externalWorker *ExternalWorkerInfo
+	if len(conf.ExternalWorkerAllowIPs) > 0 && len(directorCredsBytes) > 0 {
+		externalWorker = externalWorkerInfo(directorCredsBytes, conf.Domain)
+	}
+
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
 	switch client.provider.IAAS() {
@@ -99,23 +131,61 @@ func (client *Client) FetchInfo() (*Info, error) {
 		NatGatewayIP:     natGatewayIP,
 	}
 
-	userIP, err1 := client.ipChecker()
-	if err1 != nil {
-		return nil, err1
+	if conf.InternalLB {
+		terraformInfo.InternalLBDNSName, err = tfOutputs.Get("InternalLBDNSName")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	directorSecurityGroupID, err1 := tfOutputs.Get("DirectorSecurityGroupID")
-	if err1 != nil {
-		return nil, err1
+	if conf.DBReadReplica {
+		terraformInfo.DBReplicaAddress, err = tfOutputs.Get("DBReplicaAddress")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Bastion {
+		terraformInfo.BastionPublicIP, err = tfOutputs.Get("BastionPublicIP")
+		if err != nil {
+			return nil, err
+		}
 	}
-	whitelisted, err1 := client.provider.CheckForWhitelistedIP(userIP, directorSecurityGroupID)
-	if err1 != nil {
-		return nil, err1
+
+	// The director is reached through the bastion when there is one, whose firewall only admits SSH, so
+	// the check of the ports the director is reached on only applies without one
+	if !conf.Bastion {
+		userIP, err1 := client.ipChecker()
+		if err1 != nil {
+			return nil, err1
+		}
+
+		directorSecurityGroupID, err1 := tfOutputs.Get("DirectorSecurityGroupID")
+		if err1 != nil {
+			return nil, err1
+		}
+		whitelisted, err1 := client.provider.CheckForWhitelistedIP(userIP, directorSecurityGroupID)
+		if err1 != nil {
+			return nil, err1
+		}
+
+		if !whitelisted {
+			err1 = fmt.Errorf("Do you need to add your IP %s to the %s-director security group/source range entry for director firewall (for ports 22, 6868, and 25555)?", userIP, conf.Deployment)
+			return nil, err1
+		}
 	}
 
-	if !whitelisted {
-		err1 = fmt.Errorf("Do you need to add your IP %s to the %s-director security group/source range entry for director firewall (for ports 22, 6868, and 25555)?", userIP, conf.Deployment)
-		return nil, err1
+	var database *iaas.DatabaseAvailability
+	if client.provider.IAAS() == iaas.GCP && conf.DBHA {
+		dbName, err1 := tfOutputs.Get("DBName")
+		if err1 != nil {
+			return nil, err1
+		}
+		availability, err1 := client.provider.DatabaseAvailability(dbName)
+		if err1 != nil {
+			return nil, err1
+		}
+		database = &availability
 	}
 
 	boshClient, err := client.buildBoshClient(conf, tfOutputs)
@@ -130,14 +200,35 @@ func (client *Client) FetchInfo() (*Info, error) {
 	}
 
 	return &Info{
-		Terraform:   terraformInfo,
-		Config:      conf,
-		Instances:   instances,
-		GatewayUser: gatewayUser,
-		CertExpiry:  certExpiry,
+		Terraform:      terraformInfo,
+		Config:         conf,
+		Instances:      instances,
+		GatewayUser:    gatewayUser,
+		CertExpiry:     certExpiry,
+		ExternalWorker: externalWorker,
+		Database:       database,
 	}, nil
 }
 
+// externalWorkerInfo reads the TSA host key and the external worker key pair from the Concourse
+// credentials, returning nil when the worker key hasn't been generated yet
+func externalWorkerInfo(creds []byte, domain string) *ExternalWorkerInfo {
+	info := &ExternalWorkerInfo{TSAHost: net.JoinHostPort(domain, tsaPort)}
+	for path, value := range map[string]*string{
+		"tsa_host_key/public_key":             &info.TSAPublicKey,
+		"tsa_host_key/public_key_fingerprint": &info.TSAFingerprint,
+		"external_worker_key/public_key":      &info.WorkerPublicKey,
+		"external_worker_key/private_key":     &info.WorkerPrivateKey,
+	} {
+		v, err := yaml.Path(creds, path)
+		if err != nil {
+			return nil
+		}
+		*value = strings.TrimSpace(v)
+	}
+	return info
+}
+
 const infoTemplate = `Deployment:
 	Namespace: {{.Config.Namespace}}
 	IAAS:      {{.Config.IAAS}}
@@ -150,13 +241,17 @@ Workers:
 
 Instances:
 {{range .Instances}}
-	{{.Name}} {{.IP | replace "\n" ","}} {{.State}}
+	{{.Name}} {{.IP | replace "\n" ","}} {{.State}}{{if .Uptime}}
+		uptime: {{.Uptime}}, cpu: {{.CPU}}, memory: {{.Memory}}, ephemeral disk: {{.EphemeralDisk}}{{if .PersistentDisk}}, persistent disk: {{.PersistentDisk}}{{end}}{{end}}
 {{end}}
 
 Concourse credentials:
 	username: {{.Config.ConcourseUsername}}
 	password: {{.Config.ConcoursePassword}}
-	URL:      https://{{.Config.Domain}}
+	URL:      https://{{.Config.Domain}}{{range .Config.AdditionalDomains}}
+	Also:     https://{{.}}{{end}}{{with .Config.ExternalURL}}
+	External: {{.}}{{end}}{{if .Terraform.InternalLBDNSName}}
+	Internal: https://{{.Terraform.InternalLBDNSName}}{{end}}
 
 Credhub credentials:
 	username: {{.Config.CredhubUsername}}
@@ -170,7 +265,37 @@ Grafana credentials (if metrics are enabled):
 	password: {{.Config.ConcoursePassword}}
 	URL:      https://{{.Config.Domain}}:3000
 
-Bosh credentials:
+{{with .PrometheusTargets}}Prometheus scrape endpoints (private network only):{{range .}}
+	{{.}}{{end}}
+
+{{end}}{{if .Config.MetricsScrapeAllowIPs}}Metrics scrape endpoint (allowed IPs only):
+	username: prometheus
+	password: {{.Config.MetricsScrapePassword}}
+	URL:      https://{{.Config.Domain}}:9392/metrics
+
+{{end}}{{with .ExternalWorker}}External worker registration (allowed IPs only):
+	TSA host:             {{.TSAHost}}
+	TSA host fingerprint: {{.TSAFingerprint}}
+	TSA public key:       {{.TSAPublicKey}}
+	Worker public key:    {{.WorkerPublicKey}}
+	Worker private key:
+		{{ .WorkerPrivateKey | replace "\n" "\n\t\t"}}
+	Save the TSA public key as tsa_host_key.pub and the worker private key as worker_key, then run:
+		concourse worker --work-dir /opt/concourse/worker --tsa-host {{.TSAHost}} --tsa-public-key tsa_host_key.pub --tsa-worker-private-key worker_key
+
+{{end}}{{with .Database}}Database:
+	CloudSQL instance: {{.Name}} {{.State}}
+	Availability:      {{.AvailabilityType}}
+	Primary zone:      {{.PrimaryZone}}
+	Standby zone:      {{if .StandbyZone}}{{.StandbyZone}}{{else}}none, failover is unavailable{{end}}
+
+{{end}}{{if .Terraform.BastionPublicIP}}Bastion:
+	host:        {{.Terraform.BastionPublicIP}}
+	user:        ubuntu
+	private key:
+		{{ .Config.BastionPrivateKey | replace "\n" "\n\t\t"}}
+
+{{end}}Bosh credentials:
 	username: {{.Config.DirectorUsername}}
 	password: {{.Config.DirectorPassword}}
 	IP:       {{.Terraform.DirectorPublicIP}}
@@ -179,11 +304,33 @@ Bosh credentials:
 
 BOSH-generated NAT certs will expire on: {{ .CertExpiry }}
 
-Uses Control-Tower version {{.Config.Version}}
+{{with .ConcourseVersion}}Concourse version {{.}}
+
+{{end}}Uses Control-Tower version {{.Config.Version}}
 
 Built by {{"EngineerBetter http://engineerbetter.com" | blue}}
 `
 
+// PrometheusTargets returns the private scrape endpoint of each web instance when Prometheus metrics are enabled
+func (info *Info) PrometheusTargets() []string {
+	if !info.Config.EnablePrometheusMetrics {
+		return nil
+	}
+	var targets []string
+	for _, instance := range info.Instances {
+		if !strings.HasPrefix(instance.Name, "web/") {
+			continue
+		}
+		for _, ip := range strings.Split(instance.IP, "\n") {
+			if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil && parsed.IsPrivate() {
+				targets = append(targets, fmt.Sprintf("http://%s/metrics", net.JoinHostPort(parsed.String(), prometheusMetricsPort)))
+				break
+			}
+		}
+	}
+	return targets
+}
+
 func (info *Info) String() string {
 	t := template.Must(template.New("info").Funcs(template.FuncMap{
 		"replace": func(old, new, s string) string {
@@ -226,7 +373,8 @@ export BOSH_CLIENT={{.Config.DirectorUsername}}
 export BOSH_CLIENT_SECRET={{.Config.DirectorPassword}}
 export BOSH_GW_USER={{.GatewayUser}}
 export BOSH_GW_PRIVATE_KEY={{.Config.PrivateKey | to_file}}
-export CREDHUB_SERVER={{.Config.CredhubURL}}
+{{if .Terraform.BastionPublicIP}}export BOSH_ALL_PROXY=ssh+socks5://ubuntu@{{.Terraform.BastionPublicIP}}:22?private-key={{.Config.BastionPrivateKey | to_file}}
+{{end}}export CREDHUB_SERVER={{.Config.CredhubURL}}
 export CREDHUB_CA_CERT='{{.Config.CredhubCACert}}'
 export CREDHUB_CLIENT=credhub_admin
 export CREDHUB_SECRET={{.Config.CredhubAdminClientSecret}}
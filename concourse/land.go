@@ -0,0 +1,165 @@
+package concourse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// workerDiagnostics is run on a landed or retired worker over bosh ssh, to show the state it was drained in
+const workerDiagnostics = "uptime; sudo /var/vcap/bosh/bin/monit summary; df -h /var/vcap/data; sudo tail -n 50 /var/vcap/sys/log/worker/worker.stderr.log"
+
+// landWorker lands the named worker through the ATC, so that it takes no new builds and leaves once its
+// running builds have finished. Retiring a worker also waits for it to land, stops its jobs with bosh stop
+// so that monit doesn't start the worker again, and then prunes it from the ATC. Either way its VM keeps
+// running, so that it can be investigated before it is recreated. The state of the worker is shown over
+// bosh ssh, and its BOSH logs are downloaded to logsDir when it is given
+func (client *Client) landWorker(name string, retire bool, logsDir string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	landTimeout, err := workerLandTimeout(conf)
+	if err != nil {
+		return err
+	}
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", conf.GetDomain()),
+		Username: conf.GetConcourseUsername(),
+		Password: conf.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return err
+	}
+	defer flyClient.Cleanup()
+
+	// Workers are named after their BOSH instance ID, so either worker/<id> or <id> can be given
+	parts := strings.SplitN(name, "/", 2)
+	name = parts[len(parts)-1]
+
+	worker, registered, err := findWorker(flyClient, name)
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return fmt.Errorf("worker %s is not registered with the ATC", name)
+	}
+
+	boshClientPointer, err := client.constructBoshClient()
+	if err != nil {
+		return err
+	}
+	boshClient := *boshClientPointer
+	defer boshClient.Cleanup()
+
+	instances, err := boshClient.Instances()
+	if err != nil {
+		return fmt.Errorf("error listing VMs: [%v]", err)
+	}
+	var instance string
+	for _, i := range workerInstances(instances) {
+		if workerName(i) == name {
+			instance = i.Name
+		}
+	}
+	if instance == "" {
+		return fmt.Errorf("worker %s is not a VM of this deployment", name)
+	}
+
+	if worker.State == "running" {
+		fmt.Fprintf(client.stdout, "Landing worker %s\n", name)
+		if err = flyClient.LandWorker(name); err != nil {
+			return err
+		}
+	}
+
+	if !retire {
+		if err = client.inspectWorker(boshClient, instance, logsDir); err != nil {
+			return err
+		}
+		fmt.Fprintf(client.stdout, "Worker %s takes no new builds and leaves once its running builds have finished\n", name)
+		return nil
+	}
+
+	if err = waitForLanded(flyClient, name, landTimeout); err != nil {
+		return fmt.Errorf("%v, run maintain --retire-worker again once its builds have finished", err)
+	}
+	if err = client.inspectWorker(boshClient, instance, logsDir); err != nil {
+		return err
+	}
+	// A landed worker exits, and monit would start it again, registering it with the ATC once it is pruned
+	fmt.Fprintf(client.stdout, "Stopping worker %s\n", instance)
+	if err = boshClient.StopInstance(instance); err != nil {
+		return fmt.Errorf("error stopping worker %s: [%v]", instance, err)
+	}
+	if err = pruneWorker(flyClient, name, client.stdout); err != nil {
+		return err
+	}
+	fmt.Fprintf(client.stdout, "Worker %s is retired, its VM %s is stopped and can be investigated with bosh ssh until it is next recreated\n", name, instance)
+	return nil
+}
+
+// inspectWorker shows the state of a drained worker over bosh ssh, and downloads its BOSH logs to logsDir
+// when it is given. A worker that can't be reached is still drained, as it may be why it is being drained
+func (client *Client) inspectWorker(boshClient bosh.IClient, instance, logsDir string) error {
+	output, err := boshClient.SSH(instance, workerDiagnostics)
+	if err != nil {
+		fmt.Fprintf(client.stderr, "WARNING: failed to inspect worker %s over bosh ssh: [%v]\n", instance, err)
+	} else {
+		fmt.Fprintf(client.stdout, "State of worker %s:\n%s\n", instance, output)
+	}
+
+	if logsDir == "" {
+		return nil
+	}
+	fmt.Fprintf(client.stdout, "Downloading the logs of worker %s to %s\n", instance, logsDir)
+	if err = boshClient.Logs(instance, logsDir); err != nil {
+		return fmt.Errorf("error downloading the logs of worker %s: [%v]", instance, err)
+	}
+	return nil
+}
+
+// pruneLandedWorker waits for a landing worker's running builds to finish, then prunes it from the ATC
+func pruneLandedWorker(flyClient fly.IClient, name string, landTimeout time.Duration, stdout io.Writer) error {
+	if err := waitForLanded(flyClient, name, landTimeout); err != nil {
+		return err
+	}
+	return pruneWorker(flyClient, name, stdout)
+}
+
+// waitForLanded waits for a landing worker's running builds to finish
+func waitForLanded(flyClient fly.IClient, name string, landTimeout time.Duration) error {
+	err := waitForWorker(flyClient, name, landTimeout, func(worker fly.Worker, registered bool) bool {
+		return !registered || worker.State == "landed" || worker.State == "stalled"
+	})
+	if err != nil {
+		return fmt.Errorf("worker %s did not land within %s: [%v]", name, landTimeout, err)
+	}
+	return nil
+}
+
+// pruneWorker prunes a worker from the ATC if it is still registered
+func pruneWorker(flyClient fly.IClient, name string, stdout io.Writer) error {
+	_, registered, err := findWorker(flyClient, name)
+	if err != nil {
+		return err
+	}
+	if registered {
+		fmt.Fprintf(stdout, "Pruning worker %s\n", name)
+		if err = flyClient.PruneWorker(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
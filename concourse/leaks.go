@@ -0,0 +1,122 @@
+package concourse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/leakscan"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// maxRemnantSize skips the binaries control-tower downloads into its working directories
+const maxRemnantSize = 1 << 20
+
+// scanForLeaks looks for credentials outside the fields meant to hold them in what a deploy leaves
+// behind: the manifest as deployed, the stored config, the output of info and any working directories
+// left in the temp dir
+func (client *Client) scanForLeaks(conf config.Config, tfOutputs terraform.Outputs) error {
+	scanner := leakscan.Scanner{Secrets: knownSecrets(conf)}
+	var findings []leakscan.Finding
+
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	manifest, err := boshClient.Manifest()
+	if err != nil {
+		return fmt.Errorf("error fetching the deployed manifest to scan for leaks: [%v]", err)
+	}
+	findings = append(findings, scanner.Scan("deployed manifest", manifest)...)
+
+	configJSON, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		return err
+	}
+	findings = append(findings, scanner.Scan("config", configJSON)...)
+
+	info := &Info{Config: conf}
+	findings = append(findings, scanner.Scan("info", []byte(info.String()))...)
+
+	remnants, err := scanWorkingDirRemnants(scanner)
+	if err != nil {
+		return fmt.Errorf("error scanning working directories for leaks: [%v]", err)
+	}
+	findings = append(findings, remnants...)
+
+	if len(findings) == 0 {
+		_, err = client.stdout.Write([]byte("Leak scan found no credentials outside expected fields\n"))
+		return err
+	}
+	for _, finding := range findings {
+		fmt.Fprintf(client.stderr, "WARNING: possible credential leak at %s\n", finding)
+	}
+	return fmt.Errorf("leak scan found %d possible credentials outside expected fields", len(findings))
+}
+
+func scanWorkingDirRemnants(scanner leakscan.Scanner) ([]leakscan.Finding, error) {
+	dirs, err := filepath.Glob(filepath.Join(os.TempDir(), "control-tower*"))
+	if err != nil {
+		return nil, err
+	}
+	var findings []leakscan.Finding
+	for _, dir := range dirs {
+		err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+			if err == nil && !fi.IsDir() && fi.Size() <= maxRemnantSize {
+				var contents []byte
+				contents, err = ioutil.ReadFile(path)
+				findings = append(findings, scanner.Scan(path, contents)...)
+			}
+			// Working directories left by other users can't be read, and aren't this deploy's leaks
+			if os.IsPermission(err) {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return findings, nil
+}
+
+// knownSecrets are the single-line credentials in the config, which are reported wherever they
+// appear outside a field expected to hold them
+func knownSecrets(c config.Config) []string {
+	candidates := []string{
+		c.BitbucketClientSecret,
+		c.CFAuthClientSecret,
+		c.ConcoursePassword,
+		c.CredhubAdminClientSecret,
+		c.CredhubPassword,
+		c.DBProxyPassword,
+		c.DirectorHMUserPassword,
+		c.DirectorMbusPassword,
+		c.DirectorNATSPassword,
+		c.DirectorPassword,
+		c.DirectorRegistryPassword,
+		c.EncryptionKey,
+		c.GithubClientSecret,
+		c.GrafanaPassword,
+		c.InfluxDbPassword,
+		c.LDAPBindPassword,
+		c.MetricsScrapePassword,
+		c.MicrosoftClientSecret,
+		c.NewRelicInsertKey,
+		c.OAuthClientSecret,
+		c.OIDCClientSecret,
+		c.RDSPassword,
+	}
+	var secrets []string
+	for _, secret := range candidates {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
@@ -42,6 +42,18 @@ func (client *Client) Maintain(m maintain.Args) error {
 	switch {
 	case m.RenewNatsCertIsSet:
 		return client.renewCert(m)
+	case m.Remediate:
+		return client.remediate(m)
+	case m.Autoscale:
+		return client.autoscale(m)
+	case m.RotateWorkers:
+		return client.rotateWorkers()
+	case m.LandWorkerIsSet:
+		return client.landWorker(m.LandWorker, false, m.WorkerLogsDir)
+	case m.RetireWorkerIsSet:
+		return client.landWorker(m.RetireWorker, true, m.WorkerLogsDir)
+	case m.RotateDBCA:
+		return client.rotateDBCA(m.DBCAIdentifier)
 	}
 	return nil
 }
@@ -116,13 +128,18 @@ func (client *Client) constructBoshClient() (*bosh.IClient, error) {
 // checkIfLocked checks if the lock is taken on the director
 // returns true if the lock is taken
 func (client *Client) checkIfLocked() (bool, error) {
-	var tables Tables
 	boshClientPointer, err := client.constructBoshClient()
 	if err != nil {
 		return true, err
 	}
 	boshClient := *boshClientPointer
 	defer boshClient.Cleanup()
+	return boshLocked(boshClient)
+}
+
+// boshLocked returns true if the lock is taken on the director that boshClient talks to
+func boshLocked(boshClient bosh.IClient) (bool, error) {
+	var tables Tables
 	lockBytes, err := boshClient.Locks()
 	if err != nil {
 		return true, err
@@ -0,0 +1,459 @@
+package concourse
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/opsassets"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	concourseReleasesURL = "https://api.github.com/repos/concourse/concourse/releases?per_page=100"
+	releaseFeedTTL       = 24 * time.Hour
+	// a release line with no new release in this long is considered end of life
+	releaseLineEOLAge = 365 * 24 * time.Hour
+	// releaseFeedMaxPages stops a feed that keeps linking to a next page from being followed forever
+	releaseFeedMaxPages = 50
+)
+
+var nextPageLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Release is a published Concourse release
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+	Draft       bool      `json:"draft"`
+}
+
+// ReleaseFeed fetches Concourse releases, caching them on disk so that checks work offline
+type ReleaseFeed struct {
+	URL        string
+	CachePath  string
+	TTL        time.Duration
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+// NewReleaseFeed returns a ReleaseFeed for the Concourse GitHub releases, cached in the user cache dir
+func NewReleaseFeed() ReleaseFeed {
+	cachePath := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		cachePath = filepath.Join(dir, "control-tower", "concourse-releases.json")
+	}
+	return ReleaseFeed{
+		URL:        concourseReleasesURL,
+		CachePath:  cachePath,
+		TTL:        releaseFeedTTL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+// Releases returns the published releases, from the cache if it is fresh. If the feed cannot be
+// reached a stale cache is used instead, in which case stale is true
+func (f ReleaseFeed) Releases() (releases []Release, stale bool, err error) {
+	cached, cachedAt, cacheErr := f.readCache()
+	if cacheErr == nil && f.Now().Sub(cachedAt) < f.TTL {
+		return cached, false, nil
+	}
+
+	releases, err = f.fetch()
+	if err != nil {
+		if cacheErr == nil {
+			return cached, true, nil
+		}
+		return nil, false, fmt.Errorf("failed to fetch Concourse releases: [%v]", err)
+	}
+
+	f.writeCache(releases)
+	return releases, false, nil
+}
+
+// fetch reads every page of the feed, following the next links GitHub paginates releases with, as the
+// releases of an old line that decide whether it is end of life are on the later pages
+func (f ReleaseFeed) fetch() ([]Release, error) {
+	var releases []Release
+	url := f.URL
+	for page := 0; url != ""; page++ {
+		if page == releaseFeedMaxPages {
+			return nil, fmt.Errorf("%s has more than %d pages of releases", f.URL, releaseFeedMaxPages)
+		}
+		pageReleases, next, err := f.fetchPage(url)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, pageReleases...)
+		url = next
+	}
+	return releases, nil
+}
+
+func (f ReleaseFeed) fetchPage(url string) ([]Release, string, error) {
+	resp, err := f.HTTPClient.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	var releases []Release
+	if err = json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if match := nextPageLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		next = match[1]
+	}
+	return releases, next, nil
+}
+
+func (f ReleaseFeed) readCache() ([]Release, time.Time, error) {
+	if f.CachePath == "" {
+		return nil, time.Time{}, fmt.Errorf("no cache path")
+	}
+	info, err := os.Stat(f.CachePath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	contents, err := ioutil.ReadFile(f.CachePath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var releases []Release
+	if err = json.Unmarshal(contents, &releases); err != nil {
+		return nil, time.Time{}, err
+	}
+	return releases, info.ModTime(), nil
+}
+
+// writeCache is best effort, a failure only means the feed is fetched again next time
+func (f ReleaseFeed) writeCache(releases []Release) {
+	if f.CachePath == "" {
+		return
+	}
+	contents, err := json.Marshal(releases)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(f.CachePath), 0700); err != nil {
+		return
+	}
+	ioutil.WriteFile(f.CachePath, contents, 0600)
+}
+
+// ConcourseVersionStatus compares the deployed Concourse with the latest release
+type ConcourseVersionStatus struct {
+	Deployed string `json:"deployed"`
+	Latest   string `json:"latest"`
+	Outdated bool   `json:"outdated"`
+	EOL      bool   `json:"eol"`
+	// Stale is true when the releases feed could not be reached and a cached copy was used
+	Stale bool `json:"stale"`
+}
+
+// String describes the status for the user
+func (s ConcourseVersionStatus) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (latest %s)", s.Deployed, s.Latest)
+	switch {
+	case s.EOL:
+		b.WriteString(" - END OF LIFE, no releases in this line for over a year, please upgrade")
+	case s.Outdated:
+		b.WriteString(" - a newer version is available")
+	default:
+		b.WriteString(" - up to date")
+	}
+	if s.Stale {
+		b.WriteString(" (using cached releases, feed unavailable)")
+	}
+	return b.String()
+}
+
+// compareConcourseVersion determines whether deployed is behind the latest release, and whether
+// its major.minor line is end of life
+func compareConcourseVersion(deployed string, releases []Release, now time.Time) (ConcourseVersionStatus, error) {
+	current, err := parseVersion(deployed)
+	if err != nil {
+		return ConcourseVersionStatus{}, err
+	}
+
+	var latest version
+	var latestTag string
+	var lineLastRelease time.Time
+	for _, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		v, err := parseVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		if latestTag == "" || latest.less(v) {
+			latest, latestTag = v, release.TagName
+		}
+		if v.major == current.major && v.minor == current.minor && release.PublishedAt.After(lineLastRelease) {
+			lineLastRelease = release.PublishedAt
+		}
+	}
+	if latestTag == "" {
+		return ConcourseVersionStatus{}, fmt.Errorf("no Concourse releases found")
+	}
+
+	status := ConcourseVersionStatus{
+		Deployed: current.String(),
+		Latest:   latest.String(),
+		Outdated: current.less(latest),
+	}
+	if status.Outdated && !lineLastRelease.IsZero() && now.Sub(lineLastRelease) > releaseLineEOLAge {
+		status.EOL = true
+	}
+	return status, nil
+}
+
+type version struct {
+	major, minor, patch int
+}
+
+func parseVersion(s string) (version, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return version{}, fmt.Errorf("%q is not a valid Concourse version", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version{}, fmt.Errorf("%q is not a valid Concourse version", s)
+		}
+		numbers[i] = n
+	}
+	return version{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+func (v version) less(o version) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// deployedConcourseVersion asks the Concourse API which version it is running
+func deployedConcourseVersion(domain string) (string, error) {
+	// the certificate may be self signed and only the version is read, as when downloading fly
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := client.Get(fmt.Sprintf("https://%s/api/v1/info", domain))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s from Concourse API", resp.Status)
+	}
+
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// CheckConcourseVersion records on the info how the deployed Concourse compares with the latest release
+func (info *Info) CheckConcourseVersion(feed ReleaseFeed) error {
+	deployed, err := deployedConcourseVersion(info.Config.Domain)
+	if err != nil {
+		return fmt.Errorf("failed to determine deployed Concourse version: [%v]", err)
+	}
+	releases, stale, err := feed.Releases()
+	if err != nil {
+		return err
+	}
+	status, err := compareConcourseVersion(deployed, releases, feed.Now())
+	if err != nil {
+		return err
+	}
+	status.Stale = stale
+	info.ConcourseVersion = &status
+	return nil
+}
+
+//go:embed assets/concourse-compatibility.yml
+var concourseCompatibilityMatrix []byte
+
+// compatibility is the range of Concourse releases that run on a stemcell line, from min up to but excluding max
+type compatibility struct {
+	stemcell string
+	min, max version
+}
+
+// concourseCompatibility finds the stemcell line the Concourse manifest is deployed with in the compatibility matrix
+func concourseCompatibility(manifest, matrix []byte) (compatibility, error) {
+	var m struct {
+		Stemcells []struct {
+			OS string `yaml:"os"`
+		} `yaml:"stemcells"`
+	}
+	if err := yaml.Unmarshal(manifest, &m); err != nil {
+		return compatibility{}, err
+	}
+	if len(m.Stemcells) == 0 {
+		return compatibility{}, fmt.Errorf("the Concourse manifest has no stemcell")
+	}
+	stemcell := m.Stemcells[0].OS
+
+	var lines []struct {
+		Stemcell string `yaml:"stemcell"`
+		Min      string `yaml:"min"`
+		Max      string `yaml:"max"`
+	}
+	if err := yaml.Unmarshal(matrix, &lines); err != nil {
+		return compatibility{}, err
+	}
+	for _, line := range lines {
+		if line.Stemcell != stemcell {
+			continue
+		}
+		min, err := parseVersion(line.Min)
+		if err != nil {
+			return compatibility{}, err
+		}
+		max, err := parseVersion(line.Max)
+		if err != nil {
+			return compatibility{}, err
+		}
+		return compatibility{stemcell: stemcell, min: min, max: max}, nil
+	}
+	return compatibility{}, fmt.Errorf("no Concourse versions are known to run on the %s stemcell line", stemcell)
+}
+
+// validateConcourseVersion checks that a requested Concourse version is compatible with the stemcell
+// line in use and has been published. If the releases feed cannot be reached only compatibility is checked
+func validateConcourseVersion(requested string, feed ReleaseFeed) error {
+	c, err := concourseCompatibility(opsassets.ConcourseManifestContents, concourseCompatibilityMatrix)
+	if err != nil {
+		return err
+	}
+	releases, _, err := feed.Releases()
+	if err != nil {
+		releases = nil
+	}
+	return checkConcourseVersion(requested, c, releases)
+}
+
+func checkConcourseVersion(requested string, c compatibility, releases []Release) error {
+	v, err := parseVersion(requested)
+	if err != nil {
+		return err
+	}
+	if v.less(c.min) || !v.less(c.max) {
+		return fmt.Errorf("Concourse %s is not supported on the %s stemcell line, choose a version from %s up to but excluding %s", v, c.stemcell, c.min, c.max)
+	}
+	if releases == nil {
+		return nil
+	}
+	for _, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		if published, err := parseVersion(release.TagName); err == nil && published == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("Concourse %s has not been released", v)
+}
+
+const (
+	// boshIOConcourseReleasesURL lists the Concourse BOSH releases bosh.io publishes, with their checksums
+	boshIOConcourseReleasesURL = "https://bosh.io/api/v1/releases/github.com/concourse/concourse-bosh-release"
+	// boshIOOSConfReleasesURL lists the os-conf BOSH releases bosh.io publishes, with their checksums
+	boshIOOSConfReleasesURL = "https://bosh.io/api/v1/releases/github.com/cloudfoundry/os-conf-release"
+)
+
+// boshIOReleaseSHA1 looks up the checksum bosh.io publishes for a version of a BOSH release, so that the
+// director verifies the tarball it downloads
+func boshIOReleaseSHA1(httpClient *http.Client, url, release, requested string) (string, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the checksum of %s %s: [%v]", release, requested, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to look up the checksum of %s %s: unexpected status %s from %s", release, requested, resp.Status, url)
+	}
+
+	var releases []struct {
+		Version string `json:"version"`
+		SHA1    string `json:"sha1"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", err
+	}
+	for _, release := range releases {
+		if release.Version == requested && release.SHA1 != "" {
+			return release.SHA1, nil
+		}
+	}
+	return "", fmt.Errorf("bosh.io has no %s BOSH release %s", release, requested)
+}
+
+// resolveConcourseVersion checks that the Concourse version the deployment is pinned to still runs on the
+// stemcell line of this control-tower, and looks up the checksum of its release the first time it is deployed
+func (client *Client) resolveConcourseVersion(conf config.Config) (config.Config, error) {
+	if err := validateConcourseVersion(conf.ConcourseVersion, NewReleaseFeed()); err != nil {
+		return conf, err
+	}
+	if conf.ConcourseReleaseSHA1 != "" {
+		return conf, nil
+	}
+	sha1, err := boshIOReleaseSHA1(&http.Client{Timeout: 30 * time.Second}, boshIOConcourseReleasesURL, "Concourse", conf.ConcourseVersion)
+	if err != nil {
+		return conf, err
+	}
+	conf.ConcourseReleaseSHA1 = sha1
+	return conf, nil
+}
+
+// resolveOSConfRelease looks up the checksum of the os-conf release the first time a deployment needs it,
+// and again whenever control-tower moves to another version of it
+func (client *Client) resolveOSConfRelease(conf config.Config) (config.Config, error) {
+	if !bosh.UsesOSConf(conf, client.provider.IAAS()) {
+		return conf, nil
+	}
+	if conf.OSConfReleaseVersion == bosh.OSConfReleaseVersion && conf.OSConfReleaseSHA1 != "" {
+		return conf, nil
+	}
+	sha1, err := boshIOReleaseSHA1(&http.Client{Timeout: 30 * time.Second}, boshIOOSConfReleasesURL, "os-conf", bosh.OSConfReleaseVersion)
+	if err != nil {
+		return conf, err
+	}
+	conf.OSConfReleaseVersion, conf.OSConfReleaseSHA1 = bosh.OSConfReleaseVersion, sha1
+	return conf, nil
+}
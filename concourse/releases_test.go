@@ -0,0 +1,223 @@
+package concourse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_compareConcourseVersion(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	releases := []Release{
+		{TagName: "v8.0.0-rc.1", PublishedAt: now.AddDate(0, 0, -1), Prerelease: true},
+		{TagName: "v7.14.1", PublishedAt: now.AddDate(0, -1, 0)},
+		{TagName: "v7.14.0", PublishedAt: now.AddDate(0, -3, 0)},
+		{TagName: "v7.13.2", PublishedAt: now.AddDate(0, -6, 0)},
+		{TagName: "v7.9.1", PublishedAt: now.AddDate(-2, 0, 0)},
+	}
+
+	tests := []struct {
+		name     string
+		deployed string
+		want     ConcourseVersionStatus
+		wantErr  bool
+	}{
+		{
+			name:     "latest release is up to date",
+			deployed: "7.14.1",
+			want:     ConcourseVersionStatus{Deployed: "7.14.1", Latest: "7.14.1"},
+		},
+		{
+			name:     "older patch is outdated",
+			deployed: "7.14.0",
+			want:     ConcourseVersionStatus{Deployed: "7.14.0", Latest: "7.14.1", Outdated: true},
+		},
+		{
+			name:     "recently maintained line is outdated but not end of life",
+			deployed: "7.13.0",
+			want:     ConcourseVersionStatus{Deployed: "7.13.0", Latest: "7.14.1", Outdated: true},
+		},
+		{
+			name:     "line without a release for a year is end of life",
+			deployed: "7.9.1",
+			want:     ConcourseVersionStatus{Deployed: "7.9.1", Latest: "7.14.1", Outdated: true, EOL: true},
+		},
+		{
+			name:     "invalid deployed version",
+			deployed: "latest",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compareConcourseVersion(tt.deployed, releases, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("compareConcourseVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("compareConcourseVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseFeed_Releases(t *testing.T) {
+	requests := 0
+	online := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `[{"tag_name": "v7.14.1", "published_at": "2026-05-01T00:00:00Z"}]`)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	feed := ReleaseFeed{
+		URL:        server.URL,
+		CachePath:  filepath.Join(t.TempDir(), "releases.json"),
+		TTL:        time.Hour,
+		HTTPClient: server.Client(),
+		Now:        func() time.Time { return now },
+	}
+
+	releases, stale, err := feed.Releases()
+	if err != nil || stale || len(releases) != 1 || releases[0].TagName != "v7.14.1" {
+		t.Fatalf("first fetch: releases = %v, stale = %v, err = %v", releases, stale, err)
+	}
+
+	if _, _, err = feed.Releases(); err != nil || requests != 1 {
+		t.Fatalf("expected a fresh cache to be used, got %d requests and err %v", requests, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	online = false
+	releases, stale, err = feed.Releases()
+	if err != nil || !stale || len(releases) != 1 {
+		t.Fatalf("expected a stale cache when offline: releases = %v, stale = %v, err = %v", releases, stale, err)
+	}
+
+	feed.CachePath = filepath.Join(t.TempDir(), "missing.json")
+	if _, _, err = feed.Releases(); err == nil {
+		t.Fatal("expected an error when offline without a cache")
+	}
+}
+
+func TestReleaseFeed_ReleasesFollowsPages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"tag_name": "v7.9.1", "published_at": "2024-05-01T00:00:00Z"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/?page=2>; rel="next", <%s/?page=2>; rel="last"`, server.URL, server.URL))
+		fmt.Fprint(w, `[{"tag_name": "v7.14.1", "published_at": "2026-05-01T00:00:00Z"}]`)
+	}))
+	defer server.Close()
+
+	feed := ReleaseFeed{
+		URL:        server.URL,
+		TTL:        time.Hour,
+		HTTPClient: server.Client(),
+		Now:        time.Now,
+	}
+
+	releases, _, err := feed.Releases()
+	if err != nil || len(releases) != 2 || releases[1].TagName != "v7.9.1" {
+		t.Fatalf("expected releases from both pages: releases = %v, err = %v", releases, err)
+	}
+}
+
+var jammy = compatibility{stemcell: "ubuntu-jammy", min: version{7, 8, 0}, max: version{8, 0, 0}}
+
+func Test_concourseCompatibility(t *testing.T) {
+	manifest := []byte("stemcells:\n- alias: jammy\n  os: ubuntu-jammy\n  version: latest\n")
+	got, err := concourseCompatibility(manifest, concourseCompatibilityMatrix)
+	if err != nil {
+		t.Fatalf("concourseCompatibility() error = %v", err)
+	}
+	if got != jammy {
+		t.Errorf("concourseCompatibility() = %+v, want %+v", got, jammy)
+	}
+
+	manifest = []byte("stemcells:\n- alias: noble\n  os: ubuntu-noble\n  version: latest\n")
+	if _, err = concourseCompatibility(manifest, concourseCompatibilityMatrix); err == nil || err.Error() != "no Concourse versions are known to run on the ubuntu-noble stemcell line" {
+		t.Errorf("concourseCompatibility() error = %v, want an unknown stemcell line", err)
+	}
+}
+
+func Test_boshIOReleaseSHA1(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "github.com/concourse/concourse-bosh-release", "version": "7.11.2", "sha1": "sha256:abc123"}]`)
+	}))
+	defer server.Close()
+
+	sha1, err := boshIOReleaseSHA1(server.Client(), server.URL, "Concourse", "7.11.2")
+	if err != nil || sha1 != "sha256:abc123" {
+		t.Errorf("boshIOReleaseSHA1() = %s, %v", sha1, err)
+	}
+	if _, err = boshIOReleaseSHA1(server.Client(), server.URL, "Concourse", "7.11.3"); err == nil || err.Error() != "bosh.io has no Concourse BOSH release 7.11.3" {
+		t.Errorf("boshIOReleaseSHA1() error = %v, want a missing release", err)
+	}
+}
+
+func Test_checkConcourseVersion(t *testing.T) {
+	releases := []Release{
+		{TagName: "v8.0.0-rc.1", Prerelease: true},
+		{TagName: "v7.14.1"},
+		{TagName: "v7.11.2"},
+		{TagName: "v7.4.0"},
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		releases  []Release
+		wantErr   string
+	}{
+		{
+			name:      "published compatible release",
+			requested: "7.11.2",
+			releases:  releases,
+		},
+		{
+			name:      "unpublished release",
+			requested: "7.11.3",
+			releases:  releases,
+			wantErr:   "Concourse 7.11.3 has not been released",
+		},
+		{
+			name:      "release too old for the stemcell line",
+			requested: "7.4.0",
+			releases:  releases,
+			wantErr:   "Concourse 7.4.0 is not supported on the ubuntu-jammy stemcell line, choose a version from 7.8.0 up to but excluding 8.0.0",
+		},
+		{
+			name:      "next major release",
+			requested: "8.0.0",
+			releases:  releases,
+			wantErr:   "Concourse 8.0.0 is not supported on the ubuntu-jammy stemcell line, choose a version from 7.8.0 up to but excluding 8.0.0",
+		},
+		{
+			name:      "compatible release without the feed",
+			requested: "7.11.3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConcourseVersion(tt.requested, jammy, tt.releases)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkConcourseVersion() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkConcourseVersion() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package concourse
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/commands/maintain"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/remediate"
+)
+
+// buildsToInspect is how many recent builds are checked for being stuck
+const buildsToInspect = 1000
+
+// remediate reports builds stuck pending, stalled and orphaned workers and unhealthy VMs. It then prunes
+// the stalled and orphaned workers, retires workers whose VM is unhealthy, and recreates the unhealthy
+// VMs. Stuck builds are only reported, as they are usually waiting on a worker and get scheduled once
+// it is back
+func (client *Client) remediate(m maintain.Args) error {
+	stuckAfter := remediate.DefaultStuckAfter
+	if m.StuckAfter != "" {
+		var err error
+		if stuckAfter, err = time.ParseDuration(m.StuckAfter); err != nil {
+			return err
+		}
+	}
+
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", conf.GetDomain()),
+		Username: conf.GetConcourseUsername(),
+		Password: conf.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return err
+	}
+	defer flyClient.Cleanup()
+
+	builds, err := flyClient.Builds(buildsToInspect)
+	if err != nil {
+		return fmt.Errorf("error listing builds: [%v]", err)
+	}
+	workers, err := flyClient.Workers()
+	if err != nil {
+		return fmt.Errorf("error listing workers: [%v]", err)
+	}
+
+	boshClientPointer, err := client.constructBoshClient()
+	if err != nil {
+		return err
+	}
+	boshClient := *boshClientPointer
+	defer boshClient.Cleanup()
+
+	instances, err := boshClient.Instances()
+	if err != nil {
+		return fmt.Errorf("error listing VMs: [%v]", err)
+	}
+
+	report := remediate.New(builds, workers, instances, stuckAfter, time.Now())
+	if err = report.Write(client.stdout); err != nil {
+		return err
+	}
+	if m.DryRun || report.Healthy() {
+		return nil
+	}
+
+	landTimeout, err := workerLandTimeout(conf)
+	if err != nil {
+		return err
+	}
+
+	lock, err := client.acquireDeploymentLock(conf, "remediate", deploylock.DefaultStaleAfter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	for _, worker := range report.StalledWorkers {
+		fmt.Fprintf(client.stdout, "Pruning stalled worker %s\n", worker.Name)
+		if err = flyClient.PruneWorker(worker.Name); err != nil {
+			return err
+		}
+	}
+
+	// Orphaned and failing workers may still be running builds, so they are landed and only pruned
+	// once those have finished
+	for _, worker := range append(report.OrphanedWorkers, report.FailingWorkers...) {
+		if worker.State == "running" {
+			fmt.Fprintf(client.stdout, "Landing worker %s\n", worker.Name)
+			if err = flyClient.LandWorker(worker.Name); err != nil {
+				return err
+			}
+		}
+		if err = pruneLandedWorker(flyClient, worker.Name, landTimeout, client.stdout); err != nil {
+			return fmt.Errorf("%v, run maintain --remediate again once its builds have finished", err)
+		}
+	}
+
+	// BOSH can recreate a worker whose processes are failing, but only cloud-check can recreate a VM
+	// that is missing or whose agent is unresponsive. Other VMs with failing processes are left to be
+	// investigated, as recreating them may not fix what is wrong
+	var unresponsive bool
+	workerVMs := map[string]bool{}
+	for _, instance := range workerInstances(report.UnhealthyInstances) {
+		workerVMs[instance.Name] = true
+	}
+	for _, instance := range report.UnhealthyInstances {
+		switch {
+		case instance.State != "failing":
+			unresponsive = true
+		case workerVMs[instance.Name]:
+			fmt.Fprintf(client.stdout, "Recreating worker %s\n", instance.Name)
+			if err = boshClient.RecreateInstance(instance.Name); err != nil {
+				return fmt.Errorf("error recreating worker %s: [%v]", instance.Name, err)
+			}
+		default:
+			fmt.Fprintf(client.stdout, "%s has failing processes and was left to investigate with `bosh ssh`\n", instance.Name)
+		}
+	}
+	if unresponsive {
+		fmt.Fprintln(client.stdout, "Recreating missing and unresponsive VMs with bosh cloud-check")
+		if err = boshClient.CloudCheck(); err != nil {
+			return fmt.Errorf("error recreating unhealthy VMs: [%v]", err)
+		}
+		fmt.Fprintln(client.stdout, "Any other problems bosh cloud-check found, such as missing disks, were left to resolve with `bosh cloud-check`")
+	}
+	if len(report.StuckBuilds) > 0 {
+		fmt.Fprintln(client.stdout, "Stuck builds were left to be scheduled once workers are available, abort them with `fly abort-build` if they remain pending")
+	}
+	return nil
+}
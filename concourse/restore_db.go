@@ -0,0 +1,184 @@
+package concourse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/commands/restoredb"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/exitcode"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// defaultRDSBackupRetention is the days of backups RDS keeps when --db-backup-retention isn't set, and
+// cloudSQLLogRetention the days of transaction logs CloudSQL keeps for point-in-time recovery
+const (
+	defaultRDSBackupRetention = 1
+	cloudSQLLogRetention      = 7
+)
+
+// RestoreDB restores the database to a point in time as a new instance, and moves Concourse, UAA and CredHub
+// onto it. The instance it replaces is archived: terraform keeps it as it is until the deployment is destroyed.
+// The director on AWS keeps its database on the instance it used before the first restore, so that its record
+// of the deployment's VMs and disks isn't rolled back. On GCP the director's database is on the director
+func (client *Client) RestoreDB(args restoredb.Args) (err error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	defer func() { client.sendAuditEvent(conf, "restore-db", err) }()
+
+	restoreTime, err := args.Time()
+	if err != nil {
+		return err
+	}
+	if err = checkRestoreTime(conf, client.provider.IAAS(), restoreTime, time.Now()); err != nil {
+		return exitcode.WithCode(exitcode.Validation, err)
+	}
+
+	lock, err := client.acquireDeploymentLock(conf, "restore-db", deploylock.DefaultStaleAfter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	source, err := dbInstanceName(conf, client.provider.IAAS(), tfOutputs)
+	if err != nil {
+		return err
+	}
+
+	previous := conf
+	moves := dbArchiveMoves(conf, client.provider.IAAS(), len(conf.DBArchivedInstances))
+	conf.DBArchivedInstances = append(conf.DBArchivedInstances, source)
+	conf.DBRestore = config.DBRestore{
+		Source:   source,
+		Instance: fmt.Sprintf("%s-restored-%d", conf.GetDeployment(), time.Now().Unix()),
+		Time:     restoreTime.UTC().Format(time.RFC3339),
+	}
+	if conf.DirectorDBInstance == "" && client.provider.IAAS() == iaas.AWS {
+		conf.DirectorDBInstance = source
+	}
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+
+	// The config is saved before the instance is archived in the state, as terraform would destroy an archived
+	// instance that the config doesn't list. It is put back if archiving fails
+	if err = client.configClient.Update(conf); err != nil {
+		return err
+	}
+	fmt.Fprintf(client.stdout, "Archiving database instance %s\n", source)
+	if err = client.tfCLI.MoveState(tfInputVars, moves); err != nil {
+		if err1 := client.configClient.Update(previous); err1 != nil {
+			return exitcode.WithCode(exitcode.Partial, fmt.Errorf("%v, and failed to put back the config: [%v]", err, err1))
+		}
+		return err
+	}
+	// once the old instance is archived, deploying again finishes moving the deployment onto the restored one
+	defer func() { err = exitcode.WithCode(exitcode.Partial, err) }()
+
+	fmt.Fprintf(client.stdout, "Restoring %s to %s as %s\n", source, conf.DBRestore.Time, conf.DBRestore.Instance)
+	if err = client.tfCLI.Apply(tfInputVars); err != nil {
+		return err
+	}
+	tfOutputs, err = client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(client.stdout, "Moving the deployment onto %s\n", conf.DBRestore.Instance)
+	if err = client.redeployBosh(conf, tfOutputs); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(client.stdout, "The deployment now uses %s. %s is kept until the deployment is destroyed\n", conf.DBRestore.Instance, source)
+	return nil
+}
+
+// checkRestoreTime checks that the database keeps the backups or logs to restore it to t
+func checkRestoreTime(conf config.ConfigView, iaasName iaas.Name, t, now time.Time) error {
+	if conf.GetExternalDBURL() != "" {
+		return errors.New("restore-db restores the RDS or CloudSQL instance, which a deployment with --external-db-url doesn't have")
+	}
+	if t.After(now) {
+		return fmt.Errorf("--timestamp %s is in the future", t.Format(time.RFC3339))
+	}
+
+	if iaasName == iaas.GCP {
+		if !conf.GetDBHA() && conf.GetDBBackupRetention() == 0 && conf.GetDBBackupWindow() == "" {
+			return errors.New("point-in-time recovery is off for the CloudSQL instance. Deploy with --db-backup-retention to turn it on, after which the database can be restored to times from then onwards")
+		}
+		if t.Before(now.AddDate(0, 0, -cloudSQLLogRetention)) {
+			return fmt.Errorf("--timestamp %s is older than the %d days of transaction logs CloudSQL keeps", t.Format(time.RFC3339), cloudSQLLogRetention)
+		}
+		return nil
+	}
+
+	retention := conf.GetDBBackupRetention()
+	if retention == 0 {
+		retention = defaultRDSBackupRetention
+	}
+	if t.Before(now.AddDate(0, 0, -retention)) {
+		return fmt.Errorf("--timestamp %s is older than the %d day(s) of backups RDS keeps", t.Format(time.RFC3339), retention)
+	}
+	return nil
+}
+
+// dbInstanceName is the name of the database instance the deployment uses, which is the identifier of the
+// RDS instance or Aurora cluster on AWS
+func dbInstanceName(conf config.ConfigView, iaasName iaas.Name, tfOutputs terraform.Outputs) (string, error) {
+	if instance := conf.GetDBRestore().Instance; instance != "" {
+		return instance, nil
+	}
+	if iaasName == iaas.GCP {
+		return tfOutputs.Get("DBName")
+	}
+	if conf.GetDBEngine() == config.DB_ENGINE_AURORA_SERVERLESS {
+		return conf.GetDeployment(), nil
+	}
+	// RDS generated the instance's identifier, which is the first label of its address
+	address, err := tfOutputs.Get("BoshDBAddress")
+	if err != nil {
+		return "", err
+	}
+	return strings.SplitN(address, ".", 2)[0], nil
+}
+
+// dbArchiveMoves moves the database instance in the terraform state to the index'th archived instance of
+// the template, where it's left as it is. The database and user of a CloudSQL instance are dropped from
+// the state, as the restored instance is cloned with them
+func dbArchiveMoves(conf config.ConfigView, iaasName iaas.Name, index int) map[string]string {
+	var resources []string
+	switch {
+	case iaasName == iaas.GCP:
+		resources = []string{"google_sql_database_instance"}
+	case conf.GetDBEngine() == config.DB_ENGINE_AURORA_SERVERLESS:
+		resources = []string{"aws_rds_cluster", "aws_rds_cluster_instance"}
+	default:
+		resources = []string{"aws_db_instance"}
+	}
+
+	name := "default"
+	if iaasName == iaas.GCP {
+		name = "director"
+	}
+	moves := map[string]string{}
+	for _, resource := range resources {
+		moves[fmt.Sprintf("%s.%s", resource, name)] = fmt.Sprintf("%s.archived_%d", resource, index)
+	}
+	if iaasName == iaas.GCP && conf.GetDBRestore().Instance == "" {
+		moves["google_sql_database.director"] = ""
+		moves["google_sql_user.director"] = ""
+	}
+	return moves
+}
@@ -0,0 +1,172 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/deploylock"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// defaultWorkerLandTimeout is how long a worker may take to land when no --worker-drain-timeout was deployed
+const defaultWorkerLandTimeout = time.Hour
+
+// workerPollInterval is how often the ATC is asked for the state of a worker being rotated
+const workerPollInterval = 15 * time.Second
+
+// rotateWorkers recreates every worker VM one at a time. Each worker is landed through the ATC first, so that
+// it takes no new builds and leaves once its running builds have finished, and the next worker is only
+// rotated once the recreated one has registered again
+func (client *Client) rotateWorkers() error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	landTimeout, err := workerLandTimeout(conf)
+	if err != nil {
+		return err
+	}
+
+	lock, err := client.acquireDeploymentLock(conf, "rotate-workers", deploylock.DefaultStaleAfter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := lock.Release(); err1 != nil {
+			fmt.Fprintf(client.stderr, "WARNING: failed to release deployment lock: [%v]\n", err1)
+		}
+	}()
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", conf.GetDomain()),
+		Username: conf.GetConcourseUsername(),
+		Password: conf.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return err
+	}
+	defer flyClient.Cleanup()
+
+	boshClientPointer, err := client.constructBoshClient()
+	if err != nil {
+		return err
+	}
+	boshClient := *boshClientPointer
+	defer boshClient.Cleanup()
+
+	instances, err := boshClient.Instances()
+	if err != nil {
+		return fmt.Errorf("error listing VMs: [%v]", err)
+	}
+	workers := workerInstances(instances)
+	fmt.Fprintf(client.stdout, "Rotating %d workers\n", len(workers))
+
+	for _, instance := range workers {
+		name := workerName(instance)
+
+		worker, registered, err := findWorker(flyClient, name)
+		if err != nil {
+			return err
+		}
+		if registered && worker.State == "running" {
+			fmt.Fprintf(client.stdout, "Landing worker %s\n", instance.Name)
+			if err = flyClient.LandWorker(name); err != nil {
+				return err
+			}
+		}
+		if registered {
+			err = waitForWorker(flyClient, name, landTimeout, func(worker fly.Worker, registered bool) bool {
+				return !registered || worker.State == "landed" || worker.State == "stalled"
+			})
+			if err != nil {
+				return fmt.Errorf("worker %s did not land within %s, run maintain --rotate-workers again once its builds have finished: [%v]", instance.Name, landTimeout, err)
+			}
+		}
+
+		fmt.Fprintf(client.stdout, "Recreating worker %s\n", instance.Name)
+		if err = boshClient.RecreateInstance(instance.Name); err != nil {
+			return fmt.Errorf("error recreating worker %s: [%v]", instance.Name, err)
+		}
+
+		err = waitForWorker(flyClient, name, landTimeout, func(worker fly.Worker, registered bool) bool {
+			return registered && worker.State == "running"
+		})
+		if err != nil {
+			return fmt.Errorf("worker %s did not register again after being recreated: [%v]", instance.Name, err)
+		}
+
+		// A recreated VM boots with the stemcell's cgroup version until it is rebooted
+		if conf.WorkerCgroupVersion != "" && isCgroupWorker(instance, conf.WorkerPools) {
+			if err = switchWorkerCgroup(boshClient, flyClient, instance, conf.WorkerCgroupVersion, landTimeout, client.stdout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// workerLandTimeout is how long a worker may take to land, which is the deployed --worker-drain-timeout
+func workerLandTimeout(conf config.ConfigView) (time.Duration, error) {
+	if conf.GetWorkerDrainTimeout() == "" {
+		return defaultWorkerLandTimeout, nil
+	}
+	return time.ParseDuration(conf.GetWorkerDrainTimeout())
+}
+
+// workerInstances are the VMs of the worker instance group, worker pools and Windows workers
+func workerInstances(instances []bosh.Instance) []bosh.Instance {
+	var workers []bosh.Instance
+	for _, instance := range instances {
+		group := strings.SplitN(instance.Name, "/", 2)[0]
+		if group == "worker" || strings.HasPrefix(group, "worker-") {
+			workers = append(workers, instance)
+		}
+	}
+	return workers
+}
+
+// workerName is the name a worker registers with, which is the ID of its BOSH instance
+func workerName(instance bosh.Instance) string {
+	parts := strings.SplitN(instance.Name, "/", 2)
+	return parts[len(parts)-1]
+}
+
+func findWorker(flyClient fly.IClient, name string) (fly.Worker, bool, error) {
+	workers, err := flyClient.Workers()
+	if err != nil {
+		return fly.Worker{}, false, fmt.Errorf("error listing workers: [%v]", err)
+	}
+	for _, worker := range workers {
+		if worker.Name == name {
+			return worker, true, nil
+		}
+	}
+	return fly.Worker{}, false, nil
+}
+
+// waitForWorker polls the ATC until done is true for the named worker, or the timeout passes
+func waitForWorker(flyClient fly.IClient, name string, timeout time.Duration, done func(worker fly.Worker, registered bool) bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		worker, registered, err := findWorker(flyClient, name)
+		if err != nil {
+			return err
+		}
+		if done(worker, registered) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		time.Sleep(workerPollInterval)
+	}
+}
@@ -2,8 +2,11 @@ package concourse
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/EngineerBetter/control-tower/commands/deploy"
 	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/terraform"
 )
@@ -45,32 +48,148 @@ type AWSInputVarsFactory struct{}
 func (f *AWSInputVarsFactory) NewInputVars(c config.ConfigView) terraform.InputVars {
 	metricsEnabled := !c.MetricsIsDisabled()
 	return &terraform.AWSInputVars{
-		NetworkCIDR:            c.GetNetworkCIDR(),
-		PublicCIDR:             c.GetPublicCIDR(),
-		PrivateCIDR:            c.GetPrivateCIDR(),
-		AllowIPs:               c.GetAllowIPs(),
-		AvailabilityZone:       c.GetAvailabilityZone(),
-		ConfigBucket:           c.GetConfigBucket(),
-		Deployment:             c.GetDeployment(),
-		HostedZoneID:           c.GetHostedZoneID(),
-		HostedZoneRecordPrefix: c.GetHostedZoneRecordPrefix(),
-		MetricsEnabled:         metricsEnabled,
-		Namespace:              c.GetNamespace(),
-		Project:                c.GetProject(),
-		PublicKey:              c.GetPublicKey(),
-		RDSDefaultDatabaseName: c.GetRDSDefaultDatabaseName(),
-		RDSInstanceClass:       c.GetRDSInstanceClass(),
-		RDSPassword:            c.GetRDSPassword(),
-		RDSUsername:            c.GetRDSUsername(),
-		RDSDiskEncryption:      c.GetRDSDiskEncryption(),
-		RDS1CIDR:               c.GetRDS1CIDR(),
-		RDS2CIDR:               c.GetRDS2CIDR(),
-		Region:                 c.GetRegion(),
-		SourceAccessIP:         c.GetSourceAccessIP(),
-		TFStatePath:            c.GetTFStatePath(),
+		NetworkCIDR:                   c.GetNetworkCIDR(),
+		PublicCIDR:                    c.GetPublicCIDR(),
+		PrivateCIDR:                   c.GetPrivateCIDR(),
+		AllowIPs:                      c.GetAllowIPs(),
+		AvailabilityZone:              c.GetAvailabilityZone(),
+		ConfigBucket:                  c.GetConfigBucket(),
+		Deployment:                    c.GetDeployment(),
+		HostedZoneID:                  c.GetHostedZoneID(),
+		HostedZoneRecordPrefix:        c.GetHostedZoneRecordPrefix(),
+		AdditionalRecordPrefixes:      c.GetAdditionalRecordPrefixes(),
+		InternalLB:                    c.GetInternalLB(),
+		InternalLBAllowIPs:            c.GetInternalLBAllowIPs(),
+		InternalLBTLSCert:             c.GetInternalLBTLSCert(),
+		InternalLBTLSKey:              c.GetInternalLBTLSKey(),
+		InternalLBHealthCheckPath:     c.GetInternalLBHealthCheckPath(),
+		InternalLBHealthCheckInterval: c.GetInternalLBHealthCheckInterval(),
+		InternalLBHealthyThreshold:    c.GetInternalLBHealthyThreshold(),
+		InternalLBUnhealthyThreshold:  c.GetInternalLBUnhealthyThreshold(),
+		ManagedPrometheus:             c.IsManagedPrometheusSet(),
+		ManagedPrometheusRegion:       c.GetManagedPrometheusRegion(),
+		ManagedPrometheusWorkspaceID:  ampWorkspaceID(c.GetManagedPrometheusURL()),
+		MetricsEnabled:                metricsEnabled,
+		MetricsScrapeAllowIPs:         c.GetMetricsScrapeAllowIPs(),
+		ExternalWorkerAllowIPs:        c.GetExternalWorkerAllowIPs(),
+		RestrictWorkerEgress:          c.GetRestrictWorkerEgress(),
+		WorkerEgressAllow:             c.GetWorkerEgressAllow(),
+		WorkerZones:                   c.GetWorkerZones(),
+		ExistingVPCID:                 c.GetExistingNetwork(),
+		ExistingPublicSubnetID:        c.GetExistingPublicSubnet(),
+		ExistingPrivateSubnetID:       c.GetExistingPrivateSubnet(),
+		ExistingDBSubnetIDs:           c.GetExistingDBSubnets(),
+		ExistingNATIP:                 c.GetExistingNATIP(),
+		Private:                       c.GetPrivate(),
+		NATCIDR:                       c.GetNATCIDR(),
+		Bastion:                       c.GetBastion(),
+		BastionAllowIPs:               bastionAllowIPs(c),
+		WebAllowIPs:                   webAllowIPs(c),
+		DirectorAllowIPs:              directorAllowIPs(c),
+		SSHAllowIPs:                   sshAllowIPs(c),
+		MetricsAllowIPs:               metricsAllowIPs(c),
+		WebEIP:                        c.GetWebEIP(),
+		BastionPublicKey:              c.GetBastionPublicKey(),
+		VPCEndpoints:                  c.GetVPCEndpoints(),
+		NATTopology:                   c.GetNATTopology(),
+		IPv6:                          c.GetIPv6(),
+		AllowIPv6s:                    c.GetAllowIPv6s(),
+		PeerVPCIDs:                    c.GetPeerVPCIDs(),
+		TransitGatewayID:              c.GetTransitGatewayID(),
+		TransitGatewayRoutes:          c.GetTransitGatewayRoutes(),
+		TransitGatewayRouteTableID:    c.GetTransitGatewayRouteTableID(),
+		NoTransitGatewayPropagation:   c.GetNoTransitGatewayPropagation(),
+		ExternalDBHost:                externalDB(c).Host,
+		ExternalDBPort:                externalDB(c).Port,
+		DBMinACU:                      c.GetDBMinACU(),
+		DBMaxACU:                      c.GetDBMaxACU(),
+		DBEngineVersion:               dbEngineVersion(c),
+		DBStorage:                     c.GetDBStorage(),
+		DBMaxStorage:                  c.GetDBMaxStorage(),
+		DBStorageType:                 c.GetDBStorageType(),
+		DBIOPS:                        c.GetDBIOPS(),
+		DBBackupRetention:             c.GetDBBackupRetention(),
+		DBBackupWindow:                c.GetDBBackupWindow(),
+		DBDeletionProtection:          c.GetDBDeletionProtection(),
+		DBMultiAZ:                     c.GetDBMultiAZ(),
+		DBReadReplica:                 c.GetDBReadReplica(),
+		DBKMSKeyARN:                   kmsKeyARN(c.GetDBKMSKey()),
+		DBIAMUser:                     dbIAMUser(c),
+		DBBlueGreenUpdate:             c.GetDBBlueGreenUpdate(),
+		DBParameters:                  dbParameters(c),
+		DBParameterGroupFamily:        dbParameterGroupFamily(c),
+		DBRestoreSource:               c.GetDBRestore().Source,
+		DBRestoreInstance:             c.GetDBRestore().Instance,
+		DBRestoreTime:                 c.GetDBRestore().Time,
+		DBArchivedInstances:           c.GetDBArchivedInstances(),
+		DirectorDBArchive:             directorDBArchive(c),
+		DBCACertIdentifier:            c.GetDBCACertIdentifier(),
+		WebCount:                      c.GetConcourseWebCount(),
+		Namespace:                     c.GetNamespace(),
+		Project:                       c.GetProject(),
+		PrometheusMetrics:             c.GetEnablePrometheusMetrics(),
+		PublicKey:                     c.GetPublicKey(),
+		RDSDefaultDatabaseName:        c.GetRDSDefaultDatabaseName(),
+		RDSInstanceClass:              c.GetRDSInstanceClass(),
+		RDSPassword:                   c.GetRDSPassword(),
+		RDSUsername:                   c.GetRDSUsername(),
+		RDSDiskEncryption:             c.GetRDSDiskEncryption(),
+		RDS1CIDR:                      c.GetRDS1CIDR(),
+		RDS2CIDR:                      c.GetRDS2CIDR(),
+		Region:                        c.GetRegion(),
+		SecretsManager:                c.IsSecretsManagerSet(),
+		SecretsManagerPathPrefix:      secretPathPrefix(c.GetSecretsManagerPipelinePath(), c.GetSecretsManagerTeamPath()),
+		SourceAccessIP:                c.GetSourceAccessIP(),
+		SSM:                           c.IsSSMSet(),
+		SSMKMSKeyARN:                  kmsKeyARN(c.GetSSMKMSKeyID()),
+		SSMPathPrefix:                 secretPathPrefix(c.GetSSMPipelinePath(), c.GetSSMTeamPath()),
+		TFStatePath:                   c.GetTFStatePath(),
 	}
 }
 
+// secretPathPrefix returns the fixed start shared by all the secret path templates, which is
+// as far as the web node's access to secrets can be scoped
+func secretPathPrefix(templates ...string) string {
+	var prefix string
+	for i, template := range templates {
+		if end := strings.Index(template, "{{"); end >= 0 {
+			template = template[:end]
+		}
+		if i == 0 {
+			prefix = template
+			continue
+		}
+		for !strings.HasPrefix(template, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// dbIAMUser is the role the web VMs log into the database as with IAM authentication tokens, when they do
+func dbIAMUser(c config.ConfigView) string {
+	if !c.GetDBIAMAuth() {
+		return ""
+	}
+	return db.IAMRole
+}
+
+// kmsKeyARN returns the ARN of a KMS key given either its ARN or its ID in the deployment's account and region
+func kmsKeyARN(keyID string) string {
+	if keyID == "" || strings.HasPrefix(keyID, "arn:") {
+		return keyID
+	}
+	return "arn:aws:kms:${var.region}:${data.aws_caller_identity.current.account_id}:key/" + keyID
+}
+
+// ampWorkspaceID returns the workspace ID from an AWS Managed Prometheus remote write URL
+func ampWorkspaceID(url string) string {
+	if m := deploy.AMPRemoteWriteURLPattern.FindStringSubmatch(url); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
 type GCPInputVarsFactory struct {
 	credentialsPath string
 	project         string
@@ -80,6 +199,10 @@ type GCPInputVarsFactory struct {
 
 func (f *GCPInputVarsFactory) NewInputVars(c config.ConfigView) terraform.InputVars {
 	metricsEnabled := !c.MetricsIsDisabled()
+	zone := c.GetAvailabilityZone()
+	if zone == "" {
+		zone = f.zone
+	}
 	return &terraform.GCPInputVars{
 		AllowIPs:           c.GetAllowIPs(),
 		ConfigBucket:       c.GetConfigBucket(),
@@ -94,11 +217,192 @@ func (f *GCPInputVarsFactory) NewInputVars(c config.ConfigView) terraform.InputV
 		GCPCredentialsJSON: f.credentialsPath,
 		MetricsEnabled:     metricsEnabled,
 		Namespace:          c.GetNamespace(),
+		P2PVolumeStreaming: c.GetEnableP2PVolumeStreaming(),
 		Project:            f.project,
+		PrometheusMetrics:  c.GetEnablePrometheusMetrics(),
 		Region:             f.region,
 		Tags:               "",
-		Zone:               f.zone,
+		Zone:               zone,
 		PublicCIDR:         c.GetPublicCIDR(),
 		PrivateCIDR:        c.GetPrivateCIDR(),
+
+		AdditionalRecordSetPrefixes: c.GetAdditionalRecordPrefixes(),
+		MetricsScrapeAllowIPs:       c.GetMetricsScrapeAllowIPs(),
+		ExternalWorkerAllowIPs:      c.GetExternalWorkerAllowIPs(),
+		RestrictWorkerEgress:        c.GetRestrictWorkerEgress(),
+		WorkerEgressAllow:           c.GetWorkerEgressAllow(),
+		WebCount:                    c.GetConcourseWebCount(),
+		SoleTenantNodeType:          soleTenantNodeType(c),
+		SoleTenantNodeCount:         c.GetSoleTenantNodeCount(),
+		ExistingNetwork:             c.GetExistingNetwork(),
+		ExistingPublicSubnet:        c.GetExistingPublicSubnet(),
+		ExistingPrivateSubnet:       c.GetExistingPrivateSubnet(),
+		Private:                     c.GetPrivate(),
+		Bastion:                     c.GetBastion(),
+		BastionAllowIPs:             bastionAllowIPs(c),
+		WebAllowIPs:                 webAllowIPs(c),
+		DirectorAllowIPs:            directorAllowIPs(c),
+		SSHAllowIPs:                 sshAllowIPs(c),
+		MetricsAllowIPs:             metricsAllowIPs(c),
+		WebAddressName:              c.GetWebAddressName(),
+		BastionPublicKey:            c.GetBastionPublicKey(),
+		PeerNetworks:                c.GetPeerNetworks(),
+		NCCHub:                      c.GetNCCHub(),
+		NCCExcludeExportRanges:      c.GetNCCExcludeExportRanges(),
+		GoogleBetaProvider:          c.GetGoogleBetaProvider(),
+		ExternalDBHost:              externalDB(c).Host,
+		DBHA:                        c.GetDBHA(),
+		DBReadReplica:               c.GetDBReadReplica(),
+		DBKMSKey:                    c.GetDBKMSKey(),
+		DatabaseVersion:             cloudSQLDatabaseVersion(c),
+		DBBackupRetention:           c.GetDBBackupRetention(),
+		DBBackupStartTime:           strings.Split(c.GetDBBackupWindow(), "-")[0],
+		DBDeletionProtection:        c.GetDBDeletionProtection(),
+		DBParameters:                dbParameters(c),
+		DBRestoreSource:             c.GetDBRestore().Source,
+		DBRestoreInstance:           c.GetDBRestore().Instance,
+		DBRestoreTime:               c.GetDBRestore().Time,
+		DBArchivedInstances:         c.GetDBArchivedInstances(),
+	}
+}
+
+// externalDB is the deployment's external database, which is empty while it uses RDS or CloudSQL
+// webAllowIPs is who can reach the web UI, CredHub and UAA, which is --allow-ips unless it is given its own list
+func webAllowIPs(c config.ConfigView) string {
+	if c.GetWebAllowIPs() != "" {
+		return c.GetWebAllowIPs()
+	}
+	return c.GetAllowIPs()
+}
+
+// metricsAllowIPs is who can reach Grafana, which is --allow-ips unless it is given its own list
+func metricsAllowIPs(c config.ConfigView) string {
+	if c.GetMetricsAllowIPs() != "" {
+		return c.GetMetricsAllowIPs()
+	}
+	return c.GetAllowIPs()
+}
+
+// directorAllowIPs is who can reach the director's APIs besides control-tower and the deployment itself. Without
+// its own list, only a private deployment's director lets --allow-ips in, as any other has a public address
+func directorAllowIPs(c config.ConfigView) string {
+	if c.GetDirectorAllowIPs() != "" {
+		return c.GetDirectorAllowIPs()
+	}
+	if c.GetPrivate() {
+		return c.GetAllowIPs()
+	}
+	return ""
+}
+
+// sshAllowIPs is who can SSH to the director, which is whoever can reach its APIs unless it is given its own list
+func sshAllowIPs(c config.ConfigView) string {
+	if c.GetSSHAllowIPs() != "" {
+		return c.GetSSHAllowIPs()
+	}
+	return directorAllowIPs(c)
+}
+
+// bastionAllowIPs is who can SSH to the bastion besides control-tower, which is --ssh-allow-ips unless the
+// bastion is given its own list
+func bastionAllowIPs(c config.ConfigView) string {
+	if c.GetBastionAllowIPs() != "" {
+		return c.GetBastionAllowIPs()
+	}
+	return c.GetSSHAllowIPs()
+}
+
+func externalDB(c config.ConfigView) db.External {
+	if c.GetExternalDBURL() == "" {
+		return db.External{}
+	}
+	// The URL was validated when it was applied to the config
+	external, _ := db.ParseExternalURL(c.GetExternalDBURL())
+	return external
+}
+
+// auroraEngineVersions are the Aurora PostgreSQL versions that clusters of each major version run. Aurora
+// needs a full version, so its minor versions are upgraded by bumping these rather than automatically
+var auroraEngineVersions = map[string]string{"13": "13.12", "14": "14.9", "15": "15.4", "16": "16.1"}
+
+// dbEngineVersion is the major version of an RDS instance, which picks up minor versions automatically, or
+// the full version of an Aurora cluster
+func dbEngineVersion(c config.ConfigView) string {
+	if c.GetDBEngine() == config.DB_ENGINE_AURORA_SERVERLESS {
+		return auroraEngineVersions[dbVersion(c)]
+	}
+	return dbVersion(c)
+}
+
+// cloudSQLDatabaseVersion is the CloudSQL name of the database's major version, such as POSTGRES_9_6
+func cloudSQLDatabaseVersion(c config.ConfigView) string {
+	return "POSTGRES_" + strings.Replace(dbVersion(c), ".", "_", -1)
+}
+
+// staticDBParameters are the Postgres parameters that are only read when the server starts, which RDS
+// applies when the instance next reboots rather than immediately
+var staticDBParameters = map[string]bool{
+	"autovacuum_max_workers":          true,
+	"max_connections":                 true,
+	"max_locks_per_transaction":       true,
+	"max_logical_replication_workers": true,
+	"max_prepared_transactions":       true,
+	"max_replication_slots":           true,
+	"max_wal_senders":                 true,
+	"max_worker_processes":            true,
+	"rds.logical_replication":         true,
+	"shared_buffers":                  true,
+	"shared_preload_libraries":        true,
+	"track_activity_query_size":       true,
+	"wal_buffers":                     true,
+}
+
+// dbParameters are the database's Postgres parameters, from settings in the format name=value, along with
+// the rds.logical_replication that a Blue/Green deployment needs once it has been enabled
+func dbParameters(c config.ConfigView) []terraform.DBParameter {
+	var params []terraform.DBParameter
+	logicalReplication := c.GetDBLogicalReplication()
+	for _, param := range c.GetDBParams() {
+		nameValue := strings.SplitN(param, "=", 2)
+		applyMethod := "immediate"
+		if staticDBParameters[nameValue[0]] {
+			applyMethod = "pending-reboot"
+		}
+		if nameValue[0] == "rds.logical_replication" && logicalReplication {
+			nameValue[1] = "1"
+			logicalReplication = false
+		}
+		params = append(params, terraform.DBParameter{Name: nameValue[0], Value: nameValue[1], ApplyMethod: applyMethod})
+	}
+	if logicalReplication {
+		params = append(params, terraform.DBParameter{Name: "rds.logical_replication", Value: "1", ApplyMethod: "pending-reboot"})
+	}
+	return params
+}
+
+// directorDBArchive is the archived instance that the director keeps its database on, once restore-db has moved
+// the rest of the deployment onto a restored instance
+func directorDBArchive(c config.ConfigView) string {
+	for i, instance := range c.GetDBArchivedInstances() {
+		if instance == c.GetDirectorDBInstance() {
+			return fmt.Sprintf("archived_%d", i)
+		}
+	}
+	return ""
+}
+
+// dbParameterGroupFamily is the family of the RDS parameter group, which follows the engine's major version
+func dbParameterGroupFamily(c config.ConfigView) string {
+	if c.GetDBEngine() == config.DB_ENGINE_AURORA_SERVERLESS {
+		return "aurora-postgresql" + dbVersion(c)
+	}
+	return "postgres" + dbVersion(c)
+}
+
+// soleTenantNodeType is only given to terraform while there are dedicated VMs to place on the node group
+func soleTenantNodeType(c config.ConfigView) string {
+	if !c.GetDedicatedWeb() && !c.GetDedicatedWorkers() {
+		return ""
 	}
+	return c.GetSoleTenantNodeType()
 }
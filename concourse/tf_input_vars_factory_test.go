@@ -0,0 +1,127 @@
+package concourse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/db"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+func Test_componentAllowIPs(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		conf                                    config.Config
+		web, director, ssh, metrics, bastionIPs string
+	}{
+		{
+			name:    "only --allow-ips",
+			conf:    config.Config{AllowIPs: `"0.0.0.0/0"`},
+			web:     `"0.0.0.0/0"`,
+			metrics: `"0.0.0.0/0"`,
+		},
+		{
+			name:     "only --allow-ips in a private deployment",
+			conf:     config.Config{AllowIPs: `"10.8.0.0/16"`, Private: true},
+			web:      `"10.8.0.0/16"`,
+			director: `"10.8.0.0/16"`,
+			ssh:      `"10.8.0.0/16"`,
+			metrics:  `"10.8.0.0/16"`,
+		},
+		{
+			name: "a list for each component",
+			conf: config.Config{
+				AllowIPs:         `"0.0.0.0/0"`,
+				WebAllowIPs:      `"198.51.100.0/24"`,
+				DirectorAllowIPs: `"203.0.113.10/32"`,
+				SSHAllowIPs:      `"203.0.113.20/32"`,
+				MetricsAllowIPs:  `"192.0.2.0/24"`,
+			},
+			web:        `"198.51.100.0/24"`,
+			director:   `"203.0.113.10/32"`,
+			ssh:        `"203.0.113.20/32"`,
+			metrics:    `"192.0.2.0/24"`,
+			bastionIPs: `"203.0.113.20/32"`,
+		},
+		{
+			name:       "SSH falls back to the director, and the bastion keeps its own list",
+			conf:       config.Config{AllowIPs: `"0.0.0.0/0"`, DirectorAllowIPs: `"203.0.113.10/32"`, BastionAllowIPs: `"203.0.113.30/32"`},
+			web:        `"0.0.0.0/0"`,
+			director:   `"203.0.113.10/32"`,
+			ssh:        `"203.0.113.10/32"`,
+			metrics:    `"0.0.0.0/0"`,
+			bastionIPs: `"203.0.113.30/32"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{webAllowIPs(tt.conf), directorAllowIPs(tt.conf), sshAllowIPs(tt.conf), metricsAllowIPs(tt.conf), bastionAllowIPs(tt.conf)}
+			want := []string{tt.web, tt.director, tt.ssh, tt.metrics, tt.bastionIPs}
+			for i, name := range []string{"web", "director", "ssh", "metrics", "bastion"} {
+				if got[i] != want[i] {
+					t.Errorf("%s allow IPs = %s, want %s", name, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_dbIAMUser(t *testing.T) {
+	if got := dbIAMUser(config.Config{}); got != "" {
+		t.Errorf("dbIAMUser() = %q, want no IAM user without IAM authentication", got)
+	}
+	if got := dbIAMUser(config.Config{DBIAMAuth: true}); got != db.IAMRole {
+		t.Errorf("dbIAMUser() = %q, want %q", got, db.IAMRole)
+	}
+}
+
+func Test_dbParameters(t *testing.T) {
+	tests := []struct {
+		name string
+		conf config.Config
+		want []terraform.DBParameter
+	}{
+		{
+			name: "settings",
+			conf: config.Config{DBParams: []string{"max_connections=500", "log_min_duration_statement=1000"}},
+			want: []terraform.DBParameter{
+				{Name: "max_connections", Value: "500", ApplyMethod: "pending-reboot"},
+				{Name: "log_min_duration_statement", Value: "1000", ApplyMethod: "immediate"},
+			},
+		},
+		{
+			name: "logical replication",
+			conf: config.Config{DBParams: []string{"max_connections=500"}, DBLogicalReplication: true},
+			want: []terraform.DBParameter{
+				{Name: "max_connections", Value: "500", ApplyMethod: "pending-reboot"},
+				{Name: "rds.logical_replication", Value: "1", ApplyMethod: "pending-reboot"},
+			},
+		},
+		{
+			name: "logical replication turned off by a setting",
+			conf: config.Config{DBParams: []string{"rds.logical_replication=0"}, DBLogicalReplication: true},
+			want: []terraform.DBParameter{
+				{Name: "rds.logical_replication", Value: "1", ApplyMethod: "pending-reboot"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dbParameters(tt.conf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dbParameters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_directorDBArchive(t *testing.T) {
+	conf := config.Config{DBArchivedInstances: []string{"terraform-20230101", "control-tower-test-restored-1700000000"}}
+	if got := directorDBArchive(conf); got != "" {
+		t.Errorf("directorDBArchive() = %q, want the director to stay on the current instance", got)
+	}
+	conf.DirectorDBInstance = "terraform-20230101"
+	if got := directorDBArchive(conf); got != "archived_0" {
+		t.Errorf("directorDBArchive() = %q, want archived_0", got)
+	}
+}
@@ -0,0 +1,88 @@
+package concourse
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/fly"
+	"github.com/EngineerBetter/control-tower/soak"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+// buildsToWatch is how many recent builds are fetched each time the error rate is measured
+const buildsToWatch = 500
+
+// watchUpgrade starts watching the rate of errored builds while an existing Concourse has its
+// workers upgraded in batches, cancelling the deploy if the rate rises too far. The returned
+// function stops watching, and returns an error if the upgrade was halted
+func (client *Client) watchUpgrade(c config.ConfigView, tfOutputs terraform.Outputs) (func() error, error) {
+	notWatching := func() error { return nil }
+	if c.GetUpgradeBatchSize() == 0 || c.GetConcoursePassword() == "" {
+		return notWatching, nil
+	}
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   c.GetDeployment(),
+		API:      fmt.Sprintf("https://%s", c.GetDomain()),
+		Username: c.GetConcourseUsername(),
+		Password: c.GetConcoursePassword(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a running Concourse there are no builds to watch, and nothing to upgrade gradually
+	if connected, err1 := flyClient.CanConnect(); err1 != nil || !connected {
+		flyClient.Cleanup()
+		return notWatching, nil
+	}
+
+	builds, err := flyClient.Builds(buildsToWatch)
+	if err != nil {
+		flyClient.Cleanup()
+		return nil, fmt.Errorf("error measuring the rate of errored builds before upgrading: [%v]", err)
+	}
+
+	monitor := soak.Monitor{
+		Builds: func() ([]fly.Build, error) {
+			return flyClient.Builds(buildsToWatch)
+		},
+		Cancel: func() error {
+			boshClient, err1 := client.buildBoshClient(c, tfOutputs)
+			if err1 != nil {
+				return err1
+			}
+			defer boshClient.Cleanup()
+			return boshClient.CancelDeploy()
+		},
+		Baseline:    soak.ErrorRate(builds, time.Time{}),
+		MaxIncrease: float64(c.GetSoakMaxErrorRateIncrease()),
+		MinBuilds:   soak.DefaultMinBuilds,
+		Interval:    soak.DefaultInterval,
+		Stderr:      client.stderr,
+	}
+	fmt.Fprintf(client.stdout, "Upgrading workers in batches of %d, halting if the rate of errored builds rises more than %d points above %s\n", c.GetUpgradeBatchSize(), c.GetSoakMaxErrorRateIncrease(), monitor.Baseline)
+
+	stop := make(chan struct{})
+	results := make(chan soak.Result, 1)
+	since := time.Now()
+	go func() { results <- monitor.Watch(since, stop) }()
+
+	return func() error {
+		close(stop)
+		result := <-results
+		flyClient.Cleanup()
+		if !result.Halted {
+			return nil
+		}
+		if result.Err != nil {
+			return fmt.Errorf("the rate of errored builds rose to %s during the upgrade, but it could not be halted: [%v]", result.Rate, result.Err)
+		}
+		return fmt.Errorf("halted the upgrade as the rate of errored builds rose to %s, from %s before it began", result.Rate, monitor.Baseline)
+	}, nil
+}
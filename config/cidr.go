@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/apparentlymart/go-cidr/cidr"
+)
+
+// NATSubnetBits is the prefix length of the subnet that holds the NAT gateway of a private deployment on AWS
+const NATSubnetBits = 28
+
+var (
+	errNoRoom = errors.New("network has no room left")
+	errTooBig = errors.New("subnet is bigger than network")
+)
+
+// NamedCIDR is a range of the deployment's network, with the Name of the flag it is given by, for errors
+type NamedCIDR struct {
+	Name string
+	CIDR string
+}
+
+// CheckCIDROverlaps returns an error naming the first two ranges that share any addresses. Ranges without a
+// CIDR are skipped
+func CheckCIDROverlaps(ranges []NamedCIDR) error {
+	var parsed []*net.IPNet
+	var named []NamedCIDR
+	for _, r := range ranges {
+		if r.CIDR == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(r.CIDR)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid CIDR", r.Name)
+		}
+		for i, previous := range parsed {
+			if previous.Contains(ipNet.IP) || ipNet.Contains(previous.IP) {
+				return fmt.Errorf("%s %s overlaps %s %s", named[i].Name, named[i].CIDR, r.Name, r.CIDR)
+			}
+		}
+		parsed = append(parsed, ipNet)
+		named = append(named, r)
+	}
+	return nil
+}
+
+// AllocateNATCIDR finds the first block of network for the subnet of the NAT gateway of a private deployment,
+// whose VMs reach the internet through it rather than from public addresses of their own
+func AllocateNATCIDR(network string, taken []string) (string, error) {
+	allocated, err := allocateCIDR(network, NATSubnetBits, taken)
+	if err == errNoRoom || err == errTooBig {
+		return "", fmt.Errorf("network %s has no room left for a /%d subnet for the NAT gateway", network, NATSubnetBits)
+	}
+	return allocated, err
+}
+
+// allocateCIDR finds the first block of network with the prefix length bits that doesn't overlap any of the
+// taken CIDRs
+func allocateCIDR(network string, bits int, taken []string) (string, error) {
+	_, networkNet, err := net.ParseCIDR(network)
+	if err != nil {
+		return "", err
+	}
+	var takenNets []*net.IPNet
+	for _, t := range taken {
+		_, takenNet, err := net.ParseCIDR(t)
+		if err != nil {
+			return "", err
+		}
+		takenNets = append(takenNets, takenNet)
+	}
+
+	networkBits, _ := networkNet.Mask.Size()
+	newBits := bits - networkBits
+	if newBits < 0 {
+		return "", errTooBig
+	}
+
+	for num := 0; num < 1<<uint(newBits); num++ {
+		candidate, err := cidr.Subnet(networkNet, newBits, num)
+		if err != nil {
+			return "", err
+		}
+		free := true
+		for _, takenNet := range takenNets {
+			if candidate.Contains(takenNet.IP) || takenNet.Contains(candidate.IP) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return candidate.String(), nil
+		}
+	}
+	return "", errNoRoom
+}
+
+// ValidateCidrRanges checks that the ranges of a deployment's network are valid, big enough and, on AWS, within the
+// network, and that none of them overlap. Errors name the flags the ranges are given by
+func ValidateCidrRanges(iaasName iaas.Name, networkCIDR, publicCIDR, privateCIDR, RDS1CIDR, RDS2CIDR string) error {
+	var parsedNetworkCidr, parsedPublicCidr, parsedPrivateCidr, parsedRDS1CIDR, parsedRDS2CIDR *net.IPNet
+	var err error
+
+	if networkCIDR == "" && publicCIDR == "" && privateCIDR == "" && RDS1CIDR == "" && RDS2CIDR == "" {
+		return nil
+	}
+
+	if iaasName == iaas.AWS {
+		if (privateCIDR != "" || publicCIDR != "" || RDS1CIDR != "" || RDS2CIDR != "") && networkCIDR == "" {
+			return errors.New("error validating CIDR ranges - --network-cidr must be provided when using AWS")
+		}
+		_, parsedNetworkCidr, err = net.ParseCIDR(networkCIDR)
+		if err != nil {
+			return errors.New("error validating CIDR ranges - --network-cidr is not a valid CIDR")
+		}
+		if !validateNetworkSize(parsedNetworkCidr) {
+			return errors.New("error validating CIDR ranges - --network-cidr is not big enough, at least /26 needed.")
+		}
+		if RDS1CIDR == "" || RDS2CIDR == "" {
+			return errors.New("error validating CIDR ranges - both --rds-subnet-range1 and --rds-subnet-range2 must be provided")
+		}
+		_, parsedRDS1CIDR, err = net.ParseCIDR(RDS1CIDR)
+		if err != nil {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range1 is not a valid CIDR")
+		}
+		if !validateRDSSubnetSize(parsedRDS1CIDR) {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range1 is not big enough, at least /29 needed.")
+		}
+		_, parsedRDS2CIDR, err = net.ParseCIDR(RDS2CIDR)
+		if err != nil {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range2 is not a valid CIDR")
+		}
+		if !validateRDSSubnetSize(parsedRDS2CIDR) {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range2 is not big enough, at least /29 needed.")
+		}
+
+	}
+	if privateCIDR != "" || publicCIDR != "" {
+		if privateCIDR == "" || publicCIDR == "" {
+			return errors.New("error validating CIDR ranges - both --public-cidr and --private-cidr must be provided")
+		}
+	}
+	_, parsedPublicCidr, err = net.ParseCIDR(publicCIDR)
+	if err != nil {
+		return errors.New("error validating CIDR ranges - --public-cidr is not a valid CIDR")
+	}
+	if !validateSubnetSize(parsedPublicCidr) {
+		return errors.New("error validating CIDR ranges - --public-cidr is not big enough, at least /28 needed.")
+	}
+	_, parsedPrivateCidr, err = net.ParseCIDR(privateCIDR)
+	if err != nil {
+		return errors.New("error validating CIDR ranges - --private-cidr is not a valid CIDR")
+	}
+	if !validateSubnetSize(parsedPrivateCidr) {
+		return errors.New("error validating CIDR ranges - --private-cidr is not big enough, at least /28 needed.")
+	}
+
+	if iaasName == iaas.AWS {
+		if !parsedNetworkCidr.Contains(parsedPublicCidr.IP) {
+			return errors.New("error validating CIDR ranges - --public-cidr must be within --network-cidr")
+		}
+
+		if !parsedNetworkCidr.Contains(parsedPrivateCidr.IP) {
+			return errors.New("error validating CIDR ranges - --private-cidr must be within --network-cidr")
+		}
+
+		if !parsedNetworkCidr.Contains(parsedRDS1CIDR.IP) {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range1 must be within --network-cidr")
+		}
+
+		if !parsedNetworkCidr.Contains(parsedRDS2CIDR.IP) {
+			return errors.New("error validating CIDR ranges - --rds-subnet-range2 must be within --network-cidr")
+		}
+	}
+
+	err = CheckCIDROverlaps([]NamedCIDR{
+		{Name: "--public-cidr", CIDR: publicCIDR},
+		{Name: "--private-cidr", CIDR: privateCIDR},
+		{Name: "--rds-subnet-range1", CIDR: RDS1CIDR},
+		{Name: "--rds-subnet-range2", CIDR: RDS2CIDR},
+	})
+	if err != nil {
+		return fmt.Errorf("error validating CIDR ranges - %v", err)
+	}
+
+	return nil
+}
+
+func cidrSize(cidr *net.IPNet) float64 {
+	prefix, suffix := cidr.Mask.Size()
+	return math.Pow(2, float64(suffix-prefix))
+}
+
+func validateNetworkSize(cidr *net.IPNet) bool {
+	size := cidrSize(cidr)
+	return size > 16
+}
+
+func validateSubnetSize(cidr *net.IPNet) bool {
+	size := cidrSize(cidr)
+	return size > 8
+}
+
+func validateRDSSubnetSize(cidr *net.IPNet) bool {
+	size := cidrSize(cidr)
+	return size > 4
+}
@@ -0,0 +1,335 @@
+package config_test
+
+import (
+	"testing"
+
+	. "github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/iaas"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CIDR", func() {
+	Describe("CheckCIDROverlaps", func() {
+		It("accepts ranges that don't overlap", func() {
+			Expect(CheckCIDROverlaps([]NamedCIDR{
+				{Name: "--public-cidr", CIDR: "10.0.0.0/24"},
+				{Name: "--private-cidr", CIDR: "10.0.1.0/24"},
+				{Name: "--rds-subnet-range1", CIDR: "10.0.4.0/29"},
+				{Name: "--rds-subnet-range2", CIDR: ""},
+			})).To(Succeed())
+		})
+
+		It("names the ranges that overlap", func() {
+			err := CheckCIDROverlaps([]NamedCIDR{
+				{Name: "--public-cidr", CIDR: "10.0.0.0/24"},
+				{Name: "--private-cidr", CIDR: "10.0.1.0/24"},
+				{Name: "--rds-subnet-range1", CIDR: "10.0.0.0/16"},
+			})
+			Expect(err).To(MatchError("--public-cidr 10.0.0.0/24 overlaps --rds-subnet-range1 10.0.0.0/16"))
+		})
+
+		It("errors on an invalid range", func() {
+			err := CheckCIDROverlaps([]NamedCIDR{{Name: "--public-cidr", CIDR: "10.0.0.0"}})
+			Expect(err).To(MatchError("--public-cidr is not a valid CIDR"))
+		})
+	})
+
+	Describe("AllocateNATCIDR", func() {
+		It("allocates the first free /28", func() {
+			Expect(AllocateNATCIDR("10.0.0.0/16", []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.4.0/24", "10.0.5.0/24"})).To(Equal("10.0.2.0/28"))
+		})
+
+		It("errors when the network is full", func() {
+			_, err := AllocateNATCIDR("10.0.0.0/24", []string{"10.0.0.0/25", "10.0.0.128/25"})
+			Expect(err).To(MatchError("network 10.0.0.0/24 has no room left for a /28 subnet for the NAT gateway"))
+		})
+	})
+})
+
+func TestValidateCidrRanges(t *testing.T) {
+	type args struct {
+		iaasName    iaas.Name
+		networkCidr string
+		publicCidr  string
+		privateCidr string
+		rds1Cidr    string
+		rds2Cidr    string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantErr       bool
+		desiredErrMsg string
+	}{
+		{
+			name: "does not err if no flags provided",
+			args: args{
+				iaasName: iaas.AWS,
+			},
+			wantErr: false,
+		}, //
+		{
+			name: "errs if public range is provided and private is not",
+			args: args{
+				iaasName:    iaas.GCP,
+				networkCidr: "10.0.0.0/16",
+				publicCidr:  "10.0.0.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - both --public-cidr and --private-cidr must be provided",
+		},
+		{
+			name: "errs if private range is provided and public is not",
+			args: args{
+				iaasName:    iaas.GCP,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - both --public-cidr and --private-cidr must be provided",
+		},
+		{
+			name: "errs if provider is AWS and default range is not provided",
+			args: args{
+				iaasName:    iaas.AWS,
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --network-cidr must be provided when using AWS",
+		},
+		{
+			name: "errs if provider is AWS and rds1 range is not provided",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds2Cidr:    "10.0.4.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - both --rds-subnet-range1 and --rds-subnet-range2 must be provided",
+		},
+		{
+			name: "errs if provider is AWS and rds2 range is not provided",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "10.0.2.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - both --rds-subnet-range1 and --rds-subnet-range2 must be provided",
+		},
+		{
+			name: "errs if default range isn't a CIDR",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "aNetwork",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --network-cidr is not a valid CIDR",
+		},
+		{
+			name: "errs if public range isn't a CIDR",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "aPublicNetwork",
+				rds1Cidr:    "10.0.2.0/16",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --public-cidr is not a valid CIDR",
+		},
+		{
+			name: "errs if private range isn't a CIDR",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "aPrivateNetwork",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "10.0.2.0/16",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --private-cidr is not a valid CIDR",
+		},
+		{
+			name: "errs if rds1 range isn't a CIDR",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "aPrivateNetwork",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range1 is not a valid CIDR",
+		},
+		{
+			name: "errs if rds2 range isn't a CIDR",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "10.0.2.0/16",
+				rds2Cidr:    "aPrivateNetwork",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range2 is not a valid CIDR",
+		},
+		{
+			name: "errs if provider is AWS and public range is not in default range",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "172.0.0.0/24",
+				rds1Cidr:    "10.0.2.0/16",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --public-cidr must be within --network-cidr",
+		},
+		{
+			name: "errs if provider is AWS and private range is not in default range",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "172.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "10.0.2.0/16",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --private-cidr must be within --network-cidr",
+		},
+		{
+			name: "errs if provider is AWS and rds1 range is not in default range",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "192.168.2.0/16",
+				rds2Cidr:    "10.0.3.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range1 must be within --network-cidr",
+		},
+		{
+			name: "errs if provider is AWS and rds2 range is not in default range",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.1.0/24",
+				rds1Cidr:    "10.0.3.0/16",
+				rds2Cidr:    "192.168.2.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range2 must be within --network-cidr",
+		},
+		{
+			name: "errs if public range overlaps with private range",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.0.0/24",
+				rds1Cidr:    "10.0.3.0/16",
+				rds2Cidr:    "10.0.4.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --public-cidr 10.0.0.0/24 overlaps --private-cidr 10.0.0.0/24",
+		},
+		{
+			name: "errs if network cidr range is not big enough (16 usable IPs)",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/28",
+				privateCidr: "10.0.0.0/32",
+				publicCidr:  "10.0.0.0/24",
+				rds1Cidr:    "10.0.3.0/16",
+				rds2Cidr:    "10.0.4.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --network-cidr is not big enough, at least /26 needed.",
+		},
+		{
+			name: "errs if private cidr range is not big enough (8 usable IPs)",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/32",
+				publicCidr:  "10.0.0.0/24",
+				rds1Cidr:    "10.0.3.0/16",
+				rds2Cidr:    "10.0.4.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --private-cidr is not big enough, at least /28 needed.",
+		},
+		{
+			name: "errs if public cidr range is not big enough  (8 usable IPs)",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.0.0/32",
+				rds1Cidr:    "10.0.3.0/16",
+				rds2Cidr:    "10.0.4.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --public-cidr is not big enough, at least /28 needed.",
+		},
+		{
+			name: "errs if rds1 cidr range is not big enough  (8 usable IPs)",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.0.0/24",
+				rds1Cidr:    "10.0.3.0/32",
+				rds2Cidr:    "10.0.4.0/16",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range1 is not big enough, at least /29 needed.",
+		},
+		{
+			name: "errs if rds2 cidr range is not big enough  (8 usable IPs)",
+			args: args{
+				iaasName:    iaas.AWS,
+				networkCidr: "10.0.0.0/16",
+				privateCidr: "10.0.0.0/24",
+				publicCidr:  "10.0.0.0/24",
+				rds1Cidr:    "10.0.3.0/24",
+				rds2Cidr:    "10.0.4.0/32",
+			},
+			wantErr:       true,
+			desiredErrMsg: "error validating CIDR ranges - --rds-subnet-range2 is not big enough, at least /29 needed.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCidrRanges(tt.args.iaasName, tt.args.networkCidr, tt.args.publicCidr, tt.args.privateCidr, tt.args.rds1Cidr, tt.args.rds2Cidr)
+
+			if (err == nil && tt.wantErr) || (err != nil && !tt.wantErr) {
+				t.Errorf("ValidateCidrRanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && tt.wantErr {
+				if err.Error() != tt.desiredErrMsg {
+					t.Errorf("ValidateCidrRanges() error message = [%v], desiredErrMsg [%v]", err.Error(), tt.desiredErrMsg)
+				}
+			}
+		})
+	}
+}
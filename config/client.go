@@ -17,6 +17,8 @@ type IClient interface {
 	Load() (Config, error)
 	DeleteAll(config ConfigView) error
 	Update(Config) error
+	LoadRuntime() (Runtime, error)
+	UpdateRuntime(Runtime) error
 	StoreAsset(filename string, contents []byte) error
 	HasAsset(filename string) (bool, error)
 	ConfigExists() (bool, error)
@@ -79,14 +81,74 @@ func (client *Client) ConfigExists() (bool, error) {
 	return client.HasAsset(configFilePath)
 }
 
-// Update stores the control-tower config file to S3
+// Update stores the control-tower config file to S3, along with the parts of it in the runtime file
 func (client *Client) Update(config Config) error {
 	bytes, err := json.Marshal(config)
 	if err != nil {
 		return err
 	}
 
-	return client.Iaas.WriteFile(client.configBucket(), configFilePath, bytes)
+	if err = client.Iaas.WriteFile(client.configBucket(), configFilePath, bytes); err != nil {
+		return err
+	}
+
+	// Keep the outputs that the last deploy recorded
+	runtime := Runtime{}
+	hasRuntime, err := client.HasAsset(runtimeFilePath)
+	if err != nil {
+		return err
+	}
+	if hasRuntime {
+		if runtime, err = client.loadRuntime(); err != nil {
+			return err
+		}
+	}
+	runtime.Config = RuntimeConfig(config)
+	return client.UpdateRuntime(runtime)
+}
+
+// LoadRuntime loads the runtime file, which holds what scaling the workers needs, falling back to the config
+// file for deployments that haven't been deployed since the runtime file was added
+func (client *Client) LoadRuntime() (Runtime, error) {
+	if client.BucketError != nil {
+		return Runtime{}, client.BucketError
+	}
+
+	exists, err := client.HasAsset(runtimeFilePath)
+	if err != nil {
+		return Runtime{}, err
+	}
+	if !exists {
+		conf, err := client.Load()
+		if err != nil {
+			return Runtime{}, err
+		}
+		return Runtime{Config: RuntimeConfig(conf)}, nil
+	}
+	return client.loadRuntime()
+}
+
+// UpdateRuntime stores the runtime file to S3
+func (client *Client) UpdateRuntime(runtime Runtime) error {
+	bytes, err := json.Marshal(runtime)
+	if err != nil {
+		return err
+	}
+
+	return client.Iaas.WriteFile(client.configBucket(), runtimeFilePath, bytes)
+}
+
+func (client *Client) loadRuntime() (Runtime, error) {
+	runtimeBytes, err := client.Iaas.LoadFile(client.configBucket(), runtimeFilePath)
+	if err != nil {
+		return Runtime{}, err
+	}
+
+	runtime := Runtime{}
+	if err := json.Unmarshal(runtimeBytes, &runtime); err != nil {
+		return Runtime{}, err
+	}
+	return runtime, nil
 }
 
 // DeleteAll deletes the entire configuration bucket
@@ -115,6 +177,19 @@ func (client *Client) Load() (Config, error) {
 
 	conf = populateMandatoryFieldsAddedSinceLastSave(conf)
 
+	// Autoscaling only records the number of workers it has deployed in the runtime file
+	hasRuntime, err := client.HasAsset(runtimeFilePath)
+	if err != nil {
+		return Config{}, err
+	}
+	if hasRuntime {
+		runtime, err := client.loadRuntime()
+		if err != nil {
+			return Config{}, err
+		}
+		conf.ConcourseWorkerCount = runtime.Config.ConcourseWorkerCount
+	}
+
 	return conf, nil
 }
 
@@ -172,7 +247,12 @@ func determineBucketName(iaas iaas.Provider, namespace, project string) (string,
 	if err != nil {
 		foundNamespacedBucket, err = iaas.BucketExists(namespaceBucketName)
 		if err != nil {
-			return "", false, fmt.Errorf("error looking for possible config buckets [%v] or [%v]: [%v]", regionBucketName, namespaceBucketName, err)
+			// Users with read-only access may be allowed to read the config but not to look up buckets
+			var configErr error
+			foundRegionNamedBucket, foundNamespacedBucket, configErr = findConfigFile(iaas, regionBucketName, namespaceBucketName)
+			if configErr != nil {
+				return "", false, fmt.Errorf("error looking for possible config buckets [%v] or [%v]: [%v]", regionBucketName, namespaceBucketName, err)
+			}
 		}
 	}
 
@@ -188,6 +268,27 @@ func determineBucketName(iaas iaas.Provider, namespace, project string) (string,
 	}
 }
 
+func findConfigFile(iaas iaas.Provider, regionBucketName, namespaceBucketName string) (bool, bool, error) {
+	inRegionBucket, regionErr := hasConfigFile(iaas, regionBucketName)
+	inNamespaceBucket, namespaceErr := hasConfigFile(iaas, namespaceBucketName)
+	if regionErr != nil && namespaceErr != nil {
+		return false, false, namespaceErr
+	}
+	return inRegionBucket, inNamespaceBucket, nil
+}
+
+// hasConfigFile looks for the runtime file as well, as it is all that the autoscaler's credential can see
+func hasConfigFile(iaas iaas.Provider, bucket string) (bool, error) {
+	found, err := iaas.HasFile(bucket, configFilePath)
+	if !found {
+		found, _ = iaas.HasFile(bucket, runtimeFilePath)
+	}
+	if found {
+		return true, nil
+	}
+	return false, err
+}
+
 func determineNamespace(namespace, region string) string {
 	if namespace == "" {
 		return region
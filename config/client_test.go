@@ -199,6 +199,44 @@ func TestNew(t *testing.T) {
 				return false, fmt.Errorf("an error")
 			},
 		},
+		{
+			name: "with read-only access to a namespace based bucket",
+			args: args{
+				iaas:      provider,
+				project:   "aProject",
+				namespace: "someNamespace",
+			},
+			want: &Client{
+				Iaas:         provider,
+				Project:      "aProject",
+				Namespace:    "someNamespace",
+				BucketName:   "control-tower-aProject-someNamespace-config",
+				BucketExists: true,
+				BucketError:  nil,
+			},
+			FakeBucketExists: func(name string) (bool, error) {
+				return false, fmt.Errorf("AccessDenied: Access Denied")
+			},
+		},
+		{
+			name: "with access to only the runtime file of a namespace based bucket",
+			args: args{
+				iaas:      provider,
+				project:   "runtimeOnly",
+				namespace: "someNamespace",
+			},
+			want: &Client{
+				Iaas:         provider,
+				Project:      "runtimeOnly",
+				Namespace:    "someNamespace",
+				BucketName:   "control-tower-runtimeOnly-someNamespace-config",
+				BucketExists: true,
+				BucketError:  nil,
+			},
+			FakeBucketExists: func(name string) (bool, error) {
+				return false, fmt.Errorf("AccessDenied: Access Denied")
+			},
+		},
 		{
 			name: "with Namespace and bucket existing and namespace == region",
 			args: args{
@@ -222,6 +260,13 @@ func TestNew(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			provider.BucketExistsStub = tt.FakeBucketExists
+			provider.HasFileStub = func(bucket, path string) (bool, error) {
+				if bucket == "control-tower-runtimeOnly-someNamespace-config" && path != "runtime.json" {
+					return false, fmt.Errorf("AccessDenied: Access Denied")
+				}
+				return bucket == "control-tower-aProject-someNamespace-config" && path == "config.json" ||
+					bucket == "control-tower-runtimeOnly-someNamespace-config", nil
+			}
 			if got := New(tt.args.iaas, tt.args.project, tt.args.namespace); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("New() = %v,\n want %v", got, tt.want)
 			}
@@ -361,3 +406,62 @@ func TestClient_HasConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Runtime(t *testing.T) {
+	files := map[string][]byte{}
+	provider := new(iaasfakes.FakeProvider)
+	provider.HasFileStub = func(bucket, path string) (bool, error) {
+		_, ok := files[path]
+		return ok, nil
+	}
+	provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+		return files[path], nil
+	}
+	provider.WriteFileStub = func(bucket, path string, contents []byte) error {
+		files[path] = contents
+		return nil
+	}
+	client := &Client{Iaas: provider, BucketName: "control-tower-test-eu-west-1-config"}
+
+	conf := Config{ConcourseWorkerCount: 2, DirectorPassword: "secret", PrivateKey: "key", RDSPassword: "db-secret"}
+	runtime, err := client.LoadRuntime()
+	if err == nil {
+		t.Fatalf("LoadRuntime() without any config = %v, want an error", runtime)
+	}
+
+	if err = client.Update(conf); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if runtime, err = client.LoadRuntime(); err != nil {
+		t.Fatalf("LoadRuntime() error = %v", err)
+	}
+	want := Runtime{Config: Config{ConcourseWorkerCount: 2, DirectorPassword: "secret"}}
+	if !reflect.DeepEqual(runtime, want) {
+		t.Errorf("LoadRuntime() = %v, want %v", runtime, want)
+	}
+
+	runtime.Config.ConcourseWorkerCount = 5
+	runtime.Outputs = map[string]string{"DirectorPublicIP": "99.99.99.99"}
+	if err = client.UpdateRuntime(runtime); err != nil {
+		t.Fatalf("UpdateRuntime() error = %v", err)
+	}
+	got, err := client.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.ConcourseWorkerCount != 5 || got.RDSPassword != "db-secret" {
+		t.Errorf("Load() = %d workers and RDS password %q, want the autoscaled 5 workers and the rest of config.json", got.ConcourseWorkerCount, got.RDSPassword)
+	}
+
+	// Deploying again keeps the outputs recorded in the runtime file
+	conf.ConcourseWorkerCount = 4
+	if err = client.Update(conf); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if runtime, err = client.LoadRuntime(); err != nil {
+		t.Fatalf("LoadRuntime() error = %v", err)
+	}
+	if runtime.Config.ConcourseWorkerCount != 4 || runtime.Outputs["DirectorPublicIP"] != "99.99.99.99" {
+		t.Errorf("LoadRuntime() after Update() = %v", runtime)
+	}
+}
@@ -1,8 +1,26 @@
 package config
 
+import "github.com/EngineerBetter/control-tower/quota"
+
 const SPOT = "spot"
 const ON_DEMAND = "on-demand"
 
+// DBEngine is either an RDS for PostgreSQL instance, which is also what an empty DBEngine means, or on AWS
+// an Aurora Serverless v2 cluster
+const DB_ENGINE_POSTGRES = "postgres"
+const DB_ENGINE_AURORA_SERVERLESS = "aurora-serverless"
+
+// NATTopology is how VMs without public addresses reach the internet on AWS: through a single NAT gateway, which
+// is also what an empty NATTopology means, a NAT gateway in each worker zone as well, or a NAT instance
+const NAT_SINGLE = "single"
+const NAT_PER_ZONE = "per-zone"
+const NAT_INSTANCE = "instance"
+
+// DBVersion is the Postgres major version of the database. Deployments without one, from before it could be
+// chosen, run the version that each IAAS's template used to hard-code
+const DEFAULT_AWS_DB_VERSION = "13"
+const DEFAULT_GCP_DB_VERSION = "9.6"
+
 func ConvertSpotBoolToVMProvisioningType(spot bool) string {
 	if spot {
 		return SPOT
@@ -13,85 +31,359 @@ func ConvertSpotBoolToVMProvisioningType(spot bool) string {
 
 // Config represents a control-tower configuration file
 type Config struct {
-	AllowIPs                 string `json:"allow_ips"`
-	AllowIPsUnformatted      string `json:"allow_ips_unformatted"`
-	AvailabilityZone         string `json:"availability_zone"`
-	BitbucketClientID        string `json:"bitbucket_client_id"`
-	BitbucketClientSecret    string `json:"bitbucket_client_secret"`
-	ConcourseCACert          string `json:"concourse_ca_cert"`
-	ConcourseCert            string `json:"concourse_cert"`
-	ConcourseKey             string `json:"concourse_key"`
-	ConcoursePassword        string `json:"concourse_password"`
-	ConcourseUsername        string `json:"concourse_username"`
-	ConcourseWebSize         string `json:"concourse_web_size"`
-	ConcourseWorkerCount     int    `json:"concourse_worker_count"`
-	ConcourseWorkerSize      string `json:"concourse_worker_size"`
-	ConfigBucket             string `json:"config_bucket"`
-	CredhubAdminClientSecret string `json:"credhub_admin_client_secret"`
-	CredhubCACert            string `json:"credhub_ca_cert"`
-	CredhubPassword          string `json:"credhub_password"`
-	CredhubURL               string `json:"credhub_url"`
-	CredhubUsername          string `json:"credhub_username"`
-	Deployment               string `json:"deployment"`
-	DirectorCACert           string `json:"director_ca_cert"`
-	DirectorCert             string `json:"director_cert"`
-	DirectorHMUserPassword   string `json:"director_hm_user_password"`
-	DirectorKey              string `json:"director_key"`
-	DirectorMbusPassword     string `json:"director_mbus_password"`
-	DirectorNATSPassword     string `json:"director_nats_password"`
-	DirectorPassword         string `json:"director_password"`
-	DirectorPublicIP         string `json:"director_public_ip"`
-	DirectorRegistryPassword string `json:"director_registry_password"`
-	DirectorUsername         string `json:"director_username"`
-	Domain                   string `json:"domain"`
-	EnableGlobalResources    bool   `json:"enable_global_resources"`
-	EnablePipelineInstances  bool   `json:"enable_pipeline_instances"`
-	InfluxDbRetention        string `json:"influx_db_retention_period"`
-	EncryptionKey            string `json:"encryption_key"`
-	GithubClientID           string `json:"github_client_id"`
-	GithubClientSecret       string `json:"github_client_secret"`
-	GithubHost               string `json:"github_host"`
-	GithubCaCert             string `json:"github_ca_cert"`
-	GrafanaPassword          string `json:"grafana_password"`
-	HostedZoneID             string `json:"hosted_zone_id"`
-	HostedZoneRecordPrefix   string `json:"hosted_zone_record_prefix"`
-	IAAS                     string `json:"iaas"`
-	MainGithubUsers          string `json:"main_github_users"`
-	MainGithubTeams          string `json:"main_github_teams"`
-	MainGithubOrgs           string `json:"main_github_orgs"`
-	MicrosoftClientID        string `json:"microsoft_client_id"`
-	MicrosoftClientSecret    string `json:"microsoft_client_secret"`
-	MicrosoftTenant          string `json:"microsoft_tenant"`
-	Namespace                string `json:"namespace"`
-	NetworkCIDR              string `json:"network_cidr"`
-	NoMetrics                bool   `json:"no_metrics"`
-	PersistentDisk           string `json:"persistent_disk"`
-	PrivateCIDR              string `json:"private_cidr"`
-	PrivateKey               string `json:"private_key"`
-	Project                  string `json:"project"`
-	PublicCIDR               string `json:"public_cidr"`
-	PublicKey                string `json:"public_key"`
-	RDS1CIDR                 string `json:"rds1_cidr"`
-	RDS2CIDR                 string `json:"rds2_cidr"`
-	RDSDefaultDatabaseName   string `json:"rds_default_database_name"`
-	RDSInstanceClass         string `json:"rds_instance_class"`
-	RDSPassword              string `json:"rds_password"`
-	RDSUsername              string `json:"rds_username"`
-	RDSDiskEncryption        bool   `json:"rds_disk_encryption"`
-	Region                   string `json:"region"`
-	SourceAccessIP           string `json:"source_access_ip"`
+	AllowIPs                      string   `json:"allow_ips"`
+	AllowIPsUnformatted           string   `json:"allow_ips_unformatted"`
+	ARM64ReleaseURL               string   `json:"arm64_release_url"`
+	ARM64ReleaseSHA1              string   `json:"arm64_release_sha1"`
+	ARM64StemcellURL              string   `json:"arm64_stemcell_url"`
+	AvailabilityZone              string   `json:"availability_zone"`
+	BitbucketClientID             string   `json:"bitbucket_client_id"`
+	BitbucketClientSecret         string   `json:"bitbucket_client_secret"`
+	ConcourseCACert               string   `json:"concourse_ca_cert"`
+	ConcourseCert                 string   `json:"concourse_cert"`
+	ConcourseKey                  string   `json:"concourse_key"`
+	ConcoursePassword             string   `json:"concourse_password"`
+	ConcourseUsername             string   `json:"concourse_username"`
+	ConcourseVars                 string   `json:"concourse_vars"`
+	TeamsSpec                     string   `json:"teams_spec"`
+	CredentialManager             string   `json:"credential_manager"`
+	ManagedPrometheus             bool     `json:"managed_prometheus"`
+	ManagedPrometheusURL          string   `json:"managed_prometheus_workspace_url"`
+	ManagedPrometheusRegion       string   `json:"managed_prometheus_region"`
+	EnablePrometheusMetrics       bool     `json:"enable_prometheus_metrics"`
+	MetricsScrapeAllowIPs         []string `json:"metrics_scrape_allow_ips"`
+	MetricsScrapePassword         string   `json:"metrics_scrape_password"`
+	ExternalWorkerAllowIPs        []string `json:"external_worker_allow_ips"`
+	SIEMEndpoint                  string   `json:"siem_endpoint"`
+	ConcourseVersion              string   `json:"concourse_version"`
+	ConcourseReleaseSHA1          string   `json:"concourse_release_sha1"`
+	OSConfReleaseVersion          string   `json:"os_conf_release_version"`
+	OSConfReleaseSHA1             string   `json:"os_conf_release_sha1"`
+	ContainerPlacementStrategies  []string `json:"container_placement_strategies"`
+	MaxActiveTasksPerWorker       int      `json:"max_active_tasks_per_worker"`
+	MaxActiveContainersPerWorker  int      `json:"max_active_containers_per_worker"`
+	MaxActiveVolumesPerWorker     int      `json:"max_active_volumes_per_worker"`
+	DefaultTaskCPULimit           int      `json:"default_task_cpu_limit"`
+	DefaultTaskMemoryLimit        string   `json:"default_task_memory_limit"`
+	InterceptIdleTimeout          string   `json:"intercept_idle_timeout"`
+	FailedGracePeriod             string   `json:"failed_grace_period"`
+	EnableP2PVolumeStreaming      bool     `json:"enable_p2p_volume_streaming"`
+	StreamingCompression          string   `json:"streaming_compression"`
+	ConcourseWebEnv               []string `json:"concourse_web_env"`
+	ConcourseWorkerEnv            []string `json:"concourse_worker_env"`
+	ExternalURL                   string   `json:"external_url"`
+	SyslogAddress                 string   `json:"syslog_address"`
+	SyslogTransport               string   `json:"syslog_transport"`
+	SyslogCACert                  string   `json:"syslog_ca_cert"`
+	NewRelicAccountID             string   `json:"newrelic_account_id"`
+	NewRelicInsertKey             string   `json:"newrelic_insert_key"`
+	NewRelicInsightsURL           string   `json:"newrelic_insights_api_url"`
+	NewRelicServicePrefix         string   `json:"newrelic_service_prefix"`
+	NewRelicBatchSize             int      `json:"newrelic_batch_size"`
+	NewRelicBatchDuration         string   `json:"newrelic_batch_duration"`
+	InfluxDbURL                   string   `json:"influxdb_url"`
+	InfluxDbDatabase              string   `json:"influxdb_database"`
+	InfluxDbUsername              string   `json:"influxdb_username"`
+	InfluxDbPassword              string   `json:"influxdb_password"`
+	InfluxDbBatchSize             int      `json:"influxdb_batch_size"`
+	OPAURL                        string   `json:"opa_url"`
+	OPAPolicyPath                 string   `json:"opa_policy_path"`
+	PolicyCheckActions            []string `json:"policy_check_actions"`
+	AuditLogs                     []string `json:"audit_logs"`
+	SecretsManagerPipelinePath    string   `json:"secretsmanager_pipeline_path"`
+	SecretsManagerTeamPath        string   `json:"secretsmanager_team_path"`
+	SSMPipelinePath               string   `json:"ssm_pipeline_path"`
+	SSMTeamPath                   string   `json:"ssm_team_path"`
+	SSMKMSKeyID                   string   `json:"ssm_kms_key_id"`
+	VaultURL                      string   `json:"vault_url"`
+	VaultCACert                   string   `json:"vault_ca_cert"`
+	VaultAuthBackend              string   `json:"vault_auth_backend"`
+	VaultAuthParams               []string `json:"vault_auth_params"`
+	VaultClientToken              string   `json:"vault_client_token"`
+	VaultPathPrefix               string   `json:"vault_path_prefix"`
+	ConcourseWebSize              string   `json:"concourse_web_size"`
+	ConcourseWebCount             int      `json:"concourse_web_count"`
+	ConcourseWorkerCount          int      `json:"concourse_worker_count"`
+	ConcourseWorkerSize           string   `json:"concourse_worker_size"`
+	UpgradeBatchSize              int      `json:"upgrade_batch_size"`
+	SoakMinutes                   int      `json:"soak_minutes"`
+	SoakMaxErrorRateIncrease      int      `json:"soak_max_error_rate_increase"`
+	ConfigBucket                  string   `json:"config_bucket"`
+	CredhubAdminClientSecret      string   `json:"credhub_admin_client_secret"`
+	CredhubCACert                 string   `json:"credhub_ca_cert"`
+	CredhubPassword               string   `json:"credhub_password"`
+	CredhubURL                    string   `json:"credhub_url"`
+	CredhubUsername               string   `json:"credhub_username"`
+	Deployment                    string   `json:"deployment"`
+	DirectorCACert                string   `json:"director_ca_cert"`
+	DirectorCert                  string   `json:"director_cert"`
+	DirectorHMUserPassword        string   `json:"director_hm_user_password"`
+	DirectorKey                   string   `json:"director_key"`
+	DirectorMbusPassword          string   `json:"director_mbus_password"`
+	DirectorNATSPassword          string   `json:"director_nats_password"`
+	DirectorPassword              string   `json:"director_password"`
+	DirectorPublicIP              string   `json:"director_public_ip"`
+	DirectorRegistryPassword      string   `json:"director_registry_password"`
+	DirectorUsername              string   `json:"director_username"`
+	Domain                        string   `json:"domain"`
+	AdditionalDomains             []string `json:"additional_domains"`
+	EnableGlobalResources         bool     `json:"enable_global_resources"`
+	EnablePipelineInstances       bool     `json:"enable_pipeline_instances"`
+	InfluxDbRetention             string   `json:"influx_db_retention_period"`
+	EncryptionKey                 string   `json:"encryption_key"`
+	GithubClientID                string   `json:"github_client_id"`
+	GithubClientSecret            string   `json:"github_client_secret"`
+	GithubHost                    string   `json:"github_host"`
+	GithubCaCert                  string   `json:"github_ca_cert"`
+	GrafanaPassword               string   `json:"grafana_password"`
+	HostedZoneID                  string   `json:"hosted_zone_id"`
+	HostedZoneRecordPrefix        string   `json:"hosted_zone_record_prefix"`
+	AdditionalRecordPrefixes      []string `json:"additional_hosted_zone_record_prefixes"`
+	DNSProvider                   string   `json:"dns_provider"`
+	DNSZone                       string   `json:"dns_zone"`
+	IAAS                          string   `json:"iaas"`
+	MainGithubUsers               string   `json:"main_github_users"`
+	MainGithubTeams               string   `json:"main_github_teams"`
+	MainGithubOrgs                string   `json:"main_github_orgs"`
+	MicrosoftClientID             string   `json:"microsoft_client_id"`
+	MicrosoftClientSecret         string   `json:"microsoft_client_secret"`
+	MicrosoftTenant               string   `json:"microsoft_tenant"`
+	Namespace                     string   `json:"namespace"`
+	OIDCIssuer                    string   `json:"oidc_issuer"`
+	OIDCClientID                  string   `json:"oidc_client_id"`
+	OIDCClientSecret              string   `json:"oidc_client_secret"`
+	OIDCGroupsClaim               string   `json:"oidc_groups_claim"`
+	OIDCUsernameClaim             string   `json:"oidc_username_claim"`
+	OAuthAuthURL                  string   `json:"oauth_auth_url"`
+	OAuthTokenURL                 string   `json:"oauth_token_url"`
+	OAuthUserinfoURL              string   `json:"oauth_userinfo_url"`
+	OAuthClientID                 string   `json:"oauth_client_id"`
+	OAuthClientSecret             string   `json:"oauth_client_secret"`
+	OAuthScopes                   []string `json:"oauth_scopes"`
+	OAuthGroupsKey                string   `json:"oauth_groups_key"`
+	SAMLMetadataURL               string   `json:"saml_metadata_url"`
+	SAMLCACert                    string   `json:"saml_ca_cert"`
+	SAMLUsernameAttr              string   `json:"saml_username_attr"`
+	SAMLEmailAttr                 string   `json:"saml_email_attr"`
+	SAMLGroupsAttr                string   `json:"saml_groups_attr"`
+	CFAuthAPIURL                  string   `json:"cf_auth_api_url"`
+	CFAuthClientID                string   `json:"cf_auth_client_id"`
+	CFAuthClientSecret            string   `json:"cf_auth_client_secret"`
+	CFAuthCACert                  string   `json:"cf_auth_ca_cert"`
+	MainCFOrgs                    []string `json:"main_cf_orgs"`
+	MainCFSpaces                  []string `json:"main_cf_spaces"`
+	InternalLB                    bool     `json:"internal_lb"`
+	InternalLBAllowIPs            string   `json:"internal_lb_allow_ips"`
+	InternalLBTLSCert             string   `json:"internal_lb_tls_cert"`
+	InternalLBTLSKey              string   `json:"internal_lb_tls_key"`
+	InternalLBHealthCheckPath     string   `json:"internal_lb_health_check_path"`
+	InternalLBHealthCheckInterval int      `json:"internal_lb_health_check_interval"`
+	InternalLBHealthyThreshold    int      `json:"internal_lb_healthy_threshold"`
+	InternalLBUnhealthyThreshold  int      `json:"internal_lb_unhealthy_threshold"`
+	LDAPHost                      string   `json:"ldap_host"`
+	LDAPBindDN                    string   `json:"ldap_bind_dn"`
+	LDAPBindPassword              string   `json:"ldap_bind_password"`
+	LDAPCACert                    string   `json:"ldap_ca_cert"`
+	LDAPUserSearchBaseDN          string   `json:"ldap_user_search_base_dn"`
+	LDAPUserSearchFilter          string   `json:"ldap_user_search_filter"`
+	LDAPUserSearchUsername        string   `json:"ldap_user_search_username"`
+	LDAPGroupSearchBaseDN         string   `json:"ldap_group_search_base_dn"`
+	LDAPGroupSearchFilter         string   `json:"ldap_group_search_filter"`
+	LDAPGroupSearchNameAttr       string   `json:"ldap_group_search_name_attr"`
+	NetworkCIDR                   string   `json:"network_cidr"`
+	NoMetrics                     bool     `json:"no_metrics"`
+	PersistentDisk                string   `json:"persistent_disk"`
+	PrivateCIDR                   string   `json:"private_cidr"`
+	PrivateKey                    string   `json:"private_key"`
+	Project                       string   `json:"project"`
+	PublicCIDR                    string   `json:"public_cidr"`
+	PublicKey                     string   `json:"public_key"`
+	RDS1CIDR                      string   `json:"rds1_cidr"`
+	RDS2CIDR                      string   `json:"rds2_cidr"`
+	RDSDefaultDatabaseName        string   `json:"rds_default_database_name"`
+	RDSInstanceClass              string   `json:"rds_instance_class"`
+	RDSPassword                   string   `json:"rds_password"`
+	RDSUsername                   string   `json:"rds_username"`
+	RDSDiskEncryption             bool     `json:"rds_disk_encryption"`
+	Region                        string   `json:"region"`
+	SourceAccessIP                string   `json:"source_access_ip"`
 	//Spot is deprecated, exists only as we need to migrate old configs to VMProvisioningType
-	Spot               bool     `json:"spot"`
-	Tags               []string `json:"tags"`
-	TFStatePath        string   `json:"tf_state_path"`
-	Version            string   `json:"version"`
-	VMProvisioningType string   `json:"vm_provisioning_type"`
-	WorkerType         string   `json:"worker_type"`
+	Spot                     bool     `json:"spot"`
+	Tags                     []string `json:"tags"`
+	TFStatePath              string   `json:"tf_state_path"`
+	Version                  string   `json:"version"`
+	VMProvisioningType       string   `json:"vm_provisioning_type"`
+	WindowsStemcellVersion   string   `json:"windows_stemcell_version"`
+	WindowsWorkerCount       int      `json:"windows_worker_count"`
+	WindowsWorkerReleaseSHA1 string   `json:"windows_worker_release_sha1"`
+	WindowsWorkerReleaseURL  string   `json:"windows_worker_release_url"`
+	WindowsWorkerType        string   `json:"windows_worker_type"`
+	WorkerAutoscaleMax       int      `json:"worker_autoscale_max"`
+	WorkerAutoscaleMin       int      `json:"worker_autoscale_min"`
+	WorkerCgroupVersion      string   `json:"worker_cgroup_version"`
+	WorkerIMDSHopLimit       int      `json:"worker_imds_hop_limit"`
+	WorkerSpotBid            int      `json:"worker_spot_bid_percentage"`
+	WorkerSysctls            []string `json:"worker_sysctls"`
+	WorkerType               string   `json:"worker_type"`
+
+	// WorkerPools are extra worker instance groups, each with its own size, count and Concourse tags
+	WorkerPools []WorkerPool `json:"worker_pools"`
+
+	// WorkerDNSServers, WorkerDNSProxy, WorkerNetworkPool and WorkerMaxContainers configure the containerd
+	// runtime of every worker, and are left to Concourse's defaults when empty
+	WorkerDNSServers    []string `json:"worker_dns_servers"`
+	WorkerDNSProxy      bool     `json:"worker_dns_proxy"`
+	WorkerNetworkPool   string   `json:"worker_network_pool"`
+	WorkerMaxContainers int      `json:"worker_max_containers"`
+
+	// RestrictWorkerEgress limits the connections workers open to the deployment's network, the blobstore
+	// and WorkerEgressAllow, which is ignored while egress is open
+	RestrictWorkerEgress bool         `json:"restrict_worker_egress"`
+	WorkerEgressAllow    []EgressRule `json:"worker_egress_allow"`
+	// WorkerSchedules set the number of workers at the times they match, in WorkerScheduleTimezone
+	WorkerSchedules        []WorkerSchedule `json:"worker_schedules"`
+	WorkerScheduleTimezone string           `json:"worker_schedule_timezone"`
+	// WorkerDrainTimeout, WorkerRebalanceInterval and WorkerRetireMaxInFlight control how workers are retired
+	WorkerDrainTimeout      string `json:"worker_drain_timeout"`
+	WorkerRebalanceInterval string `json:"worker_rebalance_interval"`
+	WorkerRetireMaxInFlight int    `json:"worker_retire_max_in_flight"`
+	// WebInstanceType and WorkerInstanceType name instance types in place of the ones that
+	// ConcourseWebSize and ConcourseWorkerSize map to
+	WebInstanceType    string `json:"web_instance_type"`
+	WorkerInstanceType string `json:"worker_instance_type"`
+	// WorkerDiskSizeGB and WorkerDiskType override the size and type of the worker disk when set
+	WorkerDiskSizeGB int    `json:"worker_disk_size_gb"`
+	WorkerDiskType   string `json:"worker_disk_type"`
+	// WorkerZones are further zones that workers are spread across, alongside AvailabilityZone
+	WorkerZones []WorkerZone `json:"worker_zones"`
+	// DedicatedWeb and DedicatedWorkers run those VMs on hardware dedicated to the account. On GCP that
+	// is a sole-tenant node group of SoleTenantNodeCount nodes of SoleTenantNodeType
+	DedicatedWeb        bool   `json:"dedicated_web"`
+	DedicatedWorkers    bool   `json:"dedicated_workers"`
+	SoleTenantNodeType  string `json:"sole_tenant_node_type"`
+	SoleTenantNodeCount int    `json:"sole_tenant_node_count"`
+	// ExistingNetwork is the VPC ID on AWS, or the network name on GCP, of a network managed elsewhere that the
+	// deployment is placed in, in ExistingPublicSubnet and ExistingPrivateSubnet. ExistingNATIP is the address
+	// the existing network's NAT gateway reaches the internet from, which the security groups allow
+	ExistingNetwork       string   `json:"existing_network"`
+	ExistingPublicSubnet  string   `json:"existing_public_subnet"`
+	ExistingPrivateSubnet string   `json:"existing_private_subnet"`
+	ExistingDBSubnets     []string `json:"existing_db_subnets"`
+	ExistingNATIP         string   `json:"existing_nat_ip"`
+	// Private deployments have no public addresses. On AWS, their NAT gateway is placed in a subnet of its own,
+	// NATCIDR, so that the public subnet can reach the internet through it
+	Private bool   `json:"private"`
+	NATCIDR string `json:"nat_cidr"`
+	// Bastion is a host in the public subnet that the director is reached through, by SSH with BastionPrivateKey
+	// from control-tower's address and BastionAllowIPs
+	Bastion           bool   `json:"bastion"`
+	BastionAllowIPs   string `json:"bastion_allow_ips"`
+	BastionPrivateKey string `json:"bastion_private_key"`
+	BastionPublicKey  string `json:"bastion_public_key"`
+	// VPCEndpoints gives a private deployment on AWS VPC endpoints for S3, EC2, Secrets Manager and CloudWatch
+	VPCEndpoints bool `json:"vpc_endpoints"`
+	// NATTopology of NAT_PER_ZONE gives each of WorkerZones a NAT gateway in a public subnet of its own
+	NATTopology string `json:"nat_topology"`
+	// IPv6 gives the network on AWS IPv6 ranges and the web load balancer IPv6 addresses, reached from
+	// AllowIPv6s
+	IPv6       bool   `json:"ipv6"`
+	AllowIPv6s string `json:"allow_ipv6_ips"`
+	// PeerVPCIDs on AWS and PeerNetworks on GCP are peered with the deployment's network
+	PeerVPCIDs   []string `json:"peer_vpc_ids"`
+	PeerNetworks []string `json:"peer_networks"`
+	// TransitGatewayID is a transit gateway on AWS that the VPC is attached to, with routes to
+	// TransitGatewayRoutes through it
+	TransitGatewayID            string   `json:"transit_gateway_id"`
+	TransitGatewayRoutes        []string `json:"transit_gateway_routes"`
+	TransitGatewayRouteTableID  string   `json:"transit_gateway_route_table_id"`
+	NoTransitGatewayPropagation bool     `json:"no_transit_gateway_propagation"`
+	// NCCHub is a Network Connectivity Center hub on GCP that the network is a VPC spoke of, which doesn't
+	// export the routes to subnets in NCCExcludeExportRanges
+	NCCHub                 string   `json:"ncc_hub"`
+	NCCExcludeExportRanges []string `json:"ncc_exclude_export_ranges"`
+	// GoogleBetaProvider is set once the network has been a spoke, and kept so that terraform can remove the
+	// spoke with the provider that made it
+	GoogleBetaProvider bool `json:"google_beta_provider"`
+	// WebAllowIPs, DirectorAllowIPs, SSHAllowIPs and MetricsAllowIPs are the allow-lists of each component,
+	// which fall back to AllowIPs or each other when empty
+	WebAllowIPs      string `json:"web_allow_ips"`
+	DirectorAllowIPs string `json:"director_allow_ips"`
+	SSHAllowIPs      string `json:"ssh_allow_ips"`
+	MetricsAllowIPs  string `json:"metrics_allow_ips"`
+	// WebEIP on AWS and WebAddressName on GCP are existing addresses of the web endpoint, which are only read
+	// by terraform, and so outlive the deployment
+	WebEIP         string `json:"web_eip"`
+	WebAddressName string `json:"web_address_name"`
+	// ExternalDBURL is an existing Postgres server used instead of RDS or CloudSQL. Its credentials and
+	// database are also kept in the RDS fields, which the director and Concourse connect with
+	ExternalDBURL    string `json:"external_db_url"`
+	ExternalDBCACert string `json:"external_db_ca_cert"`
+	// DBEngine of DB_ENGINE_AURORA_SERVERLESS scales the cluster between DBMinACU and DBMaxACU Aurora
+	// capacity units in place of an RDSInstanceClass
+	DBEngine string  `json:"db_engine"`
+	DBMinACU float64 `json:"db_min_acu"`
+	DBMaxACU float64 `json:"db_max_acu"`
+	// DBHA makes the CloudSQL instance regional rather than zonal
+	DBHA bool `json:"db_ha"`
+	// DBMultiAZ runs the RDS instance with a synchronous standby in a second zone
+	DBMultiAZ bool `json:"db_multi_az"`
+	// DBReadReplica adds a read replica of the database for analytics
+	DBReadReplica bool `json:"db_read_replica"`
+	// DBKMSKey is the customer managed key the database is encrypted with, which can't be changed
+	DBKMSKey string `json:"db_kms_key"`
+	// DBIAMAuth connects Concourse, UAA and CredHub to RDS with IAM authentication tokens, through a proxy on
+	// the web VMs that they log into with DBProxyPassword over TLS with DBProxyCert, signed by DBProxyCACert
+	DBIAMAuth       bool   `json:"db_iam_auth"`
+	DBProxyPassword string `json:"db_proxy_password"`
+	DBProxyCACert   string `json:"db_proxy_ca_cert"`
+	DBProxyCert     string `json:"db_proxy_cert"`
+	DBProxyKey      string `json:"db_proxy_key"`
+	// DBBlueGreenUpdate resizes the RDS instance with a Blue/Green deployment. It isn't stored, so that it only
+	// applies to the deploy that resizes the instance
+	DBBlueGreenUpdate bool `json:"-"`
+	// DBResizedFrom is the class the RDS instance is at until the Blue/Green deployment switches over
+	DBResizedFrom string `json:"-"`
+	// DBLogicalReplication sets rds.logical_replication in the RDS instance's parameter group, which a Blue/Green
+	// deployment replicates with. It is kept once set, as unsetting it would restart the instance again
+	DBLogicalReplication bool `json:"db_logical_replication"`
+	// DBVersion is the Postgres major version of the database, or DEFAULT_AWS_DB_VERSION or
+	// DEFAULT_GCP_DB_VERSION when empty
+	DBVersion string `json:"db_version"`
+	// DBStorage is the GB of storage of the RDS instance, where 0 leaves it at the size it was created with.
+	// RDS grows it up to DBMaxStorage GB by itself when that is set
+	DBStorage     int    `json:"db_storage"`
+	DBMaxStorage  int    `json:"db_max_storage"`
+	DBStorageType string `json:"db_storage_type"`
+	DBIOPS        int    `json:"db_iops"`
+	// DBBackupRetention and DBBackupWindow leave the IAAS's defaults in place while they are empty
+	DBBackupRetention    int    `json:"db_backup_retention"`
+	DBBackupWindow       string `json:"db_backup_window"`
+	DBDeletionProtection bool   `json:"db_deletion_protection"`
+	// DBParams are Postgres parameters for the database in the format name=value
+	DBParams []string `json:"db_params"`
+	// DBRestore is set once restore-db has replaced the database instance, and DBArchivedInstances are
+	// the instances it replaced, oldest first, which are kept until the deployment is destroyed
+	DBRestore           DBRestore `json:"db_restore"`
+	DBArchivedInstances []string  `json:"db_archived_instances"`
+	// DirectorDBInstance is the instance among DBArchivedInstances that the director on AWS keeps its database
+	// on, as restore-db only rolls back the databases of Concourse, UAA and CredHub
+	DirectorDBInstance string `json:"director_db_instance"`
+	// DBCACert is the bundle of the database's CAs that was last fetched from the IAAS, which the director
+	// and Concourse trust. DBCACertIdentifier is the RDS CA that maintain --rotate-db-ca last rotated onto
+	DBCACert           string `json:"db_ca_cert"`
+	DBCACertIdentifier string `json:"db_ca_cert_identifier"`
+	// QuotaPolicyURL is the policy service, or QuotaPolicy the policy read from a file, that every later
+	// deploy is checked against once a --quota-policy has been given
+	QuotaPolicyURL string        `json:"quota_policy_url"`
+	QuotaPolicy    *quota.Policy `json:"quota_policy,omitempty"`
 }
 
 type ConfigView interface {
 	GetAllowIPs() string
 	GetAllowIPsUnformatted() string
+	GetARM64ReleaseURL() string
+	GetARM64ReleaseSHA1() string
+	GetARM64StemcellURL() string
 	GetAvailabilityZone() string
 	GetBitbucketClientID() string
 	GetBitbucketClientSecret() string
@@ -100,9 +392,98 @@ type ConfigView interface {
 	GetConcourseKey() string
 	GetConcoursePassword() string
 	GetConcourseUsername() string
+	GetConcourseVars() string
+	GetTeamsSpec() string
+	GetCredentialManager() string
+	GetManagedPrometheusURL() string
+	GetManagedPrometheusRegion() string
+	GetEnablePrometheusMetrics() bool
+	GetMetricsScrapeAllowIPs() []string
+	GetMetricsScrapePassword() string
+	GetExternalWorkerAllowIPs() []string
+	GetExternalDBCACert() string
+	GetDBEngine() string
+	GetDBHA() bool
+	GetDBMultiAZ() bool
+	GetDBReadReplica() bool
+	GetDBKMSKey() string
+	GetDBIAMAuth() bool
+	GetDBProxyPassword() string
+	GetDBProxyCACert() string
+	GetDBProxyCert() string
+	GetDBProxyKey() string
+	GetDBBlueGreenUpdate() bool
+	GetDBLogicalReplication() bool
+	GetDBVersion() string
+	GetDBStorage() int
+	GetDBMaxStorage() int
+	GetDBStorageType() string
+	GetDBIOPS() int
+	GetDBBackupRetention() int
+	GetDBBackupWindow() string
+	GetDBDeletionProtection() bool
+	GetDBParams() []string
+	GetDBRestore() DBRestore
+	GetDBArchivedInstances() []string
+	GetDirectorDBInstance() string
+	GetDBCACert() string
+	GetDBCACertIdentifier() string
+	GetDBMaxACU() float64
+	GetDBMinACU() float64
+	GetExternalDBURL() string
+	GetSIEMEndpoint() string
+	GetConcourseVersion() string
+	GetConcourseReleaseSHA1() string
+	GetOSConfReleaseSHA1() string
+	GetContainerPlacementStrategies() []string
+	GetMaxActiveTasksPerWorker() int
+	GetMaxActiveContainersPerWorker() int
+	GetMaxActiveVolumesPerWorker() int
+	GetDefaultTaskCPULimit() int
+	GetDefaultTaskMemoryLimit() string
+	GetInterceptIdleTimeout() string
+	GetFailedGracePeriod() string
+	GetEnableP2PVolumeStreaming() bool
+	GetStreamingCompression() string
+	GetConcourseWebEnv() []string
+	GetConcourseWorkerEnv() []string
+	GetExternalURL() string
+	GetSyslogAddress() string
+	GetSyslogTransport() string
+	GetSyslogCACert() string
+	GetNewRelicAccountID() string
+	GetNewRelicInsertKey() string
+	GetNewRelicInsightsURL() string
+	GetNewRelicServicePrefix() string
+	GetNewRelicBatchSize() int
+	GetNewRelicBatchDuration() string
+	GetInfluxDbURL() string
+	GetInfluxDbDatabase() string
+	GetInfluxDbUsername() string
+	GetInfluxDbPassword() string
+	GetInfluxDbBatchSize() int
+	GetOPAURL() string
+	GetOPAPolicyPath() string
+	GetPolicyCheckActions() []string
+	GetAuditLogs() []string
+	GetSecretsManagerPipelinePath() string
+	GetSecretsManagerTeamPath() string
+	GetSSMPipelinePath() string
+	GetSSMTeamPath() string
+	GetSSMKMSKeyID() string
+	GetVaultURL() string
+	GetVaultCACert() string
+	GetVaultAuthBackend() string
+	GetVaultAuthParams() []string
+	GetVaultClientToken() string
+	GetVaultPathPrefix() string
 	GetConcourseWebSize() string
+	GetConcourseWebCount() int
 	GetConcourseWorkerCount() int
 	GetConcourseWorkerSize() string
+	GetUpgradeBatchSize() int
+	GetSoakMinutes() int
+	GetSoakMaxErrorRateIncrease() int
 	GetConfigBucket() string
 	GetCredhubAdminClientSecret() string
 	GetCredhubCACert() string
@@ -120,7 +501,10 @@ type ConfigView interface {
 	GetDirectorPublicIP() string
 	GetDirectorRegistryPassword() string
 	GetDirectorUsername() string
+	GetDedicatedWeb() bool
+	GetDedicatedWorkers() bool
 	GetDomain() string
+	GetAdditionalDomains() []string
 	GetEnableGlobalResources() bool
 	GetEnablePipelineInstances() bool
 	GetInfluxDbRetention() string
@@ -132,6 +516,9 @@ type ConfigView interface {
 	GetGrafanaPassword() string
 	GetHostedZoneID() string
 	GetHostedZoneRecordPrefix() string
+	GetAdditionalRecordPrefixes() []string
+	GetDNSProvider() string
+	GetDNSZone() string
 	GetIAAS() string
 	GetMainGithubUsers() string
 	GetMainGithubTeams() string
@@ -139,6 +526,47 @@ type ConfigView interface {
 	GetMicrosoftClientID() string
 	GetMicrosoftClientSecret() string
 	GetMicrosoftTenant() string
+	GetOIDCIssuer() string
+	GetOIDCClientID() string
+	GetOIDCClientSecret() string
+	GetOIDCGroupsClaim() string
+	GetOIDCUsernameClaim() string
+	GetOAuthAuthURL() string
+	GetOAuthTokenURL() string
+	GetOAuthUserinfoURL() string
+	GetOAuthClientID() string
+	GetOAuthClientSecret() string
+	GetOAuthScopes() []string
+	GetOAuthGroupsKey() string
+	GetSAMLMetadataURL() string
+	GetSAMLCACert() string
+	GetSAMLUsernameAttr() string
+	GetSAMLEmailAttr() string
+	GetSAMLGroupsAttr() string
+	GetCFAuthAPIURL() string
+	GetCFAuthClientID() string
+	GetCFAuthClientSecret() string
+	GetCFAuthCACert() string
+	GetMainCFOrgs() []string
+	GetMainCFSpaces() []string
+	GetInternalLB() bool
+	GetInternalLBAllowIPs() string
+	GetInternalLBTLSCert() string
+	GetInternalLBTLSKey() string
+	GetInternalLBHealthCheckPath() string
+	GetInternalLBHealthCheckInterval() int
+	GetInternalLBHealthyThreshold() int
+	GetInternalLBUnhealthyThreshold() int
+	GetLDAPHost() string
+	GetLDAPBindDN() string
+	GetLDAPBindPassword() string
+	GetLDAPCACert() string
+	GetLDAPUserSearchBaseDN() string
+	GetLDAPUserSearchFilter() string
+	GetLDAPUserSearchUsername() string
+	GetLDAPGroupSearchBaseDN() string
+	GetLDAPGroupSearchFilter() string
+	GetLDAPGroupSearchNameAttr() string
 	GetNamespace() string
 	GetNetworkCIDR() string
 	GetPersistentDiskSize() string
@@ -155,16 +583,86 @@ type ConfigView interface {
 	GetRDSUsername() string
 	GetRDSDiskEncryption() bool
 	GetRegion() string
+	GetRestrictWorkerEgress() bool
+	GetSoleTenantNodeCount() int
+	GetSoleTenantNodeType() string
 	GetSourceAccessIP() string
 	GetTags() []string
 	GetTFStatePath() string
 	GetVersion() string
+	GetWebInstanceType() string
+	GetWindowsStemcellVersion() string
+	GetWindowsWorkerCount() int
+	GetWindowsWorkerReleaseSHA1() string
+	GetWindowsWorkerReleaseURL() string
+	GetWindowsWorkerType() string
+	GetWorkerAutoscaleMax() int
+	GetWorkerAutoscaleMin() int
+	GetWorkerSchedules() []WorkerSchedule
+	GetWorkerScheduleTimezone() string
+	GetWorkerCgroupVersion() string
+	GetWorkerDiskSizeGB() int
+	GetWorkerDiskType() string
+	GetWorkerDNSProxy() bool
+	GetWorkerDNSServers() []string
+	GetWorkerDrainTimeout() string
+	GetWorkerEgressAllow() []EgressRule
+	GetWorkerIMDSHopLimit() int
+	GetWorkerInstanceType() string
+	GetWorkerMaxContainers() int
+	GetWorkerNetworkPool() string
+	GetWorkerPools() []WorkerPool
+	GetWorkerRebalanceInterval() string
+	GetWorkerRetireMaxInFlight() int
+	GetWorkerSpotBid() int
+	GetWorkerZones() []WorkerZone
+	GetExistingNetwork() string
+	GetExistingPublicSubnet() string
+	GetExistingPrivateSubnet() string
+	GetExistingDBSubnets() []string
+	GetExistingNATIP() string
+	GetPrivate() bool
+	GetNATCIDR() string
+	GetBastion() bool
+	GetBastionAllowIPs() string
+	GetBastionPrivateKey() string
+	GetBastionPublicKey() string
+	GetVPCEndpoints() bool
+	GetNATTopology() string
+	GetIPv6() bool
+	GetAllowIPv6s() string
+	GetPeerVPCIDs() []string
+	GetPeerNetworks() []string
+	GetTransitGatewayID() string
+	GetTransitGatewayRoutes() []string
+	GetTransitGatewayRouteTableID() string
+	GetNoTransitGatewayPropagation() bool
+	GetNCCHub() string
+	GetNCCExcludeExportRanges() []string
+	GetGoogleBetaProvider() bool
+	GetWebAllowIPs() string
+	GetDirectorAllowIPs() string
+	GetSSHAllowIPs() string
+	GetMetricsAllowIPs() string
+	GetWebEIP() string
+	GetWebAddressName() string
+	GetWorkerSysctls() []string
 	GetWorkerType() string
 	IsBitbucketAuthSet() bool
 	IsGithubAuthSet() bool
 	IsGithubEnterpriseAuthSet() bool
 	IsMainGithubAuthSet() bool
 	IsMicrosoftAuthSet() bool
+	IsOIDCAuthSet() bool
+	IsOAuthAuthSet() bool
+	IsSAMLAuthSet() bool
+	IsLDAPAuthSet() bool
+	IsCFAuthSet() bool
+	IsVaultSet() bool
+	IsSecretsManagerSet() bool
+	IsSSMSet() bool
+	IsManagedPrometheusSet() bool
+	IsMainCFAuthSet() bool
 	IsSpot() bool
 	MetricsIsDisabled() bool
 }
@@ -177,6 +675,18 @@ func (c Config) GetAllowIPsUnformatted() string {
 	return c.AllowIPsUnformatted
 }
 
+func (c Config) GetARM64ReleaseURL() string {
+	return c.ARM64ReleaseURL
+}
+
+func (c Config) GetARM64ReleaseSHA1() string {
+	return c.ARM64ReleaseSHA1
+}
+
+func (c Config) GetARM64StemcellURL() string {
+	return c.ARM64StemcellURL
+}
+
 func (c Config) GetAvailabilityZone() string {
 	return c.AvailabilityZone
 }
@@ -209,10 +719,234 @@ func (c Config) GetConcourseUsername() string {
 	return c.ConcourseUsername
 }
 
+func (c Config) GetConcourseVars() string {
+	return c.ConcourseVars
+}
+
+func (c Config) GetTeamsSpec() string {
+	return c.TeamsSpec
+}
+
+func (c Config) GetCredentialManager() string {
+	return c.CredentialManager
+}
+
+func (c Config) GetManagedPrometheusURL() string {
+	return c.ManagedPrometheusURL
+}
+
+func (c Config) GetManagedPrometheusRegion() string {
+	return c.ManagedPrometheusRegion
+}
+
+func (c Config) GetEnablePrometheusMetrics() bool {
+	return c.EnablePrometheusMetrics
+}
+
+func (c Config) GetMetricsScrapeAllowIPs() []string {
+	return c.MetricsScrapeAllowIPs
+}
+
+func (c Config) GetMetricsScrapePassword() string {
+	return c.MetricsScrapePassword
+}
+
+func (c Config) GetExternalWorkerAllowIPs() []string {
+	return c.ExternalWorkerAllowIPs
+}
+
+func (c Config) GetSIEMEndpoint() string {
+	return c.SIEMEndpoint
+}
+
+func (c Config) GetConcourseVersion() string {
+	return c.ConcourseVersion
+}
+
+func (c Config) GetConcourseReleaseSHA1() string {
+	return c.ConcourseReleaseSHA1
+}
+
+func (c Config) GetOSConfReleaseSHA1() string {
+	return c.OSConfReleaseSHA1
+}
+
+func (c Config) GetContainerPlacementStrategies() []string {
+	return c.ContainerPlacementStrategies
+}
+
+func (c Config) GetMaxActiveTasksPerWorker() int {
+	return c.MaxActiveTasksPerWorker
+}
+
+func (c Config) GetMaxActiveContainersPerWorker() int {
+	return c.MaxActiveContainersPerWorker
+}
+
+func (c Config) GetMaxActiveVolumesPerWorker() int {
+	return c.MaxActiveVolumesPerWorker
+}
+
+func (c Config) GetDefaultTaskCPULimit() int {
+	return c.DefaultTaskCPULimit
+}
+
+func (c Config) GetDefaultTaskMemoryLimit() string {
+	return c.DefaultTaskMemoryLimit
+}
+
+func (c Config) GetInterceptIdleTimeout() string {
+	return c.InterceptIdleTimeout
+}
+
+func (c Config) GetFailedGracePeriod() string {
+	return c.FailedGracePeriod
+}
+
+func (c Config) GetEnableP2PVolumeStreaming() bool {
+	return c.EnableP2PVolumeStreaming
+}
+
+func (c Config) GetStreamingCompression() string {
+	return c.StreamingCompression
+}
+
+func (c Config) GetConcourseWebEnv() []string {
+	return c.ConcourseWebEnv
+}
+
+func (c Config) GetConcourseWorkerEnv() []string {
+	return c.ConcourseWorkerEnv
+}
+
+func (c Config) GetExternalURL() string {
+	return c.ExternalURL
+}
+
+func (c Config) GetSyslogAddress() string {
+	return c.SyslogAddress
+}
+
+func (c Config) GetSyslogTransport() string {
+	return c.SyslogTransport
+}
+
+func (c Config) GetSyslogCACert() string {
+	return c.SyslogCACert
+}
+
+func (c Config) GetNewRelicAccountID() string {
+	return c.NewRelicAccountID
+}
+
+func (c Config) GetNewRelicInsertKey() string {
+	return c.NewRelicInsertKey
+}
+
+func (c Config) GetNewRelicInsightsURL() string {
+	return c.NewRelicInsightsURL
+}
+
+func (c Config) GetNewRelicServicePrefix() string {
+	return c.NewRelicServicePrefix
+}
+
+func (c Config) GetNewRelicBatchSize() int {
+	return c.NewRelicBatchSize
+}
+
+func (c Config) GetNewRelicBatchDuration() string {
+	return c.NewRelicBatchDuration
+}
+
+func (c Config) GetInfluxDbURL() string {
+	return c.InfluxDbURL
+}
+
+func (c Config) GetInfluxDbDatabase() string {
+	return c.InfluxDbDatabase
+}
+
+func (c Config) GetInfluxDbUsername() string {
+	return c.InfluxDbUsername
+}
+
+func (c Config) GetInfluxDbPassword() string {
+	return c.InfluxDbPassword
+}
+
+func (c Config) GetInfluxDbBatchSize() int {
+	return c.InfluxDbBatchSize
+}
+
+func (c Config) GetOPAURL() string {
+	return c.OPAURL
+}
+
+func (c Config) GetOPAPolicyPath() string {
+	return c.OPAPolicyPath
+}
+
+func (c Config) GetPolicyCheckActions() []string {
+	return c.PolicyCheckActions
+}
+
+func (c Config) GetAuditLogs() []string {
+	return c.AuditLogs
+}
+
+func (c Config) GetSecretsManagerPipelinePath() string {
+	return c.SecretsManagerPipelinePath
+}
+
+func (c Config) GetSecretsManagerTeamPath() string {
+	return c.SecretsManagerTeamPath
+}
+
+func (c Config) GetSSMPipelinePath() string {
+	return c.SSMPipelinePath
+}
+
+func (c Config) GetSSMTeamPath() string {
+	return c.SSMTeamPath
+}
+
+func (c Config) GetSSMKMSKeyID() string {
+	return c.SSMKMSKeyID
+}
+
+func (c Config) GetVaultURL() string {
+	return c.VaultURL
+}
+
+func (c Config) GetVaultCACert() string {
+	return c.VaultCACert
+}
+
+func (c Config) GetVaultAuthBackend() string {
+	return c.VaultAuthBackend
+}
+
+func (c Config) GetVaultAuthParams() []string {
+	return c.VaultAuthParams
+}
+
+func (c Config) GetVaultClientToken() string {
+	return c.VaultClientToken
+}
+
+func (c Config) GetVaultPathPrefix() string {
+	return c.VaultPathPrefix
+}
+
 func (c Config) GetConcourseWebSize() string {
 	return c.ConcourseWebSize
 }
 
+func (c Config) GetConcourseWebCount() int {
+	return c.ConcourseWebCount
+}
+
 func (c Config) GetPersistentDiskSize() string {
 	return c.PersistentDisk
 }
@@ -225,6 +959,18 @@ func (c Config) GetConcourseWorkerSize() string {
 	return c.ConcourseWorkerSize
 }
 
+func (c Config) GetUpgradeBatchSize() int {
+	return c.UpgradeBatchSize
+}
+
+func (c Config) GetSoakMinutes() int {
+	return c.SoakMinutes
+}
+
+func (c Config) GetSoakMaxErrorRateIncrease() int {
+	return c.SoakMaxErrorRateIncrease
+}
+
 func (c Config) GetConfigBucket() string {
 	return c.ConfigBucket
 }
@@ -297,6 +1043,10 @@ func (c Config) GetDomain() string {
 	return c.Domain
 }
 
+func (c Config) GetAdditionalDomains() []string {
+	return c.AdditionalDomains
+}
+
 func (c Config) GetEnableGlobalResources() bool {
 	return c.EnableGlobalResources
 }
@@ -341,6 +1091,18 @@ func (c Config) GetHostedZoneRecordPrefix() string {
 	return c.HostedZoneRecordPrefix
 }
 
+func (c Config) GetAdditionalRecordPrefixes() []string {
+	return c.AdditionalRecordPrefixes
+}
+
+func (c Config) GetDNSProvider() string {
+	return c.DNSProvider
+}
+
+func (c Config) GetDNSZone() string {
+	return c.DNSZone
+}
+
 func (c Config) GetIAAS() string {
 	return c.IAAS
 }
@@ -369,6 +1131,170 @@ func (c Config) GetMicrosoftTenant() string {
 	return c.MicrosoftTenant
 }
 
+func (c Config) GetOIDCIssuer() string {
+	return c.OIDCIssuer
+}
+
+func (c Config) GetOIDCClientID() string {
+	return c.OIDCClientID
+}
+
+func (c Config) GetOIDCClientSecret() string {
+	return c.OIDCClientSecret
+}
+
+func (c Config) GetOIDCGroupsClaim() string {
+	return c.OIDCGroupsClaim
+}
+
+func (c Config) GetOIDCUsernameClaim() string {
+	return c.OIDCUsernameClaim
+}
+
+func (c Config) GetOAuthAuthURL() string {
+	return c.OAuthAuthURL
+}
+
+func (c Config) GetOAuthTokenURL() string {
+	return c.OAuthTokenURL
+}
+
+func (c Config) GetOAuthUserinfoURL() string {
+	return c.OAuthUserinfoURL
+}
+
+func (c Config) GetOAuthClientID() string {
+	return c.OAuthClientID
+}
+
+func (c Config) GetOAuthClientSecret() string {
+	return c.OAuthClientSecret
+}
+
+func (c Config) GetOAuthScopes() []string {
+	return c.OAuthScopes
+}
+
+func (c Config) GetOAuthGroupsKey() string {
+	return c.OAuthGroupsKey
+}
+
+func (c Config) GetSAMLMetadataURL() string {
+	return c.SAMLMetadataURL
+}
+
+func (c Config) GetSAMLCACert() string {
+	return c.SAMLCACert
+}
+
+func (c Config) GetSAMLUsernameAttr() string {
+	return c.SAMLUsernameAttr
+}
+
+func (c Config) GetSAMLEmailAttr() string {
+	return c.SAMLEmailAttr
+}
+
+func (c Config) GetSAMLGroupsAttr() string {
+	return c.SAMLGroupsAttr
+}
+
+func (c Config) GetCFAuthAPIURL() string {
+	return c.CFAuthAPIURL
+}
+
+func (c Config) GetCFAuthClientID() string {
+	return c.CFAuthClientID
+}
+
+func (c Config) GetCFAuthClientSecret() string {
+	return c.CFAuthClientSecret
+}
+
+func (c Config) GetCFAuthCACert() string {
+	return c.CFAuthCACert
+}
+
+func (c Config) GetMainCFOrgs() []string {
+	return c.MainCFOrgs
+}
+
+func (c Config) GetMainCFSpaces() []string {
+	return c.MainCFSpaces
+}
+
+func (c Config) GetInternalLB() bool {
+	return c.InternalLB
+}
+
+func (c Config) GetInternalLBAllowIPs() string {
+	return c.InternalLBAllowIPs
+}
+
+func (c Config) GetInternalLBTLSCert() string {
+	return c.InternalLBTLSCert
+}
+
+func (c Config) GetInternalLBTLSKey() string {
+	return c.InternalLBTLSKey
+}
+
+func (c Config) GetInternalLBHealthCheckPath() string {
+	return c.InternalLBHealthCheckPath
+}
+
+func (c Config) GetInternalLBHealthCheckInterval() int {
+	return c.InternalLBHealthCheckInterval
+}
+
+func (c Config) GetInternalLBHealthyThreshold() int {
+	return c.InternalLBHealthyThreshold
+}
+
+func (c Config) GetInternalLBUnhealthyThreshold() int {
+	return c.InternalLBUnhealthyThreshold
+}
+
+func (c Config) GetLDAPHost() string {
+	return c.LDAPHost
+}
+
+func (c Config) GetLDAPBindDN() string {
+	return c.LDAPBindDN
+}
+
+func (c Config) GetLDAPBindPassword() string {
+	return c.LDAPBindPassword
+}
+
+func (c Config) GetLDAPCACert() string {
+	return c.LDAPCACert
+}
+
+func (c Config) GetLDAPUserSearchBaseDN() string {
+	return c.LDAPUserSearchBaseDN
+}
+
+func (c Config) GetLDAPUserSearchFilter() string {
+	return c.LDAPUserSearchFilter
+}
+
+func (c Config) GetLDAPUserSearchUsername() string {
+	return c.LDAPUserSearchUsername
+}
+
+func (c Config) GetLDAPGroupSearchBaseDN() string {
+	return c.LDAPGroupSearchBaseDN
+}
+
+func (c Config) GetLDAPGroupSearchFilter() string {
+	return c.LDAPGroupSearchFilter
+}
+
+func (c Config) GetLDAPGroupSearchNameAttr() string {
+	return c.LDAPGroupSearchNameAttr
+}
+
 func (c Config) GetNamespace() string {
 	return c.Namespace
 }
@@ -429,6 +1355,10 @@ func (c Config) GetRegion() string {
 	return c.Region
 }
 
+func (c Config) GetRestrictWorkerEgress() bool {
+	return c.RestrictWorkerEgress
+}
+
 func (c Config) GetSourceAccessIP() string {
 	return c.SourceAccessIP
 }
@@ -445,6 +1375,370 @@ func (c Config) GetVersion() string {
 	return c.Version
 }
 
+func (c Config) GetWebInstanceType() string {
+	return c.WebInstanceType
+}
+
+func (c Config) GetWindowsStemcellVersion() string {
+	return c.WindowsStemcellVersion
+}
+
+func (c Config) GetWindowsWorkerCount() int {
+	return c.WindowsWorkerCount
+}
+
+func (c Config) GetWindowsWorkerReleaseSHA1() string {
+	return c.WindowsWorkerReleaseSHA1
+}
+
+func (c Config) GetWindowsWorkerReleaseURL() string {
+	return c.WindowsWorkerReleaseURL
+}
+
+func (c Config) GetWindowsWorkerType() string {
+	return c.WindowsWorkerType
+}
+
+func (c Config) GetWorkerAutoscaleMax() int {
+	return c.WorkerAutoscaleMax
+}
+
+func (c Config) GetWorkerAutoscaleMin() int {
+	return c.WorkerAutoscaleMin
+}
+
+func (c Config) GetWorkerSchedules() []WorkerSchedule {
+	return c.WorkerSchedules
+}
+
+func (c Config) GetWorkerScheduleTimezone() string {
+	return c.WorkerScheduleTimezone
+}
+
+func (c Config) GetWorkerCgroupVersion() string {
+	return c.WorkerCgroupVersion
+}
+
+func (c Config) GetWorkerDiskSizeGB() int {
+	return c.WorkerDiskSizeGB
+}
+
+func (c Config) GetWorkerDiskType() string {
+	return c.WorkerDiskType
+}
+
+func (c Config) GetWorkerDNSProxy() bool {
+	return c.WorkerDNSProxy
+}
+
+func (c Config) GetWorkerDNSServers() []string {
+	return c.WorkerDNSServers
+}
+
+func (c Config) GetWorkerDrainTimeout() string {
+	return c.WorkerDrainTimeout
+}
+
+func (c Config) GetWorkerEgressAllow() []EgressRule {
+	return c.WorkerEgressAllow
+}
+
+func (c Config) GetWorkerZones() []WorkerZone {
+	return c.WorkerZones
+}
+
+func (c Config) GetExistingNetwork() string {
+	return c.ExistingNetwork
+}
+
+func (c Config) GetExistingPublicSubnet() string {
+	return c.ExistingPublicSubnet
+}
+
+func (c Config) GetExistingPrivateSubnet() string {
+	return c.ExistingPrivateSubnet
+}
+
+func (c Config) GetExistingDBSubnets() []string {
+	return c.ExistingDBSubnets
+}
+
+func (c Config) GetExistingNATIP() string {
+	return c.ExistingNATIP
+}
+
+func (c Config) GetPrivate() bool {
+	return c.Private
+}
+
+func (c Config) GetNATCIDR() string {
+	return c.NATCIDR
+}
+
+func (c Config) GetBastion() bool {
+	return c.Bastion
+}
+
+func (c Config) GetBastionAllowIPs() string {
+	return c.BastionAllowIPs
+}
+
+func (c Config) GetBastionPrivateKey() string {
+	return c.BastionPrivateKey
+}
+
+func (c Config) GetBastionPublicKey() string {
+	return c.BastionPublicKey
+}
+
+func (c Config) GetVPCEndpoints() bool {
+	return c.VPCEndpoints
+}
+
+func (c Config) GetNATTopology() string {
+	return c.NATTopology
+}
+
+func (c Config) GetIPv6() bool {
+	return c.IPv6
+}
+
+func (c Config) GetAllowIPv6s() string {
+	return c.AllowIPv6s
+}
+
+func (c Config) GetPeerVPCIDs() []string {
+	return c.PeerVPCIDs
+}
+
+func (c Config) GetPeerNetworks() []string {
+	return c.PeerNetworks
+}
+
+func (c Config) GetTransitGatewayID() string {
+	return c.TransitGatewayID
+}
+
+func (c Config) GetTransitGatewayRoutes() []string {
+	return c.TransitGatewayRoutes
+}
+
+func (c Config) GetTransitGatewayRouteTableID() string {
+	return c.TransitGatewayRouteTableID
+}
+
+func (c Config) GetNoTransitGatewayPropagation() bool {
+	return c.NoTransitGatewayPropagation
+}
+
+func (c Config) GetNCCHub() string {
+	return c.NCCHub
+}
+
+func (c Config) GetNCCExcludeExportRanges() []string {
+	return c.NCCExcludeExportRanges
+}
+
+func (c Config) GetGoogleBetaProvider() bool {
+	return c.GoogleBetaProvider
+}
+
+func (c Config) GetWebAllowIPs() string {
+	return c.WebAllowIPs
+}
+
+func (c Config) GetDirectorAllowIPs() string {
+	return c.DirectorAllowIPs
+}
+
+func (c Config) GetSSHAllowIPs() string {
+	return c.SSHAllowIPs
+}
+
+func (c Config) GetMetricsAllowIPs() string {
+	return c.MetricsAllowIPs
+}
+
+func (c Config) GetWebEIP() string {
+	return c.WebEIP
+}
+
+func (c Config) GetWebAddressName() string {
+	return c.WebAddressName
+}
+
+func (c Config) GetDedicatedWeb() bool {
+	return c.DedicatedWeb
+}
+
+func (c Config) GetDedicatedWorkers() bool {
+	return c.DedicatedWorkers
+}
+
+func (c Config) GetSoleTenantNodeType() string {
+	return c.SoleTenantNodeType
+}
+
+func (c Config) GetSoleTenantNodeCount() int {
+	return c.SoleTenantNodeCount
+}
+
+func (c Config) GetExternalDBURL() string {
+	return c.ExternalDBURL
+}
+
+func (c Config) GetExternalDBCACert() string {
+	return c.ExternalDBCACert
+}
+
+func (c Config) GetDBEngine() string {
+	return c.DBEngine
+}
+
+func (c Config) GetDBMinACU() float64 {
+	return c.DBMinACU
+}
+
+func (c Config) GetDBMaxACU() float64 {
+	return c.DBMaxACU
+}
+
+func (c Config) GetDBHA() bool {
+	return c.DBHA
+}
+
+func (c Config) GetDBMultiAZ() bool {
+	return c.DBMultiAZ
+}
+
+func (c Config) GetDBReadReplica() bool {
+	return c.DBReadReplica
+}
+
+func (c Config) GetDBKMSKey() string {
+	return c.DBKMSKey
+}
+
+func (c Config) GetDBIAMAuth() bool {
+	return c.DBIAMAuth
+}
+
+func (c Config) GetDBProxyPassword() string {
+	return c.DBProxyPassword
+}
+
+func (c Config) GetDBProxyCACert() string {
+	return c.DBProxyCACert
+}
+
+func (c Config) GetDBProxyCert() string {
+	return c.DBProxyCert
+}
+
+func (c Config) GetDBProxyKey() string {
+	return c.DBProxyKey
+}
+
+func (c Config) GetDBBlueGreenUpdate() bool {
+	return c.DBBlueGreenUpdate
+}
+
+func (c Config) GetDBLogicalReplication() bool {
+	return c.DBLogicalReplication
+}
+
+func (c Config) GetDBVersion() string {
+	return c.DBVersion
+}
+
+func (c Config) GetDBStorage() int {
+	return c.DBStorage
+}
+
+func (c Config) GetDBMaxStorage() int {
+	return c.DBMaxStorage
+}
+
+func (c Config) GetDBStorageType() string {
+	return c.DBStorageType
+}
+
+func (c Config) GetDBIOPS() int {
+	return c.DBIOPS
+}
+
+func (c Config) GetDBBackupRetention() int {
+	return c.DBBackupRetention
+}
+
+func (c Config) GetDBBackupWindow() string {
+	return c.DBBackupWindow
+}
+
+func (c Config) GetDBDeletionProtection() bool {
+	return c.DBDeletionProtection
+}
+
+func (c Config) GetDBParams() []string {
+	return c.DBParams
+}
+
+func (c Config) GetDBRestore() DBRestore {
+	return c.DBRestore
+}
+
+func (c Config) GetDBArchivedInstances() []string {
+	return c.DBArchivedInstances
+}
+
+func (c Config) GetDirectorDBInstance() string {
+	return c.DirectorDBInstance
+}
+
+func (c Config) GetDBCACert() string {
+	return c.DBCACert
+}
+
+func (c Config) GetDBCACertIdentifier() string {
+	return c.DBCACertIdentifier
+}
+
+func (c Config) GetWorkerRebalanceInterval() string {
+	return c.WorkerRebalanceInterval
+}
+
+func (c Config) GetWorkerRetireMaxInFlight() int {
+	return c.WorkerRetireMaxInFlight
+}
+
+func (c Config) GetWorkerIMDSHopLimit() int {
+	return c.WorkerIMDSHopLimit
+}
+
+func (c Config) GetWorkerInstanceType() string {
+	return c.WorkerInstanceType
+}
+
+func (c Config) GetWorkerMaxContainers() int {
+	return c.WorkerMaxContainers
+}
+
+func (c Config) GetWorkerNetworkPool() string {
+	return c.WorkerNetworkPool
+}
+
+func (c Config) GetWorkerPools() []WorkerPool {
+	return c.WorkerPools
+}
+
+func (c Config) GetWorkerSpotBid() int {
+	return c.WorkerSpotBid
+}
+
+func (c Config) GetWorkerSysctls() []string {
+	return c.WorkerSysctls
+}
+
 func (c Config) GetWorkerType() string {
 	return c.WorkerType
 }
@@ -469,6 +1763,50 @@ func (c Config) IsMicrosoftAuthSet() bool {
 	return c.MicrosoftClientID != "" && c.MicrosoftClientSecret != ""
 }
 
+func (c Config) IsOIDCAuthSet() bool {
+	return c.OIDCIssuer != "" && c.OIDCClientID != "" && c.OIDCClientSecret != ""
+}
+
+func (c Config) IsOAuthAuthSet() bool {
+	return c.OAuthAuthURL != "" && c.OAuthTokenURL != "" && c.OAuthUserinfoURL != "" && c.OAuthClientID != "" && c.OAuthClientSecret != ""
+}
+
+func (c Config) IsSAMLAuthSet() bool {
+	return c.SAMLMetadataURL != ""
+}
+
+// IsVaultSet is true when Concourse uses Vault rather than the colocated CredHub as its credential manager
+func (c Config) IsVaultSet() bool {
+	return c.VaultURL != ""
+}
+
+// IsSecretsManagerSet is true when Concourse uses AWS Secrets Manager rather than the colocated CredHub as its credential manager
+func (c Config) IsSecretsManagerSet() bool {
+	return c.CredentialManager == "secretsmanager"
+}
+
+// IsSSMSet is true when Concourse uses AWS SSM Parameter Store rather than the colocated CredHub as its credential manager
+func (c Config) IsSSMSet() bool {
+	return c.CredentialManager == "ssm"
+}
+
+// IsManagedPrometheusSet is true when metrics are remote-written to the IAAS's managed Prometheus
+func (c Config) IsManagedPrometheusSet() bool {
+	return c.ManagedPrometheus
+}
+
+func (c Config) IsCFAuthSet() bool {
+	return c.CFAuthAPIURL != "" && c.CFAuthClientID != "" && c.CFAuthClientSecret != ""
+}
+
+func (c Config) IsMainCFAuthSet() bool {
+	return len(c.MainCFOrgs) > 0 || len(c.MainCFSpaces) > 0
+}
+
+func (c Config) IsLDAPAuthSet() bool {
+	return c.LDAPHost != "" && c.LDAPBindDN != "" && c.LDAPBindPassword != "" && c.LDAPUserSearchBaseDN != ""
+}
+
 func (c Config) IsSpot() bool {
 	return c.VMProvisioningType == SPOT
 }
@@ -79,6 +79,18 @@ type FakeIClient struct {
 		result1 []byte
 		result2 error
 	}
+	LoadRuntimeStub        func() (config.Runtime, error)
+	loadRuntimeMutex       sync.RWMutex
+	loadRuntimeArgsForCall []struct {
+	}
+	loadRuntimeReturns struct {
+		result1 config.Runtime
+		result2 error
+	}
+	loadRuntimeReturnsOnCall map[int]struct {
+		result1 config.Runtime
+		result2 error
+	}
 	NewConfigStub        func() config.Config
 	newConfigMutex       sync.RWMutex
 	newConfigArgsForCall []struct {
@@ -112,6 +124,17 @@ type FakeIClient struct {
 	updateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateRuntimeStub        func(config.Runtime) error
+	updateRuntimeMutex       sync.RWMutex
+	updateRuntimeArgsForCall []struct {
+		arg1 config.Runtime
+	}
+	updateRuntimeReturns struct {
+		result1 error
+	}
+	updateRuntimeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -464,6 +487,62 @@ func (fake *FakeIClient) LoadAssetReturnsOnCall(i int, result1 []byte, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeIClient) LoadRuntime() (config.Runtime, error) {
+	fake.loadRuntimeMutex.Lock()
+	ret, specificReturn := fake.loadRuntimeReturnsOnCall[len(fake.loadRuntimeArgsForCall)]
+	fake.loadRuntimeArgsForCall = append(fake.loadRuntimeArgsForCall, struct {
+	}{})
+	stub := fake.LoadRuntimeStub
+	fakeReturns := fake.loadRuntimeReturns
+	fake.recordInvocation("LoadRuntime", []interface{}{})
+	fake.loadRuntimeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) LoadRuntimeCallCount() int {
+	fake.loadRuntimeMutex.RLock()
+	defer fake.loadRuntimeMutex.RUnlock()
+	return len(fake.loadRuntimeArgsForCall)
+}
+
+func (fake *FakeIClient) LoadRuntimeCalls(stub func() (config.Runtime, error)) {
+	fake.loadRuntimeMutex.Lock()
+	defer fake.loadRuntimeMutex.Unlock()
+	fake.LoadRuntimeStub = stub
+}
+
+func (fake *FakeIClient) LoadRuntimeReturns(result1 config.Runtime, result2 error) {
+	fake.loadRuntimeMutex.Lock()
+	defer fake.loadRuntimeMutex.Unlock()
+	fake.LoadRuntimeStub = nil
+	fake.loadRuntimeReturns = struct {
+		result1 config.Runtime
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) LoadRuntimeReturnsOnCall(i int, result1 config.Runtime, result2 error) {
+	fake.loadRuntimeMutex.Lock()
+	defer fake.loadRuntimeMutex.Unlock()
+	fake.LoadRuntimeStub = nil
+	if fake.loadRuntimeReturnsOnCall == nil {
+		fake.loadRuntimeReturnsOnCall = make(map[int]struct {
+			result1 config.Runtime
+			result2 error
+		})
+	}
+	fake.loadRuntimeReturnsOnCall[i] = struct {
+		result1 config.Runtime
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) NewConfig() config.Config {
 	fake.newConfigMutex.Lock()
 	ret, specificReturn := fake.newConfigReturnsOnCall[len(fake.newConfigArgsForCall)]
@@ -642,6 +721,67 @@ func (fake *FakeIClient) UpdateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) UpdateRuntime(arg1 config.Runtime) error {
+	fake.updateRuntimeMutex.Lock()
+	ret, specificReturn := fake.updateRuntimeReturnsOnCall[len(fake.updateRuntimeArgsForCall)]
+	fake.updateRuntimeArgsForCall = append(fake.updateRuntimeArgsForCall, struct {
+		arg1 config.Runtime
+	}{arg1})
+	stub := fake.UpdateRuntimeStub
+	fakeReturns := fake.updateRuntimeReturns
+	fake.recordInvocation("UpdateRuntime", []interface{}{arg1})
+	fake.updateRuntimeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) UpdateRuntimeCallCount() int {
+	fake.updateRuntimeMutex.RLock()
+	defer fake.updateRuntimeMutex.RUnlock()
+	return len(fake.updateRuntimeArgsForCall)
+}
+
+func (fake *FakeIClient) UpdateRuntimeCalls(stub func(config.Runtime) error) {
+	fake.updateRuntimeMutex.Lock()
+	defer fake.updateRuntimeMutex.Unlock()
+	fake.UpdateRuntimeStub = stub
+}
+
+func (fake *FakeIClient) UpdateRuntimeArgsForCall(i int) config.Runtime {
+	fake.updateRuntimeMutex.RLock()
+	defer fake.updateRuntimeMutex.RUnlock()
+	argsForCall := fake.updateRuntimeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) UpdateRuntimeReturns(result1 error) {
+	fake.updateRuntimeMutex.Lock()
+	defer fake.updateRuntimeMutex.Unlock()
+	fake.UpdateRuntimeStub = nil
+	fake.updateRuntimeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) UpdateRuntimeReturnsOnCall(i int, result1 error) {
+	fake.updateRuntimeMutex.Lock()
+	defer fake.updateRuntimeMutex.Unlock()
+	fake.UpdateRuntimeStub = nil
+	if fake.updateRuntimeReturnsOnCall == nil {
+		fake.updateRuntimeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateRuntimeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -657,12 +797,16 @@ func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	defer fake.loadMutex.RUnlock()
 	fake.loadAssetMutex.RLock()
 	defer fake.loadAssetMutex.RUnlock()
+	fake.loadRuntimeMutex.RLock()
+	defer fake.loadRuntimeMutex.RUnlock()
 	fake.newConfigMutex.RLock()
 	defer fake.newConfigMutex.RUnlock()
 	fake.storeAssetMutex.RLock()
 	defer fake.storeAssetMutex.RUnlock()
 	fake.updateMutex.RLock()
 	defer fake.updateMutex.RUnlock()
+	fake.updateRuntimeMutex.RLock()
+	defer fake.updateRuntimeMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,11 @@
+package config
+
+// DBRestore is the point-in-time restore that created the current database instance, which terraform
+// needs to keep describing the instance as it was created
+type DBRestore struct {
+	// Source is the instance that was restored, and Instance the one created from it
+	Source   string `json:"source"`
+	Instance string `json:"instance"`
+	// Time is the RFC3339 time the source was restored to
+	Time string `json:"time"`
+}
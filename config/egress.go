@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// EgressRule lets workers with restricted egress open connections to the ports of a CIDR block
+type EgressRule struct {
+	CIDR     string `json:"cidr"`
+	Protocol string `json:"protocol"`
+	FromPort int    `json:"from_port"`
+	ToPort   int    `json:"to_port"`
+}
+
+// ParseEgressRule reads a rule in the format CIDR:PORT, CIDR:PORT-PORT or either followed by /udp or /tcp,
+// as in 10.0.0.0/8:443 or 10.0.0.2:53/udp. The protocol is tcp when not given, and an IP address is a /32
+func ParseEgressRule(rule string) (EgressRule, error) {
+	invalid := fmt.Errorf("worker egress rule `%s` is invalid: must be in the format CIDR:PORT, CIDR:PORT-PORT, or either followed by /tcp or /udp", rule)
+
+	separator := strings.LastIndex(rule, ":")
+	if separator == -1 {
+		return EgressRule{}, invalid
+	}
+	address, ports := rule[:separator], rule[separator+1:]
+
+	if !strings.Contains(address, "/") {
+		address += "/32"
+	}
+	_, cidr, err := net.ParseCIDR(address)
+	if err != nil || cidr.IP.To4() == nil {
+		return EgressRule{}, invalid
+	}
+
+	protocol := "tcp"
+	if i := strings.Index(ports, "/"); i != -1 {
+		ports, protocol = ports[:i], ports[i+1:]
+		if protocol != "tcp" && protocol != "udp" {
+			return EgressRule{}, invalid
+		}
+	}
+
+	from, to := ports, ports
+	if i := strings.Index(ports, "-"); i != -1 {
+		from, to = ports[:i], ports[i+1:]
+	}
+	fromPort, err := strconv.Atoi(from)
+	if err != nil {
+		return EgressRule{}, invalid
+	}
+	toPort, err := strconv.Atoi(to)
+	if err != nil {
+		return EgressRule{}, invalid
+	}
+	if fromPort < 1 || toPort > 65535 || fromPort > toPort {
+		return EgressRule{}, fmt.Errorf("worker egress rule `%s` is invalid: ports must be between 1 and 65535, lowest first", rule)
+	}
+
+	return EgressRule{CIDR: cidr.String(), Protocol: protocol, FromPort: fromPort, ToPort: toPort}, nil
+}
+
+// Ports is the rule's port, or its range of ports in the format PORT-PORT
+func (r EgressRule) Ports() string {
+	if r.FromPort == r.ToPort {
+		return strconv.Itoa(r.FromPort)
+	}
+	return fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+}
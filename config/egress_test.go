@@ -0,0 +1,46 @@
+package config_test
+
+import (
+	. "github.com/EngineerBetter/control-tower/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressRule", func() {
+	Describe("ParseEgressRule", func() {
+		It("defaults to tcp", func() {
+			rule, err := ParseEgressRule("10.0.0.0/8:443")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rule).To(Equal(EgressRule{CIDR: "10.0.0.0/8", Protocol: "tcp", FromPort: 443, ToPort: 443}))
+			Expect(rule.Ports()).To(Equal("443"))
+		})
+
+		It("reads an IP address as a /32 and a protocol after the port", func() {
+			rule, err := ParseEgressRule("10.0.0.2:53/udp")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rule).To(Equal(EgressRule{CIDR: "10.0.0.2/32", Protocol: "udp", FromPort: 53, ToPort: 53}))
+		})
+
+		It("reads a range of ports", func() {
+			rule, err := ParseEgressRule("192.168.1.7/16:8000-8100")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rule).To(Equal(EgressRule{CIDR: "192.168.0.0/16", Protocol: "tcp", FromPort: 8000, ToPort: 8100}))
+			Expect(rule.Ports()).To(Equal("8000-8100"))
+		})
+
+		It("rejects a rule without a port", func() {
+			_, err := ParseEgressRule("10.0.0.0/8")
+			Expect(err).To(MatchError("worker egress rule `10.0.0.0/8` is invalid: must be in the format CIDR:PORT, CIDR:PORT-PORT, or either followed by /tcp or /udp"))
+		})
+
+		It("rejects an unknown protocol", func() {
+			_, err := ParseEgressRule("10.0.0.0/8:443/icmp")
+			Expect(err).To(MatchError("worker egress rule `10.0.0.0/8:443/icmp` is invalid: must be in the format CIDR:PORT, CIDR:PORT-PORT, or either followed by /tcp or /udp"))
+		})
+
+		It("rejects ports out of order", func() {
+			_, err := ParseEgressRule("10.0.0.0/8:8100-8000")
+			Expect(err).To(MatchError("worker egress rule `10.0.0.0/8:8100-8000` is invalid: ports must be between 1 and 65535, lowest first"))
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package config
+
+const runtimeFilePath = "runtime.json"
+
+// Runtime is what scaling the workers needs from a deployment, kept in its own file of the config bucket so that
+// the credential that `maintain --autoscale` runs with can be denied the rest of the config and the terraform state
+type Runtime struct {
+	Config Config `json:"config"`
+	// Outputs are the terraform outputs needed to reach the director, as recorded by the last deploy
+	Outputs map[string]string `json:"outputs"`
+}
+
+// RuntimeConfig returns the parts of conf that scaling the workers needs
+func RuntimeConfig(conf Config) Config {
+	return Config{
+		Bastion:                conf.Bastion,
+		BastionPrivateKey:      conf.BastionPrivateKey,
+		ConcoursePassword:      conf.ConcoursePassword,
+		ConcourseUsername:      conf.ConcourseUsername,
+		ConcourseWorkerCount:   conf.ConcourseWorkerCount,
+		ConfigBucket:           conf.ConfigBucket,
+		Deployment:             conf.Deployment,
+		DirectorCACert:         conf.DirectorCACert,
+		DirectorPassword:       conf.DirectorPassword,
+		DirectorPublicIP:       conf.DirectorPublicIP,
+		DirectorUsername:       conf.DirectorUsername,
+		Domain:                 conf.Domain,
+		IAAS:                   conf.IAAS,
+		Namespace:              conf.Namespace,
+		Project:                conf.Project,
+		Region:                 conf.Region,
+		WorkerAutoscaleMax:     conf.WorkerAutoscaleMax,
+		WorkerAutoscaleMin:     conf.WorkerAutoscaleMin,
+		WorkerPools:            conf.WorkerPools,
+		WorkerSchedules:        conf.WorkerSchedules,
+		WorkerScheduleTimezone: conf.WorkerScheduleTimezone,
+	}
+}
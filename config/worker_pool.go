@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// WorkerPool is a group of workers deployed alongside the default workers. Steps only run on a
+// pool's workers when their tags match the pool's Tags
+type WorkerPool struct {
+	Name  string   `json:"name" yaml:"name"`
+	Size  string   `json:"size" yaml:"size"`
+	Count int      `json:"count" yaml:"count"`
+	Type  string   `json:"type,omitempty" yaml:"type"`
+	Arch  string   `json:"arch,omitempty" yaml:"arch"`
+	GPU   string   `json:"gpu,omitempty" yaml:"gpu"`
+	Tags  []string `json:"tags" yaml:"tags"`
+	// BaggageclaimDriver is the volume driver of the pool's workers, detected by baggageclaim when empty
+	BaggageclaimDriver string `json:"baggageclaim_driver,omitempty" yaml:"baggageclaim_driver"`
+	// Team is the only Concourse team whose builds run on the pool's workers. Workers without a team run
+	// the builds of every team
+	Team string `json:"team,omitempty" yaml:"team"`
+}
+
+// ARM64 is the Arch of a pool of Graviton or Tau T2A workers
+const ARM64 = "arm64"
+
+// GPUTag is the tag that workers in a pool with a GPU always register with
+const GPUTag = "gpu"
+
+// AWSGPUFamilies maps each GPU a pool may have on AWS to the EC2 instance family with that GPU
+var AWSGPUFamilies = map[string]string{
+	"a10g": "g5",
+	"t4":   "g4dn",
+}
+
+// GCPGPUAccelerators maps each GPU a pool may have on GCP to the accelerator type attached to each worker
+var GCPGPUAccelerators = map[string]string{
+	"p100": "nvidia-tesla-p100",
+	"t4":   "nvidia-tesla-t4",
+	"v100": "nvidia-tesla-v100",
+}
+
+// BaggageclaimDrivers are the volume drivers a worker pool can choose
+var BaggageclaimDrivers = []string{"overlay", "btrfs", "naive"}
+
+// StemcellSupportsBaggageclaimDriver is true when workers on the named stemcell can use the driver. overlay and
+// btrfs need the kernel modules of an Ubuntu stemcell, while naive copies volumes and works anywhere
+func StemcellSupportsBaggageclaimDriver(stemcellName, driver string) bool {
+	return driver == "naive" || strings.Contains(stemcellName, "-ubuntu-")
+}
+
+var (
+	stemcellFilename = regexp.MustCompile(`^(?:light-)?bosh-stemcell-([0-9][^-]*)-(.+)\.tgz$`)
+	releaseFilename  = regexp.MustCompile(`^(.+)-([0-9][0-9A-Za-z.+]*)\.tgz$`)
+)
+
+// ParseStemcellURL gets the name and version of a stemcell from the filename at the end of its
+// https URL, as in light-bosh-stemcell-1.406-aws-xen-hvm-ubuntu-jammy-go_agent.tgz
+func ParseStemcellURL(stemcellURL string) (name, version string, err error) {
+	filename, err := httpsFilename(stemcellURL)
+	if err != nil {
+		return "", "", err
+	}
+	match := stemcellFilename.FindStringSubmatch(filename)
+	if match == nil {
+		return "", "", fmt.Errorf("stemcell URL %s is invalid: must end in a stemcell filename like light-bosh-stemcell-<version>-<name>.tgz", stemcellURL)
+	}
+	return "bosh-" + match[2], match[1], nil
+}
+
+// ParseReleaseURL gets the name and version of a BOSH release from the filename at the end of its
+// https URL, as in concourse-arm64-7.11.2.tgz
+func ParseReleaseURL(releaseURL string) (name, version string, err error) {
+	filename, err := httpsFilename(releaseURL)
+	if err != nil {
+		return "", "", err
+	}
+	match := releaseFilename.FindStringSubmatch(filename)
+	if match == nil {
+		return "", "", fmt.Errorf("release URL %s is invalid: must end in a release filename like <name>-<version>.tgz", releaseURL)
+	}
+	return match[1], match[2], nil
+}
+
+func httpsFilename(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%s is invalid: must be an https URL", rawURL)
+	}
+	return path.Base(u.Path), nil
+}
@@ -0,0 +1,42 @@
+package config_test
+
+import (
+	. "github.com/EngineerBetter/control-tower/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerPool", func() {
+	Describe("ParseStemcellURL", func() {
+		It("gets the name and version from a light stemcell filename", func() {
+			name, version, err := ParseStemcellURL("https://example.com/stemcells/light-bosh-stemcell-1.406-aws-xen-hvm-ubuntu-jammy-go_agent-arm64.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("bosh-aws-xen-hvm-ubuntu-jammy-go_agent-arm64"))
+			Expect(version).To(Equal("1.406"))
+		})
+
+		It("rejects URLs that aren't https", func() {
+			_, _, err := ParseStemcellURL("http://example.com/light-bosh-stemcell-1.406-aws-xen-hvm-ubuntu-jammy-go_agent.tgz")
+			Expect(err).To(MatchError("http://example.com/light-bosh-stemcell-1.406-aws-xen-hvm-ubuntu-jammy-go_agent.tgz is invalid: must be an https URL"))
+		})
+
+		It("rejects filenames it can't get a name and version from", func() {
+			_, _, err := ParseStemcellURL("https://example.com/stemcell.tgz")
+			Expect(err).To(MatchError("stemcell URL https://example.com/stemcell.tgz is invalid: must end in a stemcell filename like light-bosh-stemcell-<version>-<name>.tgz"))
+		})
+	})
+
+	Describe("ParseReleaseURL", func() {
+		It("gets the name and version from a release filename", func() {
+			name, version, err := ParseReleaseURL("https://example.com/releases/concourse-arm64-7.11.2.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("concourse-arm64"))
+			Expect(version).To(Equal("7.11.2"))
+		})
+
+		It("rejects filenames without a version", func() {
+			_, _, err := ParseReleaseURL("https://example.com/releases/concourse.tgz")
+			Expect(err).To(MatchError("release URL https://example.com/releases/concourse.tgz is invalid: must end in a release filename like <name>-<version>.tgz"))
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Schedules are evaluated in the pipeline's task image, which may not have zoneinfo
+	_ "time/tzdata"
+)
+
+// WorkerSchedule sets the number of workers from each minute its Cron expression matches until
+// another schedule's expression matches
+type WorkerSchedule struct {
+	Cron    string `json:"cron"`
+	Workers int    `json:"workers"`
+}
+
+// ParseWorkerSchedule parses a schedule given as `<cron expression>=<workers>`, like `0 8 * * 1-5=10`
+func ParseWorkerSchedule(s string) (WorkerSchedule, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return WorkerSchedule{}, fmt.Errorf("worker schedule `%s` is invalid: must be in the format `<cron expression>=<workers>`", s)
+	}
+	schedule := WorkerSchedule{Cron: strings.TrimSpace(s[:i])}
+	workers, err := strconv.Atoi(strings.TrimSpace(s[i+1:]))
+	if err != nil || workers < 1 {
+		return WorkerSchedule{}, fmt.Errorf("worker schedule `%s` is invalid: number of workers must be at least 1", s)
+	}
+	schedule.Workers = workers
+	if _, err = ParseCron(schedule.Cron); err != nil {
+		return WorkerSchedule{}, fmt.Errorf("worker schedule `%s` is invalid: %v", s, err)
+	}
+	return schedule, nil
+}
+
+// Cron is a parsed five field cron expression: minute, hour, day of month, month and day of week
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// As in crontab, when both days are restricted a time matches if either does
+	domStar, dowStar bool
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a cron expression made of numbers, `*`, ranges like `1-5`, steps like `*/15` and
+// comma separated lists of these
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return Cron{}, fmt.Errorf("cron expression `%s` must have 5 fields: minute, hour, day of month, month and day of week", expr)
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i].min, cronFields[i].max); err != nil {
+			return Cron{}, fmt.Errorf("cron %s `%s` is invalid: %v", cronFields[i].name, field, err)
+		}
+	}
+	// Sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return Cron{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.New("step must be a positive number")
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			var err error
+			bounds := strings.SplitN(rangePart, "-", 2)
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("%s is not a number", bounds[0])
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("%s is not a number", bounds[1])
+				}
+			}
+			if low < min || high > max || low > high {
+				return 0, fmt.Errorf("must be between %d and %d", min, max)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches is true when t falls in a minute that the expression matches
+func (c Cron) Matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
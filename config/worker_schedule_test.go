@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"time"
+
+	. "github.com/EngineerBetter/control-tower/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerSchedule", func() {
+	Describe("ParseWorkerSchedule", func() {
+		It("splits the cron expression from the number of workers", func() {
+			schedule, err := ParseWorkerSchedule("0 8 * * 1-5=10")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule).To(Equal(WorkerSchedule{Cron: "0 8 * * 1-5", Workers: 10}))
+		})
+
+		It("rejects a schedule without a number of workers", func() {
+			_, err := ParseWorkerSchedule("0 8 * * 1-5")
+			Expect(err).To(MatchError("worker schedule `0 8 * * 1-5` is invalid: must be in the format `<cron expression>=<workers>`"))
+		})
+
+		It("rejects a schedule for no workers", func() {
+			_, err := ParseWorkerSchedule("0 8 * * 1-5=0")
+			Expect(err).To(MatchError("worker schedule `0 8 * * 1-5=0` is invalid: number of workers must be at least 1"))
+		})
+
+		It("rejects an invalid cron expression", func() {
+			_, err := ParseWorkerSchedule("0 25 * * *=2")
+			Expect(err).To(MatchError("worker schedule `0 25 * * *=2` is invalid: cron hour `25` is invalid: must be between 0 and 23"))
+		})
+	})
+
+	Describe("ParseCron", func() {
+		// 2024-03-04 is a Monday
+		monday := time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC)
+
+		It("matches ranges of days", func() {
+			cron, err := ParseCron("0 8 * * 1-5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cron.Matches(monday)).To(BeTrue())
+			Expect(cron.Matches(monday.Add(time.Minute))).To(BeFalse())
+			Expect(cron.Matches(monday.AddDate(0, 0, 5))).To(BeFalse())
+		})
+
+		It("matches steps and lists", func() {
+			cron, err := ParseCron("*/15 8,20 * * *")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cron.Matches(monday.Add(45 * time.Minute))).To(BeTrue())
+			Expect(cron.Matches(monday.Add(12 * time.Hour))).To(BeTrue())
+			Expect(cron.Matches(monday.Add(10 * time.Minute))).To(BeFalse())
+		})
+
+		It("treats 7 as Sunday", func() {
+			cron, err := ParseCron("0 8 * * 7")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cron.Matches(monday.AddDate(0, 0, 6))).To(BeTrue())
+		})
+
+		It("matches either day when both are restricted", func() {
+			cron, err := ParseCron("0 8 1 * 1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cron.Matches(monday)).To(BeTrue())
+			Expect(cron.Matches(time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC))).To(BeTrue())
+			Expect(cron.Matches(time.Date(2024, time.March, 2, 8, 0, 0, 0, time.UTC))).To(BeFalse())
+		})
+
+		It("rejects expressions without 5 fields", func() {
+			_, err := ParseCron("0 8 * *")
+			Expect(err).To(MatchError("cron expression `0 8 * *` must have 5 fields: minute, hour, day of month, month and day of week"))
+		})
+
+		It("rejects steps that aren't positive", func() {
+			_, err := ParseCron("*/0 * * * *")
+			Expect(err).To(MatchError("cron minute `*/0` is invalid: step must be a positive number"))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// WorkerZone is a further zone in the region that workers are spread across, alongside the deployment's
+// own zone. On AWS its workers are placed in a private subnet of their own, CIDR, as subnets can't span zones.
+// NATCIDR is the public subnet of the zone's own NAT gateway, when the deployment has one per zone
+type WorkerZone struct {
+	Zone    string `json:"zone"`
+	CIDR    string `json:"cidr,omitempty"`
+	NATCIDR string `json:"nat_cidr,omitempty"`
+}
+
+// AllocateWorkerZoneCIDR finds the first block of network, the same size as the private subnet, that
+// doesn't overlap any of the taken CIDRs
+func AllocateWorkerZoneCIDR(network, private string, taken []string) (string, error) {
+	_, privateNet, err := net.ParseCIDR(private)
+	if err != nil {
+		return "", err
+	}
+	privateBits, _ := privateNet.Mask.Size()
+	allocated, err := allocateCIDR(network, privateBits, taken)
+	if err == errNoRoom {
+		return "", fmt.Errorf("network %s has no room left for another subnet the size of %s", network, private)
+	}
+	if err == errTooBig {
+		return "", fmt.Errorf("private subnet %s is bigger than network %s", private, network)
+	}
+	return allocated, err
+}
@@ -0,0 +1,28 @@
+package config_test
+
+import (
+	. "github.com/EngineerBetter/control-tower/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerZone", func() {
+	Describe("AllocateWorkerZoneCIDR", func() {
+		It("skips the blocks that are taken", func() {
+			allocated, err := AllocateWorkerZoneCIDR("10.0.0.0/16", "10.0.1.0/24", []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.4.0/24", "10.0.5.0/24"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allocated).To(Equal("10.0.2.0/24"))
+		})
+
+		It("skips blocks that contain a smaller taken subnet", func() {
+			allocated, err := AllocateWorkerZoneCIDR("10.0.0.0/24", "10.0.0.64/26", []string{"10.0.0.0/28", "10.0.0.64/26", "10.0.0.200/29"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allocated).To(Equal("10.0.0.128/26"))
+		})
+
+		It("errors when the network is full", func() {
+			_, err := AllocateWorkerZoneCIDR("10.0.0.0/23", "10.0.1.0/24", []string{"10.0.0.0/24", "10.0.1.0/24"})
+			Expect(err).To(MatchError("network 10.0.0.0/23 has no room left for another subnet the size of 10.0.1.0/24"))
+		})
+	})
+})
@@ -2,10 +2,13 @@ package credhub
 
 import (
 	"fmt"
+	"strings"
 
 	ch "code.cloudfoundry.org/credhub-cli/credhub"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"github.com/EngineerBetter/control-tower/credhub/internal/credhubapi"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/terraform"
 )
@@ -15,10 +18,14 @@ import (
 //counterfeiter:generate . IClient
 type IClient interface {
 	SetSelfUpdateCreds(provider iaas.Provider, tfOutputs terraform.Outputs) error
+	SetRuntimeCreds(provider iaas.Provider, tfOutputs terraform.Outputs) error
+	GetCredential(name string) (credentials.Credential, error)
+	SetCredential(name, credType string, value interface{}) (credentials.Credential, error)
+	FindCertificates(path string) ([]credentials.CertificateMetadata, error)
 }
 
 type Client struct {
-	credHub *ch.CredHub
+	credHub credhubapi.API
 }
 
 // Creates a new CredHub client using provided details
@@ -67,3 +74,72 @@ func (client *Client) SetSelfUpdateCreds(provider iaas.Provider, tfOutputs terra
 	}
 	return nil
 }
+
+// SetRuntimeCreds stores the runtime credential for the self-update pipeline's autoscale-workers job. It can only
+// use the runtime file and the deploy lock in the config bucket, unlike the self-update credential
+func (client *Client) SetRuntimeCreds(provider iaas.Provider, tfOutputs terraform.Outputs) error {
+	switch provider.IAAS() {
+	case iaas.AWS:
+		keyID, err := tfOutputs.Get("RuntimeUserAccessKeyID")
+		if err != nil {
+			return err
+		}
+		secretKey, err := tfOutputs.Get("RuntimeSecretAccessKey")
+		if err != nil {
+			return err
+		}
+		_, err = client.credHub.SetValue("/concourse/main/control-tower-self-update/runtime_aws_access_key_id", values.Value(keyID))
+		if err != nil {
+			return err
+		}
+		_, err = client.credHub.SetValue("/concourse/main/control-tower-self-update/runtime_aws_secret_access_key", values.Value(secretKey))
+		if err != nil {
+			return err
+		}
+	case iaas.GCP:
+		googleCreds, err := tfOutputs.Get("RuntimeAccountCreds")
+		if err != nil {
+			return err
+		}
+		_, err = client.credHub.SetValue("/concourse/main/control-tower-self-update/runtime_google_credentials", values.Value(googleCreds))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCredential returns the latest version of the named credential
+func (client *Client) GetCredential(name string) (credentials.Credential, error) {
+	cred, err := client.credHub.GetLatestVersion(name)
+	if err != nil {
+		return credentials.Credential{}, fmt.Errorf("failed to get credential %s: [%v]", name, err)
+	}
+	return cred, nil
+}
+
+// SetCredential writes a new version of the named credential with the given type and value
+func (client *Client) SetCredential(name, credType string, value interface{}) (credentials.Credential, error) {
+	cred, err := client.credHub.SetCredential(name, credType, value)
+	if err != nil {
+		return credentials.Credential{}, fmt.Errorf("failed to set credential %s: [%v]", name, err)
+	}
+	return cred, nil
+}
+
+// FindCertificates returns the metadata of all certificates whose name is under the given path
+func (client *Client) FindCertificates(path string) ([]credentials.CertificateMetadata, error) {
+	all, err := client.credHub.GetAllCertificatesMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list certificates: [%v]", err)
+	}
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	var certs []credentials.CertificateMetadata
+	for _, cert := range all {
+		if path == "" || path == "/" || strings.HasPrefix(cert.Name, prefix) {
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
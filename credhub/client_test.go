@@ -0,0 +1,149 @@
+package credhub
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"github.com/EngineerBetter/control-tower/credhub/internal/credhubapi/credhubapifakes"
+	"github.com/EngineerBetter/control-tower/iaas"
+	"github.com/EngineerBetter/control-tower/iaas/iaasfakes"
+	"github.com/EngineerBetter/control-tower/terraform"
+)
+
+func TestClient_GetCredential(t *testing.T) {
+	api := new(credhubapifakes.FakeAPI)
+	want := credentials.Credential{Base: credentials.Base{Name: "/concourse/main/password", Type: "password"}, Value: "secret"}
+	api.GetLatestVersionReturns(want, nil)
+	client := &Client{credHub: api}
+
+	got, err := client.GetCredential("/concourse/main/password")
+	if err != nil {
+		t.Fatalf("GetCredential() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCredential() = %v, want %v", got, want)
+	}
+	if name := api.GetLatestVersionArgsForCall(0); name != "/concourse/main/password" {
+		t.Errorf("GetCredential() got %s, want /concourse/main/password", name)
+	}
+
+	api.GetLatestVersionReturns(credentials.Credential{}, errors.New("not found"))
+	if _, err = client.GetCredential("/concourse/main/missing"); err == nil || err.Error() != "failed to get credential /concourse/main/missing: [not found]" {
+		t.Errorf("GetCredential() error = %v", err)
+	}
+}
+
+func TestClient_SetCredential(t *testing.T) {
+	api := new(credhubapifakes.FakeAPI)
+	want := credentials.Credential{Base: credentials.Base{Name: "/concourse/main/token", Type: "value"}, Value: "abc"}
+	api.SetCredentialReturns(want, nil)
+	client := &Client{credHub: api}
+
+	got, err := client.SetCredential("/concourse/main/token", "value", "abc")
+	if err != nil {
+		t.Fatalf("SetCredential() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SetCredential() = %v, want %v", got, want)
+	}
+	name, credType, value, _ := api.SetCredentialArgsForCall(0)
+	if name != "/concourse/main/token" || credType != "value" || value != "abc" {
+		t.Errorf("SetCredential() set %s of type %s to %v", name, credType, value)
+	}
+
+	api.SetCredentialReturns(credentials.Credential{}, errors.New("forbidden"))
+	if _, err = client.SetCredential("/concourse/main/token", "value", "abc"); err == nil || err.Error() != "failed to set credential /concourse/main/token: [forbidden]" {
+		t.Errorf("SetCredential() error = %v", err)
+	}
+}
+
+func TestClient_FindCertificates(t *testing.T) {
+	api := new(credhubapifakes.FakeAPI)
+	api.GetAllCertificatesMetadataReturns([]credentials.CertificateMetadata{
+		{Name: "/concourse/main/ca"},
+		{Name: "/concourse/main/server"},
+		{Name: "/concourse/mainframe/ca"},
+		{Name: "/bosh/ca"},
+	}, nil)
+	client := &Client{credHub: api}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "/concourse/main", want: []string{"/concourse/main/ca", "/concourse/main/server"}},
+		{path: "/concourse/main/", want: []string{"/concourse/main/ca", "/concourse/main/server"}},
+		{path: "/", want: []string{"/concourse/main/ca", "/concourse/main/server", "/concourse/mainframe/ca", "/bosh/ca"}},
+		{path: "/vault"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			certs, err := client.FindCertificates(tt.path)
+			if err != nil {
+				t.Fatalf("FindCertificates() error = %v", err)
+			}
+			var got []string
+			for _, cert := range certs {
+				got = append(got, cert.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCertificates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	api.GetAllCertificatesMetadataReturns(nil, errors.New("unauthorised"))
+	if _, err := client.FindCertificates("/concourse"); err == nil || err.Error() != "failed to list certificates: [unauthorised]" {
+		t.Errorf("FindCertificates() error = %v", err)
+	}
+}
+
+func TestClient_SetRuntimeCreds(t *testing.T) {
+	tests := []struct {
+		name    string
+		iaas    iaas.Name
+		outputs terraform.RecordedOutputs
+		want    map[string]values.Value
+	}{
+		{
+			name:    "AWS",
+			iaas:    iaas.AWS,
+			outputs: terraform.RecordedOutputs{"RuntimeUserAccessKeyID": "key-id", "RuntimeSecretAccessKey": "secret"},
+			want: map[string]values.Value{
+				"/concourse/main/control-tower-self-update/runtime_aws_access_key_id":     "key-id",
+				"/concourse/main/control-tower-self-update/runtime_aws_secret_access_key": "secret",
+			},
+		},
+		{
+			name:    "GCP",
+			iaas:    iaas.GCP,
+			outputs: terraform.RecordedOutputs{"RuntimeAccountCreds": `{"type":"service_account"}`},
+			want: map[string]values.Value{
+				"/concourse/main/control-tower-self-update/runtime_google_credentials": `{"type":"service_account"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := new(credhubapifakes.FakeAPI)
+			provider := new(iaasfakes.FakeProvider)
+			provider.IAASReturns(tt.iaas)
+			client := &Client{credHub: api}
+
+			if err := client.SetRuntimeCreds(provider, tt.outputs); err != nil {
+				t.Fatalf("SetRuntimeCreds() error = %v", err)
+			}
+			got := map[string]values.Value{}
+			for i := 0; i < api.SetValueCallCount(); i++ {
+				name, value, _ := api.SetValueArgsForCall(i)
+				got[name] = value
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetRuntimeCreds() set %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,66 @@ package credhubfakes
 import (
 	"sync"
 
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"github.com/EngineerBetter/control-tower/credhub"
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/terraform"
 )
 
 type FakeIClient struct {
+	FindCertificatesStub        func(string) ([]credentials.CertificateMetadata, error)
+	findCertificatesMutex       sync.RWMutex
+	findCertificatesArgsForCall []struct {
+		arg1 string
+	}
+	findCertificatesReturns struct {
+		result1 []credentials.CertificateMetadata
+		result2 error
+	}
+	findCertificatesReturnsOnCall map[int]struct {
+		result1 []credentials.CertificateMetadata
+		result2 error
+	}
+	GetCredentialStub        func(string) (credentials.Credential, error)
+	getCredentialMutex       sync.RWMutex
+	getCredentialArgsForCall []struct {
+		arg1 string
+	}
+	getCredentialReturns struct {
+		result1 credentials.Credential
+		result2 error
+	}
+	getCredentialReturnsOnCall map[int]struct {
+		result1 credentials.Credential
+		result2 error
+	}
+	SetCredentialStub        func(string, string, interface{}) (credentials.Credential, error)
+	setCredentialMutex       sync.RWMutex
+	setCredentialArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+	}
+	setCredentialReturns struct {
+		result1 credentials.Credential
+		result2 error
+	}
+	setCredentialReturnsOnCall map[int]struct {
+		result1 credentials.Credential
+		result2 error
+	}
+	SetRuntimeCredsStub        func(iaas.Provider, terraform.Outputs) error
+	setRuntimeCredsMutex       sync.RWMutex
+	setRuntimeCredsArgsForCall []struct {
+		arg1 iaas.Provider
+		arg2 terraform.Outputs
+	}
+	setRuntimeCredsReturns struct {
+		result1 error
+	}
+	setRuntimeCredsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetSelfUpdateCredsStub        func(iaas.Provider, terraform.Outputs) error
 	setSelfUpdateCredsMutex       sync.RWMutex
 	setSelfUpdateCredsArgsForCall []struct {
@@ -26,6 +80,262 @@ type FakeIClient struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeIClient) FindCertificates(arg1 string) ([]credentials.CertificateMetadata, error) {
+	fake.findCertificatesMutex.Lock()
+	ret, specificReturn := fake.findCertificatesReturnsOnCall[len(fake.findCertificatesArgsForCall)]
+	fake.findCertificatesArgsForCall = append(fake.findCertificatesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FindCertificatesStub
+	fakeReturns := fake.findCertificatesReturns
+	fake.recordInvocation("FindCertificates", []interface{}{arg1})
+	fake.findCertificatesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) FindCertificatesCallCount() int {
+	fake.findCertificatesMutex.RLock()
+	defer fake.findCertificatesMutex.RUnlock()
+	return len(fake.findCertificatesArgsForCall)
+}
+
+func (fake *FakeIClient) FindCertificatesCalls(stub func(string) ([]credentials.CertificateMetadata, error)) {
+	fake.findCertificatesMutex.Lock()
+	defer fake.findCertificatesMutex.Unlock()
+	fake.FindCertificatesStub = stub
+}
+
+func (fake *FakeIClient) FindCertificatesArgsForCall(i int) string {
+	fake.findCertificatesMutex.RLock()
+	defer fake.findCertificatesMutex.RUnlock()
+	argsForCall := fake.findCertificatesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) FindCertificatesReturns(result1 []credentials.CertificateMetadata, result2 error) {
+	fake.findCertificatesMutex.Lock()
+	defer fake.findCertificatesMutex.Unlock()
+	fake.FindCertificatesStub = nil
+	fake.findCertificatesReturns = struct {
+		result1 []credentials.CertificateMetadata
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) FindCertificatesReturnsOnCall(i int, result1 []credentials.CertificateMetadata, result2 error) {
+	fake.findCertificatesMutex.Lock()
+	defer fake.findCertificatesMutex.Unlock()
+	fake.FindCertificatesStub = nil
+	if fake.findCertificatesReturnsOnCall == nil {
+		fake.findCertificatesReturnsOnCall = make(map[int]struct {
+			result1 []credentials.CertificateMetadata
+			result2 error
+		})
+	}
+	fake.findCertificatesReturnsOnCall[i] = struct {
+		result1 []credentials.CertificateMetadata
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) GetCredential(arg1 string) (credentials.Credential, error) {
+	fake.getCredentialMutex.Lock()
+	ret, specificReturn := fake.getCredentialReturnsOnCall[len(fake.getCredentialArgsForCall)]
+	fake.getCredentialArgsForCall = append(fake.getCredentialArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetCredentialStub
+	fakeReturns := fake.getCredentialReturns
+	fake.recordInvocation("GetCredential", []interface{}{arg1})
+	fake.getCredentialMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) GetCredentialCallCount() int {
+	fake.getCredentialMutex.RLock()
+	defer fake.getCredentialMutex.RUnlock()
+	return len(fake.getCredentialArgsForCall)
+}
+
+func (fake *FakeIClient) GetCredentialCalls(stub func(string) (credentials.Credential, error)) {
+	fake.getCredentialMutex.Lock()
+	defer fake.getCredentialMutex.Unlock()
+	fake.GetCredentialStub = stub
+}
+
+func (fake *FakeIClient) GetCredentialArgsForCall(i int) string {
+	fake.getCredentialMutex.RLock()
+	defer fake.getCredentialMutex.RUnlock()
+	argsForCall := fake.getCredentialArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) GetCredentialReturns(result1 credentials.Credential, result2 error) {
+	fake.getCredentialMutex.Lock()
+	defer fake.getCredentialMutex.Unlock()
+	fake.GetCredentialStub = nil
+	fake.getCredentialReturns = struct {
+		result1 credentials.Credential
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) GetCredentialReturnsOnCall(i int, result1 credentials.Credential, result2 error) {
+	fake.getCredentialMutex.Lock()
+	defer fake.getCredentialMutex.Unlock()
+	fake.GetCredentialStub = nil
+	if fake.getCredentialReturnsOnCall == nil {
+		fake.getCredentialReturnsOnCall = make(map[int]struct {
+			result1 credentials.Credential
+			result2 error
+		})
+	}
+	fake.getCredentialReturnsOnCall[i] = struct {
+		result1 credentials.Credential
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) SetCredential(arg1 string, arg2 string, arg3 interface{}) (credentials.Credential, error) {
+	fake.setCredentialMutex.Lock()
+	ret, specificReturn := fake.setCredentialReturnsOnCall[len(fake.setCredentialArgsForCall)]
+	fake.setCredentialArgsForCall = append(fake.setCredentialArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.SetCredentialStub
+	fakeReturns := fake.setCredentialReturns
+	fake.recordInvocation("SetCredential", []interface{}{arg1, arg2, arg3})
+	fake.setCredentialMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) SetCredentialCallCount() int {
+	fake.setCredentialMutex.RLock()
+	defer fake.setCredentialMutex.RUnlock()
+	return len(fake.setCredentialArgsForCall)
+}
+
+func (fake *FakeIClient) SetCredentialCalls(stub func(string, string, interface{}) (credentials.Credential, error)) {
+	fake.setCredentialMutex.Lock()
+	defer fake.setCredentialMutex.Unlock()
+	fake.SetCredentialStub = stub
+}
+
+func (fake *FakeIClient) SetCredentialArgsForCall(i int) (string, string, interface{}) {
+	fake.setCredentialMutex.RLock()
+	defer fake.setCredentialMutex.RUnlock()
+	argsForCall := fake.setCredentialArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIClient) SetCredentialReturns(result1 credentials.Credential, result2 error) {
+	fake.setCredentialMutex.Lock()
+	defer fake.setCredentialMutex.Unlock()
+	fake.SetCredentialStub = nil
+	fake.setCredentialReturns = struct {
+		result1 credentials.Credential
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) SetCredentialReturnsOnCall(i int, result1 credentials.Credential, result2 error) {
+	fake.setCredentialMutex.Lock()
+	defer fake.setCredentialMutex.Unlock()
+	fake.SetCredentialStub = nil
+	if fake.setCredentialReturnsOnCall == nil {
+		fake.setCredentialReturnsOnCall = make(map[int]struct {
+			result1 credentials.Credential
+			result2 error
+		})
+	}
+	fake.setCredentialReturnsOnCall[i] = struct {
+		result1 credentials.Credential
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) SetRuntimeCreds(arg1 iaas.Provider, arg2 terraform.Outputs) error {
+	fake.setRuntimeCredsMutex.Lock()
+	ret, specificReturn := fake.setRuntimeCredsReturnsOnCall[len(fake.setRuntimeCredsArgsForCall)]
+	fake.setRuntimeCredsArgsForCall = append(fake.setRuntimeCredsArgsForCall, struct {
+		arg1 iaas.Provider
+		arg2 terraform.Outputs
+	}{arg1, arg2})
+	stub := fake.SetRuntimeCredsStub
+	fakeReturns := fake.setRuntimeCredsReturns
+	fake.recordInvocation("SetRuntimeCreds", []interface{}{arg1, arg2})
+	fake.setRuntimeCredsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) SetRuntimeCredsCallCount() int {
+	fake.setRuntimeCredsMutex.RLock()
+	defer fake.setRuntimeCredsMutex.RUnlock()
+	return len(fake.setRuntimeCredsArgsForCall)
+}
+
+func (fake *FakeIClient) SetRuntimeCredsCalls(stub func(iaas.Provider, terraform.Outputs) error) {
+	fake.setRuntimeCredsMutex.Lock()
+	defer fake.setRuntimeCredsMutex.Unlock()
+	fake.SetRuntimeCredsStub = stub
+}
+
+func (fake *FakeIClient) SetRuntimeCredsArgsForCall(i int) (iaas.Provider, terraform.Outputs) {
+	fake.setRuntimeCredsMutex.RLock()
+	defer fake.setRuntimeCredsMutex.RUnlock()
+	argsForCall := fake.setRuntimeCredsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIClient) SetRuntimeCredsReturns(result1 error) {
+	fake.setRuntimeCredsMutex.Lock()
+	defer fake.setRuntimeCredsMutex.Unlock()
+	fake.SetRuntimeCredsStub = nil
+	fake.setRuntimeCredsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) SetRuntimeCredsReturnsOnCall(i int, result1 error) {
+	fake.setRuntimeCredsMutex.Lock()
+	defer fake.setRuntimeCredsMutex.Unlock()
+	fake.SetRuntimeCredsStub = nil
+	if fake.setRuntimeCredsReturnsOnCall == nil {
+		fake.setRuntimeCredsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setRuntimeCredsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) SetSelfUpdateCreds(arg1 iaas.Provider, arg2 terraform.Outputs) error {
 	fake.setSelfUpdateCredsMutex.Lock()
 	ret, specificReturn := fake.setSelfUpdateCredsReturnsOnCall[len(fake.setSelfUpdateCredsArgsForCall)]
@@ -91,6 +401,14 @@ func (fake *FakeIClient) SetSelfUpdateCredsReturnsOnCall(i int, result1 error) {
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.findCertificatesMutex.RLock()
+	defer fake.findCertificatesMutex.RUnlock()
+	fake.getCredentialMutex.RLock()
+	defer fake.getCredentialMutex.RUnlock()
+	fake.setCredentialMutex.RLock()
+	defer fake.setCredentialMutex.RUnlock()
+	fake.setRuntimeCredsMutex.RLock()
+	defer fake.setRuntimeCredsMutex.RUnlock()
 	fake.setSelfUpdateCredsMutex.RLock()
 	defer fake.setSelfUpdateCredsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
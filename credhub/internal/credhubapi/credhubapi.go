@@ -0,0 +1,19 @@
+package credhubapi
+
+import (
+	ch "code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+// API is the part of the CredHub API that the credhub client uses, which *ch.CredHub implements
+//
+//counterfeiter:generate . API
+type API interface {
+	GetLatestVersion(name string) (credentials.Credential, error)
+	SetCredential(name, credType string, value interface{}, options ...ch.SetOption) (credentials.Credential, error)
+	SetValue(name string, value values.Value, options ...ch.SetOption) (credentials.Value, error)
+	GetAllCertificatesMetadata() ([]credentials.CertificateMetadata, error)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseCABundle checks that bundle is made up of PEM encoded certificates, as the CA bundles that the IAASs
+// publish are, and returns it without any text around the certificates
+func ParseCABundle(bundle string) (string, error) {
+	var certs []string
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return "", fmt.Errorf("CA bundle has an invalid certificate: [%v]", err)
+		}
+		certs = append(certs, strings.TrimSpace(string(pem.EncodeToMemory(block))))
+	}
+	if len(certs) == 0 {
+		return "", errors.New("CA bundle has no certificates")
+	}
+	return strings.Join(certs, "\n") + "\n", nil
+}
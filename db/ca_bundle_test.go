@@ -0,0 +1,60 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+const exampleCA = `-----BEGIN CERTIFICATE-----
+MIIBlDCCATmgAwIBAgIUHlIGD1WZQ3mDLRQvwaoEaG6rsOowCgYIKoZIzj0EAwIw
+HjEcMBoGA1UEAwwTRXhhbXBsZSBEYXRhYmFzZSBDQTAgFw0yNjEwMTYxMzAzNTFa
+GA8yMTI2MDkyMjEzMDM1MVowHjEcMBoGA1UEAwwTRXhhbXBsZSBEYXRhYmFzZSBD
+QTBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABDQUpl8XI58ldJR1kLBmCkE1OTAT
+cc89JYwwqJbfIwNbeexhbWyl4b23M1StmfkjOuRK2pq/m+h5pdvjswVjJIGjUzBR
+MB0GA1UdDgQWBBSDj9pqnsrchwWIduwbKIYWOkqgxzAfBgNVHSMEGDAWgBSDj9pq
+nsrchwWIduwbKIYWOkqgxzAPBgNVHRMBAf8EBTADAQH/MAoGCCqGSM49BAMCA0kA
+MEYCIQCucMh9OauS1viliLOoXTlWLPo6uGpiMo4mqs2T/8zpQAIhANsLaKYUyNms
+UvAOY/5B8m6xdsLMHriD6xwd0AsPTB1Q
+-----END CERTIFICATE-----`
+
+func TestParseCABundle(t *testing.T) {
+	tests := []struct {
+		name        string
+		bundle      string
+		want        string
+		expectedErr string
+	}{
+		{
+			name:   "Bundle with text around the certificates",
+			bundle: "Example Database CA\n" + exampleCA + "\n\nExample Database CA\n" + exampleCA + "\n",
+			want:   exampleCA + "\n" + exampleCA + "\n",
+		},
+		{
+			name:        "No certificates",
+			bundle:      "<html>Not Found</html>",
+			expectedErr: "CA bundle has no certificates",
+		},
+		{
+			name:        "Invalid certificate",
+			bundle:      "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+			expectedErr: "CA bundle has an invalid certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCABundle(tt.bundle)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.expectedErr) {
+					t.Errorf("ParseCABundle() error = %v, want %q", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCABundle() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCABundle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RequiredExtensions must be available on the server, as the components' schemas define functions in them
+var RequiredExtensions = []string{"plpgsql"}
+
+const healthCheckTimeout = 10 * time.Second
+
+// sslRequest asks a Postgres server to switch to TLS, as a client does before its startup message
+var sslRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// Dialer connects to the server, either directly or through a host on the server's network
+type Dialer = pq.Dialer
+
+// Target is a server whose health is checked, connecting to Database as Username
+type Target struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Database string
+	// CACert is the bundle of CAs that the server's certificate must be signed by
+	CACert string
+	// Databases are the deployment's component databases, which Username must be able to create if they are missing
+	Databases []string
+}
+
+// CheckResult is the outcome of one step of a health check. Detail is set when it passed
+type CheckResult struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+// CheckHealth checks that the target can be reached, that its certificate is signed by the target's CA, that
+// it accepts the credentials and that RequiredExtensions are available. A step is only run once those before
+// it have passed, as each depends on the last
+func CheckHealth(d Dialer, target Target) []CheckResult {
+	addr := net.JoinHostPort(target.Host, target.Port)
+	var conn *sql.DB
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	steps := []struct {
+		name  string
+		check func() (string, error)
+	}{
+		{"reachable", func() (string, error) {
+			c, err := d.DialTimeout("tcp", addr, healthCheckTimeout)
+			if err != nil {
+				return "", fmt.Errorf("failed to connect to %s: [%v]", addr, err)
+			}
+			c.Close()
+			return fmt.Sprintf("connected to %s", addr), nil
+		}},
+		{"tls", func() (string, error) {
+			return checkTLS(d, addr, target.CACert)
+		}},
+		{"credentials", func() (string, error) {
+			var err error
+			conn, err = openTarget(d, target)
+			if err != nil {
+				return "", err
+			}
+			if err = conn.Ping(); err != nil {
+				return "", fmt.Errorf("failed to connect to database %s as %s: [%v]", target.Database, target.Username, err)
+			}
+			if err = CheckExternal(conn, target.Databases); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("connected to database %s as %s", target.Database, target.Username), nil
+		}},
+		{"extensions", func() (string, error) {
+			return checkExtensions(conn)
+		}},
+	}
+
+	var results []CheckResult
+	for _, step := range steps {
+		detail, err := step.check()
+		results = append(results, CheckResult{Name: step.name, Detail: detail, Err: err})
+		if err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// checkTLS verifies the server's certificate against the CA bundle. Its hostname isn't checked, as CloudSQL's
+// certificates name the instance rather than its address
+func checkTLS(d Dialer, addr, caCert string) (string, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(caCert)) {
+		return "", errors.New("no CA certificate is stored for the database to verify its certificate with")
+	}
+
+	c, err := d.DialTimeout("tcp", addr, healthCheckTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: [%v]", addr, err)
+	}
+	defer c.Close()
+	// connections through a jumpbox don't support deadlines, and rely on the jumpbox's own timeouts instead
+	_ = c.SetDeadline(time.Now().Add(healthCheckTimeout))
+
+	if _, err = c.Write(sslRequest); err != nil {
+		return "", fmt.Errorf("failed to request TLS from %s: [%v]", addr, err)
+	}
+	reply := make([]byte, 1)
+	if _, err = io.ReadFull(c, reply); err != nil {
+		return "", fmt.Errorf("failed to request TLS from %s: [%v]", addr, err)
+	}
+	if reply[0] != 'S' {
+		return "", fmt.Errorf("%s does not accept TLS connections", addr)
+	}
+
+	var subject string
+	tlsConn := tls.Client(c, &tls.Config{
+		// the chain is verified by VerifyPeerCertificate, without the hostname
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("the server presented no certificate")
+			}
+			certs := make([]*x509.Certificate, len(rawCerts))
+			for i, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					return err
+				}
+				certs[i] = cert
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range certs[1:] {
+				intermediates.AddCert(cert)
+			}
+			subject = certs[0].Subject.String()
+			_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+			return err
+		},
+	})
+	if err = tlsConn.Handshake(); err != nil {
+		return "", fmt.Errorf("the certificate of %s is not signed by the stored CA: [%v]", addr, err)
+	}
+	return fmt.Sprintf("certificate %s is signed by the stored CA", subject), nil
+}
+
+func openTarget(d Dialer, target Target) (*sql.DB, error) {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(target.Username, target.Password),
+		Host:     net.JoinHostPort(target.Host, target.Port),
+		Path:     target.Database,
+		RawQuery: fmt.Sprintf("sslmode=require&connect_timeout=%d", int(healthCheckTimeout.Seconds())),
+	}
+	connector, err := pq.NewConnector(u.String())
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(d)
+	return sql.OpenDB(connector), nil
+}
+
+func checkExtensions(conn *sql.DB) (string, error) {
+	rows, err := conn.Query("SELECT name FROM pg_available_extensions")
+	if err != nil {
+		return "", fmt.Errorf("failed to list the extensions available on the server: [%v]", err)
+	}
+	defer rows.Close()
+	available := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return "", err
+		}
+		available[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for _, name := range RequiredExtensions {
+		if !available[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("extensions %s are not available on the server", strings.Join(missing, ", "))
+	}
+	return fmt.Sprintf("%s available", strings.Join(RequiredExtensions, ", ")), nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type netDialer struct{}
+
+func (netDialer) Dial(network, address string) (net.Conn, error) {
+	return net.Dial(network, address)
+}
+
+func (netDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, address, timeout)
+}
+
+func TestCheckTLS(t *testing.T) {
+	ca, caKey := generateCert(t, "Example Database CA", nil, nil)
+	otherCA, _ := generateCert(t, "Other CA", nil, nil)
+	leaf, leafKey := generateCert(t, "db.example.com", ca, caKey)
+	addr := serveTLS(t, tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: leafKey})
+
+	detail, err := checkTLS(netDialer{}, addr, encodeCert(ca))
+	if err != nil {
+		t.Fatalf("checkTLS() error = %v", err)
+	}
+	if want := "certificate CN=db.example.com is signed by the stored CA"; detail != want {
+		t.Errorf("checkTLS() = %q, want %q", detail, want)
+	}
+
+	_, err = checkTLS(netDialer{}, addr, encodeCert(otherCA))
+	if err == nil || !strings.Contains(err.Error(), "is not signed by the stored CA") {
+		t.Errorf("checkTLS() error = %v, want the certificate to be rejected", err)
+	}
+
+	_, err = checkTLS(netDialer{}, addr, "")
+	if err == nil || err.Error() != "no CA certificate is stored for the database to verify its certificate with" {
+		t.Errorf("checkTLS() error = %v, want a missing CA to be reported", err)
+	}
+}
+
+func TestCheckHealthUnreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	results := CheckHealth(netDialer{}, Target{Host: host, Port: port})
+	if len(results) != 1 || results[0].Name != "reachable" || results[0].Err == nil {
+		t.Errorf("CheckHealth() = %+v, want only a failed reachable check", results)
+	}
+}
+
+func generateCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func encodeCert(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// serveTLS accepts connections as a Postgres server does, switching to TLS when asked
+func serveTLS(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				request := make([]byte, len(sslRequest))
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return
+				}
+				if _, err := conn.Write([]byte("S")); err != nil {
+					return
+				}
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+				_ = tlsConn.Handshake()
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// IAMRole is the role Concourse, UAA and CredHub log into RDS as when they authenticate with IAM. It has no
+// password, so the only way to log in as it is with an authentication token signed by an IAM identity
+const IAMRole = "control_tower_iam"
+
+// GrantIAMRole creates IAMRole and hands it the named databases, so that the components own what they create
+// in them. The role connected as is made a member of IAMRole, so that it can still manage the databases
+func GrantIAMRole(conn *sql.DB, names []string) error {
+	var exists bool
+	if err := conn.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", IAMRole).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up the %s role: [%v]", IAMRole, err)
+	}
+	var statements []string
+	if !exists {
+		statements = append(statements, "CREATE ROLE "+IAMRole+" WITH LOGIN")
+	}
+	statements = append(statements, "GRANT rds_iam TO "+IAMRole, "GRANT "+IAMRole+" TO CURRENT_USER")
+	for _, name := range names {
+		statements = append(statements, "ALTER DATABASE "+name+" OWNER TO "+IAMRole)
+	}
+	for _, statement := range statements {
+		if _, err := conn.Exec(statement); err != nil {
+			return fmt.Errorf("failed to grant the %s role its databases: %s: [%v]", IAMRole, statement, err)
+		}
+	}
+	return nil
+}
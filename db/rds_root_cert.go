@@ -1,5 +1,7 @@
 package db
 
+import "strings"
+
 // RDSRootCert is the root cert for all RDS instances
 // http://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/CHAP_PostgreSQL.html#PostgreSQL.Concepts.General.SSL
 const RDSRootCert = `postgres_ca_cert: |
@@ -3108,3 +3110,12 @@ const RDSRootCert = `postgres_ca_cert: |
   rM2p0kk=
   -----END CERTIFICATE-----
 `
+
+// RDSRootCA is the certificate of RDSRootCert, which is held as a vars file for the director
+func RDSRootCA() string {
+	lines := strings.Split(strings.TrimPrefix(RDSRootCert, "postgres_ca_cert: |\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "  ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRDSRootCA(t *testing.T) {
+	got := RDSRootCA()
+	if !strings.HasPrefix(got, "-----BEGIN CERTIFICATE-----\n") {
+		t.Errorf("RDSRootCA() = %q, want a PEM certificate", got)
+	}
+}
@@ -0,0 +1,301 @@
+package deployarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// FormatVersion is incremented whenever the layout of an archive changes incompatibly
+	FormatVersion = 1
+
+	metadataName     = "metadata.json"
+	encryptedSuffix  = ".enc"
+	saltLength       = 32
+	keyLength        = 32
+	scryptN          = 1 << 15
+	scryptR          = 8
+	scryptP          = 1
+	minPassphraseLen = 12
+)
+
+// ErrDecrypt is returned when an archive can't be decrypted, because the passphrase is wrong or
+// the archive has been tampered with
+var ErrDecrypt = errors.New("unable to decrypt the archive, either the passphrase is incorrect or the archive is corrupt")
+
+// Metadata describes an archive, and is stored unencrypted so that it can be inspected without the passphrase
+type Metadata struct {
+	FormatVersion       int       `json:"format_version"`
+	ControlTowerVersion string    `json:"control_tower_version"`
+	IAAS                string    `json:"iaas"`
+	Region              string    `json:"region"`
+	Namespace           string    `json:"namespace"`
+	Deployment          string    `json:"deployment"`
+	ExportedAt          time.Time `json:"exported_at"`
+	Salt                []byte    `json:"salt"`
+	Files               []File    `json:"files"`
+	// Seal authenticates the rest of the metadata, so that file digests can't be altered
+	Seal []byte `json:"seal"`
+}
+
+// File is an entry in an archive
+type File struct {
+	Name      string `json:"name"`
+	SHA256    string `json:"sha256"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// Archive is the decrypted contents of a deployment archive
+type Archive struct {
+	Metadata Metadata
+	// Secret files are encrypted with the passphrase
+	Secret map[string][]byte
+	// Public files are stored in plain text
+	Public map[string][]byte
+}
+
+// ValidatePassphrase returns an error if a passphrase is too short to protect an archive
+func ValidatePassphrase(passphrase string) error {
+	if len(passphrase) < minPassphraseLen {
+		return fmt.Errorf("the archive passphrase must be at least %d characters long", minPassphraseLen)
+	}
+	return nil
+}
+
+// Digest returns the hex encoded SHA256 of contents, as recorded for each file
+func Digest(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write writes a as a gzipped tarball to w, encrypting its secret files with a key derived from passphrase
+func Write(w io.Writer, a Archive, passphrase string) error {
+	if err := ValidatePassphrase(passphrase); err != nil {
+		return err
+	}
+
+	metadata := a.Metadata
+	metadata.FormatVersion = FormatVersion
+	metadata.Salt = make([]byte, saltLength)
+	if _, err := rand.Read(metadata.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, metadata.Salt)
+	if err != nil {
+		return err
+	}
+
+	entries := map[string][]byte{}
+	metadata.Files = nil
+	for _, name := range sortedNames(a.Secret) {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return err
+		}
+		// The file name is authenticated so that encrypted files can't be swapped around
+		entries[name+encryptedSuffix] = gcm.Seal(nonce, nonce, a.Secret[name], []byte(name))
+		metadata.Files = append(metadata.Files, File{Name: name, SHA256: Digest(a.Secret[name]), Encrypted: true})
+	}
+	for _, name := range sortedNames(a.Public) {
+		if _, ok := a.Secret[name]; ok {
+			return fmt.Errorf("%s can't be both a secret and a public file", name)
+		}
+		entries[name] = a.Public[name]
+		metadata.Files = append(metadata.Files, File{Name: name, SHA256: Digest(a.Public[name])})
+	}
+
+	metadata.Seal = nil
+	unsealed, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+	metadata.Seal = gcm.Seal(nonce, nonce, nil, unsealed)
+
+	metadataBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err = writeEntry(tw, metadataName, metadataBytes, metadata.ExportedAt); err != nil {
+		return err
+	}
+	for _, name := range sortedNames(entries) {
+		if err = writeEntry(tw, name, entries[name], metadata.ExportedAt); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads an archive written by Write, decrypting its secret files with passphrase and checking
+// every file against the digest recorded when it was exported
+func Read(r io.Reader, passphrase string) (Archive, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	metadataBytes, ok := entries[metadataName]
+	if !ok {
+		return Archive{}, fmt.Errorf("not a deployment archive, %s is missing", metadataName)
+	}
+	var metadata Metadata
+	if err = json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return Archive{}, fmt.Errorf("error reading archive metadata: [%v]", err)
+	}
+	if metadata.FormatVersion != FormatVersion {
+		return Archive{}, fmt.Errorf("archive format version %d is not supported by this version of control-tower, which reads version %d", metadata.FormatVersion, FormatVersion)
+	}
+
+	gcm, err := newGCM(passphrase, metadata.Salt)
+	if err != nil {
+		return Archive{}, err
+	}
+	seal := metadata.Seal
+	metadata.Seal = nil
+	unsealed, err := json.Marshal(metadata)
+	if err != nil {
+		return Archive{}, err
+	}
+	if len(seal) < gcm.NonceSize() {
+		return Archive{}, ErrDecrypt
+	}
+	if _, err = gcm.Open(nil, seal[:gcm.NonceSize()], seal[gcm.NonceSize():], unsealed); err != nil {
+		return Archive{}, ErrDecrypt
+	}
+
+	a := Archive{Metadata: metadata, Secret: map[string][]byte{}, Public: map[string][]byte{}}
+	for _, file := range metadata.Files {
+		var contents []byte
+		if file.Encrypted {
+			sealed, found := entries[file.Name+encryptedSuffix]
+			if !found || len(sealed) < gcm.NonceSize() {
+				return Archive{}, fmt.Errorf("archive is missing %s", file.Name)
+			}
+			contents, err = gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(file.Name))
+			if err != nil {
+				return Archive{}, ErrDecrypt
+			}
+			a.Secret[file.Name] = contents
+		} else {
+			var found bool
+			contents, found = entries[file.Name]
+			if !found {
+				return Archive{}, fmt.Errorf("archive is missing %s", file.Name)
+			}
+			a.Public[file.Name] = contents
+		}
+		if Digest(contents) != file.SHA256 {
+			return Archive{}, fmt.Errorf("%s does not match the digest recorded when it was exported", file.Name)
+		}
+	}
+	return a, nil
+}
+
+// ReadFile reads the archive at path
+func ReadFile(path, passphrase string) (Archive, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Read(bytes.NewReader(contents), passphrase)
+}
+
+// WriteFile writes a to a new file at path, readable only by its owner. An existing file is never overwritten
+func WriteFile(path string, a Archive, passphrase string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err = Write(f, a, passphrase); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeEntry(tw *tar.Writer, name string, contents []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(contents)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(contents)
+	return err
+}
+
+func readEntries(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a deployment archive: [%v]", err)
+	}
+	defer gz.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading deployment archive: [%v]", err)
+		}
+		var buf bytes.Buffer
+		if _, err = io.Copy(&buf, tr); err != nil {
+			return nil, err
+		}
+		entries[header.Name] = buf.Bytes()
+	}
+}
+
+func sortedNames(files map[string][]byte) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,137 @@
+package deployarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const passphrase = "correct horse battery staple"
+
+func testArchive() Archive {
+	return Archive{
+		Metadata: Metadata{
+			ControlTowerVersion: "0.1.0",
+			IAAS:                "AWS",
+			Region:              "eu-west-1",
+			Deployment:          "control-tower-lab",
+			ExportedAt:          time.Unix(1700000000, 0).UTC(),
+		},
+		Secret: map[string][]byte{
+			"config.json":        []byte(`{"concourse_password":"hunter2hunter2"}`),
+			"director-creds.yml": []byte("admin_password: s3cr3t\n"),
+		},
+		Public: map[string][]byte{
+			"pins/versions.json": []byte(`{"concourse":"7.9.1"}`),
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive(), passphrase); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("hunter2")) {
+		t.Fatal("secret files must not be readable without the passphrase")
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), passphrase)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := testArchive()
+	for name, contents := range want.Secret {
+		if !bytes.Equal(got.Secret[name], contents) {
+			t.Errorf("Secret[%s] = %q, want %q", name, got.Secret[name], contents)
+		}
+	}
+	for name, contents := range want.Public {
+		if !bytes.Equal(got.Public[name], contents) {
+			t.Errorf("Public[%s] = %q, want %q", name, got.Public[name], contents)
+		}
+	}
+	if got.Metadata.Deployment != "control-tower-lab" || got.Metadata.FormatVersion != FormatVersion {
+		t.Errorf("Metadata = %+v", got.Metadata)
+	}
+}
+
+func TestReadWrongPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive(), passphrase); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Read(&buf, "incorrect passphrase")
+	if err != ErrDecrypt {
+		t.Errorf("Read() error = %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestReadTamperedMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive(), passphrase); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readEntries(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metadata Metadata
+	if err = json.Unmarshal(entries[metadataName], &metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata.Files[len(metadata.Files)-1].SHA256 = Digest([]byte(`{"concourse":"6.0.0"}`))
+	entries[metadataName], _ = json.Marshal(metadata)
+	entries["pins/versions.json"] = []byte(`{"concourse":"6.0.0"}`)
+
+	_, err = Read(rewrite(t, entries), passphrase)
+	if err != ErrDecrypt {
+		t.Errorf("Read() error = %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestReadTamperedPublicFile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive(), passphrase); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readEntries(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries["pins/versions.json"] = []byte(`{"concourse":"6.0.0"}`)
+
+	_, err = Read(rewrite(t, entries), passphrase)
+	if err == nil || !strings.Contains(err.Error(), "does not match the digest") {
+		t.Errorf("Read() error = %v, want a digest mismatch", err)
+	}
+}
+
+func TestWriteShortPassphrase(t *testing.T) {
+	if err := Write(io.Discard, testArchive(), "short"); err == nil {
+		t.Error("expected a short passphrase to be rejected")
+	}
+}
+
+func rewrite(t *testing.T, entries map[string][]byte) io.Reader {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedNames(entries) {
+		if err := writeEntry(tw, name, entries[name], time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return &buf
+}
@@ -0,0 +1,261 @@
+package deploylock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// Filename is the name of the lock in the config bucket
+const Filename = "deploy.lock"
+
+// DefaultStaleAfter is how long a holder can go without a heartbeat before its lock can be taken over
+const DefaultStaleAfter = 15 * time.Minute
+
+var now = time.Now
+
+// Stages in which the holder runs terraform or the bosh CLI on its own machine, where nothing but
+// the holder itself can tell whether that work is still running
+const (
+	StageTerraform = "terraform"
+	StageBosh      = "bosh"
+)
+
+// Files reads and writes files in a bucket, as iaas.Provider does. WriteFileIfVersion only writes
+// when the file is still at version, or doesn't exist when version is empty
+type Files interface {
+	HasFile(bucket, path string) (bool, error)
+	LoadFileVersion(bucket, path string) ([]byte, string, error)
+	WriteFileIfVersion(bucket, path string, contents []byte, version string) (string, bool, error)
+	DeleteFile(bucket, path string) error
+}
+
+// Holder identifies the control-tower process holding the lock
+type Holder struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	Acquired  time.Time `json:"acquired"`
+	Heartbeat time.Time `json:"heartbeat"`
+	Stage     string    `json:"stage,omitempty"`
+}
+
+func (h Holder) String() string {
+	return fmt.Sprintf("%s@%s (pid %d) running %s since %s", h.User, h.Host, h.PID, h.Command, h.Acquired.Format(time.RFC3339))
+}
+
+// Options control when a lock held by another process can be taken over
+type Options struct {
+	// StaleAfter is how long the holder can go without a heartbeat before its lock is stale
+	StaleAfter time.Duration
+	// InFlight reports whether work started by a stale holder, such as a BOSH task, is still running
+	InFlight func() (bool, error)
+	// Stderr receives a warning naming the previous holder when a stale lock is taken over
+	Stderr io.Writer
+}
+
+// Lock is a held deployment lock, kept alive by a heartbeat until it is released
+type Lock struct {
+	files    Files
+	bucket   string
+	mu       sync.Mutex
+	holder   Holder
+	version  string
+	lost     bool
+	stderr   io.Writer
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Acquire takes the deployment lock in bucket for command. A lock held by another process is only
+// taken over once it has sent no heartbeat for opts.StaleAfter, it wasn't running terraform or
+// the bosh CLI, and opts.InFlight reports that none of its BOSH tasks are still running. The
+// lock is written on condition that nobody else has written it since it was read, so of two
+// processes racing for it only one wins
+func Acquire(files Files, bucket, command string, opts Options) (*Lock, error) {
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = DefaultStaleAfter
+	}
+
+	current, version, held, err := load(files, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error reading deployment lock: [%v]", err)
+	}
+	if held {
+		silence := now().Sub(current.Heartbeat)
+		if silence < opts.StaleAfter {
+			return nil, fmt.Errorf("failed to acquire lock on deployment, held by %s", current)
+		}
+		if current.Stage != "" {
+			return nil, fmt.Errorf("failed to acquire lock on deployment, held by %s: no heartbeat for %s while it was running %s on %s, which may still be running. Once you are sure it has stopped, delete %s from the config bucket", current, silence.Round(time.Second), current.Stage, current.Host, Filename)
+		}
+		if opts.InFlight != nil {
+			inFlight, err1 := opts.InFlight()
+			if err1 != nil {
+				return nil, fmt.Errorf("failed to acquire lock on deployment, held by %s: unable to check for work in progress: [%v]", current, err1)
+			}
+			if inFlight {
+				return nil, fmt.Errorf("failed to acquire lock on deployment, held by %s: no heartbeat for %s but work is still in progress", current, silence.Round(time.Second))
+			}
+		}
+		if opts.Stderr != nil {
+			fmt.Fprintf(opts.Stderr, "WARNING: taking over the deployment lock held by %s, which has sent no heartbeat for %s\n", current, silence.Round(time.Second))
+		}
+	}
+
+	l := &Lock{
+		files:   files,
+		bucket:  bucket,
+		holder:  newHolder(command),
+		version: version,
+		stderr:  opts.Stderr,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	won, err := l.write()
+	if err != nil {
+		return nil, fmt.Errorf("error writing deployment lock: [%v]", err)
+	}
+	if !won {
+		return nil, errors.New("failed to acquire lock on deployment, another control-tower process took it first")
+	}
+	go l.heartbeat(opts.StaleAfter / 3)
+	return l, nil
+}
+
+// SetStage records that the holder is starting work that runs on this machine, or has finished it
+// when stage is empty, so that the lock isn't taken over from under it should it stop heartbeating
+func (l *Lock) SetStage(stage string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holder.Stage = stage
+	l.holder.Heartbeat = now().UTC()
+	won, err := l.writeLocked()
+	if err != nil {
+		return fmt.Errorf("error writing deployment lock: [%v]", err)
+	}
+	if !won {
+		return errors.New("deployment lock was taken over by another control-tower process")
+	}
+	return nil
+}
+
+// Stop ends the heartbeat without removing the lock, for when the bucket itself is being deleted
+func (l *Lock) Stop() {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+}
+
+// Release ends the heartbeat and removes the lock, unless another process has since taken it over
+func (l *Lock) Release() error {
+	l.Stop()
+	if l.lost {
+		return nil
+	}
+	current, _, held, err := load(l.files, l.bucket)
+	if err != nil {
+		return err
+	}
+	if !held || current.ID != l.holder.ID {
+		return nil
+	}
+	return l.files.DeleteFile(l.bucket, Filename)
+}
+
+func (l *Lock) heartbeat(interval time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			l.holder.Heartbeat = now().UTC()
+			// A missed heartbeat is retried on the next tick
+			won, err := l.writeLocked()
+			l.mu.Unlock()
+			if err == nil && !won {
+				if l.stderr != nil {
+					fmt.Fprintln(l.stderr, "WARNING: the deployment lock was taken over by another control-tower process")
+				}
+				return
+			}
+		}
+	}
+}
+
+func (l *Lock) write() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writeLocked()
+}
+
+// writeLocked writes the holder on condition that the lock is still at the version last written,
+// marking the lock as lost if it isn't. l.mu must be held
+func (l *Lock) writeLocked() (bool, error) {
+	if l.lost {
+		return false, nil
+	}
+	contents, err := json.Marshal(l.holder)
+	if err != nil {
+		return false, err
+	}
+	version, won, err := l.files.WriteFileIfVersion(l.bucket, Filename, contents, l.version)
+	if err != nil {
+		return false, err
+	}
+	if !won {
+		l.lost = true
+		return false, nil
+	}
+	l.version = version
+	return true, nil
+}
+
+func load(files Files, bucket string) (Holder, string, bool, error) {
+	exists, err := files.HasFile(bucket, Filename)
+	if err != nil || !exists {
+		return Holder{}, "", false, err
+	}
+	contents, version, err := files.LoadFileVersion(bucket, Filename)
+	if err != nil {
+		return Holder{}, "", false, err
+	}
+	var h Holder
+	if err = json.Unmarshal(contents, &h); err != nil {
+		return Holder{}, "", false, err
+	}
+	return h, version, true, nil
+}
+
+func newHolder(command string) Holder {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		username = u.Username
+	}
+	host, _ := os.Hostname()
+	t := now().UTC()
+	return Holder{
+		ID:        hex.EncodeToString(id),
+		User:      username,
+		Host:      host,
+		PID:       os.Getpid(),
+		Command:   command,
+		Acquired:  t,
+		Heartbeat: t,
+	}
+}
@@ -0,0 +1,222 @@
+package deploylock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memFiles struct {
+	mu       sync.Mutex
+	files    map[string][]byte
+	versions map[string]int
+	// beforeWrite runs ahead of every conditional write, standing in for a racing process
+	beforeWrite func()
+}
+
+func (m *memFiles) HasFile(bucket, path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[bucket+"/"+path]
+	return ok, nil
+}
+
+func (m *memFiles) LoadFileVersion(bucket, path string) ([]byte, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files[bucket+"/"+path], strconv.Itoa(m.versions[bucket+"/"+path]), nil
+}
+
+func (m *memFiles) WriteFileIfVersion(bucket, path string, contents []byte, version string) (string, bool, error) {
+	if m.beforeWrite != nil {
+		m.beforeWrite()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := bucket + "/" + path
+	if m.versions == nil {
+		m.versions = map[string]int{}
+	}
+	_, exists := m.files[key]
+	if (version == "" && exists) || (version != "" && (!exists || version != strconv.Itoa(m.versions[key]))) {
+		return "", false, nil
+	}
+	m.files[key] = contents
+	m.versions[key]++
+	return strconv.Itoa(m.versions[key]), true, nil
+}
+
+func (m *memFiles) put(key string, contents []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.versions == nil {
+		m.versions = map[string]int{}
+	}
+	m.files[key] = contents
+	m.versions[key]++
+}
+
+func (m *memFiles) DeleteFile(bucket, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, bucket+"/"+path)
+	return nil
+}
+
+func heldBy(t *testing.T, heartbeat time.Time) *memFiles {
+	return heldIn(t, heartbeat, "")
+}
+
+func heldIn(t *testing.T, heartbeat time.Time, stage string) *memFiles {
+	contents, err := json.Marshal(Holder{ID: "other", User: "alice", Host: "laptop", PID: 42, Command: "deploy", Acquired: heartbeat, Heartbeat: heartbeat, Stage: stage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &memFiles{files: map[string][]byte{"bucket/" + Filename: contents}, versions: map[string]int{"bucket/" + Filename: 1}}
+}
+
+func TestAcquire(t *testing.T) {
+	noWork := func() (bool, error) { return false, nil }
+	tests := []struct {
+		name        string
+		files       *memFiles
+		inFlight    func() (bool, error)
+		wantErr     string
+		wantWarning bool
+	}{
+		{
+			name:     "unlocked",
+			files:    &memFiles{files: map[string][]byte{}},
+			inFlight: noWork,
+		},
+		{
+			name:     "held with a recent heartbeat",
+			files:    heldBy(t, time.Now().Add(-time.Minute)),
+			inFlight: noWork,
+			wantErr:  "failed to acquire lock on deployment, held by alice@laptop (pid 42) running deploy since",
+		},
+		{
+			name:     "stale with BOSH work in progress",
+			files:    heldBy(t, time.Now().Add(-time.Hour)),
+			inFlight: func() (bool, error) { return true, nil },
+			wantErr:  "no heartbeat for 1h0m0s but work is still in progress",
+		},
+		{
+			name:     "stale and unable to check for work in progress",
+			files:    heldBy(t, time.Now().Add(-time.Hour)),
+			inFlight: func() (bool, error) { return false, errors.New("director unreachable") },
+			wantErr:  "unable to check for work in progress: [director unreachable]",
+		},
+		{
+			name:     "stale while running terraform",
+			files:    heldIn(t, time.Now().Add(-time.Hour), StageTerraform),
+			inFlight: noWork,
+			wantErr:  "while it was running terraform on laptop, which may still be running",
+		},
+		{
+			name:        "stale and idle",
+			files:       heldBy(t, time.Now().Add(-time.Hour)),
+			inFlight:    noWork,
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			l, err := Acquire(tt.files, "bucket", "deploy", Options{StaleAfter: 15 * time.Minute, InFlight: tt.inFlight, Stderr: stderr})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Acquire() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(stderr.String(), "taking over the deployment lock held by alice@laptop"); got != tt.wantWarning {
+				t.Errorf("unexpected warning %q", stderr.String())
+			}
+			if err = l.Release(); err != nil {
+				t.Fatal(err)
+			}
+			if held, _ := tt.files.HasFile("bucket", Filename); held {
+				t.Error("expected the lock to be released")
+			}
+		})
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	files := &memFiles{files: map[string][]byte{}}
+	l, err := Acquire(files, "bucket", "deploy", Options{StaleAfter: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, _, _ := load(files, "bucket")
+	time.Sleep(50 * time.Millisecond)
+	l.Stop()
+	latest, _, _, _ := load(files, "bucket")
+	if !latest.Heartbeat.After(first.Heartbeat) {
+		t.Errorf("expected heartbeat after %v, got %v", first.Heartbeat, latest.Heartbeat)
+	}
+}
+
+func TestReleaseKeepsLockTakenOverByAnotherProcess(t *testing.T) {
+	files := &memFiles{files: map[string][]byte{}}
+	l, err := Acquire(files, "bucket", "deploy", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Stop()
+	other := heldBy(t, time.Now())
+	files.put("bucket/"+Filename, other.files["bucket/"+Filename])
+	if err = l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if held, _ := files.HasFile("bucket", Filename); !held {
+		t.Error("expected the other process's lock to be kept")
+	}
+}
+
+func TestAcquireLosesRace(t *testing.T) {
+	files := &memFiles{files: map[string][]byte{}}
+	other := heldBy(t, time.Now())
+	files.beforeWrite = func() {
+		files.beforeWrite = nil
+		files.put("bucket/"+Filename, other.files["bucket/"+Filename])
+	}
+	_, err := Acquire(files, "bucket", "deploy", Options{})
+	if err == nil || !strings.Contains(err.Error(), "another control-tower process took it first") {
+		t.Fatalf("Acquire() error = %v, want lost race", err)
+	}
+	current, _, _, _ := load(files, "bucket")
+	if current.ID != "other" {
+		t.Errorf("expected the winner's lock to be kept, got %v", current)
+	}
+}
+
+func TestSetStage(t *testing.T) {
+	files := &memFiles{files: map[string][]byte{}}
+	l, err := Acquire(files, "bucket", "deploy", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Stop()
+	if err = l.SetStage(StageTerraform); err != nil {
+		t.Fatal(err)
+	}
+	current, _, _, _ := load(files, "bucket")
+	if current.Stage != StageTerraform {
+		t.Errorf("expected stage %q, got %q", StageTerraform, current.Stage)
+	}
+
+	files.put("bucket/"+Filename, heldBy(t, time.Now()).files["bucket/"+Filename])
+	if err = l.SetStage(""); err == nil {
+		t.Error("expected an error once the lock has been taken over")
+	}
+}
@@ -0,0 +1,153 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	azureLoginEndpoint      = "https://login.microsoftonline.com"
+	azureManagementEndpoint = "https://management.azure.com"
+	azureDNSAPIVersion      = "2018-05-01"
+)
+
+// AzureCredentials identify the service principal and the resource group holding the DNS zone
+type AzureCredentials struct {
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+	SubscriptionID string
+	ResourceGroup  string
+}
+
+// AzureProvider manages records in a zone hosted by Azure DNS
+type AzureProvider struct {
+	zone               string
+	creds              AzureCredentials
+	loginEndpoint      string
+	managementEndpoint string
+	client             *http.Client
+}
+
+// NewAzureProvider returns a provider for zone, authenticating as a service principal
+func NewAzureProvider(zone string, creds AzureCredentials) (*AzureProvider, error) {
+	if creds.TenantID == "" || creds.ClientID == "" || creds.ClientSecret == "" || creds.SubscriptionID == "" || creds.ResourceGroup == "" {
+		return nil, errors.New("AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_SUBSCRIPTION_ID and AZURE_DNS_RESOURCE_GROUP must be set to manage DNS records with Azure DNS")
+	}
+	return &AzureProvider{
+		zone:               strings.TrimSuffix(zone, "."),
+		creds:              creds,
+		loginEndpoint:      azureLoginEndpoint,
+		managementEndpoint: azureManagementEndpoint,
+		client:             http.DefaultClient,
+	}, nil
+}
+
+// SetRecord creates or replaces the record set
+func (a *AzureProvider) SetRecord(domain, recordType, value string) error {
+	properties := map[string]interface{}{"TTL": 60}
+	switch recordType {
+	case "A":
+		properties["ARecords"] = []map[string]string{{"ipv4Address": value}}
+	case "TXT":
+		properties["TXTRecords"] = []map[string][]string{{"value": {value}}}
+	case "CNAME":
+		properties["CNAMERecord"] = map[string]string{"cname": value}
+	default:
+		return fmt.Errorf("unsupported Azure DNS record type %s", recordType)
+	}
+	status, body, err := a.do(http.MethodPut, domain, recordType, map[string]interface{}{"properties": properties})
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return fmt.Errorf("error setting Azure DNS %s record for %s: [%d %s]", recordType, domain, status, body)
+	}
+	return nil
+}
+
+// DeleteRecord deletes the record set, succeeding if it doesn't exist
+func (a *AzureProvider) DeleteRecord(domain, recordType string) error {
+	status, body, err := a.do(http.MethodDelete, domain, recordType, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return fmt.Errorf("error deleting Azure DNS %s record for %s: [%d %s]", recordType, domain, status, body)
+	}
+	return nil
+}
+
+// relativeName is the name of the record set within the zone, where @ is the zone apex
+func (a *AzureProvider) relativeName(domain string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == a.zone {
+		return "@"
+	}
+	return strings.TrimSuffix(domain, "."+a.zone)
+}
+
+func (a *AzureProvider) do(method, domain, recordType string, payload interface{}) (int, string, error) {
+	token, err := a.token()
+	if err != nil {
+		return 0, "", err
+	}
+	var reqBody io.Reader
+	if payload != nil {
+		contents, err1 := json.Marshal(payload)
+		if err1 != nil {
+			return 0, "", err1
+		}
+		reqBody = bytes.NewReader(contents)
+	}
+	recordSetURL := fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/dnsZones/%s/%s/%s?api-version=%s",
+		a.managementEndpoint, a.creds.SubscriptionID, a.creds.ResourceGroup, a.zone, recordType, a.relativeName(domain), azureDNSAPIVersion)
+	req, err := http.NewRequest(method, recordSetURL, reqBody)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("error calling Azure DNS API: [%v]", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+	return resp.StatusCode, strings.TrimSpace(string(body)), nil
+}
+
+// token fetches an access token for the service principal with the client credentials grant
+func (a *AzureProvider) token() (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {a.creds.ClientID},
+		"client_secret": {a.creds.ClientSecret},
+		"scope":         {azureManagementEndpoint + "/.default"},
+	}
+	resp, err := a.client.PostForm(fmt.Sprintf("%s/%s/oauth2/v2.0/token", a.loginEndpoint, a.creds.TenantID), form)
+	if err != nil {
+		return "", fmt.Errorf("error authenticating with Azure: [%v]", err)
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("error authenticating with Azure: [%v]", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("error authenticating with Azure: [%d %s]", resp.StatusCode, token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
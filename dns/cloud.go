@@ -0,0 +1,42 @@
+package dns
+
+import "fmt"
+
+// CloudRecords manages records in the zones hosted by the IAAS, as iaas.Provider does
+type CloudRecords interface {
+	FindLongestMatchingHostedZone(domain string) (string, string, error)
+	SetDNSRecord(zoneID, name, recordType, value string) error
+	DeleteDNSRecord(zoneID, name, recordType string) error
+}
+
+// CloudProvider manages records in Route53 or Cloud DNS, in whichever hosted zone is the closest
+// match for each record
+type CloudProvider struct {
+	records CloudRecords
+}
+
+// NewCloudProvider returns a provider for the zones hosted by the IAAS
+func NewCloudProvider(records CloudRecords) (*CloudProvider, error) {
+	if records == nil {
+		return nil, fmt.Errorf("--dns %s needs the IAAS to manage records", Cloud)
+	}
+	return &CloudProvider{records: records}, nil
+}
+
+// SetRecord creates or replaces the record of the given type for domain
+func (c *CloudProvider) SetRecord(domain, recordType, value string) error {
+	_, zoneID, err := c.records.FindLongestMatchingHostedZone(domain)
+	if err != nil {
+		return err
+	}
+	return c.records.SetDNSRecord(zoneID, domain, recordType, value)
+}
+
+// DeleteRecord deletes the record of the given type for domain, if there is one
+func (c *CloudProvider) DeleteRecord(domain, recordType string) error {
+	_, zoneID, err := c.records.FindLongestMatchingHostedZone(domain)
+	if err != nil {
+		return err
+	}
+	return c.records.DeleteDNSRecord(zoneID, domain, recordType)
+}
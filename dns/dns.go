@@ -0,0 +1,100 @@
+package dns
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+)
+
+// Names of the DNS providers that can be passed to --dns
+const (
+	// Cloud uses Route53 on AWS or Cloud DNS on GCP, managed by terraform alongside the rest of the infrastructure
+	Cloud  = "cloud"
+	NS1    = "ns1"
+	Azure  = "azure"
+	Manual = "manual"
+)
+
+// Providers contains the valid values for the --dns flag
+var Providers = []string{Cloud, NS1, Azure, Manual}
+
+// Provider manages records in a DNS zone
+type Provider interface {
+	// SetRecord creates or replaces the record of the given type, such as A or TXT, for domain
+	SetRecord(domain, recordType, value string) error
+	// DeleteRecord deletes the record of the given type for domain, if there is one
+	DeleteRecord(domain, recordType string) error
+}
+
+// Options are what the providers need beyond their credentials
+type Options struct {
+	// Cloud manages the records in zones hosted by the IAAS
+	Cloud CloudRecords
+	// Stdin and Stdout are where Manual prompts for and confirms each record
+	Stdin  io.Reader
+	Stdout io.Writer
+	// NonInteractive stops Manual waiting for confirmations that nobody is there to give
+	NonInteractive bool
+}
+
+// New returns the named provider for zone, reading credentials from the environment. Manual
+// prompts on opts.Stdout and waits on opts.Stdin for the operator to confirm each record
+func New(name, zone string, opts Options) (Provider, error) {
+	switch name {
+	case Cloud:
+		return NewCloudProvider(opts.Cloud)
+	case NS1:
+		return NewNS1Provider(zone, os.Getenv("NS1_API_KEY"))
+	case Azure:
+		return NewAzureProvider(zone, AzureCredentials{
+			TenantID:       os.Getenv("AZURE_TENANT_ID"),
+			ClientID:       os.Getenv("AZURE_CLIENT_ID"),
+			ClientSecret:   os.Getenv("AZURE_CLIENT_SECRET"),
+			SubscriptionID: os.Getenv("AZURE_SUBSCRIPTION_ID"),
+			ResourceGroup:  os.Getenv("AZURE_DNS_RESOURCE_GROUP"),
+		})
+	case Manual:
+		provider := NewManualProvider(opts.Stdin, opts.Stdout)
+		provider.nonInteractive = opts.NonInteractive
+		return provider, nil
+	}
+	return nil, fmt.Errorf("unknown DNS provider: `%s`. Valid providers are: %v", name, Providers)
+}
+
+// InZone reports whether domain is zone or one of its subdomains
+func InZone(domain, zone string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+	return domain == zone || strings.HasSuffix(domain, "."+zone)
+}
+
+// ACMEProvider answers Let's Encrypt DNS-01 challenges by creating TXT records with provider
+func ACMEProvider(provider Provider) *ACMEChallenge {
+	return &ACMEChallenge{provider: provider}
+}
+
+// ACMEChallenge adapts a Provider to lego's challenge.Provider
+type ACMEChallenge struct {
+	provider Provider
+}
+
+// Present creates the TXT record for a DNS-01 challenge
+func (a *ACMEChallenge) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	return a.provider.SetRecord(dns01.UnFqdn(fqdn), "TXT", value)
+}
+
+// CleanUp deletes the TXT record for a DNS-01 challenge
+func (a *ACMEChallenge) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	return a.provider.DeleteRecord(dns01.UnFqdn(fqdn), "TXT")
+}
+
+// Timeout allows for the same propagation delay as the Route53 and Cloud DNS challenges
+func (a *ACMEChallenge) Timeout() (timeout, interval time.Duration) {
+	return 10 * time.Minute, 30 * time.Second
+}
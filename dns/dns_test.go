@@ -0,0 +1,308 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingProvider struct {
+	set     []string
+	deleted []string
+}
+
+func (r *recordingProvider) SetRecord(domain, recordType, value string) error {
+	r.set = append(r.set, domain+" "+recordType+" "+value)
+	return nil
+}
+
+func (r *recordingProvider) DeleteRecord(domain, recordType string) error {
+	r.deleted = append(r.deleted, domain+" "+recordType)
+	return nil
+}
+
+func TestInZone(t *testing.T) {
+	tests := []struct {
+		domain, zone string
+		want         bool
+	}{
+		{"ci.example.com", "example.com", true},
+		{"example.com", "example.com.", true},
+		{"CI.Example.com", "example.com", true},
+		{"ci.notexample.com", "example.com", false},
+		{"example.com", "ci.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := InZone(tt.domain, tt.zone); got != tt.want {
+			t.Errorf("InZone(%q, %q) = %v, want %v", tt.domain, tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestACMEChallenge(t *testing.T) {
+	provider := &recordingProvider{}
+	challenge := ACMEProvider(provider)
+
+	if err := challenge.Present("ci.example.com", "token", "keyAuth"); err != nil {
+		t.Fatal(err)
+	}
+	if err := challenge.CleanUp("ci.example.com", "token", "keyAuth"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.set) != 1 || !strings.HasPrefix(provider.set[0], "_acme-challenge.ci.example.com TXT ") {
+		t.Errorf("unexpected records set %v", provider.set)
+	}
+	if len(provider.deleted) != 1 || provider.deleted[0] != "_acme-challenge.ci.example.com TXT" {
+		t.Errorf("unexpected records deleted %v", provider.deleted)
+	}
+}
+
+func TestManualProvider(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	provider := NewManualProvider(strings.NewReader("\n"), stdout)
+	provider.lookupA = func(string) ([]string, error) { return nil, errors.New("no such host") }
+	provider.lookupTXT = provider.lookupA
+
+	if err := provider.SetRecord("ci.example.com", "A", "203.0.113.10"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "ci.example.com\tA\t203.0.113.10") {
+		t.Errorf("expected the record to be printed, got %q", stdout.String())
+	}
+
+	if err := provider.SetRecord("ci.example.com", "TXT", "value"); err == nil {
+		t.Error("expected an error once stdin is closed without confirming")
+	}
+
+	provider.lookupA = func(string) ([]string, error) { return []string{"203.0.113.10"}, nil }
+	stdout.Reset()
+	if err := provider.SetRecord("ci.example.com", "A", "203.0.113.10"); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no prompt for a record that already resolves, got %q", stdout.String())
+	}
+}
+
+func TestManualProviderCNAME(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	provider, err := New(Manual, "", Options{Stdin: neverReads{t}, Stdout: stdout, NonInteractive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual := provider.(*ManualProvider)
+	manual.lookupA = func(string) ([]string, error) { return []string{"203.0.113.10"}, nil }
+	manual.lookupCNAME = func(host string) (string, error) {
+		if host != "ci.example.com" {
+			t.Errorf("unexpected CNAME lookup of %s", host)
+		}
+		return "ci-lb.elb.example.com.", nil
+	}
+
+	if err = provider.SetRecord("ci.example.com", "CNAME", "ci-lb.elb.example.com"); err != nil {
+		t.Errorf("expected a CNAME that already resolves to be accepted, got %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no prompt for a CNAME that already resolves, got %q", stdout.String())
+	}
+
+	err = provider.SetRecord("ci.example.com", "CNAME", "other-lb.elb.example.com")
+	if err == nil || !strings.Contains(err.Error(), "ci.example.com CNAME other-lb.elb.example.com doesn't resolve yet") {
+		t.Errorf("expected a CNAME to another target not to count as created, got %v", err)
+	}
+}
+
+func TestManualProviderNonInteractive(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	provider, err := New(Manual, "", Options{Stdin: neverReads{t}, Stdout: stdout, NonInteractive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual := provider.(*ManualProvider)
+	manual.lookupA = func(string) ([]string, error) { return nil, errors.New("no such host") }
+
+	err = provider.SetRecord("ci.example.com", "A", "203.0.113.10")
+	if err == nil || !strings.Contains(err.Error(), "ci.example.com A 203.0.113.10 doesn't resolve yet and can't be confirmed when running non-interactively") {
+		t.Errorf("expected a non-interactive error, got %v", err)
+	}
+
+	manual.lookupA = func(string) ([]string, error) { return []string{"203.0.113.10"}, nil }
+	if err = provider.SetRecord("ci.example.com", "A", "203.0.113.10"); err != nil {
+		t.Errorf("expected a record that already resolves to be accepted, got %v", err)
+	}
+}
+
+type neverReads struct{ t *testing.T }
+
+func (n neverReads) Read([]byte) (int, error) {
+	n.t.Fatal("unexpected read from stdin")
+	return 0, nil
+}
+
+type fakeCloudRecords struct {
+	set     []string
+	deleted []string
+}
+
+func (f *fakeCloudRecords) FindLongestMatchingHostedZone(domain string) (string, string, error) {
+	if !InZone(domain, "example.com") {
+		return "", "", errors.New("no matching hosted zone")
+	}
+	return "example.com", "Z123", nil
+}
+
+func (f *fakeCloudRecords) SetDNSRecord(zoneID, name, recordType, value string) error {
+	f.set = append(f.set, strings.Join([]string{zoneID, name, recordType, value}, " "))
+	return nil
+}
+
+func (f *fakeCloudRecords) DeleteDNSRecord(zoneID, name, recordType string) error {
+	f.deleted = append(f.deleted, strings.Join([]string{zoneID, name, recordType}, " "))
+	return nil
+}
+
+func TestCloudProvider(t *testing.T) {
+	records := &fakeCloudRecords{}
+	provider, err := New(Cloud, "", Options{Cloud: records})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = provider.SetRecord("_acme-challenge.ci.example.com", "TXT", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err = provider.DeleteRecord("_acme-challenge.ci.example.com", "TXT"); err != nil {
+		t.Fatal(err)
+	}
+	if len(records.set) != 1 || records.set[0] != "Z123 _acme-challenge.ci.example.com TXT value" {
+		t.Errorf("unexpected records set %v", records.set)
+	}
+	if len(records.deleted) != 1 || records.deleted[0] != "Z123 _acme-challenge.ci.example.com TXT" {
+		t.Errorf("unexpected records deleted %v", records.deleted)
+	}
+	if err = provider.SetRecord("ci.example.org", "A", "203.0.113.10"); err == nil {
+		t.Error("expected an error for a domain outside the hosted zones")
+	}
+}
+
+func TestNS1Provider(t *testing.T) {
+	var requests []string
+	var record ns1Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("X-NSONE-Key") != "key" {
+			t.Errorf("unexpected API key %q", r.Header.Get("X-NSONE-Key"))
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+				t.Error(err)
+			}
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewNS1Provider("example.com.", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.endpoint = server.URL
+
+	if err = provider.SetRecord("ci.example.com", "A", "203.0.113.10"); err != nil {
+		t.Fatal(err)
+	}
+	if err = provider.DeleteRecord("ci.example.com", "A"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /zones/example.com/ci.example.com/A",
+		"PUT /zones/example.com/ci.example.com/A",
+		"DELETE /zones/example.com/ci.example.com/A",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests %v", requests)
+	}
+	if record.Zone != "example.com" || len(record.Answers) != 1 || record.Answers[0].Answer[0] != "203.0.113.10" {
+		t.Errorf("unexpected record %+v", record)
+	}
+}
+
+func TestNS1ProviderRequiresAPIKey(t *testing.T) {
+	if _, err := NewNS1Provider("example.com", ""); err == nil {
+		t.Error("expected an error without an API key")
+	}
+}
+
+func TestAzureProvider(t *testing.T) {
+	var requests []string
+	var body map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			if r.FormValue("client_secret") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"token"}`))
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.Method == http.MethodPut {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewAzureProvider("example.com", AzureCredentials{
+		TenantID:       "tenant",
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		SubscriptionID: "subscription",
+		ResourceGroup:  "dns",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.loginEndpoint = server.URL
+	provider.managementEndpoint = server.URL
+
+	if err = provider.SetRecord("ci.example.com", "A", "203.0.113.10"); err != nil {
+		t.Fatal(err)
+	}
+	if err = provider.DeleteRecord("example.com", "TXT"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"PUT /subscriptions/subscription/resourceGroups/dns/providers/Microsoft.Network/dnsZones/example.com/A/ci",
+		"DELETE /subscriptions/subscription/resourceGroups/dns/providers/Microsoft.Network/dnsZones/example.com/TXT/@",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests %v", requests)
+	}
+	if _, ok := body["properties"]["ARecords"]; !ok {
+		t.Errorf("expected A records, got %v", body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("route53", "example.com", Options{}); err == nil || !strings.Contains(err.Error(), "unknown DNS provider") {
+		t.Errorf("expected an unknown provider error, got %v", err)
+	}
+	t.Setenv("AZURE_TENANT_ID", "")
+	if _, err := New(Azure, "example.com", Options{}); err == nil {
+		t.Error("expected an error without Azure credentials")
+	}
+}
@@ -0,0 +1,80 @@
+package dns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// ManualProvider prints the records for the operator to create with whoever manages their DNS, and waits
+// for them to confirm each one has been created
+type ManualProvider struct {
+	stdin          *bufio.Reader
+	stdout         io.Writer
+	nonInteractive bool
+	lookupA        func(host string) ([]string, error)
+	lookupTXT      func(name string) ([]string, error)
+	lookupCNAME    func(host string) (string, error)
+}
+
+// NewManualProvider returns a provider that prompts on stdout and reads confirmations from stdin
+func NewManualProvider(stdin io.Reader, stdout io.Writer) *ManualProvider {
+	return &ManualProvider{
+		stdin:       bufio.NewReader(stdin),
+		stdout:      stdout,
+		lookupA:     net.LookupHost,
+		lookupTXT:   net.LookupTXT,
+		lookupCNAME: net.LookupCNAME,
+	}
+}
+
+// SetRecord prints the record to create and blocks until the operator presses enter, unless the
+// record already resolves, so that redeploying doesn't ask again. When running non-interactively
+// it fails straight away instead, naming the record to create before deploying again
+func (m *ManualProvider) SetRecord(domain, recordType, value string) error {
+	if m.resolves(domain, recordType, value) {
+		return nil
+	}
+	if m.nonInteractive {
+		return fmt.Errorf("the DNS record %s %s %s doesn't resolve yet and can't be confirmed when running non-interactively. Create it, with a TTL of 60 seconds or less, and deploy again", domain, recordType, value)
+	}
+	_, err := fmt.Fprintf(m.stdout, "\nCreate the following DNS record, with a TTL of 60 seconds or less:\n\n\t%s\t%s\t%s\n\nPress enter once it has been created to continue\n", domain, recordType, value)
+	if err != nil {
+		return err
+	}
+	if _, err = m.stdin.ReadString('\n'); err != nil {
+		return fmt.Errorf("error waiting for the %s record for %s to be confirmed: [%v]", recordType, domain, err)
+	}
+	return nil
+}
+
+// DeleteRecord prints the record that is no longer needed, without waiting
+func (m *ManualProvider) DeleteRecord(domain, recordType string) error {
+	_, err := fmt.Fprintf(m.stdout, "\nThe %s record for %s is no longer needed and can be deleted\n", recordType, domain)
+	return err
+}
+
+func (m *ManualProvider) resolves(domain, recordType, value string) bool {
+	lookup := m.lookupA
+	switch recordType {
+	case "TXT":
+		lookup = m.lookupTXT
+	case "CNAME":
+		// the A lookup follows a CNAME to the addresses it points at, so its target is looked up instead.
+		// Targets are returned fully qualified, with a trailing dot
+		target, err := m.lookupCNAME(domain)
+		return err == nil && strings.EqualFold(strings.TrimSuffix(target, "."), strings.TrimSuffix(value, "."))
+	}
+	values, err := lookup(domain)
+	if err != nil {
+		return false
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const ns1Endpoint = "https://api.nsone.net/v1"
+
+// NS1Provider manages records in a zone hosted by NS1
+type NS1Provider struct {
+	zone     string
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewNS1Provider returns a provider for zone, authenticating with an NS1 API key
+func NewNS1Provider(zone, apiKey string) (*NS1Provider, error) {
+	if apiKey == "" {
+		return nil, errors.New("NS1_API_KEY must be set to manage DNS records with NS1")
+	}
+	return &NS1Provider{
+		zone:     strings.TrimSuffix(zone, "."),
+		apiKey:   apiKey,
+		endpoint: ns1Endpoint,
+		client:   http.DefaultClient,
+	}, nil
+}
+
+type ns1Record struct {
+	Zone    string      `json:"zone"`
+	Domain  string      `json:"domain"`
+	Type    string      `json:"type"`
+	TTL     int         `json:"ttl"`
+	Answers []ns1Answer `json:"answers"`
+}
+
+type ns1Answer struct {
+	Answer []string `json:"answer"`
+}
+
+// SetRecord creates the record, or replaces its answers if it already exists
+func (n *NS1Provider) SetRecord(domain, recordType, value string) error {
+	status, _, err := n.do(http.MethodGet, domain, recordType, nil)
+	if err != nil {
+		return err
+	}
+	// NS1 creates records with PUT and updates them with POST
+	method := http.MethodPost
+	if status == http.StatusNotFound {
+		method = http.MethodPut
+	}
+	record := ns1Record{
+		Zone:    n.zone,
+		Domain:  domain,
+		Type:    recordType,
+		TTL:     60,
+		Answers: []ns1Answer{{Answer: []string{value}}},
+	}
+	status, body, err := n.do(method, domain, recordType, record)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("error setting NS1 %s record for %s: [%d %s]", recordType, domain, status, body)
+	}
+	return nil
+}
+
+// DeleteRecord deletes the record, succeeding if it doesn't exist
+func (n *NS1Provider) DeleteRecord(domain, recordType string) error {
+	status, body, err := n.do(http.MethodDelete, domain, recordType, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("error deleting NS1 %s record for %s: [%d %s]", recordType, domain, status, body)
+	}
+	return nil
+}
+
+func (n *NS1Provider) do(method, domain, recordType string, payload interface{}) (int, string, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		contents, err := json.Marshal(payload)
+		if err != nil {
+			return 0, "", err
+		}
+		reqBody = bytes.NewReader(contents)
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/zones/%s/%s/%s", n.endpoint, n.zone, domain, recordType), reqBody)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("X-NSONE-Key", n.apiKey)
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("error calling NS1 API: [%v]", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+	return resp.StatusCode, strings.TrimSpace(string(body)), nil
+}
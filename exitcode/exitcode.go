@@ -0,0 +1,125 @@
+package exitcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// Exit codes returned by control-tower, so that automation wrapping it can decide whether to retry or escalate
+const (
+	// Error is any failure not covered by a more specific code
+	Error = 1
+	// Validation means the flags or arguments were invalid, and nothing was changed
+	Validation = 2
+	// Auth means the IAAS credentials are missing, expired or lack a permission
+	Auth = 3
+	// Quota means an IAAS quota or service limit was reached, or the deployment goes over its --quota-policy
+	Quota = 4
+	// LockHeld means another operation holds the BOSH lock or control-tower's lock on the deployment
+	LockHeld = 5
+	// Partial means the command failed part way through changing the deployment, and running it again resumes
+	Partial = 6
+)
+
+var (
+	authMessages = []string{
+		"accessdenied",
+		"authfailure",
+		"could not find default credentials",
+		"expiredtoken",
+		"googleapi: error 401",
+		"googleapi: error 403",
+		"invalid_grant",
+		"invalidclienttokenid",
+		"nocredentialproviders",
+		"signaturedoesnotmatch",
+		"unauthorizedoperation",
+		"unrecognizedclientexception",
+	}
+	// GCP reports some exhausted quotas as a 403, so these are checked before authMessages
+	quotaMessages = []string{
+		"deployment is over quota",
+		"limitexceeded",
+		"quota exceeded",
+		"quota_exceeded",
+		"quotaexceeded",
+	}
+	// API rate limits clear by themselves, so they are not reported as an exhausted quota
+	rateLimitMessages = []string{
+		"ratelimitexceeded",
+		"requestlimitexceeded",
+	}
+	// usageMessages are the errors the CLI library returns for flags it can't parse
+	usageMessages = []string{
+		"flag needs an argument",
+		"flag provided but not defined",
+		"invalid boolean value",
+		"invalid value",
+	}
+	lockMessages = []string{
+		"bosh lock failed to become available",
+		"failed to acquire lock",
+	}
+)
+
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e codedError) Unwrap() error {
+	return e.err
+}
+
+// WithCode marks err as having the given exit code
+func WithCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return codedError{code: code, err: err}
+}
+
+// Of returns the exit code for err. Validation errors are marked where they are raised, and are
+// recognised however deeply they are wrapped, while quota, auth and lock failures are recognised
+// from the messages of the IAAS and BOSH errors that caused them, and take precedence over a
+// Partial mark
+func Of(err error) int {
+	if err == nil {
+		return 0
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if coded, ok := e.(codedError); ok && coded.code == Validation {
+			return Validation
+		}
+	}
+	var coded codedError
+	isCoded := errors.As(err, &coded)
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, usageMessages) && !isCoded:
+		return Validation
+	case containsAny(message, quotaMessages) && !containsAny(message, rateLimitMessages):
+		return Quota
+	case containsAny(message, authMessages):
+		return Auth
+	case containsAny(message, lockMessages):
+		return LockHeld
+	case isCoded:
+		return coded.code
+	}
+	return Error
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
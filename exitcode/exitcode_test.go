@@ -0,0 +1,114 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "success",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "unclassified failure",
+			err:  errors.New("something went wrong"),
+			want: Error,
+		},
+		{
+			name: "validation error",
+			err:  WithCode(Validation, errors.New("--iaas flag not set")),
+			want: Validation,
+		},
+		{
+			name: "expired AWS credentials",
+			err:  fmt.Errorf("error ensuring config bucket exists before deploy: [%v]", errors.New("ExpiredToken: The security token included in the request is expired")),
+			want: Auth,
+		},
+		{
+			name: "GCP permission denied",
+			err:  errors.New("googleapi: Error 403: Required 'compute.instances.list' permission, forbidden"),
+			want: Auth,
+		},
+		{
+			name: "AWS vCPU limit",
+			err:  errors.New("VcpuLimitExceeded: You have requested more vCPU capacity than your current vCPU limit"),
+			want: Quota,
+		},
+		{
+			name: "GCP quota",
+			err:  errors.New("googleapi: Error 400: Quota 'CPUS' exceeded. Limit: 24.0 in region europe-west1., QUOTA_EXCEEDED"),
+			want: Quota,
+		},
+		{
+			name: "GCP quota reported as forbidden",
+			err:  errors.New("googleapi: Error 403: Quota exceeded for quota metric 'Queries' and limit 'Queries per minute', quotaExceeded"),
+			want: Quota,
+		},
+		{
+			name: "AWS API rate limit",
+			err:  errors.New("RequestLimitExceeded: Request limit exceeded."),
+			want: Error,
+		},
+		{
+			name: "unknown flag",
+			err:  errors.New("flag provided but not defined: -worker-szie"),
+			want: Validation,
+		},
+		{
+			name: "validation error wrapped in a partial failure",
+			err:  WithCode(Partial, fmt.Errorf("error resolving config: [%w]", WithCode(Validation, errors.New("invalid CIDR")))),
+			want: Validation,
+		},
+		{
+			name: "BOSH lock held",
+			err:  errors.New("failed to run bosh deploy: [Failed to acquire lock for lock:deployment:concourse uid: 1234]"),
+			want: LockHeld,
+		},
+		{
+			name: "deployment lock held",
+			err:  errors.New("failed to acquire lock on deployment, held by alice@laptop (pid 42) running deploy since 2024-01-01T00:00:00Z"),
+			want: LockHeld,
+		},
+		{
+			name: "partial failure",
+			err:  WithCode(Partial, errors.New("failed to run bosh deploy")),
+			want: Partial,
+		},
+		{
+			name: "quota policy",
+			err:  errors.New("error getting initial config before deploy: [deployment is over quota: 12 workers requested but at most 8 are allowed]"),
+			want: Quota,
+		},
+		{
+			name: "quota reached part way through",
+			err:  WithCode(Partial, errors.New("InstanceLimitExceeded: Your quota allows for 0 more running instance(s)")),
+			want: Quota,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCodeKeepsMessage(t *testing.T) {
+	cause := errors.New("cause")
+	err := WithCode(Partial, cause)
+	if err.Error() != "cause" || !errors.Is(err, cause) {
+		t.Errorf("expected %v to wrap the cause unchanged", err)
+	}
+	if WithCode(Partial, nil) != nil {
+		t.Error("expected no error to stay nil")
+	}
+}
@@ -15,7 +15,7 @@ func NewAWSPipeline() Pipeline {
 }
 
 //BuildPipelineParams builds params for AWS control-tower self update pipeline
-func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string) (Pipeline, error) {
+func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string, workerAutoscale bool) (Pipeline, error) {
 	return AWSPipeline{
 		PipelineTemplateParams: PipelineTemplateParams{
 			ControlTowerVersion: ControlTowerVersion,
@@ -25,6 +25,7 @@ func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain,
 			Namespace:           namespace,
 			Region:              region,
 			IaaS:                iaas,
+			WorkerAutoscale:     workerAutoscale,
 		},
 	}, nil
 }
@@ -109,4 +110,38 @@ jobs:
 ` + renewCertsDateCheck + `
           echo Certificates expire in $days_until_expiry days, redeploying to renew them
           ./control-tower-linux-amd64 deploy $DEPLOYMENT
-`
+{{ if .WorkerAutoscale }}- name: autoscale-workers
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: control-tower-release
+    version: {tag: {{ .ControlTowerVersion }} }
+  - get: every-5m
+    trigger: true
+  - task: autoscale
+    params:
+      AWS_ACCESS_KEY_ID: ((runtime_aws_access_key_id))
+      AWS_REGION: "{{ .Region }}"
+      AWS_SECRET_ACCESS_KEY: ((runtime_aws_secret_access_key))
+      DEPLOYMENT: "{{ .Deployment }}"
+      IAAS: "{{ .IaaS }}"
+      NAMESPACE: "{{ .Namespace }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: control-tower-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -eux
+
+          cd control-tower-release
+          chmod +x control-tower-linux-amd64
+          ./control-tower-linux-amd64 maintain --autoscale $DEPLOYMENT
+{{ end }}`
@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 
 	. "github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/util"
@@ -19,7 +20,7 @@ var _ = Describe("AWSPipeline", func() {
 
 			pipeline := NewAWSPipeline()
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com", "10.0.0.0", "AWS")
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com", "10.0.0.0", "AWS", false)
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
@@ -27,5 +28,26 @@ var _ = Describe("AWSPipeline", func() {
 
 			Expect(string(yamlBytes)).To(Equal(expectedAWS))
 		})
+
+		It("Adds a job to autoscale workers", func() {
+			pipeline := NewAWSPipeline()
+
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com", "10.0.0.0", "AWS", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			var rendered struct {
+				Resources []struct{ Name string }
+				Jobs      []struct{ Name string }
+			}
+			Expect(yaml.Unmarshal(yamlBytes, &rendered)).To(Succeed())
+			Expect(rendered.Resources[len(rendered.Resources)-1].Name).To(Equal("every-5m"))
+			Expect(rendered.Jobs[len(rendered.Jobs)-1].Name).To(Equal("autoscale-workers"))
+			Expect(string(yamlBytes)).To(ContainSubstring("./control-tower-linux-amd64 maintain --autoscale $DEPLOYMENT"))
+			Expect(string(yamlBytes)).To(ContainSubstring("AWS_ACCESS_KEY_ID: ((runtime_aws_access_key_id))"))
+			Expect(string(yamlBytes)).To(ContainSubstring("AWS_SECRET_ACCESS_KEY: ((runtime_aws_secret_access_key))"))
+		})
 	})
 })
@@ -0,0 +1,95 @@
+package fly
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Build is a Concourse build as listed by fly builds
+type Build struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	TeamName     string `json:"team_name"`
+	PipelineName string `json:"pipeline_name"`
+	JobName      string `json:"job_name"`
+	Status       string `json:"status"`
+	StartTime    int64  `json:"start_time"`
+	EndTime      int64  `json:"end_time"`
+}
+
+// Builds returns up to count of the most recent builds across all teams
+func (client *Client) Builds(count int) ([]Build, error) {
+	if err := client.login(); err != nil {
+		return nil, err
+	}
+
+	buildsJSON, stderr, err := client.output("builds", "--all-teams", "--json", "--count", strconv.Itoa(count))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds: [%v] %s", err, stderr)
+	}
+
+	var builds []Build
+	if err = json.Unmarshal(buildsJSON, &builds); err != nil {
+		return nil, fmt.Errorf("failed to parse builds: [%v]", err)
+	}
+	return builds, nil
+}
+
+// workerSteps are the steps of a build plan that run in a container on a worker
+var workerSteps = []string{"check", "get", "put", "run", "task"}
+
+// StepTags returns the tags of each step in a build's plan that runs on a worker, with an empty list for a
+// step without tags, so that the workers a pending build is waiting for can be told apart
+func (client *Client) StepTags(buildID int) ([][]string, error) {
+	if err := client.login(); err != nil {
+		return nil, err
+	}
+
+	planJSON, stderr, err := client.output("curl", fmt.Sprintf("/api/v1/builds/%d/plan", buildID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the plan of build %d: [%v] %s", buildID, err, stderr)
+	}
+
+	var plan interface{}
+	if err = json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse the plan of build %d: [%v]", buildID, err)
+	}
+	return stepTags(plan), nil
+}
+
+// stepTags finds every step in plan that runs on a worker, wherever it is nested, and returns its tags
+func stepTags(plan interface{}) [][]string {
+	var found [][]string
+	switch v := plan.(type) {
+	case map[string]interface{}:
+		for _, name := range workerSteps {
+			step, ok := v[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			tags := []string{}
+			list, _ := step["tags"].([]interface{})
+			for _, tag := range list {
+				if s, ok := tag.(string); ok {
+					tags = append(tags, s)
+				}
+			}
+			found = append(found, tags)
+		}
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			found = append(found, stepTags(v[key])...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			found = append(found, stepTags(child)...)
+		}
+	}
+	return found
+}
@@ -0,0 +1,32 @@
+package fly
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_stepTags(t *testing.T) {
+	planJSON := `{
+		"schema": "exec.v2",
+		"plan": {
+			"id": "1",
+			"do": [
+				{"id": "2", "get": {"name": "repo", "type": "git"}},
+				{"id": "3", "on_success": {
+					"step": {"id": "4", "task": {"name": "build", "tags": ["gpu"]}},
+					"on_success": {"id": "5", "put": {"name": "image", "tags": ["gpu", "large"]}}
+				}}
+			]
+		}
+	}`
+	var plan interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{}, {"gpu", "large"}, {"gpu"}}
+	if got := stepTags(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("stepTags() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,311 @@
+package fly
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+const bootstrapTeamName = "main"
+
+var pipelineSections = []string{"resource_types", "resources", "jobs", "groups"}
+
+// Drift compares the teams and pipelines that control-tower bootstraps against the live
+// Concourse and returns a description of every change made outside of control-tower
+func (client *Client) Drift(config config.ConfigView) ([]string, error) {
+	if err := client.login(); err != nil {
+		return nil, err
+	}
+
+	differences := []string{}
+
+	teamsJSON, stderr, err := client.output("teams", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: [%v] %s", err, stderr)
+	}
+	desiredTeams, err := bootstrapTeams(config)
+	if err != nil {
+		return nil, err
+	}
+	teamDifferences, err := compareTeams(teamsJSON, desiredTeams, config.GetTeamsSpec() != "")
+	if err != nil {
+		return nil, err
+	}
+	differences = append(differences, teamDifferences...)
+
+	desired, err := client.renderPipelineConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	live, stderr, err := client.output("get-pipeline", "--pipeline", selfUpdatePipelineName)
+	if err != nil {
+		if strings.Contains(string(stderr), "not found") {
+			return append(differences, fmt.Sprintf("pipeline %s is missing", selfUpdatePipelineName)), nil
+		}
+		return nil, fmt.Errorf("failed to get pipeline %s: [%v] %s", selfUpdatePipelineName, err, stderr)
+	}
+
+	pipelineDifferences, err := comparePipelines(selfUpdatePipelineName, desired, live)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(differences, pipelineDifferences...), nil
+}
+
+// teamAuth is the owner role of a team, as the users and groups it is made of
+type teamAuth struct {
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+func (auth *teamAuth) add(member teamAuthMember, value string) {
+	name := strings.ToLower(member.prefix + value + member.suffix)
+	if member.group {
+		auth.Groups = append(auth.Groups, name)
+	} else {
+		auth.Users = append(auth.Users, name)
+	}
+}
+
+func (auth teamAuth) equal(other teamAuth) bool {
+	return reflect.DeepEqual(sortedSet(auth.Users), sortedSet(other.Users)) &&
+		reflect.DeepEqual(sortedSet(auth.Groups), sortedSet(other.Groups))
+}
+
+// BootstrapTeamsSpec is the `fly set-team` auth of the main team that control-tower configures, along with
+// each team in the teams file
+func BootstrapTeamsSpec(config config.ConfigView) (TeamsSpec, error) {
+	main := map[string][]string{"local-user": {config.GetConcourseUsername()}}
+	for flag, values := range map[string]string{
+		"github-user": config.GetMainGithubUsers(),
+		"github-team": config.GetMainGithubTeams(),
+		"github-org":  config.GetMainGithubOrgs(),
+	} {
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				main[flag] = append(main[flag], value)
+			}
+		}
+	}
+	if orgs := config.GetMainCFOrgs(); len(orgs) > 0 {
+		main["cf-org"] = orgs
+	}
+	if spaces := config.GetMainCFSpaces(); len(spaces) > 0 {
+		main["cf-space-with-any-role"] = spaces
+	}
+	teams := TeamsSpec{bootstrapTeamName: main}
+
+	if config.GetTeamsSpec() == "" {
+		return teams, nil
+	}
+	spec, err := ParseTeamsSpec([]byte(config.GetTeamsSpec()))
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range spec.teamNames() {
+		teams[team] = spec[team]
+	}
+	return teams, nil
+}
+
+// bootstrapTeams is the auth of the main team that control-tower configures, and of each team in the
+// teams file, as Concourse records it
+func bootstrapTeams(config config.ConfigView) (map[string]teamAuth, error) {
+	spec, err := BootstrapTeamsSpec(config)
+	if err != nil {
+		return nil, err
+	}
+	teams := map[string]teamAuth{}
+	for _, team := range spec.teamNames() {
+		auth := teamAuth{}
+		for flag, values := range spec[team] {
+			for _, value := range values {
+				auth.add(teamAuthFlags[flag], value)
+			}
+		}
+		teams[team] = auth
+	}
+	return teams, nil
+}
+
+// compareTeams reports the desired teams that are missing or whose owners have changed. Teams that aren't
+// desired are only reported when control-tower manages the teams from a teams file
+func compareTeams(teamsJSON []byte, desired map[string]teamAuth, managed bool) ([]string, error) {
+	var teams []struct {
+		Name string `json:"name"`
+		Auth struct {
+			Owner teamAuth `json:"owner"`
+		} `json:"auth"`
+	}
+	if err := json.Unmarshal(teamsJSON, &teams); err != nil {
+		return nil, fmt.Errorf("failed to parse teams: [%v]", err)
+	}
+	live := map[string]teamAuth{}
+	for _, team := range teams {
+		live[team.Name] = team.Auth.Owner
+	}
+
+	differences := []string{}
+	for _, name := range sortedTeamNames(desired) {
+		liveAuth, ok := live[name]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("team %s is missing", name))
+			continue
+		}
+		if !desired[name].equal(liveAuth) {
+			differences = append(differences, fmt.Sprintf("team %s: auth has been modified", name))
+		}
+	}
+	if managed {
+		for _, name := range sortedTeamNames(live) {
+			if _, ok := desired[name]; !ok {
+				differences = append(differences, fmt.Sprintf("team %s was added outside of control-tower", name))
+			}
+		}
+	}
+	return differences, nil
+}
+
+func sortedTeamNames(teams map[string]teamAuth) []string {
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedSet lowercases, dedupes and sorts names, as Concourse records them
+func sortedSet(names []string) []string {
+	set := map[string]bool{}
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	sorted := []string{}
+	for name := range set {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func comparePipelines(name string, desired, live []byte) ([]string, error) {
+	var desiredConfig, liveConfig map[string]interface{}
+	if err := yaml.Unmarshal(desired, &desiredConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse desired config of pipeline %s: [%v]", name, err)
+	}
+	if err := yaml.Unmarshal(live, &liveConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse live config of pipeline %s: [%v]", name, err)
+	}
+
+	differences := []string{}
+	for _, section := range pipelineSections {
+		desiredItems := itemsByName(desiredConfig[section])
+		liveItems := itemsByName(liveConfig[section])
+		kind := strings.TrimSuffix(strings.Replace(section, "_", " ", -1), "s")
+
+		for _, itemName := range sortedKeys(desiredItems) {
+			liveItem, ok := liveItems[itemName]
+			if !ok {
+				differences = append(differences, fmt.Sprintf("pipeline %s: %s %s is missing", name, kind, itemName))
+				continue
+			}
+			if !reflect.DeepEqual(normalise(desiredItems[itemName]), normalise(liveItem)) {
+				differences = append(differences, fmt.Sprintf("pipeline %s: %s %s has been modified", name, kind, itemName))
+			}
+		}
+		for _, itemName := range sortedKeys(liveItems) {
+			if _, ok := desiredItems[itemName]; !ok {
+				differences = append(differences, fmt.Sprintf("pipeline %s: %s %s was added outside of control-tower", name, kind, itemName))
+			}
+		}
+	}
+	return differences, nil
+}
+
+// normalise makes a pipeline item comparable with the same item as fly prints it back. Keys become
+// strings, numbers become float64, and empty values are dropped, as fly omits fields left at their
+// defaults
+func normalise(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		fields := map[string]interface{}{}
+		for key, field := range v {
+			if field = normalise(field); !isEmpty(field) {
+				fields[fmt.Sprint(key)] = field
+			}
+		}
+		return fields
+	case map[string]interface{}:
+		fields := map[string]interface{}{}
+		for key, field := range v {
+			if field = normalise(field); !isEmpty(field) {
+				fields[key] = field
+			}
+		}
+		return fields
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, normalise(item))
+		}
+		return items
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func itemsByName(section interface{}) map[string]interface{} {
+	items := map[string]interface{}{}
+	list, ok := section.([]interface{})
+	if !ok {
+		return items
+	}
+	for _, item := range list {
+		fields, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		items[fmt.Sprint(fields["name"])] = item
+	}
+	return items
+}
+
+func sortedKeys(items map[string]interface{}) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package fly
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/control-tower/config"
+)
+
+func Test_comparePipelines(t *testing.T) {
+	desired := []byte(`
+resources:
+- name: control-tower-release
+  type: github-release
+- name: every-day
+  type: time
+jobs:
+- name: self-update
+  plan:
+  - get: control-tower-release
+`)
+	tests := []struct {
+		name string
+		live string
+		want []string
+	}{
+		{
+			name: "no drift",
+			live: string(desired),
+			want: []string{},
+		},
+		{
+			name: "same config as fly prints it",
+			live: `
+resources:
+- name: control-tower-release
+  type: github-release
+  check_every: ""
+- name: every-day
+  type: time
+  public: false
+jobs:
+- name: self-update
+  serial: false
+  plan:
+  - get: control-tower-release
+    trigger: false
+`,
+			want: []string{},
+		},
+		{
+			name: "modified job",
+			live: `
+resources:
+- name: control-tower-release
+  type: github-release
+- name: every-day
+  type: time
+jobs:
+- name: self-update
+  plan:
+  - get: every-day
+`,
+			want: []string{"pipeline p: job self-update has been modified"},
+		},
+		{
+			name: "missing and added resources",
+			live: `
+resources:
+- name: control-tower-release
+  type: github-release
+- name: extra
+  type: git
+jobs:
+- name: self-update
+  plan:
+  - get: control-tower-release
+`,
+			want: []string{
+				"pipeline p: resource every-day is missing",
+				"pipeline p: resource extra was added outside of control-tower",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := comparePipelines("p", desired, []byte(tt.live))
+			if err != nil {
+				t.Fatalf("comparePipelines() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("comparePipelines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareTeams(t *testing.T) {
+	desired := map[string]teamAuth{
+		"main": {Users: []string{"local:admin", "github:someone"}},
+		"dev":  {Groups: []string{"github:org:dev"}},
+	}
+	tests := []struct {
+		name    string
+		teams   string
+		managed bool
+		want    []string
+	}{
+		{
+			name:  "teams unchanged",
+			teams: `[{"id":1,"name":"main","auth":{"owner":{"users":["github:Someone","local:admin"]}}},{"id":2,"name":"dev","auth":{"owner":{"groups":["github:org:dev"]}}},{"id":3,"name":"other"}]`,
+			want:  []string{},
+		},
+		{
+			name:  "main team missing",
+			teams: `[{"id":2,"name":"dev","auth":{"owner":{"groups":["github:org:dev"]}}}]`,
+			want:  []string{"team main is missing"},
+		},
+		{
+			name:    "auth changed and team added",
+			teams:   `[{"id":1,"name":"main","auth":{"owner":{"users":["local:admin"]}}},{"id":2,"name":"dev","auth":{"owner":{"groups":["github:org:dev"]}}},{"id":3,"name":"other"}]`,
+			managed: true,
+			want: []string{
+				"team main: auth has been modified",
+				"team other was added outside of control-tower",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compareTeams([]byte(tt.teams), desired, tt.managed)
+			if err != nil {
+				t.Fatalf("compareTeams() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareTeams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bootstrapTeams(t *testing.T) {
+	got, err := bootstrapTeams(config.Config{
+		ConcourseUsername: "admin",
+		MainGithubUsers:   "someone, someone-else",
+		MainGithubOrgs:    "org",
+		TeamsSpec:         "dev:\n  github-team: [org:dev]\n  cf-space-with-developer-role: [org:space]\n",
+	})
+	if err != nil {
+		t.Fatalf("bootstrapTeams() error = %v", err)
+	}
+	if want := (teamAuth{Users: []string{"local:admin", "github:someone", "github:someone-else"}, Groups: []string{"github:org"}}); !got["main"].equal(want) {
+		t.Errorf("bootstrapTeams() main = %+v, want %+v", got["main"], want)
+	}
+	if want := (teamAuth{Groups: []string{"github:org:dev", "cf:org:space:developer"}}); !got["dev"].equal(want) {
+		t.Errorf("bootstrapTeams() dev = %+v, want %+v", got["dev"], want)
+	}
+}
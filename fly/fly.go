@@ -24,10 +24,19 @@ import (
 // ControlTowerVersion is a compile-time variable set with -ldflags
 var ControlTowerVersion = "COMPILE_TIME_VARIABLE_fly_control_tower_version"
 
+const selfUpdatePipelineName = "control-tower-self-update"
+
 //counterfeiter:generate . IClient
 type IClient interface {
 	CanConnect() (bool, error)
 	SetDefaultPipeline(config config.ConfigView, allowFlyVersionDiscrepancy bool) error
+	Drift(config config.ConfigView) ([]string, error)
+	SetTeams(spec TeamsSpec, prune bool) error
+	Builds(count int) ([]Build, error)
+	StepTags(buildID int) ([][]string, error)
+	Workers() ([]Worker, error)
+	LandWorker(name string) error
+	PruneWorker(name string) error
 	Cleanup() error
 }
 
@@ -175,7 +184,7 @@ func (client *Client) SetDefaultPipeline(config config.ConfigView, allowFlyVersi
 	}
 
 	pipelinePath := client.tempDir.Path("default-pipeline.yml")
-	pipelineName := "control-tower-self-update"
+	pipelineName := selfUpdatePipelineName
 
 	if err := client.writePipelineConfig(pipelinePath, config); err != nil {
 		return err
@@ -203,12 +212,7 @@ func (client *Client) writePipelineConfig(pipelinePath string, config config.Con
 	}
 	defer fileHandler.Close()
 
-	params, err := client.pipeline.BuildPipelineParams(config.GetDeployment(), config.GetNamespace(), config.GetRegion(), config.GetDomain(), config.GetAllowIPsUnformatted(), config.GetIAAS())
-	if err != nil {
-		return err
-	}
-	pipelineTemplate := client.pipeline.GetConfigTemplate()
-	pipelineConfig, err := util.RenderTemplate("self-update pipeline", pipelineTemplate, params)
+	pipelineConfig, err := client.renderPipelineConfig(config)
 	if err != nil {
 		return err
 	}
@@ -224,6 +228,15 @@ func (client *Client) writePipelineConfig(pipelinePath string, config config.Con
 	return nil
 }
 
+func (client *Client) renderPipelineConfig(config config.ConfigView) ([]byte, error) {
+	params, err := client.pipeline.BuildPipelineParams(config.GetDeployment(), config.GetNamespace(), config.GetRegion(), config.GetDomain(), config.GetAllowIPsUnformatted(), config.GetIAAS(), config.GetWorkerAutoscaleMax() > 0 || len(config.GetWorkerSchedules()) > 0)
+	if err != nil {
+		return nil, err
+	}
+	pipelineTemplate := client.pipeline.GetConfigTemplate()
+	return util.RenderTemplate("self-update pipeline", pipelineTemplate, params)
+}
+
 // Cleanup removes tempfiles
 func (client *Client) Cleanup() error {
 	return client.tempDir.Cleanup()
@@ -264,6 +277,17 @@ func (client *Client) run(args ...string) error {
 	return cmd.Run()
 }
 
+func (client *Client) output(args ...string) ([]byte, []byte, error) {
+	args = append([]string{"--target", client.creds.Target}, args...)
+	cmd := client.runFly(args...)
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
 func getFlyURL(api string) (string, error) {
 	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
 		return "", fmt.Errorf("unknown os: `%s`", runtime.GOOS)
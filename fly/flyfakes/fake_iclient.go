@@ -9,6 +9,19 @@ import (
 )
 
 type FakeIClient struct {
+	BuildsStub        func(int) ([]fly.Build, error)
+	buildsMutex       sync.RWMutex
+	buildsArgsForCall []struct {
+		arg1 int
+	}
+	buildsReturns struct {
+		result1 []fly.Build
+		result2 error
+	}
+	buildsReturnsOnCall map[int]struct {
+		result1 []fly.Build
+		result2 error
+	}
 	CanConnectStub        func() (bool, error)
 	canConnectMutex       sync.RWMutex
 	canConnectArgsForCall []struct {
@@ -31,6 +44,41 @@ type FakeIClient struct {
 	cleanupReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DriftStub        func(config.ConfigView) ([]string, error)
+	driftMutex       sync.RWMutex
+	driftArgsForCall []struct {
+		arg1 config.ConfigView
+	}
+	driftReturns struct {
+		result1 []string
+		result2 error
+	}
+	driftReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	LandWorkerStub        func(string) error
+	landWorkerMutex       sync.RWMutex
+	landWorkerArgsForCall []struct {
+		arg1 string
+	}
+	landWorkerReturns struct {
+		result1 error
+	}
+	landWorkerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PruneWorkerStub        func(string) error
+	pruneWorkerMutex       sync.RWMutex
+	pruneWorkerArgsForCall []struct {
+		arg1 string
+	}
+	pruneWorkerReturns struct {
+		result1 error
+	}
+	pruneWorkerReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetDefaultPipelineStub        func(config.ConfigView, bool) error
 	setDefaultPipelineMutex       sync.RWMutex
 	setDefaultPipelineArgsForCall []struct {
@@ -43,10 +91,111 @@ type FakeIClient struct {
 	setDefaultPipelineReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetTeamsStub        func(fly.TeamsSpec, bool) error
+	setTeamsMutex       sync.RWMutex
+	setTeamsArgsForCall []struct {
+		arg1 fly.TeamsSpec
+		arg2 bool
+	}
+	setTeamsReturns struct {
+		result1 error
+	}
+	setTeamsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StepTagsStub        func(int) ([][]string, error)
+	stepTagsMutex       sync.RWMutex
+	stepTagsArgsForCall []struct {
+		arg1 int
+	}
+	stepTagsReturns struct {
+		result1 [][]string
+		result2 error
+	}
+	stepTagsReturnsOnCall map[int]struct {
+		result1 [][]string
+		result2 error
+	}
+	WorkersStub        func() ([]fly.Worker, error)
+	workersMutex       sync.RWMutex
+	workersArgsForCall []struct {
+	}
+	workersReturns struct {
+		result1 []fly.Worker
+		result2 error
+	}
+	workersReturnsOnCall map[int]struct {
+		result1 []fly.Worker
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeIClient) Builds(arg1 int) ([]fly.Build, error) {
+	fake.buildsMutex.Lock()
+	ret, specificReturn := fake.buildsReturnsOnCall[len(fake.buildsArgsForCall)]
+	fake.buildsArgsForCall = append(fake.buildsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.BuildsStub
+	fakeReturns := fake.buildsReturns
+	fake.recordInvocation("Builds", []interface{}{arg1})
+	fake.buildsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) BuildsCallCount() int {
+	fake.buildsMutex.RLock()
+	defer fake.buildsMutex.RUnlock()
+	return len(fake.buildsArgsForCall)
+}
+
+func (fake *FakeIClient) BuildsCalls(stub func(int) ([]fly.Build, error)) {
+	fake.buildsMutex.Lock()
+	defer fake.buildsMutex.Unlock()
+	fake.BuildsStub = stub
+}
+
+func (fake *FakeIClient) BuildsArgsForCall(i int) int {
+	fake.buildsMutex.RLock()
+	defer fake.buildsMutex.RUnlock()
+	argsForCall := fake.buildsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) BuildsReturns(result1 []fly.Build, result2 error) {
+	fake.buildsMutex.Lock()
+	defer fake.buildsMutex.Unlock()
+	fake.BuildsStub = nil
+	fake.buildsReturns = struct {
+		result1 []fly.Build
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) BuildsReturnsOnCall(i int, result1 []fly.Build, result2 error) {
+	fake.buildsMutex.Lock()
+	defer fake.buildsMutex.Unlock()
+	fake.BuildsStub = nil
+	if fake.buildsReturnsOnCall == nil {
+		fake.buildsReturnsOnCall = make(map[int]struct {
+			result1 []fly.Build
+			result2 error
+		})
+	}
+	fake.buildsReturnsOnCall[i] = struct {
+		result1 []fly.Build
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) CanConnect() (bool, error) {
 	fake.canConnectMutex.Lock()
 	ret, specificReturn := fake.canConnectReturnsOnCall[len(fake.canConnectArgsForCall)]
@@ -156,6 +305,192 @@ func (fake *FakeIClient) CleanupReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) Drift(arg1 config.ConfigView) ([]string, error) {
+	fake.driftMutex.Lock()
+	ret, specificReturn := fake.driftReturnsOnCall[len(fake.driftArgsForCall)]
+	fake.driftArgsForCall = append(fake.driftArgsForCall, struct {
+		arg1 config.ConfigView
+	}{arg1})
+	stub := fake.DriftStub
+	fakeReturns := fake.driftReturns
+	fake.recordInvocation("Drift", []interface{}{arg1})
+	fake.driftMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) DriftCallCount() int {
+	fake.driftMutex.RLock()
+	defer fake.driftMutex.RUnlock()
+	return len(fake.driftArgsForCall)
+}
+
+func (fake *FakeIClient) DriftCalls(stub func(config.ConfigView) ([]string, error)) {
+	fake.driftMutex.Lock()
+	defer fake.driftMutex.Unlock()
+	fake.DriftStub = stub
+}
+
+func (fake *FakeIClient) DriftArgsForCall(i int) config.ConfigView {
+	fake.driftMutex.RLock()
+	defer fake.driftMutex.RUnlock()
+	argsForCall := fake.driftArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) DriftReturns(result1 []string, result2 error) {
+	fake.driftMutex.Lock()
+	defer fake.driftMutex.Unlock()
+	fake.DriftStub = nil
+	fake.driftReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) DriftReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.driftMutex.Lock()
+	defer fake.driftMutex.Unlock()
+	fake.DriftStub = nil
+	if fake.driftReturnsOnCall == nil {
+		fake.driftReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.driftReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) LandWorker(arg1 string) error {
+	fake.landWorkerMutex.Lock()
+	ret, specificReturn := fake.landWorkerReturnsOnCall[len(fake.landWorkerArgsForCall)]
+	fake.landWorkerArgsForCall = append(fake.landWorkerArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LandWorkerStub
+	fakeReturns := fake.landWorkerReturns
+	fake.recordInvocation("LandWorker", []interface{}{arg1})
+	fake.landWorkerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) LandWorkerCallCount() int {
+	fake.landWorkerMutex.RLock()
+	defer fake.landWorkerMutex.RUnlock()
+	return len(fake.landWorkerArgsForCall)
+}
+
+func (fake *FakeIClient) LandWorkerCalls(stub func(string) error) {
+	fake.landWorkerMutex.Lock()
+	defer fake.landWorkerMutex.Unlock()
+	fake.LandWorkerStub = stub
+}
+
+func (fake *FakeIClient) LandWorkerArgsForCall(i int) string {
+	fake.landWorkerMutex.RLock()
+	defer fake.landWorkerMutex.RUnlock()
+	argsForCall := fake.landWorkerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) LandWorkerReturns(result1 error) {
+	fake.landWorkerMutex.Lock()
+	defer fake.landWorkerMutex.Unlock()
+	fake.LandWorkerStub = nil
+	fake.landWorkerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) LandWorkerReturnsOnCall(i int, result1 error) {
+	fake.landWorkerMutex.Lock()
+	defer fake.landWorkerMutex.Unlock()
+	fake.LandWorkerStub = nil
+	if fake.landWorkerReturnsOnCall == nil {
+		fake.landWorkerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.landWorkerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) PruneWorker(arg1 string) error {
+	fake.pruneWorkerMutex.Lock()
+	ret, specificReturn := fake.pruneWorkerReturnsOnCall[len(fake.pruneWorkerArgsForCall)]
+	fake.pruneWorkerArgsForCall = append(fake.pruneWorkerArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PruneWorkerStub
+	fakeReturns := fake.pruneWorkerReturns
+	fake.recordInvocation("PruneWorker", []interface{}{arg1})
+	fake.pruneWorkerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) PruneWorkerCallCount() int {
+	fake.pruneWorkerMutex.RLock()
+	defer fake.pruneWorkerMutex.RUnlock()
+	return len(fake.pruneWorkerArgsForCall)
+}
+
+func (fake *FakeIClient) PruneWorkerCalls(stub func(string) error) {
+	fake.pruneWorkerMutex.Lock()
+	defer fake.pruneWorkerMutex.Unlock()
+	fake.PruneWorkerStub = stub
+}
+
+func (fake *FakeIClient) PruneWorkerArgsForCall(i int) string {
+	fake.pruneWorkerMutex.RLock()
+	defer fake.pruneWorkerMutex.RUnlock()
+	argsForCall := fake.pruneWorkerArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) PruneWorkerReturns(result1 error) {
+	fake.pruneWorkerMutex.Lock()
+	defer fake.pruneWorkerMutex.Unlock()
+	fake.PruneWorkerStub = nil
+	fake.pruneWorkerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) PruneWorkerReturnsOnCall(i int, result1 error) {
+	fake.pruneWorkerMutex.Lock()
+	defer fake.pruneWorkerMutex.Unlock()
+	fake.PruneWorkerStub = nil
+	if fake.pruneWorkerReturnsOnCall == nil {
+		fake.pruneWorkerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pruneWorkerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) SetDefaultPipeline(arg1 config.ConfigView, arg2 bool) error {
 	fake.setDefaultPipelineMutex.Lock()
 	ret, specificReturn := fake.setDefaultPipelineReturnsOnCall[len(fake.setDefaultPipelineArgsForCall)]
@@ -218,15 +553,211 @@ func (fake *FakeIClient) SetDefaultPipelineReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) SetTeams(arg1 fly.TeamsSpec, arg2 bool) error {
+	fake.setTeamsMutex.Lock()
+	ret, specificReturn := fake.setTeamsReturnsOnCall[len(fake.setTeamsArgsForCall)]
+	fake.setTeamsArgsForCall = append(fake.setTeamsArgsForCall, struct {
+		arg1 fly.TeamsSpec
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.SetTeamsStub
+	fakeReturns := fake.setTeamsReturns
+	fake.recordInvocation("SetTeams", []interface{}{arg1, arg2})
+	fake.setTeamsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) SetTeamsCallCount() int {
+	fake.setTeamsMutex.RLock()
+	defer fake.setTeamsMutex.RUnlock()
+	return len(fake.setTeamsArgsForCall)
+}
+
+func (fake *FakeIClient) SetTeamsCalls(stub func(fly.TeamsSpec, bool) error) {
+	fake.setTeamsMutex.Lock()
+	defer fake.setTeamsMutex.Unlock()
+	fake.SetTeamsStub = stub
+}
+
+func (fake *FakeIClient) SetTeamsArgsForCall(i int) (fly.TeamsSpec, bool) {
+	fake.setTeamsMutex.RLock()
+	defer fake.setTeamsMutex.RUnlock()
+	argsForCall := fake.setTeamsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIClient) SetTeamsReturns(result1 error) {
+	fake.setTeamsMutex.Lock()
+	defer fake.setTeamsMutex.Unlock()
+	fake.SetTeamsStub = nil
+	fake.setTeamsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) SetTeamsReturnsOnCall(i int, result1 error) {
+	fake.setTeamsMutex.Lock()
+	defer fake.setTeamsMutex.Unlock()
+	fake.SetTeamsStub = nil
+	if fake.setTeamsReturnsOnCall == nil {
+		fake.setTeamsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setTeamsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) StepTags(arg1 int) ([][]string, error) {
+	fake.stepTagsMutex.Lock()
+	ret, specificReturn := fake.stepTagsReturnsOnCall[len(fake.stepTagsArgsForCall)]
+	fake.stepTagsArgsForCall = append(fake.stepTagsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.StepTagsStub
+	fakeReturns := fake.stepTagsReturns
+	fake.recordInvocation("StepTags", []interface{}{arg1})
+	fake.stepTagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) StepTagsCallCount() int {
+	fake.stepTagsMutex.RLock()
+	defer fake.stepTagsMutex.RUnlock()
+	return len(fake.stepTagsArgsForCall)
+}
+
+func (fake *FakeIClient) StepTagsCalls(stub func(int) ([][]string, error)) {
+	fake.stepTagsMutex.Lock()
+	defer fake.stepTagsMutex.Unlock()
+	fake.StepTagsStub = stub
+}
+
+func (fake *FakeIClient) StepTagsArgsForCall(i int) int {
+	fake.stepTagsMutex.RLock()
+	defer fake.stepTagsMutex.RUnlock()
+	argsForCall := fake.stepTagsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) StepTagsReturns(result1 [][]string, result2 error) {
+	fake.stepTagsMutex.Lock()
+	defer fake.stepTagsMutex.Unlock()
+	fake.StepTagsStub = nil
+	fake.stepTagsReturns = struct {
+		result1 [][]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) StepTagsReturnsOnCall(i int, result1 [][]string, result2 error) {
+	fake.stepTagsMutex.Lock()
+	defer fake.stepTagsMutex.Unlock()
+	fake.StepTagsStub = nil
+	if fake.stepTagsReturnsOnCall == nil {
+		fake.stepTagsReturnsOnCall = make(map[int]struct {
+			result1 [][]string
+			result2 error
+		})
+	}
+	fake.stepTagsReturnsOnCall[i] = struct {
+		result1 [][]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) Workers() ([]fly.Worker, error) {
+	fake.workersMutex.Lock()
+	ret, specificReturn := fake.workersReturnsOnCall[len(fake.workersArgsForCall)]
+	fake.workersArgsForCall = append(fake.workersArgsForCall, struct {
+	}{})
+	stub := fake.WorkersStub
+	fakeReturns := fake.workersReturns
+	fake.recordInvocation("Workers", []interface{}{})
+	fake.workersMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) WorkersCallCount() int {
+	fake.workersMutex.RLock()
+	defer fake.workersMutex.RUnlock()
+	return len(fake.workersArgsForCall)
+}
+
+func (fake *FakeIClient) WorkersCalls(stub func() ([]fly.Worker, error)) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = stub
+}
+
+func (fake *FakeIClient) WorkersReturns(result1 []fly.Worker, result2 error) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = nil
+	fake.workersReturns = struct {
+		result1 []fly.Worker
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) WorkersReturnsOnCall(i int, result1 []fly.Worker, result2 error) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = nil
+	if fake.workersReturnsOnCall == nil {
+		fake.workersReturnsOnCall = make(map[int]struct {
+			result1 []fly.Worker
+			result2 error
+		})
+	}
+	fake.workersReturnsOnCall[i] = struct {
+		result1 []fly.Worker
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.buildsMutex.RLock()
+	defer fake.buildsMutex.RUnlock()
 	fake.canConnectMutex.RLock()
 	defer fake.canConnectMutex.RUnlock()
 	fake.cleanupMutex.RLock()
 	defer fake.cleanupMutex.RUnlock()
+	fake.driftMutex.RLock()
+	defer fake.driftMutex.RUnlock()
+	fake.landWorkerMutex.RLock()
+	defer fake.landWorkerMutex.RUnlock()
+	fake.pruneWorkerMutex.RLock()
+	defer fake.pruneWorkerMutex.RUnlock()
 	fake.setDefaultPipelineMutex.RLock()
 	defer fake.setDefaultPipelineMutex.RUnlock()
+	fake.setTeamsMutex.RLock()
+	defer fake.setTeamsMutex.RUnlock()
+	fake.stepTagsMutex.RLock()
+	defer fake.stepTagsMutex.RUnlock()
+	fake.workersMutex.RLock()
+	defer fake.workersMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -16,7 +16,7 @@ func NewGCPPipeline() Pipeline {
 }
 
 //BuildPipelineParams builds params for AWS control-tower self update pipeline
-func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string) (Pipeline, error) {
+func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string, workerAutoscale bool) (Pipeline, error) {
 	return GCPPipeline{
 		PipelineTemplateParams: PipelineTemplateParams{
 			ControlTowerVersion: ControlTowerVersion,
@@ -26,6 +26,7 @@ func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain,
 			Namespace:           namespace,
 			Region:              region,
 			IaaS:                iaas,
+			WorkerAutoscale:     workerAutoscale,
 		},
 	}, nil
 }
@@ -111,4 +112,38 @@ jobs:
 ` + renewCertsDateCheck + `
           echo Certificates expire in $days_until_expiry days, redeploying to renew them
           ./control-tower-linux-amd64 deploy $DEPLOYMENT
-`
+{{ if .WorkerAutoscale }}- name: autoscale-workers
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: control-tower-release
+    version: {tag: "{{ .ControlTowerVersion }}" }
+  - get: every-5m
+    trigger: true
+  - task: autoscale
+    params:
+      AWS_REGION: "{{ .Region }}"
+      DEPLOYMENT: "{{ .Deployment }}"
+      GCPCreds: ((runtime_google_credentials))
+      IAAS: "{{ .IaaS }}"
+      NAMESPACE: "{{ .Namespace }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: control-tower-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          cd control-tower-release
+          echo "${GCPCreds}" > googlecreds.json
+          export GOOGLE_APPLICATION_CREDENTIALS=$PWD/googlecreds.json
+          set -eux
+          chmod +x control-tower-linux-amd64
+          ./control-tower-linux-amd64 maintain --autoscale $DEPLOYMENT
+{{ end }}`
@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 
 	. "github.com/EngineerBetter/control-tower/fly"
 	"github.com/EngineerBetter/control-tower/util"
@@ -18,7 +19,7 @@ var _ = Describe("GCPPipeline", func() {
 		It("Generates something sensible", func() {
 			pipeline := NewGCPPipeline()
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com", "10.0.0.0", "GCP")
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com", "10.0.0.0", "GCP", false)
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
@@ -26,5 +27,25 @@ var _ = Describe("GCPPipeline", func() {
 
 			Expect(string(yamlBytes)).To(Equal(expectedGCP))
 		})
+
+		It("Adds a job to autoscale workers", func() {
+			pipeline := NewGCPPipeline()
+
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com", "10.0.0.0", "GCP", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			var rendered struct {
+				Resources []struct{ Name string }
+				Jobs      []struct{ Name string }
+			}
+			Expect(yaml.Unmarshal(yamlBytes, &rendered)).To(Succeed())
+			Expect(rendered.Resources[len(rendered.Resources)-1].Name).To(Equal("every-5m"))
+			Expect(rendered.Jobs[len(rendered.Jobs)-1].Name).To(Equal("autoscale-workers"))
+			Expect(string(yamlBytes)).To(ContainSubstring("./control-tower-linux-amd64 maintain --autoscale $DEPLOYMENT"))
+			Expect(string(yamlBytes)).To(ContainSubstring("GCPCreds: ((runtime_google_credentials))"))
+		})
 	})
 })
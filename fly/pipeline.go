@@ -2,7 +2,7 @@ package fly
 
 // Pipeline is interface for self update pipeline
 type Pipeline interface {
-	BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string) (Pipeline, error)
+	BuildPipelineParams(deployment, namespace, region, domain, allowIps, iaas string, workerAutoscale bool) (Pipeline, error)
 	GetConfigTemplate() string
 }
 
@@ -14,6 +14,9 @@ type PipelineTemplateParams struct {
 	Namespace           string
 	Region              string
 	IaaS                string
+	// WorkerAutoscale adds a job that runs `maintain --autoscale` every few minutes, for autoscaling
+	// and worker schedules
+	WorkerAutoscale bool
 }
 
 const selfUpdateResources = `
@@ -30,7 +33,11 @@ resources:
   type: time
   icon: clock
   source: {interval: 24h}
-`
+{{ if .WorkerAutoscale }}- name: every-5m
+  type: time
+  icon: clock
+  source: {interval: 5m}
+{{ end }}`
 
 const renewCertsDateCheck = `
           now_seconds=$(date +%s)
@@ -0,0 +1,154 @@
+package fly
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// teamAuthFlags are the `fly set-team` flags a teams spec may use, with how Concourse records each value
+// in the team's owner role
+var teamAuthFlags = map[string]teamAuthMember{
+	"local-user":                   {prefix: "local:"},
+	"github-org":                   {prefix: "github:", group: true},
+	"github-team":                  {prefix: "github:", group: true},
+	"github-user":                  {prefix: "github:"},
+	"bitbucket-cloud-user":         {prefix: "bitbucket-cloud:"},
+	"bitbucket-cloud-team":         {prefix: "bitbucket-cloud:", group: true},
+	"microsoft-user":               {prefix: "microsoft:"},
+	"microsoft-group":              {prefix: "microsoft:", group: true},
+	"oidc-user":                    {prefix: "oidc:"},
+	"oidc-group":                   {prefix: "oidc:", group: true},
+	"oauth-user":                   {prefix: "oauth:"},
+	"oauth-group":                  {prefix: "oauth:", group: true},
+	"saml-user":                    {prefix: "saml:"},
+	"saml-group":                   {prefix: "saml:", group: true},
+	"ldap-user":                    {prefix: "ldap:"},
+	"ldap-group":                   {prefix: "ldap:", group: true},
+	"cf-user":                      {prefix: "cf:"},
+	"cf-org":                       {prefix: "cf:", group: true},
+	"cf-space-with-any-role":       {prefix: "cf:", group: true},
+	"cf-space-with-developer-role": {prefix: "cf:", suffix: ":developer", group: true},
+}
+
+// teamAuthMember is how Concourse records a value of a `fly set-team` auth flag, as a user or a group
+// named with the connector's prefix
+type teamAuthMember struct {
+	prefix string
+	suffix string
+	group  bool
+}
+
+var teamNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// TeamsSpec maps team names to the `fly set-team` auth flags and values for that team
+type TeamsSpec map[string]map[string][]string
+
+// ParseTeamsSpec reads and validates a YAML teams spec
+func ParseTeamsSpec(contents []byte) (TeamsSpec, error) {
+	var spec TeamsSpec
+	if err := yaml.UnmarshalStrict(contents, &spec); err != nil {
+		return nil, fmt.Errorf("teams file is not a YAML map of team names to auth config: [%v]", err)
+	}
+
+	for _, team := range spec.teamNames() {
+		if team == bootstrapTeamName {
+			return nil, fmt.Errorf("teams file cannot configure the %s team, which is managed by control-tower", bootstrapTeamName)
+		}
+		if !teamNamePattern.MatchString(team) {
+			return nil, fmt.Errorf("team name %q is invalid: must be lowercase letters, numbers, - and _", team)
+		}
+		if len(spec[team]) == 0 {
+			return nil, fmt.Errorf("team %q has no auth config, so nobody could log in to it", team)
+		}
+		for flag := range spec[team] {
+			if _, ok := teamAuthFlags[flag]; !ok {
+				return nil, fmt.Errorf("team %q uses unknown auth %q", team, flag)
+			}
+		}
+	}
+	return spec, nil
+}
+
+func (spec TeamsSpec) teamNames() []string {
+	names := make([]string, 0, len(spec))
+	for name := range spec {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setTeamArgs returns the fly arguments that set a team's auth from the spec
+func (spec TeamsSpec) setTeamArgs(team string) []string {
+	flags := make([]string, 0, len(spec[team]))
+	for flag := range spec[team] {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	args := []string{"set-team", "--team-name", team, "--non-interactive"}
+	for _, flag := range flags {
+		for _, value := range spec[team][flag] {
+			args = append(args, "--"+flag, value)
+		}
+	}
+	return args
+}
+
+// staleTeams returns the teams that exist in Concourse but not in the spec, excluding the main team
+func (spec TeamsSpec) staleTeams(existing []string) []string {
+	var stale []string
+	for _, team := range existing {
+		if _, ok := spec[team]; !ok && team != bootstrapTeamName {
+			stale = append(stale, team)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// SetTeams applies a teams spec to Concourse, optionally destroying teams that are not in it
+func (client *Client) SetTeams(spec TeamsSpec, prune bool) error {
+	if err := client.login(); err != nil {
+		return err
+	}
+
+	for _, team := range spec.teamNames() {
+		if err := client.run(spec.setTeamArgs(team)...); err != nil {
+			return fmt.Errorf("failed to set team %s: [%v]", team, err)
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+
+	teamsJSON, stderr, err := client.output("teams", "--json")
+	if err != nil {
+		return fmt.Errorf("failed to list teams: [%v] %s", err, stderr)
+	}
+	var existing []struct {
+		Name string `json:"name"`
+	}
+	if err = json.Unmarshal(teamsJSON, &existing); err != nil {
+		return fmt.Errorf("failed to parse teams: [%v]", err)
+	}
+	names := make([]string, 0, len(existing))
+	for _, team := range existing {
+		names = append(names, team.Name)
+	}
+
+	for _, team := range spec.staleTeams(names) {
+		if _, err := fmt.Fprintf(client.stdout, "Destroying team %s, which is not in the teams file\n", team); err != nil {
+			return err
+		}
+		if err := client.run("destroy-team", "--team-name", team, "--non-interactive"); err != nil {
+			return fmt.Errorf("failed to destroy team %s: [%v]", team, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package fly
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTeamsSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{
+			name: "valid spec",
+			spec: `
+developers:
+  github-team: [my-org:devs]
+  oidc-group: [developers]
+ops:
+  local-user: [admin]
+`,
+		},
+		{
+			name:    "main team",
+			spec:    "main:\n  github-user: [someone]\n",
+			wantErr: "teams file cannot configure the main team, which is managed by control-tower",
+		},
+		{
+			name:    "unknown auth",
+			spec:    "developers:\n  github-orgs: [my-org]\n",
+			wantErr: `team "developers" uses unknown auth "github-orgs"`,
+		},
+		{
+			name:    "no auth",
+			spec:    "developers: {}\n",
+			wantErr: `team "developers" has no auth config, so nobody could log in to it`,
+		},
+		{
+			name:    "invalid team name",
+			spec:    "Developers:\n  github-user: [someone]\n",
+			wantErr: `team name "Developers" is invalid: must be lowercase letters, numbers, - and _`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTeamsSpec([]byte(tt.spec))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ParseTeamsSpec() unexpected error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("ParseTeamsSpec() error = %v, wantErr %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTeamsSpec_setTeamArgs(t *testing.T) {
+	spec := TeamsSpec{
+		"developers": {
+			"oidc-group":  {"developers"},
+			"github-team": {"my-org:devs", "my-org:qa"},
+		},
+	}
+	got := spec.setTeamArgs("developers")
+	want := []string{
+		"set-team", "--team-name", "developers", "--non-interactive",
+		"--github-team", "my-org:devs",
+		"--github-team", "my-org:qa",
+		"--oidc-group", "developers",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("setTeamArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestTeamsSpec_staleTeams(t *testing.T) {
+	spec := TeamsSpec{"developers": {"github-user": {"someone"}}}
+	got := spec.staleTeams([]string{"main", "old-team", "developers", "another"})
+	want := []string{"another", "old-team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("staleTeams() = %v, want %v", got, want)
+	}
+}
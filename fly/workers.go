@@ -0,0 +1,61 @@
+package fly
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Worker is a Concourse worker as listed by fly workers
+type Worker struct {
+	Name             string   `json:"name"`
+	State            string   `json:"state"`
+	Team             string   `json:"team"`
+	Platform         string   `json:"platform"`
+	Tags             []string `json:"tags"`
+	ActiveContainers int      `json:"active_containers"`
+	ActiveVolumes    int      `json:"active_volumes"`
+}
+
+// Workers returns every worker registered with the ATC, whatever its state
+func (client *Client) Workers() ([]Worker, error) {
+	if err := client.login(); err != nil {
+		return nil, err
+	}
+
+	workersJSON, stderr, err := client.output("workers", "--details", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: [%v] %s", err, stderr)
+	}
+
+	var workers []Worker
+	if err = json.Unmarshal(workersJSON, &workers); err != nil {
+		return nil, fmt.Errorf("failed to parse workers: [%v]", err)
+	}
+	return workers, nil
+}
+
+// PruneWorker removes a worker that is no longer running from the ATC, along with its containers and volumes
+func (client *Client) PruneWorker(name string) error {
+	if err := client.login(); err != nil {
+		return err
+	}
+
+	_, stderr, err := client.output("prune-worker", "--worker", name)
+	if err != nil {
+		return fmt.Errorf("failed to prune worker %s: [%v] %s", name, err, stderr)
+	}
+	return nil
+}
+
+// LandWorker asks a running worker to stop taking new work and to leave once the builds running on it have finished
+func (client *Client) LandWorker(name string) error {
+	if err := client.login(); err != nil {
+		return err
+	}
+
+	_, stderr, err := client.output("land-worker", "--worker", name)
+	if err != nil {
+		return fmt.Errorf("failed to land worker %s: [%v] %s", name, err, stderr)
+	}
+	return nil
+}
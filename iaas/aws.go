@@ -3,7 +3,11 @@ package iaas
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,7 +15,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/EngineerBetter/control-tower/db"
 )
 
 // AWSDBSizes maps user set size to RDS instance classes
@@ -24,6 +31,86 @@ var AWSDBSizes = map[string]string{
 	"4xlarge": "db.m4.4xlarge",
 }
 
+// AWSWebInstanceTypes maps user set web size to the instance type in the AWS cloud config
+var AWSWebInstanceTypes = map[string]string{
+	"small":   "t3.small",
+	"medium":  "t3.medium",
+	"large":   "t3.large",
+	"xlarge":  "t3.xlarge",
+	"2xlarge": "t3.2xlarge",
+}
+
+// AWSWorkerInstanceTypes maps worker type and user set worker size to the instance type in the AWS cloud config
+var AWSWorkerInstanceTypes = map[string]map[string]string{
+	"m4": {
+		"medium":   "t3.medium",
+		"large":    "m4.large",
+		"xlarge":   "m4.xlarge",
+		"2xlarge":  "m4.2xlarge",
+		"4xlarge":  "m4.4xlarge",
+		"10xlarge": "m4.10xlarge",
+		"16xlarge": "m4.16xlarge",
+	},
+	"m5": {
+		"medium":   "t3.medium",
+		"large":    "m5.large",
+		"xlarge":   "m5.xlarge",
+		"2xlarge":  "m5.2xlarge",
+		"4xlarge":  "m5.4xlarge",
+		"12xlarge": "m5.12xlarge",
+		"24xlarge": "m5.24xlarge",
+	},
+	"m5a": {
+		"medium":   "t3.medium",
+		"large":    "m5a.large",
+		"xlarge":   "m5a.xlarge",
+		"2xlarge":  "m5a.2xlarge",
+		"4xlarge":  "m5a.4xlarge",
+		"12xlarge": "m5a.12xlarge",
+		"24xlarge": "m5a.24xlarge",
+	},
+}
+
+// AWSOnDemandPrices are the on-demand prices per hour of worker instance types in eu-west-2, which is
+// roughly a middle ground of pricing across regions. Spot workers bid a percentage of these
+var AWSOnDemandPrices = map[string]float64{
+	"t3.medium":    0.0472,
+	"m4.large":     0.116,
+	"m4.xlarge":    0.232,
+	"m4.2xlarge":   0.464,
+	"m4.4xlarge":   0.928,
+	"m4.10xlarge":  2.32,
+	"m4.16xlarge":  3.712,
+	"m5.large":     0.111,
+	"m5.xlarge":    0.222,
+	"m5.2xlarge":   0.444,
+	"m5.4xlarge":   0.888,
+	"m5.12xlarge":  2.664,
+	"m5.24xlarge":  5.328,
+	"m5a.large":    0.100,
+	"m5a.xlarge":   0.200,
+	"m5a.2xlarge":  0.400,
+	"m5a.4xlarge":  0.800,
+	"m5a.12xlarge": 2.400,
+	"m5a.24xlarge": 4.800,
+}
+
+// DefaultAWSWorkerType is the worker type used when --worker-type isn't given
+const DefaultAWSWorkerType = "m4"
+
+// AWSWebVMTypes lists the instance type of each web size, smallest first
+func AWSWebVMTypes() []VMType {
+	return vmTypes(AWSWebInstanceTypes, nil)
+}
+
+// AWSWorkerVMTypes lists the instance type of each worker size of the given worker type, smallest first
+func AWSWorkerVMTypes(workerType string) []VMType {
+	if workerType == "" {
+		workerType = DefaultAWSWorkerType
+	}
+	return vmTypes(AWSWorkerInstanceTypes[workerType], AWSOnDemandPrices)
+}
+
 // AWSProvider is the concrete implementation of AWS Provider
 type AWSProvider struct {
 	sess *session.Session
@@ -62,7 +149,7 @@ func (a *AWSProvider) Zone(requestedZone, workerSize string) string {
 	}
 	ec2Client := ec2.New(a.sess)
 
-	zones, err := a.listZones()
+	zones, err := a.ListZones()
 	if err != nil {
 		return fmt.Sprintf("%sa", a.Region())
 	}
@@ -85,6 +172,18 @@ func (a *AWSProvider) Zone(requestedZone, workerSize string) string {
 	return fmt.Sprintf("%sa", a.Region())
 }
 
+// ValidateZone checks that the zone is available in the provider's region
+func (a *AWSProvider) ValidateZone(zone, webSize, workerSize string) error {
+	zones, err := a.ListZones()
+	if err != nil {
+		return fmt.Errorf("failed to list zones in region %s: [%v]", a.Region(), err)
+	}
+	if !contains(zones, zone) {
+		return fmt.Errorf("zone %s is not an available zone in region %s, choose one of %s", zone, a.Region(), strings.Join(zones, ", "))
+	}
+	return nil
+}
+
 // Attr returns an attribute of the provider
 func (a *AWSProvider) Attr(name string) (string, error) {
 	return "", nil
@@ -100,7 +199,8 @@ func (a *AWSProvider) IAAS() Name {
 	return AWS
 }
 
-func (a *AWSProvider) listZones() ([]string, error) {
+// ListZones returns the names of the available zones in the provider's region
+func (a *AWSProvider) ListZones() ([]string, error) {
 	ec2Client := ec2.New(a.sess)
 	zones := []string{}
 
@@ -125,6 +225,154 @@ func (a *AWSProvider) listZones() ([]string, error) {
 	return zones, nil
 }
 
+// ListInstanceTypes returns the instance types that each web and worker size maps to, with the
+// zones in the provider's region that offer them
+func (a *AWSProvider) ListInstanceTypes() ([]InstanceType, error) {
+	var instanceTypes []InstanceType
+	for _, vmType := range AWSWebVMTypes() {
+		instanceTypes = append(instanceTypes, InstanceType{Role: "web", Size: vmType.Size, Name: vmType.InstanceType})
+	}
+	for workerType := range AWSWorkerInstanceTypes {
+		for _, vmType := range AWSWorkerVMTypes(workerType) {
+			instanceTypes = append(instanceTypes, InstanceType{Role: "worker", Size: vmType.Size, WorkerType: workerType, Name: vmType.InstanceType})
+		}
+	}
+
+	var names []*string
+	for _, name := range uniqueInstanceTypeNames(instanceTypes) {
+		names = append(names, aws.String(name))
+	}
+	zonesOffering := map[string][]string{}
+	err := ec2.New(a.sess).DescribeInstanceTypeOfferingsPages(&ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-type"),
+				Values: names,
+			},
+		},
+	}, func(page *ec2.DescribeInstanceTypeOfferingsOutput, lastPage bool) bool {
+		for _, offering := range page.InstanceTypeOfferings {
+			zonesOffering[*offering.InstanceType] = append(zonesOffering[*offering.InstanceType], *offering.Location)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instance type offerings in region %s: [%v]", a.Region(), err)
+	}
+
+	for i := range instanceTypes {
+		zones := zonesOffering[instanceTypes[i].Name]
+		sort.Strings(zones)
+		instanceTypes[i].Zones = zones
+	}
+	sortInstanceTypes(instanceTypes)
+	return instanceTypes, nil
+}
+
+// DescribeInstanceType looks up the vCPUs, memory and architectures of an instance type, and checks
+// that the zone offers it
+func (a *AWSProvider) DescribeInstanceType(zone, name string) (InstanceTypeSpec, error) {
+	ec2Client := ec2.New(a.sess)
+	o, err := ec2Client.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(name)},
+	})
+	if err != nil || len(o.InstanceTypes) == 0 {
+		return InstanceTypeSpec{}, fmt.Errorf("instance type %s is not available in region %s: [%v]", name, a.Region(), err)
+	}
+
+	offerings, err := ec2Client.DescribeInstanceTypeOfferings(&ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-type"),
+				Values: []*string{aws.String(name)},
+			},
+			{
+				Name:   aws.String("location"),
+				Values: []*string{aws.String(zone)},
+			},
+		},
+	})
+	if err != nil {
+		return InstanceTypeSpec{}, fmt.Errorf("failed to list instance type offerings in zone %s: [%v]", zone, err)
+	}
+	if len(offerings.InstanceTypeOfferings) == 0 {
+		return InstanceTypeSpec{}, fmt.Errorf("zone %s does not offer instance type %s, choose another zone or type", zone, name)
+	}
+
+	info := o.InstanceTypes[0]
+	spec := InstanceTypeSpec{
+		Name:      name,
+		VCPUs:     int(aws.Int64Value(info.VCpuInfo.DefaultVCpus)),
+		MemoryMiB: int(aws.Int64Value(info.MemoryInfo.SizeInMiB)),
+	}
+	for _, arch := range aws.StringValueSlice(info.ProcessorInfo.SupportedArchitectures) {
+		switch arch {
+		case ec2.ArchitectureTypeX8664:
+			spec.Architectures = append(spec.Architectures, "amd64")
+		case ec2.ArchitectureTypeArm64:
+			spec.Architectures = append(spec.Architectures, "arm64")
+		}
+	}
+	return spec, nil
+}
+
+// Quotas returns the account's limits on instances and elastic IPs in the provider's region. Limits
+// on vCPUs per instance family are managed by AWS Service Quotas instead
+func (a *AWSProvider) Quotas() ([]Quota, error) {
+	ec2Client := ec2.New(a.sess)
+	attributes, err := ec2Client.DescribeAccountAttributes(&ec2.DescribeAccountAttributesInput{
+		AttributeNames: aws.StringSlice([]string{"max-instances", "vpc-max-elastic-ips"}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe account attributes in region %s: [%v]", a.Region(), err)
+	}
+
+	usage := map[string]float64{}
+	err = ec2Client.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running"}),
+			},
+		},
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			usage["max-instances"] += float64(len(reservation.Instances))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count instances in region %s: [%v]", a.Region(), err)
+	}
+	addresses, err := ec2Client.DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: aws.StringSlice([]string{"vpc"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count elastic IPs in region %s: [%v]", a.Region(), err)
+	}
+	usage["vpc-max-elastic-ips"] = float64(len(addresses.Addresses))
+
+	var quotas []Quota
+	for _, attribute := range attributes.AccountAttributes {
+		for _, value := range attribute.AttributeValues {
+			limit, err := strconv.ParseFloat(aws.StringValue(value.AttributeValue), 64)
+			if err != nil {
+				continue
+			}
+			quotas = append(quotas, Quota{Name: *attribute.AttributeName, Limit: limit, Usage: usage[*attribute.AttributeName]})
+		}
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Name < quotas[j].Name })
+	return quotas, nil
+}
+
 // CheckForWhitelistedIP checks if the specified IP is whitelisted in the security group
 func (a *AWSProvider) CheckForWhitelistedIP(ip, securityGroup string) (bool, error) {
 
@@ -289,6 +537,108 @@ func DeleteVolume(ec2Client IEC2, volumeID *string) error {
 	return err
 }
 
+// DescribeNetwork describes an existing VPC and the subnets a deployment is placed into. The public and
+// private subnets must share a zone, as the director and web VMs are placed in it, and the private subnet
+// must reach the internet through a NAT gateway, whose address the deployment's security groups allow
+func (a *AWSProvider) DescribeNetwork(vpcID, publicSubnet, privateSubnet string, dbSubnets []string) (Network, error) {
+	ec2Client := ec2.New(a.sess)
+	vpcs, err := ec2Client.DescribeVpcs(&ec2.DescribeVpcsInput{
+		VpcIds: aws.StringSlice([]string{vpcID}),
+	})
+	if err != nil {
+		return Network{}, fmt.Errorf("failed to describe VPC %s in region %s: [%v]", vpcID, a.Region(), err)
+	}
+	if len(vpcs.Vpcs) == 0 {
+		return Network{}, fmt.Errorf("VPC %s does not exist in region %s", vpcID, a.Region())
+	}
+
+	ids := append([]string{publicSubnet, privateSubnet}, dbSubnets...)
+	resp, err := ec2Client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(ids),
+	})
+	if err != nil {
+		return Network{}, fmt.Errorf("failed to describe subnets %s in region %s: [%v]", strings.Join(ids, ", "), a.Region(), err)
+	}
+	subnets := map[string]*ec2.Subnet{}
+	for _, subnet := range resp.Subnets {
+		subnets[aws.StringValue(subnet.SubnetId)] = subnet
+	}
+	for _, id := range ids {
+		subnet, ok := subnets[id]
+		if !ok {
+			return Network{}, fmt.Errorf("subnet %s does not exist in region %s", id, a.Region())
+		}
+		if aws.StringValue(subnet.VpcId) != vpcID {
+			return Network{}, fmt.Errorf("subnet %s is not in VPC %s", id, vpcID)
+		}
+	}
+
+	public, private := subnets[publicSubnet], subnets[privateSubnet]
+	zone := aws.StringValue(public.AvailabilityZone)
+	if aws.StringValue(private.AvailabilityZone) != zone {
+		return Network{}, fmt.Errorf("public subnet %s is in %s and private subnet %s is in %s, but both must be in the same zone", publicSubnet, zone, privateSubnet, aws.StringValue(private.AvailabilityZone))
+	}
+	// RDS needs subnets in two zones for its subnet group
+	if len(dbSubnets) == 2 && aws.StringValue(subnets[dbSubnets[0]].AvailabilityZone) == aws.StringValue(subnets[dbSubnets[1]].AvailabilityZone) {
+		return Network{}, fmt.Errorf("database subnets %s and %s must be in different zones", dbSubnets[0], dbSubnets[1])
+	}
+
+	natIP, err := natGatewayIP(ec2Client, vpcID, privateSubnet)
+	if err != nil {
+		return Network{}, err
+	}
+	return Network{
+		CIDR:        aws.StringValue(vpcs.Vpcs[0].CidrBlock),
+		PublicCIDR:  aws.StringValue(public.CidrBlock),
+		PrivateCIDR: aws.StringValue(private.CidrBlock),
+		Zone:        zone,
+		NATIP:       natIP,
+	}, nil
+}
+
+// natGatewayIP is the public address of the NAT gateway that the subnet's default route goes through. A
+// subnet without a route table of its own uses the main route table of its VPC
+func natGatewayIP(ec2Client *ec2.EC2, vpcID, subnetID string) (string, error) {
+	for _, filter := range []*ec2.Filter{
+		{Name: aws.String("association.subnet-id"), Values: aws.StringSlice([]string{subnetID})},
+		{Name: aws.String("association.main"), Values: aws.StringSlice([]string{"true"})},
+	} {
+		resp, err := ec2Client.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+			Filters: []*ec2.Filter{
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{vpcID})},
+				filter,
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe the route table of subnet %s: [%v]", subnetID, err)
+		}
+		if len(resp.RouteTables) == 0 {
+			continue
+		}
+
+		for _, route := range resp.RouteTables[0].Routes {
+			if aws.StringValue(route.DestinationCidrBlock) != "0.0.0.0/0" || route.NatGatewayId == nil {
+				continue
+			}
+			gateways, err := ec2Client.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{
+				NatGatewayIds: []*string{route.NatGatewayId},
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to describe NAT gateway %s: [%v]", aws.StringValue(route.NatGatewayId), err)
+			}
+			for _, gateway := range gateways.NatGateways {
+				for _, address := range gateway.NatGatewayAddresses {
+					if address.PublicIp != nil {
+						return aws.StringValue(address.PublicIp), nil
+					}
+				}
+			}
+		}
+		break
+	}
+	return "", fmt.Errorf("private subnet %s must reach the internet through a NAT gateway, whose address the deployment's security groups allow", subnetID)
+}
+
 // DeleteVMsInDeployment is a placeholder for a function used with GCP deployments
 func (a *AWSProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
 	return nil
@@ -296,19 +646,26 @@ func (a *AWSProvider) DeleteVMsInDeployment(zone, project, deployment string) er
 
 // DeleteVMsInVPC deletes all the VMs in the given VPC
 func (a *AWSProvider) DeleteVMsInVPC(vpcID string) ([]string, error) {
+	return a.deleteVMs(&ec2.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: aws.StringSlice([]string{vpcID}),
+	})
+}
+
+// DeleteVMsInSecurityGroups deletes the VMs in any of the given security groups, for a deployment in an
+// existing VPC that other VMs share
+func (a *AWSProvider) DeleteVMsInSecurityGroups(groupIDs []string) ([]string, error) {
+	return a.deleteVMs(&ec2.Filter{
+		Name:   aws.String("instance.group-id"),
+		Values: aws.StringSlice(groupIDs),
+	})
+}
 
-	filterName := "vpc-id"
+func (a *AWSProvider) deleteVMs(filter *ec2.Filter) ([]string, error) {
 	ec2Client := ec2.New(a.sess)
 
 	resp, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{
-		Filters: []*ec2.Filter{
-			{
-				Name: &filterName,
-				Values: []*string{
-					&vpcID,
-				},
-			},
-		},
+		Filters: []*ec2.Filter{filter},
 	})
 	if err != nil {
 		return nil, err
@@ -396,7 +753,121 @@ func (a *AWSProvider) FindLongestMatchingHostedZone(subdomain string) (string, s
 	return longestMatchingHostedZoneName, longestMatchingHostedZoneID, err
 }
 
+// SetDNSRecord creates or replaces the record of recordType for name in the Route53 hosted zone zoneID
+func (a *AWSProvider) SetDNSRecord(zoneID, name, recordType, value string) error {
+	_, err := route53.New(a.sess).ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{
+				Action: aws.String(route53.ChangeActionUpsert),
+				ResourceRecordSet: &route53.ResourceRecordSet{
+					Name:            aws.String(name),
+					Type:            aws.String(recordType),
+					TTL:             aws.Int64(60),
+					ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(recordValue(recordType, value))}},
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// DeleteDNSRecord deletes the record of recordType for name from the Route53 hosted zone zoneID, if it has one
+func (a *AWSProvider) DeleteDNSRecord(zoneID, name, recordType string) error {
+	r53Client := route53.New(a.sess)
+	output, err := r53Client.ListResourceRecordSets(&route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zoneID),
+		StartRecordName: aws.String(name),
+		StartRecordType: aws.String(recordType),
+		MaxItems:        aws.String("1"),
+	})
+	if err != nil {
+		return err
+	}
+	if len(output.ResourceRecordSets) == 0 {
+		return nil
+	}
+	existing := output.ResourceRecordSets[0]
+	if strings.TrimSuffix(aws.StringValue(existing.Name), ".") != strings.TrimSuffix(name, ".") || aws.StringValue(existing.Type) != recordType {
+		return nil
+	}
+	_, err = r53Client.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{
+				Action:            aws.String(route53.ChangeActionDelete),
+				ResourceRecordSet: existing,
+			}},
+		},
+	})
+	return err
+}
+
 // CreateDatabases creates databases on the server
 func (a *AWSProvider) CreateDatabases(name, username, password string) error {
 	return fmt.Errorf("not implemented")
 }
+
+// DatabaseAvailability returns the availability of a CloudSQL instance
+func (a *AWSProvider) DatabaseAvailability(name string) (DatabaseAvailability, error) {
+	return DatabaseAvailability{}, fmt.Errorf("not implemented")
+}
+
+// rdsTrustStoreURL is where AWS publishes the bundle of the RDS CAs that sign the certificates of
+// instances in a region
+const rdsTrustStoreURL = "https://truststore.pki.rds.amazonaws.com/%[1]s/%[1]s-bundle.pem"
+
+// DatabaseCACerts returns the bundle of the current RDS CAs of the provider's region, which includes
+// those that RDS instances can be rotated onto. RDS instances share their CAs, so name is unused
+func (a *AWSProvider) DatabaseCACerts(name string) (string, error) {
+	url := fmt.Sprintf(rdsTrustStoreURL, a.Region())
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the RDS CA bundle from %s: [%v]", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch the RDS CA bundle from %s: [%s]", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the RDS CA bundle from %s: [%v]", url, err)
+	}
+	return db.ParseCABundle(string(body))
+}
+
+// AddDatabaseCA isn't needed on AWS, where the CAs an RDS instance can be rotated onto are always published
+func (a *AWSProvider) AddDatabaseCA(name string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// RotateDatabaseCA is done by terraform on AWS, which changes the CA of RDS instances when their
+// ca_cert_identifier does
+func (a *AWSProvider) RotateDatabaseCA(name string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// RebootDatabase restarts an RDS instance, which applies the static parameters of its parameter group, and
+// waits for it to be available again
+func (a *AWSProvider) RebootDatabase(name string) error {
+	rdsClient := rds.New(a.sess)
+	_, err := rdsClient.RebootDBInstance(&rds.RebootDBInstanceInput{
+		DBInstanceIdentifier: aws.String(name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reboot RDS instance %s: [%v]", name, err)
+	}
+	err = rdsClient.WaitUntilDBInstanceAvailable(&rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for RDS instance %s to be available after rebooting: [%v]", name, err)
+	}
+	return nil
+}
+
+// UpgradeDatabase is done by terraform on AWS, which upgrades RDS in place when its engine version changes
+func (a *AWSProvider) UpgradeDatabase(name, version string) error {
+	return fmt.Errorf("not implemented")
+}
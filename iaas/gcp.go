@@ -9,6 +9,10 @@ import (
 	"log"
 	"net"
 	"os"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,8 +20,12 @@ import (
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 	clouddns "google.golang.org/api/dns/v1"
 	"google.golang.org/api/iterator"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+
+	"github.com/EngineerBetter/control-tower/db"
 
 	// PostgreSQL driver required at runtime
 	_ "github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/dialers/postgres"
@@ -194,6 +202,50 @@ func (g *GCPProvider) LoadFile(bucket, path string) ([]byte, error) {
 	return data, nil
 }
 
+// DeleteFile deletes a file from GCS
+func (g *GCPProvider) DeleteFile(bucket, path string) error {
+	return g.storage.Bucket(bucket).Object(path).Delete(g.ctx)
+}
+
+// LoadFileVersion loads a file from GCS along with its generation, to pass to WriteFileIfVersion
+func (g *GCPProvider) LoadFileVersion(bucket, path string) ([]byte, string, error) {
+	rc, err := g.storage.Bucket(bucket).Object(path).NewReader(g.ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, strconv.FormatInt(rc.Attrs.Generation, 10), nil
+}
+
+// WriteFileIfVersion writes the GCS object only if it is still at the generation version, or doesn't exist
+// yet when version is empty, and returns its new generation. It returns false when another writer changed it first
+func (g *GCPProvider) WriteFileIfVersion(bucket, path string, contents []byte, version string) (string, bool, error) {
+	conditions := storage.Conditions{DoesNotExist: true}
+	if version != "" {
+		generation, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid generation %q for %s: [%v]", version, path, err)
+		}
+		conditions = storage.Conditions{GenerationMatch: generation}
+	}
+	wc := g.storage.Bucket(bucket).Object(path).If(conditions).NewWriter(g.ctx)
+
+	if _, err := wc.Write(contents); err != nil {
+		return "", false, fmt.Errorf("failed to write %s to bucket: [%s]", path, err)
+	}
+	if err := wc.Close(); err != nil {
+		if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusPreconditionFailed {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to close writer for %s: [%s]", path, err)
+	}
+	return strconv.FormatInt(wc.Attrs().Generation, 10), true, nil
+}
+
 func (g *GCPProvider) WriteFile(bucket, path string, contents []byte) error {
 	wc := g.storage.Bucket(bucket).Object(path).NewWriter(g.ctx)
 
@@ -212,13 +264,452 @@ func (g *GCPProvider) Region() string {
 	return g.region
 }
 
-//TODO: Choose an appropriate zone based on what zones the region has
+// GCPWebMachineTypes maps user set web size to the machine type in the GCP cloud config
+var GCPWebMachineTypes = map[string]string{
+	"small":   "n1-standard-1",
+	"medium":  "n1-standard-2",
+	"large":   "n1-standard-4",
+	"xlarge":  "n1-standard-8",
+	"2xlarge": "n1-standard-16",
+}
+
+// GCPWorkerMachineTypes maps user set worker size to the machine type in the GCP cloud config
+var GCPWorkerMachineTypes = map[string]string{
+	"medium":   "n1-standard-1",
+	"large":    "n1-standard-2",
+	"xlarge":   "n1-standard-4",
+	"2xlarge":  "n1-standard-8",
+	"4xlarge":  "n1-standard-16",
+	"10xlarge": "n1-standard-32",
+	"16xlarge": "n1-standard-64",
+}
 
-func (g *GCPProvider) Zone(requestedZone, workerSizeNotUsedInGCP string) string {
+// GCPWebVMTypes lists the machine type of each web size, smallest first
+func GCPWebVMTypes() []VMType {
+	return vmTypes(GCPWebMachineTypes, nil)
+}
+
+// GCPWorkerVMTypes lists the machine type of each worker size, smallest first
+func GCPWorkerVMTypes() []VMType {
+	return vmTypes(GCPWorkerMachineTypes, nil)
+}
+
+// Zone returns the requested zone, or else the first zone in the region that offers the
+// machine type for workers of the given size. Without a worker size it returns the region's b zone.
+func (g *GCPProvider) Zone(requestedZone, workerSize string) string {
 	if requestedZone != "" {
 		return requestedZone
 	}
-	return fmt.Sprintf("%s-b", g.region)
+	fallback := fmt.Sprintf("%s-b", g.region)
+	if workerSize == "" {
+		return fallback
+	}
+
+	computeService, err := g.computeService()
+	if err != nil {
+		return fallback
+	}
+	zones, err := g.listZones(computeService)
+	if err != nil {
+		return fallback
+	}
+	for _, z := range zones {
+		available, err := g.zoneMachineTypes(computeService, z)
+		if err != nil {
+			continue
+		}
+		if len(missingMachineTypes(available, GCPWorkerMachineTypes[workerSize])) == 0 {
+			fmt.Printf("Proposed zone for %s worker instances: %s\n", workerSize, z)
+			return z
+		}
+	}
+	return fallback
+}
+
+// ValidateZone checks that the zone is in the provider's region and offers the machine types
+// for the given web and worker sizes
+func (g *GCPProvider) ValidateZone(zone, webSize, workerSize string) error {
+	computeService, err := g.computeService()
+	if err != nil {
+		return err
+	}
+	zones, err := g.listZones(computeService)
+	if err != nil {
+		return fmt.Errorf("failed to list zones in region %s: [%v]", g.region, err)
+	}
+	if !contains(zones, zone) {
+		return fmt.Errorf("zone %s is not an available zone in region %s, choose one of %s", zone, g.region, strings.Join(zones, ", "))
+	}
+
+	available, err := g.zoneMachineTypes(computeService, zone)
+	if err != nil {
+		return fmt.Errorf("failed to list machine types in zone %s: [%v]", zone, err)
+	}
+	if missing := missingMachineTypes(available, GCPWebMachineTypes[webSize], GCPWorkerMachineTypes[workerSize]); len(missing) > 0 {
+		return fmt.Errorf("zone %s does not offer machine types %s, choose another zone or size", zone, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (g *GCPProvider) computeService() (*compute.Service, error) {
+	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
+	if err != nil {
+		return nil, err
+	}
+	return compute.New(c)
+}
+
+// ListZones returns the sorted names of the zones in the provider's region that are up
+func (g *GCPProvider) ListZones() ([]string, error) {
+	computeService, err := g.computeService()
+	if err != nil {
+		return nil, err
+	}
+	return g.listZones(computeService)
+}
+
+// ListInstanceTypes returns the machine types that each web and worker size maps to, with the
+// zones in the provider's region that offer them
+func (g *GCPProvider) ListInstanceTypes() ([]InstanceType, error) {
+	computeService, err := g.computeService()
+	if err != nil {
+		return nil, err
+	}
+	zones, err := g.listZones(computeService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones in region %s: [%v]", g.region, err)
+	}
+
+	var instanceTypes []InstanceType
+	for _, vmType := range GCPWebVMTypes() {
+		instanceTypes = append(instanceTypes, InstanceType{Role: "web", Size: vmType.Size, Name: vmType.InstanceType})
+	}
+	for _, vmType := range GCPWorkerVMTypes() {
+		instanceTypes = append(instanceTypes, InstanceType{Role: "worker", Size: vmType.Size, Name: vmType.InstanceType})
+	}
+
+	for _, zone := range zones {
+		available, err := g.zoneMachineTypes(computeService, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machine types in zone %s: [%v]", zone, err)
+		}
+		for i := range instanceTypes {
+			if available[instanceTypes[i].Name] {
+				instanceTypes[i].Zones = append(instanceTypes[i].Zones, zone)
+			}
+		}
+	}
+	sortInstanceTypes(instanceTypes)
+	return instanceTypes, nil
+}
+
+// gcpARM64MachineFamily matches the Arm machine families, like t2a and c4a. GCE doesn't report a
+// machine type's architecture, but Arm families are named for it
+var gcpARM64MachineFamily = regexp.MustCompile(`^[a-z]+[0-9]+a-`)
+
+// DescribeInstanceType looks up the vCPUs, memory and architecture of a machine type in the zone
+func (g *GCPProvider) DescribeInstanceType(zone, name string) (InstanceTypeSpec, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return InstanceTypeSpec{}, err
+	}
+	computeService, err := g.computeService()
+	if err != nil {
+		return InstanceTypeSpec{}, err
+	}
+	machineType, err := computeService.MachineTypes.Get(project, zone, name).Context(g.ctx).Do()
+	if err != nil {
+		return InstanceTypeSpec{}, fmt.Errorf("zone %s does not offer machine type %s, choose another zone or type: [%v]", zone, name, err)
+	}
+	if machineType.Deprecated != nil && machineType.Deprecated.State != "" && machineType.Deprecated.State != "ACTIVE" {
+		return InstanceTypeSpec{}, fmt.Errorf("machine type %s is %s in zone %s", name, strings.ToLower(machineType.Deprecated.State), zone)
+	}
+
+	arch := "amd64"
+	if gcpARM64MachineFamily.MatchString(name) {
+		arch = "arm64"
+	}
+	return InstanceTypeSpec{
+		Name:          name,
+		VCPUs:         int(machineType.GuestCpus),
+		MemoryMiB:     int(machineType.MemoryMb),
+		Architectures: []string{arch},
+	}, nil
+}
+
+// Quotas returns the project's quotas in the provider's region
+func (g *GCPProvider) Quotas() ([]Quota, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return nil, err
+	}
+	computeService, err := g.computeService()
+	if err != nil {
+		return nil, err
+	}
+	region, err := computeService.Regions.Get(project, g.region).Context(g.ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quotas for region %s: [%v]", g.region, err)
+	}
+
+	var quotas []Quota
+	for _, quota := range region.Quotas {
+		quotas = append(quotas, Quota{Name: quota.Metric, Limit: quota.Limit, Usage: quota.Usage})
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Name < quotas[j].Name })
+	return quotas, nil
+}
+
+// DescribeNetwork describes an existing network and its subnetworks in the provider's region that a
+// deployment is placed into. Subnetworks span the region, so the deployment's zone is still chosen as usual,
+// and dbSubnets are ignored as CloudSQL isn't placed in a subnetwork
+func (g *GCPProvider) DescribeNetwork(network, publicSubnet, privateSubnet string, dbSubnets []string) (Network, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return Network{}, err
+	}
+	computeService, err := g.computeService()
+	if err != nil {
+		return Network{}, err
+	}
+	existing, err := computeService.Networks.Get(project, network).Context(g.ctx).Do()
+	if err != nil {
+		return Network{}, fmt.Errorf("failed to get network %s: [%v]", network, err)
+	}
+
+	cidrs := map[string]string{}
+	for _, name := range []string{publicSubnet, privateSubnet} {
+		subnetwork, err := computeService.Subnetworks.Get(project, g.region, name).Context(g.ctx).Do()
+		if err != nil {
+			return Network{}, fmt.Errorf("failed to get subnetwork %s in region %s: [%v]", name, g.region, err)
+		}
+		if subnetwork.Network != existing.SelfLink {
+			return Network{}, fmt.Errorf("subnetwork %s is not in network %s", name, network)
+		}
+		cidrs[name] = subnetwork.IpCidrRange
+	}
+	return Network{PublicCIDR: cidrs[publicSubnet], PrivateCIDR: cidrs[privateSubnet]}, nil
+}
+
+// DatabaseAvailability returns the availability type of the named CloudSQL instance and the zones of its
+// primary and, when regional, its standby
+func (g *GCPProvider) DatabaseAvailability(name string) (DatabaseAvailability, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return DatabaseAvailability{}, err
+	}
+	sqlService, err := g.sqlService()
+	if err != nil {
+		return DatabaseAvailability{}, err
+	}
+	instance, err := sqlService.Instances.Get(project, name).Context(g.ctx).Do()
+	if err != nil {
+		return DatabaseAvailability{}, fmt.Errorf("failed to get CloudSQL instance %s: [%v]", name, err)
+	}
+
+	availability := DatabaseAvailability{
+		Name:        instance.Name,
+		State:       instance.State,
+		PrimaryZone: instance.GceZone,
+		StandbyZone: instance.SecondaryGceZone,
+	}
+	if instance.Settings != nil {
+		availability.AvailabilityType = instance.Settings.AvailabilityType
+	}
+	return availability, nil
+}
+
+// UpgradeDatabase upgrades the named CloudSQL instance in place to a later database version, such as
+// POSTGRES_15, taking a backup of it first. An instance that already runs the version is left as it is
+func (g *GCPProvider) UpgradeDatabase(name, version string) error {
+	project, err := g.Attr("project")
+	if err != nil {
+		return err
+	}
+	sqlService, err := g.sqlService()
+	if err != nil {
+		return err
+	}
+	instance, err := sqlService.Instances.Get(project, name).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get CloudSQL instance %s: [%v]", name, err)
+	}
+	if instance.DatabaseVersion == version {
+		return nil
+	}
+
+	backup := &sqladmin.BackupRun{Description: fmt.Sprintf("Before upgrading from %s to %s", instance.DatabaseVersion, version)}
+	op, err := sqlService.BackupRuns.Insert(project, name, backup).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to back up CloudSQL instance %s: [%v]", name, err)
+	}
+	if err = g.waitForSQLOperation(sqlService, project, op); err != nil {
+		return fmt.Errorf("failed to back up CloudSQL instance %s: [%v]", name, err)
+	}
+
+	op, err = sqlService.Instances.Patch(project, name, &sqladmin.DatabaseInstance{DatabaseVersion: version}).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to upgrade CloudSQL instance %s to %s: [%v]", name, version, err)
+	}
+	if err = g.waitForSQLOperation(sqlService, project, op); err != nil {
+		return fmt.Errorf("failed to upgrade CloudSQL instance %s to %s: [%v]", name, version, err)
+	}
+	return nil
+}
+
+// DatabaseCACerts returns the CAs of the named CloudSQL instance, which are the active one, any added
+// by AddDatabaseCA that it can be rotated onto, and any it was recently rotated off
+func (g *GCPProvider) DatabaseCACerts(name string) (string, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return "", err
+	}
+	sqlService, err := g.sqlService()
+	if err != nil {
+		return "", err
+	}
+	resp, err := sqlService.Instances.ListServerCas(project, name).Context(g.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the CAs of CloudSQL instance %s: [%v]", name, err)
+	}
+	var certs []string
+	for _, cert := range resp.Certs {
+		certs = append(certs, cert.Cert)
+	}
+	return db.ParseCABundle(strings.Join(certs, "\n"))
+}
+
+// AddDatabaseCA adds a new CA to the named CloudSQL instance, which it can be rotated onto once clients
+// trust it
+func (g *GCPProvider) AddDatabaseCA(name string) error {
+	project, err := g.Attr("project")
+	if err != nil {
+		return err
+	}
+	sqlService, err := g.sqlService()
+	if err != nil {
+		return err
+	}
+	op, err := sqlService.Instances.AddServerCa(project, name).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to add a CA to CloudSQL instance %s: [%v]", name, err)
+	}
+	if err = g.waitForSQLOperation(sqlService, project, op); err != nil {
+		return fmt.Errorf("failed to add a CA to CloudSQL instance %s: [%v]", name, err)
+	}
+	return nil
+}
+
+// RebootDatabase isn't needed on GCP, where CloudSQL restarts the instance itself when a flag that needs it changes
+func (g *GCPProvider) RebootDatabase(name string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// RotateDatabaseCA rotates the named CloudSQL instance onto the CA most recently added by AddDatabaseCA
+func (g *GCPProvider) RotateDatabaseCA(name string) error {
+	project, err := g.Attr("project")
+	if err != nil {
+		return err
+	}
+	sqlService, err := g.sqlService()
+	if err != nil {
+		return err
+	}
+	op, err := sqlService.Instances.RotateServerCa(project, name, &sqladmin.InstancesRotateServerCaRequest{}).Context(g.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to rotate the CA of CloudSQL instance %s: [%v]", name, err)
+	}
+	if err = g.waitForSQLOperation(sqlService, project, op); err != nil {
+		return fmt.Errorf("failed to rotate the CA of CloudSQL instance %s: [%v]", name, err)
+	}
+	return nil
+}
+
+func (g *GCPProvider) sqlService() (*sqladmin.Service, error) {
+	c, err := google.DefaultClient(g.ctx, sqladmin.SqlserviceAdminScope)
+	if err != nil {
+		return nil, err
+	}
+	return sqladmin.New(c)
+}
+
+// waitForSQLOperation polls a CloudSQL operation until it is done, returning the first of any errors it had
+func (g *GCPProvider) waitForSQLOperation(sqlService *sqladmin.Service, project string, op *sqladmin.Operation) error {
+	var err error
+	for op.Status != "DONE" {
+		time.Sleep(10 * time.Second)
+		op, err = sqlService.Operations.Get(project, op.Name).Context(g.ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		return errors.New(op.Error.Errors[0].Message)
+	}
+	return nil
+}
+
+// listZones returns the sorted names of the zones in the provider's region that are up
+func (g *GCPProvider) listZones(computeService *compute.Service) ([]string, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return nil, err
+	}
+
+	regionURLSuffix := fmt.Sprintf("/regions/%s", g.region)
+	var zones []string
+	req := computeService.Zones.List(project)
+	if err := req.Pages(g.ctx, func(page *compute.ZoneList) error {
+		for _, zone := range page.Items {
+			if zone.Status == "UP" && strings.HasSuffix(zone.Region, regionURLSuffix) {
+				zones = append(zones, zone.Name)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+func (g *GCPProvider) zoneMachineTypes(computeService *compute.Service, zone string) (map[string]bool, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return nil, err
+	}
+
+	available := map[string]bool{}
+	req := computeService.MachineTypes.List(project, zone)
+	if err := req.Pages(g.ctx, func(page *compute.MachineTypeList) error {
+		for _, machineType := range page.Items {
+			available[machineType.Name] = true
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return available, nil
+}
+
+// missingMachineTypes returns the machine types that are not available, ignoring empty names
+func missingMachineTypes(available map[string]bool, machineTypes ...string) []string {
+	var missing []string
+	for _, machineType := range machineTypes {
+		if machineType != "" && !available[machineType] && !contains(missing, machineType) {
+			missing = append(missing, machineType)
+		}
+	}
+	return missing
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 func (g *GCPProvider) IAAS() Name {
@@ -300,6 +791,11 @@ func (g *GCPProvider) DeleteVMsInVPC(vpcID string) ([]string, error) {
 	return []string{}, nil
 }
 
+// DeleteVMsInSecurityGroups is a placeholder function used with AWS deployments
+func (g *GCPProvider) DeleteVMsInSecurityGroups(groupIDs []string) ([]string, error) {
+	return []string{}, nil
+}
+
 //DeleteVMsInDeployment will delete all vms in a deployment apart from nat instance
 func (g *GCPProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
 	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
@@ -398,6 +894,52 @@ func (g *GCPProvider) FindLongestMatchingHostedZone(domain string) (string, stri
 	return zoneDnsName, zoneName, err
 }
 
+// SetDNSRecord creates or replaces the record of recordType for name in the Cloud DNS managed zone zoneName
+func (g *GCPProvider) SetDNSRecord(zoneName, name, recordType, value string) error {
+	return g.changeDNSRecord(zoneName, name, recordType, &clouddns.ResourceRecordSet{
+		Name:    dnsName(name),
+		Type:    recordType,
+		Ttl:     60,
+		Rrdatas: []string{recordValue(recordType, value)},
+	})
+}
+
+// DeleteDNSRecord deletes the record of recordType for name from the Cloud DNS managed zone zoneName, if it has one
+func (g *GCPProvider) DeleteDNSRecord(zoneName, name, recordType string) error {
+	return g.changeDNSRecord(zoneName, name, recordType, nil)
+}
+
+// changeDNSRecord replaces the record of recordType for name with addition, deleting it when addition is nil
+func (g *GCPProvider) changeDNSRecord(zoneName, name, recordType string, addition *clouddns.ResourceRecordSet) error {
+	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
+	if err != nil {
+		return err
+	}
+	cloudDNSService, err := clouddns.New(c)
+	if err != nil {
+		return err
+	}
+	project := g.attrs["project"]
+
+	existing, err := cloudDNSService.ResourceRecordSets.List(project, zoneName).Name(dnsName(name)).Type(recordType).Context(g.ctx).Do()
+	if err != nil {
+		return err
+	}
+	change := &clouddns.Change{Deletions: existing.Rrsets}
+	if addition != nil {
+		change.Additions = []*clouddns.ResourceRecordSet{addition}
+	}
+	if len(change.Deletions) == 0 && len(change.Additions) == 0 {
+		return nil
+	}
+	_, err = cloudDNSService.Changes.Create(project, zoneName, change).Context(g.ctx).Do()
+	return err
+}
+
+func dnsName(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
 func getCredentials() (string, string, error) {
 	credsStruct := make(map[string]interface{})
 
@@ -435,13 +977,5 @@ func (g *GCPProvider) CreateDatabases(name, username, password string) error {
 		return err
 	}
 	defer gcpDB.Close()
-	dbNames := []string{"concourse_atc", "uaa", "credhub"}
-	for _, dbName := range dbNames {
-		_, err := gcpDB.Exec("CREATE DATABASE " + dbName)
-		if err != nil && !strings.Contains(err.Error(),
-			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
-			return err
-		}
-	}
-	return nil
+	return db.CreateComponentDatabases(gcpDB, db.ComponentDatabases)
 }
@@ -1,6 +1,7 @@
 package iaas
 
 import (
+	"reflect"
 	"testing"
 
 	"cloud.google.com/go/storage"
@@ -145,3 +146,43 @@ func TestGCPProvider_DBType(t *testing.T) {
 		})
 	}
 }
+
+func Test_missingMachineTypes(t *testing.T) {
+	available := map[string]bool{"n1-standard-1": true, "n1-standard-4": true}
+	tests := []struct {
+		name         string
+		machineTypes []string
+		want         []string
+	}{
+		{
+			name:         "all machine types available",
+			machineTypes: []string{"n1-standard-1", "n1-standard-4"},
+		},
+		{
+			name:         "missing machine types are reported once",
+			machineTypes: []string{"n1-standard-1", "n1-standard-64", "n1-standard-64"},
+			want:         []string{"n1-standard-64"},
+		},
+		{
+			name:         "unknown sizes are ignored",
+			machineTypes: []string{"", "n1-standard-4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingMachineTypes(available, tt.machineTypes...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingMachineTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCPProvider_ZoneWithoutWorkerSize(t *testing.T) {
+	g := &GCPProvider{region: "europe-west2"}
+	if got := g.Zone("", ""); got != "europe-west2-b" {
+		t.Errorf("GCPProvider.Zone() = %v, want europe-west2-b", got)
+	}
+	if got := g.Zone("europe-west2-c", "xlarge"); got != "europe-west2-c" {
+		t.Errorf("GCPProvider.Zone() = %v, want europe-west2-c", got)
+	}
+}
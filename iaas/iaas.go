@@ -2,6 +2,7 @@ package iaas
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -42,27 +43,158 @@ func Validate(name string) (Name, error) {
 	return Unknown, fmt.Errorf("cannot map iaas [%s] as any of %+v", name, names[1:])
 }
 
+// InstanceType is the instance type that a web or worker size maps to, and the zones in the
+// region that offer it
+type InstanceType struct {
+	Role string `json:"role"`
+	Size string `json:"size"`
+	// WorkerType is the --worker-type the size applies to, on AWS only
+	WorkerType string   `json:"worker_type,omitempty"`
+	Name       string   `json:"name"`
+	Zones      []string `json:"zones"`
+}
+
+// InstanceTypeSpec is the shape of an instance type as the provider describes it, for checking a
+// type chosen by name rather than by size
+type InstanceTypeSpec struct {
+	Name      string
+	VCPUs     int
+	MemoryMiB int
+	// Architectures are the CPU architectures the type runs, amd64 and/or arm64
+	Architectures []string
+}
+
+// Quota is a limit on a resource in the provider's region, and how much of it is in use
+type Quota struct {
+	Name  string  `json:"name"`
+	Limit float64 `json:"limit"`
+	Usage float64 `json:"usage"`
+}
+
+// DatabaseAvailability is whether a database instance is zonal, or regional with a standby in a second
+// zone that it fails over to
+type DatabaseAvailability struct {
+	Name             string `json:"name"`
+	AvailabilityType string `json:"availability_type"`
+	State            string `json:"state"`
+	PrimaryZone      string `json:"primary_zone"`
+	StandbyZone      string `json:"standby_zone,omitempty"`
+}
+
+// Network is an existing network that a deployment is placed into rather than creating its own. CIDR, Zone
+// and NATIP are only set on AWS, where the subnets are in one zone and NATIP is the address that the
+// private subnet reaches the internet from
+type Network struct {
+	CIDR        string
+	PublicCIDR  string
+	PrivateCIDR string
+	Zone        string
+	NATIP       string
+}
+
+// sizes are the web and worker sizes, smallest first
+var sizes = []string{"small", "medium", "large", "xlarge", "2xlarge", "4xlarge", "10xlarge", "12xlarge", "16xlarge", "24xlarge"}
+
+// VMType is the instance type that a web or worker size maps to, with its on-demand price per hour
+// where it is known. These are the vm_types of the cloud config, and what list-instance-types reports
+type VMType struct {
+	Size          string
+	InstanceType  string
+	OnDemandPrice float64
+}
+
+// vmTypes lists the instance types that table maps sizes to, smallest first
+func vmTypes(table map[string]string, prices map[string]float64) []VMType {
+	var types []VMType
+	for _, size := range sizes {
+		if instanceType, ok := table[size]; ok {
+			types = append(types, VMType{Size: size, InstanceType: instanceType, OnDemandPrice: prices[instanceType]})
+		}
+	}
+	return types
+}
+
+// sortInstanceTypes orders instance types by role, worker type and then size, smallest first
+func sortInstanceTypes(instanceTypes []InstanceType) {
+	sizeIndex := func(size string) int {
+		for i, s := range sizes {
+			if s == size {
+				return i
+			}
+		}
+		return len(sizes)
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool {
+		a, b := instanceTypes[i], instanceTypes[j]
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		if a.WorkerType != b.WorkerType {
+			return a.WorkerType < b.WorkerType
+		}
+		return sizeIndex(a.Size) < sizeIndex(b.Size)
+	})
+}
+
+// recordValue quotes the value of a TXT record, as Route53 and Cloud DNS expect
+func recordValue(recordType, value string) string {
+	if recordType == "TXT" {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// uniqueInstanceTypeNames returns the sorted names of the instance types, without duplicates
+func uniqueInstanceTypeNames(instanceTypes []InstanceType) []string {
+	var names []string
+	for _, instanceType := range instanceTypes {
+		if !contains(names, instanceType.Name) {
+			names = append(names, instanceType.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 //counterfeiter:generate . Provider
 // Provider represents actions taken against AWS
 type Provider interface {
+	AddDatabaseCA(name string) error
 	Attr(string) (string, error)
 	BucketExists(name string) (bool, error)
 	CheckForWhitelistedIP(ip, securityGroup string) (bool, error)
 	CreateBucket(name string) error
 	CreateDatabases(name, username, password string) error
+	DatabaseAvailability(name string) (DatabaseAvailability, error)
+	DatabaseCACerts(name string) (string, error)
+	DeleteDNSRecord(zoneID, name, recordType string) error
+	DeleteFile(bucket, path string) error
 	DeleteVersionedBucket(name string) error
 	DeleteVMsInDeployment(zone, project, deployment string) error
+	DeleteVMsInSecurityGroups(groupIDs []string) ([]string, error)
 	DeleteVMsInVPC(vpcID string) ([]string, error)
 	DeleteVolumes(volumesToDelete []string, deleteVolume func(ec2Client IEC2, volumeID *string) error) error
 	EnsureFileExists(bucket, path string, defaultContents []byte) ([]byte, bool, error)
 	FindLongestMatchingHostedZone(subdomain string) (string, string, error)
 	HasFile(bucket, path string) (bool, error)
 	DBType(name string) string
+	DescribeInstanceType(zone, name string) (InstanceTypeSpec, error)
+	DescribeNetwork(network, publicSubnet, privateSubnet string, dbSubnets []string) (Network, error)
 	IAAS() Name
+	ListInstanceTypes() ([]InstanceType, error)
+	ListZones() ([]string, error)
 	LoadFile(bucket, path string) ([]byte, error)
+	LoadFileVersion(bucket, path string) ([]byte, string, error)
+	Quotas() ([]Quota, error)
+	RebootDatabase(name string) error
 	Region() string
+	RotateDatabaseCA(name string) error
+	SetDNSRecord(zoneID, name, recordType, value string) error
+	UpgradeDatabase(name, version string) error
 	WriteFile(bucket, path string, contents []byte) error
+	WriteFileIfVersion(bucket, path string, contents []byte, version string) (string, bool, error)
 	Zone(string, string) string
+	ValidateZone(zone, webSize, workerSize string) error
 	Choose(Choice) interface{}
 }
 
@@ -0,0 +1,62 @@
+package iaas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_sortInstanceTypes(t *testing.T) {
+	instanceTypes := []InstanceType{
+		{Role: "worker", Size: "12xlarge", WorkerType: "m5", Name: "m5.12xlarge"},
+		{Role: "worker", Size: "large", WorkerType: "m5", Name: "m5.large"},
+		{Role: "worker", Size: "2xlarge", WorkerType: "m4", Name: "m4.2xlarge"},
+		{Role: "web", Size: "xlarge", Name: "t3.xlarge"},
+		{Role: "web", Size: "small", Name: "t3.small"},
+	}
+
+	sortInstanceTypes(instanceTypes)
+
+	var got []string
+	for _, instanceType := range instanceTypes {
+		got = append(got, instanceType.Name)
+	}
+	want := []string{"t3.small", "t3.xlarge", "m4.2xlarge", "m5.large", "m5.12xlarge"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortInstanceTypes() = %v, want %v", got, want)
+	}
+}
+
+func Test_uniqueInstanceTypeNames(t *testing.T) {
+	instanceTypes := []InstanceType{}
+	for size, name := range AWSWebInstanceTypes {
+		instanceTypes = append(instanceTypes, InstanceType{Role: "web", Size: size, Name: name})
+	}
+	for size, name := range AWSWorkerInstanceTypes["m5"] {
+		instanceTypes = append(instanceTypes, InstanceType{Role: "worker", Size: size, WorkerType: "m5", Name: name})
+	}
+
+	got := uniqueInstanceTypeNames(instanceTypes)
+
+	want := []string{"m5.12xlarge", "m5.24xlarge", "m5.2xlarge", "m5.4xlarge", "m5.large", "m5.xlarge", "t3.2xlarge", "t3.large", "t3.medium", "t3.small", "t3.xlarge"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueInstanceTypeNames() = %v, want %v", got, want)
+	}
+}
+
+func TestAWSWorkerVMTypes(t *testing.T) {
+	var got []string
+	for _, vmType := range AWSWorkerVMTypes("") {
+		got = append(got, vmType.Size+"="+vmType.InstanceType)
+	}
+	want := []string{"medium=t3.medium", "large=m4.large", "xlarge=m4.xlarge", "2xlarge=m4.2xlarge", "4xlarge=m4.4xlarge", "10xlarge=m4.10xlarge", "16xlarge=m4.16xlarge"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AWSWorkerVMTypes() = %v, want %v", got, want)
+	}
+	for workerType := range AWSWorkerInstanceTypes {
+		for _, vmType := range AWSWorkerVMTypes(workerType) {
+			if vmType.OnDemandPrice == 0 {
+				t.Errorf("no on-demand price for %s, so spot workers would bid nothing", vmType.InstanceType)
+			}
+		}
+	}
+}
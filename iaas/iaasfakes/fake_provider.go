@@ -8,6 +8,17 @@ import (
 )
 
 type FakeProvider struct {
+	AddDatabaseCAStub        func(string) error
+	addDatabaseCAMutex       sync.RWMutex
+	addDatabaseCAArgsForCall []struct {
+		arg1 string
+	}
+	addDatabaseCAReturns struct {
+		result1 error
+	}
+	addDatabaseCAReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AttrStub        func(string) (string, error)
 	attrMutex       sync.RWMutex
 	attrArgsForCall []struct {
@@ -94,6 +105,57 @@ type FakeProvider struct {
 	dBTypeReturnsOnCall map[int]struct {
 		result1 string
 	}
+	DatabaseAvailabilityStub        func(string) (iaas.DatabaseAvailability, error)
+	databaseAvailabilityMutex       sync.RWMutex
+	databaseAvailabilityArgsForCall []struct {
+		arg1 string
+	}
+	databaseAvailabilityReturns struct {
+		result1 iaas.DatabaseAvailability
+		result2 error
+	}
+	databaseAvailabilityReturnsOnCall map[int]struct {
+		result1 iaas.DatabaseAvailability
+		result2 error
+	}
+	DatabaseCACertsStub        func(string) (string, error)
+	databaseCACertsMutex       sync.RWMutex
+	databaseCACertsArgsForCall []struct {
+		arg1 string
+	}
+	databaseCACertsReturns struct {
+		result1 string
+		result2 error
+	}
+	databaseCACertsReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	DeleteDNSRecordStub        func(string, string, string) error
+	deleteDNSRecordMutex       sync.RWMutex
+	deleteDNSRecordArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	deleteDNSRecordReturns struct {
+		result1 error
+	}
+	deleteDNSRecordReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteFileStub        func(string, string) error
+	deleteFileMutex       sync.RWMutex
+	deleteFileArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteFileReturns struct {
+		result1 error
+	}
+	deleteFileReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteVMsInDeploymentStub        func(string, string, string) error
 	deleteVMsInDeploymentMutex       sync.RWMutex
 	deleteVMsInDeploymentArgsForCall []struct {
@@ -107,6 +169,19 @@ type FakeProvider struct {
 	deleteVMsInDeploymentReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteVMsInSecurityGroupsStub        func([]string) ([]string, error)
+	deleteVMsInSecurityGroupsMutex       sync.RWMutex
+	deleteVMsInSecurityGroupsArgsForCall []struct {
+		arg1 []string
+	}
+	deleteVMsInSecurityGroupsReturns struct {
+		result1 []string
+		result2 error
+	}
+	deleteVMsInSecurityGroupsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	DeleteVMsInVPCStub        func(string) ([]string, error)
 	deleteVMsInVPCMutex       sync.RWMutex
 	deleteVMsInVPCArgsForCall []struct {
@@ -143,6 +218,36 @@ type FakeProvider struct {
 	deleteVolumesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DescribeInstanceTypeStub        func(string, string) (iaas.InstanceTypeSpec, error)
+	describeInstanceTypeMutex       sync.RWMutex
+	describeInstanceTypeArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	describeInstanceTypeReturns struct {
+		result1 iaas.InstanceTypeSpec
+		result2 error
+	}
+	describeInstanceTypeReturnsOnCall map[int]struct {
+		result1 iaas.InstanceTypeSpec
+		result2 error
+	}
+	DescribeNetworkStub        func(string, string, string, []string) (iaas.Network, error)
+	describeNetworkMutex       sync.RWMutex
+	describeNetworkArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	describeNetworkReturns struct {
+		result1 iaas.Network
+		result2 error
+	}
+	describeNetworkReturnsOnCall map[int]struct {
+		result1 iaas.Network
+		result2 error
+	}
 	EnsureFileExistsStub        func(string, string, []byte) ([]byte, bool, error)
 	ensureFileExistsMutex       sync.RWMutex
 	ensureFileExistsArgsForCall []struct {
@@ -199,6 +304,30 @@ type FakeProvider struct {
 	iAASReturnsOnCall map[int]struct {
 		result1 iaas.Name
 	}
+	ListInstanceTypesStub        func() ([]iaas.InstanceType, error)
+	listInstanceTypesMutex       sync.RWMutex
+	listInstanceTypesArgsForCall []struct {
+	}
+	listInstanceTypesReturns struct {
+		result1 []iaas.InstanceType
+		result2 error
+	}
+	listInstanceTypesReturnsOnCall map[int]struct {
+		result1 []iaas.InstanceType
+		result2 error
+	}
+	ListZonesStub        func() ([]string, error)
+	listZonesMutex       sync.RWMutex
+	listZonesArgsForCall []struct {
+	}
+	listZonesReturns struct {
+		result1 []string
+		result2 error
+	}
+	listZonesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	LoadFileStub        func(string, string) ([]byte, error)
 	loadFileMutex       sync.RWMutex
 	loadFileArgsForCall []struct {
@@ -213,6 +342,45 @@ type FakeProvider struct {
 		result1 []byte
 		result2 error
 	}
+	LoadFileVersionStub        func(string, string) ([]byte, string, error)
+	loadFileVersionMutex       sync.RWMutex
+	loadFileVersionArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	loadFileVersionReturns struct {
+		result1 []byte
+		result2 string
+		result3 error
+	}
+	loadFileVersionReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 string
+		result3 error
+	}
+	QuotasStub        func() ([]iaas.Quota, error)
+	quotasMutex       sync.RWMutex
+	quotasArgsForCall []struct {
+	}
+	quotasReturns struct {
+		result1 []iaas.Quota
+		result2 error
+	}
+	quotasReturnsOnCall map[int]struct {
+		result1 []iaas.Quota
+		result2 error
+	}
+	RebootDatabaseStub        func(string) error
+	rebootDatabaseMutex       sync.RWMutex
+	rebootDatabaseArgsForCall []struct {
+		arg1 string
+	}
+	rebootDatabaseReturns struct {
+		result1 error
+	}
+	rebootDatabaseReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RegionStub        func() string
 	regionMutex       sync.RWMutex
 	regionArgsForCall []struct {
@@ -223,6 +391,56 @@ type FakeProvider struct {
 	regionReturnsOnCall map[int]struct {
 		result1 string
 	}
+	RotateDatabaseCAStub        func(string) error
+	rotateDatabaseCAMutex       sync.RWMutex
+	rotateDatabaseCAArgsForCall []struct {
+		arg1 string
+	}
+	rotateDatabaseCAReturns struct {
+		result1 error
+	}
+	rotateDatabaseCAReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetDNSRecordStub        func(string, string, string, string) error
+	setDNSRecordMutex       sync.RWMutex
+	setDNSRecordArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	setDNSRecordReturns struct {
+		result1 error
+	}
+	setDNSRecordReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpgradeDatabaseStub        func(string, string) error
+	upgradeDatabaseMutex       sync.RWMutex
+	upgradeDatabaseArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	upgradeDatabaseReturns struct {
+		result1 error
+	}
+	upgradeDatabaseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ValidateZoneStub        func(string, string, string) error
+	validateZoneMutex       sync.RWMutex
+	validateZoneArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	validateZoneReturns struct {
+		result1 error
+	}
+	validateZoneReturnsOnCall map[int]struct {
+		result1 error
+	}
 	WriteFileStub        func(string, string, []byte) error
 	writeFileMutex       sync.RWMutex
 	writeFileArgsForCall []struct {
@@ -236,6 +454,24 @@ type FakeProvider struct {
 	writeFileReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WriteFileIfVersionStub        func(string, string, []byte, string) (string, bool, error)
+	writeFileIfVersionMutex       sync.RWMutex
+	writeFileIfVersionArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 string
+	}
+	writeFileIfVersionReturns struct {
+		result1 string
+		result2 bool
+		result3 error
+	}
+	writeFileIfVersionReturnsOnCall map[int]struct {
+		result1 string
+		result2 bool
+		result3 error
+	}
 	ZoneStub        func(string, string) string
 	zoneMutex       sync.RWMutex
 	zoneArgsForCall []struct {
@@ -252,6 +488,67 @@ type FakeProvider struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeProvider) AddDatabaseCA(arg1 string) error {
+	fake.addDatabaseCAMutex.Lock()
+	ret, specificReturn := fake.addDatabaseCAReturnsOnCall[len(fake.addDatabaseCAArgsForCall)]
+	fake.addDatabaseCAArgsForCall = append(fake.addDatabaseCAArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AddDatabaseCAStub
+	fakeReturns := fake.addDatabaseCAReturns
+	fake.recordInvocation("AddDatabaseCA", []interface{}{arg1})
+	fake.addDatabaseCAMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) AddDatabaseCACallCount() int {
+	fake.addDatabaseCAMutex.RLock()
+	defer fake.addDatabaseCAMutex.RUnlock()
+	return len(fake.addDatabaseCAArgsForCall)
+}
+
+func (fake *FakeProvider) AddDatabaseCACalls(stub func(string) error) {
+	fake.addDatabaseCAMutex.Lock()
+	defer fake.addDatabaseCAMutex.Unlock()
+	fake.AddDatabaseCAStub = stub
+}
+
+func (fake *FakeProvider) AddDatabaseCAArgsForCall(i int) string {
+	fake.addDatabaseCAMutex.RLock()
+	defer fake.addDatabaseCAMutex.RUnlock()
+	argsForCall := fake.addDatabaseCAArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) AddDatabaseCAReturns(result1 error) {
+	fake.addDatabaseCAMutex.Lock()
+	defer fake.addDatabaseCAMutex.Unlock()
+	fake.AddDatabaseCAStub = nil
+	fake.addDatabaseCAReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) AddDatabaseCAReturnsOnCall(i int, result1 error) {
+	fake.addDatabaseCAMutex.Lock()
+	defer fake.addDatabaseCAMutex.Unlock()
+	fake.AddDatabaseCAStub = nil
+	if fake.addDatabaseCAReturnsOnCall == nil {
+		fake.addDatabaseCAReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addDatabaseCAReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) Attr(arg1 string) (string, error) {
 	fake.attrMutex.Lock()
 	ret, specificReturn := fake.attrReturnsOnCall[len(fake.attrArgsForCall)]
@@ -691,79 +988,80 @@ func (fake *FakeProvider) DBTypeReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVMsInDeployment(arg1 string, arg2 string, arg3 string) error {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	ret, specificReturn := fake.deleteVMsInDeploymentReturnsOnCall[len(fake.deleteVMsInDeploymentArgsForCall)]
-	fake.deleteVMsInDeploymentArgsForCall = append(fake.deleteVMsInDeploymentArgsForCall, struct {
+func (fake *FakeProvider) DatabaseAvailability(arg1 string) (iaas.DatabaseAvailability, error) {
+	fake.databaseAvailabilityMutex.Lock()
+	ret, specificReturn := fake.databaseAvailabilityReturnsOnCall[len(fake.databaseAvailabilityArgsForCall)]
+	fake.databaseAvailabilityArgsForCall = append(fake.databaseAvailabilityArgsForCall, struct {
 		arg1 string
-		arg2 string
-		arg3 string
-	}{arg1, arg2, arg3})
-	stub := fake.DeleteVMsInDeploymentStub
-	fakeReturns := fake.deleteVMsInDeploymentReturns
-	fake.recordInvocation("DeleteVMsInDeployment", []interface{}{arg1, arg2, arg3})
-	fake.deleteVMsInDeploymentMutex.Unlock()
+	}{arg1})
+	stub := fake.DatabaseAvailabilityStub
+	fakeReturns := fake.databaseAvailabilityReturns
+	fake.recordInvocation("DatabaseAvailability", []interface{}{arg1})
+	fake.databaseAvailabilityMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentCallCount() int {
-	fake.deleteVMsInDeploymentMutex.RLock()
-	defer fake.deleteVMsInDeploymentMutex.RUnlock()
-	return len(fake.deleteVMsInDeploymentArgsForCall)
+func (fake *FakeProvider) DatabaseAvailabilityCallCount() int {
+	fake.databaseAvailabilityMutex.RLock()
+	defer fake.databaseAvailabilityMutex.RUnlock()
+	return len(fake.databaseAvailabilityArgsForCall)
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentCalls(stub func(string, string, string) error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = stub
+func (fake *FakeProvider) DatabaseAvailabilityCalls(stub func(string) (iaas.DatabaseAvailability, error)) {
+	fake.databaseAvailabilityMutex.Lock()
+	defer fake.databaseAvailabilityMutex.Unlock()
+	fake.DatabaseAvailabilityStub = stub
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentArgsForCall(i int) (string, string, string) {
-	fake.deleteVMsInDeploymentMutex.RLock()
-	defer fake.deleteVMsInDeploymentMutex.RUnlock()
-	argsForCall := fake.deleteVMsInDeploymentArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+func (fake *FakeProvider) DatabaseAvailabilityArgsForCall(i int) string {
+	fake.databaseAvailabilityMutex.RLock()
+	defer fake.databaseAvailabilityMutex.RUnlock()
+	argsForCall := fake.databaseAvailabilityArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentReturns(result1 error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = nil
-	fake.deleteVMsInDeploymentReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeProvider) DatabaseAvailabilityReturns(result1 iaas.DatabaseAvailability, result2 error) {
+	fake.databaseAvailabilityMutex.Lock()
+	defer fake.databaseAvailabilityMutex.Unlock()
+	fake.DatabaseAvailabilityStub = nil
+	fake.databaseAvailabilityReturns = struct {
+		result1 iaas.DatabaseAvailability
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentReturnsOnCall(i int, result1 error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = nil
-	if fake.deleteVMsInDeploymentReturnsOnCall == nil {
-		fake.deleteVMsInDeploymentReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeProvider) DatabaseAvailabilityReturnsOnCall(i int, result1 iaas.DatabaseAvailability, result2 error) {
+	fake.databaseAvailabilityMutex.Lock()
+	defer fake.databaseAvailabilityMutex.Unlock()
+	fake.DatabaseAvailabilityStub = nil
+	if fake.databaseAvailabilityReturnsOnCall == nil {
+		fake.databaseAvailabilityReturnsOnCall = make(map[int]struct {
+			result1 iaas.DatabaseAvailability
+			result2 error
 		})
 	}
-	fake.deleteVMsInDeploymentReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.databaseAvailabilityReturnsOnCall[i] = struct {
+		result1 iaas.DatabaseAvailability
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeProvider) DeleteVMsInVPC(arg1 string) ([]string, error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	ret, specificReturn := fake.deleteVMsInVPCReturnsOnCall[len(fake.deleteVMsInVPCArgsForCall)]
-	fake.deleteVMsInVPCArgsForCall = append(fake.deleteVMsInVPCArgsForCall, struct {
+func (fake *FakeProvider) DatabaseCACerts(arg1 string) (string, error) {
+	fake.databaseCACertsMutex.Lock()
+	ret, specificReturn := fake.databaseCACertsReturnsOnCall[len(fake.databaseCACertsArgsForCall)]
+	fake.databaseCACertsArgsForCall = append(fake.databaseCACertsArgsForCall, struct {
 		arg1 string
 	}{arg1})
-	stub := fake.DeleteVMsInVPCStub
-	fakeReturns := fake.deleteVMsInVPCReturns
-	fake.recordInvocation("DeleteVMsInVPC", []interface{}{arg1})
-	fake.deleteVMsInVPCMutex.Unlock()
+	stub := fake.DatabaseCACertsStub
+	fakeReturns := fake.databaseCACertsReturns
+	fake.recordInvocation("DatabaseCACerts", []interface{}{arg1})
+	fake.databaseCACertsMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
@@ -773,53 +1071,374 @@ func (fake *FakeProvider) DeleteVMsInVPC(arg1 string) ([]string, error) {
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeProvider) DeleteVMsInVPCCallCount() int {
-	fake.deleteVMsInVPCMutex.RLock()
-	defer fake.deleteVMsInVPCMutex.RUnlock()
-	return len(fake.deleteVMsInVPCArgsForCall)
+func (fake *FakeProvider) DatabaseCACertsCallCount() int {
+	fake.databaseCACertsMutex.RLock()
+	defer fake.databaseCACertsMutex.RUnlock()
+	return len(fake.databaseCACertsArgsForCall)
 }
 
-func (fake *FakeProvider) DeleteVMsInVPCCalls(stub func(string) ([]string, error)) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = stub
+func (fake *FakeProvider) DatabaseCACertsCalls(stub func(string) (string, error)) {
+	fake.databaseCACertsMutex.Lock()
+	defer fake.databaseCACertsMutex.Unlock()
+	fake.DatabaseCACertsStub = stub
 }
 
-func (fake *FakeProvider) DeleteVMsInVPCArgsForCall(i int) string {
-	fake.deleteVMsInVPCMutex.RLock()
-	defer fake.deleteVMsInVPCMutex.RUnlock()
-	argsForCall := fake.deleteVMsInVPCArgsForCall[i]
+func (fake *FakeProvider) DatabaseCACertsArgsForCall(i int) string {
+	fake.databaseCACertsMutex.RLock()
+	defer fake.databaseCACertsMutex.RUnlock()
+	argsForCall := fake.databaseCACertsArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *FakeProvider) DeleteVMsInVPCReturns(result1 []string, result2 error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = nil
-	fake.deleteVMsInVPCReturns = struct {
-		result1 []string
+func (fake *FakeProvider) DatabaseCACertsReturns(result1 string, result2 error) {
+	fake.databaseCACertsMutex.Lock()
+	defer fake.databaseCACertsMutex.Unlock()
+	fake.DatabaseCACertsStub = nil
+	fake.databaseCACertsReturns = struct {
+		result1 string
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeProvider) DeleteVMsInVPCReturnsOnCall(i int, result1 []string, result2 error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = nil
-	if fake.deleteVMsInVPCReturnsOnCall == nil {
-		fake.deleteVMsInVPCReturnsOnCall = make(map[int]struct {
-			result1 []string
+func (fake *FakeProvider) DatabaseCACertsReturnsOnCall(i int, result1 string, result2 error) {
+	fake.databaseCACertsMutex.Lock()
+	defer fake.databaseCACertsMutex.Unlock()
+	fake.DatabaseCACertsStub = nil
+	if fake.databaseCACertsReturnsOnCall == nil {
+		fake.databaseCACertsReturnsOnCall = make(map[int]struct {
+			result1 string
 			result2 error
 		})
 	}
-	fake.deleteVMsInVPCReturnsOnCall[i] = struct {
-		result1 []string
+	fake.databaseCACertsReturnsOnCall[i] = struct {
+		result1 string
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeProvider) DeleteVersionedBucket(arg1 string) error {
-	fake.deleteVersionedBucketMutex.Lock()
+func (fake *FakeProvider) DeleteDNSRecord(arg1 string, arg2 string, arg3 string) error {
+	fake.deleteDNSRecordMutex.Lock()
+	ret, specificReturn := fake.deleteDNSRecordReturnsOnCall[len(fake.deleteDNSRecordArgsForCall)]
+	fake.deleteDNSRecordArgsForCall = append(fake.deleteDNSRecordArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteDNSRecordStub
+	fakeReturns := fake.deleteDNSRecordReturns
+	fake.recordInvocation("DeleteDNSRecord", []interface{}{arg1, arg2, arg3})
+	fake.deleteDNSRecordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) DeleteDNSRecordCallCount() int {
+	fake.deleteDNSRecordMutex.RLock()
+	defer fake.deleteDNSRecordMutex.RUnlock()
+	return len(fake.deleteDNSRecordArgsForCall)
+}
+
+func (fake *FakeProvider) DeleteDNSRecordCalls(stub func(string, string, string) error) {
+	fake.deleteDNSRecordMutex.Lock()
+	defer fake.deleteDNSRecordMutex.Unlock()
+	fake.DeleteDNSRecordStub = stub
+}
+
+func (fake *FakeProvider) DeleteDNSRecordArgsForCall(i int) (string, string, string) {
+	fake.deleteDNSRecordMutex.RLock()
+	defer fake.deleteDNSRecordMutex.RUnlock()
+	argsForCall := fake.deleteDNSRecordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) DeleteDNSRecordReturns(result1 error) {
+	fake.deleteDNSRecordMutex.Lock()
+	defer fake.deleteDNSRecordMutex.Unlock()
+	fake.DeleteDNSRecordStub = nil
+	fake.deleteDNSRecordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteDNSRecordReturnsOnCall(i int, result1 error) {
+	fake.deleteDNSRecordMutex.Lock()
+	defer fake.deleteDNSRecordMutex.Unlock()
+	fake.DeleteDNSRecordStub = nil
+	if fake.deleteDNSRecordReturnsOnCall == nil {
+		fake.deleteDNSRecordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDNSRecordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteFile(arg1 string, arg2 string) error {
+	fake.deleteFileMutex.Lock()
+	ret, specificReturn := fake.deleteFileReturnsOnCall[len(fake.deleteFileArgsForCall)]
+	fake.deleteFileArgsForCall = append(fake.deleteFileArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteFileStub
+	fakeReturns := fake.deleteFileReturns
+	fake.recordInvocation("DeleteFile", []interface{}{arg1, arg2})
+	fake.deleteFileMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) DeleteFileCallCount() int {
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
+	return len(fake.deleteFileArgsForCall)
+}
+
+func (fake *FakeProvider) DeleteFileCalls(stub func(string, string) error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = stub
+}
+
+func (fake *FakeProvider) DeleteFileArgsForCall(i int) (string, string) {
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
+	argsForCall := fake.deleteFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) DeleteFileReturns(result1 error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = nil
+	fake.deleteFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteFileReturnsOnCall(i int, result1 error) {
+	fake.deleteFileMutex.Lock()
+	defer fake.deleteFileMutex.Unlock()
+	fake.DeleteFileStub = nil
+	if fake.deleteFileReturnsOnCall == nil {
+		fake.deleteFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteVMsInDeployment(arg1 string, arg2 string, arg3 string) error {
+	fake.deleteVMsInDeploymentMutex.Lock()
+	ret, specificReturn := fake.deleteVMsInDeploymentReturnsOnCall[len(fake.deleteVMsInDeploymentArgsForCall)]
+	fake.deleteVMsInDeploymentArgsForCall = append(fake.deleteVMsInDeploymentArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteVMsInDeploymentStub
+	fakeReturns := fake.deleteVMsInDeploymentReturns
+	fake.recordInvocation("DeleteVMsInDeployment", []interface{}{arg1, arg2, arg3})
+	fake.deleteVMsInDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) DeleteVMsInDeploymentCallCount() int {
+	fake.deleteVMsInDeploymentMutex.RLock()
+	defer fake.deleteVMsInDeploymentMutex.RUnlock()
+	return len(fake.deleteVMsInDeploymentArgsForCall)
+}
+
+func (fake *FakeProvider) DeleteVMsInDeploymentCalls(stub func(string, string, string) error) {
+	fake.deleteVMsInDeploymentMutex.Lock()
+	defer fake.deleteVMsInDeploymentMutex.Unlock()
+	fake.DeleteVMsInDeploymentStub = stub
+}
+
+func (fake *FakeProvider) DeleteVMsInDeploymentArgsForCall(i int) (string, string, string) {
+	fake.deleteVMsInDeploymentMutex.RLock()
+	defer fake.deleteVMsInDeploymentMutex.RUnlock()
+	argsForCall := fake.deleteVMsInDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) DeleteVMsInDeploymentReturns(result1 error) {
+	fake.deleteVMsInDeploymentMutex.Lock()
+	defer fake.deleteVMsInDeploymentMutex.Unlock()
+	fake.DeleteVMsInDeploymentStub = nil
+	fake.deleteVMsInDeploymentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteVMsInDeploymentReturnsOnCall(i int, result1 error) {
+	fake.deleteVMsInDeploymentMutex.Lock()
+	defer fake.deleteVMsInDeploymentMutex.Unlock()
+	fake.DeleteVMsInDeploymentStub = nil
+	if fake.deleteVMsInDeploymentReturnsOnCall == nil {
+		fake.deleteVMsInDeploymentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteVMsInDeploymentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroups(arg1 []string) ([]string, error) {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteVMsInSecurityGroupsMutex.Lock()
+	ret, specificReturn := fake.deleteVMsInSecurityGroupsReturnsOnCall[len(fake.deleteVMsInSecurityGroupsArgsForCall)]
+	fake.deleteVMsInSecurityGroupsArgsForCall = append(fake.deleteVMsInSecurityGroupsArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.DeleteVMsInSecurityGroupsStub
+	fakeReturns := fake.deleteVMsInSecurityGroupsReturns
+	fake.recordInvocation("DeleteVMsInSecurityGroups", []interface{}{arg1Copy})
+	fake.deleteVMsInSecurityGroupsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroupsCallCount() int {
+	fake.deleteVMsInSecurityGroupsMutex.RLock()
+	defer fake.deleteVMsInSecurityGroupsMutex.RUnlock()
+	return len(fake.deleteVMsInSecurityGroupsArgsForCall)
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroupsCalls(stub func([]string) ([]string, error)) {
+	fake.deleteVMsInSecurityGroupsMutex.Lock()
+	defer fake.deleteVMsInSecurityGroupsMutex.Unlock()
+	fake.DeleteVMsInSecurityGroupsStub = stub
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroupsArgsForCall(i int) []string {
+	fake.deleteVMsInSecurityGroupsMutex.RLock()
+	defer fake.deleteVMsInSecurityGroupsMutex.RUnlock()
+	argsForCall := fake.deleteVMsInSecurityGroupsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroupsReturns(result1 []string, result2 error) {
+	fake.deleteVMsInSecurityGroupsMutex.Lock()
+	defer fake.deleteVMsInSecurityGroupsMutex.Unlock()
+	fake.DeleteVMsInSecurityGroupsStub = nil
+	fake.deleteVMsInSecurityGroupsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DeleteVMsInSecurityGroupsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.deleteVMsInSecurityGroupsMutex.Lock()
+	defer fake.deleteVMsInSecurityGroupsMutex.Unlock()
+	fake.DeleteVMsInSecurityGroupsStub = nil
+	if fake.deleteVMsInSecurityGroupsReturnsOnCall == nil {
+		fake.deleteVMsInSecurityGroupsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.deleteVMsInSecurityGroupsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DeleteVMsInVPC(arg1 string) ([]string, error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	ret, specificReturn := fake.deleteVMsInVPCReturnsOnCall[len(fake.deleteVMsInVPCArgsForCall)]
+	fake.deleteVMsInVPCArgsForCall = append(fake.deleteVMsInVPCArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteVMsInVPCStub
+	fakeReturns := fake.deleteVMsInVPCReturns
+	fake.recordInvocation("DeleteVMsInVPC", []interface{}{arg1})
+	fake.deleteVMsInVPCMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DeleteVMsInVPCCallCount() int {
+	fake.deleteVMsInVPCMutex.RLock()
+	defer fake.deleteVMsInVPCMutex.RUnlock()
+	return len(fake.deleteVMsInVPCArgsForCall)
+}
+
+func (fake *FakeProvider) DeleteVMsInVPCCalls(stub func(string) ([]string, error)) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = stub
+}
+
+func (fake *FakeProvider) DeleteVMsInVPCArgsForCall(i int) string {
+	fake.deleteVMsInVPCMutex.RLock()
+	defer fake.deleteVMsInVPCMutex.RUnlock()
+	argsForCall := fake.deleteVMsInVPCArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) DeleteVMsInVPCReturns(result1 []string, result2 error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = nil
+	fake.deleteVMsInVPCReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DeleteVMsInVPCReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = nil
+	if fake.deleteVMsInVPCReturnsOnCall == nil {
+		fake.deleteVMsInVPCReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.deleteVMsInVPCReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DeleteVersionedBucket(arg1 string) error {
+	fake.deleteVersionedBucketMutex.Lock()
 	ret, specificReturn := fake.deleteVersionedBucketReturnsOnCall[len(fake.deleteVersionedBucketArgsForCall)]
 	fake.deleteVersionedBucketArgsForCall = append(fake.deleteVersionedBucketArgsForCall, struct {
 		arg1 string
@@ -946,6 +1565,143 @@ func (fake *FakeProvider) DeleteVolumesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeProvider) DescribeInstanceType(arg1 string, arg2 string) (iaas.InstanceTypeSpec, error) {
+	fake.describeInstanceTypeMutex.Lock()
+	ret, specificReturn := fake.describeInstanceTypeReturnsOnCall[len(fake.describeInstanceTypeArgsForCall)]
+	fake.describeInstanceTypeArgsForCall = append(fake.describeInstanceTypeArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DescribeInstanceTypeStub
+	fakeReturns := fake.describeInstanceTypeReturns
+	fake.recordInvocation("DescribeInstanceType", []interface{}{arg1, arg2})
+	fake.describeInstanceTypeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DescribeInstanceTypeCallCount() int {
+	fake.describeInstanceTypeMutex.RLock()
+	defer fake.describeInstanceTypeMutex.RUnlock()
+	return len(fake.describeInstanceTypeArgsForCall)
+}
+
+func (fake *FakeProvider) DescribeInstanceTypeCalls(stub func(string, string) (iaas.InstanceTypeSpec, error)) {
+	fake.describeInstanceTypeMutex.Lock()
+	defer fake.describeInstanceTypeMutex.Unlock()
+	fake.DescribeInstanceTypeStub = stub
+}
+
+func (fake *FakeProvider) DescribeInstanceTypeArgsForCall(i int) (string, string) {
+	fake.describeInstanceTypeMutex.RLock()
+	defer fake.describeInstanceTypeMutex.RUnlock()
+	argsForCall := fake.describeInstanceTypeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) DescribeInstanceTypeReturns(result1 iaas.InstanceTypeSpec, result2 error) {
+	fake.describeInstanceTypeMutex.Lock()
+	defer fake.describeInstanceTypeMutex.Unlock()
+	fake.DescribeInstanceTypeStub = nil
+	fake.describeInstanceTypeReturns = struct {
+		result1 iaas.InstanceTypeSpec
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DescribeInstanceTypeReturnsOnCall(i int, result1 iaas.InstanceTypeSpec, result2 error) {
+	fake.describeInstanceTypeMutex.Lock()
+	defer fake.describeInstanceTypeMutex.Unlock()
+	fake.DescribeInstanceTypeStub = nil
+	if fake.describeInstanceTypeReturnsOnCall == nil {
+		fake.describeInstanceTypeReturnsOnCall = make(map[int]struct {
+			result1 iaas.InstanceTypeSpec
+			result2 error
+		})
+	}
+	fake.describeInstanceTypeReturnsOnCall[i] = struct {
+		result1 iaas.InstanceTypeSpec
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DescribeNetwork(arg1 string, arg2 string, arg3 string, arg4 []string) (iaas.Network, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.describeNetworkMutex.Lock()
+	ret, specificReturn := fake.describeNetworkReturnsOnCall[len(fake.describeNetworkArgsForCall)]
+	fake.describeNetworkArgsForCall = append(fake.describeNetworkArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.DescribeNetworkStub
+	fakeReturns := fake.describeNetworkReturns
+	fake.recordInvocation("DescribeNetwork", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.describeNetworkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DescribeNetworkCallCount() int {
+	fake.describeNetworkMutex.RLock()
+	defer fake.describeNetworkMutex.RUnlock()
+	return len(fake.describeNetworkArgsForCall)
+}
+
+func (fake *FakeProvider) DescribeNetworkCalls(stub func(string, string, string, []string) (iaas.Network, error)) {
+	fake.describeNetworkMutex.Lock()
+	defer fake.describeNetworkMutex.Unlock()
+	fake.DescribeNetworkStub = stub
+}
+
+func (fake *FakeProvider) DescribeNetworkArgsForCall(i int) (string, string, string, []string) {
+	fake.describeNetworkMutex.RLock()
+	defer fake.describeNetworkMutex.RUnlock()
+	argsForCall := fake.describeNetworkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeProvider) DescribeNetworkReturns(result1 iaas.Network, result2 error) {
+	fake.describeNetworkMutex.Lock()
+	defer fake.describeNetworkMutex.Unlock()
+	fake.DescribeNetworkStub = nil
+	fake.describeNetworkReturns = struct {
+		result1 iaas.Network
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DescribeNetworkReturnsOnCall(i int, result1 iaas.Network, result2 error) {
+	fake.describeNetworkMutex.Lock()
+	defer fake.describeNetworkMutex.Unlock()
+	fake.DescribeNetworkStub = nil
+	if fake.describeNetworkReturnsOnCall == nil {
+		fake.describeNetworkReturnsOnCall = make(map[int]struct {
+			result1 iaas.Network
+			result2 error
+		})
+	}
+	fake.describeNetworkReturnsOnCall[i] = struct {
+		result1 iaas.Network
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) EnsureFileExists(arg1 string, arg2 string, arg3 []byte) ([]byte, bool, error) {
 	var arg3Copy []byte
 	if arg3 != nil {
@@ -1200,9 +1956,121 @@ func (fake *FakeProvider) IAASReturnsOnCall(i int, result1 iaas.Name) {
 			result1 iaas.Name
 		})
 	}
-	fake.iAASReturnsOnCall[i] = struct {
-		result1 iaas.Name
-	}{result1}
+	fake.iAASReturnsOnCall[i] = struct {
+		result1 iaas.Name
+	}{result1}
+}
+
+func (fake *FakeProvider) ListInstanceTypes() ([]iaas.InstanceType, error) {
+	fake.listInstanceTypesMutex.Lock()
+	ret, specificReturn := fake.listInstanceTypesReturnsOnCall[len(fake.listInstanceTypesArgsForCall)]
+	fake.listInstanceTypesArgsForCall = append(fake.listInstanceTypesArgsForCall, struct {
+	}{})
+	stub := fake.ListInstanceTypesStub
+	fakeReturns := fake.listInstanceTypesReturns
+	fake.recordInvocation("ListInstanceTypes", []interface{}{})
+	fake.listInstanceTypesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) ListInstanceTypesCallCount() int {
+	fake.listInstanceTypesMutex.RLock()
+	defer fake.listInstanceTypesMutex.RUnlock()
+	return len(fake.listInstanceTypesArgsForCall)
+}
+
+func (fake *FakeProvider) ListInstanceTypesCalls(stub func() ([]iaas.InstanceType, error)) {
+	fake.listInstanceTypesMutex.Lock()
+	defer fake.listInstanceTypesMutex.Unlock()
+	fake.ListInstanceTypesStub = stub
+}
+
+func (fake *FakeProvider) ListInstanceTypesReturns(result1 []iaas.InstanceType, result2 error) {
+	fake.listInstanceTypesMutex.Lock()
+	defer fake.listInstanceTypesMutex.Unlock()
+	fake.ListInstanceTypesStub = nil
+	fake.listInstanceTypesReturns = struct {
+		result1 []iaas.InstanceType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListInstanceTypesReturnsOnCall(i int, result1 []iaas.InstanceType, result2 error) {
+	fake.listInstanceTypesMutex.Lock()
+	defer fake.listInstanceTypesMutex.Unlock()
+	fake.ListInstanceTypesStub = nil
+	if fake.listInstanceTypesReturnsOnCall == nil {
+		fake.listInstanceTypesReturnsOnCall = make(map[int]struct {
+			result1 []iaas.InstanceType
+			result2 error
+		})
+	}
+	fake.listInstanceTypesReturnsOnCall[i] = struct {
+		result1 []iaas.InstanceType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListZones() ([]string, error) {
+	fake.listZonesMutex.Lock()
+	ret, specificReturn := fake.listZonesReturnsOnCall[len(fake.listZonesArgsForCall)]
+	fake.listZonesArgsForCall = append(fake.listZonesArgsForCall, struct {
+	}{})
+	stub := fake.ListZonesStub
+	fakeReturns := fake.listZonesReturns
+	fake.recordInvocation("ListZones", []interface{}{})
+	fake.listZonesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) ListZonesCallCount() int {
+	fake.listZonesMutex.RLock()
+	defer fake.listZonesMutex.RUnlock()
+	return len(fake.listZonesArgsForCall)
+}
+
+func (fake *FakeProvider) ListZonesCalls(stub func() ([]string, error)) {
+	fake.listZonesMutex.Lock()
+	defer fake.listZonesMutex.Unlock()
+	fake.ListZonesStub = stub
+}
+
+func (fake *FakeProvider) ListZonesReturns(result1 []string, result2 error) {
+	fake.listZonesMutex.Lock()
+	defer fake.listZonesMutex.Unlock()
+	fake.ListZonesStub = nil
+	fake.listZonesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListZonesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listZonesMutex.Lock()
+	defer fake.listZonesMutex.Unlock()
+	fake.ListZonesStub = nil
+	if fake.listZonesReturnsOnCall == nil {
+		fake.listZonesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listZonesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeProvider) LoadFile(arg1 string, arg2 string) ([]byte, error) {
@@ -1270,6 +2138,191 @@ func (fake *FakeProvider) LoadFileReturnsOnCall(i int, result1 []byte, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeProvider) LoadFileVersion(arg1 string, arg2 string) ([]byte, string, error) {
+	fake.loadFileVersionMutex.Lock()
+	ret, specificReturn := fake.loadFileVersionReturnsOnCall[len(fake.loadFileVersionArgsForCall)]
+	fake.loadFileVersionArgsForCall = append(fake.loadFileVersionArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LoadFileVersionStub
+	fakeReturns := fake.loadFileVersionReturns
+	fake.recordInvocation("LoadFileVersion", []interface{}{arg1, arg2})
+	fake.loadFileVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeProvider) LoadFileVersionCallCount() int {
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
+	return len(fake.loadFileVersionArgsForCall)
+}
+
+func (fake *FakeProvider) LoadFileVersionCalls(stub func(string, string) ([]byte, string, error)) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = stub
+}
+
+func (fake *FakeProvider) LoadFileVersionArgsForCall(i int) (string, string) {
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
+	argsForCall := fake.loadFileVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) LoadFileVersionReturns(result1 []byte, result2 string, result3 error) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = nil
+	fake.loadFileVersionReturns = struct {
+		result1 []byte
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeProvider) LoadFileVersionReturnsOnCall(i int, result1 []byte, result2 string, result3 error) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = nil
+	if fake.loadFileVersionReturnsOnCall == nil {
+		fake.loadFileVersionReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 string
+			result3 error
+		})
+	}
+	fake.loadFileVersionReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeProvider) Quotas() ([]iaas.Quota, error) {
+	fake.quotasMutex.Lock()
+	ret, specificReturn := fake.quotasReturnsOnCall[len(fake.quotasArgsForCall)]
+	fake.quotasArgsForCall = append(fake.quotasArgsForCall, struct {
+	}{})
+	stub := fake.QuotasStub
+	fakeReturns := fake.quotasReturns
+	fake.recordInvocation("Quotas", []interface{}{})
+	fake.quotasMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) QuotasCallCount() int {
+	fake.quotasMutex.RLock()
+	defer fake.quotasMutex.RUnlock()
+	return len(fake.quotasArgsForCall)
+}
+
+func (fake *FakeProvider) QuotasCalls(stub func() ([]iaas.Quota, error)) {
+	fake.quotasMutex.Lock()
+	defer fake.quotasMutex.Unlock()
+	fake.QuotasStub = stub
+}
+
+func (fake *FakeProvider) QuotasReturns(result1 []iaas.Quota, result2 error) {
+	fake.quotasMutex.Lock()
+	defer fake.quotasMutex.Unlock()
+	fake.QuotasStub = nil
+	fake.quotasReturns = struct {
+		result1 []iaas.Quota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) QuotasReturnsOnCall(i int, result1 []iaas.Quota, result2 error) {
+	fake.quotasMutex.Lock()
+	defer fake.quotasMutex.Unlock()
+	fake.QuotasStub = nil
+	if fake.quotasReturnsOnCall == nil {
+		fake.quotasReturnsOnCall = make(map[int]struct {
+			result1 []iaas.Quota
+			result2 error
+		})
+	}
+	fake.quotasReturnsOnCall[i] = struct {
+		result1 []iaas.Quota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) RebootDatabase(arg1 string) error {
+	fake.rebootDatabaseMutex.Lock()
+	ret, specificReturn := fake.rebootDatabaseReturnsOnCall[len(fake.rebootDatabaseArgsForCall)]
+	fake.rebootDatabaseArgsForCall = append(fake.rebootDatabaseArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RebootDatabaseStub
+	fakeReturns := fake.rebootDatabaseReturns
+	fake.recordInvocation("RebootDatabase", []interface{}{arg1})
+	fake.rebootDatabaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) RebootDatabaseCallCount() int {
+	fake.rebootDatabaseMutex.RLock()
+	defer fake.rebootDatabaseMutex.RUnlock()
+	return len(fake.rebootDatabaseArgsForCall)
+}
+
+func (fake *FakeProvider) RebootDatabaseCalls(stub func(string) error) {
+	fake.rebootDatabaseMutex.Lock()
+	defer fake.rebootDatabaseMutex.Unlock()
+	fake.RebootDatabaseStub = stub
+}
+
+func (fake *FakeProvider) RebootDatabaseArgsForCall(i int) string {
+	fake.rebootDatabaseMutex.RLock()
+	defer fake.rebootDatabaseMutex.RUnlock()
+	argsForCall := fake.rebootDatabaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) RebootDatabaseReturns(result1 error) {
+	fake.rebootDatabaseMutex.Lock()
+	defer fake.rebootDatabaseMutex.Unlock()
+	fake.RebootDatabaseStub = nil
+	fake.rebootDatabaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) RebootDatabaseReturnsOnCall(i int, result1 error) {
+	fake.rebootDatabaseMutex.Lock()
+	defer fake.rebootDatabaseMutex.Unlock()
+	fake.RebootDatabaseStub = nil
+	if fake.rebootDatabaseReturnsOnCall == nil {
+		fake.rebootDatabaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rebootDatabaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) Region() string {
 	fake.regionMutex.Lock()
 	ret, specificReturn := fake.regionReturnsOnCall[len(fake.regionArgsForCall)]
@@ -1323,6 +2376,256 @@ func (fake *FakeProvider) RegionReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeProvider) RotateDatabaseCA(arg1 string) error {
+	fake.rotateDatabaseCAMutex.Lock()
+	ret, specificReturn := fake.rotateDatabaseCAReturnsOnCall[len(fake.rotateDatabaseCAArgsForCall)]
+	fake.rotateDatabaseCAArgsForCall = append(fake.rotateDatabaseCAArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RotateDatabaseCAStub
+	fakeReturns := fake.rotateDatabaseCAReturns
+	fake.recordInvocation("RotateDatabaseCA", []interface{}{arg1})
+	fake.rotateDatabaseCAMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) RotateDatabaseCACallCount() int {
+	fake.rotateDatabaseCAMutex.RLock()
+	defer fake.rotateDatabaseCAMutex.RUnlock()
+	return len(fake.rotateDatabaseCAArgsForCall)
+}
+
+func (fake *FakeProvider) RotateDatabaseCACalls(stub func(string) error) {
+	fake.rotateDatabaseCAMutex.Lock()
+	defer fake.rotateDatabaseCAMutex.Unlock()
+	fake.RotateDatabaseCAStub = stub
+}
+
+func (fake *FakeProvider) RotateDatabaseCAArgsForCall(i int) string {
+	fake.rotateDatabaseCAMutex.RLock()
+	defer fake.rotateDatabaseCAMutex.RUnlock()
+	argsForCall := fake.rotateDatabaseCAArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) RotateDatabaseCAReturns(result1 error) {
+	fake.rotateDatabaseCAMutex.Lock()
+	defer fake.rotateDatabaseCAMutex.Unlock()
+	fake.RotateDatabaseCAStub = nil
+	fake.rotateDatabaseCAReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) RotateDatabaseCAReturnsOnCall(i int, result1 error) {
+	fake.rotateDatabaseCAMutex.Lock()
+	defer fake.rotateDatabaseCAMutex.Unlock()
+	fake.RotateDatabaseCAStub = nil
+	if fake.rotateDatabaseCAReturnsOnCall == nil {
+		fake.rotateDatabaseCAReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rotateDatabaseCAReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) SetDNSRecord(arg1 string, arg2 string, arg3 string, arg4 string) error {
+	fake.setDNSRecordMutex.Lock()
+	ret, specificReturn := fake.setDNSRecordReturnsOnCall[len(fake.setDNSRecordArgsForCall)]
+	fake.setDNSRecordArgsForCall = append(fake.setDNSRecordArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.SetDNSRecordStub
+	fakeReturns := fake.setDNSRecordReturns
+	fake.recordInvocation("SetDNSRecord", []interface{}{arg1, arg2, arg3, arg4})
+	fake.setDNSRecordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) SetDNSRecordCallCount() int {
+	fake.setDNSRecordMutex.RLock()
+	defer fake.setDNSRecordMutex.RUnlock()
+	return len(fake.setDNSRecordArgsForCall)
+}
+
+func (fake *FakeProvider) SetDNSRecordCalls(stub func(string, string, string, string) error) {
+	fake.setDNSRecordMutex.Lock()
+	defer fake.setDNSRecordMutex.Unlock()
+	fake.SetDNSRecordStub = stub
+}
+
+func (fake *FakeProvider) SetDNSRecordArgsForCall(i int) (string, string, string, string) {
+	fake.setDNSRecordMutex.RLock()
+	defer fake.setDNSRecordMutex.RUnlock()
+	argsForCall := fake.setDNSRecordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeProvider) SetDNSRecordReturns(result1 error) {
+	fake.setDNSRecordMutex.Lock()
+	defer fake.setDNSRecordMutex.Unlock()
+	fake.SetDNSRecordStub = nil
+	fake.setDNSRecordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) SetDNSRecordReturnsOnCall(i int, result1 error) {
+	fake.setDNSRecordMutex.Lock()
+	defer fake.setDNSRecordMutex.Unlock()
+	fake.SetDNSRecordStub = nil
+	if fake.setDNSRecordReturnsOnCall == nil {
+		fake.setDNSRecordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setDNSRecordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) UpgradeDatabase(arg1 string, arg2 string) error {
+	fake.upgradeDatabaseMutex.Lock()
+	ret, specificReturn := fake.upgradeDatabaseReturnsOnCall[len(fake.upgradeDatabaseArgsForCall)]
+	fake.upgradeDatabaseArgsForCall = append(fake.upgradeDatabaseArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UpgradeDatabaseStub
+	fakeReturns := fake.upgradeDatabaseReturns
+	fake.recordInvocation("UpgradeDatabase", []interface{}{arg1, arg2})
+	fake.upgradeDatabaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) UpgradeDatabaseCallCount() int {
+	fake.upgradeDatabaseMutex.RLock()
+	defer fake.upgradeDatabaseMutex.RUnlock()
+	return len(fake.upgradeDatabaseArgsForCall)
+}
+
+func (fake *FakeProvider) UpgradeDatabaseCalls(stub func(string, string) error) {
+	fake.upgradeDatabaseMutex.Lock()
+	defer fake.upgradeDatabaseMutex.Unlock()
+	fake.UpgradeDatabaseStub = stub
+}
+
+func (fake *FakeProvider) UpgradeDatabaseArgsForCall(i int) (string, string) {
+	fake.upgradeDatabaseMutex.RLock()
+	defer fake.upgradeDatabaseMutex.RUnlock()
+	argsForCall := fake.upgradeDatabaseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) UpgradeDatabaseReturns(result1 error) {
+	fake.upgradeDatabaseMutex.Lock()
+	defer fake.upgradeDatabaseMutex.Unlock()
+	fake.UpgradeDatabaseStub = nil
+	fake.upgradeDatabaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) UpgradeDatabaseReturnsOnCall(i int, result1 error) {
+	fake.upgradeDatabaseMutex.Lock()
+	defer fake.upgradeDatabaseMutex.Unlock()
+	fake.UpgradeDatabaseStub = nil
+	if fake.upgradeDatabaseReturnsOnCall == nil {
+		fake.upgradeDatabaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.upgradeDatabaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ValidateZone(arg1 string, arg2 string, arg3 string) error {
+	fake.validateZoneMutex.Lock()
+	ret, specificReturn := fake.validateZoneReturnsOnCall[len(fake.validateZoneArgsForCall)]
+	fake.validateZoneArgsForCall = append(fake.validateZoneArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ValidateZoneStub
+	fakeReturns := fake.validateZoneReturns
+	fake.recordInvocation("ValidateZone", []interface{}{arg1, arg2, arg3})
+	fake.validateZoneMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) ValidateZoneCallCount() int {
+	fake.validateZoneMutex.RLock()
+	defer fake.validateZoneMutex.RUnlock()
+	return len(fake.validateZoneArgsForCall)
+}
+
+func (fake *FakeProvider) ValidateZoneCalls(stub func(string, string, string) error) {
+	fake.validateZoneMutex.Lock()
+	defer fake.validateZoneMutex.Unlock()
+	fake.ValidateZoneStub = stub
+}
+
+func (fake *FakeProvider) ValidateZoneArgsForCall(i int) (string, string, string) {
+	fake.validateZoneMutex.RLock()
+	defer fake.validateZoneMutex.RUnlock()
+	argsForCall := fake.validateZoneArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) ValidateZoneReturns(result1 error) {
+	fake.validateZoneMutex.Lock()
+	defer fake.validateZoneMutex.Unlock()
+	fake.ValidateZoneStub = nil
+	fake.validateZoneReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ValidateZoneReturnsOnCall(i int, result1 error) {
+	fake.validateZoneMutex.Lock()
+	defer fake.validateZoneMutex.Unlock()
+	fake.ValidateZoneStub = nil
+	if fake.validateZoneReturnsOnCall == nil {
+		fake.validateZoneReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.validateZoneReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) WriteFile(arg1 string, arg2 string, arg3 []byte) error {
 	var arg3Copy []byte
 	if arg3 != nil {
@@ -1391,6 +2694,81 @@ func (fake *FakeProvider) WriteFileReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeProvider) WriteFileIfVersion(arg1 string, arg2 string, arg3 []byte, arg4 string) (string, bool, error) {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.writeFileIfVersionMutex.Lock()
+	ret, specificReturn := fake.writeFileIfVersionReturnsOnCall[len(fake.writeFileIfVersionArgsForCall)]
+	fake.writeFileIfVersionArgsForCall = append(fake.writeFileIfVersionArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+		arg4 string
+	}{arg1, arg2, arg3Copy, arg4})
+	stub := fake.WriteFileIfVersionStub
+	fakeReturns := fake.writeFileIfVersionReturns
+	fake.recordInvocation("WriteFileIfVersion", []interface{}{arg1, arg2, arg3Copy, arg4})
+	fake.writeFileIfVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeProvider) WriteFileIfVersionCallCount() int {
+	fake.writeFileIfVersionMutex.RLock()
+	defer fake.writeFileIfVersionMutex.RUnlock()
+	return len(fake.writeFileIfVersionArgsForCall)
+}
+
+func (fake *FakeProvider) WriteFileIfVersionCalls(stub func(string, string, []byte, string) (string, bool, error)) {
+	fake.writeFileIfVersionMutex.Lock()
+	defer fake.writeFileIfVersionMutex.Unlock()
+	fake.WriteFileIfVersionStub = stub
+}
+
+func (fake *FakeProvider) WriteFileIfVersionArgsForCall(i int) (string, string, []byte, string) {
+	fake.writeFileIfVersionMutex.RLock()
+	defer fake.writeFileIfVersionMutex.RUnlock()
+	argsForCall := fake.writeFileIfVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeProvider) WriteFileIfVersionReturns(result1 string, result2 bool, result3 error) {
+	fake.writeFileIfVersionMutex.Lock()
+	defer fake.writeFileIfVersionMutex.Unlock()
+	fake.WriteFileIfVersionStub = nil
+	fake.writeFileIfVersionReturns = struct {
+		result1 string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeProvider) WriteFileIfVersionReturnsOnCall(i int, result1 string, result2 bool, result3 error) {
+	fake.writeFileIfVersionMutex.Lock()
+	defer fake.writeFileIfVersionMutex.Unlock()
+	fake.WriteFileIfVersionStub = nil
+	if fake.writeFileIfVersionReturnsOnCall == nil {
+		fake.writeFileIfVersionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 bool
+			result3 error
+		})
+	}
+	fake.writeFileIfVersionReturnsOnCall[i] = struct {
+		result1 string
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeProvider) Zone(arg1 string, arg2 string) string {
 	fake.zoneMutex.Lock()
 	ret, specificReturn := fake.zoneReturnsOnCall[len(fake.zoneArgsForCall)]
@@ -1456,6 +2834,8 @@ func (fake *FakeProvider) ZoneReturnsOnCall(i int, result1 string) {
 func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.addDatabaseCAMutex.RLock()
+	defer fake.addDatabaseCAMutex.RUnlock()
 	fake.attrMutex.RLock()
 	defer fake.attrMutex.RUnlock()
 	fake.bucketExistsMutex.RLock()
@@ -1470,14 +2850,28 @@ func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	defer fake.createDatabasesMutex.RUnlock()
 	fake.dBTypeMutex.RLock()
 	defer fake.dBTypeMutex.RUnlock()
+	fake.databaseAvailabilityMutex.RLock()
+	defer fake.databaseAvailabilityMutex.RUnlock()
+	fake.databaseCACertsMutex.RLock()
+	defer fake.databaseCACertsMutex.RUnlock()
+	fake.deleteDNSRecordMutex.RLock()
+	defer fake.deleteDNSRecordMutex.RUnlock()
+	fake.deleteFileMutex.RLock()
+	defer fake.deleteFileMutex.RUnlock()
 	fake.deleteVMsInDeploymentMutex.RLock()
 	defer fake.deleteVMsInDeploymentMutex.RUnlock()
+	fake.deleteVMsInSecurityGroupsMutex.RLock()
+	defer fake.deleteVMsInSecurityGroupsMutex.RUnlock()
 	fake.deleteVMsInVPCMutex.RLock()
 	defer fake.deleteVMsInVPCMutex.RUnlock()
 	fake.deleteVersionedBucketMutex.RLock()
 	defer fake.deleteVersionedBucketMutex.RUnlock()
 	fake.deleteVolumesMutex.RLock()
 	defer fake.deleteVolumesMutex.RUnlock()
+	fake.describeInstanceTypeMutex.RLock()
+	defer fake.describeInstanceTypeMutex.RUnlock()
+	fake.describeNetworkMutex.RLock()
+	defer fake.describeNetworkMutex.RUnlock()
 	fake.ensureFileExistsMutex.RLock()
 	defer fake.ensureFileExistsMutex.RUnlock()
 	fake.findLongestMatchingHostedZoneMutex.RLock()
@@ -1486,12 +2880,32 @@ func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	defer fake.hasFileMutex.RUnlock()
 	fake.iAASMutex.RLock()
 	defer fake.iAASMutex.RUnlock()
+	fake.listInstanceTypesMutex.RLock()
+	defer fake.listInstanceTypesMutex.RUnlock()
+	fake.listZonesMutex.RLock()
+	defer fake.listZonesMutex.RUnlock()
 	fake.loadFileMutex.RLock()
 	defer fake.loadFileMutex.RUnlock()
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
+	fake.quotasMutex.RLock()
+	defer fake.quotasMutex.RUnlock()
+	fake.rebootDatabaseMutex.RLock()
+	defer fake.rebootDatabaseMutex.RUnlock()
 	fake.regionMutex.RLock()
 	defer fake.regionMutex.RUnlock()
+	fake.rotateDatabaseCAMutex.RLock()
+	defer fake.rotateDatabaseCAMutex.RUnlock()
+	fake.setDNSRecordMutex.RLock()
+	defer fake.setDNSRecordMutex.RUnlock()
+	fake.upgradeDatabaseMutex.RLock()
+	defer fake.upgradeDatabaseMutex.RUnlock()
+	fake.validateZoneMutex.RLock()
+	defer fake.validateZoneMutex.RUnlock()
 	fake.writeFileMutex.RLock()
 	defer fake.writeFileMutex.RUnlock()
+	fake.writeFileIfVersionMutex.RLock()
+	defer fake.writeFileIfVersionMutex.RUnlock()
 	fake.zoneMutex.RLock()
 	defer fake.zoneMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"io/ioutil"
+	"net/http"
 
 	"time"
 
@@ -203,6 +204,46 @@ func (client *AWSProvider) LoadFile(bucket, path string) ([]byte, error) {
 	return ioutil.ReadAll(output.Body)
 }
 
+// LoadFileVersion loads a file from S3 along with its ETag, to pass to WriteFileIfVersion
+func (client *AWSProvider) LoadFileVersion(bucket, path string) ([]byte, string, error) {
+	s3Client := s3.New(client.sess)
+
+	output, err := s3Client.GetObject(&s3.GetObjectInput{Bucket: &bucket, Key: &path})
+	if err != nil {
+		return nil, "", err
+	}
+	contents, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return contents, aws.StringValue(output.ETag), nil
+}
+
+// WriteFileIfVersion writes the S3 object only if it still has the ETag version, or doesn't exist yet when
+// version is empty, and returns its new ETag. It returns false when another writer changed it first
+func (client *AWSProvider) WriteFileIfVersion(bucket, path string, contents []byte, version string) (string, bool, error) {
+	s3Client := s3.New(client.sess)
+
+	req, output := s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &path,
+		Body:   bytes.NewReader(contents),
+	})
+	// The pinned SDK predates conditional writes, so the headers are set on the request directly
+	if version == "" {
+		req.HTTPRequest.Header.Set("If-None-Match", "*")
+	} else {
+		req.HTTPRequest.Header.Set("If-Match", version)
+	}
+	if err := req.Send(); err != nil {
+		if failure, ok := err.(awserr.RequestFailure); ok && (failure.StatusCode() == http.StatusPreconditionFailed || failure.StatusCode() == http.StatusConflict) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return aws.StringValue(output.ETag), true, nil
+}
+
 // DeleteFile deletes a file from S3
 func (client *AWSProvider) DeleteFile(bucket, path string) error {
 
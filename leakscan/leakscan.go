@@ -0,0 +1,138 @@
+package leakscan
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	// expectedField matches the names of fields that are meant to hold credentials
+	expectedField = regexp.MustCompile(`(?i)(password|passwd|secret|token|key|cert|(^|[^a-z])ca($|[^a-z])|private|credential|hmac|salt|signing)`)
+	// checksumField matches the names of fields holding high-entropy values that aren't secret
+	checksumField = regexp.MustCompile(`(?i)(sha1|sha256|sha512|digest|checksum|fingerprint|uuid|(^|_)id$)`)
+
+	base64Token   = regexp.MustCompile(`[A-Za-z0-9+/=_\-]{20,}`)
+	hexToken      = regexp.MustCompile(`[0-9a-fA-F]{20,}`)
+	privateKeyPEM = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	awsAccessKey  = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	yamlField     = regexp.MustCompile(`^(\s*)(?:- )?"?([A-Za-z0-9_.\- ]+?)"?\s*:(?:\s+|$)(.*)$`)
+	envField      = regexp.MustCompile(`^(\s*)(?:export\s+)?([A-Z][A-Z0-9_]*)=(.*)$`)
+)
+
+const (
+	base64EntropyThreshold = 4.5
+	hexEntropyThreshold    = 3.0
+)
+
+// Finding is a value that looks like a credential, found outside a field expected to hold one
+type Finding struct {
+	Source string
+	Line   int
+	Field  string
+	Reason string
+}
+
+func (f Finding) String() string {
+	field := f.Field
+	if field == "" {
+		field = "(no field)"
+	}
+	return fmt.Sprintf("%s:%d: %s in %s", f.Source, f.Line, f.Reason, field)
+}
+
+// Scanner looks for credentials in YAML, JSON, shell and plain text output
+type Scanner struct {
+	// Secrets are values known to be credentials, reported wherever they appear outside an expected field
+	Secrets []string
+}
+
+// Scan returns the credentials in content that are outside a field expected to hold one. Fields
+// are recognised by name, and a field expected to hold a credential includes any lines indented
+// beneath it, such as a YAML block scalar holding a PEM
+func (s Scanner) Scan(source string, content []byte) []Finding {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil
+	}
+
+	var findings []Finding
+	field := ""
+	expected := false
+	fieldIndent := -1
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		value := line
+		m := yamlField.FindStringSubmatch(line)
+		if m == nil {
+			m = envField.FindStringSubmatch(line)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case m != nil:
+			field = strings.TrimSpace(m[2])
+			expected = expectedField.MatchString(field) || checksumField.MatchString(field)
+			fieldIndent = len(m[1])
+			value = m[3]
+		case fieldIndent >= 0 && indent > fieldIndent:
+			// A continuation of the field above, such as a line of a block scalar
+		default:
+			field = ""
+			expected = false
+			fieldIndent = -1
+		}
+		if expected {
+			continue
+		}
+		if reason := s.suspicious(value); reason != "" {
+			findings = append(findings, Finding{Source: source, Line: i + 1, Field: field, Reason: reason})
+		}
+	}
+	return findings
+}
+
+func (s Scanner) suspicious(value string) string {
+	for _, secret := range s.Secrets {
+		if len(secret) >= 8 && strings.Contains(value, secret) {
+			return "known credential"
+		}
+	}
+	switch {
+	case privateKeyPEM.MatchString(value):
+		return "private key"
+	case awsAccessKey.MatchString(value):
+		return "AWS access key"
+	}
+	for _, token := range base64Token.FindAllString(value, -1) {
+		if Entropy(token) > base64EntropyThreshold {
+			return "high-entropy string"
+		}
+	}
+	for _, token := range hexToken.FindAllString(value, -1) {
+		if Entropy(token) > hexEntropyThreshold {
+			return "high-entropy hex string"
+		}
+	}
+	return ""
+}
+
+// Entropy returns the Shannon entropy of s in bits per character
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	length := float64(len([]rune(s)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -8,6 +8,7 @@ import (
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/EngineerBetter/control-tower/commands"
+	"github.com/EngineerBetter/control-tower/exitcode"
 )
 
 // ControlTowerVersion is a compile-time variable set with -ldflags
@@ -31,6 +32,6 @@ Built by %s %s
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitcode.Of(err))
 	}
 }
@@ -13,9 +13,9 @@ var _ = Describe("control-tower", func() {
 		Expect(err).NotTo(HaveOccurred())
 		outputStr := string(output)
 		Expect(outputStr).To(ContainSubstring("Control-Tower - A CLI tool to deploy Concourse CI"), outputStr)
-		Expect(outputStr).To(ContainSubstring("deploy, d    Deploys or updates a Concourse"), outputStr)
-		Expect(outputStr).To(ContainSubstring("destroy, x   Destroys a Concourse"), outputStr)
-		Expect(outputStr).To(ContainSubstring("info, i      Fetches information on a deployed environment"), outputStr)
-		Expect(outputStr).To(ContainSubstring("maintain, m  Handles maintenance operations in control-tower"), outputStr)
+		Expect(outputStr).To(ContainSubstring("deploy, d           Deploys or updates a Concourse"), outputStr)
+		Expect(outputStr).To(ContainSubstring("destroy, x          Destroys a Concourse"), outputStr)
+		Expect(outputStr).To(ContainSubstring("info, i             Fetches information on a deployed environment"), outputStr)
+		Expect(outputStr).To(ContainSubstring("maintain, m         Handles maintenance operations in control-tower"), outputStr)
 	})
 })
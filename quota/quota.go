@@ -0,0 +1,154 @@
+package quota
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const timeout = 10 * time.Second
+
+// Policy caps what may be deployed. Zero values and empty lists don't restrict anything, except that
+// instance types named in place of sizes are only allowed when they are listed, or sizes aren't capped
+type Policy struct {
+	MaxWorkers                 int      `yaml:"max_workers" json:"max_workers,omitempty"`
+	MaxWebNodes                int      `yaml:"max_web_nodes" json:"max_web_nodes,omitempty"`
+	AllowedRegions             []string `yaml:"allowed_regions" json:"allowed_regions,omitempty"`
+	AllowedWorkerSizes         []string `yaml:"allowed_worker_sizes" json:"allowed_worker_sizes,omitempty"`
+	AllowedWebSizes            []string `yaml:"allowed_web_sizes" json:"allowed_web_sizes,omitempty"`
+	AllowedWorkerInstanceTypes []string `yaml:"allowed_worker_instance_types" json:"allowed_worker_instance_types,omitempty"`
+	AllowedWebInstanceTypes    []string `yaml:"allowed_web_instance_types" json:"allowed_web_instance_types,omitempty"`
+	MaxGPUWorkers              int      `yaml:"max_gpu_workers" json:"max_gpu_workers,omitempty"`
+}
+
+// Request is what a deploy would create, once its flags have been merged with the stored config
+type Request struct {
+	Workers    int
+	WebNodes   int
+	Region     string
+	WorkerSize string
+	WebSize    string
+	// WorkerInstanceType and WebInstanceType are named in place of WorkerSize and WebSize when set
+	WorkerInstanceType string
+	WebInstanceType    string
+	// PoolSizes are the sizes of any worker pools, whose workers are counted in Workers
+	PoolSizes []string
+	// GPUWorkers are the workers in pools with a GPU, which are also counted in Workers
+	GPUWorkers int
+}
+
+// IsURL is true when source is a policy service rather than a local file
+func IsURL(source string) bool {
+	return strings.Contains(source, "://")
+}
+
+// Load reads the policy for project from a local YAML or JSON file, or asks a policy service
+// for it with a GET request to an https:// URL
+func Load(source, project string) (Policy, error) {
+	var contents []byte
+	var err error
+	if IsURL(source) {
+		contents, err = fetch(source, project)
+	} else {
+		contents, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("error reading quota policy from %s: [%v]", source, err)
+	}
+
+	var policy Policy
+	if err = yaml.UnmarshalStrict(contents, &policy); err != nil {
+		return Policy{}, fmt.Errorf("quota policy from %s is invalid: [%v]", source, err)
+	}
+	return policy, nil
+}
+
+func fetch(policyURL, project string) ([]byte, error) {
+	u, err := url.Parse(policyURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("project", project)
+	u.RawQuery = query.Encode()
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy service responded with %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Check returns an error listing every way the request goes over the policy
+func (p Policy) Check(r Request) error {
+	var violations []string
+	if p.MaxWorkers > 0 && r.Workers > p.MaxWorkers {
+		violations = append(violations, fmt.Sprintf("%d workers requested but at most %d are allowed", r.Workers, p.MaxWorkers))
+	}
+	if p.MaxWebNodes > 0 && r.WebNodes > p.MaxWebNodes {
+		violations = append(violations, fmt.Sprintf("%d web nodes requested but at most %d are allowed", r.WebNodes, p.MaxWebNodes))
+	}
+	if p.MaxGPUWorkers > 0 && r.GPUWorkers > p.MaxGPUWorkers {
+		violations = append(violations, fmt.Sprintf("%d GPU workers requested but at most %d are allowed", r.GPUWorkers, p.MaxGPUWorkers))
+	}
+	if !allowed(p.AllowedRegions, r.Region) {
+		violations = append(violations, fmt.Sprintf("region %s is not allowed, must be one of %v", r.Region, p.AllowedRegions))
+	}
+	if r.WorkerInstanceType != "" {
+		if v := instanceTypeViolation("worker", r.WorkerInstanceType, p.AllowedWorkerInstanceTypes, p.AllowedWorkerSizes); v != "" {
+			violations = append(violations, v)
+		}
+	} else if !allowed(p.AllowedWorkerSizes, r.WorkerSize) {
+		violations = append(violations, fmt.Sprintf("worker size %s is not allowed, must be one of %v", r.WorkerSize, p.AllowedWorkerSizes))
+	}
+	for _, size := range r.PoolSizes {
+		if !allowed(p.AllowedWorkerSizes, size) {
+			violations = append(violations, fmt.Sprintf("worker pool size %s is not allowed, must be one of %v", size, p.AllowedWorkerSizes))
+		}
+	}
+	if r.WebInstanceType != "" {
+		if v := instanceTypeViolation("web", r.WebInstanceType, p.AllowedWebInstanceTypes, p.AllowedWebSizes); v != "" {
+			violations = append(violations, v)
+		}
+	} else if !allowed(p.AllowedWebSizes, r.WebSize) {
+		violations = append(violations, fmt.Sprintf("web size %s is not allowed, must be one of %v", r.WebSize, p.AllowedWebSizes))
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("deployment is over quota: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// instanceTypeViolation describes why a named instance type isn't allowed, or is empty when it is. A policy
+// that caps sizes without listing any instance types allows none, so that naming a type can't get around it
+func instanceTypeViolation(role, instanceType string, allowedTypes, allowedSizes []string) string {
+	switch {
+	case len(allowedTypes) > 0 && !allowed(allowedTypes, instanceType):
+		return fmt.Sprintf("%s instance type %s is not allowed, must be one of %v", role, instanceType, allowedTypes)
+	case len(allowedTypes) == 0 && len(allowedSizes) > 0:
+		return fmt.Sprintf("%s instance type %s is not allowed, only %s sizes %v are", role, instanceType, role, allowedSizes)
+	}
+	return ""
+}
+
+func allowed(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package quota
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	var project string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		project = r.URL.Query().Get("project")
+		w.Write([]byte(`{"max_workers": 4, "allowed_regions": ["eu-west-1"]}`))
+	}))
+	defer server.Close()
+
+	policy, err := Load(server.URL+"/quota?tenant=data", "my-project")
+	if err != nil {
+		t.Fatalf("Load() from service error = %v", err)
+	}
+	want := Policy{MaxWorkers: 4, AllowedRegions: []string{"eu-west-1"}}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("Load() from service = %v, want %v", policy, want)
+	}
+	if project != "my-project" {
+		t.Errorf("Load() asked for project %q, want %q", project, "my-project")
+	}
+
+	path := filepath.Join(t.TempDir(), "quota.yml")
+	if err = ioutil.WriteFile(path, []byte("max_workers: 2\nallowed_worker_sizes: [medium, large]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = Load(path, "my-project")
+	if err != nil {
+		t.Fatalf("Load() from file error = %v", err)
+	}
+	want = Policy{MaxWorkers: 2, AllowedWorkerSizes: []string{"medium", "large"}}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("Load() from file = %v, want %v", policy, want)
+	}
+
+	if err = ioutil.WriteFile(path, []byte("max_wokers: 2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(path, "my-project"); err == nil {
+		t.Error("Load() of a misspelt policy succeeded, want an error")
+	}
+}
+
+func TestCheck_InstanceTypesAndWebNodes(t *testing.T) {
+	policy := Policy{
+		MaxWebNodes:                2,
+		AllowedWorkerSizes:         []string{"medium"},
+		AllowedWorkerInstanceTypes: []string{"m7i.xlarge"},
+		AllowedWebInstanceTypes:    []string{"t3.medium"},
+	}
+	if err := policy.Check(Request{WebNodes: 2, WorkerInstanceType: "m7i.xlarge", WebInstanceType: "t3.medium"}); err != nil {
+		t.Errorf("Check() error = %v, want none", err)
+	}
+	err := policy.Check(Request{WebNodes: 3, WorkerInstanceType: "m7i.2xlarge", WebInstanceType: "t3.large"})
+	want := "deployment is over quota: 3 web nodes requested but at most 2 are allowed; worker instance type m7i.2xlarge is not allowed, must be one of [m7i.xlarge]; web instance type t3.large is not allowed, must be one of [t3.medium]"
+	if err == nil || err.Error() != want {
+		t.Errorf("Check() error = %v, want %s", err, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy := Policy{
+		MaxWorkers:         4,
+		MaxGPUWorkers:      1,
+		AllowedRegions:     []string{"eu-west-1", "eu-west-2"},
+		AllowedWorkerSizes: []string{"medium", "large"},
+	}
+	tests := []struct {
+		name    string
+		request Request
+		wantErr string
+	}{
+		{
+			name:    "within quota",
+			request: Request{Workers: 4, Region: "eu-west-2", WorkerSize: "large", WebSize: "xlarge"},
+		},
+		{
+			name:    "too many workers",
+			request: Request{Workers: 5, Region: "eu-west-1", WorkerSize: "medium", WebSize: "small"},
+			wantErr: "deployment is over quota: 5 workers requested but at most 4 are allowed",
+		},
+		{
+			name:    "worker pools",
+			request: Request{Workers: 3, Region: "eu-west-1", WorkerSize: "medium", WebSize: "small", PoolSizes: []string{"large", "2xlarge"}},
+			wantErr: "deployment is over quota: worker pool size 2xlarge is not allowed, must be one of [medium large]",
+		},
+		{
+			name:    "too many GPU workers",
+			request: Request{Workers: 3, Region: "eu-west-1", WorkerSize: "medium", WebSize: "small", PoolSizes: []string{"large"}, GPUWorkers: 2},
+			wantErr: "deployment is over quota: 2 GPU workers requested but at most 1 are allowed",
+		},
+		{
+			name:    "named worker instance type while sizes are capped",
+			request: Request{Workers: 1, Region: "eu-west-1", WorkerInstanceType: "m7i.8xlarge", WebSize: "small"},
+			wantErr: "deployment is over quota: worker instance type m7i.8xlarge is not allowed, only worker sizes [medium large] are",
+		},
+		{
+			name:    "named web instance type while web sizes aren't capped",
+			request: Request{Workers: 1, Region: "eu-west-1", WorkerSize: "medium", WebInstanceType: "t3.2xlarge"},
+		},
+		{
+			name:    "every violation is reported",
+			request: Request{Workers: 1, Region: "us-east-1", WorkerSize: "4xlarge", WebSize: "small"},
+			wantErr: "deployment is over quota: region us-east-1 is not allowed, must be one of [eu-west-1 eu-west-2]; worker size 4xlarge is not allowed, must be one of [medium large]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.request)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Check() error = %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Check() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package remediate
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// DefaultStuckAfter is how long a build can be pending before it is reported as stuck
+const DefaultStuckAfter = 2 * time.Hour
+
+// boshInstanceID matches the IDs BOSH gives instances, which deployed workers register with
+var boshInstanceID = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Report describes the problems found on a Concourse, and is what remediation works from
+type Report struct {
+	StuckBuilds    []fly.Build
+	StalledWorkers []fly.Worker
+	// OrphanedWorkers are registered with the ATC but have no BOSH instance, such as when their VM
+	// was deleted outside BOSH
+	OrphanedWorkers []fly.Worker
+	// FailingWorkers are still running on a VM that BOSH reports as unhealthy, and are retired
+	// before it is recreated
+	FailingWorkers     []fly.Worker
+	OrphanedContainers int
+	OrphanedVolumes    int
+	UnhealthyInstances []bosh.Instance
+}
+
+// New builds a report from the state of the ATC and the BOSH deployment
+func New(builds []fly.Build, workers []fly.Worker, instances []bosh.Instance, stuckAfter time.Duration, now time.Time) Report {
+	report := Report{
+		StuckBuilds:        StuckBuilds(builds, stuckAfter, now),
+		StalledWorkers:     StalledWorkers(workers),
+		OrphanedWorkers:    OrphanedWorkers(workers, instances),
+		UnhealthyInstances: UnhealthyInstances(instances),
+	}
+	report.FailingWorkers = FailingWorkers(workers, report.UnhealthyInstances)
+	for _, worker := range append(report.StalledWorkers, report.OrphanedWorkers...) {
+		report.OrphanedContainers += worker.ActiveContainers
+		report.OrphanedVolumes += worker.ActiveVolumes
+	}
+	return report
+}
+
+// StuckBuilds returns the builds that have been pending for longer than stuckAfter. Builds don't
+// record when they were created, but IDs are assigned in order, so a pending build has waited at
+// least as long as any later build has been running
+func StuckBuilds(builds []fly.Build, stuckAfter time.Duration, now time.Time) []fly.Build {
+	cutoff := now.Add(-stuckAfter).Unix()
+	var stuck []fly.Build
+	for _, build := range builds {
+		if build.Status != "pending" {
+			continue
+		}
+		for _, later := range builds {
+			if later.ID > build.ID && later.StartTime > 0 && later.StartTime <= cutoff {
+				stuck = append(stuck, build)
+				break
+			}
+		}
+	}
+	return stuck
+}
+
+// StalledWorkers returns the workers that have stopped heartbeating to the ATC. Their containers
+// and volumes are orphaned until the worker is pruned
+func StalledWorkers(workers []fly.Worker) []fly.Worker {
+	var stalled []fly.Worker
+	for _, worker := range workers {
+		if worker.State == "stalled" {
+			stalled = append(stalled, worker)
+		}
+	}
+	return stalled
+}
+
+// OrphanedWorkers returns the workers that are named after a BOSH instance that no longer exists.
+// Stalled workers are left to StalledWorkers, and workers not named after a BOSH instance ID, such as
+// external workers, are never orphans
+func OrphanedWorkers(workers []fly.Worker, instances []bosh.Instance) []fly.Worker {
+	ids := map[string]bool{}
+	for _, instance := range instances {
+		ids[instanceID(instance)] = true
+	}
+	var orphaned []fly.Worker
+	for _, worker := range workers {
+		if worker.State != "stalled" && boshInstanceID.MatchString(worker.Name) && !ids[worker.Name] {
+			orphaned = append(orphaned, worker)
+		}
+	}
+	return orphaned
+}
+
+// FailingWorkers returns the workers the ATC still schedules builds on whose VM is unhealthy
+func FailingWorkers(workers []fly.Worker, unhealthy []bosh.Instance) []fly.Worker {
+	ids := map[string]bool{}
+	for _, instance := range unhealthy {
+		ids[instanceID(instance)] = true
+	}
+	var failing []fly.Worker
+	for _, worker := range workers {
+		if worker.State == "running" && ids[worker.Name] {
+			failing = append(failing, worker)
+		}
+	}
+	return failing
+}
+
+// UnhealthyInstances returns the instances whose VM BOSH doesn't consider running. Instances that
+// were stopped or detached deliberately are left alone
+func UnhealthyInstances(instances []bosh.Instance) []bosh.Instance {
+	var unhealthy []bosh.Instance
+	for _, instance := range instances {
+		if instance.ExpectedState == "stopped" || instance.ExpectedState == "detached" {
+			continue
+		}
+		if instance.State != "running" {
+			unhealthy = append(unhealthy, instance)
+		}
+	}
+	return unhealthy
+}
+
+// instanceID is the ID part of an instance name such as worker/<id>
+func instanceID(instance bosh.Instance) string {
+	parts := strings.SplitN(instance.Name, "/", 2)
+	return parts[len(parts)-1]
+}
+
+// Healthy is true when nothing was found that needs remediating
+func (r Report) Healthy() bool {
+	return len(r.StuckBuilds) == 0 && len(r.StalledWorkers) == 0 && len(r.OrphanedWorkers) == 0 && len(r.UnhealthyInstances) == 0
+}
+
+// Write writes a human readable summary of the report to w
+func (r Report) Write(w io.Writer) error {
+	if r.Healthy() {
+		_, err := fmt.Fprintln(w, "No stuck builds, stalled or orphaned workers or unhealthy VMs found")
+		return err
+	}
+
+	fmt.Fprintf(w, "Stuck builds: %d\n", len(r.StuckBuilds))
+	for _, build := range r.StuckBuilds {
+		fmt.Fprintf(w, "  - %s (build %d)\n", buildName(build), build.ID)
+	}
+	fmt.Fprintf(w, "Stalled workers: %d\n", len(r.StalledWorkers))
+	for _, worker := range r.StalledWorkers {
+		fmt.Fprintf(w, "  - %s\n", worker.Name)
+	}
+	fmt.Fprintf(w, "Orphaned workers: %d\n", len(r.OrphanedWorkers))
+	for _, worker := range r.OrphanedWorkers {
+		fmt.Fprintf(w, "  - %s is %s with no BOSH VM\n", worker.Name, worker.State)
+	}
+	fmt.Fprintf(w, "Orphaned containers: %d, orphaned volumes: %d\n", r.OrphanedContainers, r.OrphanedVolumes)
+	_, err := fmt.Fprintf(w, "Unhealthy VMs: %d\n", len(r.UnhealthyInstances))
+	for _, instance := range r.UnhealthyInstances {
+		_, err = fmt.Fprintf(w, "  - %s is %s\n", instance.Name, instance.State)
+	}
+	for _, worker := range r.FailingWorkers {
+		_, err = fmt.Fprintf(w, "  - worker %s is still running builds and will be retired first\n", worker.Name)
+	}
+	return err
+}
+
+func buildName(build fly.Build) string {
+	if build.JobName == "" {
+		return fmt.Sprintf("%s/one-off #%s", build.TeamName, build.Name)
+	}
+	return strings.Join([]string{build.TeamName, build.PipelineName, build.JobName}, "/") + " #" + build.Name
+}
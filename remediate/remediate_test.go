@@ -0,0 +1,124 @@
+package remediate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/bosh"
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+func TestStuckBuilds(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	builds := []fly.Build{
+		{ID: 5, Status: "pending"},
+		{ID: 4, Status: "started", StartTime: now.Add(-30 * time.Minute).Unix()},
+		{ID: 3, Status: "pending"},
+		{ID: 2, Status: "succeeded", StartTime: now.Add(-3 * time.Hour).Unix(), EndTime: now.Add(-2 * time.Hour).Unix()},
+		{ID: 1, Status: "pending"},
+	}
+
+	tests := []struct {
+		name       string
+		stuckAfter time.Duration
+		want       []int
+	}{
+		{name: "pending behind a build that started hours ago", stuckAfter: 2 * time.Hour, want: []int{1}},
+		{name: "pending behind a build that started recently", stuckAfter: 20 * time.Minute, want: []int{3, 1}},
+		{name: "nothing started long enough ago", stuckAfter: 4 * time.Hour, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, build := range StuckBuilds(builds, tt.stuckAfter, now) {
+				got = append(got, build.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("StuckBuilds() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("StuckBuilds() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	const (
+		failingID  = "0b2e6d7c-6d1c-4b8e-9f2a-3c4d5e6f7a80"
+		orphanedID = "5f0c1a2b-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	)
+	workers := []fly.Worker{
+		{Name: "a", State: "running", ActiveContainers: 10, ActiveVolumes: 20},
+		{Name: "b", State: "stalled", ActiveContainers: 2, ActiveVolumes: 5},
+		{Name: "c", State: "stalled", ActiveContainers: 1, ActiveVolumes: 3},
+		{Name: "d", State: "retiring", ActiveContainers: 4, ActiveVolumes: 4},
+		{Name: failingID, State: "running", ActiveContainers: 6, ActiveVolumes: 6},
+		{Name: orphanedID, State: "running", ActiveContainers: 1, ActiveVolumes: 2},
+	}
+	instances := []bosh.Instance{
+		{Name: "web/0", State: "running", ExpectedState: "started"},
+		{Name: "worker/" + failingID, State: "failing", ExpectedState: "started"},
+		{Name: "worker/1", State: "stopped", ExpectedState: "stopped"},
+		{Name: "worker/2", State: "", ExpectedState: "detached"},
+	}
+
+	report := New(nil, workers, instances, DefaultStuckAfter, time.Now())
+
+	if len(report.StalledWorkers) != 2 || report.OrphanedContainers != 4 || report.OrphanedVolumes != 10 {
+		t.Errorf("New() = %+v", report)
+	}
+	if len(report.OrphanedWorkers) != 1 || report.OrphanedWorkers[0].Name != orphanedID {
+		t.Errorf("OrphanedWorkers = %+v", report.OrphanedWorkers)
+	}
+	if len(report.UnhealthyInstances) != 1 || report.UnhealthyInstances[0].Name != "worker/"+failingID {
+		t.Errorf("UnhealthyInstances = %+v", report.UnhealthyInstances)
+	}
+	if len(report.FailingWorkers) != 1 || report.FailingWorkers[0].Name != failingID {
+		t.Errorf("FailingWorkers = %+v", report.FailingWorkers)
+	}
+	if report.Healthy() {
+		t.Error("expected report with stalled workers not to be healthy")
+	}
+}
+
+func TestUnhealthyInstances_IgnoresStoppedInstances(t *testing.T) {
+	instances := []bosh.Instance{
+		{Name: "worker/0", State: "stopped", ExpectedState: "stopped"},
+		{Name: "worker/1", State: "", ExpectedState: "detached"},
+	}
+	if got := UnhealthyInstances(instances); len(got) != 0 {
+		t.Errorf("UnhealthyInstances() = %+v, want none", got)
+	}
+	if !New(nil, nil, instances, DefaultStuckAfter, time.Now()).Healthy() {
+		t.Error("expected deliberately stopped instances to be healthy")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Report{}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No stuck builds, stalled or orphaned workers or unhealthy VMs found") {
+		t.Errorf("Write() = %q", buf.String())
+	}
+
+	buf.Reset()
+	report := Report{StuckBuilds: []fly.Build{
+		{ID: 7, Name: "3", TeamName: "main", PipelineName: "deploy", JobName: "test", Status: "pending"},
+		{ID: 8, Name: "12", TeamName: "ops", Status: "pending"},
+	}}
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"main/deploy/test #3 (build 7)", "ops/one-off #12 (build 8)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Write() = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
@@ -6,6 +6,18 @@ terraform {
 	}
 }
 
+{{define "peer_routes"}}{{range .KeyedPeerVPCIDs}}
+  route {
+    cidr_block                = data.aws_vpc.peer_{{ .Key }}.cidr_block
+    vpc_peering_connection_id = aws_vpc_peering_connection.peer_{{ .Key }}.id
+  }
+{{end}}{{range $i, $cidr := .TransitGatewayRoutes}}
+  route {
+    cidr_block         = "{{ $cidr }}"
+    transit_gateway_id = aws_ec2_transit_gateway_vpc_attachment.hub.transit_gateway_id
+  }
+{{end}}{{end}}
+{{define "zone_nat_cidrs"}}{{if eq .NATTopology "per-zone"}}{{range $i, $zone := .WorkerZones}}, "${aws_eip.nat_{{ $i }}.public_ip}/32"{{end}}{{end}}{{end}}
 data "aws_availability_zones" "available" {
   state = "available"
 }
@@ -105,7 +117,7 @@ terraform {
   required_providers {
     aws = {
       source = "hashicorp/aws"
-      version = "~> 4.39"
+      version = "~> 5.46"
     }
   }
 }
@@ -186,11 +198,141 @@ resource "aws_iam_user_policy" "bosh" {
       ],
       "Effect": "Allow",
       "Resource": "*"
+    }{{if or .SecretsManager .SSM .ManagedPrometheus .DBIAMUser}},
+    {
+      "Action": "iam:PassRole",
+      "Effect": "Allow",
+      "Resource": "${aws_iam_role.web.arn}"
+    }{{end}}
+  ]
+}
+EOF
+}
+
+{{if or .SecretsManager .SSM .ManagedPrometheus .DBIAMUser}}
+data "aws_caller_identity" "current" {}
+
+resource "aws_iam_role" "web" {
+  name = "${var.deployment}-${var.region}-web"
+
+  assume_role_policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "sts:AssumeRole",
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "ec2.amazonaws.com"
+      }
+    }
+  ]
+}
+EOF
+}
+
+resource "aws_iam_instance_profile" "web" {
+  name = "${var.deployment}-${var.region}-web"
+  role = aws_iam_role.web.name
+}
+{{end}}
+
+{{if .SecretsManager}}
+resource "aws_iam_role_policy" "web_secretsmanager" {
+  name = "${var.deployment}-${var.region}-web-secretsmanager"
+  role = aws_iam_role.web.id
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": [
+        "secretsmanager:DescribeSecret",
+        "secretsmanager:GetSecretValue"
+      ],
+      "Effect": "Allow",
+      "Resource": "arn:aws:secretsmanager:${var.region}:${data.aws_caller_identity.current.account_id}:secret:{{ .SecretsManagerPathPrefix }}*"
+    },
+    {
+      "Action": "secretsmanager:ListSecrets",
+      "Effect": "Allow",
+      "Resource": "*"
+    }
+  ]
+}
+EOF
+}
+{{end}}
+
+{{if .SSM}}
+resource "aws_iam_role_policy" "web_ssm" {
+  name = "${var.deployment}-${var.region}-web-ssm"
+  role = aws_iam_role.web.id
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": [
+        "ssm:GetParameter",
+        "ssm:GetParameters",
+        "ssm:GetParametersByPath"
+      ],
+      "Effect": "Allow",
+      "Resource": "arn:aws:ssm:${var.region}:${data.aws_caller_identity.current.account_id}:parameter{{ .SSMPathPrefix }}*"
+    }{{if .SSMKMSKeyARN}},
+    {
+      "Action": "kms:Decrypt",
+      "Effect": "Allow",
+      "Resource": "{{ .SSMKMSKeyARN }}"
+    }{{end}}
+  ]
+}
+EOF
+}
+{{end}}
+
+{{if .ManagedPrometheus}}
+resource "aws_iam_role_policy" "web_managed_prometheus" {
+  name = "${var.deployment}-${var.region}-web-managed-prometheus"
+  role = aws_iam_role.web.id
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "aps:RemoteWrite",
+      "Effect": "Allow",
+      "Resource": "arn:aws:aps:{{ .ManagedPrometheusRegion }}:${data.aws_caller_identity.current.account_id}:workspace/{{ .ManagedPrometheusWorkspaceID }}"
+    }
+  ]
+}
+EOF
+}
+{{end}}
+
+{{if .DBIAMUser}}
+resource "aws_iam_role_policy" "web_rds_iam" {
+  name = "${var.deployment}-${var.region}-web-rds-iam"
+  role = aws_iam_role.web.id
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "rds-db:connect",
+      "Effect": "Allow",
+      "Resource": "arn:aws:rds-db:${var.region}:${data.aws_caller_identity.current.account_id}:dbuser:${ {{- if .DBMaxACU}}aws_rds_cluster.default.cluster_resource_id{{else}}aws_db_instance.default.resource_id{{end -}} }/{{ .DBIAMUser }}"
     }
   ]
 }
 EOF
 }
+{{end}}
 
 resource "aws_iam_user" "self_update" {
   name = "${var.deployment}-${var.region}-self-update"
@@ -214,16 +356,31 @@ resource "aws_iam_user_policy" "self_update" {
             "Effect": "Allow",
             "Action": [
                 "ec2:*",
+                "iam:AddRoleToInstanceProfile",
                 "iam:CreateAccessKey",
+                "iam:CreateInstanceProfile",
+                "iam:CreateRole",
                 "iam:CreateUser",
                 "iam:DeleteAccessKey",
+                "iam:DeleteInstanceProfile",
+                "iam:DeleteRole",
+                "iam:DeleteRolePolicy",
                 "iam:DeleteUser",
                 "iam:DeleteUserPolicy",
+                "iam:GetInstanceProfile",
+                "iam:GetRole",
+                "iam:GetRolePolicy",
                 "iam:GetUser",
                 "iam:GetUserPolicy",
                 "iam:ListAccessKeys",
+                "iam:ListAttachedRolePolicies",
                 "iam:ListGroupsForUser",
+                "iam:ListInstanceProfilesForRole",
+                "iam:ListRolePolicies",
+                "iam:PassRole",
+                "iam:PutRolePolicy",
                 "iam:PutUserPolicy",
+                "iam:RemoveRoleFromInstanceProfile",
                 "rds:*",
                 "route53:*",
                 "s3:*",
@@ -232,7 +389,7 @@ resource "aws_iam_user_policy" "self_update" {
             "Resource": "*",
             "Condition": {
                 "IpAddress": {
-                    "aws:SourceIp": "${aws_nat_gateway.default.public_ip}/32"
+                    "aws:SourceIp": ["${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}]
                 }
             }
         }
@@ -241,9 +398,71 @@ resource "aws_iam_user_policy" "self_update" {
 EOF
 }
 
+resource "aws_iam_user" "runtime" {
+  name = "${var.deployment}-${var.region}-runtime"
+}
+
+resource "aws_iam_access_key" "runtime" {
+  user = aws_iam_user.runtime.name
+}
+
+// runtime autoscales the workers, so it can only use the runtime file and the deploy lock in the config bucket,
+// and not config.json or the terraform state
+resource "aws_iam_user_policy" "runtime" {
+  name = "${var.deployment}-${var.region}-runtime"
+  user = aws_iam_user.runtime.name
+
+  policy = <<EOF
+{
+    "Version": "2012-10-17",
+    "Statement": [
+        {
+            "Effect": "Allow",
+            "Action": [
+                "s3:ListBucket"
+            ],
+            "Resource": [
+                "arn:aws:s3:::{{ .ConfigBucket }}"
+            ]
+        },
+        {
+            "Effect": "Allow",
+            "Action": [
+                "s3:DeleteObject",
+                "s3:GetObject",
+                "s3:PutObject"
+            ],
+            "Resource": [
+                "arn:aws:s3:::{{ .ConfigBucket }}/runtime.json",
+                "arn:aws:s3:::{{ .ConfigBucket }}/deploy.lock"
+            ]
+        }
+    ]
+}
+EOF
+}
+
+{{if .ExistingVPCID}}
+locals {
+  vpc_id            = "{{ .ExistingVPCID }}"
+  public_subnet_id  = "{{ .ExistingPublicSubnetID }}"
+  private_subnet_id = "{{ .ExistingPrivateSubnetID }}"
+  nat_public_ip     = "{{ .ExistingNATIP }}"
+}
+{{else}}
+locals {
+  vpc_id            = aws_vpc.default.id
+  public_subnet_id  = aws_subnet.public.id
+  private_subnet_id = aws_subnet.private.id
+  nat_public_ip     = aws_eip.nat.public_ip
+}
+
 resource "aws_vpc" "default" {
   cidr_block = var.network_cidr
-
+{{if .VPCEndpoints}}  enable_dns_support   = true
+  enable_dns_hostnames = true
+{{end}}{{if .IPv6}}  assign_generated_ipv6_cidr_block = true
+{{end}}
   tags = {
     Name = var.deployment
     control-tower-project = var.project
@@ -266,10 +485,172 @@ resource "aws_route" "internet_access" {
   destination_cidr_block = "0.0.0.0/0"
   gateway_id             = aws_internet_gateway.default.id
 }
+{{if .IPv6}}
+resource "aws_route" "internet_access_ipv6" {
+  route_table_id              = aws_vpc.default.main_route_table_id
+  destination_ipv6_cidr_block = "::/0"
+  gateway_id                  = aws_internet_gateway.default.id
+}
+
+// VMs without public addresses reach the internet over IPv6 through an egress-only internet gateway, which
+// NAT gateways don't do for IPv6
+resource "aws_egress_only_internet_gateway" "default" {
+  vpc_id = aws_vpc.default.id
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+{{end}}
+{{range .KeyedPeerVPCIDs}}
+// The peering is accepted along with the request, as the peer VPC is in the same account and region. Its
+// route tables are its own, so routes back to the deployment's network are added to them separately
+data "aws_vpc" "peer_{{ .Key }}" {
+  id = "{{ .Value }}"
+}
+
+resource "aws_vpc_peering_connection" "peer_{{ .Key }}" {
+  vpc_id      = aws_vpc.default.id
+  peer_vpc_id = data.aws_vpc.peer_{{ .Key }}.id
+  auto_accept = true
 
+  tags = {
+    Name = "${var.deployment}-peer-{{ .Value }}"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+
+resource "aws_route" "peer_{{ .Key }}" {
+  route_table_id            = aws_vpc.default.main_route_table_id
+  destination_cidr_block    = data.aws_vpc.peer_{{ .Key }}.cidr_block
+  vpc_peering_connection_id = aws_vpc_peering_connection.peer_{{ .Key }}.id
+}
+{{end}}
+{{if .TransitGatewayID}}
+// The VPC is attached in the private subnet and each worker zone's subnet, as an attachment takes one subnet
+// in each zone it reaches. Attachments to a transit gateway shared from another account wait for its owner
+// to accept them, unless it accepts attachments automatically
+resource "aws_ec2_transit_gateway_vpc_attachment" "hub" {
+  transit_gateway_id = "{{ .TransitGatewayID }}"
+  vpc_id             = aws_vpc.default.id
+  subnet_ids         = [aws_subnet.private.id{{range $i, $zone := .WorkerZones}}, aws_subnet.worker_{{ $i }}.id{{end}}]
+{{if .TransitGatewayRouteTableID}}  transit_gateway_default_route_table_association = false
+  transit_gateway_default_route_table_propagation = false
+{{else if .NoTransitGatewayPropagation}}  transit_gateway_default_route_table_propagation = false
+{{end}}
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+{{if .TransitGatewayRouteTableID}}
+resource "aws_ec2_transit_gateway_route_table_association" "hub" {
+  transit_gateway_attachment_id  = aws_ec2_transit_gateway_vpc_attachment.hub.id
+  transit_gateway_route_table_id = "{{ .TransitGatewayRouteTableID }}"
+}
+{{if not .NoTransitGatewayPropagation}}
+resource "aws_ec2_transit_gateway_route_table_propagation" "hub" {
+  transit_gateway_attachment_id  = aws_ec2_transit_gateway_vpc_attachment.hub.id
+  transit_gateway_route_table_id = "{{ .TransitGatewayRouteTableID }}"
+}
+{{end}}{{end}}
+{{range .KeyedTransitGatewayRoutes}}
+resource "aws_route" "hub_{{ .Key }}" {
+  route_table_id         = aws_vpc.default.main_route_table_id
+  destination_cidr_block = "{{ .Value }}"
+  transit_gateway_id     = aws_ec2_transit_gateway_vpc_attachment.hub.transit_gateway_id
+}
+{{end}}
+{{end}}
+{{if eq .NATTopology "instance"}}
+// A NAT instance costs a fraction of a NAT gateway, but is a single VM that the private subnets lose the
+// internet with while it is replaced. It takes over the NAT gateway's Elastic IP
+data "aws_ami" "nat" {
+  most_recent = true
+  owners      = ["amazon"]
+
+  filter {
+    name   = "name"
+    values = ["al2023-ami-2023.*-x86_64"]
+  }
+}
+
+resource "aws_security_group" "nat" {
+  name        = "${var.deployment}-nat"
+  description = "Control-Tower NAT instance security group"
+  vpc_id      = aws_vpc.default.id
+
+  tags = {
+    Name = "${var.deployment}-nat"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+
+  ingress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [var.network_cidr]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_instance" "nat" {
+  ami                    = data.aws_ami.nat.id
+  instance_type          = "t3.micro"
+  subnet_id              = {{if .Private}}aws_subnet.nat.id{{else}}aws_subnet.public.id{{end}}
+  vpc_security_group_ids = [aws_security_group.nat.id]
+  source_dest_check      = false
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  root_block_device {
+    encrypted = true
+  }
+
+  user_data = <<EOF
+#!/bin/bash
+echo net.ipv4.ip_forward=1 > /etc/sysctl.d/90-nat.conf
+sysctl -p /etc/sysctl.d/90-nat.conf
+# the Elastic IP may not be associated yet, so wait for the internet to be reachable
+until dnf install -y iptables-services; do sleep 5; done
+iface=$(ip route show default | awk '{print $5}')
+iptables -t nat -A POSTROUTING -o "$iface" -j MASQUERADE
+iptables -F FORWARD
+service iptables save
+systemctl enable --now iptables
+EOF
+
+  depends_on = [aws_internet_gateway.default]
+
+  tags = {
+    Name = "${var.deployment}-nat"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+
+resource "aws_eip_association" "nat" {
+  allocation_id = aws_eip.nat.id
+  instance_id   = aws_instance.nat.id
+}
+{{else}}
 resource "aws_nat_gateway" "default" {
   allocation_id = aws_eip.nat.id
-  subnet_id     = aws_subnet.public.id
+  subnet_id     = {{if .Private}}aws_subnet.nat.id{{else}}aws_subnet.public.id{{end}}
 
   depends_on = [aws_internet_gateway.default, aws_ec2_subnet_cidr_reservation.director]
 
@@ -278,15 +659,21 @@ resource "aws_nat_gateway" "default" {
     control-tower-project = var.project
   }
 }
+{{end}}
 
 resource "aws_route_table" "private" {
   vpc_id = aws_vpc.default.id
 
   route {
     cidr_block = "0.0.0.0/0"
-    nat_gateway_id = aws_nat_gateway.default.id
+    {{if eq .NATTopology "instance"}}network_interface_id = aws_instance.nat.primary_network_interface_id{{else}}nat_gateway_id = aws_nat_gateway.default.id{{end}}
   }
-
+{{if .IPv6}}
+  route {
+    ipv6_cidr_block        = "::/0"
+    egress_only_gateway_id = aws_egress_only_internet_gateway.default.id
+  }
+{{end}}{{template "peer_routes" .}}
   tags = {
     Name = "${var.deployment}-private"
     control-tower-project = var.project
@@ -298,8 +685,9 @@ resource "aws_subnet" "public" {
   vpc_id                  = aws_vpc.default.id
   availability_zone       = var.availability_zone
   cidr_block              = var.public_cidr
-  map_public_ip_on_launch = true
-
+  map_public_ip_on_launch = {{if .Private}}false{{else}}true{{end}}
+{{if .IPv6}}  ipv6_cidr_block         = cidrsubnet(aws_vpc.default.ipv6_cidr_block, 8, 0)
+{{end}}
   tags = {
     Name = "${var.deployment}-public"
     control-tower-project = var.project
@@ -312,7 +700,9 @@ resource "aws_subnet" "private" {
   availability_zone       = var.availability_zone
   cidr_block              = var.private_cidr
   map_public_ip_on_launch = false
-
+{{if .IPv6}}  ipv6_cidr_block         = cidrsubnet(aws_vpc.default.ipv6_cidr_block, 8, 1)
+  assign_ipv6_address_on_creation = true
+{{end}}
   tags = {
     Name = "${var.deployment}-private"
     control-tower-project = var.project
@@ -324,39 +714,338 @@ resource "aws_route_table_association" "private" {
   subnet_id      = aws_subnet.private.id
   route_table_id = aws_route_table.private.id
 }
+{{if .Private}}
+// Without public addresses, the public subnet reaches the internet through the NAT gateway, which is given a
+// subnet of its own that routes through the internet gateway
+resource "aws_subnet" "nat" {
+  vpc_id                  = aws_vpc.default.id
+  availability_zone       = var.availability_zone
+  cidr_block              = "{{ .NATCIDR }}"
+  map_public_ip_on_launch = false
+
+  tags = {
+    Name = "${var.deployment}-nat"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+
+resource "aws_route_table_association" "public" {
+  subnet_id      = aws_subnet.public.id
+  route_table_id = aws_route_table.private.id
+}
+{{end}}
+{{if .VPCEndpoints}}
+// VMs call S3, EC2, Secrets Manager and CloudWatch through endpoints in the VPC rather than the NAT gateway. The
+// public and worker subnets share the private route table, so the S3 endpoint covers them all
+resource "aws_vpc_endpoint" "s3" {
+  vpc_id            = aws_vpc.default.id
+  service_name      = "com.amazonaws.${var.region}.s3"
+  vpc_endpoint_type = "Gateway"
+  route_table_ids   = [aws_route_table.private.id{{if eq .NATTopology "per-zone"}}{{range $i, $zone := .WorkerZones}}, aws_route_table.worker_{{ $i }}.id{{end}}{{end}}]
+
+  tags = {
+    Name = "${var.deployment}-s3"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+
+resource "aws_security_group" "vpc_endpoints" {
+  name        = "${var.deployment}-vpc-endpoints"
+  description = "Control-Tower VPC endpoints security group"
+  vpc_id      = aws_vpc.default.id
+
+  tags = {
+    Name = "${var.deployment}-vpc-endpoints"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = [var.network_cidr]
+  }
+}
+
+resource "aws_vpc_endpoint" "interface" {
+  for_each = toset(["ec2", "secretsmanager", "logs", "monitoring"])
+
+  vpc_id              = aws_vpc.default.id
+  service_name        = "com.amazonaws.${var.region}.${each.key}"
+  vpc_endpoint_type   = "Interface"
+  subnet_ids          = [aws_subnet.private.id]
+  security_group_ids  = [aws_security_group.vpc_endpoints.id]
+  private_dns_enabled = true
+
+  tags = {
+    Name = "${var.deployment}-${each.key}"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+{{end}}
+{{end}}
+{{range $i, $zone := .WorkerZones }}
+resource "aws_subnet" "worker_{{ $i }}" {
+  vpc_id                  = local.vpc_id
+  availability_zone       = "{{ $zone.Zone }}"
+  cidr_block              = "{{ $zone.CIDR }}"
+  map_public_ip_on_launch = false
+{{if $.IPv6}}  ipv6_cidr_block         = cidrsubnet(aws_vpc.default.ipv6_cidr_block, 8, {{ $i }} + 2)
+  assign_ipv6_address_on_creation = true
+{{end}}
+  tags = {
+    Name = "${var.deployment}-worker-{{ $zone.Zone }}"
+    control-tower-project = var.project
+    control-tower-component = "concourse"
+  }
+}
+
+{{if eq $.NATTopology "per-zone"}}
+// The zone's workers reach the internet through a NAT gateway in the same zone, so that they keep it when
+// another zone fails, and don't pay for traffic between zones
+resource "aws_subnet" "worker_nat_{{ $i }}" {
+  vpc_id                  = local.vpc_id
+  availability_zone       = "{{ $zone.Zone }}"
+  cidr_block              = "{{ $zone.NATCIDR }}"
+  map_public_ip_on_launch = false
+
+  tags = {
+    Name = "${var.deployment}-nat-{{ $zone.Zone }}"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+}
+
+resource "aws_eip" "nat_{{ $i }}" {
+  domain = "vpc"
+  depends_on = [aws_internet_gateway.default]
+
+  tags = {
+    Name = "${var.deployment}-nat-{{ $zone.Zone }}"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_nat_gateway" "worker_{{ $i }}" {
+  allocation_id = aws_eip.nat_{{ $i }}.id
+  subnet_id     = aws_subnet.worker_nat_{{ $i }}.id
+
+  depends_on = [aws_internet_gateway.default]
+
+  tags = {
+    Name = "${var.deployment}-nat-{{ $zone.Zone }}"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_route_table" "worker_{{ $i }}" {
+  vpc_id = local.vpc_id
 
+  route {
+    cidr_block = "0.0.0.0/0"
+    nat_gateway_id = aws_nat_gateway.worker_{{ $i }}.id
+  }
+{{if $.IPv6}}
+  route {
+    ipv6_cidr_block        = "::/0"
+    egress_only_gateway_id = aws_egress_only_internet_gateway.default.id
+  }
+{{end}}{{template "peer_routes" $}}
+  tags = {
+    Name = "${var.deployment}-worker-{{ $zone.Zone }}"
+    control-tower-project = var.project
+    control-tower-component = "concourse"
+  }
+}
+{{end}}
+resource "aws_route_table_association" "worker_{{ $i }}" {
+  subnet_id      = aws_subnet.worker_{{ $i }}.id
+  route_table_id = {{if eq $.NATTopology "per-zone"}}aws_route_table.worker_{{ $i }}.id{{else}}aws_route_table.private.id{{end}}
+}
+{{end}}
 {{if .HostedZoneID }}
 resource "aws_route53_record" "concourse" {
   zone_id = var.hosted_zone_id
   name    = var.hosted_zone_record_prefix
   ttl     = "60"
   type    = "A"
-  records = [aws_eip.atc.public_ip]
+  records = [{{if .Private}}cidrhost(var.public_cidr, 8){{else if gt .WebCount 1}}{{if .WebLBEIPAdopted}}data.{{end}}aws_eip.web_lb.public_ip{{else}}local.atc_public_ip{{end}}]
+}
+{{if .IPv6}}
+resource "aws_route53_record" "concourse_ipv6" {
+  zone_id = var.hosted_zone_id
+  name    = var.hosted_zone_record_prefix
+  type    = "AAAA"
+
+  alias {
+    name                   = aws_lb.web.dns_name
+    zone_id                = aws_lb.web.zone_id
+    evaluate_target_health = false
+  }
 }
 {{end}}
+{{range $i, $prefix := .AdditionalRecordPrefixes}}
+resource "aws_route53_record" "concourse_additional_{{ $i }}" {
+  zone_id = var.hosted_zone_id
+  name    = "{{ $prefix }}"
+  ttl     = "60"
+  type    = "A"
+  records = [{{if $.Private}}cidrhost(var.public_cidr, 8){{else if gt $.WebCount 1}}{{if $.WebLBEIPAdopted}}data.{{end}}aws_eip.web_lb.public_ip{{else}}local.atc_public_ip{{end}}]
+}
+{{if $.IPv6}}
+resource "aws_route53_record" "concourse_additional_ipv6_{{ $i }}" {
+  zone_id = var.hosted_zone_id
+  name    = "{{ $prefix }}"
+  type    = "AAAA"
 
-resource "aws_eip" "director" {
-  vpc = true
-  depends_on = [aws_internet_gateway.default]
+  alias {
+    name                   = aws_lb.web.dns_name
+    zone_id                = aws_lb.web.zone_id
+    evaluate_target_health = false
+  }
+}
+{{end}}
+{{end}}
+{{end}}
 
+{{if not .Private}}
+resource "aws_eip" "director" {
+  domain = "vpc"
+{{if not .ExistingVPCID}}  depends_on = [aws_internet_gateway.default]
+{{end}}
     tags = {
     Name = "${var.deployment}-director"
     control-tower-project = var.project
   }
 }
 
-resource "aws_eip" "atc" {
-  vpc = true
-  depends_on = [aws_internet_gateway.default]
+{{if .ATCEIPAdopted}}
+// The ATC takes an Elastic IP given with --web-eip, which is only read here so that destroying the deployment
+// leaves it be
+data "aws_eip" "atc" {
+  public_ip = "{{ .WebEIP }}"
+}
 
+locals {
+  atc_public_ip = data.aws_eip.atc.public_ip
+}
+{{else}}
+resource "aws_eip" "atc" {
+  domain = "vpc"
+{{if not .ExistingVPCID}}  depends_on = [aws_internet_gateway.default]
+{{end}}
   tags = {
     Name = "${var.deployment}-atc"
     control-tower-project = var.project
   }
 }
 
+locals {
+  atc_public_ip = aws_eip.atc.public_ip
+}
+{{end}}
+{{end}}
+
+{{if gt .WebCount 1}}
+// Several web VMs sit in the private subnet behind a load balancer, which takes the place of the ATC's Elastic IP
+{{if .WebLBEIPAdopted}}
+data "aws_eip" "web_lb" {
+  public_ip = "{{ .WebEIP }}"
+}
+{{else if not .Private}}
+resource "aws_eip" "web_lb" {
+  domain = "vpc"
+{{if not .ExistingVPCID}}  depends_on = [aws_internet_gateway.default]
+{{end}}
+  tags = {
+    Name = "${var.deployment}-web-lb"
+    control-tower-project = var.project
+  }
+}
+{{end}}
+
+resource "aws_lb" "web" {
+  name               = "${var.deployment}-web"
+  internal           = {{if .Private}}true{{else}}false{{end}}
+  load_balancer_type = "network"
+{{if .IPv6}}  ip_address_type    = "dualstack"
+  security_groups    = [aws_security_group.web_lb.id]
+{{end}}
+  subnet_mapping {
+    subnet_id            = local.public_subnet_id
+{{if .Private}}    private_ipv4_address = cidrhost(var.public_cidr, 8)
+{{else}}    allocation_id        = {{if .WebLBEIPAdopted}}data.{{end}}aws_eip.web_lb.id
+{{end}}  }
+
+  tags = {
+    Name = "${var.deployment}-web"
+    control-tower-project = var.project
+    control-tower-component = "concourse"
+  }
+}
+{{range $port := .WebLBPorts}}
+resource "aws_lb_target_group" "web_{{$port}}" {
+  name     = "${var.deployment}-web-{{$port}}"
+  port     = {{$port}}
+  protocol = "TCP"
+  vpc_id   = local.vpc_id
+
+  health_check {
+    protocol = "TCP"
+  }
+}
+
+resource "aws_lb_listener" "web_{{$port}}" {
+  load_balancer_arn = aws_lb.web.arn
+  port              = {{$port}}
+  protocol          = "TCP"
+
+  default_action {
+    type             = "forward"
+    target_group_arn = aws_lb_target_group.web_{{$port}}.arn
+  }
+}
+{{end}}
+{{if .IPv6}}
+// The load balancer passes IPv6 clients on to the web VMs from its own IPv4 address, which the ATC security
+// group lets through for health checks, so they are filtered here instead. IPv4 clients keep their addresses
+// and are still filtered by the ATC security group
+resource "aws_security_group" "web_lb" {
+  name        = "${var.deployment}-web-lb"
+  description = "Control-Tower web load balancer security group"
+  vpc_id      = local.vpc_id
+
+  tags = {
+    Name = "${var.deployment}-web-lb"
+    control-tower-project = var.project
+    control-tower-component = "concourse"
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [var.network_cidr]
+  }
+{{range $port := .WebLBPorts}}
+  ingress {
+    from_port        = {{$port}}
+    to_port          = {{$port}}
+    protocol         = "tcp"
+    cidr_blocks      = ["0.0.0.0/0"]
+    ipv6_cidr_blocks = [{{ $.AllowIPv6s }}]
+  }
+{{end}}}
+{{end}}
+{{end}}
+
+{{if not .ExistingVPCID}}
 resource "aws_eip" "nat" {
-  vpc = true
+  domain = "vpc"
   depends_on = [aws_internet_gateway.default]
 
   tags = {
@@ -364,57 +1053,171 @@ resource "aws_eip" "nat" {
     control-tower-project = var.project
   }
 }
+{{end}}
 
 resource "aws_ec2_subnet_cidr_reservation" "director" {
   cidr_block       = "${cidrhost(var.public_cidr, 6)}/32"
   reservation_type = "explicit"
-  subnet_id        = aws_subnet.public.id
+  subnet_id        = local.public_subnet_id
+}
+
+resource "aws_security_group" "director" {
+  name        = "${var.deployment}-director"
+  description = "Control-Tower Default BOSH security group"
+  vpc_id      = local.vpc_id
+
+  tags = {
+    Name = "${var.deployment}-director"
+    control-tower-project = var.project
+    control-tower-component = "bosh"
+  }
+
+  ingress {
+    from_port   = 6868
+    to_port     = 6868
+    protocol    = "tcp"
+    cidr_blocks = ["${var.source_access_ip}/32", {{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}{{end}}{{if .DirectorAllowIPs}}, {{ .DirectorAllowIPs }}{{end}}]
+{{if .Bastion}}    security_groups = [aws_security_group.bastion.id]
+{{end}}  }
+
+  ingress {
+    from_port   = 25555
+    to_port     = 25555
+    protocol    = "tcp"
+    cidr_blocks = ["${var.source_access_ip}/32", {{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}{{end}}{{if .DirectorAllowIPs}}, {{ .DirectorAllowIPs }}{{end}}]
+{{if .Bastion}}    security_groups = [aws_security_group.bastion.id]
+{{end}}  }
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["${var.source_access_ip}/32", {{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}{{end}}{{if .SSHAllowIPs}}, {{ .SSHAllowIPs }}{{end}}]
+{{if .Bastion}}    security_groups = [aws_security_group.bastion.id]
+{{end}}  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+{{if .Bastion}}
+// The bastion only accepts SSH, with its own key, and only reaches the deployment's network and package mirrors
+data "aws_ami" "bastion" {
+  most_recent = true
+  owners      = ["099720109477"]
+
+  filter {
+    name   = "name"
+    values = ["ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-amd64-server-*"]
+  }
 }
 
-resource "aws_security_group" "director" {
-  name        = "${var.deployment}-director"
-  description = "Control-Tower Default BOSH security group"
-  vpc_id      = aws_vpc.default.id
+resource "aws_key_pair" "bastion" {
+  key_name   = "${var.deployment}-bastion"
+  public_key = "{{ .BastionPublicKey }}"
+}
+
+resource "aws_security_group" "bastion" {
+  name        = "${var.deployment}-bastion"
+  description = "Control-Tower bastion security group"
+  vpc_id      = local.vpc_id
 
   tags = {
-    Name = "${var.deployment}-director"
+    Name = "${var.deployment}-bastion"
     control-tower-project = var.project
-    control-tower-component = "bosh"
-  }
-
-  ingress {
-    from_port   = 6868
-    to_port     = 6868
-    protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
-  }
-
-  ingress {
-    from_port   = 25555
-    to_port     = 25555
-    protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    control-tower-component = "bastion"
   }
 
   ingress {
     from_port   = 22
     to_port     = 22
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    cidr_blocks = ["${var.source_access_ip}/32"{{if .BastionAllowIPs}}, {{ .BastionAllowIPs }}{{end}}]
   }
 
   egress {
     from_port   = 0
     to_port     = 0
     protocol    = "-1"
+    cidr_blocks = [var.network_cidr]
+  }
+
+  egress {
+    from_port   = 80
+    to_port     = 80
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  egress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
     cidr_blocks = ["0.0.0.0/0"]
   }
 }
 
+resource "aws_instance" "bastion" {
+  ami                    = data.aws_ami.bastion.id
+  instance_type          = "t3.nano"
+  subnet_id              = aws_subnet.nat.id
+  vpc_security_group_ids = [aws_security_group.bastion.id]
+  key_name               = aws_key_pair.bastion.key_name
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  root_block_device {
+    encrypted = true
+  }
+
+  user_data = <<EOF
+#cloud-config
+ssh_pwauth: false
+disable_root: true
+write_files:
+- path: /etc/ssh/sshd_config.d/99-control-tower.conf
+  content: |
+    PasswordAuthentication no
+    KbdInteractiveAuthentication no
+    PermitRootLogin no
+    AllowUsers ubuntu
+    AllowAgentForwarding no
+    X11Forwarding no
+    MaxAuthTries 3
+    ClientAliveInterval 300
+runcmd:
+- systemctl restart ssh
+EOF
+
+  tags = {
+    Name = "${var.deployment}-bastion"
+    control-tower-project = var.project
+    control-tower-component = "bastion"
+  }
+}
+
+resource "aws_eip" "bastion" {
+  domain   = "vpc"
+  instance = aws_instance.bastion.id
+
+  tags = {
+    Name = "${var.deployment}-bastion"
+    control-tower-project = var.project
+  }
+}
+{{end}}
+
 resource "aws_security_group" "vms" {
   name        = "${var.deployment}-vms"
   description = "Control-Tower VMs security group"
-  vpc_id      = aws_vpc.default.id
+  vpc_id      = local.vpc_id
 
   tags = {
     Name = "${var.deployment}-vms"
@@ -506,18 +1309,65 @@ resource "aws_security_group" "vms" {
     protocol  = "tcp"
   }
 
+{{if .RestrictWorkerEgress}}
+  // Workers only reach the deployment's network, S3 for the blobstore and the egress allow-list. Web
+  // VMs keep open egress through the ATC security group
   egress {
     from_port   = 0
     to_port     = 0
     protocol    = "-1"
-    cidr_blocks = ["0.0.0.0/0"]
+    cidr_blocks = [var.network_cidr]
+  }
+
+  egress {
+    from_port       = 443
+    to_port         = 443
+    protocol        = "tcp"
+    prefix_list_ids = [data.aws_prefix_list.s3.id]
+  }
+{{range .KeyedPeerVPCIDs}}
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [data.aws_vpc.peer_{{ .Key }}.cidr_block]
   }
+{{end}}{{if .TransitGatewayRoutes}}
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [{{range $i, $cidr := .TransitGatewayRoutes}}{{if $i}}, {{end}}"{{ $cidr }}"{{end}}]
+  }
+{{end}}{{range .WorkerEgressAllow}}
+  egress {
+    from_port   = {{.FromPort}}
+    to_port     = {{.ToPort}}
+    protocol    = "{{.Protocol}}"
+    cidr_blocks = ["{{.CIDR}}"]
+  }
+{{end}}
+{{else}}
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+{{if .IPv6}}    ipv6_cidr_blocks = ["::/0"]
+{{end}}  }
+{{end}}
+}
+
+{{if .RestrictWorkerEgress}}
+data "aws_prefix_list" "s3" {
+  name = "com.amazonaws.${var.region}.s3"
 }
+{{end}}
 
 resource "aws_security_group" "rds" {
   name        = "${var.deployment}-rds"
   description = "Control-Tower RDS security group"
-  vpc_id      = aws_vpc.default.id
+  vpc_id      = local.vpc_id
 
   tags = {
     Name = "${var.deployment}-rds"
@@ -536,8 +1386,9 @@ resource "aws_security_group" "rds" {
 resource "aws_security_group" "atc" {
   name        = "${var.deployment}-atc"
   description = "Control-Tower ATC security group"
-  vpc_id      = aws_vpc.default.id
-  depends_on = [aws_eip.nat, aws_eip.atc]
+  vpc_id      = local.vpc_id
+{{if not .Private}}  depends_on = [{{if not .ExistingVPCID}}aws_eip.nat, {{end}}{{if .ATCEIPAdopted}}data.{{end}}aws_eip.atc]
+{{end}}
 
   tags = {
     Name = "${var.deployment}-atc"
@@ -550,7 +1401,8 @@ resource "aws_security_group" "atc" {
     to_port     = 0
     protocol    = "-1"
     cidr_blocks = ["0.0.0.0/0"]
-  }
+{{if .IPv6}}    ipv6_cidr_blocks = ["::/0"]
+{{end}}  }
 
   // HTTP
   ingress {
@@ -558,7 +1410,7 @@ resource "aws_security_group" "atc" {
     to_port     = 80
     protocol    = "tcp"
     security_groups = [aws_security_group.vms.id, aws_security_group.director.id]
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = [{{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}, "${local.atc_public_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   }
 
   // HTTPS
@@ -566,7 +1418,7 @@ resource "aws_security_group" "atc" {
     from_port   = 443
     to_port     = 443
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = [{{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}, "${local.atc_public_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   }
 
   // Credhub
@@ -574,7 +1426,7 @@ resource "aws_security_group" "atc" {
     from_port   = 8844
     to_port     = 8844
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = [{{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}, "${local.atc_public_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   }
 
   // UAA
@@ -582,7 +1434,7 @@ resource "aws_security_group" "atc" {
     from_port   = 8443
     to_port     = 8443
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = [{{if .Private}}var.network_cidr{{else}}"${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}, "${local.atc_public_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   }
 
 {{if .MetricsEnabled}}
@@ -591,7 +1443,7 @@ resource "aws_security_group" "atc" {
     from_port   = 3000
     to_port     = 3000
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_public_ip}/32"{{template "zone_nat_cidrs" .}}, {{ .MetricsAllowIPs }}]
   }
 
   // Telegraf/InfluxDB
@@ -602,8 +1454,195 @@ resource "aws_security_group" "atc" {
     cidr_blocks = [var.private_cidr]
   }
 {{ end }}
+
+{{if gt .WebCount 1}}{{range $port := .WebLBPorts}}
+  // Health checks from the web load balancer
+  ingress {
+    from_port   = {{$port}}
+    to_port     = {{$port}}
+    protocol    = "tcp"
+    cidr_blocks = [var.public_cidr]
+  }
+{{end}}{{end}}
+
+{{if .PrometheusMetrics}}
+  // Concourse Prometheus metrics
+  ingress {
+    from_port   = 9391
+    to_port     = 9391
+    protocol    = "tcp"
+    cidr_blocks = [var.private_cidr]
+  }
+{{end}}
+
+{{if .MetricsScrapeAllowIPs}}
+  // Metrics scraping over HTTPS with basic auth
+  ingress {
+    from_port   = 9392
+    to_port     = 9392
+    protocol    = "tcp"
+    cidr_blocks = [{{range $i, $ip := .MetricsScrapeAllowIPs}}{{if $i}}, {{end}}"{{$ip}}"{{end}}]
+  }
+{{end}}
+
+{{if .ExternalWorkerAllowIPs}}
+  // Registration of workers outside the deployment with the TSA
+  ingress {
+    from_port   = 2222
+    to_port     = 2222
+    protocol    = "tcp"
+    cidr_blocks = [{{range $i, $ip := .ExternalWorkerAllowIPs}}{{if $i}}, {{end}}"{{$ip}}"{{end}}]
+  }
+{{end}}
+
+{{if .InternalLB}}
+  // HTTPS and TSA via the internal load balancer, which preserves client IPs
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = [{{if .InternalLBAllowIPs}}{{ .InternalLBAllowIPs }}{{else}}var.network_cidr{{end}}]
+  }
+
+  ingress {
+    from_port   = 2222
+    to_port     = 2222
+    protocol    = "tcp"
+    cidr_blocks = [{{if .InternalLBAllowIPs}}{{ .InternalLBAllowIPs }}{{else}}var.network_cidr{{end}}]
+  }
+{{end}}
+}
+
+{{if .InternalLB}}
+resource "aws_lb" "internal" {
+  name               = "${var.deployment}-internal"
+  internal           = true
+  load_balancer_type = "network"
+  subnets            = [local.public_subnet_id]
+
+  tags = {
+    Name = "${var.deployment}-internal"
+    control-tower-project = var.project
+    control-tower-component = "concourse"
+  }
+}
+
+resource "aws_lb_target_group" "internal_web" {
+  name               = "${var.deployment}-internal-web"
+  port               = 443
+  protocol           = "{{if .InternalLBTLSCert}}TLS{{else}}TCP{{end}}"
+  target_type        = "ip"
+  vpc_id             = local.vpc_id
+  preserve_client_ip = true
+
+  health_check {
+    protocol            = "{{if .InternalLBHealthCheckPath}}HTTPS{{else}}TCP{{end}}"
+{{if .InternalLBHealthCheckPath}}
+    path                = "{{ .InternalLBHealthCheckPath }}"
+    matcher             = "200-399"
+{{end}}
+{{if .InternalLBHealthCheckInterval}}
+    interval            = {{ .InternalLBHealthCheckInterval }}
+{{end}}
+{{if .InternalLBHealthyThreshold}}
+    healthy_threshold   = {{ .InternalLBHealthyThreshold }}
+{{end}}
+{{if .InternalLBUnhealthyThreshold}}
+    unhealthy_threshold = {{ .InternalLBUnhealthyThreshold }}
+{{end}}
+  }
+}
+
+resource "aws_lb_target_group_attachment" "internal_web" {
+{{if gt .WebCount 1}}
+  count            = {{ .WebCount }}
+  target_group_arn = aws_lb_target_group.internal_web.arn
+  target_id        = cidrhost(var.private_cidr, 8 + count.index)
+{{else}}
+  target_group_arn = aws_lb_target_group.internal_web.arn
+  target_id        = cidrhost(var.public_cidr, 8)
+{{end}}
+  port             = 443
+}
+
+resource "aws_lb_target_group" "internal_tsa" {
+  name               = "${var.deployment}-internal-tsa"
+  port               = 2222
+  protocol           = "TCP"
+  target_type        = "ip"
+  vpc_id             = local.vpc_id
+  preserve_client_ip = true
+
+  health_check {
+    protocol            = "TCP"
+{{if .InternalLBHealthCheckInterval}}
+    interval            = {{ .InternalLBHealthCheckInterval }}
+{{end}}
+{{if .InternalLBHealthyThreshold}}
+    healthy_threshold   = {{ .InternalLBHealthyThreshold }}
+{{end}}
+{{if .InternalLBUnhealthyThreshold}}
+    unhealthy_threshold = {{ .InternalLBUnhealthyThreshold }}
+{{end}}
+  }
+}
+
+resource "aws_lb_target_group_attachment" "internal_tsa" {
+{{if gt .WebCount 1}}
+  count            = {{ .WebCount }}
+  target_group_arn = aws_lb_target_group.internal_tsa.arn
+  target_id        = cidrhost(var.private_cidr, 8 + count.index)
+{{else}}
+  target_group_arn = aws_lb_target_group.internal_tsa.arn
+  target_id        = cidrhost(var.public_cidr, 8)
+{{end}}
+  port             = 2222
+}
+
+{{if .InternalLBTLSCert}}
+resource "aws_acm_certificate" "internal_lb" {
+  private_key      = <<EOT
+{{ .InternalLBTLSKey }}
+EOT
+  certificate_body = <<EOT
+{{ .InternalLBTLSCert }}
+EOT
+
+  tags = {
+    Name = "${var.deployment}-internal"
+    control-tower-project = var.project
+  }
+}
+{{end}}
+
+resource "aws_lb_listener" "internal_web" {
+  load_balancer_arn = aws_lb.internal.arn
+  port              = 443
+  protocol          = "{{if .InternalLBTLSCert}}TLS{{else}}TCP{{end}}"
+{{if .InternalLBTLSCert}}
+  certificate_arn   = aws_acm_certificate.internal_lb.arn
+  ssl_policy        = "ELBSecurityPolicy-TLS13-1-2-2021-06"
+{{end}}
+
+  default_action {
+    type             = "forward"
+    target_group_arn = aws_lb_target_group.internal_web.arn
+  }
 }
 
+resource "aws_lb_listener" "internal_tsa" {
+  load_balancer_arn = aws_lb.internal.arn
+  port              = 2222
+  protocol          = "TCP"
+
+  default_action {
+    type             = "forward"
+    target_group_arn = aws_lb_target_group.internal_tsa.arn
+  }
+}
+{{end}}
+
+{{if not .ExistingVPCID}}
 resource "aws_route_table" "rds" {
   vpc_id = aws_vpc.default.id
 
@@ -647,10 +1686,12 @@ resource "aws_subnet" "rds_b" {
     control-tower-component = "rds"
   }
 }
+{{end}}
 
+{{if not .ExternalDBHost}}
 resource "aws_db_subnet_group" "default" {
   name       = var.deployment
-  subnet_ids = [aws_subnet.rds_a.id, aws_subnet.rds_b.id]
+  subnet_ids = [{{if .ExistingVPCID}}{{range $i, $subnet := .ExistingDBSubnetIDs}}{{if $i}}, {{end}}"{{$subnet}}"{{end}}{{else}}aws_subnet.rds_a.id, aws_subnet.rds_b.id{{end}}]
 
   tags = {
     Name = var.deployment
@@ -672,38 +1713,222 @@ resource "aws_kms_alias" "default_key_alias" {
   target_key_id = aws_kms_key.default_key[0].key_id
 }
 
+{{if .DBParameters}}
+resource "{{if .DBMaxACU}}aws_rds_cluster_parameter_group{{else}}aws_db_parameter_group{{end}}" "default" {
+  name_prefix = "${var.deployment}-"
+  family      = "{{.DBParameterGroupFamily}}"
+{{range .DBParameters}}
+  parameter {
+    name         = "{{.Name}}"
+    value        = "{{.Value}}"
+    apply_method = "{{.ApplyMethod}}"
+  }
+{{end}}
+  lifecycle {
+    create_before_destroy = true
+  }
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+    control-tower-component = "rds"
+  }
+}
+{{end}}
+{{if .DBMaxACU}}
+resource "aws_rds_cluster" "default" {
+  cluster_identifier          = {{if .DBRestoreInstance}}"{{.DBRestoreInstance}}"{{else}}var.deployment{{end}}
+  engine                      = "aurora-postgresql"
+  engine_mode                 = "provisioned"
+  engine_version              = "{{.DBEngineVersion}}"
+  allow_major_version_upgrade = true
+  apply_immediately           = true
+  port                        = 5432
+  database_name               = var.rds_default_database_name
+  master_username             = var.rds_instance_username
+  master_password             = var.rds_instance_password
+  vpc_security_group_ids      = [aws_security_group.rds.id]
+  db_subnet_group_name        = aws_db_subnet_group.default.name
+  skip_final_snapshot         = true
+  storage_encrypted           = {{if .DBKMSKeyARN}}true{{else}}var.rds_disk_encryption{{end}}
+  kms_key_id                  = {{if .DBKMSKeyARN}}"{{.DBKMSKeyARN}}"{{else}}var.rds_disk_encryption == "true" ? aws_kms_key.default_key[0].arn : ""{{end}}
+  deletion_protection         = {{.DBDeletionProtection}}
+{{if .DBBackupRetention}}  backup_retention_period     = {{.DBBackupRetention}}
+{{end}}{{if .DBBackupWindow}}  preferred_backup_window     = "{{.DBBackupWindow}}"
+{{end}}{{if .DBParameters}}  db_cluster_parameter_group_name = aws_rds_cluster_parameter_group.default.name
+{{end}}{{if .DBIAMUser}}  iam_database_authentication_enabled = true
+{{end}}
+{{if .DBRestoreInstance}}
+  restore_to_point_in_time {
+    source_cluster_identifier = "{{.DBRestoreSource}}"
+    restore_to_time           = "{{.DBRestoreTime}}"
+  }
+{{end}}
+  serverlessv2_scaling_configuration {
+    min_capacity = {{.DBMinACU}}
+    max_capacity = {{.DBMaxACU}}
+  }
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+    control-tower-component = "rds"
+  }
+}
+
+resource "aws_rds_cluster_instance" "default" {
+  identifier                 = {{if .DBRestoreInstance}}"{{.DBRestoreInstance}}"{{else}}var.deployment{{end}}
+  cluster_identifier         = aws_rds_cluster.default.id
+  instance_class             = "db.serverless"
+  engine                     = aws_rds_cluster.default.engine
+  engine_version             = aws_rds_cluster.default.engine_version
+  auto_minor_version_upgrade = false
+  publicly_accessible        = false
+  db_subnet_group_name       = aws_db_subnet_group.default.name
+  apply_immediately          = true
+{{if .DBCACertIdentifier}}  ca_cert_identifier         = "{{.DBCACertIdentifier}}"
+{{end}}
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+    control-tower-component = "rds"
+  }
+}
+{{else}}
 resource "aws_db_instance" "default" {
-  allocated_storage           = 10
+  allocated_storage           = {{if .DBStorage}}{{.DBStorage}}{{else}}10{{end}}
+  max_allocated_storage       = {{.DBMaxStorage}}
   apply_immediately           = true
   port                        = 5432
   engine                      = "postgres"
   instance_class              = var.rds_instance_class
-  engine_version              = "13"
+  engine_version              = "{{.DBEngineVersion}}"
   auto_minor_version_upgrade  = true
   allow_major_version_upgrade = true
   db_name                     = var.rds_default_database_name
   username                    = var.rds_instance_username
   password                    = var.rds_instance_password
   publicly_accessible         = false
-  multi_az                    = false
+  multi_az                    = {{.DBMultiAZ}}
   vpc_security_group_ids      = [aws_security_group.rds.id]
   db_subnet_group_name        = aws_db_subnet_group.default.name
   skip_final_snapshot         = true
-  storage_type                = "gp2"
-  storage_encrypted           = var.rds_disk_encryption
-  kms_key_id                  = var.rds_disk_encryption == "true" ? aws_kms_key.default_key[0].arn : ""
+  storage_type                = "{{if .DBStorageType}}{{.DBStorageType}}{{else}}gp2{{end}}"
+{{if .DBIOPS}}  iops                        = {{.DBIOPS}}
+{{end}}  storage_encrypted           = {{if .DBKMSKeyARN}}true{{else}}var.rds_disk_encryption{{end}}
+  kms_key_id                  = {{if .DBKMSKeyARN}}"{{.DBKMSKeyARN}}"{{else}}var.rds_disk_encryption == "true" ? aws_kms_key.default_key[0].arn : ""{{end}}
+  deletion_protection         = {{.DBDeletionProtection}}
+{{if .DBBackupRetention}}  backup_retention_period     = {{.DBBackupRetention}}
+{{end}}{{if .DBBackupWindow}}  preferred_backup_window     = "{{.DBBackupWindow}}"
+{{end}}{{if .DBParameters}}  parameter_group_name        = aws_db_parameter_group.default.name
+{{end}}{{if .DBCACertIdentifier}}  ca_cert_identifier          = "{{.DBCACertIdentifier}}"
+{{end}}{{if .DBIAMUser}}  iam_database_authentication_enabled = true
+{{end}}{{if .DBRestoreInstance}}  identifier                  = "{{.DBRestoreInstance}}"
+
+  restore_to_point_in_time {
+    source_db_instance_identifier = "{{.DBRestoreSource}}"
+    restore_time                  = "{{.DBRestoreTime}}"
+  }
+{{end}}{{if .DBBlueGreenUpdate}}
+  blue_green_update {
+    enabled = true
+  }
+{{end}}{{if not .DBStorage}}
   lifecycle {
     ignore_changes = [allocated_storage]
   }
+{{end}}
   tags = {
     Name = var.deployment
     control-tower-project = var.project
     control-tower-component = "rds"
   }
 }
+{{end}}
+{{if .DBReadReplica}}
+{{if .DBMaxACU}}
+resource "aws_rds_cluster_instance" "replica" {
+  identifier                 = "${aws_rds_cluster_instance.default.identifier}-replica"
+  cluster_identifier         = aws_rds_cluster.default.id
+  instance_class             = "db.serverless"
+  engine                     = aws_rds_cluster.default.engine
+  engine_version             = aws_rds_cluster.default.engine_version
+  auto_minor_version_upgrade = false
+  publicly_accessible        = false
+  db_subnet_group_name       = aws_db_subnet_group.default.name
+  promotion_tier             = 15
+  apply_immediately          = true
+{{if .DBCACertIdentifier}}  ca_cert_identifier         = "{{.DBCACertIdentifier}}"
+{{end}}
+  tags = {
+    Name = "${var.deployment}-replica"
+    control-tower-project = var.project
+    control-tower-component = "rds"
+  }
+}
+{{else}}
+resource "aws_db_instance" "replica" {
+  identifier_prefix      = "${var.deployment}-replica-"
+  replicate_source_db    = aws_db_instance.default.identifier
+  instance_class         = var.rds_instance_class
+  apply_immediately      = true
+  publicly_accessible    = false
+  vpc_security_group_ids = [aws_security_group.rds.id]
+  skip_final_snapshot    = true
+{{if .DBParameters}}  parameter_group_name   = aws_db_parameter_group.default.name
+{{end}}{{if .DBCACertIdentifier}}  ca_cert_identifier     = "{{.DBCACertIdentifier}}"
+{{end}}
+  tags = {
+    Name = "${var.deployment}-replica"
+    control-tower-project = var.project
+    control-tower-component = "rds"
+  }
+}
+{{end}}
+{{end}}
+{{range $i, $instance := .DBArchivedInstances}}
+{{if $.DBMaxACU}}
+# {{$instance}} was replaced by restore-db, and is kept as it was until the deployment is destroyed{{if eq (printf "archived_%d" $i) $.DirectorDBArchive}}.
+# The director still keeps its database on it{{end}}
+resource "aws_rds_cluster" "archived_{{$i}}" {
+  engine                 = "aurora-postgresql"
+  vpc_security_group_ids = [aws_security_group.rds.id]
+  db_subnet_group_name   = aws_db_subnet_group.default.name
+  skip_final_snapshot    = true
+
+  lifecycle {
+    ignore_changes = all
+  }
+}
+
+resource "aws_rds_cluster_instance" "archived_{{$i}}" {
+  cluster_identifier = aws_rds_cluster.archived_{{$i}}.id
+  instance_class     = "db.serverless"
+  engine             = "aurora-postgresql"
+
+  lifecycle {
+    ignore_changes = all
+  }
+}
+{{else}}
+# {{$instance}} was replaced by restore-db, and is kept as it was until the deployment is destroyed{{if eq (printf "archived_%d" $i) $.DirectorDBArchive}}.
+# The director still keeps its database on it{{end}}
+resource "aws_db_instance" "archived_{{$i}}" {
+  instance_class         = var.rds_instance_class
+  vpc_security_group_ids = [aws_security_group.rds.id]
+  db_subnet_group_name   = aws_db_subnet_group.default.name
+  skip_final_snapshot    = true
+
+  lifecycle {
+    ignore_changes = all
+  }
+}
+{{end}}
+{{end}}
+{{end}}
 
 output "vpc_id" {
-  value = aws_vpc.default.id
+  value = local.vpc_id
 }
 
 output "source_access_ip" {
@@ -715,11 +1940,15 @@ output "director_key_pair" {
 }
 
 output "director_public_ip" {
-  value = aws_eip.director.public_ip
+  value = {{if .Private}}cidrhost(var.public_cidr, 6){{else}}aws_eip.director.public_ip{{end}}
 }
 
 output "atc_public_ip" {
-  value = aws_eip.atc.public_ip
+  value = {{if .Private}}cidrhost(var.public_cidr, 8){{else if gt .WebCount 1}}{{if .WebLBEIPAdopted}}data.{{end}}aws_eip.web_lb.public_ip{{else}}local.atc_public_ip{{end}}
+}
+
+output "web_target_groups" {
+  value = {{if gt .WebCount 1}}join(",", [{{range $i, $port := .WebLBPorts}}{{if $i}}, {{end}}aws_lb_target_group.web_{{$port}}.name{{end}}]){{else}}""{{end}}
 }
 
 output "director_security_group_id" {
@@ -734,20 +1963,36 @@ output "atc_security_group_id" {
   value = aws_security_group.atc.id
 }
 
+output "internal_lb_dns_name" {
+  value = {{if .InternalLB}}aws_lb.internal.dns_name{{else}}""{{end}}
+}
+
+output "web_instance_profile" {
+  value = {{if or .SecretsManager .SSM .ManagedPrometheus .DBIAMUser}}aws_iam_instance_profile.web.name{{else}}""{{end}}
+}
+
 output "nat_gateway_ip" {
-  value = aws_nat_gateway.default.public_ip
+  value = local.nat_public_ip
 }
 
 output "nat_gateway_private_ip" {
-  value = aws_nat_gateway.default.private_ip
+  value = {{if .ExistingVPCID}}""{{else if eq .NATTopology "instance"}}aws_instance.nat.private_ip{{else}}aws_nat_gateway.default.private_ip{{end}}
 }
 
 output "public_subnet_id" {
-  value = aws_subnet.public.id
+  value = local.public_subnet_id
 }
 
 output "private_subnet_id" {
-  value = aws_subnet.private.id
+  value = local.private_subnet_id
+}
+
+output "worker_subnet_ids" {
+  value = join(",", [{{range $i, $zone := .WorkerZones }}{{if $i}}, {{end}}aws_subnet.worker_{{ $i }}.id{{end}}])
+}
+
+output "bastion_public_ip" {
+  value = {{if .Bastion}}aws_eip.bastion.public_ip{{else}}""{{end}}
 }
 
 output "blobstore_bucket" {
@@ -781,10 +2026,27 @@ output "self_update_user_secret_access_key" {
   sensitive = true
 }
 
+output "runtime_user_access_key_id" {
+  value = aws_iam_access_key.runtime.id
+}
+
+output "runtime_user_secret_access_key" {
+  value     = aws_iam_access_key.runtime.secret
+  sensitive = true
+}
+
 output "bosh_db_port" {
-  value = tostring(aws_db_instance.default.port)
+  value = {{if .ExternalDBHost}}"{{.ExternalDBPort}}"{{else if .DBMaxACU}}tostring(aws_rds_cluster.default.port){{else}}tostring(aws_db_instance.default.port){{end}}
+}
+
+output "db_replica_address" {
+  value = {{if not .DBReadReplica}}""{{else if .DBMaxACU}}aws_rds_cluster.default.reader_endpoint{{else}}aws_db_instance.replica.address{{end}}
 }
 
 output "bosh_db_address" {
-  value = aws_db_instance.default.address
+  value = {{if .ExternalDBHost}}"{{.ExternalDBHost}}"{{else if .DBMaxACU}}aws_rds_cluster.default.endpoint{{else}}aws_db_instance.default.address{{end}}
+}
+
+output "director_db_address" {
+  value = {{if .ExternalDBHost}}"{{.ExternalDBHost}}"{{else if .DirectorDBArchive}}{{if .DBMaxACU}}aws_rds_cluster.{{.DirectorDBArchive}}.endpoint{{else}}aws_db_instance.{{.DirectorDBArchive}}.address{{end}}{{else if .DBMaxACU}}aws_rds_cluster.default.endpoint{{else}}aws_db_instance.default.address{{end}}
 }
@@ -0,0 +1,192 @@
+// Network and firewall module matching the layout control-tower deploys Concourse into, for placing
+// other systems on the same standard network. Generated by `control-tower generate-terraform --network-only`.
+
+variable "region" {
+  type = string
+}
+
+variable "availability_zone" {
+  type = string
+}
+
+variable "deployment" {
+  type = string
+}
+
+variable "project" {
+  type = string
+}
+
+variable "network_cidr" {
+  type    = string
+  default = "10.0.0.0/16"
+}
+
+variable "public_cidr" {
+  type    = string
+  default = "10.0.0.0/24"
+}
+
+variable "private_cidr" {
+  type    = string
+  default = "10.0.1.0/24"
+}
+
+variable "source_access_ip" {
+  type        = string
+  description = "CIDR range allowed to reach SSH on the public subnet"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+      version = "~> 5.46"
+    }
+  }
+}
+
+provider "aws" {
+  region = var.region
+}
+
+resource "aws_vpc" "default" {
+  cidr_block = var.network_cidr
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_internet_gateway" "default" {
+  vpc_id = aws_vpc.default.id
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_route" "internet_access" {
+  route_table_id         = aws_vpc.default.main_route_table_id
+  destination_cidr_block = "0.0.0.0/0"
+  gateway_id             = aws_internet_gateway.default.id
+}
+
+resource "aws_eip" "nat" {
+  domain = "vpc"
+  depends_on = [aws_internet_gateway.default]
+
+  tags = {
+    Name = "${var.deployment}-nat"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_nat_gateway" "default" {
+  allocation_id = aws_eip.nat.id
+  subnet_id     = aws_subnet.public.id
+
+  depends_on = [aws_internet_gateway.default]
+
+  tags = {
+    Name = var.deployment
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_route_table" "private" {
+  vpc_id = aws_vpc.default.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    nat_gateway_id = aws_nat_gateway.default.id
+  }
+
+  tags = {
+    Name = "${var.deployment}-private"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_subnet" "public" {
+  vpc_id                  = aws_vpc.default.id
+  availability_zone       = var.availability_zone
+  cidr_block              = var.public_cidr
+  map_public_ip_on_launch = true
+
+  tags = {
+    Name = "${var.deployment}-public"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_subnet" "private" {
+  vpc_id                  = aws_vpc.default.id
+  availability_zone       = var.availability_zone
+  cidr_block              = var.private_cidr
+  map_public_ip_on_launch = false
+
+  tags = {
+    Name = "${var.deployment}-private"
+    control-tower-project = var.project
+  }
+}
+
+resource "aws_route_table_association" "private" {
+  subnet_id      = aws_subnet.private.id
+  route_table_id = aws_route_table.private.id
+}
+
+resource "aws_security_group" "internal" {
+  name        = "${var.deployment}-internal"
+  description = "Traffic within the network"
+  vpc_id      = aws_vpc.default.id
+
+  tags = {
+    Name = "${var.deployment}-internal"
+    control-tower-project = var.project
+  }
+
+  ingress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [var.network_cidr]
+  }
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = [var.source_access_ip]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+output "vpc_id" {
+  value = aws_vpc.default.id
+}
+
+output "public_subnet_id" {
+  value = aws_subnet.public.id
+}
+
+output "private_subnet_id" {
+  value = aws_subnet.private.id
+}
+
+output "internal_security_group_id" {
+  value = aws_security_group.internal.id
+}
+
+output "nat_gateway_ip" {
+  value = aws_eip.nat.public_ip
+}
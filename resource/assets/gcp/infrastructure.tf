@@ -84,6 +84,15 @@ provider "google" {
     project = "{{ .Project }}"
     region = var.region
 }
+{{if .GoogleBetaProvider}}
+// Network Connectivity Center VPC spokes are only in newer versions of the provider than the deployment's other
+// resources use, so they come from google-beta alongside them
+provider "google-beta" {
+    credentials = "{{ .GCPCredentialsJSON }}"
+    project = "{{ .Project }}"
+    region = var.region
+}
+{{end}}
 
 
 terraform {
@@ -94,9 +103,13 @@ terraform {
     required_providers {
       google = {
         source = "hashicorp/google"
-        version = "~> 3.49.0"
+        version = "~> 3.90.0"
       }
-    }
+{{if .GoogleBetaProvider}}      google-beta = {
+        source = "hashicorp/google-beta"
+        version = "~> 5.40.0"
+      }
+{{end}}    }
 }
 
 {{if .DNSManagedZoneName }}
@@ -110,14 +123,24 @@ resource "google_dns_record_set" "dns" {
   type    = "A"
   ttl     = 60
 
-  rrdatas = [google_compute_address.atc_ip.address]
+  rrdatas = [{{if .Private}}cidrhost(var.public_cidr, 7){{else if gt .WebCount 1}}{{if .WebLBIPAdopted}}data.{{end}}google_compute_address.web_lb.address{{else}}local.atc_ip{{end}}]
+}
+{{range $i, $prefix := .AdditionalRecordSetPrefixes}}
+resource "google_dns_record_set" "dns_additional_{{ $i }}" {
+  managed_zone = data.google_dns_managed_zone.dns_zone.name
+  name = "{{ $prefix }}${data.google_dns_managed_zone.dns_zone.dns_name}"
+  type    = "A"
+  ttl     = 60
+
+  rrdatas = [{{if .Private}}cidrhost(var.public_cidr, 7){{else if gt .WebCount 1}}{{if .WebLBIPAdopted}}data.{{end}}google_compute_address.web_lb.address{{else}}local.atc_ip{{end}}]
 }
 {{end}}
+{{end}}
 
 resource "google_compute_router" "nat-router" {
   name    = "${var.deployment}-router"
   region  = var.region
-  network = google_compute_network.default.self_link
+  network = local.network.self_link
   bgp {
     asn = 64514
   }
@@ -132,15 +155,36 @@ resource "google_compute_router_nat" "worker-nat" {
   nat_ip_allocate_option             = "MANUAL_ONLY"
   source_subnetwork_ip_ranges_to_nat = "LIST_OF_SUBNETWORKS"
   subnetwork {
-    name                    = google_compute_subnetwork.private.self_link
+    name                    = local.private_subnetwork.self_link
+    source_ip_ranges_to_nat = ["ALL_IP_RANGES"]
+  }
+{{if .Private}}  subnetwork {
+    name                    = local.public_subnetwork.self_link
     source_ip_ranges_to_nat = ["ALL_IP_RANGES"]
   }
-  log_config {
+{{end}}  log_config {
     filter = "TRANSLATIONS_ONLY"
     enable = true
   }
 }
 
+{{if .ExistingNetwork}}
+data "google_compute_network" "default" {
+  name    = "{{ .ExistingNetwork }}"
+  project = var.project
+}
+
+data "google_compute_subnetwork" "public" {
+  name    = "{{ .ExistingPublicSubnet }}"
+  region  = var.region
+  project = var.project
+}
+data "google_compute_subnetwork" "private" {
+  name    = "{{ .ExistingPrivateSubnet }}"
+  region  = var.region
+  project = var.project
+}
+{{else}}
 resource "google_compute_network" "default" {
   name                    = var.deployment
   project                 = var.project
@@ -159,26 +203,139 @@ resource "google_compute_subnetwork" "private" {
   network       = google_compute_network.default.self_link
   project       = var.project
 }
+{{end}}
+
+{{$previous := ""}}{{range .KeyedPeerNetworks}}
+// GCP only peers networks once both sides are peered, so the peered network must peer back with this one.
+// Peerings of a network can't be changed at the same time, so each waits for the one before it. The name
+// is kept short by a hash of the peered network, as names are limited to 63 characters
+resource "google_compute_network_peering" "peer-{{ .Key }}" {
+  name         = "${var.deployment}-peer-${substr(sha1("{{ .Value }}"), 0, 8)}"
+  network      = local.network.self_link
+  peer_network = "{{ .Value }}"
+{{if $previous}}  depends_on   = [{{ $previous }}]
+{{end}}}
+{{$previous = printf "google_compute_network_peering.peer-%s" .Key}}{{end}}
+{{if .NCCHub}}
+// The network is a VPC spoke of the hub, which exchanges the routes to its subnets with the hub's other spokes.
+// A hub in another project must accept the spoke, unless it does so automatically
+resource "google_network_connectivity_spoke" "hub" {
+  provider = google-beta
+  name     = "${var.deployment}-spoke"
+  location = "global"
+  hub      = "{{ .NCCHub }}"
+
+  linked_vpc_network {
+    uri                   = local.network.self_link
+    exclude_export_ranges = [{{range $i, $cidr := .NCCExcludeExportRanges}}{{if $i}}, {{end}}"{{ $cidr }}"{{end}}]
+  }
+}
+{{end}}
+locals {
+  network            = {{if .ExistingNetwork}}data.{{end}}google_compute_network.default
+  public_subnetwork  = {{if .ExistingNetwork}}data.{{end}}google_compute_subnetwork.public
+  private_subnetwork = {{if .ExistingNetwork}}data.{{end}}google_compute_subnetwork.private
+}
 
 resource "google_compute_firewall" "director" {
   name = "${var.deployment}-director"
   description = "Firewall for external access to BOSH director"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
+  target_tags = ["external"]
+{{if .Bastion}}  source_tags = ["bastion"]
+{{end}}  source_ranges = ["${var.source_access_ip}/32", {{if .Private}}var.public_cidr, var.private_cidr{{else}}"${google_compute_address.nat_ip.address}/32"{{end}}{{if .DirectorAllowIPs}}, {{ .DirectorAllowIPs }}{{end}}]
+  allow {
+    protocol = "tcp"
+    ports = ["6868", "25555"]
+  }
+}
+
+resource "google_compute_firewall" "director-ssh" {
+  name = "${var.deployment}-director-ssh"
+  description = "Firewall for SSH to BOSH director"
+  network     = local.network.self_link
   target_tags = ["external"]
-  source_ranges = ["${var.source_access_ip}/32", "${google_compute_address.nat_ip.address}/32"]
+{{if .Bastion}}  source_tags = ["bastion"]
+{{end}}  source_ranges = ["${var.source_access_ip}/32", {{if .Private}}var.public_cidr, var.private_cidr{{else}}"${google_compute_address.nat_ip.address}/32"{{end}}{{if .SSHAllowIPs}}, {{ .SSHAllowIPs }}{{end}}]
+  allow {
+    protocol = "tcp"
+    ports = ["22"]
+  }
+}
+
+{{if .Bastion}}
+// The bastion only accepts SSH, with its own key, to which the director's firewall admits it
+resource "google_compute_address" "bastion" {
+  name = "${var.deployment}-bastion-ip"
+}
+
+resource "google_compute_instance" "bastion" {
+  name         = "${var.deployment}-bastion"
+  machine_type = "e2-micro"
+  zone         = var.zone
+  tags         = ["bastion"]
+
+  boot_disk {
+    initialize_params {
+      image = "ubuntu-os-cloud/ubuntu-2204-lts"
+    }
+  }
+
+  network_interface {
+    subnetwork = local.public_subnetwork.self_link
+    access_config {
+      nat_ip = google_compute_address.bastion.address
+    }
+  }
+
+  shielded_instance_config {
+    enable_secure_boot = true
+  }
+
+  metadata = {
+    ssh-keys               = "ubuntu:{{ .BastionPublicKey }}"
+    block-project-ssh-keys = "true"
+    user-data              = <<EOF
+#cloud-config
+ssh_pwauth: false
+disable_root: true
+write_files:
+- path: /etc/ssh/sshd_config.d/99-control-tower.conf
+  content: |
+    PasswordAuthentication no
+    KbdInteractiveAuthentication no
+    PermitRootLogin no
+    AllowUsers ubuntu
+    AllowAgentForwarding no
+    X11Forwarding no
+    MaxAuthTries 3
+    ClientAliveInterval 300
+runcmd:
+- systemctl restart ssh
+EOF
+  }
+}
+
+resource "google_compute_firewall" "bastion" {
+  name = "${var.deployment}-bastion"
+  description = "Firewall for SSH to the bastion"
+  network     = local.network.self_link
+  target_tags = ["bastion"]
+  source_ranges = ["${var.source_access_ip}/32"{{if .BastionAllowIPs}}, {{ .BastionAllowIPs }}{{end}}]
   allow {
     protocol = "tcp"
-    ports = ["6868", "25555", "22"]
+    ports = ["22"]
   }
 }
+{{end}}
 
 resource "google_compute_firewall" "atc-http" {
   name = "${var.deployment}-atc-http"
   description = "Firewall for external access to concourse atc"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   target_tags = ["web"]
   source_tags = ["web", "worker", "external", "internal"]
-  source_ranges = [{{ .AllowIPs }}]
+  source_ranges = [{{ .WebAllowIPs }}]
   allow {
     protocol = "tcp"
     ports = ["80"]
@@ -188,9 +345,9 @@ resource "google_compute_firewall" "atc-http" {
 resource "google_compute_firewall" "atc-https" {
   name = "${var.deployment}-atc-https"
   description = "Firewall for external access to concourse atc"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   target_tags = ["web"]
-  source_ranges = ["${google_compute_address.nat_ip.address}/32", "${google_compute_address.atc_ip.address}/32", {{ .AllowIPs }}]
+  source_ranges = [{{if .Private}}var.public_cidr, var.private_cidr{{else}}"${google_compute_address.nat_ip.address}/32", "${local.atc_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   allow {
     protocol = "tcp"
     ports = ["443", "8443"]
@@ -200,7 +357,7 @@ resource "google_compute_firewall" "atc-https" {
 resource "google_compute_firewall" "from-public" {
   name = "${var.deployment}-public"
   description = "Control-Tower firewall from public VMs"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   target_tags = ["web", "external", "internal", "worker"]
   source_ranges = [var.public_cidr]
   allow {
@@ -224,7 +381,7 @@ resource "google_compute_firewall" "from-public" {
 resource "google_compute_firewall" "from-private" {
   name = "${var.deployment}-private"
   description = "Control-Tower firewall from private VMs"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   target_tags = ["web", "external", "internal", "worker"]
   source_ranges = [var.private_cidr]
   allow {
@@ -240,6 +397,13 @@ resource "google_compute_firewall" "from-private" {
     // Telegraf/InfluxDB
     ports = ["8086"]
   }
+{{ end }}
+{{if .PrometheusMetrics}}
+  allow {
+    protocol = "tcp"
+    // Concourse Prometheus metrics
+    ports = ["9391"]
+  }
 {{ end }}
   allow {
     protocol = "udp"
@@ -250,29 +414,135 @@ resource "google_compute_firewall" "from-private" {
   }
 }
 
+{{if .P2PVolumeStreaming}}
+resource "google_compute_firewall" "p2p-volume-streaming" {
+  name = "${var.deployment}-p2p"
+  description = "Firewall for workers streaming volumes directly to each other"
+  network     = local.network.self_link
+  target_tags = ["worker"]
+  source_ranges = [var.private_cidr]
+  allow {
+    protocol = "tcp"
+    // 7788 == baggageclaim
+    ports = ["7788"]
+  }
+}
+{{ end }}
+
+{{if .MetricsScrapeAllowIPs}}
+resource "google_compute_firewall" "metrics-scrape" {
+  name = "${var.deployment}-metrics-scrape"
+  description = "Firewall for scraping metrics over HTTPS with basic auth"
+  network     = local.network.self_link
+  target_tags = ["web"]
+  source_ranges = [{{range $i, $ip := .MetricsScrapeAllowIPs}}{{if $i}}, {{end}}"{{$ip}}"{{end}}]
+  allow {
+    protocol = "tcp"
+    ports = ["9392"]
+  }
+}
+{{ end }}
+
+{{if .ExternalWorkerAllowIPs}}
+resource "google_compute_firewall" "external-workers" {
+  name = "${var.deployment}-external-workers"
+  description = "Firewall for registering workers outside the deployment with the TSA"
+  network     = local.network.self_link
+  target_tags = ["web"]
+  source_ranges = [{{range $i, $ip := .ExternalWorkerAllowIPs}}{{if $i}}, {{end}}"{{$ip}}"{{end}}]
+  allow {
+    protocol = "tcp"
+    ports = ["2222"]
+  }
+}
+{{ end }}
+
+{{if .SoleTenantNodeType}}
+resource "google_compute_node_template" "sole-tenant" {
+  name      = "${var.deployment}-sole-tenant"
+  region    = var.region
+  node_type = "{{ .SoleTenantNodeType }}"
+}
+
+resource "google_compute_node_group" "sole-tenant" {
+  name          = "${var.deployment}-sole-tenant"
+  description   = "Nodes dedicated to the deployment's web VMs or workers"
+  zone          = var.zone
+  initial_size  = {{ .SoleTenantNodeCount }}
+  node_template = google_compute_node_template.sole-tenant.id
+}
+{{end}}
+
+{{if .RestrictWorkerEgress}}
+resource "google_compute_firewall" "worker-egress-deny" {
+  name = "${var.deployment}-worker-egress-deny"
+  description = "Firewall denying egress from workers that isn't allowed by a higher priority rule"
+  network     = local.network.self_link
+  direction   = "EGRESS"
+  priority    = 65000
+  target_tags = ["restricted-egress"]
+  destination_ranges = ["0.0.0.0/0"]
+  deny {
+    protocol = "all"
+  }
+}
+
+resource "google_compute_firewall" "worker-egress-internal" {
+  name = "${var.deployment}-worker-egress-internal"
+  description = "Firewall for workers reaching the director, web VMs and each other"
+  network     = local.network.self_link
+  direction   = "EGRESS"
+  target_tags = ["restricted-egress"]
+  destination_ranges = [var.public_cidr, var.private_cidr]
+  allow {
+    protocol = "all"
+  }
+}
+{{range $i, $rule := .WorkerEgressAllow}}
+resource "google_compute_firewall" "worker-egress-allow-{{$i}}" {
+  name = "${var.deployment}-worker-egress-allow-{{$i}}"
+  description = "Firewall for workers reaching {{$rule.CIDR}} on {{$rule.Protocol}} port {{$rule.Ports}}"
+  network     = local.network.self_link
+  direction   = "EGRESS"
+  target_tags = ["restricted-egress"]
+  destination_ranges = ["{{$rule.CIDR}}"]
+  allow {
+    protocol = "{{$rule.Protocol}}"
+    ports = ["{{$rule.Ports}}"]
+  }
+}
+{{end}}
+{{ end }}
+
 resource "google_compute_firewall" "atc-services" {
   name = "${var.deployment}-atc-services"
   description = "Firewall for external access to concourse atc"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   target_tags = ["web"]
-  source_ranges = ["${google_compute_address.nat_ip.address}/32", "${google_compute_address.atc_ip.address}/32", {{ .AllowIPs }}]
+  source_ranges = [{{if .Private}}var.public_cidr, var.private_cidr{{else}}"${google_compute_address.nat_ip.address}/32", "${local.atc_ip}/32"{{end}}, {{ .WebAllowIPs }}]
   allow {
     protocol = "tcp"
     ports = ["8844"]
   }
+}
 {{if .MetricsEnabled}}
+resource "google_compute_firewall" "grafana" {
+  name = "${var.deployment}-grafana"
+  description = "Firewall for external access to Grafana"
+  network     = local.network.self_link
+  target_tags = ["web"]
+  source_ranges = [{{if .Private}}var.public_cidr, var.private_cidr{{else}}"${google_compute_address.nat_ip.address}/32", "${local.atc_ip}/32"{{end}}, {{ .MetricsAllowIPs }}]
   allow {
     protocol = "tcp"
-    // Grafana
     ports = ["3000"]
   }
-{{ end }}
 }
+{{ end }}
 
 resource "google_compute_firewall" "internal" {
   name        = "${var.deployment}-int"
   description = "BOSH CI Internal Traffic"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   source_tags = ["internal"]
   target_tags = ["internal"]
 
@@ -289,10 +559,11 @@ resource "google_compute_firewall" "internal" {
   }
 }
 
+{{if not .ExternalDBHost}}
 resource "google_compute_firewall" "sql" {
   name        = "${var.deployment}-sql"
   description = "BOSH CI External Traffic"
-  network     = google_compute_network.default.self_link
+  network     = local.network.self_link
   direction = "EGRESS"
   allow {
     protocol = "tcp"
@@ -300,6 +571,7 @@ resource "google_compute_firewall" "sql" {
   }
   destination_ranges = ["${google_sql_database_instance.director.first_ip_address}/32"]
 }
+{{end}}
 
 resource "google_service_account" "bosh" {
   account_id   = "${var.deployment}-bosh"
@@ -331,42 +603,155 @@ resource "google_project_iam_member" "self_update" {
   member  = "serviceAccount:${google_service_account.self_update.email}"
 }
 
+resource "google_service_account" "runtime" {
+  account_id   = "${var.deployment}-rt"
+  display_name = "runtime"
+}
+resource "google_service_account_key" "runtime" {
+  service_account_id = google_service_account.runtime.name
+  public_key_type = "TYPE_X509_PEM_FILE"
+}
+
+// runtime autoscales the workers, so it can only use the runtime file and the deploy lock in the config bucket,
+// and not config.json or the terraform state
+resource "google_project_iam_member" "runtime" {
+  project = var.project
+  role    = "roles/storage.objectAdmin"
+  member  = "serviceAccount:${google_service_account.runtime.email}"
+
+  condition {
+    title      = "runtime-files"
+    expression = "resource.name == \"projects/_/buckets/{{ .ConfigBucket }}/objects/runtime.json\" || resource.name == \"projects/_/buckets/{{ .ConfigBucket }}/objects/deploy.lock\""
+  }
+}
+
+{{if .ATCIPAdopted}}
+// The ATC takes the address given with --web-address-name, which is only read here so that destroying the
+// deployment leaves it be
+data "google_compute_address" "atc_ip" {
+  name = "{{ .WebAddressName }}"
+}
+
+locals {
+  atc_ip = data.google_compute_address.atc_ip.address
+}
+{{else if not .Private}}
 resource "google_compute_address" "atc_ip" {
   name = "${var.deployment}-atc-ip"
 }
 
+locals {
+  atc_ip = google_compute_address.atc_ip.address
+}
+{{end}}
+
+{{if gt .WebCount 1}}
+// Several web VMs sit in the private subnetwork behind a load balancer, which takes the place of the ATC's address
+{{if .WebLBIPAdopted}}
+data "google_compute_address" "web_lb" {
+  name = "{{ .WebAddressName }}"
+}
+{{else}}
+resource "google_compute_address" "web_lb" {
+  name = "${var.deployment}-web-lb-ip"
+}
+{{end}}
+
+// The target pool only sends traffic to web VMs whose ATC answers, so that one being updated or recreated is
+// taken out of rotation
+resource "google_compute_http_health_check" "web" {
+  name                = "${var.deployment}-web"
+  port                = 80
+  request_path        = "/api/v1/info"
+  check_interval_sec  = 10
+  timeout_sec         = 5
+  healthy_threshold   = 2
+  unhealthy_threshold = 3
+}
+
+// Legacy health checks come from Google's health checking ranges, which the other firewall rules don't allow
+resource "google_compute_firewall" "web-health-check" {
+  name          = "${var.deployment}-web-health-check"
+  network       = local.network.self_link
+  target_tags   = ["web"]
+  source_ranges = ["35.191.0.0/16", "209.85.152.0/22", "209.85.204.0/22"]
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+resource "google_compute_target_pool" "web" {
+  name          = "${var.deployment}-web"
+  health_checks = [google_compute_http_health_check.web.name]
+}
+
+resource "google_compute_forwarding_rule" "web" {
+  name        = "${var.deployment}-web"
+  target      = google_compute_target_pool.web.self_link
+  ip_address  = {{if .WebLBIPAdopted}}data.{{end}}google_compute_address.web_lb.address
+  ip_protocol = "TCP"
+}
+{{end}}
+
+{{if not .Private}}
 resource "google_compute_address" "director" {
   name = "${var.deployment}-director-ip"
 }
+{{end}}
 
 resource "google_compute_address" "nat_ip" {
   name = "${var.deployment}-nat-ip"
 }
 
+{{if not .ExternalDBHost}}
 resource "google_sql_database_instance" "director" {
-  name                = var.db_name
-  database_version    = "POSTGRES_9_6"
+  name                = {{if .DBRestoreInstance}}"{{.DBRestoreInstance}}"{{else}}var.db_name{{end}}
+  database_version    = "{{.DatabaseVersion}}"
   region              = var.region
-  deletion_protection = false
-
+  deletion_protection = {{.DBDeletionProtection}}
+{{if .DBKMSKey}}  encryption_key_name = "{{.DBKMSKey}}"
+{{end}}{{if .DBRestoreInstance}}
+  clone {
+    source_instance_name = "{{.DBRestoreSource}}"
+    point_in_time        = "{{.DBRestoreTime}}"
+  }
+{{end}}
   settings {
-    tier = var.db_tier
+    tier              = var.db_tier
+    availability_type = "{{if .DBHA}}REGIONAL{{else}}ZONAL{{end}}"
     user_labels = {
       deployment = var.deployment
     }
-
+{{range .DBParameters}}
+    database_flags {
+      name  = "{{.Name}}"
+      value = "{{.Value}}"
+    }
+{{end}}{{if or .DBHA .DBBackupRetention .DBBackupStartTime}}
+    backup_configuration {
+      enabled                        = true
+      point_in_time_recovery_enabled = true
+{{if .DBBackupStartTime}}      start_time                     = "{{.DBBackupStartTime}}"
+{{end}}{{if .DBBackupRetention}}
+      backup_retention_settings {
+        retained_backups = {{.DBBackupRetention}}
+        retention_unit   = "COUNT"
+      }
+{{end}}    }
+{{end}}
     ip_configuration {
       ipv4_enabled = "true"
-      authorized_networks {
+{{if not .Private}}      authorized_networks {
         name = "atc_conf"
-        value = "${google_compute_address.atc_ip.address}/32"
+        value = "${local.atc_ip}/32"
       }
 
       authorized_networks {
           name = "bosh"
           value = "${google_compute_address.director.address}/32"
       }
-
+{{end}}
       authorized_networks {
           name = "nat"
           value = "${google_compute_address.nat_ip.address}/32"
@@ -375,6 +760,7 @@ resource "google_sql_database_instance" "director" {
   }
 }
 
+{{if not .DBRestoreInstance}}
 resource "google_sql_database" "director" {
   name      = "udb"
   instance  = google_sql_database_instance.director.name
@@ -386,9 +772,72 @@ resource "google_sql_user" "director" {
   password = var.db_password
   deletion_policy = "ABANDON"
 }
+{{end}}
+{{if .DBReadReplica}}
+resource "google_sql_database_instance" "replica" {
+  name                 = "${google_sql_database_instance.director.name}-replica"
+  master_instance_name = google_sql_database_instance.director.name
+  database_version     = google_sql_database_instance.director.database_version
+  region               = var.region
+  deletion_protection  = false
+{{if .DBKMSKey}}  encryption_key_name  = "{{.DBKMSKey}}"
+{{end}}
+  settings {
+    tier              = var.db_tier
+    availability_type = "ZONAL"
+    user_labels = {
+      deployment = var.deployment
+    }
+{{range .DBParameters}}
+    database_flags {
+      name  = "{{.Name}}"
+      value = "{{.Value}}"
+    }
+{{end}}
+    ip_configuration {
+      ipv4_enabled = "true"
+{{if not .Private}}      authorized_networks {
+        name = "atc_conf"
+        value = "${local.atc_ip}/32"
+      }
+
+      authorized_networks {
+          name = "bosh"
+          value = "${google_compute_address.director.address}/32"
+      }
+{{end}}
+      authorized_networks {
+          name = "nat"
+          value = "${google_compute_address.nat_ip.address}/32"
+      }
+    }
+  }
+}
+{{end}}
+{{range $i, $instance := .DBArchivedInstances}}
+# {{$instance}} was replaced by restore-db, and is kept as it was until the deployment is destroyed
+resource "google_sql_database_instance" "archived_{{$i}}" {
+  name             = "{{$instance}}"
+  database_version = "{{$.DatabaseVersion}}"
+  region           = var.region
+
+  settings {
+    tier = var.db_tier
+  }
+
+  lifecycle {
+    ignore_changes = all
+  }
+}
+{{end}}
+{{end}}
+
+output "bastion_public_ip" {
+value = {{if .Bastion}}google_compute_address.bastion.address{{else}}""{{end}}
+}
 
 output "network" {
-value = google_compute_network.default.name
+value = local.network.name
 }
 
 output "director_firewall_name" {
@@ -396,23 +845,31 @@ value = google_compute_firewall.director.name
 }
 
 output "private_subnetwork_name" {
-value = google_compute_subnetwork.private.name
+value = local.private_subnetwork.name
 }
 
 output "public_subnetwork_name" {
-value = google_compute_subnetwork.public.name
+value = local.public_subnetwork.name
 }
 
 output "private_subnetwork_internal_gw" {
-value = google_compute_subnetwork.private.gateway_address
+value = local.private_subnetwork.gateway_address
 }
 
 output "public_subnetwork_internal_gw" {
-value = google_compute_subnetwork.public.gateway_address
+value = local.public_subnetwork.gateway_address
 }
 
 output "atc_public_ip" {
-value = google_compute_address.atc_ip.address
+value = {{if .Private}}cidrhost(var.public_cidr, 7){{else if gt .WebCount 1}}{{if .WebLBIPAdopted}}data.{{end}}google_compute_address.web_lb.address{{else}}local.atc_ip{{end}}
+}
+
+output "web_target_pool" {
+value = {{if gt .WebCount 1}}google_compute_target_pool.web.name{{else}}""{{end}}
+}
+
+output "sole_tenant_node_group" {
+value = {{if .SoleTenantNodeType}}google_compute_node_group.sole-tenant.name{{else}}""{{end}}
 }
 
 output "director_account_creds" {
@@ -420,21 +877,30 @@ output "director_account_creds" {
   sensitive = true
 }
 
+output "runtime_account_creds" {
+  value = base64decode(google_service_account_key.runtime.private_key)
+  sensitive = true
+}
+
 output "self_update_account_creds" {
   value = base64decode(google_service_account_key.self_update.private_key)
   sensitive = true
 }
 
 output "director_public_ip" {
-  value = google_compute_address.director.address
+  value = {{if .Private}}cidrhost(var.public_cidr, 6){{else}}google_compute_address.director.address{{end}}
 }
 
 output "bosh_db_address" {
-  value = google_sql_database_instance.director.first_ip_address
+  value = {{if .ExternalDBHost}}"{{.ExternalDBHost}}"{{else}}google_sql_database_instance.director.first_ip_address{{end}}
+}
+
+output "db_replica_address" {
+  value = {{if .DBReadReplica}}google_sql_database_instance.replica.first_ip_address{{else}}""{{end}}
 }
 
 output "db_name" {
-  value = google_sql_database_instance.director.name
+  value = {{if .ExternalDBHost}}var.db_name{{else}}google_sql_database_instance.director.name{{end}}
 }
 
 output "nat_gateway_ip" {
@@ -442,5 +908,5 @@ output "nat_gateway_ip" {
 }
 
 output "server_ca_cert" {
-  value = google_sql_database_instance.director.server_ca_cert.0.cert
+  value = {{if .ExternalDBHost}}""{{else}}google_sql_database_instance.director.server_ca_cert.0.cert{{end}}
 }
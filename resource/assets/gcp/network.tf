@@ -0,0 +1,147 @@
+// Network and firewall module matching the layout control-tower deploys Concourse into, for placing
+// other systems on the same standard network. Generated by `control-tower generate-terraform --network-only`.
+
+variable "project" {
+  type = string
+}
+
+variable "region" {
+  type = string
+}
+
+variable "deployment" {
+  type = string
+}
+
+variable "namespace" {
+  type = string
+}
+
+variable "public_cidr" {
+  type    = string
+  default = "10.0.0.0/24"
+}
+
+variable "private_cidr" {
+  type    = string
+  default = "10.0.1.0/24"
+}
+
+variable "source_access_ip" {
+  type        = string
+  description = "CIDR range allowed to reach SSH on instances tagged external"
+}
+
+terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+      version = "~> 3.90.0"
+    }
+  }
+}
+
+provider "google" {
+  project = var.project
+  region  = var.region
+}
+
+resource "google_compute_network" "default" {
+  name                    = var.deployment
+  project                 = var.project
+  auto_create_subnetworks = "false"
+}
+
+resource "google_compute_subnetwork" "public" {
+  name          = "${var.deployment}-${var.namespace}-public"
+  ip_cidr_range = var.public_cidr
+  network       = google_compute_network.default.self_link
+  project       = var.project
+}
+
+resource "google_compute_subnetwork" "private" {
+  name          = "${var.deployment}-${var.namespace}-private"
+  ip_cidr_range = var.private_cidr
+  network       = google_compute_network.default.self_link
+  project       = var.project
+}
+
+resource "google_compute_address" "nat_ip" {
+  name = "${var.deployment}-nat-ip"
+}
+
+resource "google_compute_router" "nat-router" {
+  name    = "${var.deployment}-router"
+  region  = var.region
+  network = google_compute_network.default.self_link
+  bgp {
+    asn = 64514
+  }
+}
+
+resource "google_compute_router_nat" "worker-nat" {
+  name                               = "${var.deployment}-worker-nat"
+  project                            = var.project
+  region                             = var.region
+  router                             = google_compute_router.nat-router.name
+  nat_ips                            = google_compute_address.nat_ip.*.self_link
+  nat_ip_allocate_option             = "MANUAL_ONLY"
+  source_subnetwork_ip_ranges_to_nat = "LIST_OF_SUBNETWORKS"
+  subnetwork {
+    name                    = google_compute_subnetwork.private.self_link
+    source_ip_ranges_to_nat = ["ALL_IP_RANGES"]
+  }
+  log_config {
+    filter = "TRANSLATIONS_ONLY"
+    enable = true
+  }
+}
+
+resource "google_compute_firewall" "internal" {
+  name        = "${var.deployment}-int"
+  description = "Internal Traffic"
+  network     = google_compute_network.default.self_link
+  source_tags = ["internal"]
+  target_tags = ["internal"]
+
+  allow {
+    protocol = "tcp"
+  }
+
+  allow {
+    protocol = "udp"
+  }
+
+  allow {
+    protocol = "icmp"
+  }
+}
+
+resource "google_compute_firewall" "ssh" {
+  name          = "${var.deployment}-ssh"
+  description   = "SSH from the access range"
+  network       = google_compute_network.default.self_link
+  source_ranges = [var.source_access_ip]
+  target_tags   = ["external"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+
+output "network" {
+  value = google_compute_network.default.name
+}
+
+output "public_subnetwork_name" {
+  value = google_compute_subnetwork.public.name
+}
+
+output "private_subnetwork_name" {
+  value = google_compute_subnetwork.private.name
+}
+
+output "nat_gateway_ip" {
+  value = google_compute_address.nat_ip.address
+}
@@ -59,6 +59,14 @@ var (
 	//go:embed assets/gcp/infrastructure.tf
 	GCPTerraformConfig string
 
+	// AWSNetworkTerraformConfig holds a standalone terraform module of the AWS network and firewall
+	//go:embed assets/aws/network.tf
+	AWSNetworkTerraformConfig string
+
+	// GCPNetworkTerraformConfig holds a standalone terraform module of the GCP network and firewall
+	//go:embed assets/gcp/network.tf
+	GCPNetworkTerraformConfig string
+
 	// AWSReleaseVersions carries all versions of releases
 	AWSReleaseVersions = string(opsassets.AwsConcourseVersions)
 
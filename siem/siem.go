@@ -0,0 +1,200 @@
+package siem
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timeout = 10 * time.Second
+
+// Endpoint is a parsed --siem-endpoint
+type Endpoint struct {
+	URL string
+	// Host and Port are only set for syslog endpoints
+	Host string
+	Port int
+	// Transport is tcp or udp for syslog endpoints, and https for HTTPS collectors
+	Transport string
+	TLS       bool
+}
+
+// IsSyslog is true when events are sent as CEF over syslog rather than posted as JSON
+func (e Endpoint) IsSyslog() bool {
+	return e.Transport != "https"
+}
+
+// ParseEndpoint accepts syslog://host:port (TCP), syslog+udp://host:port, syslog+tls://host:port
+// or an https:// collector URL
+func ParseEndpoint(endpoint string) (Endpoint, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("siem endpoint %s is invalid: [%v]", endpoint, err)
+	}
+	e := Endpoint{URL: endpoint}
+	switch u.Scheme {
+	case "https":
+		e.Transport = "https"
+		e.TLS = true
+		if u.Host == "" {
+			return Endpoint{}, fmt.Errorf("siem endpoint %s is invalid: must include a host", endpoint)
+		}
+		return e, nil
+	case "syslog":
+		e.Transport = "tcp"
+	case "syslog+udp":
+		e.Transport = "udp"
+	case "syslog+tls":
+		e.Transport = "tcp"
+		e.TLS = true
+	default:
+		return Endpoint{}, fmt.Errorf("siem endpoint %s is invalid: scheme must be one of syslog, syslog+udp, syslog+tls or https", endpoint)
+	}
+	e.Host = u.Hostname()
+	e.Port, err = strconv.Atoi(u.Port())
+	if e.Host == "" || err != nil {
+		return Endpoint{}, fmt.Errorf("siem endpoint %s is invalid: must be of the form %s://host:port", endpoint, u.Scheme)
+	}
+	return e, nil
+}
+
+// Event is an audit record of a control-tower command run against a deployment
+type Event struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Project string    `json:"project"`
+	IAAS    string    `json:"iaas"`
+	Region  string    `json:"region"`
+	User    string    `json:"user"`
+	Version string    `json:"control_tower_version"`
+	Outcome string    `json:"outcome"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// NewEvent returns an Event for the current user, with the outcome taken from err
+func NewEvent(action, project, iaas, region, version string, err error) Event {
+	e := Event{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Project: project,
+		IAAS:    iaas,
+		Region:  region,
+		User:    currentUser(),
+		Version: version,
+		Outcome: "success",
+	}
+	if err != nil {
+		e.Outcome = "failure"
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// Send delivers the event to the endpoint
+func Send(endpoint string, event Event) error {
+	e, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	if !e.IsSyslog() {
+		return post(e.URL, event)
+	}
+	return sendSyslog(e, event)
+}
+
+func post(collectorURL string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(collectorURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("siem collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func sendSyslog(e Endpoint, event Event) error {
+	address := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	var conn net.Conn
+	var err error
+	if e.TLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", address, &tls.Config{ServerName: e.Host})
+	} else {
+		conn, err = net.DialTimeout(e.Transport, address, timeout)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	msg := SyslogMessage(event)
+	if e.Transport == "tcp" {
+		// Octet-counted framing from RFC 6587
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err = conn.Write([]byte(msg))
+	return err
+}
+
+// SyslogMessage formats the event as an RFC 5424 message with a CEF payload
+func SyslogMessage(event Event) string {
+	// authpriv facility, notice severity on success and warning on failure
+	priority, severity := 10*8+5, 3
+	if event.Outcome != "success" {
+		priority, severity = 10*8+4, 7
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	extension := []string{
+		"rt=" + strconv.FormatInt(event.Time.UnixNano()/int64(time.Millisecond), 10),
+		"suser=" + cefValue(event.User),
+		"outcome=" + cefValue(event.Outcome),
+		"cs1Label=project",
+		"cs1=" + cefValue(event.Project),
+		"cs2Label=iaas",
+		"cs2=" + cefValue(event.IAAS),
+		"cs3Label=region",
+		"cs3=" + cefValue(event.Region),
+	}
+	if event.Error != "" {
+		extension = append(extension, "msg="+cefValue(event.Error))
+	}
+
+	cef := fmt.Sprintf("CEF:0|EngineerBetter|control-tower|%s|%s|control-tower %s|%d|%s",
+		cefHeader(event.Version), cefHeader(event.Action), cefHeader(event.Action), severity, strings.Join(extension, " "))
+	return fmt.Sprintf("<%d>1 %s %s control-tower - - - %s\n", priority, event.Time.UTC().Format(time.RFC3339), hostname, cef)
+}
+
+func cefHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`).Replace(s)
+}
+
+func cefValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
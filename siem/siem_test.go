@@ -0,0 +1,139 @@
+package siem
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     Endpoint
+		wantErr  bool
+	}{
+		{
+			name:     "syslog over TCP",
+			endpoint: "syslog://siem.example.com:514",
+			want:     Endpoint{URL: "syslog://siem.example.com:514", Host: "siem.example.com", Port: 514, Transport: "tcp"},
+		},
+		{
+			name:     "syslog over UDP",
+			endpoint: "syslog+udp://10.0.0.5:514",
+			want:     Endpoint{URL: "syslog+udp://10.0.0.5:514", Host: "10.0.0.5", Port: 514, Transport: "udp"},
+		},
+		{
+			name:     "syslog over TLS",
+			endpoint: "syslog+tls://siem.example.com:6514",
+			want:     Endpoint{URL: "syslog+tls://siem.example.com:6514", Host: "siem.example.com", Port: 6514, Transport: "tcp", TLS: true},
+		},
+		{
+			name:     "HTTPS collector",
+			endpoint: "https://collector.example.com/services/collector",
+			want:     Endpoint{URL: "https://collector.example.com/services/collector", Transport: "https", TLS: true},
+		},
+		{
+			name:     "syslog without a port",
+			endpoint: "syslog://siem.example.com",
+			wantErr:  true,
+		},
+		{
+			name:     "plain HTTP",
+			endpoint: "http://collector.example.com",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEndpoint(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEndpoint() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogMessage(t *testing.T) {
+	event := NewEvent("deploy", "my|project", "AWS", "eu-west-1", "1.2.3", errors.New("bad=thing\nhappened"))
+
+	got := SyslogMessage(event)
+
+	for _, want := range []string{
+		"<84>1 ",
+		" control-tower - - - CEF:0|EngineerBetter|control-tower|1.2.3|deploy|control-tower deploy|7|",
+		`cs1=my|project`,
+		`outcome=failure`,
+		`msg=bad\=thing\nhappened`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q to contain %q", got, want)
+		}
+	}
+	if strings.Count(got, "\n") != 1 {
+		t.Errorf("expected a single line message, got %q", got)
+	}
+}
+
+func TestSend_Syslog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	err = Send("syslog://"+listener.Addr().String(), NewEvent("destroy", "my-project", "GCP", "europe-west1", "1.2.3", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if !strings.Contains(got, "|destroy|") || !strings.Contains(got, "outcome=success") {
+		t.Errorf("unexpected message %q", got)
+	}
+	length := strings.SplitN(got, " ", 2)[0]
+	if length == "" || strings.HasPrefix(got, "<") {
+		t.Errorf("expected an octet-counted frame, got %q", got)
+	}
+}
+
+func TestSend_HTTPS(t *testing.T) {
+	var got Event
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	http.DefaultTransport = server.Client().Transport
+
+	err := Send(server.URL, NewEvent("deploy", "my-project", "AWS", "eu-west-1", "1.2.3", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Action != "deploy" || got.Project != "my-project" || got.Outcome != "success" {
+		t.Errorf("unexpected event %+v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package soak
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+// DefaultInterval is how often the ATC is polled for builds during an upgrade
+const DefaultInterval = time.Minute
+
+// DefaultMinBuilds is how many builds must finish during an upgrade before their error rate is compared with the baseline
+const DefaultMinBuilds = 10
+
+// Rate counts the builds that finished and how many of them errored
+type Rate struct {
+	Errored  int
+	Finished int
+}
+
+// Percent returns the rate as a percentage, or 0 when no builds finished
+func (r Rate) Percent() float64 {
+	if r.Finished == 0 {
+		return 0
+	}
+	return float64(r.Errored) * 100 / float64(r.Finished)
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%.1f%% (%d of %d builds)", r.Percent(), r.Errored, r.Finished)
+}
+
+// ErrorRate counts the builds that finished at or after since, and how many of them errored. Failed
+// builds are left out of the errored count, since a failing test says nothing about the workers
+func ErrorRate(builds []fly.Build, since time.Time) Rate {
+	var r Rate
+	for _, b := range builds {
+		if b.EndTime == 0 || time.Unix(b.EndTime, 0).Before(since) {
+			continue
+		}
+		switch b.Status {
+		case "errored":
+			r.Errored++
+			r.Finished++
+		case "succeeded", "failed", "aborted":
+			r.Finished++
+		}
+	}
+	return r
+}
+
+// Monitor watches the rate of errored builds while workers are upgraded, and cancels the upgrade
+// if it rises too far above the rate before the upgrade began
+type Monitor struct {
+	// Builds lists the most recent builds
+	Builds func() ([]fly.Build, error)
+	// Cancel halts the upgrade
+	Cancel func() error
+	// Baseline is the error rate before the upgrade began
+	Baseline Rate
+	// MaxIncrease is how many percentage points the error rate can rise above Baseline
+	MaxIncrease float64
+	// MinBuilds is how many builds must finish before the rate is compared
+	MinBuilds int
+	// Interval is how often Builds is polled
+	Interval time.Duration
+	// Stderr receives warnings when builds can't be listed
+	Stderr io.Writer
+}
+
+// Result describes why a Monitor stopped
+type Result struct {
+	Halted bool
+	Rate   Rate
+	Err    error
+}
+
+// Watch polls for builds that finished after since until stop is closed, cancelling the upgrade
+// and returning as soon as the error rate exceeds the allowed increase
+func (m Monitor) Watch(since time.Time, stop <-chan struct{}) Result {
+	if m.Interval <= 0 {
+		m.Interval = DefaultInterval
+	}
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	var latest Rate
+	for {
+		select {
+		case <-stop:
+			return Result{Rate: latest}
+		case <-ticker.C:
+		}
+
+		builds, err := m.Builds()
+		if err != nil {
+			// The web node may be restarting, so the next poll is tried regardless
+			if m.Stderr != nil {
+				fmt.Fprintf(m.Stderr, "WARNING: unable to list builds while soaking upgraded workers: [%v]\n", err)
+			}
+			continue
+		}
+		latest = ErrorRate(builds, since)
+		if !m.exceeded(latest) {
+			continue
+		}
+		return Result{Halted: true, Rate: latest, Err: m.Cancel()}
+	}
+}
+
+func (m Monitor) exceeded(r Rate) bool {
+	if r.Finished < m.MinBuilds {
+		return false
+	}
+	return r.Percent()-m.Baseline.Percent() > m.MaxIncrease
+}
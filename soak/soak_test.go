@@ -0,0 +1,93 @@
+package soak
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/control-tower/fly"
+)
+
+func builds(since time.Time, statuses ...string) []fly.Build {
+	var bs []fly.Build
+	for i, status := range statuses {
+		bs = append(bs, fly.Build{ID: i, Status: status, EndTime: since.Add(time.Second).Unix()})
+	}
+	return bs
+}
+
+func TestErrorRate(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	bs := append(builds(since, "succeeded", "errored", "failed", "started"),
+		fly.Build{ID: 9, Status: "errored", EndTime: since.Add(-time.Minute).Unix()})
+
+	got := ErrorRate(bs, since)
+
+	if got != (Rate{Errored: 1, Finished: 3}) {
+		t.Errorf("ErrorRate() = %+v", got)
+	}
+	if got.String() != "33.3% (1 of 3 builds)" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
+
+func TestMonitor_Watch(t *testing.T) {
+	since := time.Now()
+	tests := []struct {
+		name       string
+		builds     []fly.Build
+		buildsErr  error
+		cancelErr  error
+		wantHalted bool
+		wantErr    bool
+	}{
+		{
+			name:   "error rate within the allowed increase",
+			builds: builds(since, "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "errored"),
+		},
+		{
+			name:       "error rate above the allowed increase",
+			builds:     builds(since, "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "succeeded", "errored", "errored"),
+			wantHalted: true,
+		},
+		{
+			name:   "too few builds to judge",
+			builds: builds(since, "errored", "errored", "errored"),
+		},
+		{
+			name:      "builds can't be listed",
+			buildsErr: errors.New("could not reach the Concourse server"),
+		},
+		{
+			name:       "upgrade can't be cancelled",
+			builds:     builds(since, "errored", "errored", "errored", "errored", "errored", "errored", "errored", "errored", "errored", "errored"),
+			cancelErr:  errors.New("director unreachable"),
+			wantHalted: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelled := false
+			m := Monitor{
+				Builds:      func() ([]fly.Build, error) { return tt.builds, tt.buildsErr },
+				Cancel:      func() error { cancelled = true; return tt.cancelErr },
+				Baseline:    Rate{Errored: 1, Finished: 20},
+				MaxIncrease: 5,
+				MinBuilds:   DefaultMinBuilds,
+				Interval:    time.Millisecond,
+			}
+			stop := make(chan struct{})
+			time.AfterFunc(50*time.Millisecond, func() { close(stop) })
+
+			got := m.Watch(since, stop)
+
+			if got.Halted != tt.wantHalted || cancelled != tt.wantHalted {
+				t.Errorf("Watch() halted = %v, cancelled = %v, want %v", got.Halted, cancelled, tt.wantHalted)
+			}
+			if (got.Err != nil) != tt.wantErr {
+				t.Errorf("Watch() error = %v, wantErr %v", got.Err, tt.wantErr)
+			}
+		})
+	}
+}
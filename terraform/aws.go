@@ -6,6 +6,7 @@ import (
 	"errors"
 	"reflect"
 
+	"github.com/EngineerBetter/control-tower/config"
 	"github.com/EngineerBetter/control-tower/util"
 	"github.com/asaskevich/govalidator"
 )
@@ -18,26 +19,176 @@ type AWSInputVars struct {
 	Deployment             string
 	HostedZoneID           string
 	HostedZoneRecordPrefix string
-	MetricsEnabled         bool
-	Namespace              string
-	NetworkCIDR            string
-	PrivateCIDR            string
-	Project                string
-	PublicCIDR             string
-	PublicKey              string
-	RDSDefaultDatabaseName string
-	RDSInstanceClass       string
-	RDSPassword            string
-	RDSUsername            string
-	RDSDiskEncryption      bool
-	RDS1CIDR               string
-	RDS2CIDR               string
-	Region                 string
-	SourceAccessIP         string
-	TFStatePath            string
+	// AdditionalRecordPrefixes name further records in the hosted zone for any additional domains
+	AdditionalRecordPrefixes []string
+	InternalLB               bool
+	InternalLBAllowIPs       string
+	InternalLBTLSCert        string
+	InternalLBTLSKey         string
+	// InternalLBHealthCheck* are left at the AWS defaults when zero
+	InternalLBHealthCheckPath     string
+	InternalLBHealthCheckInterval int
+	InternalLBHealthyThreshold    int
+	InternalLBUnhealthyThreshold  int
+	MetricsEnabled                bool
+	MetricsScrapeAllowIPs         []string
+	ExternalWorkerAllowIPs        []string
+	WebCount                      int
+	Namespace                     string
+	NetworkCIDR                   string
+	PrivateCIDR                   string
+	Project                       string
+	PrometheusMetrics             bool
+	PublicCIDR                    string
+	PublicKey                     string
+	RDSDefaultDatabaseName        string
+	RDSInstanceClass              string
+	RDSPassword                   string
+	RDSUsername                   string
+	RDSDiskEncryption             bool
+	RDS1CIDR                      string
+	RDS2CIDR                      string
+	Region                        string
+	ManagedPrometheus             bool
+	ManagedPrometheusRegion       string
+	ManagedPrometheusWorkspaceID  string
+	SecretsManager                bool
+	SecretsManagerPathPrefix      string
+	SourceAccessIP                string
+	SSM                           bool
+	SSMKMSKeyARN                  string
+	SSMPathPrefix                 string
+	TFStatePath                   string
+	// RestrictWorkerEgress replaces open egress from the VMs security group with the deployment's network,
+	// S3 for the blobstore and WorkerEgressAllow
+	RestrictWorkerEgress bool
+	WorkerEgressAllow    []config.EgressRule
+	// WorkerZones are given private subnets of their own, as AWS subnets can't span zones
+	WorkerZones []config.WorkerZone
+	// ExistingVPCID places the deployment into a VPC managed elsewhere, in ExistingPublicSubnetID and
+	// ExistingPrivateSubnetID, whose NAT gateway reaches the internet from ExistingNATIP
+	ExistingVPCID           string
+	ExistingPublicSubnetID  string
+	ExistingPrivateSubnetID string
+	ExistingDBSubnetIDs     []string
+	ExistingNATIP           string
+	// Private leaves out the Elastic IPs of the director, ATC and web load balancer, whose private addresses
+	// are output in their place. The NAT gateway moves to NATCIDR, so that the public subnet can route through it
+	Private bool
+	NATCIDR string
+	// Bastion adds a host that the director is reached through, by SSH with BastionPublicKey's pair from
+	// SourceAccessIP and BastionAllowIPs
+	Bastion          bool
+	BastionAllowIPs  string
+	BastionPublicKey string
+	// VPCEndpoints adds a gateway endpoint for S3 and interface endpoints for EC2, Secrets Manager and CloudWatch,
+	// whose private DNS names take the place of the public ones within the VPC
+	VPCEndpoints bool
+	// NATTopology replaces the NAT gateway with a NAT instance, or adds a NAT gateway to each of WorkerZones
+	NATTopology string
+	// IPv6 gives the VPC and its subnets IPv6 ranges, and the web load balancer IPv6 addresses with AAAA
+	// records, which it is reached at from AllowIPv6s
+	IPv6       bool
+	AllowIPv6s string
+	// PeerVPCIDs are peered with the VPC, with routes to them from its route tables and, when worker egress
+	// is restricted, egress to them from the VMs security group
+	PeerVPCIDs []string
+	// TransitGatewayID attaches the VPC to a transit gateway, with routes to TransitGatewayRoutes through it
+	// from its route tables. The attachment uses TransitGatewayRouteTableID in place of the transit gateway's
+	// default route table, and doesn't propagate the VPC's range into it with NoTransitGatewayPropagation
+	TransitGatewayID            string
+	TransitGatewayRoutes        []string
+	TransitGatewayRouteTableID  string
+	NoTransitGatewayPropagation bool
+	// WebAllowIPs, DirectorAllowIPs, SSHAllowIPs and MetricsAllowIPs are let in to the web UI, the director's
+	// APIs, SSH to the director and Grafana, alongside the deployment's own addresses
+	WebAllowIPs      string
+	DirectorAllowIPs string
+	SSHAllowIPs      string
+	MetricsAllowIPs  string
+	// WebEIP is the address of an existing Elastic IP that the web endpoint takes in place of one of its own
+	WebEIP string
+	// ExternalDBHost and ExternalDBPort replace the RDS instance when the deployment has an external database
+	ExternalDBHost string
+	ExternalDBPort string
+	// DBMinACU and DBMaxACU replace the RDS instance with an Aurora Serverless v2 cluster when set
+	DBMinACU float64
+	DBMaxACU float64
+	// DBEngineVersion is a major version of the RDS instance, or a full version of the Aurora cluster
+	DBEngineVersion string
+	// DBStorage is the GB of storage of the RDS instance, which is left at the size it was created with
+	// while it is 0. RDS autoscales it up to DBMaxStorage when that is set
+	DBStorage     int
+	DBMaxStorage  int
+	DBStorageType string
+	DBIOPS        int
+	// DBBackupRetention and DBBackupWindow are left to RDS while they are empty
+	DBBackupRetention    int
+	DBBackupWindow       string
+	DBDeletionProtection bool
+	// DBMultiAZ gives the RDS instance a synchronous standby in a second zone
+	DBMultiAZ bool
+	// DBReadReplica adds a replica of the RDS instance, or a reader to the Aurora cluster
+	DBReadReplica bool
+	// DBKMSKeyARN encrypts the database with a customer managed key, in place of RDSDiskEncryption's
+	DBKMSKeyARN string
+	// DBIAMUser is the database role the web VMs may log in as with IAM authentication tokens, when they
+	// authenticate to the database with IAM
+	DBIAMUser string
+	// DBBlueGreenUpdate makes changes to the RDS instance in a Blue/Green deployment, switching over to the
+	// changed copy once it has caught up
+	DBBlueGreenUpdate bool
+	// DBParameters are set in a parameter group of DBParameterGroupFamily when there are any
+	DBParameters           []DBParameter
+	DBParameterGroupFamily string
+	// DBRestoreSource and DBRestoreTime are the instance and time that the DBRestoreInstance was restored from,
+	// when restore-db has replaced the database. DBArchivedInstances are the instances it replaced
+	DBRestoreSource     string
+	DBRestoreInstance   string
+	DBRestoreTime       string
+	DBArchivedInstances []string
+	// DirectorDBArchive is the archived instance, such as archived_0, that the director keeps its database on
+	DirectorDBArchive string
+	// DBCACertIdentifier is the RDS CA the database is rotated onto, or RDS's default when it's empty
+	DBCACertIdentifier string
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
+// WebLBPorts are forwarded to every web VM by the load balancer in front of them, when there is more than one
+func (v *AWSInputVars) WebLBPorts() []int {
+	return []int{80, 443, 2222, 8443, 8844}
+}
+
+// KeyedPeerVPCIDs are PeerVPCIDs keyed by the ID of each VPC
+func (v *AWSInputVars) KeyedPeerVPCIDs() []KeyedValue {
+	var keyed []KeyedValue
+	for _, id := range v.PeerVPCIDs {
+		keyed = append(keyed, KeyedValue{Key: resourceKey(id), Value: id})
+	}
+	return keyed
+}
+
+// KeyedTransitGatewayRoutes are TransitGatewayRoutes keyed by each CIDR
+func (v *AWSInputVars) KeyedTransitGatewayRoutes() []KeyedValue {
+	var keyed []KeyedValue
+	for _, cidr := range v.TransitGatewayRoutes {
+		keyed = append(keyed, KeyedValue{Key: resourceKey(cidr), Value: cidr})
+	}
+	return keyed
+}
+
+// ATCEIPAdopted is true when WebEIP takes the place of the ATC's Elastic IP, as the web endpoint of a
+// deployment with a single web VM
+func (v *AWSInputVars) ATCEIPAdopted() bool {
+	return v.WebEIP != "" && v.WebCount <= 1
+}
+
+// WebLBEIPAdopted is true when WebEIP takes the place of the Elastic IP of the load balancer in front of
+// several web VMs
+func (v *AWSInputVars) WebLBEIPAdopted() bool {
+	return v.WebEIP != "" && v.WebCount > 1
+}
+
 func (v *AWSInputVars) ConfigureTerraform(terraformContents string) (string, error) {
 	terraformConfig, err := util.RenderTemplate("terraform", terraformContents, v)
 	if terraformConfig == nil {
@@ -55,6 +206,7 @@ type MetadataStringValue struct {
 type AWSOutputs struct {
 	ATCPublicIP               MetadataStringValue `json:"atc_public_ip" valid:"required"`
 	ATCSecurityGroupID        MetadataStringValue `json:"atc_security_group_id" valid:"required"`
+	BastionPublicIP           MetadataStringValue `json:"bastion_public_ip"`
 	BlobstoreBucket           MetadataStringValue `json:"blobstore_bucket" valid:"required"`
 	BlobstoreSecretAccessKey  MetadataStringValue `json:"blobstore_user_secret_access_key" valid:"required"`
 	BlobstoreUserAccessKeyID  MetadataStringValue `json:"blobstore_user_access_key_id" valid:"required"`
@@ -62,17 +214,25 @@ type AWSOutputs struct {
 	BoshDBPort                MetadataStringValue `json:"bosh_db_port" valid:"required"`
 	BoshSecretAccessKey       MetadataStringValue `json:"bosh_user_secret_access_key" valid:"required"`
 	BoshUserAccessKeyID       MetadataStringValue `json:"bosh_user_access_key_id" valid:"required"`
+	DBReplicaAddress          MetadataStringValue `json:"db_replica_address"`
+	DirectorDBAddress         MetadataStringValue `json:"director_db_address"`
 	DirectorKeyPair           MetadataStringValue `json:"director_key_pair" valid:"required"`
 	DirectorPublicIP          MetadataStringValue `json:"director_public_ip" valid:"required"`
 	DirectorSecurityGroupID   MetadataStringValue `json:"director_security_group_id" valid:"required"`
+	InternalLBDNSName         MetadataStringValue `json:"internal_lb_dns_name"`
 	NatGatewayIP              MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
 	PrivateSubnetID           MetadataStringValue `json:"private_subnet_id" valid:"required"`
 	PublicSubnetID            MetadataStringValue `json:"public_subnet_id" valid:"required"`
+	RuntimeSecretAccessKey    MetadataStringValue `json:"runtime_user_secret_access_key"`
+	RuntimeUserAccessKeyID    MetadataStringValue `json:"runtime_user_access_key_id"`
 	SelfUpdateSecretAccessKey MetadataStringValue `json:"self_update_user_secret_access_key" valid:"required"`
 	SelfUpdateUserAccessKeyID MetadataStringValue `json:"self_update_user_access_key_id" valid:"required"`
 	SourceAccessIP            MetadataStringValue `json:"source_access_ip"`
 	VMsSecurityGroupID        MetadataStringValue `json:"vms_security_group_id" valid:"required"`
 	VPCID                     MetadataStringValue `json:"vpc_id" valid:"required"`
+	WebInstanceProfile        MetadataStringValue `json:"web_instance_profile"`
+	WebTargetGroups           MetadataStringValue `json:"web_target_groups"`
+	WorkerSubnetIDs           MetadataStringValue `json:"worker_subnet_ids"`
 }
 
 // AssertValid returns an error if the struct contains any missing fields
@@ -3,8 +3,11 @@ package terraform_test
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/resource"
 	. "github.com/EngineerBetter/control-tower/terraform"
 )
 
@@ -149,3 +152,928 @@ func TestAWSMetadata_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestAWSInputVars_ConfigureTerraformInternalLB(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	withoutLB, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(withoutLB, `resource "aws_lb" "internal"`) {
+		t.Error("expected no internal load balancer by default")
+	}
+
+	withLB := base
+	withLB.InternalLB = true
+	withLB.InternalLBAllowIPs = `"10.1.0.0/16"`
+	withLB.InternalLBTLSCert = "a-cert"
+	withLB.InternalLBTLSKey = "a-key"
+	got, err := (&withLB).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_lb" "internal"`,
+		`resource "aws_acm_certificate" "internal_lb"`,
+		`certificate_arn   = aws_acm_certificate.internal_lb.arn`,
+		`cidr_blocks = ["10.1.0.0/16"]`,
+		`value = aws_lb.internal.dns_name`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "unhealthy_threshold") {
+		t.Error("expected health check thresholds to be left at the AWS defaults when unset")
+	}
+
+	withHealthCheck := withLB
+	withHealthCheck.InternalLBHealthCheckPath = "/api/v1/info"
+	withHealthCheck.InternalLBHealthCheckInterval = 10
+	withHealthCheck.InternalLBUnhealthyThreshold = 5
+	got, err = (&withHealthCheck).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`protocol            = "HTTPS"`,
+		`path                = "/api/v1/info"`,
+		`interval            = 10`,
+		`unhealthy_threshold = 5`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformSecretsManager(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	without, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(without, `resource "aws_iam_role" "web"`) || strings.Contains(without, "aws_iam_role.web.arn") {
+		t.Error("expected no web IAM role by default")
+	}
+
+	withSecretsManager := base
+	withSecretsManager.SecretsManager = true
+	withSecretsManager.SecretsManagerPathPrefix = "/concourse/"
+	got, err := (&withSecretsManager).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_iam_instance_profile" "web"`,
+		`secret:/concourse/*"`,
+		`"Resource": "${aws_iam_role.web.arn}"`,
+		`value = aws_iam_instance_profile.web.name`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformManagedPrometheus(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:                     `"1.2.3.4/32"`,
+		Deployment:                   "control-tower-test",
+		ManagedPrometheus:            true,
+		ManagedPrometheusRegion:      "eu-west-1",
+		ManagedPrometheusWorkspaceID: "ws-1234abcd",
+	}
+	got, err := inputVars.ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_iam_instance_profile" "web"`,
+		`"Action": "aps:RemoteWrite"`,
+		`arn:aws:aps:eu-west-1:${data.aws_caller_identity.current.account_id}:workspace/ws-1234abcd"`,
+		`value = aws_iam_instance_profile.web.name`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "web_secretsmanager") {
+		t.Error("expected no secrets manager policy")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformSSM(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", SSM: true, SSMPathPrefix: "/concourse/"}
+
+	got, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_iam_role_policy" "web_ssm"`,
+		`parameter/concourse/*"`,
+		`value = aws_iam_instance_profile.web.name`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "kms:Decrypt") {
+		t.Error("expected no KMS grant without a customer managed key")
+	}
+
+	withKey := base
+	withKey.SSMKMSKeyARN = "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	got, err = (&withKey).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"Resource": "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"`) {
+		t.Error("expected the web role to be able to decrypt with the chosen KMS key")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformRestrictWorkerEgress(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	open, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(open, `data "aws_prefix_list" "s3"`) {
+		t.Error("expected open worker egress by default")
+	}
+
+	restricted := base
+	restricted.RestrictWorkerEgress = true
+	restricted.WorkerEgressAllow = []config.EgressRule{
+		{CIDR: "10.0.0.0/8", Protocol: "tcp", FromPort: 443, ToPort: 443},
+		{CIDR: "10.0.0.2/32", Protocol: "udp", FromPort: 53, ToPort: 53},
+	}
+	got, err := (&restricted).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`cidr_blocks = [var.network_cidr]`,
+		`prefix_list_ids = [data.aws_prefix_list.s3.id]`,
+		`name = "com.amazonaws.${var.region}.s3"`,
+		"from_port   = 443\n    to_port     = 443\n    protocol    = \"tcp\"\n    cidr_blocks = [\"10.0.0.0/8\"]",
+		"from_port   = 53\n    to_port     = 53\n    protocol    = \"udp\"\n    cidr_blocks = [\"10.0.0.2/32\"]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	// The ATC security group keeps its open egress for the web VMs
+	if strings.Count(got, `cidr_blocks = ["0.0.0.0/0"]`) != strings.Count(open, `cidr_blocks = ["0.0.0.0/0"]`)-1 {
+		t.Error("expected only the VMs security group to lose its open egress")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformWorkerZones(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	single, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(single, `resource "aws_subnet" "worker_0"`) || !strings.Contains(single, `value = join(",", [])`) {
+		t.Error("expected no worker subnets by default")
+	}
+
+	spread := base
+	spread.WorkerZones = []config.WorkerZone{
+		{Zone: "eu-west-1b", CIDR: "10.0.2.0/24"},
+		{Zone: "eu-west-1c", CIDR: "10.0.3.0/24"},
+	}
+	got, err := (&spread).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"availability_zone       = \"eu-west-1b\"\n  cidr_block              = \"10.0.2.0/24\"",
+		"availability_zone       = \"eu-west-1c\"\n  cidr_block              = \"10.0.3.0/24\"",
+		"subnet_id      = aws_subnet.worker_1.id\n  route_table_id = aws_route_table.private.id",
+		`value = join(",", [aws_subnet.worker_0.id, aws_subnet.worker_1.id])`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformExternalDB(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	rds, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rds, `resource "aws_db_instance" "default"`) {
+		t.Error("expected an RDS instance by default")
+	}
+
+	external := base
+	external.ExternalDBHost = "db.example.com"
+	external.ExternalDBPort = "6432"
+	got, err := (&external).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "aws_db_instance") || strings.Contains(got, "aws_db_subnet_group") {
+		t.Error("expected no RDS instance with an external database")
+	}
+	for _, want := range []string{`value = "6432"`, `value = "db.example.com"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformAuroraServerless(t *testing.T) {
+	aurora := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBMinACU: 0.5, DBMaxACU: 8, DBEngineVersion: "15.4"}
+
+	got, err := (&aurora).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, `resource "aws_db_instance" "default"`) {
+		t.Error("expected no RDS instance with Aurora Serverless")
+	}
+	for _, want := range []string{
+		"min_capacity = 0.5\n    max_capacity = 8",
+		`instance_class             = "db.serverless"`,
+		`engine_version              = "15.4"`,
+		`value = aws_rds_cluster.default.endpoint`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBEngineVersion(t *testing.T) {
+	rds := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBEngineVersion: "16"}
+
+	got, err := (&rds).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `engine_version              = "16"`) {
+		t.Error("expected the RDS instance to run the given major version")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBStorage(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	got, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"allocated_storage           = 10\n",
+		`storage_type                = "gp2"`,
+		"ignore_changes = [allocated_storage]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q by default", want)
+		}
+	}
+
+	sized := base
+	sized.DBStorage = 100
+	sized.DBMaxStorage = 500
+	sized.DBStorageType = "io1"
+	sized.DBIOPS = 3000
+	got, err = (&sized).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"allocated_storage           = 100\n",
+		"max_allocated_storage       = 500\n",
+		`storage_type                = "io1"`,
+		"iops                        = 3000\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "ignore_changes = [allocated_storage]") {
+		t.Error("expected the size of the storage to be managed once it is set")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBBackups(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	got, err := (&base).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "backup_retention_period") || strings.Contains(got, "preferred_backup_window") {
+		t.Error("expected backups to be left to RDS by default")
+	}
+
+	for _, inputVars := range []AWSInputVars{base, {AllowIPs: base.AllowIPs, Deployment: base.Deployment, DBMinACU: 0.5, DBMaxACU: 4}} {
+		inputVars.DBBackupRetention = 30
+		inputVars.DBBackupWindow = "03:00-04:00"
+		inputVars.DBDeletionProtection = true
+		got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"backup_retention_period     = 30\n",
+			`preferred_backup_window     = "03:00-04:00"`,
+			"deletion_protection         = true\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected terraform to contain %q", want)
+			}
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBMultiAZ(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "multi_az                    = false\n") {
+		t.Error("expected a single-AZ RDS instance by default")
+	}
+
+	inputVars.DBMultiAZ = true
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "multi_az                    = true\n") {
+		t.Error("expected a multi-AZ RDS instance with DBMultiAZ")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBBlueGreenUpdate(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "blue_green_update") {
+		t.Error("expected changes to be made in place by default")
+	}
+
+	inputVars.DBBlueGreenUpdate = true
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "blue_green_update {\n    enabled = true\n  }") {
+		t.Error("expected a Blue/Green deployment with DBBlueGreenUpdate")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBReadReplica(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, `"replica"`) {
+		t.Error("expected no read replica by default")
+	}
+
+	inputVars.DBReadReplica = true
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_db_instance" "replica"`,
+		"replicate_source_db    = aws_db_instance.default.identifier",
+		"value = aws_db_instance.replica.address",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.DBMinACU = 0.5
+	inputVars.DBMaxACU = 4
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_rds_cluster_instance" "replica"`,
+		"value = aws_rds_cluster.default.reader_endpoint",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBKMSKey(t *testing.T) {
+	const arn = "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBKMSKeyARN: arn}
+	for _, inputVars := range []AWSInputVars{base, {AllowIPs: base.AllowIPs, Deployment: base.Deployment, DBKMSKeyARN: arn, DBMinACU: 0.5, DBMaxACU: 4}} {
+		got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"storage_encrypted           = true\n",
+			`kms_key_id                  = "` + arn + `"`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected terraform to contain %q", want)
+			}
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBIAMUser(t *testing.T) {
+	base := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBIAMUser: "control_tower_iam"}
+	aurora := base
+	aurora.DBMinACU, aurora.DBMaxACU = 0.5, 4
+	for resourceID, inputVars := range map[string]AWSInputVars{
+		"aws_db_instance.default.resource_id":         base,
+		"aws_rds_cluster.default.cluster_resource_id": aurora,
+	} {
+		got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"  iam_database_authentication_enabled = true\n",
+			`resource "aws_iam_role_policy" "web_rds_iam"`,
+			`"Resource": "arn:aws:rds-db:${var.region}:${data.aws_caller_identity.current.account_id}:dbuser:${` + resourceID + `}/control_tower_iam"`,
+			`value = aws_iam_instance_profile.web.name`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected terraform to contain %q", want)
+			}
+		}
+	}
+
+	got, err := (&AWSInputVars{AllowIPs: base.AllowIPs, Deployment: base.Deployment}).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "iam_database_authentication_enabled") || strings.Contains(got, "web_rds_iam") {
+		t.Error("expected no IAM database authentication")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformExistingVPC(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:                `"1.2.3.4/32"`,
+		Deployment:              "control-tower-test",
+		ExistingVPCID:           "vpc-0123",
+		ExistingPublicSubnetID:  "subnet-public",
+		ExistingPrivateSubnetID: "subnet-private",
+		ExistingDBSubnetIDs:     []string{"subnet-db-a", "subnet-db-b"},
+		ExistingNATIP:           "5.6.7.8",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`vpc_id            = "vpc-0123"`,
+		`nat_public_ip     = "5.6.7.8"`,
+		`subnet_ids = ["subnet-db-a", "subnet-db-b"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{
+		`resource "aws_vpc" "default"`,
+		`resource "aws_nat_gateway" "default"`,
+		`resource "aws_eip" "nat"`,
+		`resource "aws_subnet" "rds_a"`,
+		`aws_internet_gateway.default`,
+	} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected terraform not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBRestore(t *testing.T) {
+	restore := AWSInputVars{
+		AllowIPs:            `"1.2.3.4/32"`,
+		Deployment:          "control-tower-test",
+		DBRestoreSource:     "terraform-20230101",
+		DBRestoreInstance:   "control-tower-test-restored-1700000000",
+		DBRestoreTime:       "2023-11-14T22:00:00Z",
+		DBArchivedInstances: []string{"terraform-20230101"},
+		DirectorDBArchive:   "archived_0",
+	}
+
+	got, err := (&restore).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`identifier                  = "control-tower-test-restored-1700000000"`,
+		`source_db_instance_identifier = "terraform-20230101"`,
+		`restore_time                  = "2023-11-14T22:00:00Z"`,
+		`resource "aws_db_instance" "archived_0" {`,
+		"value = aws_db_instance.archived_0.address\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	restore.DBMinACU = 0.5
+	restore.DBMaxACU = 4
+	got, err = (&restore).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`cluster_identifier          = "control-tower-test-restored-1700000000"`,
+		`source_cluster_identifier = "terraform-20230101"`,
+		`resource "aws_rds_cluster" "archived_0" {`,
+		`resource "aws_rds_cluster_instance" "archived_0" {`,
+		"value = aws_rds_cluster.archived_0.endpoint\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformDBParameters(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:               `"1.2.3.4/32"`,
+		Deployment:             "control-tower-test",
+		DBParameters:           []DBParameter{{Name: "max_connections", Value: "500", ApplyMethod: "pending-reboot"}},
+		DBParameterGroupFamily: "postgres15",
+	}
+
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "aws_db_parameter_group" "default" {`,
+		`family      = "postgres15"`,
+		`name         = "max_connections"`,
+		`apply_method = "pending-reboot"`,
+		"parameter_group_name        = aws_db_parameter_group.default.name\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.DBMinACU = 0.5
+	inputVars.DBMaxACU = 4
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "db_cluster_parameter_group_name = aws_rds_cluster_parameter_group.default.name\n") {
+		t.Error("expected the Aurora cluster to use a cluster parameter group")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformPrivate(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"10.8.0.0/16"`, DirectorAllowIPs: `"10.8.0.0/16"`, Deployment: "control-tower-test", Private: true, NATCIDR: "10.0.2.0/28", WebCount: 2}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`cidr_block              = "10.0.2.0/28"`,
+		`subnet_id     = aws_subnet.nat.id`,
+		"subnet_id      = aws_subnet.public.id\n  route_table_id = aws_route_table.private.id",
+		`internal           = true`,
+		`private_ipv4_address = cidrhost(var.public_cidr, 8)`,
+		`value = cidrhost(var.public_cidr, 6)`,
+		`cidr_blocks = ["${var.source_access_ip}/32", var.network_cidr, "10.8.0.0/16"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{`resource "aws_eip" "director"`, `resource "aws_eip" "atc"`, `resource "aws_eip" "web_lb"`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected terraform not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformBastion(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"10.8.0.0/16"`, Deployment: "control-tower-test", Private: true, NATCIDR: "10.0.2.0/28", Bastion: true, BastionAllowIPs: `"203.0.113.0/24"`, BastionPublicKey: "ssh-rsa AAAAbastion"}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`public_key = "ssh-rsa AAAAbastion"`,
+		`cidr_blocks = ["${var.source_access_ip}/32", "203.0.113.0/24"]`,
+		`subnet_id              = aws_subnet.nat.id`,
+		`http_tokens   = "required"`,
+		`security_groups = [aws_security_group.bastion.id]`,
+		`value = aws_eip.bastion.public_ip`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.Bastion = false
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, `resource "aws_instance" "bastion"`) || strings.Contains(got, "aws_security_group.bastion.id") {
+		t.Error("expected no bastion without Bastion")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformVPCEndpoints(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"10.8.0.0/16"`, Deployment: "control-tower-test", Private: true, NATCIDR: "10.0.2.0/28", VPCEndpoints: true}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`enable_dns_hostnames = true`,
+		`service_name      = "com.amazonaws.${var.region}.s3"`,
+		`route_table_ids   = [aws_route_table.private.id]`,
+		`for_each = toset(["ec2", "secretsmanager", "logs", "monitoring"])`,
+		`private_dns_enabled = true`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.VPCEndpoints = false
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, `resource "aws_vpc_endpoint"`) {
+		t.Error("expected no VPC endpoints without VPCEndpoints")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformNATInstance(t *testing.T) {
+	inputVars := AWSInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", NATTopology: "instance"}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`source_dest_check      = false`,
+		`allocation_id = aws_eip.nat.id
+  instance_id   = aws_instance.nat.id`,
+		`network_interface_id = aws_instance.nat.primary_network_interface_id`,
+		`value = aws_instance.nat.private_ip`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `resource "aws_nat_gateway" "default"`) {
+		t.Error("expected the NAT instance to replace the NAT gateway")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformNATPerZone(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:    `"1.2.3.4/32"`,
+		Deployment:  "control-tower-test",
+		NATTopology: "per-zone",
+		WorkerZones: []config.WorkerZone{{Zone: "eu-west-1b", CIDR: "10.0.6.0/24", NATCIDR: "10.0.2.0/28"}},
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`cidr_block              = "10.0.2.0/28"`,
+		`subnet_id     = aws_subnet.worker_nat_0.id`,
+		`nat_gateway_id = aws_nat_gateway.worker_0.id`,
+		`route_table_id = aws_route_table.worker_0.id`,
+		`"aws:SourceIp": ["${local.nat_public_ip}/32", "${aws_eip.nat_0.public_ip}/32"]`,
+		`cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_public_ip}/32", "${aws_eip.nat_0.public_ip}/32"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.NATTopology = ""
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "aws_nat_gateway.worker_0") || !strings.Contains(got, "route_table_id = aws_route_table.private.id") {
+		t.Error("expected worker zones to share the NAT gateway without per-zone NAT")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformIPv6(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:                 `"1.2.3.4/32"`,
+		AllowIPv6s:               `"2001:db8::/32"`,
+		Deployment:               "control-tower-test",
+		HostedZoneID:             "Z123",
+		AdditionalRecordPrefixes: []string{"ci"},
+		IPv6:                     true,
+		WebCount:                 2,
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`assign_generated_ipv6_cidr_block = true`,
+		`ipv6_cidr_block         = cidrsubnet(aws_vpc.default.ipv6_cidr_block, 8, 0)`,
+		`egress_only_gateway_id = aws_egress_only_internet_gateway.default.id`,
+		`ip_address_type    = "dualstack"`,
+		`security_groups    = [aws_security_group.web_lb.id]`,
+		`ipv6_cidr_blocks = ["2001:db8::/32"]`,
+		`resource "aws_route53_record" "concourse_ipv6"`,
+		`resource "aws_route53_record" "concourse_additional_ipv6_0"`,
+		`type    = "AAAA"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.IPv6 = false
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unwanted := range []string{"ipv6_cidr_block", "AAAA", "dualstack"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected terraform not to contain %q without IPv6", unwanted)
+		}
+	}
+	if !strings.Contains(got, `resource "aws_route53_record" "concourse_additional_0"`) {
+		t.Error("expected the additional record to render")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformPeering(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:             `"1.2.3.4/32"`,
+		Deployment:           "control-tower-test",
+		PeerVPCIDs:           []string{"vpc-0123"},
+		RestrictWorkerEgress: true,
+		NATTopology:          "per-zone",
+		WorkerZones:          []config.WorkerZone{{Zone: "eu-west-1b", CIDR: "10.0.6.0/24", NATCIDR: "10.0.2.0/28"}},
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`id = "vpc-0123"`,
+		`resource "aws_vpc_peering_connection" "peer_vpc-0123"`,
+		`peer_vpc_id = data.aws_vpc.peer_vpc-0123.id
+  auto_accept = true`,
+		`route_table_id            = aws_vpc.default.main_route_table_id`,
+		`cidr_blocks = [data.aws_vpc.peer_vpc-0123.cidr_block]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	// Once for the private route table and once for the worker zone's
+	route := `vpc_peering_connection_id = aws_vpc_peering_connection.peer_vpc-0123.id
+  }`
+	if count := strings.Count(got, route); count != 2 {
+		t.Errorf("expected 2 route table routes to the peer, got %d", count)
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformTransitGateway(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:                   `"1.2.3.4/32"`,
+		Deployment:                 "control-tower-test",
+		TransitGatewayID:           "tgw-0123",
+		TransitGatewayRoutes:       []string{"10.128.0.0/9", "172.16.0.0/12"},
+		TransitGatewayRouteTableID: "tgw-rtb-0123",
+		RestrictWorkerEgress:       true,
+		WorkerZones:                []config.WorkerZone{{Zone: "eu-west-1b", CIDR: "10.0.6.0/24"}},
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`transit_gateway_id = "tgw-0123"`,
+		`subnet_ids         = [aws_subnet.private.id, aws_subnet.worker_0.id]`,
+		`transit_gateway_default_route_table_association = false`,
+		`resource "aws_ec2_transit_gateway_route_table_propagation" "hub"`,
+		`resource "aws_route" "hub_172_16_0_0_12" {
+  route_table_id         = aws_vpc.default.main_route_table_id
+  destination_cidr_block = "172.16.0.0/12"`,
+		`cidr_block         = "10.128.0.0/9"
+    transit_gateway_id = aws_ec2_transit_gateway_vpc_attachment.hub.transit_gateway_id`,
+		`cidr_blocks = ["10.128.0.0/9", "172.16.0.0/12"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	inputVars.TransitGatewayRouteTableID = ""
+	inputVars.NoTransitGatewayPropagation = true
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "transit_gateway_default_route_table_propagation = false") || strings.Contains(got, "aws_ec2_transit_gateway_route_table_propagation") {
+		t.Error("expected the VPC's range not to be propagated into the default route table")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformComponentAllowIPs(t *testing.T) {
+	inputVars := AWSInputVars{
+		AllowIPs:         `"0.0.0.0/0"`,
+		WebAllowIPs:      `"198.51.100.0/24"`,
+		DirectorAllowIPs: `"203.0.113.10/32"`,
+		SSHAllowIPs:      `"203.0.113.20/32"`,
+		MetricsAllowIPs:  `"192.0.2.0/24"`,
+		MetricsEnabled:   true,
+		Deployment:       "control-tower-test",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"from_port   = 25555\n    to_port     = 25555\n    protocol    = \"tcp\"\n    cidr_blocks = [\"${var.source_access_ip}/32\", \"${local.nat_public_ip}/32\", \"203.0.113.10/32\"]",
+		"from_port   = 22\n    to_port     = 22\n    protocol    = \"tcp\"\n    cidr_blocks = [\"${var.source_access_ip}/32\", \"${local.nat_public_ip}/32\", \"203.0.113.20/32\"]",
+		`"${local.atc_public_ip}/32", "198.51.100.0/24"]`,
+		`cidr_blocks = ["${local.nat_public_ip}/32", "192.0.2.0/24"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `, "0.0.0.0/0"]`) {
+		t.Error("expected --allow-ips to be replaced by each component's list")
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraformWebEIP(t *testing.T) {
+	inputVars := AWSInputVars{
+		WebEIP:     "198.51.100.7",
+		WebCount:   1,
+		Deployment: "control-tower-test",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `data "aws_eip" "atc" {
+  public_ip = "198.51.100.7"
+}`) || !strings.Contains(got, "atc_public_ip = data.aws_eip.atc.public_ip") {
+		t.Error("expected the ATC to take the existing Elastic IP")
+	}
+	if strings.Contains(got, `resource "aws_eip" "atc"`) {
+		t.Error("expected no Elastic IP to be created for the ATC")
+	}
+
+	inputVars.WebCount = 2
+	got, err = (&inputVars).ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`data "aws_eip" "web_lb" {
+  public_ip = "198.51.100.7"
+}`,
+		"allocation_id        = data.aws_eip.web_lb.id",
+		`resource "aws_eip" "atc"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `resource "aws_eip" "web_lb"`) {
+		t.Error("expected no Elastic IP to be created for the load balancer")
+	}
+}
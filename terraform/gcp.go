@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"reflect"
+	"strings"
 
+	"github.com/EngineerBetter/control-tower/config"
 	"github.com/EngineerBetter/control-tower/util"
 	"github.com/asaskevich/govalidator"
 )
@@ -25,12 +27,104 @@ type GCPInputVars struct {
 	GCPCredentialsJSON string
 	MetricsEnabled     bool
 	Namespace          string
+	P2PVolumeStreaming bool
 	PrivateCIDR        string
 	Project            string
+	PrometheusMetrics  bool
 	PublicCIDR         string
 	Region             string
 	Tags               string
 	Zone               string
+	// AdditionalRecordSetPrefixes name further record sets in the managed zone for any additional domains
+	AdditionalRecordSetPrefixes []string
+	MetricsScrapeAllowIPs       []string
+	ExternalWorkerAllowIPs      []string
+	WebCount                    int
+	// RestrictWorkerEgress denies egress from workers other than to the deployment's subnets and WorkerEgressAllow
+	RestrictWorkerEgress bool
+	WorkerEgressAllow    []config.EgressRule
+	// SoleTenantNodeType and SoleTenantNodeCount create a sole-tenant node group for dedicated VMs when set
+	SoleTenantNodeType  string
+	SoleTenantNodeCount int
+	// ExistingNetwork places the deployment into a network managed elsewhere, in ExistingPublicSubnet and
+	// ExistingPrivateSubnet
+	ExistingNetwork       string
+	ExistingPublicSubnet  string
+	ExistingPrivateSubnet string
+	// Private leaves out the addresses of the director and ATC, whose private addresses are output in their
+	// place, and NATs the public subnetwork as well as the private one
+	Private bool
+	// Bastion adds a host that the director is reached through, by SSH with BastionPublicKey's pair from
+	// ExternalIP and BastionAllowIPs
+	Bastion          bool
+	BastionAllowIPs  string
+	BastionPublicKey string
+	// PeerNetworks are peered with the network, which exchanges routes with them
+	PeerNetworks []string
+	// NCCHub is a Network Connectivity Center hub that the network is a VPC spoke of, exporting the routes
+	// to its subnets other than those in NCCExcludeExportRanges
+	NCCHub                 string
+	NCCExcludeExportRanges []string
+	// GoogleBetaProvider declares the google-beta provider that the spoke is made with. It is kept once a
+	// deployment has had a spoke, as terraform needs the provider to remove the spoke
+	GoogleBetaProvider bool
+	// WebAllowIPs, DirectorAllowIPs, SSHAllowIPs and MetricsAllowIPs are let in to the web UI, the director's
+	// APIs, SSH to the director and Grafana, alongside the deployment's own addresses
+	WebAllowIPs      string
+	DirectorAllowIPs string
+	SSHAllowIPs      string
+	MetricsAllowIPs  string
+	// WebAddressName is the name of an existing static address that the web endpoint takes in place of one of
+	// its own
+	WebAddressName string
+	// ExternalDBHost replaces the CloudSQL instance when the deployment has an external database
+	ExternalDBHost string
+	// DBHA makes the CloudSQL instance regional, with a standby in another zone
+	DBHA bool
+	// DBReadReplica adds a read replica of the CloudSQL instance
+	DBReadReplica bool
+	// DBKMSKey encrypts the CloudSQL instance with a customer managed key
+	DBKMSKey string
+	// DatabaseVersion is the CloudSQL database version, such as POSTGRES_15
+	DatabaseVersion string
+	// DBBackupRetention is the number of daily backups CloudSQL keeps, which start at DBBackupStartTime
+	DBBackupRetention    int
+	DBBackupStartTime    string
+	DBDeletionProtection bool
+	// DBParameters are set as database flags of the CloudSQL instance
+	DBParameters []DBParameter
+	// DBRestoreSource and DBRestoreTime are the instance and time that the DBRestoreInstance was restored from,
+	// when restore-db has replaced the database. DBArchivedInstances are the instances it replaced
+	DBRestoreSource     string
+	DBRestoreInstance   string
+	DBRestoreTime       string
+	DBArchivedInstances []string
+}
+
+// KeyedPeerNetworks are PeerNetworks keyed by the project and name of each network, which are the last parts
+// of projects/PROJECT/global/networks/NETWORK
+func (v *GCPInputVars) KeyedPeerNetworks() []KeyedValue {
+	var keyed []KeyedValue
+	for _, network := range v.PeerNetworks {
+		key := network
+		if parts := strings.Split(network, "/"); len(parts) >= 5 {
+			key = parts[len(parts)-4] + "_" + parts[len(parts)-1]
+		}
+		keyed = append(keyed, KeyedValue{Key: resourceKey(key), Value: network})
+	}
+	return keyed
+}
+
+// ATCIPAdopted is true when WebAddressName takes the place of the ATC's address, as the web endpoint of a
+// deployment with a single web VM
+func (v *GCPInputVars) ATCIPAdopted() bool {
+	return v.WebAddressName != "" && v.WebCount <= 1
+}
+
+// WebLBIPAdopted is true when WebAddressName takes the place of the address of the load balancer in front of
+// several web VMs
+func (v *GCPInputVars) WebLBIPAdopted() bool {
+	return v.WebAddressName != "" && v.WebCount > 1
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
@@ -45,8 +139,10 @@ func (v *GCPInputVars) ConfigureTerraform(terraformContents string) (string, err
 // Metadata represents output from terraform on GCP or GCP
 type GCPOutputs struct {
 	ATCPublicIP                 MetadataStringValue `json:"atc_public_ip" valid:"required"`
+	BastionPublicIP             MetadataStringValue `json:"bastion_public_ip"`
 	BoshDBAddress               MetadataStringValue `json:"bosh_db_address" valid:"required"`
 	DBName                      MetadataStringValue `json:"db_name" valid:"required"`
+	DBReplicaAddress            MetadataStringValue `json:"db_replica_address"`
 	DirectorAccountCreds        MetadataStringValue `json:"director_account_creds" valid:"required"`
 	DirectorPublicIP            MetadataStringValue `json:"director_public_ip" valid:"required"`
 	DirectorSecurityGroupID     MetadataStringValue `json:"director_firewall_name" valid:"required"`
@@ -56,8 +152,11 @@ type GCPOutputs struct {
 	PrivateSubnetworkName       MetadataStringValue `json:"private_subnetwork_name" valid:"required"`
 	PublicSubnetworkInternalGw  MetadataStringValue `json:"public_subnetwork_internal_gw" valid:"required"`
 	PublicSubnetworkName        MetadataStringValue `json:"public_subnetwork_name" valid:"required"`
+	RuntimeAccountCreds         MetadataStringValue `json:"runtime_account_creds"`
 	SelfUpdateAccountCreds      MetadataStringValue `json:"self_update_account_creds" valid:"required"`
 	SQLServerCert               MetadataStringValue `json:"server_ca_cert" valid:"required"`
+	WebTargetPool               MetadataStringValue `json:"web_target_pool"`
+	SoleTenantNodeGroup         MetadataStringValue `json:"sole_tenant_node_group"`
 }
 
 // AssertValid returns an error if the struct contains any missing fields
@@ -3,8 +3,11 @@ package terraform_test
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/EngineerBetter/control-tower/config"
+	"github.com/EngineerBetter/control-tower/resource"
 	. "github.com/EngineerBetter/control-tower/terraform"
 )
 
@@ -155,3 +158,409 @@ func TestGCPMetadata_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestGCPInputVars_ConfigureTerraformRestrictWorkerEgress(t *testing.T) {
+	base := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	open, err := (&base).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(open, "restricted-egress") {
+		t.Error("expected open worker egress by default")
+	}
+
+	restricted := base
+	restricted.RestrictWorkerEgress = true
+	restricted.WorkerEgressAllow = []config.EgressRule{{CIDR: "10.0.0.0/8", Protocol: "tcp", FromPort: 8000, ToPort: 8100}}
+	got, err := (&restricted).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "google_compute_firewall" "worker-egress-deny"`,
+		`destination_ranges = [var.public_cidr, var.private_cidr]`,
+		`resource "google_compute_firewall" "worker-egress-allow-0"`,
+		`destination_ranges = ["10.0.0.0/8"]`,
+		`ports = ["8000-8100"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformSoleTenantNodeGroup(t *testing.T) {
+	base := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+
+	shared, err := (&base).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(shared, "google_compute_node_group") {
+		t.Error("expected no sole-tenant node group by default")
+	}
+
+	dedicated := base
+	dedicated.SoleTenantNodeType = "n1-node-96-624"
+	dedicated.SoleTenantNodeCount = 2
+	got, err := (&dedicated).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`node_type = "n1-node-96-624"`,
+		"zone          = var.zone\n  initial_size  = 2\n  node_template = google_compute_node_template.sole-tenant.id",
+		`value = google_compute_node_group.sole-tenant.name`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformExternalDB(t *testing.T) {
+	external := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", ExternalDBHost: "db.example.com"}
+
+	got, err := (&external).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "google_sql_") {
+		t.Error("expected no CloudSQL instance with an external database")
+	}
+	if !strings.Contains(got, `value = "db.example.com"`) {
+		t.Error("expected the external database to be output as the database address")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBHA(t *testing.T) {
+	zonal := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test"}
+	got, err := (&zonal).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `availability_type = "ZONAL"`) {
+		t.Error("expected a zonal CloudSQL instance by default")
+	}
+
+	regional := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBHA: true}
+	got, err = (&regional).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `availability_type = "REGIONAL"`) {
+		t.Error("expected a regional CloudSQL instance with DBHA")
+	}
+	if !strings.Contains(got, "point_in_time_recovery_enabled = true") {
+		t.Error("expected backups to be enabled with DBHA")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBReadReplica(t *testing.T) {
+	inputVars := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBReadReplica: true}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "google_sql_database_instance" "replica"`,
+		"master_instance_name = google_sql_database_instance.director.name",
+		"value = google_sql_database_instance.replica.first_ip_address",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBKMSKey(t *testing.T) {
+	const key = "projects/my-project/locations/europe-west1/keyRings/control-tower/cryptoKeys/db"
+	inputVars := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBKMSKey: key, DBReadReplica: true}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(got, `encryption_key_name`); n != 2 {
+		t.Errorf("expected the instance and its replica to be encrypted with the key, got %d encryption_key_name", n)
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformExistingNetwork(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:              `"1.2.3.4/32"`,
+		Deployment:            "control-tower-test",
+		ExistingNetwork:       "shared",
+		ExistingPublicSubnet:  "shared-public",
+		ExistingPrivateSubnet: "shared-private",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`data "google_compute_network" "default"`,
+		`name    = "shared-private"`,
+		`network            = data.google_compute_network.default`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `resource "google_compute_network" "default"`) {
+		t.Error("expected the network not to be created")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformPrivate(t *testing.T) {
+	inputVars := GCPInputVars{AllowIPs: `"10.8.0.0/16"`, Deployment: "control-tower-test", Private: true}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"name                    = local.public_subnetwork.self_link\n    source_ip_ranges_to_nat",
+		`value = cidrhost(var.public_cidr, 6)`,
+		`value = cidrhost(var.public_cidr, 7)`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{`resource "google_compute_address" "director"`, `resource "google_compute_address" "atc_ip"`, `name = "atc_conf"`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected terraform not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformBastion(t *testing.T) {
+	inputVars := GCPInputVars{AllowIPs: `"10.8.0.0/16"`, Deployment: "control-tower-test", Private: true, Bastion: true, BastionAllowIPs: `"203.0.113.0/24"`, BastionPublicKey: "ssh-rsa AAAAbastion"}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`ssh-keys               = "ubuntu:ssh-rsa AAAAbastion"`,
+		`source_ranges = ["${var.source_access_ip}/32", "203.0.113.0/24"]`,
+		`source_tags = ["bastion"]`,
+		`value = google_compute_address.bastion.address`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformPeering(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:     `"1.2.3.4/32"`,
+		Deployment:   "control-tower-test",
+		PeerNetworks: []string{"projects/shared/global/networks/artifacts", "projects/shared/global/networks/targets"},
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`peer_network = "projects/shared/global/networks/artifacts"`,
+		`peer_network = "projects/shared/global/networks/targets"
+  depends_on   = [google_compute_network_peering.peer-shared_artifacts]`,
+		`resource "google_compute_network_peering" "peer-shared_targets"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformNCCSpoke(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:               `"1.2.3.4/32"`,
+		Deployment:             "control-tower-test",
+		NCCHub:                 "projects/shared/locations/global/hubs/core",
+		NCCExcludeExportRanges: []string{"10.0.0.0/24", "10.0.1.0/24"},
+		GoogleBetaProvider:     true,
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`source = "hashicorp/google-beta"`,
+		`provider "google-beta" {`,
+		`resource "google_network_connectivity_spoke" "hub" {
+  provider = google-beta`,
+		`hub      = "projects/shared/locations/global/hubs/core"`,
+		`exclude_export_ranges = ["10.0.0.0/24", "10.0.1.0/24"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+
+	// The provider is kept to remove the spoke once the network has left the hub
+	inputVars.NCCHub = ""
+	inputVars.NCCExcludeExportRanges = nil
+	got, err = (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "google_network_connectivity_spoke") || !strings.Contains(got, `provider "google-beta" {`) {
+		t.Error("expected terraform to keep the google-beta provider without the spoke")
+	}
+
+	inputVars.GoogleBetaProvider = false
+	got, err = (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "google-beta") {
+		t.Error("expected terraform not to need the google-beta provider for a network that has never been a spoke")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformComponentAllowIPs(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:         `"0.0.0.0/0"`,
+		WebAllowIPs:      `"198.51.100.0/24"`,
+		DirectorAllowIPs: `"203.0.113.10/32"`,
+		SSHAllowIPs:      `"203.0.113.20/32"`,
+		MetricsAllowIPs:  `"192.0.2.0/24"`,
+		MetricsEnabled:   true,
+		Deployment:       "control-tower-test",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"source_ranges = [\"${var.source_access_ip}/32\", \"${google_compute_address.nat_ip.address}/32\", \"203.0.113.10/32\"]\n  allow {\n    protocol = \"tcp\"\n    ports = [\"6868\", \"25555\"]",
+		"source_ranges = [\"${var.source_access_ip}/32\", \"${google_compute_address.nat_ip.address}/32\", \"203.0.113.20/32\"]\n  allow {\n    protocol = \"tcp\"\n    ports = [\"22\"]",
+		"source_ranges = [\"198.51.100.0/24\"]",
+		"\"${local.atc_ip}/32\", \"192.0.2.0/24\"]\n  allow {\n    protocol = \"tcp\"\n    ports = [\"3000\"]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformWebAddressName(t *testing.T) {
+	inputVars := GCPInputVars{
+		WebAddressName: "concourse-web",
+		WebCount:       1,
+		Deployment:     "control-tower-test",
+	}
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `data "google_compute_address" "atc_ip" {
+  name = "concourse-web"
+}`) || strings.Contains(got, `resource "google_compute_address" "atc_ip"`) {
+		t.Error("expected the ATC to take the existing address rather than one of its own")
+	}
+
+	inputVars.WebCount = 2
+	got, err = (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`data "google_compute_address" "web_lb" {
+  name = "concourse-web"
+}`,
+		"ip_address  = data.google_compute_address.web_lb.address",
+		`resource "google_compute_address" "atc_ip"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `resource "google_compute_address" "web_lb"`) {
+		t.Error("expected no address to be created for the load balancer")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDatabaseVersion(t *testing.T) {
+	inputVars := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DatabaseVersion: "POSTGRES_15"}
+
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `database_version    = "POSTGRES_15"`) {
+		t.Error("expected the CloudSQL instance to run the given version")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBBackups(t *testing.T) {
+	inputVars := GCPInputVars{AllowIPs: `"1.2.3.4/32"`, Deployment: "control-tower-test", DBBackupRetention: 30, DBBackupStartTime: "03:00", DBDeletionProtection: true}
+
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"point_in_time_recovery_enabled = true\n",
+		`start_time                     = "03:00"`,
+		"retained_backups = 30\n",
+		"deletion_protection = true\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBRestore(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:            `"1.2.3.4/32"`,
+		Deployment:          "control-tower-test",
+		DBRestoreSource:     "rdsabcdefgh",
+		DBRestoreInstance:   "control-tower-test-restored-1700000000",
+		DBRestoreTime:       "2023-11-14T22:00:00Z",
+		DBArchivedInstances: []string{"rdsabcdefgh"},
+	}
+
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`name                = "control-tower-test-restored-1700000000"`,
+		`source_instance_name = "rdsabcdefgh"`,
+		`point_in_time        = "2023-11-14T22:00:00Z"`,
+		`resource "google_sql_database_instance" "archived_0" {`,
+		`name             = "rdsabcdefgh"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+	if strings.Contains(got, `resource "google_sql_user" "director"`) {
+		t.Error("expected the restored instance to keep the user it was cloned with")
+	}
+}
+
+func TestGCPInputVars_ConfigureTerraformDBParameters(t *testing.T) {
+	inputVars := GCPInputVars{
+		AllowIPs:     `"1.2.3.4/32"`,
+		Deployment:   "control-tower-test",
+		DBParameters: []DBParameter{{Name: "max_connections", Value: "500"}, {Name: "log_min_duration_statement", Value: "1000"}},
+	}
+
+	got, err := (&inputVars).ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"database_flags {\n      name  = \"max_connections\"\n      value = \"500\"\n",
+		`name  = "log_min_duration_statement"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected terraform to contain %q", want)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/EngineerBetter/control-tower/iaas"
 	"github.com/EngineerBetter/control-tower/resource"
@@ -23,6 +25,32 @@ type InputVars interface {
 	ConfigureTerraform(string) (string, error)
 }
 
+// DBParameter is a Postgres parameter of the database. ApplyMethod is when RDS applies it, and is unused on GCP
+type DBParameter struct {
+	Name        string
+	Value       string
+	ApplyMethod string
+}
+
+// KeyedValue is a value that terraform makes resources for, with the Key of the resources in the state. Keys
+// come from the value rather than its place in the list it's in, so that removing a value doesn't replace the
+// resources of those after it
+type KeyedValue struct {
+	Key   string
+	Value string
+}
+
+// resourceKey turns value into part of the name of a terraform resource, which can only contain letters,
+// digits, underscores and hyphens
+func resourceKey(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
 //counterfeiter:generate . Outputs
 // Outputs holds IAAS specific terraform outputs
 type Outputs interface {
@@ -37,13 +65,16 @@ type CLIInterface interface {
 	Apply(InputVars) error
 	Destroy(InputVars) error
 	BuildOutput(InputVars) (Outputs, error)
+	MirrorProviders(InputVars, string) error
+	MoveState(InputVars, map[string]string) error
 }
 
 // CLI struct holds the abstraction of execCmd
 type CLI struct {
-	execCmd func(string, ...string) *exec.Cmd
-	Path    string
-	iaas    iaas.Name
+	execCmd   func(string, ...string) *exec.Cmd
+	Path      string
+	iaas      iaas.Name
+	pluginDir string
 }
 
 //Factory function to return iaas-specific outputs
@@ -84,6 +115,14 @@ func DownloadTerraform(versionFile []byte) Option {
 	}
 }
 
+// PluginDir installs providers from dir, written by MirrorProviders, rather than from the registry
+func PluginDir(dir string) Option {
+	return func(c *CLI) error {
+		c.pluginDir = dir
+		return nil
+	}
+}
+
 // New provides a new CLI
 func New(iaas iaas.Name, ops ...Option) (*CLI, error) {
 	cli := &CLI{
@@ -113,7 +152,37 @@ func (n *NullOutputs) Init(*bytes.Buffer) error { return nil }
 
 func (n *NullOutputs) Get(string) (string, error) { return "", nil }
 
-func (c *CLI) init(config InputVars) (string, error) {
+// RuntimeOutputKeys are the outputs needed to reach the director, which deploy records for autoscaling the
+// workers without access to the terraform state
+var RuntimeOutputKeys = []string{"BastionPublicIP", "BoshDBAddress", "BoshDBPort", "DirectorPublicIP"}
+
+// RecordOutputs returns the values of the given outputs, leaving out any that the IAAS doesn't have
+func RecordOutputs(outputs Outputs, keys []string) map[string]string {
+	recorded := map[string]string{}
+	for _, key := range keys {
+		if value, err := outputs.Get(key); err == nil {
+			recorded[key] = value
+		}
+	}
+	return recorded
+}
+
+// RecordedOutputs are outputs as recorded by RecordOutputs
+type RecordedOutputs map[string]string
+
+func (r RecordedOutputs) AssertValid() error { return nil }
+
+func (r RecordedOutputs) Init(*bytes.Buffer) error { return nil }
+
+func (r RecordedOutputs) Get(key string) (string, error) {
+	value, ok := r[key]
+	if !ok {
+		return "", errors.New(key + " key not found")
+	}
+	return value, nil
+}
+
+func (c *CLI) init(config InputVars, initArgs ...string) (string, error) {
 	var (
 		tfConfig string
 		err      error
@@ -135,7 +204,10 @@ func (c *CLI) init(config InputVars) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	cmd := c.execCmd(c.Path, "init")
+	if c.pluginDir != "" {
+		initArgs = append(initArgs, "-plugin-dir="+c.pluginDir)
+	}
+	cmd := c.execCmd(c.Path, append([]string{"init"}, initArgs...)...)
 	cmd.Dir = terraformConfigPath
 	cmd.Stderr = os.Stderr
 	err = cmd.Run()
@@ -180,9 +252,59 @@ func (c *CLI) Destroy(config InputVars) error {
 	return cmd.Run()
 }
 
+// MoveState moves each resource in the state from the address it's keyed by to the address it maps to,
+// or removes it from the state if it maps to "", without changing the resource itself
+func (c *CLI) MoveState(config InputVars, moves map[string]string) error {
+	terraformConfigPath, err := c.init(config)
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(terraformConfigPath)
+
+	var from []string
+	for address := range moves {
+		from = append(from, address)
+	}
+	sort.Strings(from)
+
+	for _, address := range from {
+		args := []string{"state", "rm", address}
+		if moves[address] != "" {
+			args = []string{"state", "mv", address, moves[address]}
+		}
+		cmd := c.execCmd(c.Path, args...)
+		cmd.Dir = terraformConfigPath
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+		if err = cmd.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MirrorProviders downloads the providers that config needs into dir, for PluginDir to install them from
+func (c *CLI) MirrorProviders(config InputVars, dir string) error {
+	terraformConfigPath, err := c.init(config, "-lock=false")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(terraformConfigPath)
+
+	cmd := c.execCmd(c.Path, "providers", "mirror", dir)
+	cmd.Dir = terraformConfigPath
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
+
 // BuildOutput builds the terraform output
 func (c *CLI) BuildOutput(config InputVars) (Outputs, error) {
-	terraformConfigPath, err := c.init(config)
+	// Reading outputs never writes state, so don't take the state lock, which users with
+	// read-only access to the config bucket are not allowed to do
+	terraformConfigPath, err := c.init(config, "-lock=false")
 	if err != nil {
 		return nil, err
 	}
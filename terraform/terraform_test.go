@@ -2,9 +2,13 @@ package terraform_test
 
 import (
 	"bytes"
-	"github.com/EngineerBetter/control-tower/iaas"
+	"fmt"
+	"os"
+	"strconv"
 	"testing"
 
+	"github.com/EngineerBetter/control-tower/iaas"
+
 	"github.com/EngineerBetter/control-tower/internal/fakeexec"
 	"github.com/EngineerBetter/control-tower/terraform"
 	"github.com/stretchr/testify/require"
@@ -30,6 +34,15 @@ func (mockInputVars *mockTerraformInputVars) ConfigureTerraform(terraformContent
 func (mockInputVars *mockTerraformInputVars) Build(data map[string]interface{}) error {
 	return nil
 }
+func TestExecCommandHelper(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Print(os.Getenv("STDOUT"))
+	i, _ := strconv.Atoi(os.Getenv("EXIT_STATUS"))
+	os.Exit(i)
+}
+
 func TestCLI_Apply(t *testing.T) {
 	e := fakeexec.New(t)
 	defer e.Finish()
@@ -97,3 +110,90 @@ func TestCLI_Destroy(t *testing.T) {
 	err = mockCLIent.Destroy(config)
 	require.NoError(t, err)
 }
+
+func TestCLI_BuildOutput(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+
+	config := &mockTerraformInputVars{}
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"init", "-lock=false"}, args)
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"output", "-json"}, args)
+	}).Outputs("{}")
+	_, err = mockCLIent.BuildOutput(config)
+	require.NoError(t, err)
+}
+
+func TestCLI_MoveState(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.GCP, terraform.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+
+	config := &mockTerraformInputVars{}
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, "init", args[0])
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"state", "rm", "google_sql_database.director"}, args)
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"state", "mv", "google_sql_database_instance.director", "google_sql_database_instance.archived_0"}, args)
+	})
+	err = mockCLIent.MoveState(config, map[string]string{
+		"google_sql_database_instance.director": "google_sql_database_instance.archived_0",
+		"google_sql_database.director":          "",
+	})
+	require.NoError(t, err)
+}
+
+func TestCLI_MirrorProviders(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+
+	config := &mockTerraformInputVars{}
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"init", "-lock=false"}, args)
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"providers", "mirror", "/artifacts/terraform-providers"}, args)
+	})
+	err = mockCLIent.MirrorProviders(config, "/artifacts/terraform-providers")
+	require.NoError(t, err)
+}
+
+func TestCLI_PluginDir(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()), terraform.PluginDir("/artifacts/terraform-providers"))
+	require.NoError(t, err)
+
+	config := &mockTerraformInputVars{}
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"init", "-lock=false", "-plugin-dir=/artifacts/terraform-providers"}, args)
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"output", "-json"}, args)
+	}).Outputs("{}")
+	_, err = mockCLIent.BuildOutput(config)
+	require.NoError(t, err)
+}
@@ -43,6 +43,30 @@ type FakeCLIInterface struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MirrorProvidersStub        func(terraform.InputVars, string) error
+	mirrorProvidersMutex       sync.RWMutex
+	mirrorProvidersArgsForCall []struct {
+		arg1 terraform.InputVars
+		arg2 string
+	}
+	mirrorProvidersReturns struct {
+		result1 error
+	}
+	mirrorProvidersReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MoveStateStub        func(terraform.InputVars, map[string]string) error
+	moveStateMutex       sync.RWMutex
+	moveStateArgsForCall []struct {
+		arg1 terraform.InputVars
+		arg2 map[string]string
+	}
+	moveStateReturns struct {
+		result1 error
+	}
+	moveStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -233,6 +257,130 @@ func (fake *FakeCLIInterface) DestroyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeCLIInterface) MirrorProviders(arg1 terraform.InputVars, arg2 string) error {
+	fake.mirrorProvidersMutex.Lock()
+	ret, specificReturn := fake.mirrorProvidersReturnsOnCall[len(fake.mirrorProvidersArgsForCall)]
+	fake.mirrorProvidersArgsForCall = append(fake.mirrorProvidersArgsForCall, struct {
+		arg1 terraform.InputVars
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.MirrorProvidersStub
+	fakeReturns := fake.mirrorProvidersReturns
+	fake.recordInvocation("MirrorProviders", []interface{}{arg1, arg2})
+	fake.mirrorProvidersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCLIInterface) MirrorProvidersCallCount() int {
+	fake.mirrorProvidersMutex.RLock()
+	defer fake.mirrorProvidersMutex.RUnlock()
+	return len(fake.mirrorProvidersArgsForCall)
+}
+
+func (fake *FakeCLIInterface) MirrorProvidersCalls(stub func(terraform.InputVars, string) error) {
+	fake.mirrorProvidersMutex.Lock()
+	defer fake.mirrorProvidersMutex.Unlock()
+	fake.MirrorProvidersStub = stub
+}
+
+func (fake *FakeCLIInterface) MirrorProvidersArgsForCall(i int) (terraform.InputVars, string) {
+	fake.mirrorProvidersMutex.RLock()
+	defer fake.mirrorProvidersMutex.RUnlock()
+	argsForCall := fake.mirrorProvidersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCLIInterface) MirrorProvidersReturns(result1 error) {
+	fake.mirrorProvidersMutex.Lock()
+	defer fake.mirrorProvidersMutex.Unlock()
+	fake.MirrorProvidersStub = nil
+	fake.mirrorProvidersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCLIInterface) MirrorProvidersReturnsOnCall(i int, result1 error) {
+	fake.mirrorProvidersMutex.Lock()
+	defer fake.mirrorProvidersMutex.Unlock()
+	fake.MirrorProvidersStub = nil
+	if fake.mirrorProvidersReturnsOnCall == nil {
+		fake.mirrorProvidersReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.mirrorProvidersReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCLIInterface) MoveState(arg1 terraform.InputVars, arg2 map[string]string) error {
+	fake.moveStateMutex.Lock()
+	ret, specificReturn := fake.moveStateReturnsOnCall[len(fake.moveStateArgsForCall)]
+	fake.moveStateArgsForCall = append(fake.moveStateArgsForCall, struct {
+		arg1 terraform.InputVars
+		arg2 map[string]string
+	}{arg1, arg2})
+	stub := fake.MoveStateStub
+	fakeReturns := fake.moveStateReturns
+	fake.recordInvocation("MoveState", []interface{}{arg1, arg2})
+	fake.moveStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCLIInterface) MoveStateCallCount() int {
+	fake.moveStateMutex.RLock()
+	defer fake.moveStateMutex.RUnlock()
+	return len(fake.moveStateArgsForCall)
+}
+
+func (fake *FakeCLIInterface) MoveStateCalls(stub func(terraform.InputVars, map[string]string) error) {
+	fake.moveStateMutex.Lock()
+	defer fake.moveStateMutex.Unlock()
+	fake.MoveStateStub = stub
+}
+
+func (fake *FakeCLIInterface) MoveStateArgsForCall(i int) (terraform.InputVars, map[string]string) {
+	fake.moveStateMutex.RLock()
+	defer fake.moveStateMutex.RUnlock()
+	argsForCall := fake.moveStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCLIInterface) MoveStateReturns(result1 error) {
+	fake.moveStateMutex.Lock()
+	defer fake.moveStateMutex.Unlock()
+	fake.MoveStateStub = nil
+	fake.moveStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCLIInterface) MoveStateReturnsOnCall(i int, result1 error) {
+	fake.moveStateMutex.Lock()
+	defer fake.moveStateMutex.Unlock()
+	fake.MoveStateStub = nil
+	if fake.moveStateReturnsOnCall == nil {
+		fake.moveStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.moveStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -242,6 +390,10 @@ func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	defer fake.buildOutputMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.mirrorProvidersMutex.RLock()
+	defer fake.mirrorProvidersMutex.RUnlock()
+	fake.moveStateMutex.RLock()
+	defer fake.moveStateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
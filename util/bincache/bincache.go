@@ -5,84 +5,173 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
 // Download a file from url and check with a sha1
 func Download(url string) (string, error) {
-	path, err := os.UserCacheDir()
+	return DownloadVerified(url, "")
+}
+
+// DownloadVerified downloads a file from url like Download, and fails unless the sha256 of the
+// download matches checksum. The download is kept as it was served, and checked again each time
+// it is used, so that a cached file that has changed since is never run. An empty checksum skips
+// the check
+func DownloadVerified(url, checksum string) (string, error) {
+	dir, err := os.UserCacheDir()
 	if err != nil {
 		return "", err
 	}
-	path = filepath.Join(path, "control-tower", "bin")
-	err = os.MkdirAll(path, 0700)
+	dir = filepath.Join(dir, "control-tower", "bin")
+	err = os.MkdirAll(dir, 0700)
 	if err != nil {
 		return "", err
 	}
-	path = filepath.Join(path, hash(url))
-	if _, err = os.Stat(path); !os.IsNotExist(err) {
-		return path, nil
+	path := filepath.Join(dir, hash(url+checksum))
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		if err = fetch(url, path); err != nil {
+			return "", err
+		}
 	}
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0700)
-	if err != nil {
-		return "", err
+
+	if checksum != "" {
+		got, err := fileSHA256(path)
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(got, checksum) {
+			os.Remove(path)
+			return "", fmt.Errorf("sha256 of %s is %s, expected %s", url, got, checksum)
+		}
 	}
-	defer f.Close()
-	resp, err := http.Get(url)
+
+	zipped, err := isZip(path)
 	if err != nil {
-		os.Remove(path)
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	var closer io.ReadCloser
+	if !zipped {
+		return executable(path)
+	}
+	// The binary is extracted from the verified archive every time, rather than trusting an earlier copy
+	return extractFirstFile(path, path+"-bin")
+}
 
-	if isZip(url, resp) {
-		closer, err = handleZipFile(resp)
+// fetch downloads url to a temporary file which is renamed to path once complete, so that an
+// interrupted download is never mistaken for a cached one. A file:// url is copied, for CLIs that
+// were downloaded ahead of time for a deployment without internet access
+func fetch(url, path string) error {
+	var body io.ReadCloser
+	if strings.HasPrefix(url, "file://") {
+		f, err := os.Open(filepath.FromSlash(strings.TrimPrefix(url, "file://")))
 		if err != nil {
-			return "", nil
+			return err
 		}
+		body = f
 	} else {
-		closer = resp.Body
+		resp, err := http.Get(url)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status %s downloading %s", resp.Status, url)
+		}
+		body = resp.Body
 	}
-	_, err = io.Copy(f, closer)
+	defer body.Close()
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".download-")
 	if err != nil {
-		os.Remove(path)
-		return "", err
+		return err
 	}
-	return path, nil
+	defer os.Remove(f.Name())
+	if _, err = io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
 
-func handleZipFile(resp *http.Response) (io.ReadCloser, error) {
-	body, errz := ioutil.ReadAll(resp.Body)
-	if errz != nil {
-		return nil, errz
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
-	r, errz := zip.NewReader(bytes.NewReader(body), resp.ContentLength)
-	if errz != nil {
-		return nil, errz
+	defer f.Close()
+	digest := sha256.New()
+	if _, err = io.Copy(digest, f); err != nil {
+		return "", err
 	}
-	firstFile, errz := r.File[0].Open()
-	if errz != nil {
-		return nil, errz
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// isZip checks for the zip signature, as servers don't all name or label zip downloads as such
+func isZip(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
 	}
-	return firstFile, nil
+	defer f.Close()
+	header := make([]byte, 4)
+	if _, err = io.ReadFull(f, header); err != nil {
+		return false, nil
+	}
+	return bytes.Equal(header, []byte("PK\x03\x04")), nil
+}
 
+// executable makes path executable, and on Windows returns a copy with the .exe extension it needs to run
+func executable(path string) (string, error) {
+	if err := os.Chmod(path, 0700); err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" {
+		return path, nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	exe := path + ".exe"
+	return exe, ioutil.WriteFile(exe, contents, 0700)
 }
 
-func isZip(url string, resp *http.Response) bool {
-	if strings.HasSuffix(url, ".zip") {
-		return true
+func extractFirstFile(archive, path string) (string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	if len(r.File) == 0 {
+		return "", fmt.Errorf("%s is an empty zip file", archive)
+	}
+	first, err := r.File[0].Open()
+	if err != nil {
+		return "", err
 	}
-	if resp.Header.Get("Content-Type") == "application/zip" {
-		return true
+	defer first.Close()
+
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0700)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, first); err != nil {
+		f.Close()
+		return "", err
 	}
-	return false
+	return path, f.Close()
 }
 
 func hash(s string) string {
@@ -65,3 +65,26 @@ func TestDownloadZip(t *testing.T) {
 	require.Equal(t, "HELLO\n", string(out))
 	s.Close()
 }
+
+func TestDownloadVerified(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "#!/bin/bash\necho verified")
+	}))
+	defer s.Close()
+
+	_, err := bincache.DownloadVerified(s.URL+"/tampered", "0000000000000000000000000000000000000000000000000000000000000000")
+	require.EqualError(t, err, fmt.Sprintf("sha256 of %s/tampered is 003bb6d390a6b6009a1fdd399f0c3b6964485c483372a0e1b82af38c470ef5dc, expected 0000000000000000000000000000000000000000000000000000000000000000", s.URL))
+
+	path, err := bincache.DownloadVerified(s.URL+"/verified", "003bb6d390a6b6009a1fdd399f0c3b6964485c483372a0e1b82af38c470ef5dc")
+	require.NoError(t, err)
+	defer os.Remove(path)
+	out, err := exec.Command(path).Output()
+	require.NoError(t, err)
+	require.Equal(t, "verified\n", string(out))
+
+	// check a cached download that has changed since is not used
+	s.Close()
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/bash\necho tampered"), 0700))
+	_, err = bincache.DownloadVerified(s.URL+"/verified", "003bb6d390a6b6009a1fdd399f0c3b6964485c483372a0e1b82af38c470ef5dc")
+	require.EqualError(t, err, fmt.Sprintf("sha256 of %s/verified is 0e11a672fe5a382a3230aee6c5ae5e061d9ed0e30bff2f96a56e438a3b4037ee, expected 003bb6d390a6b6009a1fdd399f0c3b6964485c483372a0e1b82af38c470ef5dc", s.URL))
+}
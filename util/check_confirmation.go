@@ -8,9 +8,14 @@ import (
 
 // CheckConfirmation prompts the user for confirmation and returns true IFF the user responds with 'yes'
 func CheckConfirmation(stdin io.Reader, stdout io.Writer, name string) (bool, error) {
+	return Confirm(stdin, stdout, fmt.Sprintf("Are you sure you want to destroy %s?\nThis cannot be undone.", name))
+}
+
+// Confirm asks question and returns true IFF the user responds with 'yes'
+func Confirm(stdin io.Reader, stdout io.Writer, question string) (bool, error) {
 	var response string
 
-	if _, err := fmt.Fprintf(stdout, "Are you sure you want to destroy %s?\nThis cannot be undone. [yes/no]: ", name); err != nil {
+	if _, err := fmt.Fprintf(stdout, "%s [yes/no]: ", question); err != nil {
 		return false, err
 	}
 
@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/json"
+	"fmt"
 	"runtime"
 
 	"github.com/EngineerBetter/control-tower/util/bincache"
@@ -14,9 +15,19 @@ type Resource struct {
 	SHA1    string `json:"sha1"`
 }
 
+// BinaryPaths are the downloads of a CLI. Platforms is keyed by GOOS/GOARCH, eg darwin/arm64, and takes
+// precedence over Mac and Linux, which are the amd64 builds listed by older version files
 type BinaryPaths struct {
-	Mac   string `json:"mac"`
-	Linux string `json:"linux"`
+	Mac       string            `json:"mac"`
+	Linux     string            `json:"linux"`
+	Platforms map[string]Binary `json:"platforms"`
+}
+
+// Binary is the download of a CLI for one platform
+type Binary struct {
+	URL string `json:"url"`
+	// SHA256 is the checksum of the download, which is checked when it is set
+	SHA256 string `json:"sha256"`
 }
 
 func ParseVersionResources(versionFile []byte) map[string]Resource {
@@ -38,25 +49,34 @@ func GetResource(name string, resources map[string]Resource) Resource {
 	return r
 }
 
-func (p BinaryPaths) path() string {
-	switch runtime.GOOS {
-	case "darwin":
-		return p.Mac
-	case "linux":
-		return p.Linux
-	default:
-		panic("OS not supported")
+func (p BinaryPaths) binary(goos, goarch string) (Binary, error) {
+	if b, ok := p.Platforms[goos+"/"+goarch]; ok {
+		return b, nil
 	}
+	switch {
+	case goos == "darwin" && p.Mac != "":
+		// Apple Silicon runs the amd64 build under Rosetta
+		return Binary{URL: p.Mac}, nil
+	case goos == "linux" && goarch == "amd64" && p.Linux != "":
+		return Binary{URL: p.Linux}, nil
+	}
+	return Binary{}, fmt.Errorf("no build for %s/%s", goos, goarch)
 }
 
 // DownloadBOSHCLI returns the path of the downloaded bosh-cli
 func DownloadBOSHCLI(binaries map[string]BinaryPaths) (string, error) {
-	p := binaries["bosh-cli"].path()
-	return bincache.Download(p)
+	return download("bosh-cli", binaries)
 }
 
 // DownloadTerraformCLI returns the path of the downloaded terraform-cli
 func DownloadTerraformCLI(binaries map[string]BinaryPaths) (string, error) {
-	p := binaries["terraform"].path()
-	return bincache.Download(p)
+	return download("terraform", binaries)
+}
+
+func download(name string, binaries map[string]BinaryPaths) (string, error) {
+	b, err := binaries[name].binary(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", fmt.Errorf("version file has no %s for this platform: [%v]", name, err)
+	}
+	return bincache.DownloadVerified(b.URL, b.SHA256)
 }
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestBinaryPaths_binary(t *testing.T) {
+	paths := BinaryPaths{
+		Mac:   "https://example.com/bosh-cli-darwin-amd64",
+		Linux: "https://example.com/bosh-cli-linux-amd64",
+		Platforms: map[string]Binary{
+			"linux/arm64":   {URL: "https://example.com/bosh-cli-linux-arm64", SHA256: "abc123"},
+			"windows/amd64": {URL: "https://example.com/bosh-cli-windows-amd64.exe", SHA256: "def456"},
+		},
+	}
+	tests := []struct {
+		name    string
+		paths   BinaryPaths
+		goos    string
+		goarch  string
+		want    Binary
+		wantErr bool
+	}{
+		{
+			name:   "platform entry",
+			paths:  paths,
+			goos:   "linux",
+			goarch: "arm64",
+			want:   Binary{URL: "https://example.com/bosh-cli-linux-arm64", SHA256: "abc123"},
+		},
+		{
+			name:   "windows",
+			paths:  paths,
+			goos:   "windows",
+			goarch: "amd64",
+			want:   Binary{URL: "https://example.com/bosh-cli-windows-amd64.exe", SHA256: "def456"},
+		},
+		{
+			name:   "linux amd64 from an older version file",
+			paths:  paths,
+			goos:   "linux",
+			goarch: "amd64",
+			want:   Binary{URL: "https://example.com/bosh-cli-linux-amd64"},
+		},
+		{
+			name:   "Apple Silicon falls back to the amd64 build",
+			paths:  paths,
+			goos:   "darwin",
+			goarch: "arm64",
+			want:   Binary{URL: "https://example.com/bosh-cli-darwin-amd64"},
+		},
+		{
+			name:    "linux arm64 cannot use the amd64 build",
+			paths:   BinaryPaths{Linux: "https://example.com/bosh-cli-linux-amd64"},
+			goos:    "linux",
+			goarch:  "arm64",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.paths.binary(tt.goos, tt.goarch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("binary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("binary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}